@@ -0,0 +1,277 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// simulatedDashboardsLimit caps the number of dashboards evaluated for a folder
+const simulatedDashboardsLimit = 1000
+
+// AccessSimulationDTO is the outcome of an access control evaluation simulated for a user or a team
+type AccessSimulationDTO struct {
+	accesscontrol.Explanation
+	Route *SimulatedRouteDTO `json:"route,omitempty"`
+}
+
+// SimulatedRouteDTO is the route whose access control requirements were evaluated
+type SimulatedRouteDTO struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+// DashboardAccessSimulationDTO is the outcome of the dashboard permission checks simulated for a user
+type DashboardAccessSimulationDTO struct {
+	UserID     int64                          `json:"userId"`
+	Login      string                         `json:"login"`
+	OrgRole    models.RoleType                `json:"orgRole"`
+	Dashboards []*SimulatedDashboardAccessDTO `json:"dashboards"`
+}
+
+// SimulatedDashboardAccessDTO holds the guardian decisions for a dashboard and the ACL items granting access to it
+type SimulatedDashboardAccessDTO struct {
+	UID      string                        `json:"uid"`
+	Title    string                        `json:"title"`
+	URL      string                        `json:"url"`
+	CanView  bool                          `json:"canView"`
+	CanEdit  bool                          `json:"canEdit"`
+	CanSave  bool                          `json:"canSave"`
+	CanAdmin bool                          `json:"canAdmin"`
+	ACL      []*models.DashboardAclInfoDTO `json:"acl"`
+}
+
+// GET /api/access-control/users/:userId/simulate
+func (hs *HTTPServer) SimulateUserAccess(c *models.ReqContext) response.Response {
+	explainer, errResponse := hs.permissionExplainer()
+	if errResponse != nil {
+		return errResponse
+	}
+
+	user, errResponse := hs.getSimulatedUser(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	evaluator, route, errResponse := hs.getSimulatedEvaluator(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	explanation, err := explainer.ExplainUser(c.Req.Context(), user, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate user permissions", err)
+	}
+
+	return response.JSON(http.StatusOK, AccessSimulationDTO{Explanation: *explanation, Route: route})
+}
+
+// GET /api/access-control/teams/:teamId/simulate
+func (hs *HTTPServer) SimulateTeamAccess(c *models.ReqContext) response.Response {
+	explainer, errResponse := hs.permissionExplainer()
+	if errResponse != nil {
+		return errResponse
+	}
+
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	query := models.GetTeamByIdQuery{OrgId: c.OrgId, Id: teamID, SignedInUser: c.SignedInUser, HiddenUsers: hs.Cfg.HiddenUsers}
+	if err := hs.SQLStore.GetTeamById(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return response.Error(http.StatusNotFound, "Team not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get team", err)
+	}
+
+	evaluator, route, errResponse := hs.getSimulatedEvaluator(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	explanation, err := explainer.ExplainTeam(c.Req.Context(), c.OrgId, teamID, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate team permissions", err)
+	}
+
+	return response.JSON(http.StatusOK, AccessSimulationDTO{Explanation: *explanation, Route: route})
+}
+
+// GET /api/access-control/users/:userId/simulate/dashboards
+func (hs *HTTPServer) SimulateUserDashboardAccess(c *models.ReqContext) response.Response {
+	user, errResponse := hs.getSimulatedUser(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	var hits []*search.Hit
+	switch {
+	case c.Query("dashboardUid") != "":
+		dashboard, err := hs.SQLStore.GetDashboard(0, c.OrgId, c.Query("dashboardUid"), "")
+		if err != nil {
+			return simulatedDashboardErrorResponse(err, "Dashboard not found")
+		}
+		hits = append(hits, &search.Hit{ID: dashboard.Id, UID: dashboard.Uid, Title: dashboard.Title, URL: dashboard.GetUrl()})
+	case c.Query("folderUid") != "":
+		folder, err := hs.SQLStore.GetDashboard(0, c.OrgId, c.Query("folderUid"), "")
+		if err != nil {
+			return simulatedDashboardErrorResponse(err, "Folder not found")
+		}
+		if !folder.IsFolder {
+			return response.Error(http.StatusBadRequest, "folderUid is not the uid of a folder", nil)
+		}
+
+		// only the dashboards visible to the requester are evaluated
+		query := search.FindPersistedDashboardsQuery{
+			OrgId:        c.OrgId,
+			SignedInUser: c.SignedInUser,
+			FolderIds:    []int64{folder.Id},
+			Type:         string(search.DashHitDB),
+			Limit:        simulatedDashboardsLimit,
+			Permission:   models.PERMISSION_VIEW,
+		}
+		if err := hs.SQLStore.SearchDashboards(c.Req.Context(), &query); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to search dashboards", err)
+		}
+		hits = query.Result
+	default:
+		return response.Error(http.StatusBadRequest, "Either dashboardUid or folderUid is required", nil)
+	}
+
+	result := DashboardAccessSimulationDTO{
+		UserID:     user.UserId,
+		Login:      user.Login,
+		OrgRole:    user.OrgRole,
+		Dashboards: make([]*SimulatedDashboardAccessDTO, 0, len(hits)),
+	}
+	for _, hit := range hits {
+		dashboard, err := simulateDashboardAccess(c, user, hit)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate dashboard permissions", err)
+		}
+		result.Dashboards = append(result.Dashboards, dashboard)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+func simulateDashboardAccess(c *models.ReqContext, user *models.SignedInUser, hit *search.Hit) (*SimulatedDashboardAccessDTO, error) {
+	g := guardian.New(c.Req.Context(), hit.ID, c.OrgId, user)
+	result := &SimulatedDashboardAccessDTO{UID: hit.UID, Title: hit.Title, URL: hit.URL}
+
+	checks := []struct {
+		evaluate func() (bool, error)
+		result   *bool
+	}{
+		{g.CanView, &result.CanView},
+		{g.CanEdit, &result.CanEdit},
+		{g.CanSave, &result.CanSave},
+		{g.CanAdmin, &result.CanAdmin},
+	}
+	for _, check := range checks {
+		allowed, err := check.evaluate()
+		if err != nil {
+			return nil, err
+		}
+		*check.result = allowed
+	}
+
+	acl, err := g.GetMatchingACL(models.PERMISSION_VIEW)
+	if err != nil {
+		return nil, err
+	}
+	result.ACL = acl
+	return result, nil
+}
+
+func simulatedDashboardErrorResponse(err error, notFoundMessage string) response.Response {
+	if errors.Is(err, models.ErrDashboardNotFound) {
+		return response.Error(http.StatusNotFound, notFoundMessage, err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+}
+
+func (hs *HTTPServer) permissionExplainer() (accesscontrol.PermissionExplainer, response.Response) {
+	if hs.AccessControl.IsDisabled() {
+		return nil, response.Error(http.StatusBadRequest, "Access control is not enabled", nil)
+	}
+
+	explainer, ok := hs.AccessControl.(accesscontrol.PermissionExplainer)
+	if !ok {
+		return nil, response.Error(http.StatusNotImplemented, "Access control simulation is not supported", nil)
+	}
+	return explainer, nil
+}
+
+// getSimulatedUser returns the user of the :userId url parameter as a member of the current organization
+func (hs *HTTPServer) getSimulatedUser(c *models.ReqContext) (*models.SignedInUser, response.Response) {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+
+	query := models.GetSignedInUserQuery{UserId: userID, OrgId: c.OrgId}
+	if err := hs.SQLStore.GetSignedInUser(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, response.Error(http.StatusNotFound, "User not found", err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	if query.Result.OrgId != c.OrgId {
+		return nil, response.Error(http.StatusNotFound, "User not found", nil)
+	}
+
+	return query.Result, nil
+}
+
+// getSimulatedEvaluator builds the evaluator to simulate from either the action and scope
+// query parameters or the access control requirements of the route matching method and path.
+func (hs *HTTPServer) getSimulatedEvaluator(c *models.ReqContext) (accesscontrol.Evaluator, *SimulatedRouteDTO, response.Response) {
+	if action := c.Query("action"); action != "" {
+		return accesscontrol.EvalPermission(action, c.QueryStrings("scope")...), nil, nil
+	}
+
+	path := c.Query("path")
+	if path == "" {
+		return nil, nil, response.Error(http.StatusBadRequest, "Either action or path is required", nil)
+	}
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	method := strings.ToUpper(c.Query("method"))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	match, ok := hs.RouteRegister.Lookup(method, path)
+	if !ok {
+		return nil, nil, response.Error(http.StatusNotFound, "Route not found", nil)
+	}
+
+	params := accesscontrol.ScopeParams{OrgID: c.OrgId, URLParams: match.Params}
+	evaluators, err := acmiddleware.RouteEvaluators(c.Req.Context(), params, match.Metadata)
+	if err != nil {
+		return nil, nil, response.Error(http.StatusInternalServerError, "Failed to get route permissions", err)
+	}
+
+	route := &SimulatedRouteDTO{Method: match.Method, Pattern: match.Pattern}
+	switch len(evaluators) {
+	case 0:
+		return nil, nil, response.Error(http.StatusBadRequest, "Route is not protected by access control", nil)
+	case 1:
+		return evaluators[0], route, nil
+	default:
+		return accesscontrol.EvalAll(evaluators...), route, nil
+	}
+}
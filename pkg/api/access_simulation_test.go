@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+)
+
+func TestAccessSimulationAPI(t *testing.T) {
+	sc := setupHTTPServer(t, false, true)
+	setInitCtxSignedInOrgAdmin(sc.initCtx)
+
+	ctx := context.Background()
+	admin, err := sc.db.CreateUser(ctx, models.CreateUserCommand{Login: "admin"})
+	require.NoError(t, err)
+	viewer, err := sc.db.CreateUser(ctx, models.CreateUserCommand{Login: "viewer", SkipOrgSetup: true})
+	require.NoError(t, err)
+	require.NoError(t, sc.db.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: admin.OrgId, UserId: viewer.Id, Role: models.ROLE_VIEWER}))
+	team, err := sc.db.CreateTeam("team", "", admin.OrgId)
+	require.NoError(t, err)
+	require.NoError(t, sc.db.AddTeamMember(viewer.Id, admin.OrgId, team.Id, false, models.PERMISSION_VIEW))
+
+	simulate := func(t *testing.T, path string, query url.Values) (*AccessSimulationDTO, int) {
+		t.Helper()
+		recorder := callAPI(sc.server, http.MethodGet, path+"?"+query.Encode(), nil, t)
+		if recorder.Code != http.StatusOK {
+			return nil, recorder.Code
+		}
+		var result AccessSimulationDTO
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+		return &result, recorder.Code
+	}
+	userPath := fmt.Sprintf("/api/access-control/users/%d/simulate", viewer.Id)
+	teamPath := fmt.Sprintf("/api/access-control/teams/%d/simulate", team.Id)
+	readOrgUsers := url.Values{"action": {accesscontrol.ActionOrgUsersRead}, "scope": {"users:id:1"}}
+
+	t.Run("should deny permissions that are not granted", func(t *testing.T) {
+		result, code := simulate(t, userPath, readOrgUsers)
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, result.Allowed)
+		assert.Empty(t, result.Permissions)
+	})
+
+	t.Run("should explain permissions granted through a team", func(t *testing.T) {
+		store := database.ProvideService(sc.db)
+		role, err := store.CreateRole(ctx, admin.OrgId, accesscontrol.CreateRoleCommand{
+			Name:        "custom:org.users:reader",
+			Permissions: []accesscontrol.Permission{{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, store.AddTeamRole(ctx, admin.OrgId, team.Id, role.UID))
+
+		result, code := simulate(t, userPath, readOrgUsers)
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, result.Allowed)
+		require.Len(t, result.Permissions, 1)
+		assert.Equal(t, accesscontrol.PermissionSourceTeam, result.Permissions[0].Source)
+		assert.Equal(t, role.Name, result.Permissions[0].RoleName)
+
+		result, code = simulate(t, teamPath, readOrgUsers)
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("should evaluate the permissions required by a route", func(t *testing.T) {
+		result, code := simulate(t, userPath, url.Values{"method": {"get"}, "path": {"/api/org/users/lookup?query=admin"}})
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, result.Route)
+		assert.Equal(t, "/api/org/users/lookup", result.Route.Pattern)
+
+		result, code = simulate(t, userPath, url.Values{"method": {"DELETE"}, "path": {fmt.Sprintf("/api/teams/%d", team.Id)}})
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, result.Allowed)
+		assert.Contains(t, result.Evaluator, fmt.Sprintf("teams:id:%d", team.Id))
+
+		_, code = simulate(t, userPath, url.Values{"path": {"/api/unknown"}})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("should validate the simulated subject", func(t *testing.T) {
+		_, code := simulate(t, "/api/access-control/users/999/simulate", readOrgUsers)
+		assert.Equal(t, http.StatusNotFound, code)
+		_, code = simulate(t, "/api/access-control/teams/999/simulate", readOrgUsers)
+		assert.Equal(t, http.StatusNotFound, code)
+		_, code = simulate(t, userPath, url.Values{})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("should evaluate dashboards of a folder", func(t *testing.T) {
+		folder, err := sc.db.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     admin.OrgId,
+			IsFolder:  true,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "folder"}),
+		})
+		require.NoError(t, err)
+		for _, title := range []string{"first", "second"} {
+			_, err := sc.db.SaveDashboard(models.SaveDashboardCommand{
+				OrgId:     admin.OrgId,
+				FolderId:  folder.Id,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": title}),
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, sc.db.UpdateDashboardACL(ctx, folder.Id, []*models.DashboardAcl{
+			{OrgID: admin.OrgId, DashboardID: folder.Id, TeamID: team.Id, Permission: models.PERMISSION_EDIT, Created: time.Now(), Updated: time.Now()},
+		}))
+
+		recorder := callAPI(sc.server, http.MethodGet, fmt.Sprintf("/api/access-control/users/%d/simulate/dashboards?folderUid=%s", viewer.Id, folder.Uid), nil, t)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var result DashboardAccessSimulationDTO
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+		assert.Equal(t, models.ROLE_VIEWER, result.OrgRole)
+		require.Len(t, result.Dashboards, 2)
+		for _, dashboard := range result.Dashboards {
+			assert.True(t, dashboard.CanView)
+			assert.True(t, dashboard.CanEdit)
+			assert.False(t, dashboard.CanAdmin)
+			require.Len(t, dashboard.ACL, 1)
+			assert.Equal(t, team.Id, dashboard.ACL[0].TeamId)
+			assert.True(t, dashboard.ACL[0].Inherited)
+		}
+
+		recorder = callAPI(sc.server, http.MethodGet, fmt.Sprintf("/api/access-control/users/%d/simulate/dashboards", viewer.Id), nil, t)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -31,7 +31,7 @@ func (hs *HTTPServer) registerRoutes() {
 	reqCanAccessTeams := middleware.AdminOrFeatureEnabled(hs.Cfg.EditorsCanAdmin)
 	reqSnapshotPublicModeOrSignedIn := middleware.SnapshotPublicModeOrSignedIn(hs.Cfg)
 	redirectFromLegacyPanelEditURL := middleware.RedirectFromLegacyPanelEditURL(hs.Cfg)
-	authorize := acmiddleware.Middleware(hs.AccessControl)
+	authorize := acmiddleware.RouteMiddleware(hs.AccessControl)
 	authorizeInOrg := acmiddleware.AuthorizeInOrgMiddleware(hs.AccessControl, hs.SQLStore)
 	quota := middleware.Quota(hs.QuotaService)
 
@@ -40,6 +40,9 @@ func (hs *HTTPServer) registerRoutes() {
 	// not logged in views
 	r.Get("/logout", hs.Logout)
 	r.Post("/login", quota("session"), routing.Wrap(hs.LoginPost))
+	if hs.Cfg.TwoFactorEnabled {
+		r.Post("/login/2fa", quota("session"), routing.Wrap(hs.LoginTwoFactorPost))
+	}
 	r.Get("/login/:name", quota("session"), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
@@ -94,6 +97,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/dashboard-solo/snapshot/*", hs.Index)
 	r.Get("/d-solo/:uid/:slug", reqSignedIn, hs.Index)
 	r.Get("/d-solo/:uid", reqSignedIn, hs.Index)
+	r.Get("/d-csv/:uid", reqSignedIn, hs.Index)
 	r.Get("/dashboard-solo/script/*", reqSignedIn, hs.Index)
 	r.Get("/import/dashboard", reqSignedIn, hs.Index)
 	r.Get("/dashboards/", reqSignedIn, hs.Index)
@@ -164,6 +168,14 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Get("/auth-tokens", routing.Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", routing.Wrap(hs.RevokeUserAuthToken))
+
+			if hs.Cfg.TwoFactorEnabled {
+				userRoute.Get("/2fa", routing.Wrap(hs.GetUserTwoFactor))
+				userRoute.Post("/2fa/enroll", routing.Wrap(hs.EnrollUserTwoFactor))
+				userRoute.Post("/2fa/verify", routing.Wrap(hs.VerifyUserTwoFactor))
+				userRoute.Post("/2fa/recovery-codes", routing.Wrap(hs.RegenerateUserTwoFactorRecoveryCodes))
+				userRoute.Post("/2fa/disable", routing.Wrap(hs.DisableUserTwoFactor))
+			}
 		}, reqSignedInNoAnonymous)
 
 		apiRoute.Group("/users", func(usersRoute routing.RouteRegister) {
@@ -198,6 +210,14 @@ func (hs *HTTPServer) registerRoutes() {
 			teamsRoute.Get("/search", routing.Wrap(hs.SearchTeams))
 		})
 
+		// access control simulation
+		apiRoute.Group("/access-control", func(acRoute routing.RouteRegister) {
+			userIDScope := ac.Scope("users", "id", ac.Parameter(":userId"))
+			acRoute.Get("/users/:userId/simulate", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersRolesList, userIDScope)), routing.Wrap(hs.SimulateUserAccess))
+			acRoute.Get("/users/:userId/simulate/dashboards", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionUsersRolesList, userIDScope)), routing.Wrap(hs.SimulateUserDashboardAccess))
+			acRoute.Get("/teams/:teamId/simulate", authorize(reqOrgAdmin, ac.EvalPermission(ac.ActionTeamsRolesList, ac.ScopeTeamsID)), routing.Wrap(hs.SimulateTeamAccess))
+		})
+
 		// org information available to all users.
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
 			orgRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(ActionOrgsRead)), routing.Wrap(GetCurrentOrg))
@@ -277,6 +297,7 @@ func (hs *HTTPServer) registerRoutes() {
 			datasourceRoute.Delete("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceName)), routing.Wrap(hs.DeleteDataSourceByName))
 			datasourceRoute.Get("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead)), routing.Wrap(hs.GetDataSourceById))
 			datasourceRoute.Get("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead)), routing.Wrap(hs.GetDataSourceByUID))
+			datasourceRoute.Get("/uid/:uid/usage", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceUID)), routing.Wrap(hs.GetDataSourceUsage))
 			datasourceRoute.Get("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead)), routing.Wrap(GetDataSourceByName))
 		})
 
@@ -293,6 +314,7 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/plugins", func(pluginRoute routing.RouteRegister) {
 			pluginRoute.Post("/:pluginId/install", routing.Wrap(hs.InstallPlugin))
 			pluginRoute.Post("/:pluginId/uninstall", routing.Wrap(hs.UninstallPlugin))
+			pluginRoute.Get("/:pluginId/usage", routing.Wrap(hs.GetPluginUsage))
 		}, reqGrafanaAdmin)
 
 		apiRoute.Group("/plugins", func(pluginRoute routing.RouteRegister) {
@@ -330,6 +352,7 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/dashboards", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/uid/:uid", routing.Wrap(hs.GetDashboard))
 			dashboardRoute.Delete("/uid/:uid", routing.Wrap(hs.DeleteDashboardByUID))
+			dashboardRoute.Get("/uid/:uid/references", routing.Wrap(hs.GetDashboardReferences))
 
 			if hs.ThumbService != nil {
 				dashboardRoute.Get("/uid/:uid/img/:size/:theme", hs.ThumbService.GetImage)
@@ -337,12 +360,19 @@ func (hs *HTTPServer) registerRoutes() {
 			}
 
 			dashboardRoute.Post("/calculate-diff", routing.Wrap(CalculateDashboardDiff))
+			dashboardRoute.Post("/merge", routing.Wrap(hs.MergeDashboard))
 			dashboardRoute.Post("/trim", routing.Wrap(hs.TrimDashboard))
 
 			dashboardRoute.Post("/db", routing.Wrap(hs.PostDashboard))
 			dashboardRoute.Get("/home", routing.Wrap(hs.GetHomeDashboard))
 			dashboardRoute.Get("/tags", GetDashboardTags)
 
+			dashboardRoute.Group("/trash", func(trashRoute routing.RouteRegister) {
+				trashRoute.Get("/", authorize(reqSignedIn, ac.EvalPermission(ActionDashboardsTrashRead)), routing.Wrap(hs.GetDeletedDashboards))
+				trashRoute.Post("/:dashboardId/restore", authorize(reqEditorRole, ac.EvalPermission(ActionDashboardsTrashRestore)), routing.Wrap(hs.RestoreDeletedDashboard))
+				trashRoute.Delete("/:dashboardId", authorize(reqOrgAdmin, ac.EvalPermission(ActionDashboardsTrashDelete)), routing.Wrap(hs.PurgeDeletedDashboard))
+			})
+
 			dashboardRoute.Group("/id/:dashboardId", func(dashIdRoute routing.RouteRegister) {
 				dashIdRoute.Get("/versions", routing.Wrap(GetDashboardVersions))
 				dashIdRoute.Get("/versions/:id", routing.Wrap(GetDashboardVersion))
@@ -363,6 +393,11 @@ func (hs *HTTPServer) registerRoutes() {
 		// Playlist
 		apiRoute.Group("/playlists", func(playlistRoute routing.RouteRegister) {
 			playlistRoute.Get("/", routing.Wrap(hs.SearchPlaylists))
+			playlistRoute.Get("/uid/:uid", routing.Wrap(hs.GetPlaylistByUID))
+			playlistRoute.Get("/uid/:uid/items", routing.Wrap(hs.GetPlaylistItemsByUID))
+			playlistRoute.Get("/uid/:uid/dashboards", routing.Wrap(hs.GetPlaylistDashboardsByUID))
+			playlistRoute.Delete("/uid/:uid", reqEditorRole, routing.Wrap(hs.DeletePlaylistByUID))
+			playlistRoute.Put("/uid/:uid", reqEditorRole, routing.Wrap(hs.UpdatePlaylistByUID))
 			playlistRoute.Get("/:id", hs.ValidateOrgPlaylist, routing.Wrap(hs.GetPlaylist))
 			playlistRoute.Get("/:id/items", hs.ValidateOrgPlaylist, routing.Wrap(hs.GetPlaylistItems))
 			playlistRoute.Get("/:id/dashboards", hs.ValidateOrgPlaylist, routing.Wrap(hs.GetPlaylistDashboards))
@@ -373,6 +408,7 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// Search
 		apiRoute.Get("/search/sorting", routing.Wrap(hs.ListSortOptions))
+		apiRoute.Get("/search/fulltext", routing.Wrap(hs.FullTextSearch))
 		apiRoute.Get("/search/", routing.Wrap(Search))
 
 		// metrics
@@ -455,6 +491,9 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// short urls
 		apiRoute.Post("/short-urls", routing.Wrap(hs.createShortURL))
+		apiRoute.Get("/short-urls", reqOrgAdmin, routing.Wrap(hs.searchShortURLs))
+		apiRoute.Put("/short-urls/:uid", routing.Wrap(hs.updateShortURL))
+		apiRoute.Get("/short-urls/:uid/stats", routing.Wrap(hs.getShortURLStats))
 	}, reqSignedIn)
 
 	// admin api
@@ -499,6 +538,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminUserRoute.Post("/:id/logout", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersLogout, userIDScope)), routing.Wrap(hs.AdminLogoutUser))
 		adminUserRoute.Get("/:id/auth-tokens", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersAuthTokenList, userIDScope)), routing.Wrap(hs.AdminGetUserAuthTokens))
 		adminUserRoute.Post("/:id/revoke-auth-token", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, userIDScope)), routing.Wrap(hs.AdminRevokeUserAuthToken))
+		adminUserRoute.Delete("/:id/2fa", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersTwoFactorReset, userIDScope)), routing.Wrap(hs.AdminResetUserTwoFactor))
 	})
 
 	// rendering
@@ -517,6 +557,8 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/api/snapshots/:key", routing.Wrap(GetDashboardSnapshot))
 	r.Get("/api/snapshots-delete/:deleteKey", reqSnapshotPublicModeOrSignedIn, routing.Wrap(DeleteDashboardSnapshotByDeleteKey))
 	r.Delete("/api/snapshots/:key", reqEditorRole, routing.Wrap(DeleteDashboardSnapshot))
+	r.Put("/api/snapshots/:key/visibility", reqSignedIn, routing.Wrap(UpdateDashboardSnapshotVisibility))
+	r.Get("/api/snapshots/:key/views", reqSignedIn, routing.Wrap(GetDashboardSnapshotViews))
 
 	// Frontend logs
 	sourceMapStore := frontendlogging.NewSourceMapStore(hs.Cfg, hs.pluginStaticRouteResolver, frontendlogging.ReadSourceMapFromFS)
@@ -620,7 +620,7 @@ func CalculateDashboardDiff(c *models.ReqContext) response.Response {
 		return response.Error(500, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffSemantic {
 		return response.Respond(200, result.Delta).SetHeader("Content-Type", "application/json")
 	}
 
@@ -668,6 +668,56 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *models.ReqContext) response.Res
 	return hs.postDashboard(c, saveCmd)
 }
 
+// MergeDashboard merges a dashboard edited from a previous version with the version saved in the meantime. It
+// responds with 409 and the conflicts when the changes cannot all be merged.
+// POST /api/dashboards/merge
+func (hs *HTTPServer) MergeDashboard(c *models.ReqContext) response.Response {
+	cmd := dtos.MergeDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	edited := models.NewDashboardFromJson(cmd.Dashboard)
+	if edited.Id == 0 && edited.Uid == "" {
+		return response.Error(http.StatusBadRequest, "Dashboard id or uid is required", nil)
+	}
+
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, edited.Id, edited.Uid)
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	versionQuery := models.GetDashboardVersionQuery{DashboardId: dash.Id, Version: edited.Version, OrgId: c.OrgId}
+	if err := bus.Dispatch(c.Req.Context(), &versionQuery); err != nil {
+		if errors.Is(err, models.ErrDashboardVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard version not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard version", err)
+	}
+
+	merged, err := dashdiffs.Merge(versionQuery.Result.Data, cmd.Dashboard, dash.Data)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to merge dashboard", err)
+	}
+	merged.Dashboard.Set("version", dash.Version)
+
+	changes, err := dashdiffs.SemanticDiff(dash.Data, merged.Dashboard)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	}
+
+	result := dtos.MergeDashboardResult{Dashboard: merged.Dashboard, Conflicts: merged.Conflicts, Changes: changes}
+	if len(result.Conflicts) > 0 {
+		return response.JSON(http.StatusConflict, result)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 func GetDashboardTags(c *models.ReqContext) {
 	query := models.GetDashboardTagsQuery{OrgId: c.OrgId}
 	err := bus.Dispatch(c.Req.Context(), &query)
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// usageSearchBatchSize is the number of dashboards checked at once for the permissions of the user.
+const usageSearchBatchSize = 500
+
+// GET /api/dashboards/uid/:uid/references
+func (hs *HTTPServer) GetDashboardReferences(c *models.ReqContext) response.Response {
+	dash, rsp := getDashboardHelper(c.Req.Context(), c.OrgId, 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	query := models.GetDashboardReferencesQuery{DashboardId: dash.Id, OrgId: c.OrgId}
+	if err := hs.SQLStore.GetDashboardReferences(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard references", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
+
+// GET /api/datasources/uid/:uid/usage
+func (hs *HTTPServer) GetDataSourceUsage(c *models.ReqContext) response.Response {
+	ds, err := getRawDataSourceByUID(c.Req.Context(), web.Params(c.Req)[":uid"], c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
+	}
+
+	query := models.GetDatasourceUsageQuery{
+		OrgId:     c.OrgId,
+		Uid:       ds.Uid,
+		Name:      ds.Name,
+		Type:      ds.Type,
+		IsDefault: ds.IsDefault,
+	}
+	if err := hs.SQLStore.GetDatasourceUsage(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get data source usage", err)
+	}
+
+	return hs.dashboardUsageResponse(c, query.Result)
+}
+
+// GET /api/plugins/:pluginId/usage
+func (hs *HTTPServer) GetPluginUsage(c *models.ReqContext) response.Response {
+	query := models.GetPluginUsageQuery{OrgId: c.OrgId, PluginId: web.Params(c.Req)[":pluginId"]}
+	if err := hs.SQLStore.GetPluginUsage(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get plugin usage", err)
+	}
+
+	return hs.dashboardUsageResponse(c, query.Result)
+}
+
+// dashboardUsageResponse groups references by dashboard. Every dashboard is counted but only the ones that the user
+// can view are listed.
+func (hs *HTTPServer) dashboardUsageResponse(c *models.ReqContext, refs []*models.DashboardReference) response.Response {
+	byDashboard := make(map[int64][]*models.DashboardReference)
+	ids := make([]int64, 0)
+	for _, ref := range refs {
+		if _, ok := byDashboard[ref.DashboardId]; !ok {
+			ids = append(ids, ref.DashboardId)
+		}
+		byDashboard[ref.DashboardId] = append(byDashboard[ref.DashboardId], ref)
+	}
+
+	usage := dtos.DashboardUsage{Total: len(byDashboard), Dashboards: make([]*dtos.DashboardUsageItem, 0)}
+	for start := 0; start < len(ids); start += usageSearchBatchSize {
+		end := start + usageSearchBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		query := search.FindPersistedDashboardsQuery{
+			OrgId:        c.OrgId,
+			SignedInUser: c.SignedInUser,
+			DashboardIds: ids[start:end],
+			Type:         string(search.DashHitDB),
+			Permission:   models.PERMISSION_VIEW,
+			Limit:        int64(end - start),
+		}
+		if err := hs.SQLStore.SearchDashboards(c.Req.Context(), &query); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to search dashboards", err)
+		}
+
+		for _, hit := range query.Result {
+			usage.Dashboards = append(usage.Dashboards, &dtos.DashboardUsageItem{
+				Id:          hit.ID,
+				Uid:         hit.UID,
+				Title:       hit.Title,
+				Url:         hit.URL,
+				FolderId:    hit.FolderID,
+				FolderUid:   hit.FolderUID,
+				FolderTitle: hit.FolderTitle,
+				References:  byDashboard[hit.ID],
+			})
+		}
+	}
+
+	return response.JSON(http.StatusOK, usage)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -85,6 +86,16 @@ func CreateDashboardSnapshot(c *models.ReqContext) response.Response {
 		cmd.Name = "Unnamed snapshot"
 	}
 
+	if cmd.Visibility == "" {
+		cmd.Visibility = models.SnapshotVisibilityPublic
+	}
+	if err := validateSnapshotVisibility(cmd.Visibility, cmd.TeamIds); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	if cmd.Visibility != models.SnapshotVisibilityPublic && (cmd.External || !c.IsSignedIn || c.IsAnonymous) {
+		return response.Error(http.StatusBadRequest, "Only snapshots created by signed in users and stored locally can be restricted", nil)
+	}
+
 	var url string
 	cmd.ExternalUrl = ""
 	cmd.OrgId = c.OrgId
@@ -156,7 +167,7 @@ func GetDashboardSnapshot(c *models.ReqContext) response.Response {
 		return response.Error(404, "Snapshot not found", nil)
 	}
 
-	query := &models.GetDashboardSnapshotQuery{Key: key}
+	query := &models.GetDashboardSnapshotQuery{Key: key, WithoutDashboard: true}
 
 	err := bus.Dispatch(c.Req.Context(), query)
 	if err != nil {
@@ -170,6 +181,22 @@ func GetDashboardSnapshot(c *models.ReqContext) response.Response {
 		return response.Error(404, "Dashboard snapshot not found", err)
 	}
 
+	canView, err := canViewDashboardSnapshot(c, snapshot)
+	if err != nil {
+		return response.Error(500, "Error while checking permissions for snapshot", err)
+	}
+	if !canView {
+		if !c.IsSignedIn || c.IsAnonymous {
+			return response.Error(401, "Sign in to view this snapshot", nil)
+		}
+		return response.Error(403, "Access denied to this snapshot", nil)
+	}
+
+	// the dashboard is only loaded once access is granted, it can live in the blob storage
+	if err := bus.Dispatch(c.Req.Context(), &models.LoadDashboardSnapshotQuery{Snapshot: snapshot}); err != nil {
+		return response.Error(500, "Failed to get dashboard snapshot", err)
+	}
+
 	dto := dtos.DashboardFullWithMeta{
 		Dashboard: snapshot.Dashboard,
 		Meta: dtos.DashboardMeta{
@@ -182,9 +209,72 @@ func GetDashboardSnapshot(c *models.ReqContext) response.Response {
 
 	metrics.MApiDashboardSnapshotGet.Inc()
 
+	view := &models.RecordDashboardSnapshotViewCommand{SnapshotId: snapshot.Id, OrgId: snapshot.OrgId, UserId: c.UserId}
+	if err := bus.Dispatch(c.Req.Context(), view); err != nil {
+		plog.Warn("Failed to record dashboard snapshot view", "snapshotId", snapshot.Id, "err", err)
+	}
+
+	if !snapshot.IsPublic() {
+		return response.JSON(200, dto).SetHeader("Cache-Control", "private, no-store")
+	}
 	return response.JSON(200, dto).SetHeader("Cache-Control", "public, max-age=3600")
 }
 
+// canViewDashboardSnapshot checks the visibility of a snapshot, its owner and the admins of its organization can always view it.
+func canViewDashboardSnapshot(c *models.ReqContext, snapshot *models.DashboardSnapshot) (bool, error) {
+	if snapshot.IsPublic() {
+		return true, nil
+	}
+	if !c.IsSignedIn || c.IsAnonymous || c.OrgId != snapshot.OrgId {
+		return false, nil
+	}
+	if snapshot.UserId == c.UserId || c.OrgRole == models.ROLE_ADMIN || snapshot.Visibility == models.SnapshotVisibilityOrg {
+		return true, nil
+	}
+	if snapshot.Visibility != models.SnapshotVisibilityTeams {
+		return false, nil
+	}
+
+	query := models.GetTeamsByUserQuery{OrgId: snapshot.OrgId, UserId: c.UserId}
+	if err := bus.Dispatch(c.Req.Context(), &query); err != nil {
+		return false, err
+	}
+	for _, team := range query.Result {
+		for _, teamID := range snapshot.TeamIds {
+			if team.Id == teamID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// canEditDashboardSnapshot returns true if the user created the snapshot or can edit its dashboard.
+func canEditDashboardSnapshot(c *models.ReqContext, snapshot *models.DashboardSnapshot) (bool, error) {
+	// snapshots created anonymously in public mode have no owner
+	if snapshot.UserId != 0 && snapshot.UserId == c.SignedInUser.UserId {
+		return true, nil
+	}
+
+	dashboardID := snapshot.Dashboard.Get("id").MustInt64()
+
+	guardian := guardian.New(c.Req.Context(), dashboardID, c.OrgId, c.SignedInUser)
+	return guardian.CanEdit()
+}
+
+func validateSnapshotVisibility(visibility string, teamIDs []int64) error {
+	switch visibility {
+	case models.SnapshotVisibilityPublic, models.SnapshotVisibilityOrg:
+		return nil
+	case models.SnapshotVisibilityTeams:
+		if len(teamIDs) == 0 {
+			return errors.New("snapshots visible to teams need at least one team")
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid snapshot visibility %q", visibility)
+}
+
 func deleteExternalDashboardSnapshot(externalUrl string) error {
 	response, err := client.Get(externalUrl)
 	if err != nil {
@@ -224,7 +314,7 @@ func DeleteDashboardSnapshotByDeleteKey(c *models.ReqContext) response.Response
 		return response.Error(404, "Snapshot not found", nil)
 	}
 
-	query := &models.GetDashboardSnapshotQuery{DeleteKey: key}
+	query := &models.GetDashboardSnapshotQuery{DeleteKey: key, WithoutDashboard: true}
 
 	err := bus.Dispatch(c.Req.Context(), query)
 	if err != nil {
@@ -267,15 +357,12 @@ func DeleteDashboardSnapshot(c *models.ReqContext) response.Response {
 		return response.Error(404, "Failed to get dashboard snapshot", nil)
 	}
 
-	dashboardID := query.Result.Dashboard.Get("id").MustInt64()
-
-	guardian := guardian.New(c.Req.Context(), dashboardID, c.OrgId, c.SignedInUser)
-	canEdit, err := guardian.CanEdit()
+	canEdit, err := canEditDashboardSnapshot(c, query.Result)
 	if err != nil {
 		return response.Error(500, "Error while checking permissions for snapshot", err)
 	}
 
-	if !canEdit && query.Result.UserId != c.SignedInUser.UserId {
+	if !canEdit {
 		return response.Error(403, "Access denied to this snapshot", nil)
 	}
 
@@ -298,6 +385,80 @@ func DeleteDashboardSnapshot(c *models.ReqContext) response.Response {
 	})
 }
 
+// getEditableDashboardSnapshot returns the snapshot of the request if the user can edit it.
+func getEditableDashboardSnapshot(c *models.ReqContext) (*models.DashboardSnapshot, response.Response) {
+	query := &models.GetDashboardSnapshotQuery{Key: web.Params(c.Req)[":key"]}
+	if err := bus.Dispatch(c.Req.Context(), query); err != nil {
+		if errors.Is(err, models.ErrDashboardSnapshotNotFound) {
+			return nil, response.Error(404, "Dashboard snapshot not found", err)
+		}
+		return nil, response.Error(500, "Failed to get dashboard snapshot", err)
+	}
+	if query.Result.OrgId != c.OrgId {
+		return nil, response.Error(404, "Dashboard snapshot not found", nil)
+	}
+
+	canEdit, err := canEditDashboardSnapshot(c, query.Result)
+	if err != nil {
+		return nil, response.Error(500, "Error while checking permissions for snapshot", err)
+	}
+	if !canEdit {
+		return nil, response.Error(403, "Access denied to this snapshot", nil)
+	}
+	return query.Result, nil
+}
+
+// PUT /api/snapshots/:key/visibility
+func UpdateDashboardSnapshotVisibility(c *models.ReqContext) response.Response {
+	cmd := models.UpdateDashboardSnapshotVisibilityCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := validateSnapshotVisibility(cmd.Visibility, cmd.TeamIds); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	snapshot, errResp := getEditableDashboardSnapshot(c)
+	if errResp != nil {
+		return errResp
+	}
+	if snapshot.External && cmd.Visibility != models.SnapshotVisibilityPublic {
+		return response.Error(http.StatusBadRequest, "External snapshots cannot be restricted", nil)
+	}
+	if cmd.Visibility != models.SnapshotVisibilityTeams {
+		cmd.TeamIds = nil
+	}
+
+	cmd.Key = snapshot.Key
+	if err := bus.Dispatch(c.Req.Context(), &cmd); err != nil {
+		return response.Error(500, "Failed to update dashboard snapshot visibility", err)
+	}
+
+	return response.Success("Snapshot visibility updated")
+}
+
+// GET /api/snapshots/:key/views
+func GetDashboardSnapshotViews(c *models.ReqContext) response.Response {
+	snapshot, errResp := getEditableDashboardSnapshot(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = 100
+	} else if limit > 1000 {
+		limit = 1000
+	}
+
+	query := models.GetDashboardSnapshotViewsQuery{SnapshotId: snapshot.Id, Limit: limit}
+	if err := bus.Dispatch(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to get dashboard snapshot views", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
+
 // GET /api/dashboard/snapshots
 func SearchDashboardSnapshots(c *models.ReqContext) response.Response {
 	query := c.Query("query")
@@ -329,6 +490,8 @@ func SearchDashboardSnapshots(c *models.ReqContext) response.Response {
 			UserId:      snapshot.UserId,
 			External:    snapshot.External,
 			ExternalUrl: snapshot.ExternalUrl,
+			Visibility:  snapshot.Visibility,
+			TeamIds:     snapshot.TeamIds,
 			Expires:     snapshot.Expires,
 			Created:     snapshot.Created,
 			Updated:     snapshot.Updated,
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -50,6 +51,14 @@ func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 			return nil
 		})
 
+		bus.AddHandler("test", func(ctx context.Context, query *models.LoadDashboardSnapshotQuery) error {
+			return nil
+		})
+
+		bus.AddHandler("test", func(ctx context.Context, cmd *models.RecordDashboardSnapshotViewCommand) error {
+			return nil
+		})
+
 		bus.AddHandler("test", func(ctx context.Context, cmd *models.DeleteDashboardSnapshotCommand) error {
 			return nil
 		})
@@ -249,4 +258,78 @@ func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 				assert.Equal(t, int64(100), id.MustInt64())
 			})
 	})
+
+	t.Run("When the snapshot is restricted", func(t *testing.T) {
+		setUpRestrictedSnapshotTest := func(t *testing.T, visibility string, teamIDs []int64) *[]*models.RecordDashboardSnapshotViewCommand {
+			t.Helper()
+
+			mockSnapshotResult := setUpSnapshotTest(t)
+			mockSnapshotResult.External = false
+			mockSnapshotResult.OrgId = testOrgID
+			mockSnapshotResult.Visibility = visibility
+			mockSnapshotResult.TeamIds = teamIDs
+
+			bus.AddHandler("test", func(ctx context.Context, query *models.GetTeamsByUserQuery) error {
+				query.Result = []*models.TeamDTO{{Id: 3, OrgId: testOrgID}}
+				return nil
+			})
+
+			views := make([]*models.RecordDashboardSnapshotViewCommand, 0)
+			bus.AddHandler("test", func(ctx context.Context, cmd *models.RecordDashboardSnapshotViewCommand) error {
+				views = append(views, cmd)
+				return nil
+			})
+			return &views
+		}
+
+		signedIn := func(c *models.ReqContext) response.Response {
+			c.IsSignedIn = true
+			return GetDashboardSnapshot(c)
+		}
+
+		anonymousUserScenario(t, "Should ask anonymous users to sign in when calling GET on",
+			"GET", "/api/snapshots/12345", "/api/snapshots/:key", func(sc *scenarioContext) {
+				views := setUpRestrictedSnapshotTest(t, models.SnapshotVisibilityOrg, nil)
+
+				sc.handlerFunc = GetDashboardSnapshot
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"key": "12345"}).exec()
+
+				assert.Equal(t, 401, sc.resp.Code)
+				assert.Empty(t, *views)
+			})
+
+		loggedInUserScenarioWithRole(t, "Should let members of the organization view the snapshot when calling GET on",
+			"GET", "/api/snapshots/12345", "/api/snapshots/:key", models.ROLE_VIEWER, func(sc *scenarioContext) {
+				views := setUpRestrictedSnapshotTest(t, models.SnapshotVisibilityOrg, nil)
+
+				sc.handlerFunc = signedIn
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"key": "12345"}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+				assert.Equal(t, "private, no-store", sc.resp.Header().Get("Cache-Control"))
+				require.Len(t, *views, 1)
+				assert.Equal(t, testUserID, (*views)[0].UserId)
+			})
+
+		loggedInUserScenarioWithRole(t, "Should let members of the teams view the snapshot when calling GET on",
+			"GET", "/api/snapshots/12345", "/api/snapshots/:key", models.ROLE_VIEWER, func(sc *scenarioContext) {
+				setUpRestrictedSnapshotTest(t, models.SnapshotVisibilityTeams, []int64{2, 3})
+
+				sc.handlerFunc = signedIn
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"key": "12345"}).exec()
+
+				assert.Equal(t, 200, sc.resp.Code)
+			})
+
+		loggedInUserScenarioWithRole(t, "Should deny users outside of the teams when calling GET on",
+			"GET", "/api/snapshots/12345", "/api/snapshots/:key", models.ROLE_VIEWER, func(sc *scenarioContext) {
+				views := setUpRestrictedSnapshotTest(t, models.SnapshotVisibilityTeams, []int64{2})
+
+				sc.handlerFunc = signedIn
+				sc.fakeReqWithParams("GET", sc.url, map[string]string{"key": "12345"}).exec()
+
+				assert.Equal(t, 403, sc.resp.Code)
+				assert.Empty(t, *views)
+			})
+	})
 }
@@ -936,6 +936,77 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			})
 	})
 
+	t.Run("Given dashboard edited while another version was saved", func(t *testing.T) {
+		setUp := func(savedTitle string) {
+			saved := models.NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+				"id":      2,
+				"uid":     "uid",
+				"title":   savedTitle,
+				"version": 2,
+				"panels":  []interface{}{map[string]interface{}{"id": 1, "title": "CPU"}},
+			}))
+			saved.HasAcl = false
+
+			bus.AddHandler("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+				query.Result = saved
+				return nil
+			})
+
+			bus.AddHandler("test", func(ctx context.Context, query *models.GetDashboardVersionQuery) error {
+				if query.Version != 1 {
+					return models.ErrDashboardVersionNotFound
+				}
+				query.Result = &models.DashboardVersion{
+					DashboardId: 2,
+					Version:     1,
+					Data: simplejson.NewFromAny(map[string]interface{}{
+						"id":      2,
+						"uid":     "uid",
+						"title":   "Dash",
+						"version": 1,
+						"panels":  []interface{}{},
+					}),
+				}
+				return nil
+			})
+		}
+
+		cmd := dtos.MergeDashboardCommand{
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{
+				"id":      2,
+				"uid":     "uid",
+				"title":   "Edited dash",
+				"version": 1,
+				"panels":  []interface{}{},
+			}),
+		}
+
+		mergeDashboardScenario(t, "When calling POST on", "/api/dashboards/merge", "/api/dashboards/merge", cmd, func(sc *scenarioContext) {
+			setUp("Dash")
+
+			callPostDashboard(sc)
+			require.Equal(sc.t, 200, sc.resp.Code)
+			result, err := simplejson.NewJson(sc.resp.Body.Bytes())
+			require.NoError(sc.t, err)
+			merged := result.Get("dashboard")
+			assert.Equal(sc.t, "Edited dash", merged.Get("title").MustString())
+			assert.Equal(sc.t, 2, merged.Get("version").MustInt())
+			assert.Len(sc.t, merged.Get("panels").MustArray(), 1)
+			assert.Empty(sc.t, result.Get("conflicts").MustArray())
+			assert.Equal(sc.t, "Dashboard title changed", result.Get("changes").GetIndex(0).Get("description").MustString())
+		})
+
+		mergeDashboardScenario(t, "When calling POST on", "/api/dashboards/merge", "/api/dashboards/merge", cmd, func(sc *scenarioContext) {
+			setUp("Saved dash")
+
+			callPostDashboard(sc)
+			require.Equal(sc.t, 409, sc.resp.Code)
+			result, err := simplejson.NewJson(sc.resp.Body.Bytes())
+			require.NoError(sc.t, err)
+			assert.Equal(sc.t, "Dashboard title changed in both versions", result.Get("conflicts").GetIndex(0).Get("description").MustString())
+		})
+	})
+
 	t.Run("Given provisioned dashboard", func(t *testing.T) {
 		setUp := func() {
 			bus.AddHandler("test", func(ctx context.Context, query *models.GetDashboardsBySlugQuery) error {
@@ -1182,6 +1253,31 @@ func postDiffScenario(t *testing.T, desc string, url string, routePattern string
 	})
 }
 
+func mergeDashboardScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.MergeDashboardCommand, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		defer bus.ClearBusHandlers()
+
+		hs := HTTPServer{Cfg: setting.NewCfg(), Bus: bus.GetBus()}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			sc.context = c
+			sc.context.SignedInUser = &models.SignedInUser{
+				OrgId:  testOrgID,
+				UserId: testUserID,
+			}
+			sc.context.OrgRole = models.ROLE_ADMIN
+
+			return hs.MergeDashboard(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func restoreDashboardVersionScenario(t *testing.T, desc string, url string, routePattern string,
 	mock *dashboards.FakeDashboardService, cmd dtos.RestoreDashboardVersionCommand, fn scenarioFunc) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
@@ -1275,6 +1371,16 @@ func (l *mockLibraryElementService) GetElement(c context.Context, signedInUser *
 	return libraryelements.LibraryElementDTO{}, nil
 }
 
+// PatchElement updates an element from a UID.
+func (l *mockLibraryElementService) PatchElement(c context.Context, signedInUser *models.SignedInUser, cmd libraryelements.PatchLibraryElementCommand, UID string) (libraryelements.LibraryElementDTO, error) {
+	return libraryelements.LibraryElementDTO{}, nil
+}
+
+// DeleteElement deletes an element from a UID.
+func (l *mockLibraryElementService) DeleteElement(c context.Context, signedInUser *models.SignedInUser, UID string) error {
+	return nil
+}
+
 // GetElementsForDashboard gets all connected elements for a specific dashboard.
 func (l *mockLibraryElementService) GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]libraryelements.LibraryElementDTO, error) {
 	return map[string]libraryelements.LibraryElementDTO{}, nil
@@ -1285,6 +1391,11 @@ func (l *mockLibraryElementService) ConnectElementsToDashboard(c context.Context
 	return nil
 }
 
+// ConnectElementVersionsToDashboard connects elements to a specific dashboard, pinning them to a version.
+func (l *mockLibraryElementService) ConnectElementVersionsToDashboard(c context.Context, signedInUser *models.SignedInUser, pinnedVersions map[string]int64, dashboardID int64) error {
+	return nil
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error {
 	return nil
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GET /api/dashboards/trash
+func (hs *HTTPServer) GetDeletedDashboards(c *models.ReqContext) response.Response {
+	query := models.GetDeletedDashboardsQuery{OrgId: c.OrgId}
+	if err := hs.SQLStore.GetDeletedDashboards(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get deleted dashboards", err)
+	}
+
+	// the folder of a deleted dashboard is either in the trash as well or still in use
+	folders := make(map[int64]*models.DeletedDashboard)
+	for _, dash := range query.Result {
+		if dash.IsFolder {
+			folders[dash.Id] = dash
+		}
+	}
+
+	result := make([]*dtos.DeletedDashboard, 0, len(query.Result))
+	for _, dash := range query.Result {
+		g := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+		if canSave, err := g.CanSave(); err != nil || !canSave {
+			continue
+		}
+
+		dto := hs.toDeletedDashboardDTO(dash)
+		if dash.FolderId > 0 {
+			folder, ok := folders[dash.FolderId]
+			if !ok {
+				if f, err := hs.SQLStore.GetDashboard(dash.FolderId, c.OrgId, "", ""); err == nil {
+					folder = &models.DeletedDashboard{Uid: f.Uid, Title: f.Title}
+					folders[dash.FolderId] = folder
+				}
+			}
+			if folder != nil {
+				dto.FolderUid = folder.Uid
+				dto.FolderTitle = folder.Title
+			}
+		}
+		result = append(result, dto)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// POST /api/dashboards/trash/:dashboardId/restore
+func (hs *HTTPServer) RestoreDeletedDashboard(c *models.ReqContext) response.Response {
+	apiCmd := dtos.RestoreDeletedDashboardCommand{}
+	if err := web.Bind(c.Req, &apiCmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	dash, rsp := hs.getDeletedDashboardHelper(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canSave, err := g.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd := models.RestoreDashboardCommand{
+		Id:       dash.Id,
+		OrgId:    c.OrgId,
+		FolderId: dash.FolderId,
+		Uid:      apiCmd.Uid,
+		Title:    apiCmd.Title,
+	}
+
+	if apiCmd.FolderUid != nil && !dash.IsFolder {
+		cmd.FolderId = 0
+		if *apiCmd.FolderUid != "" {
+			folder, err := hs.SQLStore.GetDashboard(0, c.OrgId, *apiCmd.FolderUid, "")
+			if err != nil || !folder.IsFolder {
+				return response.Error(http.StatusBadRequest, models.ErrFolderNotFound.Error(), nil)
+			}
+			cmd.FolderId = folder.Id
+		}
+
+		g := guardian.New(c.Req.Context(), cmd.FolderId, c.OrgId, c.SignedInUser)
+		if canSave, err := g.CanSave(); err != nil || !canSave {
+			return dashboardGuardianResponse(err)
+		}
+	}
+
+	svc := dashboards.NewService(hs.SQLStore)
+	if err := svc.RestoreDashboard(c.Req.Context(), &cmd, c.SignedInUser); err != nil {
+		return apierrors.ToDashboardErrorResponse(c.Req.Context(), hs.pluginStore, err)
+	}
+
+	// library panels were disconnected when the dashboards were deleted
+	for _, restored := range append([]*models.Dashboard{cmd.Result}, cmd.Children...) {
+		if restored.IsFolder {
+			continue
+		}
+		if err := hs.LibraryPanelService.ConnectLibraryPanelsForDashboard(c.Req.Context(), c.SignedInUser, restored); err != nil {
+			return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
+		}
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"id":       cmd.Result.Id,
+		"uid":      cmd.Result.Uid,
+		"title":    cmd.Result.Title,
+		"url":      cmd.Result.GetUrl(),
+		"restored": len(cmd.Children) + 1,
+		"message":  fmt.Sprintf("Dashboard %s restored", cmd.Result.Title),
+	})
+}
+
+// DELETE /api/dashboards/trash/:dashboardId
+func (hs *HTTPServer) PurgeDeletedDashboard(c *models.ReqContext) response.Response {
+	dash, rsp := hs.getDeletedDashboardHelper(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	g := guardian.New(c.Req.Context(), dash.Id, c.OrgId, c.SignedInUser)
+	if canAdmin, err := g.CanAdmin(); err != nil || !canAdmin {
+		return dashboardGuardianResponse(err)
+	}
+
+	if err := hs.SQLStore.PurgeDashboard(c.Req.Context(), &models.PurgeDashboardCommand{Id: dash.Id, OrgId: c.OrgId}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to permanently delete dashboard", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"id":      dash.Id,
+		"title":   dash.Title,
+		"message": fmt.Sprintf("Dashboard %s permanently deleted", dash.Title),
+	})
+}
+
+func (hs *HTTPServer) getDeletedDashboardHelper(c *models.ReqContext) (*models.DeletedDashboard, response.Response) {
+	dashboardID, err := strconv.ParseInt(web.Params(c.Req)[":dashboardId"], 10, 64)
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, "dashboardId is invalid", err)
+	}
+
+	query := models.GetDeletedDashboardQuery{Id: dashboardID, OrgId: c.OrgId}
+	if err := hs.SQLStore.GetDeletedDashboard(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, response.Error(http.StatusNotFound, "Deleted dashboard not found", err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Failed to get deleted dashboard", err)
+	}
+
+	return query.Result, nil
+}
+
+func (hs *HTTPServer) toDeletedDashboardDTO(dash *models.DeletedDashboard) *dtos.DeletedDashboard {
+	dto := &dtos.DeletedDashboard{
+		Id:       dash.Id,
+		Uid:      dash.Uid,
+		Title:    dash.Title,
+		IsFolder: dash.IsFolder,
+		FolderId: dash.FolderId,
+		Deleted:  dash.Deleted,
+	}
+
+	if hs.Cfg.DeletedDashboardRetention > 0 {
+		expires := dash.Deleted.Add(hs.Cfg.DeletedDashboardRetention)
+		dto.Expires = &expires
+	}
+
+	return dto
+}
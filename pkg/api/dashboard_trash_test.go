@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+func TestDashboardTrashAPI(t *testing.T) {
+	sc := setupHTTPServer(t, false, false)
+	setInitCtxSignedInOrgAdmin(sc.initCtx)
+	sc.hs.LibraryPanelService = &mockLibraryPanelService{}
+	sc.hs.Cfg.DeletedDashboardRetention = 24 * time.Hour
+
+	ctx := context.Background()
+	dash, err := sc.db.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     sc.initCtx.OrgId,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "trashed"}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, sc.db.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: dash.OrgId}))
+
+	t.Run("should list deleted dashboards", func(t *testing.T) {
+		recorder := callAPI(sc.server, http.MethodGet, "/api/dashboards/trash", nil, t)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var result []dtos.DeletedDashboard
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+		require.Len(t, result, 1)
+		assert.Equal(t, dash.Uid, result[0].Uid)
+		assert.Equal(t, "trashed", result[0].Title)
+		require.NotNil(t, result[0].Expires)
+		assert.True(t, result[0].Expires.After(result[0].Deleted))
+	})
+
+	t.Run("should restore a deleted dashboard", func(t *testing.T) {
+		_, err := sc.db.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     dash.OrgId,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": dash.Uid, "title": "reused"}),
+		})
+		require.NoError(t, err)
+
+		path := fmt.Sprintf("/api/dashboards/trash/%d/restore", dash.Id)
+		recorder := callAPI(sc.server, http.MethodPost, path, strings.NewReader(`{}`), t)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		recorder = callAPI(sc.server, http.MethodPost, path, strings.NewReader(`{"uid":"restored"}`), t)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		restored, err := sc.db.GetDashboard(0, dash.OrgId, "restored", "")
+		require.NoError(t, err)
+		assert.Equal(t, "trashed", restored.Title)
+	})
+
+	t.Run("should permanently delete a deleted dashboard", func(t *testing.T) {
+		require.NoError(t, sc.db.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: dash.OrgId}))
+
+		path := fmt.Sprintf("/api/dashboards/trash/%d", dash.Id)
+		recorder := callAPI(sc.server, http.MethodDelete, path, nil, t)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = callAPI(sc.server, http.MethodDelete, path, nil, t)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestDashboardTrashAPI_Authorization(t *testing.T) {
+	for _, enableAccessControl := range []bool{false, true} {
+		t.Run(fmt.Sprintf("access control enabled: %t", enableAccessControl), func(t *testing.T) {
+			sc := setupHTTPServer(t, false, enableAccessControl)
+			sc.hs.LibraryPanelService = &mockLibraryPanelService{}
+
+			ctx := context.Background()
+			dash, err := sc.db.SaveDashboard(models.SaveDashboardCommand{
+				OrgId:     1,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "trashed"}),
+			})
+			require.NoError(t, err)
+			require.NoError(t, sc.db.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: dash.OrgId}))
+
+			restorePath := fmt.Sprintf("/api/dashboards/trash/%d/restore", dash.Id)
+			purgePath := fmt.Sprintf("/api/dashboards/trash/%d", dash.Id)
+
+			t.Run("viewer can list but not restore or purge", func(t *testing.T) {
+				setInitCtxSignedInViewer(sc.initCtx)
+
+				recorder := callAPI(sc.server, http.MethodGet, "/api/dashboards/trash", nil, t)
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				recorder = callAPI(sc.server, http.MethodPost, restorePath, strings.NewReader(`{}`), t)
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+				recorder = callAPI(sc.server, http.MethodDelete, purgePath, nil, t)
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			})
+
+			t.Run("editor can restore but not purge", func(t *testing.T) {
+				origNewGuardian := guardian.New
+				t.Cleanup(func() {
+					guardian.New = origNewGuardian
+				})
+				guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+				setInitCtxSignedInEditor(sc.initCtx)
+
+				recorder := callAPI(sc.server, http.MethodDelete, purgePath, nil, t)
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+				recorder = callAPI(sc.server, http.MethodPost, restorePath, strings.NewReader(`{}`), t)
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			})
+		})
+	}
+}
@@ -3,7 +3,9 @@ package dtos
 import (
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
 )
 
 type DashboardMeta struct {
@@ -59,6 +61,58 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+// MergeDashboardCommand holds a dashboard edited from a version that is no longer the saved one.
+type MergeDashboardCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+}
+
+type MergeDashboardResult struct {
+	// Dashboard is the edited dashboard merged with the saved one. It has the saved version.
+	Dashboard *simplejson.Json      `json:"dashboard"`
+	Conflicts []*dashdiffs.Conflict `json:"conflicts"`
+	// Changes are the changes the merged dashboard makes to the saved one.
+	Changes []*dashdiffs.SemanticChange `json:"changes"`
+}
+
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+// DeletedDashboard is a dashboard or folder in the trash
+type DeletedDashboard struct {
+	Id          int64      `json:"id"`
+	Uid         string     `json:"uid"`
+	Title       string     `json:"title"`
+	IsFolder    bool       `json:"isFolder"`
+	FolderId    int64      `json:"folderId"`
+	FolderUid   string     `json:"folderUid"`
+	FolderTitle string     `json:"folderTitle"`
+	Deleted     time.Time  `json:"deleted"`
+	Expires     *time.Time `json:"expires,omitempty"`
+}
+
+type RestoreDeletedDashboardCommand struct {
+	// Uid and Title replace the ones the dashboard had when it was deleted, to resolve conflicts
+	Uid   string `json:"uid"`
+	Title string `json:"title"`
+	// FolderUid moves the dashboard to another folder, an empty string being the General folder
+	FolderUid *string `json:"folderUid"`
+}
+
+// DashboardUsage lists the dashboards using a data source or a plugin
+type DashboardUsage struct {
+	// Total is the number of dashboards using it, including the ones the user cannot view
+	Total      int                   `json:"total"`
+	Dashboards []*DashboardUsageItem `json:"dashboards"`
+}
+
+type DashboardUsageItem struct {
+	Id          int64                        `json:"id"`
+	Uid         string                       `json:"uid"`
+	Title       string                       `json:"title"`
+	Url         string                       `json:"url"`
+	FolderId    int64                        `json:"folderId"`
+	FolderUid   string                       `json:"folderUid"`
+	FolderTitle string                       `json:"folderTitle"`
+	References  []*models.DashboardReference `json:"references"`
+}
@@ -25,6 +25,15 @@ type LoginCommand struct {
 	Remember bool   `json:"remember"`
 }
 
+type LoginTwoFactorCommand struct {
+	Token string `json:"token" binding:"Required"`
+	Code  string `json:"code" binding:"Required"`
+}
+
+type TwoFactorCodeForm struct {
+	Code string `json:"code" binding:"Required"`
+}
+
 type CurrentUser struct {
 	IsSignedIn                 bool               `json:"isSignedIn"`
 	Id                         int64              `json:"id"`
@@ -2,11 +2,14 @@ package dtos
 
 type PlaylistDashboard struct {
 	Id    int64  `json:"id"`
+	Uid   string `json:"uid"`
 	Slug  string `json:"slug"`
 	Title string `json:"title"`
 	Uri   string `json:"uri"`
 	Url   string `json:"url"`
 	Order int    `json:"order"`
+	// Interval overrides the interval of the playlist when set.
+	Interval string `json:"interval,omitempty"`
 }
 
 type PlaylistDashboardsSlice []PlaylistDashboard
@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type ShortURL struct {
 	UID string `json:"uid"`
 	URL string `json:"url"`
@@ -7,4 +9,40 @@ type ShortURL struct {
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// UID is an alias for the short URL, e.g. oncall-overview for /goto/oncall-overview. Generated when empty.
+	UID string `json:"uid"`
+	// Expires is the number of seconds after which the short URL expires.
+	Expires int64 `json:"expires"`
+	Pinned  bool  `json:"pinned"`
+}
+
+type UpdateShortURLCmd struct {
+	// Expires is the number of seconds from now after which the short URL expires, 0 removes the expiry.
+	Expires int64 `json:"expires"`
+	Pinned  bool  `json:"pinned"`
+}
+
+type ShortURLDetails struct {
+	UID            string     `json:"uid"`
+	URL            string     `json:"url"`
+	Path           string     `json:"path"`
+	CreatedBy      int64      `json:"createdBy"`
+	CreatedByLogin string     `json:"createdByLogin"`
+	Created        time.Time  `json:"created"`
+	LastSeen       *time.Time `json:"lastSeen"`
+	Expires        *time.Time `json:"expires"`
+	Pinned         bool       `json:"pinned"`
+	Hits           int64      `json:"hits"`
+}
+
+type ShortURLDailyHits struct {
+	Day  string `json:"day"`
+	Hits int64  `json:"hits"`
+}
+
+type ShortURLStats struct {
+	UID      string              `json:"uid"`
+	Hits     int64               `json:"hits"`
+	LastSeen *time.Time          `json:"lastSeen"`
+	Daily    []ShortURLDailyHits `json:"daily"`
 }
@@ -13,4 +13,7 @@ type UserToken struct {
 	BrowserVersion         string    `json:"browserVersion"`
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
+	LastSeenAt             time.Time `json:"lastSeenAt"`
+	Location               string    `json:"location,omitempty"`
+	CountryCode            string    `json:"countryCode,omitempty"`
 }
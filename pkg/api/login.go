@@ -229,20 +229,29 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext) response.Response {
 
 	user = authQuery.User
 
-	err = hs.loginUserWithUser(user, c)
+	if authModule == "grafana" && hs.TwoFactorService != nil && hs.TwoFactorService.IsEnabled() {
+		if resp = hs.twoFactorChallenge(c, user); resp != nil {
+			return resp
+		}
+	}
+
+	resp = hs.completeLogin(c, user, map[string]interface{}{})
+	return resp
+}
+
+// completeLogin creates the session of the user and returns the login response
+// with the fields of result.
+func (hs *HTTPServer) completeLogin(c *models.ReqContext, user *models.User, result map[string]interface{}) *response.NormalResponse {
+	err := hs.loginUserWithUser(user, c)
 	if err != nil {
 		var createTokenErr *models.CreateTokenErr
 		if errors.As(err, &createTokenErr) {
-			resp = response.Error(createTokenErr.StatusCode, createTokenErr.ExternalErr, createTokenErr.InternalErr)
-		} else {
-			resp = response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+			return response.Error(createTokenErr.StatusCode, createTokenErr.ExternalErr, createTokenErr.InternalErr)
 		}
-		return resp
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
 	}
 
-	result := map[string]interface{}{
-		"message": "Logged in",
-	}
+	result["message"] = "Logged in"
 
 	if redirectTo := c.GetCookie("redirect_to"); len(redirectTo) > 0 {
 		if err := hs.ValidateRedirectTo(redirectTo); err == nil {
@@ -254,8 +263,7 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext) response.Response {
 	}
 
 	metrics.MApiLoginPost.Inc()
-	resp = response.JSON(http.StatusOK, result)
-	return resp
+	return response.JSON(http.StatusOK, result)
 }
 
 func (hs *HTTPServer) loginUserWithUser(user *models.User, c *models.ReqContext) error {
@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/web"
@@ -68,13 +70,40 @@ func (hs *HTTPServer) GetPlaylist(c *models.ReqContext) response.Response {
 		return response.Error(500, "Playlist not found", err)
 	}
 
-	playlistDTOs, _ := hs.LoadPlaylistItemDTOs(c.Req.Context(), id)
+	return hs.playlistResponse(c.Req.Context(), cmd.Result)
+}
+
+// GetPlaylistByUID returns a playlist by its uid.
+func (hs *HTTPServer) GetPlaylistByUID(c *models.ReqContext) response.Response {
+	playlist, errResp := hs.getPlaylistByUID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	return hs.playlistResponse(c.Req.Context(), playlist)
+}
+
+func (hs *HTTPServer) getPlaylistByUID(c *models.ReqContext) (*models.Playlist, response.Response) {
+	query := models.GetPlaylistByUidQuery{Uid: web.Params(c.Req)[":uid"], OrgId: c.OrgId}
+	if err := hs.SQLStore.GetPlaylistByUid(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrPlaylistNotFound) {
+			return nil, response.Error(404, "Playlist not found", err)
+		}
+		return nil, response.Error(500, "Failed to get playlist", err)
+	}
+
+	return query.Result, nil
+}
+
+func (hs *HTTPServer) playlistResponse(ctx context.Context, playlist *models.Playlist) response.Response {
+	playlistDTOs, _ := hs.LoadPlaylistItemDTOs(ctx, playlist.Id)
 
 	dto := &models.PlaylistDTO{
-		Id:       cmd.Result.Id,
-		Name:     cmd.Result.Name,
-		Interval: cmd.Result.Interval,
-		OrgId:    cmd.Result.OrgId,
+		Id:       playlist.Id,
+		Uid:      playlist.Uid,
+		Name:     playlist.Name,
+		Interval: playlist.Interval,
+		OrgId:    playlist.OrgId,
 		Items:    playlistDTOs,
 	}
 
@@ -98,6 +127,8 @@ func (hs *HTTPServer) LoadPlaylistItemDTOs(ctx context.Context, id int64) ([]mod
 			Value:      item.Value,
 			Order:      item.Order,
 			Title:      item.Title,
+			Interval:   item.Interval,
+			Options:    item.Options,
 		})
 	}
 
@@ -128,6 +159,21 @@ func (hs *HTTPServer) GetPlaylistItems(c *models.ReqContext) response.Response {
 	return response.JSON(200, playlistDTOs)
 }
 
+// GetPlaylistItemsByUID returns the items of a playlist by its uid.
+func (hs *HTTPServer) GetPlaylistItemsByUID(c *models.ReqContext) response.Response {
+	playlist, errResp := hs.getPlaylistByUID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	playlistDTOs, err := hs.LoadPlaylistItemDTOs(c.Req.Context(), playlist.Id)
+	if err != nil {
+		return response.Error(500, "Could not load playlist items", err)
+	}
+
+	return response.JSON(200, playlistDTOs)
+}
+
 func (hs *HTTPServer) GetPlaylistDashboards(c *models.ReqContext) response.Response {
 	playlistID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
 	if err != nil {
@@ -142,12 +188,41 @@ func (hs *HTTPServer) GetPlaylistDashboards(c *models.ReqContext) response.Respo
 	return response.JSON(200, playlists)
 }
 
+// GetPlaylistDashboardsByUID returns the dashboards a playlist resolves to, by its uid.
+func (hs *HTTPServer) GetPlaylistDashboardsByUID(c *models.ReqContext) response.Response {
+	playlist, errResp := hs.getPlaylistByUID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	playlists, err := hs.LoadPlaylistDashboards(c.Req.Context(), c.OrgId, c.SignedInUser, playlist.Id)
+	if err != nil {
+		return response.Error(500, "Could not load dashboards", err)
+	}
+
+	return response.JSON(200, playlists)
+}
+
 func (hs *HTTPServer) DeletePlaylist(c *models.ReqContext) response.Response {
 	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
 
+	return hs.deletePlaylist(c, id)
+}
+
+// DeletePlaylistByUID deletes a playlist by its uid.
+func (hs *HTTPServer) DeletePlaylistByUID(c *models.ReqContext) response.Response {
+	playlist, errResp := hs.getPlaylistByUID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	return hs.deletePlaylist(c, playlist.Id)
+}
+
+func (hs *HTTPServer) deletePlaylist(c *models.ReqContext, id int64) response.Response {
 	cmd := models.DeletePlaylistCommand{Id: id, OrgId: c.OrgId}
 	if err := hs.SQLStore.DeletePlaylist(c.Req.Context(), &cmd); err != nil {
 		return response.Error(500, "Failed to delete playlist", err)
@@ -163,7 +238,17 @@ func (hs *HTTPServer) CreatePlaylist(c *models.ReqContext) response.Response {
 	}
 	cmd.OrgId = c.OrgId
 
+	if err := validatePlaylistItems(cmd.Items); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
 	if err := hs.SQLStore.CreatePlaylist(c.Req.Context(), &cmd); err != nil {
+		switch {
+		case errors.Is(err, models.ErrPlaylistInvalidUID), errors.Is(err, models.ErrPlaylistUIDTooLong):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, models.ErrPlaylistWithSameUIDExists):
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
 		return response.Error(500, "Failed to create playlist", err)
 	}
 
@@ -171,15 +256,34 @@ func (hs *HTTPServer) CreatePlaylist(c *models.ReqContext) response.Response {
 }
 
 func (hs *HTTPServer) UpdatePlaylist(c *models.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	return hs.updatePlaylist(c, id)
+}
+
+// UpdatePlaylistByUID updates a playlist by its uid.
+func (hs *HTTPServer) UpdatePlaylistByUID(c *models.ReqContext) response.Response {
+	playlist, errResp := hs.getPlaylistByUID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	return hs.updatePlaylist(c, playlist.Id)
+}
+
+func (hs *HTTPServer) updatePlaylist(c *models.ReqContext, id int64) response.Response {
 	cmd := models.UpdatePlaylistCommand{}
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 	cmd.OrgId = c.OrgId
-	var err error
-	cmd.Id, err = strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
-	if err != nil {
-		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	cmd.Id = id
+
+	if err := validatePlaylistItems(cmd.Items); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
 	}
 
 	if err := hs.SQLStore.UpdatePlaylist(c.Req.Context(), &cmd); err != nil {
@@ -194,3 +298,24 @@ func (hs *HTTPServer) UpdatePlaylist(c *models.ReqContext) response.Response {
 	cmd.Result.Items = playlistDTOs
 	return response.JSON(200, cmd.Result)
 }
+
+func validatePlaylistItems(items []models.PlaylistItemDTO) error {
+	for _, item := range items {
+		switch item.Type {
+		case models.PlaylistItemTypeDashboardByID, models.PlaylistItemTypeDashboardByTag, models.PlaylistItemTypeDashboardByUID:
+		case models.PlaylistItemTypeDashboardSearch:
+			if item.Value == "" && (item.Options == nil || (len(item.Options.FolderUIDs) == 0 && len(item.Options.Tags) == 0)) {
+				return models.ErrPlaylistSearchItemIncomplete
+			}
+		default:
+			return models.ErrPlaylistInvalidItemType
+		}
+
+		if item.Interval != "" {
+			if d, err := gtime.ParseDuration(item.Interval); err != nil || d <= 0 {
+				return models.ErrPlaylistInvalidItemInterval
+			}
+		}
+	}
+	return nil
+}
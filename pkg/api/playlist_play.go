@@ -2,16 +2,22 @@ package api
 
 import (
 	"context"
+	"errors"
+	"net/url"
 	"sort"
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	_ "github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/search"
 )
 
-func (hs *HTTPServer) populateDashboardsByID(ctx context.Context, dashboardByIDs []int64, dashboardIDOrder map[int64]int) (dtos.PlaylistDashboardsSlice, error) {
+// playlistSearchLimit is the maximum number of dashboards a tag or search playlist item resolves to.
+const playlistSearchLimit = 100
+
+func (hs *HTTPServer) populateDashboardsByID(ctx context.Context, dashboardByIDs []int64, dashboardIDItems map[int64]models.PlaylistItem) (dtos.PlaylistDashboardsSlice, error) {
 	result := make(dtos.PlaylistDashboardsSlice, 0)
 
 	if len(dashboardByIDs) > 0 {
@@ -21,43 +27,30 @@ func (hs *HTTPServer) populateDashboardsByID(ctx context.Context, dashboardByIDs
 		}
 
 		for _, item := range dashboardQuery.Result {
-			result = append(result, dtos.PlaylistDashboard{
-				Id:    item.Id,
-				Slug:  item.Slug,
-				Title: item.Title,
-				Uri:   "db/" + item.Slug,
-				Url:   models.GetDashboardUrl(item.Uid, item.Slug),
-				Order: dashboardIDOrder[item.Id],
-			})
+			result = append(result, newPlaylistDashboard(dashboardIDItems[item.Id], item.Id, item.Uid, item.Slug, item.Title,
+				"db/"+item.Slug, models.GetDashboardUrl(item.Uid, item.Slug)))
 		}
 	}
 
 	return result, nil
 }
 
-func (hs *HTTPServer) populateDashboardsByTag(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, dashboardByTag []string, dashboardTagOrder map[string]int) dtos.PlaylistDashboardsSlice {
+func (hs *HTTPServer) populateDashboardsByTag(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, dashboardByTag []models.PlaylistItem) dtos.PlaylistDashboardsSlice {
 	result := make(dtos.PlaylistDashboardsSlice, 0)
 
-	for _, tag := range dashboardByTag {
+	for _, tagItem := range dashboardByTag {
 		searchQuery := search.Query{
 			Title:        "",
-			Tags:         []string{tag},
+			Tags:         []string{tagItem.Value},
 			SignedInUser: signedInUser,
-			Limit:        100,
+			Limit:        playlistSearchLimit,
 			IsStarred:    false,
 			OrgId:        orgID,
 		}
 
 		if err := hs.SearchService.SearchHandler(ctx, &searchQuery); err == nil {
 			for _, item := range searchQuery.Result {
-				result = append(result, dtos.PlaylistDashboard{
-					Id:    item.ID,
-					Slug:  item.Slug,
-					Title: item.Title,
-					Uri:   item.URI,
-					Url:   item.URL,
-					Order: dashboardTagOrder[tag],
-				})
+				result = append(result, newPlaylistDashboard(tagItem, item.ID, item.UID, item.Slug, item.Title, item.URI, item.URL))
 			}
 		}
 	}
@@ -65,33 +58,151 @@ func (hs *HTTPServer) populateDashboardsByTag(ctx context.Context, orgID int64,
 	return result
 }
 
+// populateDashboardByUID resolves a dashboard_by_uid item, dashboards that are missing or that the user cannot view are skipped.
+func (hs *HTTPServer) populateDashboardByUID(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, uidItem models.PlaylistItem) (dtos.PlaylistDashboardsSlice, error) {
+	result := make(dtos.PlaylistDashboardsSlice, 0)
+
+	dash, err := hs.SQLStore.GetDashboard(0, orgID, uidItem.Value, "")
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return result, nil
+		}
+		return result, err
+	}
+	if dash.IsFolder {
+		return result, nil
+	}
+
+	canView, err := guardian.New(ctx, dash.Id, orgID, signedInUser).CanView()
+	if err != nil || !canView {
+		return result, err
+	}
+
+	result = append(result, newPlaylistDashboard(uidItem, dash.Id, dash.Uid, dash.Slug, dash.Title,
+		"db/"+dash.Slug, models.GetDashboardUrl(dash.Uid, dash.Slug)))
+	return result, nil
+}
+
+// populateDashboardsBySearch resolves a dashboard_search item to the dashboards matching its title pattern, folders and tags.
+func (hs *HTTPServer) populateDashboardsBySearch(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, searchItem models.PlaylistItem) (dtos.PlaylistDashboardsSlice, error) {
+	result := make(dtos.PlaylistDashboardsSlice, 0)
+
+	searchQuery := search.Query{
+		Title:        searchItem.Value,
+		SignedInUser: signedInUser,
+		Limit:        playlistSearchLimit,
+		Type:         string(search.DashHitDB),
+		OrgId:        orgID,
+	}
+
+	if searchItem.Options != nil {
+		searchQuery.Tags = searchItem.Options.Tags
+
+		for _, folderUID := range searchItem.Options.FolderUIDs {
+			folder, err := hs.SQLStore.GetDashboard(0, orgID, folderUID, "")
+			if err != nil {
+				if errors.Is(err, models.ErrDashboardNotFound) {
+					continue
+				}
+				return result, err
+			}
+			if folder.IsFolder {
+				searchQuery.FolderIds = append(searchQuery.FolderIds, folder.Id)
+			}
+		}
+
+		// none of the folders exist anymore, searching without them would match every dashboard
+		if len(searchItem.Options.FolderUIDs) > 0 && len(searchQuery.FolderIds) == 0 {
+			return result, nil
+		}
+	}
+
+	if err := hs.SearchService.SearchHandler(ctx, &searchQuery); err != nil {
+		return result, err
+	}
+
+	for _, item := range searchQuery.Result {
+		result = append(result, newPlaylistDashboard(searchItem, item.ID, item.UID, item.Slug, item.Title, item.URI, item.URL))
+	}
+
+	return result, nil
+}
+
 func (hs *HTTPServer) LoadPlaylistDashboards(ctx context.Context, orgID int64, signedInUser *models.SignedInUser, playlistID int64) (dtos.PlaylistDashboardsSlice, error) {
 	playlistItems, _ := hs.LoadPlaylistItems(ctx, playlistID)
 
 	dashboardByIDs := make([]int64, 0)
-	dashboardByTag := make([]string, 0)
-	dashboardIDOrder := make(map[int64]int)
-	dashboardTagOrder := make(map[string]int)
+	dashboardByTag := make([]models.PlaylistItem, 0)
+	dashboardIDItems := make(map[int64]models.PlaylistItem)
+
+	result := make(dtos.PlaylistDashboardsSlice, 0)
 
 	for _, i := range playlistItems {
-		if i.Type == "dashboard_by_id" {
+		switch i.Type {
+		case models.PlaylistItemTypeDashboardByID:
 			dashboardID, _ := strconv.ParseInt(i.Value, 10, 64)
 			dashboardByIDs = append(dashboardByIDs, dashboardID)
-			dashboardIDOrder[dashboardID] = i.Order
-		}
-
-		if i.Type == "dashboard_by_tag" {
-			dashboardByTag = append(dashboardByTag, i.Value)
-			dashboardTagOrder[i.Value] = i.Order
+			dashboardIDItems[dashboardID] = i
+		case models.PlaylistItemTypeDashboardByTag:
+			dashboardByTag = append(dashboardByTag, i)
+		case models.PlaylistItemTypeDashboardByUID:
+			dashboards, err := hs.populateDashboardByUID(ctx, orgID, signedInUser, i)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, dashboards...)
+		case models.PlaylistItemTypeDashboardSearch:
+			dashboards, err := hs.populateDashboardsBySearch(ctx, orgID, signedInUser, i)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, dashboards...)
 		}
 	}
 
-	result := make(dtos.PlaylistDashboardsSlice, 0)
-
-	var k, _ = hs.populateDashboardsByID(ctx, dashboardByIDs, dashboardIDOrder)
+	var k, _ = hs.populateDashboardsByID(ctx, dashboardByIDs, dashboardIDItems)
 	result = append(result, k...)
-	result = append(result, hs.populateDashboardsByTag(ctx, orgID, signedInUser, dashboardByTag, dashboardTagOrder)...)
+	result = append(result, hs.populateDashboardsByTag(ctx, orgID, signedInUser, dashboardByTag)...)
 
-	sort.Sort(result)
+	// stable so that the dashboards an item resolves to keep their search order
+	sort.Stable(result)
 	return result, nil
 }
+
+func newPlaylistDashboard(item models.PlaylistItem, id int64, uid, slug, title, uri, dashboardURL string) dtos.PlaylistDashboard {
+	return dtos.PlaylistDashboard{
+		Id:       id,
+		Uid:      uid,
+		Slug:     slug,
+		Title:    title,
+		Uri:      uri,
+		Url:      playlistDashboardURL(dashboardURL, item.Options),
+		Order:    item.Order,
+		Interval: item.Interval,
+	}
+}
+
+// playlistDashboardURL adds the variable values and time range of a playlist item to the url of a dashboard.
+func playlistDashboardURL(dashboardURL string, options *models.PlaylistItemOptions) string {
+	if options == nil {
+		return dashboardURL
+	}
+
+	params := url.Values{}
+	for name, values := range options.Variables {
+		for _, value := range values {
+			params.Add("var-"+name, value)
+		}
+	}
+	if options.From != "" {
+		params.Set("from", options.From)
+	}
+	if options.To != "" {
+		params.Set("to", options.To)
+	}
+
+	if len(params) == 0 {
+		return dashboardURL
+	}
+	return dashboardURL + "?" + params.Encode()
+}
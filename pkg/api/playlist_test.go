@@ -0,0 +1,48 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestValidatePlaylistItems(t *testing.T) {
+	t.Run("should accept every item type", func(t *testing.T) {
+		err := validatePlaylistItems([]models.PlaylistItemDTO{
+			{Type: models.PlaylistItemTypeDashboardByID, Value: "1"},
+			{Type: models.PlaylistItemTypeDashboardByTag, Value: "wall", Interval: "1m"},
+			{Type: models.PlaylistItemTypeDashboardByUID, Value: "overview", Interval: "30s"},
+			{Type: models.PlaylistItemTypeDashboardSearch, Options: &models.PlaylistItemOptions{Tags: []string{"wall"}}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject invalid items", func(t *testing.T) {
+		testCases := []struct {
+			item models.PlaylistItemDTO
+			err  error
+		}{
+			{item: models.PlaylistItemDTO{Type: "dashboard_by_name", Value: "overview"}, err: models.ErrPlaylistInvalidItemType},
+			{item: models.PlaylistItemDTO{Type: models.PlaylistItemTypeDashboardByUID, Value: "overview", Interval: "soon"}, err: models.ErrPlaylistInvalidItemInterval},
+			{item: models.PlaylistItemDTO{Type: models.PlaylistItemTypeDashboardByUID, Value: "overview", Interval: "0s"}, err: models.ErrPlaylistInvalidItemInterval},
+			{item: models.PlaylistItemDTO{Type: models.PlaylistItemTypeDashboardSearch}, err: models.ErrPlaylistSearchItemIncomplete},
+		}
+
+		for _, tc := range testCases {
+			assert.ErrorIs(t, validatePlaylistItems([]models.PlaylistItemDTO{tc.item}), tc.err)
+		}
+	})
+}
+
+func TestPlaylistDashboardURL(t *testing.T) {
+	assert.Equal(t, "/d/abc/overview", playlistDashboardURL("/d/abc/overview", nil))
+	assert.Equal(t, "/d/abc/overview", playlistDashboardURL("/d/abc/overview", &models.PlaylistItemOptions{Tags: []string{"wall"}}))
+	assert.Equal(t, "/d/abc/overview?from=now-1h&to=now&var-host=a&var-host=b&var-region=eu", playlistDashboardURL("/d/abc/overview", &models.PlaylistItemOptions{
+		Variables: map[string][]string{"region": {"eu"}, "host": {"a", "b"}},
+		From:      "now-1h",
+		To:        "now",
+	}))
+}
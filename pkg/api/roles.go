@@ -24,6 +24,10 @@ const (
 	ActionOrgsQuotasWrite      = "orgs.quotas:write"
 	ActionOrgsDelete           = "orgs:delete"
 	ActionOrgsCreate           = "orgs:create"
+
+	ActionDashboardsTrashRead    = "dashboards.trash:read"
+	ActionDashboardsTrashRestore = "dashboards.trash:restore"
+	ActionDashboardsTrashDelete  = "dashboards.trash:delete"
 )
 
 // API related scopes
@@ -223,10 +227,57 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{string(models.ROLE_ADMIN)},
 	}
 
+	// dashboard permissions still decide which dashboards of the trash a user can see, restore or delete
+	dashboardsTrashReaderRole := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:dashboards.trash:reader",
+			DisplayName: "Dashboard trash reader",
+			Description: "List the deleted dashboards the user can edit.",
+			Group:       "Dashboards",
+			Version:     1,
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionDashboardsTrashRead},
+			},
+		},
+		Grants: []string{string(models.ROLE_VIEWER)},
+	}
+
+	dashboardsTrashRestorerRole := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:dashboards.trash:restorer",
+			DisplayName: "Dashboard trash restorer",
+			Description: "List and restore the deleted dashboards the user can edit.",
+			Group:       "Dashboards",
+			Version:     1,
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionDashboardsTrashRead},
+				{Action: ActionDashboardsTrashRestore},
+			},
+		},
+		Grants: []string{string(models.ROLE_EDITOR)},
+	}
+
+	dashboardsTrashWriterRole := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:dashboards.trash:writer",
+			DisplayName: "Dashboard trash writer",
+			Description: "List, restore and permanently delete the deleted dashboards the user can edit.",
+			Group:       "Dashboards",
+			Version:     2,
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionDashboardsTrashRead},
+				{Action: ActionDashboardsTrashRestore},
+				{Action: ActionDashboardsTrashDelete},
+			},
+		},
+		Grants: []string{string(models.ROLE_ADMIN)},
+	}
+
 	return hs.AccessControl.DeclareFixedRoles(
 		provisioningWriterRole, datasourcesReaderRole, datasourcesWriterRole, datasourcesIdReaderRole,
 		datasourcesCompatibilityReaderRole, orgReaderRole, orgWriterRole,
 		orgMaintainerRole, teamsCreatorRole, teamsWriterRole,
+		dashboardsTrashReaderRole, dashboardsTrashRestorerRole, dashboardsTrashWriterRole,
 	)
 }
 
@@ -48,6 +48,28 @@ type RouteRegister interface {
 
 	// Reset resets the route register.
 	Reset()
+
+	// Lookup returns the route that would serve a request with the given method and path.
+	Lookup(method, path string) (*RouteMatch, bool)
+}
+
+// RouteMatch is a registered route matching a request path
+type RouteMatch struct {
+	Method  string
+	Pattern string
+	// Params holds the url parameters of the path, keyed like web.Params
+	Params   map[string]string
+	Handlers []web.Handler
+	// Metadata holds the metadata of the handlers of the route, in the order of the handlers
+	Metadata []interface{}
+}
+
+// HandlerWithMetadata is a handler along with metadata describing what it enforces, such as the
+// evaluator of an access control handler. The route register records the metadata on the route
+// and only adds the handler to the router.
+type HandlerWithMetadata struct {
+	Handler  web.Handler
+	Metadata interface{}
 }
 
 type RegisterNamedMiddleware func(name string) web.Handler
@@ -70,6 +92,7 @@ type route struct {
 	method   string
 	pattern  string
 	handlers []web.Handler
+	metadata []interface{}
 }
 
 type RouteRegisterImpl struct {
@@ -90,6 +113,88 @@ func (rr *RouteRegisterImpl) Reset() {
 	rr.subfixHandlers = nil
 }
 
+func (rr *RouteRegisterImpl) Lookup(method, path string) (*RouteMatch, bool) {
+	var best *RouteMatch
+	var bestRank []int
+	rr.walk(func(r route) {
+		if r.method != method && r.method != "*" {
+			return
+		}
+		params, rank, ok := matchPattern(r.pattern, path)
+		if !ok || (best != nil && !outranks(rank, bestRank)) {
+			return
+		}
+		best = &RouteMatch{Method: r.method, Pattern: r.pattern, Params: params, Handlers: r.handlers, Metadata: r.metadata}
+		bestRank = rank
+	})
+	return best, best != nil
+}
+
+// walk calls fn for the routes of the register and its groups
+func (rr *RouteRegisterImpl) walk(fn func(r route)) {
+	for _, r := range rr.routes {
+		fn(r)
+	}
+	for _, g := range rr.groups {
+		g.walk(fn)
+	}
+}
+
+// Ranks of pattern segments, static segments take precedence over parameters and
+// parameters over wildcards, like in the router.
+const (
+	segmentWildcard = iota
+	segmentParam
+	segmentStatic
+)
+
+// matchPattern matches a path against a route pattern such as /api/teams/:teamId/*
+func matchPattern(pattern, path string) (map[string]string, []int, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	params := map[string]string{}
+	rank := make([]int, 0, len(patternSegments))
+
+	for i, segment := range patternSegments {
+		if segment == "*" {
+			if i < len(pathSegments) {
+				params["*"] = strings.Join(pathSegments[i:], "/")
+			}
+			return params, append(rank, segmentWildcard), true
+		}
+		if i >= len(pathSegments) {
+			return nil, nil, false
+		}
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if pathSegments[i] == "" {
+				return nil, nil, false
+			}
+			params[segment] = pathSegments[i]
+			rank = append(rank, segmentParam)
+		case segment == pathSegments[i]:
+			rank = append(rank, segmentStatic)
+		default:
+			return nil, nil, false
+		}
+	}
+
+	if len(patternSegments) != len(pathSegments) {
+		return nil, nil, false
+	}
+	return params, rank, true
+}
+
+// outranks tells if a route is more specific than another, comparing their segments from left to right
+func outranks(rank, other []int) bool {
+	for i := 0; i < len(rank) && i < len(other); i++ {
+		if rank[i] != other[i] {
+			return rank[i] > other[i]
+		}
+	}
+	return len(rank) > len(other)
+}
+
 func (rr *RouteRegisterImpl) Insert(pattern string, fn func(RouteRegister), handlers ...web.Handler) {
 	// loop over all groups at current level
 	for _, g := range rr.groups {
@@ -148,8 +253,16 @@ func (rr *RouteRegisterImpl) route(pattern, method string, handlers ...web.Handl
 		h = append(h, fn(fullPattern))
 	}
 
-	h = append(h, rr.subfixHandlers...)
-	h = append(h, handlers...)
+	var metadata []interface{}
+	for _, handlers := range [][]web.Handler{rr.subfixHandlers, handlers} {
+		for _, handler := range handlers {
+			if m, ok := handler.(HandlerWithMetadata); ok {
+				metadata = append(metadata, m.Metadata)
+				handler = m.Handler
+			}
+			h = append(h, handler)
+		}
+	}
 
 	for _, r := range rr.routes {
 		if r.pattern == fullPattern && r.method == method {
@@ -161,6 +274,7 @@ func (rr *RouteRegisterImpl) route(pattern, method string, handlers ...web.Handl
 		method:   method,
 		pattern:  fullPattern,
 		handlers: h,
+		metadata: metadata,
 	})
 }
 
@@ -261,3 +261,57 @@ func TestNamedMiddlewareRouteRegister(t *testing.T) {
 		}
 	}
 }
+
+func TestRouteLookup(t *testing.T) {
+	rr := NewRouteRegister()
+	rr.Group("/api", func(api RouteRegister) {
+		api.Get("/teams/search", emptyHandler("search"))
+		api.Get("/teams/:teamId", emptyHandler("team"))
+		api.Get("/teams/:teamId/members", emptyHandler("members"))
+		api.Post("/teams/:teamId/members", emptyHandler("add member"))
+		api.Any("/datasources/proxy/:id/*", emptyHandler("proxy"))
+	}, emptyHandler("api"))
+
+	testTable := []struct {
+		method   string
+		path     string
+		pattern  string
+		params   map[string]string
+		handlers int
+	}{
+		{method: "GET", path: "/api/teams/search", pattern: "/api/teams/search", params: map[string]string{}, handlers: 2},
+		{method: "GET", path: "/api/teams/1", pattern: "/api/teams/:teamId", params: map[string]string{":teamId": "1"}, handlers: 2},
+		{method: "POST", path: "/api/teams/1/members/", pattern: "/api/teams/:teamId/members", params: map[string]string{":teamId": "1"}, handlers: 2},
+		{method: "DELETE", path: "/api/datasources/proxy/2/api/v1/query", pattern: "/api/datasources/proxy/:id/*", params: map[string]string{":id": "2", "*": "api/v1/query"}, handlers: 2},
+		{method: "DELETE", path: "/api/teams/1"},
+		{method: "GET", path: "/api/teams/1/members/2"},
+	}
+
+	for _, tc := range testTable {
+		match, ok := rr.Lookup(tc.method, tc.path)
+		if tc.pattern == "" {
+			if ok {
+				t.Errorf("%s %s: want no match, got %s", tc.method, tc.path, match.Pattern)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%s %s: want %s, got no match", tc.method, tc.path, tc.pattern)
+			continue
+		}
+		if match.Pattern != tc.pattern {
+			t.Errorf("%s %s: want %s, got %s", tc.method, tc.path, tc.pattern, match.Pattern)
+		}
+		if len(match.Params) != len(tc.params) {
+			t.Errorf("%s %s: want params %v, got %v", tc.method, tc.path, tc.params, match.Params)
+		}
+		for k, v := range tc.params {
+			if match.Params[k] != v {
+				t.Errorf("%s %s: want params %v, got %v", tc.method, tc.path, tc.params, match.Params)
+			}
+		}
+		if len(match.Handlers) != tc.handlers {
+			t.Errorf("%s %s: want %d handlers, got %d", tc.method, tc.path, tc.handlers, len(match.Handlers))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/fulltext"
 )
 
 func Search(c *models.ReqContext) response.Response {
@@ -71,6 +73,50 @@ func Search(c *models.ReqContext) response.Response {
 	return response.JSON(200, searchQuery.Result)
 }
 
+// GET /api/search/fulltext
+func (hs *HTTPServer) FullTextSearch(c *models.ReqContext) response.Response {
+	if hs.FullTextSearchService.IsDisabled() {
+		return response.Error(http.StatusNotFound, "Full-text search is disabled", nil)
+	}
+
+	query := c.Query("query")
+	if query == "" {
+		return response.Error(http.StatusBadRequest, "query is required", nil)
+	}
+
+	limit := c.QueryInt64("limit")
+	if limit > 5000 {
+		return response.Error(422, "Limit is above maximum allowed (5000), use page parameter to access hits beyond limit", nil)
+	}
+
+	folderIDs := make([]int64, 0)
+	for _, id := range c.QueryStrings("folderIds") {
+		folderID, err := strconv.ParseInt(id, 10, 64)
+		if err == nil {
+			folderIDs = append(folderIDs, folderID)
+		}
+	}
+
+	searchQuery := fulltext.SearchQuery{
+		Query:        query,
+		OrgID:        c.OrgId,
+		SignedInUser: c.SignedInUser,
+		FolderIDs:    folderIDs,
+		Limit:        limit,
+		Page:         c.QueryInt64("page"),
+	}
+
+	if err := hs.FullTextSearchService.Search(c.Req.Context(), &searchQuery); err != nil {
+		if errors.Is(err, fulltext.ErrIndexNotReady) {
+			return response.Error(http.StatusServiceUnavailable, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Search failed", err)
+	}
+
+	c.TimeRequest(metrics.MApiDashboardSearch)
+	return response.JSON(http.StatusOK, searchQuery.Result)
+}
+
 func (hs *HTTPServer) ListSortOptions(c *models.ReqContext) response.Response {
 	opts := hs.SearchService.SortOptions()
 
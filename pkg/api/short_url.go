@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -34,12 +35,34 @@ func (hs *HTTPServer) createShortURL(c *models.ReqContext) response.Response {
 		return response.Error(400, "Invalid path", nil)
 	}
 
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+	if cmd.Expires < 0 {
+		return response.Error(400, "Expires should not be negative", nil)
+	}
+	if cmd.Pinned && cmd.Expires > 0 {
+		return response.Error(400, "Pinned short URLs cannot expire", nil)
+	}
+
+	createCmd := models.CreateShortUrlCommand{
+		Path:   cmd.Path,
+		Uid:    strings.TrimSpace(cmd.UID),
+		Pinned: cmd.Pinned,
+	}
+	if cmd.Expires > 0 {
+		createCmd.ExpiresAt = time.Now().Add(time.Duration(cmd.Expires) * time.Second).Unix()
+	}
+
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, &createCmd)
 	if err != nil {
+		if errors.Is(err, models.ErrShortURLWithSameUIDExists) {
+			return response.Error(409, err.Error(), nil)
+		}
+		if errors.Is(err, models.ErrShortURLInvalidUID) || errors.Is(err, models.ErrShortURLUIDTooLong) {
+			return response.Error(400, err.Error(), nil)
+		}
 		return response.Error(500, "Failed to create short URL", err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, c.OrgId)
+	url := shortURLLink(shortURL.Uid, c.OrgId)
 	c.Logger.Debug("Created short URL", "url", url)
 
 	dto := dtos.ShortURL{
@@ -68,11 +91,144 @@ func (hs *HTTPServer) redirectFromShortURL(c *models.ReqContext) {
 		return
 	}
 
-	// Failure to update LastSeenAt should still allow to redirect
-	if err := hs.ShortURLService.UpdateLastSeenAt(c.Req.Context(), shortURL); err != nil {
-		hs.log.Error("Failed to update short URL last seen at", "error", err)
+	if shortURL.IsExpired(time.Now()) {
+		hs.log.Debug("Not redirecting short URL since expired", "uid", shortURL.Uid)
+		return
+	}
+
+	// Failure to record the hit should still allow to redirect
+	if err := hs.ShortURLService.RecordHit(c.Req.Context(), shortURL); err != nil {
+		hs.log.Error("Failed to record short URL hit", "error", err)
 	}
 
 	hs.log.Debug("Redirecting short URL", "path", shortURL.Path)
 	c.Redirect(setting.ToAbsUrl(shortURL.Path), 302)
 }
+
+// searchShortURLs lists the short URLs of the organization, optionally filtered by creator and target path prefix.
+func (hs *HTTPServer) searchShortURLs(c *models.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = 100
+	} else if limit > 1000 {
+		limit = 1000
+	}
+
+	query := models.SearchShortUrlsQuery{
+		OrgId:     c.OrgId,
+		CreatedBy: c.QueryInt64("createdBy"),
+		Path:      strings.TrimPrefix(c.Query("path"), "/"),
+		Limit:     limit,
+		Page:      c.QueryInt("page"),
+	}
+	if err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), &query); err != nil {
+		return response.Error(500, "Failed to search short URLs", err)
+	}
+
+	result := make([]dtos.ShortURLDetails, 0, len(query.Result))
+	for _, hit := range query.Result {
+		result = append(result, dtos.ShortURLDetails{
+			UID:            hit.Uid,
+			URL:            shortURLLink(hit.Uid, c.OrgId),
+			Path:           hit.Path,
+			CreatedBy:      hit.CreatedBy,
+			CreatedByLogin: hit.CreatedByLogin,
+			Created:        time.Unix(hit.CreatedAt, 0),
+			LastSeen:       unixTimeOrNil(hit.LastSeenAt),
+			Expires:        unixTimeOrNil(hit.ExpiresAt),
+			Pinned:         hit.Pinned,
+			Hits:           hit.Hits,
+		})
+	}
+
+	return response.JSON(200, result)
+}
+
+// updateShortURL changes the expiry of a short URL, or pins it so that it never expires.
+func (hs *HTTPServer) updateShortURL(c *models.ReqContext) response.Response {
+	cmd := dtos.UpdateShortURLCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Expires < 0 {
+		return response.Error(400, "Expires should not be negative", nil)
+	}
+	if cmd.Pinned && cmd.Expires > 0 {
+		return response.Error(400, "Pinned short URLs cannot expire", nil)
+	}
+
+	shortURL, errResp := hs.getEditableShortURL(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var expiresAt int64
+	if cmd.Expires > 0 {
+		expiresAt = time.Now().Add(time.Duration(cmd.Expires) * time.Second).Unix()
+	}
+	if err := hs.ShortURLService.UpdateShortURLExpiry(c.Req.Context(), shortURL, expiresAt, cmd.Pinned); err != nil {
+		return response.Error(500, "Failed to update short URL", err)
+	}
+
+	return response.Success("Short URL updated")
+}
+
+// getShortURLStats returns the hits of a short URL with a histogram of the hits per day.
+func (hs *HTTPServer) getShortURLStats(c *models.ReqContext) response.Response {
+	shortURL, errResp := hs.getEditableShortURL(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	days := c.QueryInt("days")
+	if days <= 0 {
+		days = 30
+	} else if days > 90 {
+		days = 90
+	}
+
+	hits, err := hs.ShortURLService.GetDailyHits(c.Req.Context(), shortURL, days)
+	if err != nil {
+		return response.Error(500, "Failed to get short URL hits", err)
+	}
+
+	stats := dtos.ShortURLStats{
+		UID:      shortURL.Uid,
+		Hits:     shortURL.Hits,
+		LastSeen: unixTimeOrNil(shortURL.LastSeenAt),
+		Daily:    make([]dtos.ShortURLDailyHits, 0, len(hits)),
+	}
+	for _, hit := range hits {
+		stats.Daily = append(stats.Daily, dtos.ShortURLDailyHits{Day: hit.Day, Hits: hit.Hits})
+	}
+
+	return response.JSON(200, stats)
+}
+
+// getEditableShortURL returns the short URL of the request if the user created it or is an admin of the organization.
+func (hs *HTTPServer) getEditableShortURL(c *models.ReqContext) (*models.ShortUrl, response.Response) {
+	shortURL, err := hs.ShortURLService.GetShortURLByUID(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, models.ErrShortURLNotFound) {
+			return nil, response.Error(404, "Short URL not found", err)
+		}
+		return nil, response.Error(500, "Failed to get short URL", err)
+	}
+
+	if shortURL.CreatedBy != c.UserId && c.OrgRole != models.ROLE_ADMIN {
+		return nil, response.Error(403, "Access denied to this short URL", nil)
+	}
+	return shortURL, nil
+}
+
+func shortURLLink(uid string, orgID int64) string {
+	return fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), uid, orgID)
+}
+
+func unixTimeOrNil(unix int64) *time.Time {
+	if unix == 0 {
+		return nil
+	}
+	t := time.Unix(unix, 0)
+	return &t
+}
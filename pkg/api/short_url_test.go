@@ -30,7 +30,7 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
@@ -48,6 +48,40 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 	})
 }
 
+func TestShortURLAPIEndpointWithAlias(t *testing.T) {
+	cmd := dtos.CreateShortURLCmd{
+		Path: "d/TxKARsmGz/new-dashboard",
+		UID:  "oncall-overview",
+	}
+
+	t.Run("Given an alias that is already taken", func(t *testing.T) {
+		service := &fakeShortURLService{
+			createShortURLFunc: func(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
+				require.Equal(t, "oncall-overview", cmd.Uid)
+				return nil, models.ErrShortURLWithSameUIDExists
+			},
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service,
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+				require.Equal(t, 409, sc.resp.Code)
+			})
+	})
+
+	t.Run("Given a pinned short URL with an expiry", func(t *testing.T) {
+		cmd := cmd
+		cmd.Pinned = true
+		cmd.Expires = 3600
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, &fakeShortURLService{},
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+				require.Equal(t, 400, sc.resp.Code)
+			})
+	})
+}
+
 func callCreateShortURL(sc *scenarioContext) {
 	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 }
@@ -78,16 +112,16 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error)
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *models.SignedInUser, uid string) (*models.ShortUrl, error) {
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
@@ -97,6 +131,22 @@ func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *mo
 	return nil
 }
 
+func (s *fakeShortURLService) UpdateShortURLExpiry(ctx context.Context, shortURL *models.ShortUrl, expiresAt int64, pinned bool) error {
+	return nil
+}
+
+func (s *fakeShortURLService) RecordHit(ctx context.Context, shortURL *models.ShortUrl) error {
+	return nil
+}
+
+func (s *fakeShortURLService) GetDailyHits(ctx context.Context, shortURL *models.ShortUrl, days int) ([]*models.ShortUrlHit, error) {
+	return nil, nil
+}
+
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, query *models.SearchShortUrlsQuery) error {
+	return nil
+}
+
 func (s *fakeShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error {
 	return nil
 }
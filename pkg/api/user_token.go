@@ -99,6 +99,17 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 			seenAt = createdAt
 		}
 
+		lastSeenAt := time.Unix(token.LastSeenAt, 0)
+		if token.LastSeenAt < token.SeenAt {
+			lastSeenAt = seenAt
+		}
+
+		location, countryCode := "", ""
+		if loc := hs.GeoIPService.Lookup(token.ClientIp); loc != nil {
+			location = loc.String()
+			countryCode = loc.CountryCode
+		}
+
 		result = append(result, &dtos.UserToken{
 			Id:                     token.Id,
 			IsActive:               isActive,
@@ -110,6 +121,9 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 			BrowserVersion:         browserVersion,
 			CreatedAt:              createdAt,
 			SeenAt:                 seenAt,
+			LastSeenAt:             lastSeenAt,
+			Location:               location,
+			CountryCode:            countryCode,
 		})
 	}
 
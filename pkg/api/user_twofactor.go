@@ -0,0 +1,239 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/twofactor"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// twoFactorChallenge returns the response asking for a second factor when the
+// user has to provide one, or nil when the password is enough. Users who must
+// enroll get the secret of their new authenticator.
+func (hs *HTTPServer) twoFactorChallenge(c *models.ReqContext, user *models.User) *response.NormalResponse {
+	required, err := hs.TwoFactorService.IsRequired(c.Req.Context(), user)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check two-factor authentication", err)
+	}
+	if !required {
+		return nil
+	}
+
+	result := map[string]interface{}{
+		"message":           "Two-factor authentication required",
+		"twoFactorRequired": true,
+	}
+
+	enrolled, err := hs.TwoFactorService.IsEnrolled(c.Req.Context(), user.Id)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check two-factor authentication", err)
+	}
+	if !enrolled {
+		enrollment, err := hs.TwoFactorService.StartEnrollment(c.Req.Context(), user)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to start two-factor authentication enrollment", err)
+		}
+		result["twoFactorEnrollment"] = enrollment
+	}
+
+	token, err := hs.TwoFactorService.CreateChallenge(c.Req.Context(), user.Id)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to create two-factor authentication challenge", err)
+	}
+	result["twoFactorToken"] = token
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// POST /login/2fa
+func (hs *HTTPServer) LoginTwoFactorPost(c *models.ReqContext) response.Response {
+	cmd := dtos.LoginTwoFactorCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad login data", err)
+	}
+	var user *models.User
+	var resp *response.NormalResponse
+
+	defer func() {
+		err := resp.Err()
+		if err == nil && resp.ErrMessage() != "" {
+			err = errors.New(resp.ErrMessage())
+		}
+		info := &models.LoginInfo{
+			AuthModule: "grafana",
+			User:       user,
+			HTTPStatus: resp.Status(),
+			Error:      err,
+		}
+		if user != nil {
+			info.LoginUsername = user.Login
+		}
+		hs.HooksService.RunLoginHook(info, c)
+	}()
+
+	userID, recoveryCodes, err := hs.TwoFactorService.CompleteChallenge(c.Req.Context(), cmd.Token, cmd.Code, c.Req.RemoteAddr)
+	if err != nil {
+		switch {
+		case errors.Is(err, twofactor.ErrInvalidCode):
+			resp = response.Error(http.StatusUnauthorized, "Invalid two-factor authentication code", err)
+		case errors.Is(err, twofactor.ErrChallengeNotFound):
+			resp = response.Error(http.StatusUnauthorized, "Two-factor authentication expired, please log in again", err)
+		case errors.Is(err, twofactor.ErrTooManyAttempts):
+			resp = response.Error(http.StatusUnauthorized, "Too many invalid two-factor authentication codes, please try again later", err)
+		default:
+			resp = response.Error(http.StatusInternalServerError, "Error while trying to authenticate user", err)
+		}
+		return resp
+	}
+
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := hs.SQLStore.GetUserById(c.Req.Context(), &query); err != nil {
+		resp = response.Error(http.StatusInternalServerError, "Error while trying to authenticate user", err)
+		return resp
+	}
+	if query.Result.IsDisabled {
+		resp = response.Error(http.StatusUnauthorized, "Invalid username or password", nil)
+		return resp
+	}
+	user = query.Result
+
+	result := map[string]interface{}{}
+	if len(recoveryCodes) > 0 {
+		result["recoveryCodes"] = recoveryCodes
+	}
+	resp = hs.completeLogin(c, user, result)
+	return resp
+}
+
+// GET /api/user/2fa
+func (hs *HTTPServer) GetUserTwoFactor(c *models.ReqContext) response.Response {
+	query := models.GetUserByIdQuery{Id: c.UserId}
+	if err := hs.SQLStore.GetUserById(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Could not read user from database", err)
+	}
+
+	status, err := hs.TwoFactorService.GetStatus(c.Req.Context(), query.Result)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get two-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// POST /api/user/2fa/enroll
+func (hs *HTTPServer) EnrollUserTwoFactor(c *models.ReqContext) response.Response {
+	query := models.GetUserByIdQuery{Id: c.UserId}
+	if err := hs.SQLStore.GetUserById(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Could not read user from database", err)
+	}
+
+	enrollment, err := hs.TwoFactorService.StartEnrollment(c.Req.Context(), query.Result)
+	if err != nil {
+		return twoFactorErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// POST /api/user/2fa/verify
+func (hs *HTTPServer) VerifyUserTwoFactor(c *models.ReqContext) response.Response {
+	form := dtos.TwoFactorCodeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	codes, err := hs.TwoFactorService.CompleteEnrollment(c.Req.Context(), c.UserId, form.Code)
+	if err != nil {
+		return twoFactorErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// POST /api/user/2fa/recovery-codes
+func (hs *HTTPServer) RegenerateUserTwoFactorRecoveryCodes(c *models.ReqContext) response.Response {
+	form := dtos.TwoFactorCodeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.TwoFactorService.Verify(c.Req.Context(), c.UserId, form.Code); err != nil {
+		return twoFactorErrorResponse(err)
+	}
+	codes, err := hs.TwoFactorService.RegenerateRecoveryCodes(c.Req.Context(), c.UserId)
+	if err != nil {
+		return twoFactorErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"message":       "Recovery codes regenerated",
+		"recoveryCodes": codes,
+	})
+}
+
+// POST /api/user/2fa/disable
+func (hs *HTTPServer) DisableUserTwoFactor(c *models.ReqContext) response.Response {
+	form := dtos.TwoFactorCodeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query := models.GetUserByIdQuery{Id: c.UserId}
+	if err := hs.SQLStore.GetUserById(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Could not read user from database", err)
+	}
+	enforced, err := hs.TwoFactorService.IsEnforced(c.Req.Context(), query.Result)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check two-factor authentication", err)
+	}
+	if enforced {
+		return response.Error(http.StatusForbidden, "Two-factor authentication is required for administrators", twofactor.ErrRequiredForAdmins)
+	}
+
+	if err := hs.TwoFactorService.Verify(c.Req.Context(), c.UserId, form.Code); err != nil {
+		return twoFactorErrorResponse(err)
+	}
+	if err := hs.TwoFactorService.Disable(c.Req.Context(), c.UserId); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication disabled")
+}
+
+// DELETE /api/admin/users/:id/2fa
+func (hs *HTTPServer) AdminResetUserTwoFactor(c *models.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := hs.SQLStore.GetUserById(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, models.ErrUserNotFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Could not read user from database", err)
+	}
+
+	if err := hs.TwoFactorService.Disable(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication reset")
+}
+
+func twoFactorErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		return response.Error(http.StatusUnauthorized, "Invalid two-factor authentication code", err)
+	case errors.Is(err, twofactor.ErrAlreadyEnrolled):
+		return response.Error(http.StatusConflict, "Two-factor authentication is already enabled", err)
+	case errors.Is(err, twofactor.ErrNotEnrolled):
+		return response.Error(http.StatusBadRequest, "Two-factor authentication is not enabled", err)
+	case errors.Is(err, twofactor.ErrDisabled):
+		return response.Error(http.StatusNotFound, "Two-factor authentication is disabled", err)
+	}
+	return response.Error(http.StatusInternalServerError, "Two-factor authentication failed", err)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/twofactor"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginTwoFactor(t *testing.T) {
+	// The scenario context initializes the test database, so it is set up first.
+	sc := setupScenarioContext(t, "/login")
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.TwoFactorEnabled = true
+	cfg.TwoFactorEnforceForAdmins = true
+	cfg.TwoFactorIssuer = "Grafana"
+
+	hs := &HTTPServer{
+		log:              log.New("test"),
+		Cfg:              cfg,
+		License:          &licensing.OSSLicensingService{},
+		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		HooksService:     &hooks.HooksService{},
+		SQLStore:         sqlStore,
+		TwoFactorService: twofactor.ProvideService(cfg, sqlStore, fakes.NewFakeSecretsService(), remotecache.NewFakeStore(t)),
+	}
+
+	admin, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "admin", IsAdmin: true})
+	require.NoError(t, err)
+	bus.AddHandler("grafana-auth", func(ctx context.Context, query *models.LoginUserQuery) error {
+		query.User = admin
+		query.AuthModule = "grafana"
+		return nil
+	})
+
+	var body string
+	withBody := func(handler func(c *models.ReqContext) response.Response) web.Handler {
+		return routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.Req.Header.Set("Content-Type", "application/json")
+			c.Req.Body = io.NopCloser(bytes.NewBufferString(body))
+			return handler(c)
+		})
+	}
+	sc.m.Post("/login", withBody(hs.LoginPost))
+	sc.m.Post("/login/2fa", withBody(hs.LoginTwoFactorPost))
+
+	post := func(t *testing.T, url string, reqBody string) (int, map[string]interface{}) {
+		t.Helper()
+
+		body = reqBody
+		sc.fakeReqNoAssertions("POST", url).exec()
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(sc.resp.Body).Decode(&result))
+		return sc.resp.Code, result
+	}
+
+	// Admins without an authenticator enroll during login.
+	code, result := post(t, "/login", `{"user":"admin","password":"admin"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, true, result["twoFactorRequired"])
+	require.NotContains(t, result, "redirectUrl")
+	enrollment, ok := result["twoFactorEnrollment"].(map[string]interface{})
+	require.True(t, ok)
+	secret := enrollment["secret"].(string)
+	token := result["twoFactorToken"].(string)
+
+	code, _ = post(t, "/login/2fa", `{"token":"`+token+`","code":"000000"}`)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	totp, err := twofactor.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	code, result = post(t, "/login/2fa", `{"token":"`+token+`","code":"`+totp+`"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "Logged in", result["message"])
+	recoveryCodes, ok := result["recoveryCodes"].([]interface{})
+	require.True(t, ok)
+	require.NotEmpty(t, recoveryCodes)
+
+	// The challenge can only be completed once.
+	code, _ = post(t, "/login/2fa", `{"token":"`+token+`","code":"`+recoveryCodes[0].(string)+`"}`)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	// Enrolled users get a challenge without enrollment.
+	code, result = post(t, "/login", `{"user":"admin","password":"admin"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.NotContains(t, result, "twoFactorEnrollment")
+	token = result["twoFactorToken"].(string)
+
+	code, result = post(t, "/login/2fa", `{"token":"`+token+`","code":"`+recoveryCodes[0].(string)+`"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.NotContains(t, result, "recoveryCodes")
+}
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/dashboardbundle"
+)
+
+const bundleRequestTimeout = 5 * time.Minute
+
+// exportBundleCommand downloads the bundle of a folder from a Grafana instance.
+func exportBundleCommand(c utils.CommandLine) error {
+	folderUID := c.String("folder")
+	if folderUID == "" {
+		return fmt.Errorf("missing folder uid")
+	}
+
+	output := c.String("output")
+	if output == "" {
+		output = folderUID + ".zip"
+	}
+
+	resp, err := bundleRequest(c, http.MethodGet, "/api/folders/"+url.PathEscape(folderUID)+"/export", "", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, resp, 0600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	logger.Infof("%s Exported folder %s to %s\n", color.GreenString("✔"), folderUID, output)
+	return nil
+}
+
+// importBundleCommand uploads a bundle to a Grafana instance and prints what was, or would be on a dry run,
+// imported.
+func importBundleCommand(c utils.CommandLine) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("missing bundle file")
+	}
+
+	opts := dashboardbundle.ImportOptions{
+		DatasourceMappings: make(map[string]string),
+		Overwrite:          dashboardbundle.OverwritePolicy(c.String("overwrite")),
+		DryRun:             c.Bool("dry-run"),
+	}
+	for _, mapping := range c.StringSlice("datasource") {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid data source mapping %q, expected <input>=<data source uid or name>", mapping)
+		}
+		opts.DatasourceMappings[parts[0]] = parts[1]
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	optionsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("bundle", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return err
+	}
+	if err := w.WriteField("options", string(optionsJSON)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	resp, err := bundleRequest(c, http.MethodPost, "/api/folders/import", w.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+
+	result := dashboardbundle.ImportResult{}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to read import result: %w", err)
+	}
+	for _, item := range result.Items {
+		line := fmt.Sprintf("%-8s %-15s %s", item.Action, item.Kind, item.Title)
+		if item.Reason != "" {
+			line += " (" + item.Reason + ")"
+		}
+		logger.Info(line + "\n")
+	}
+	if result.DryRun {
+		logger.Infof("%s Dry run, nothing was imported\n", color.YellowString("!"))
+	} else {
+		logger.Infof("%s Imported %s\n", color.GreenString("✔"), path)
+	}
+	return nil
+}
+
+func bundleRequest(c utils.CommandLine, method, path, contentType string, body io.Reader) ([]byte, error) {
+	baseURL := strings.TrimSuffix(c.String("url"), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("missing Grafana url")
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if token := c.String("token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	client := &http.Client{Timeout: bundleRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", baseURL, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	// a conflicting import responds with the import result
+	if resp.StatusCode == http.StatusConflict {
+		result := dashboardbundle.ImportResult{}
+		if err := json.Unmarshal(data, &result); err == nil && len(result.Items) > 0 {
+			conflicts := make([]string, 0)
+			for _, item := range result.Items {
+				if item.Action == dashboardbundle.ActionConflict {
+					conflicts = append(conflicts, fmt.Sprintf("%s %q", item.Kind, item.Title))
+				}
+			}
+			return nil, fmt.Errorf("the bundle conflicts with existing content, use --overwrite skip or overwrite: %s", strings.Join(conflicts, ", "))
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := struct {
+			Message string `json:"message"`
+		}{}
+		if err := json.Unmarshal(data, &message); err == nil && message.Message != "" {
+			return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, message.Message)
+		}
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+	return data, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/dashboardbundle"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
 	"github.com/grafana/grafana/pkg/setting"
@@ -106,6 +107,12 @@ func runCueCommand(command func(commandLine utils.CommandLine) error) func(conte
 	}
 }
 
+func runBundleCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		return command(&utils.ContextCommandLine{Context: context})
+	}
+}
+
 // Command contains command state.
 type Command struct {
 	Client utils.ApiClient
@@ -250,6 +257,58 @@ so must be recompiled to validate newly-added CUE files.`,
 	},
 }
 
+var bundleFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "url",
+		Usage:   "URL of the Grafana instance",
+		Value:   "http://localhost:3000",
+		EnvVars: []string{"GF_BUNDLE_URL"},
+	},
+	&cli.StringFlag{
+		Name:    "token",
+		Usage:   "API key or service account token of an organization admin",
+		EnvVars: []string{"GF_BUNDLE_TOKEN"},
+	},
+}
+
+var bundleCommands = []*cli.Command{
+	{
+		Name:   "export",
+		Usage:  "export --folder <folder uid> [--output <file>]",
+		Action: runBundleCommand(exportBundleCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "folder",
+				Usage: "uid of the folder to export",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "path of the bundle to write, <folder uid>.zip by default",
+			},
+		}, bundleFlags...),
+	},
+	{
+		Name:   "import",
+		Usage:  "import [--datasource <input>=<data source>] [--overwrite fail|skip|overwrite] [--dry-run] <bundle file>",
+		Action: runBundleCommand(importBundleCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "datasource",
+				Usage: "map an input of the bundle to a data source uid or name, as DS_NAME=uid",
+			},
+			&cli.StringFlag{
+				Name:  "overwrite",
+				Usage: "what to do with existing content: fail, skip or overwrite",
+				Value: string(dashboardbundle.OverwriteFail),
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "only report what would be imported",
+			},
+		}, bundleFlags...),
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -266,4 +325,9 @@ var Commands = []*cli.Command{
 		Usage:       "Cue validation commands",
 		Subcommands: cueCommands,
 	},
+	{
+		Name:        "bundles",
+		Usage:       "Export and import folder bundles through the HTTP API",
+		Subcommands: bundleCommands,
+	},
 }
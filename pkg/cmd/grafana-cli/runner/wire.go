@@ -79,3 +79,5 @@ func (noOpRouteRegister) Insert(string, func(routing.RouteRegister), ...web.Hand
 func (noOpRouteRegister) Register(routing.Router, ...routing.RegisterNamedMiddleware) {}
 
 func (noOpRouteRegister) Reset() {}
+
+func (noOpRouteRegister) Lookup(string, string) (*routing.RouteMatch, bool) { return nil, false }
@@ -25,6 +25,7 @@ const (
 	DiffJSON DiffType = iota
 	DiffBasic
 	DiffDelta
+	DiffSemantic
 )
 
 type Options struct {
@@ -52,6 +53,8 @@ func ParseDiffType(diff string) DiffType {
 		return DiffBasic
 	case "delta":
 		return DiffDelta
+	case "semantic":
+		return DiffSemantic
 	}
 	return DiffBasic
 }
@@ -79,8 +82,22 @@ func CalculateDiff(ctx context.Context, options *Options) (*Result, error) {
 		return nil, err
 	}
 
-	baseData := baseVersionQuery.Result.Data
-	newData := newVersionQuery.Result.Data
+	return CalculateDataDiff(baseVersionQuery.Result.Data, newVersionQuery.Result.Data, options.DiffType)
+}
+
+// CalculateDataDiff computes the diff of two JSON documents in the given diff type.
+func CalculateDataDiff(baseData, newData *simplejson.Json, diffType DiffType) (*Result, error) {
+	if diffType == DiffSemantic {
+		changes, err := SemanticDiff(baseData, newData)
+		if err != nil {
+			return nil, err
+		}
+		delta, err := json.Marshal(changes)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Delta: delta}, nil
+	}
 
 	left, jsonDiff, err := getDiff(baseData, newData)
 	if err != nil {
@@ -89,7 +106,7 @@ func CalculateDiff(ctx context.Context, options *Options) (*Result, error) {
 
 	result := &Result{}
 
-	switch options.DiffType {
+	switch diffType {
 	case DiffDelta:
 
 		deltaOutput, err := deltaFormatter.NewDeltaFormatter().Format(jsonDiff)
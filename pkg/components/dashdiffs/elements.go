@@ -0,0 +1,250 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// ElementKind is the kind of a dashboard element compared by semantic diffs and three-way merges.
+type ElementKind string
+
+const (
+	ElementDashboard  ElementKind = "dashboard"
+	ElementPanel      ElementKind = "panel"
+	ElementVariable   ElementKind = "variable"
+	ElementAnnotation ElementKind = "annotation"
+	ElementLink       ElementKind = "link"
+)
+
+// collection is a list of elements of a dashboard.
+type collection struct {
+	kind ElementKind
+	path []string
+	// keyField is the field identifying an element. Panels are identified by id or position instead.
+	keyField string
+}
+
+var collections = []collection{
+	{kind: ElementPanel, path: []string{"panels"}},
+	{kind: ElementVariable, path: []string{"templating", "list"}, keyField: "name"},
+	{kind: ElementAnnotation, path: []string{"annotations", "list"}, keyField: "name"},
+	{kind: ElementLink, path: []string{"links"}, keyField: "title"},
+}
+
+// queries is the collection of the queries of a panel, which are compared one by one.
+var queries = collection{path: []string{"targets"}, keyField: "refId"}
+
+// ignoredKeys are the dashboard properties that are either compared as collections or that change on every save.
+var ignoredKeys = map[string]bool{
+	"panels":      true,
+	"templating":  true,
+	"annotations": true,
+	"links":       true,
+	"id":          true,
+	"version":     true,
+}
+
+var propertyLabels = map[ElementKind]map[string]string{
+	ElementPanel: {
+		"targets":     "query",
+		"gridPos":     "position",
+		"type":        "visualization",
+		"fieldConfig": "options",
+	},
+}
+
+type element struct {
+	key   string
+	title string
+	value map[string]interface{}
+}
+
+// decode returns a copy of a dashboard made only of maps, slices and JSON scalars so it can be compared and
+// modified freely.
+func decode(dash *simplejson.Json) (map[string]interface{}, error) {
+	if dash == nil {
+		return map[string]interface{}{}, nil
+	}
+	data, err := dash.Encode()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func getList(data map[string]interface{}, path []string) ([]interface{}, bool) {
+	var current interface{} = data
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	list, ok := current.([]interface{})
+	return list, ok
+}
+
+func setList(data map[string]interface{}, path []string, list []interface{}) {
+	current := data
+	for _, key := range path[:len(path)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[key] = next
+		}
+		current = next
+	}
+	current[path[len(path)-1]] = list
+}
+
+func (c collection) elements(list []interface{}) []*element {
+	result := make([]*element, 0, len(list))
+	seen := make(map[string]int)
+	for i, item := range list {
+		value, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var key string
+		if c.kind == ElementPanel {
+			key = panelKey(value, i)
+		} else if s, ok := value[c.keyField].(string); ok && s != "" {
+			key = s
+		} else {
+			key = "index:" + strconv.Itoa(i)
+		}
+		// elements sharing a key are matched in order
+		if seen[key]++; seen[key] > 1 {
+			key = fmt.Sprintf("%s#%d", key, seen[key])
+		}
+
+		el := &element{key: key, value: value}
+		switch c.kind {
+		case ElementPanel, ElementLink:
+			el.title, _ = value["title"].(string)
+		default:
+			el.title, _ = value[c.keyField].(string)
+		}
+		result = append(result, el)
+	}
+	return result
+}
+
+func panelKey(panel map[string]interface{}, index int) string {
+	if id, ok := panel["id"].(float64); ok {
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	if pos := gridPosKey(panel); pos != "" {
+		return "gridPos:" + pos
+	}
+	return "index:" + strconv.Itoa(index)
+}
+
+func gridPosKey(panel map[string]interface{}) string {
+	pos, ok := panel["gridPos"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%v,%v,%v,%v", pos["x"], pos["y"], pos["w"], pos["h"])
+}
+
+// match gives the elements of other that have no counterpart in base the key of the base element they replace.
+// Panels whose id changed, for instance after being copied from another dashboard, are matched by position.
+func (c collection) match(base, other []*element) {
+	if c.kind != ElementPanel {
+		return
+	}
+
+	baseKeys := make(map[string]bool, len(base))
+	for _, el := range base {
+		baseKeys[el.key] = true
+	}
+	otherKeys := make(map[string]bool, len(other))
+	for _, el := range other {
+		otherKeys[el.key] = true
+	}
+
+	unmatched := make(map[string]*element)
+	for _, el := range base {
+		if pos := gridPosKey(el.value); pos != "" && !otherKeys[el.key] {
+			if _, ok := unmatched[pos]; !ok {
+				unmatched[pos] = el
+			}
+		}
+	}
+
+	for _, el := range other {
+		if baseKeys[el.key] {
+			continue
+		}
+		pos := gridPosKey(el.value)
+		if b, ok := unmatched[pos]; ok && pos != "" {
+			el.key = b.key
+			delete(unmatched, pos)
+		}
+	}
+}
+
+func byKey(elements []*element) map[string]*element {
+	result := make(map[string]*element, len(elements))
+	for _, el := range elements {
+		result[el.key] = el
+	}
+	return result
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func propertyLabel(kind ElementKind, key string) string {
+	if label, ok := propertyLabels[kind][key]; ok {
+		return label
+	}
+	return key
+}
+
+// subject describes an element, for example `Panel "CPU usage"` or `Variable "host"`.
+func subject(kind ElementKind, el *element) string {
+	var name string
+	switch kind {
+	case ElementDashboard:
+		return "Dashboard"
+	case ElementPanel:
+		name = "Panel"
+	case ElementVariable:
+		name = "Variable"
+	case ElementAnnotation:
+		name = "Annotation"
+	case ElementLink:
+		name = "Link"
+	}
+
+	if el.title != "" {
+		return fmt.Sprintf("%s %q", name, el.title)
+	}
+	return name + " " + el.key
+}
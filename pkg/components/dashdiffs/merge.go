@@ -0,0 +1,219 @@
+package dashdiffs
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Conflict is a change made to a dashboard element by a user that cannot be merged with the change concurrently
+// saved by another user. The merged dashboard keeps the saved change.
+type Conflict struct {
+	Element     ElementKind `json:"element"`
+	Key         string      `json:"key,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Property    string      `json:"property,omitempty"`
+	Description string      `json:"description"`
+	Base        interface{} `json:"base"`
+	Ours        interface{} `json:"ours"`
+	Theirs      interface{} `json:"theirs"`
+}
+
+type MergeResult struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	Conflicts []*Conflict      `json:"conflicts"`
+}
+
+// Merge merges the changes made to the base version of a dashboard in ours with the ones made in theirs, the
+// version saved in the meantime. Settings, panels, variables, annotations, links and the queries of the panels are
+// merged one by one so that edits of different elements, or of different properties of an element, do not conflict.
+//
+// The merged dashboard has the version of theirs so that it can be saved over it.
+func Merge(base, ours, theirs *simplejson.Json) (*MergeResult, error) {
+	baseData, err := decode(base)
+	if err != nil {
+		return nil, err
+	}
+	oursData, err := decode(ours)
+	if err != nil {
+		return nil, err
+	}
+	theirsData, err := decode(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &merger{conflicts: make([]*Conflict, 0), nextPanelID: maxPanelID(oursData, theirsData) + 1}
+	merged := m.mergeProperties(ElementDashboard, &element{}, nil, baseData, oursData, theirsData)
+
+	for _, c := range collections {
+		baseList, inBase := getList(baseData, c.path)
+		oursList, inOurs := getList(oursData, c.path)
+		theirsList, inTheirs := getList(theirsData, c.path)
+		if inBase || inOurs || inTheirs {
+			setList(merged, c.path, m.mergeElements(c, nil, baseList, oursList, theirsList))
+		}
+	}
+
+	return &MergeResult{Dashboard: simplejson.NewFromAny(merged), Conflicts: m.conflicts}, nil
+}
+
+type merger struct {
+	conflicts   []*Conflict
+	nextPanelID float64
+}
+
+// mergeProperties merges the properties of an element present in the three versions. Collections are merged
+// separately. parent is the panel of the element when it is a query.
+func (m *merger) mergeProperties(kind ElementKind, el *element, parent *element, base, ours, theirs map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(theirs))
+	for _, key := range sortedKeys(base, ours, theirs) {
+		if kind == ElementDashboard && ignoredKeys[key] {
+			if value, ok := theirs[key]; ok {
+				merged[key] = value
+			}
+			continue
+		}
+
+		b, inBase := base[key]
+		o, inOurs := ours[key]
+		t, inTheirs := theirs[key]
+
+		if kind == ElementPanel && key == "targets" {
+			baseQueries, _ := b.([]interface{})
+			oursQueries, oursOk := o.([]interface{})
+			theirsQueries, theirsOk := t.([]interface{})
+			if oursOk && theirsOk {
+				merged[key] = m.mergeElements(queries, el, baseQueries, oursQueries, theirsQueries)
+				continue
+			}
+		}
+
+		value, ok, conflict := mergeValue(b, inBase, o, inOurs, t, inTheirs)
+		if conflict {
+			m.addConflict(kind, el, parent, propertyLabel(kind, key), "changed in both versions", b, o, t)
+		}
+		if ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// mergeElements merges the elements of a collection. Elements are kept in the order of theirs, followed by the ones
+// only added in ours. parent is the panel whose queries are merged, if any.
+func (m *merger) mergeElements(c collection, parent *element, baseList, oursList, theirsList []interface{}) []interface{} {
+	baseElements := c.elements(baseList)
+	oursElements := c.elements(oursList)
+	theirsElements := c.elements(theirsList)
+	c.match(baseElements, oursElements)
+	c.match(baseElements, theirsElements)
+	baseByKey := byKey(baseElements)
+	oursByKey := byKey(oursElements)
+	theirsByKey := byKey(theirsElements)
+
+	merged := make([]interface{}, 0, len(theirsElements))
+	for _, t := range theirsElements {
+		b, inBase := baseByKey[t.key]
+		o, inOurs := oursByKey[t.key]
+		switch {
+		case !inBase:
+			// panels added in both versions with the same id are both kept, see below
+			if inOurs && c.kind != ElementPanel && !reflect.DeepEqual(o.value, t.value) {
+				m.addConflict(c.kind, t, parent, "", "added in both versions", nil, o.value, t.value)
+			}
+			merged = append(merged, t.value)
+		case inOurs:
+			merged = append(merged, m.mergeProperties(c.kind, t, parent, b.value, o.value, t.value))
+		case !reflect.DeepEqual(b.value, t.value):
+			m.addConflict(c.kind, t, parent, "", "removed in your version and changed in the saved version", b.value, nil, t.value)
+			merged = append(merged, t.value)
+		}
+	}
+
+	for _, o := range oursElements {
+		b, inBase := baseByKey[o.key]
+		t, inTheirs := theirsByKey[o.key]
+		switch {
+		case !inBase && !inTheirs:
+			merged = append(merged, o.value)
+		case !inBase && c.kind == ElementPanel && !reflect.DeepEqual(o.value, t.value):
+			// a different panel was added with the same id in the saved version
+			o.value["id"] = m.nextPanelID
+			m.nextPanelID++
+			merged = append(merged, o.value)
+		case inBase && !inTheirs && !reflect.DeepEqual(b.value, o.value):
+			m.addConflict(c.kind, o, parent, "", "changed in your version and removed in the saved version", b.value, o.value, nil)
+		}
+	}
+	return merged
+}
+
+// addConflict records a conflict on a property of an element, or on the element itself when property is empty.
+// Conflicts on queries are reported on their panel.
+func (m *merger) addConflict(kind ElementKind, el *element, parent *element, property string, reason string, base, ours, theirs interface{}) {
+	if parent != nil {
+		kind = ElementPanel
+		property = "query " + el.key
+		el = parent
+	}
+
+	description := subject(kind, el)
+	if property != "" {
+		description += " " + property
+	}
+
+	m.conflicts = append(m.conflicts, &Conflict{
+		Element:     kind,
+		Key:         el.key,
+		Title:       el.title,
+		Property:    property,
+		Description: fmt.Sprintf("%s %s", description, reason),
+		Base:        base,
+		Ours:        ours,
+		Theirs:      theirs,
+	})
+}
+
+// mergeValue merges a value changed in ours and theirs. ok is false when the value is removed. On conflict the value
+// of theirs is kept.
+func mergeValue(base interface{}, inBase bool, ours interface{}, inOurs bool, theirs interface{}, inTheirs bool) (value interface{}, ok bool, conflict bool) {
+	equal := func(a interface{}, aOk bool, b interface{}, bOk bool) bool {
+		return aOk == bOk && (!aOk || reflect.DeepEqual(a, b))
+	}
+
+	switch {
+	case equal(ours, inOurs, theirs, inTheirs), equal(base, inBase, ours, inOurs):
+		return theirs, inTheirs, false
+	case equal(base, inBase, theirs, inTheirs):
+		return ours, inOurs, false
+	default:
+		return theirs, inTheirs, true
+	}
+}
+
+// maxPanelID returns the highest panel id of the dashboards, including the panels of collapsed rows.
+func maxPanelID(dashboards ...map[string]interface{}) float64 {
+	var max float64
+	var visit func(panels []interface{})
+	visit = func(panels []interface{}) {
+		for _, p := range panels {
+			panel, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if id, ok := panel["id"].(float64); ok && id > max {
+				max = id
+			}
+			children, _ := panel["panels"].([]interface{})
+			visit(children)
+		}
+	}
+
+	for _, dash := range dashboards {
+		panels, _ := dash["panels"].([]interface{})
+		visit(panels)
+	}
+	return max
+}
@@ -0,0 +1,121 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	const base = `{
+		"title": "Hosts",
+		"version": 1,
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "targets": [{"refId": "A", "expr": "cpu"}, {"refId": "B", "expr": "load"}]},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"}
+		],
+		"templating": {"list": [{"name": "host", "query": "hosts"}]}
+	}`
+
+	merge := func(t *testing.T, ours, theirs string) *MergeResult {
+		t.Helper()
+		baseJSON, err := simplejson.NewJson([]byte(base))
+		require.NoError(t, err)
+		oursJSON, err := simplejson.NewJson([]byte(ours))
+		require.NoError(t, err)
+		theirsJSON, err := simplejson.NewJson([]byte(theirs))
+		require.NoError(t, err)
+
+		result, err := Merge(baseJSON, oursJSON, theirsJSON)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("should merge concurrent edits of different elements", func(t *testing.T) {
+		result := merge(t, `{
+			"title": "Hosts overview",
+			"version": 1,
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries", "targets": [{"refId": "A", "expr": "cpu_usage"}, {"refId": "B", "expr": "load"}]},
+				{"id": 2, "title": "Memory", "type": "timeseries"},
+				{"id": 4, "title": "Network", "type": "timeseries"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}]}
+		}`, `{
+			"title": "Hosts",
+			"version": 2,
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries", "targets": [{"refId": "A", "expr": "cpu"}, {"refId": "B", "expr": "load5"}]},
+				{"id": 2, "title": "Memory usage", "type": "timeseries"},
+				{"id": 3, "title": "Disk", "type": "stat"},
+				{"id": 4, "title": "Uptime", "type": "stat"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}, {"name": "env", "query": "envs"}]}
+		}`)
+
+		assert.Empty(t, result.Conflicts)
+		merged := result.Dashboard
+		assert.Equal(t, "Hosts overview", merged.Get("title").MustString())
+		assert.Equal(t, 2, merged.Get("version").MustInt())
+
+		panels := merged.Get("panels").MustArray()
+		titles := make([]string, 0, len(panels))
+		for i := range panels {
+			panel := merged.Get("panels").GetIndex(i)
+			titles = append(titles, panel.Get("title").MustString())
+		}
+		assert.Equal(t, []string{"CPU", "Memory usage", "Uptime", "Network"}, titles)
+		assert.Equal(t, 5, merged.Get("panels").GetIndex(3).Get("id").MustInt(), "the panel added with a used id should be renumbered")
+
+		targets := merged.Get("panels").GetIndex(0).Get("targets")
+		assert.Equal(t, "cpu_usage", targets.GetIndex(0).Get("expr").MustString())
+		assert.Equal(t, "load5", targets.GetIndex(1).Get("expr").MustString())
+		assert.Len(t, merged.Get("templating").Get("list").MustArray(), 2)
+	})
+
+	t.Run("should report conflicting edits and keep the saved version", func(t *testing.T) {
+		result := merge(t, `{
+			"title": "Hosts",
+			"version": 1,
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries", "targets": [{"refId": "A", "expr": "cpu_usage"}, {"refId": "B", "expr": "load"}]},
+				{"id": 2, "title": "Memory", "type": "bargauge"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}]}
+		}`, `{
+			"title": "Hosts",
+			"version": 2,
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries", "targets": [{"refId": "A", "expr": "cpu_seconds"}, {"refId": "B", "expr": "load"}]},
+				{"id": 3, "title": "Disk", "type": "gauge"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}]}
+		}`)
+
+		descriptions := make([]string, 0, len(result.Conflicts))
+		for _, conflict := range result.Conflicts {
+			descriptions = append(descriptions, conflict.Description)
+		}
+		assert.Equal(t, []string{
+			`Panel "CPU" query A changed in both versions`,
+			`Panel "Disk" removed in your version and changed in the saved version`,
+			`Panel "Memory" changed in your version and removed in the saved version`,
+		}, descriptions)
+
+		conflict := result.Conflicts[0]
+		assert.Equal(t, ElementPanel, conflict.Element)
+		assert.Equal(t, "1", conflict.Key)
+		assert.Equal(t, "query A", conflict.Property)
+		assert.Equal(t, "cpu", conflict.Base)
+		assert.Equal(t, "cpu_usage", conflict.Ours)
+		assert.Equal(t, "cpu_seconds", conflict.Theirs)
+
+		panels := result.Dashboard.Get("panels").MustArray()
+		assert.Len(t, panels, 2)
+		assert.Equal(t, "cpu_seconds", result.Dashboard.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Get("expr").MustString())
+	})
+}
@@ -0,0 +1,153 @@
+package dashdiffs
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type ChangeAction string
+
+const (
+	ActionAdded   ChangeAction = "added"
+	ActionRemoved ChangeAction = "removed"
+	ActionChanged ChangeAction = "changed"
+)
+
+// SemanticChange is a change to a dashboard element, such as a panel whose query changed or a variable that was
+// added.
+type SemanticChange struct {
+	Element ElementKind  `json:"element"`
+	Action  ChangeAction `json:"action"`
+	// Key identifies the element: the id of a panel, the name of a variable or an annotation, the title of a link.
+	Key   string `json:"key,omitempty"`
+	Title string `json:"title,omitempty"`
+	// Property is the changed property of the element. It is empty when the element was added or removed.
+	Property    string `json:"property,omitempty"`
+	Description string `json:"description"`
+}
+
+// SemanticDiff returns the changes made to the settings, panels, variables, annotations and links of a dashboard
+// between two versions. Panels are matched by id, or by position when their id changed.
+func SemanticDiff(base, new *simplejson.Json) ([]*SemanticChange, error) {
+	baseData, err := decode(base)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := decode(new)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*SemanticChange, 0)
+	dashboard := &element{}
+	for _, key := range sortedKeys(baseData, newData) {
+		if ignoredKeys[key] {
+			continue
+		}
+		changes = append(changes, diffProperty(ElementDashboard, dashboard, key, baseData[key], newData[key])...)
+	}
+
+	for _, c := range collections {
+		baseList, _ := getList(baseData, c.path)
+		newList, _ := getList(newData, c.path)
+		changes = append(changes, diffElements(c, baseList, newList)...)
+	}
+	return changes, nil
+}
+
+func diffElements(c collection, baseList, newList []interface{}) []*SemanticChange {
+	baseElements := c.elements(baseList)
+	newElements := c.elements(newList)
+	c.match(baseElements, newElements)
+	baseByKey := byKey(baseElements)
+	newByKey := byKey(newElements)
+
+	changes := make([]*SemanticChange, 0)
+	for _, el := range newElements {
+		b, ok := baseByKey[el.key]
+		if !ok {
+			changes = append(changes, newChange(c.kind, el, ActionAdded, ""))
+			continue
+		}
+		for _, key := range sortedKeys(b.value, el.value) {
+			changes = appendChanges(changes, diffProperty(c.kind, el, key, b.value[key], el.value[key])...)
+		}
+	}
+
+	for _, el := range baseElements {
+		if _, ok := newByKey[el.key]; !ok {
+			changes = append(changes, newChange(c.kind, el, ActionRemoved, ""))
+		}
+	}
+	return changes
+}
+
+func diffProperty(kind ElementKind, el *element, key string, base, new interface{}) []*SemanticChange {
+	if reflect.DeepEqual(base, new) {
+		return nil
+	}
+
+	// the queries of a panel are compared one by one
+	baseQueries, baseOk := base.([]interface{})
+	newQueries, newOk := new.([]interface{})
+	if kind == ElementPanel && key == "targets" && (baseOk || base == nil) && (newOk || new == nil) {
+		changes := make([]*SemanticChange, 0)
+		for _, change := range diffElements(queries, baseQueries, newQueries) {
+			property := strings.TrimSpace("query " + change.Key)
+			action := change.Action
+			if change.Property != "" {
+				action = ActionChanged
+			}
+			changes = appendChanges(changes, newChange(kind, el, action, property))
+		}
+		if len(changes) == 0 {
+			// only the order of the queries changed
+			changes = append(changes, newChange(kind, el, ActionChanged, "query"))
+		}
+		return changes
+	}
+
+	action := ActionChanged
+	if base == nil {
+		action = ActionAdded
+	} else if new == nil {
+		action = ActionRemoved
+	}
+	return []*SemanticChange{newChange(kind, el, action, propertyLabel(kind, key))}
+}
+
+// appendChanges appends the changes that are not described yet, as several properties can share a label.
+func appendChanges(changes []*SemanticChange, added ...*SemanticChange) []*SemanticChange {
+	for _, change := range added {
+		duplicate := false
+		for _, existing := range changes {
+			if existing.Element == change.Element && existing.Key == change.Key && existing.Description == change.Description {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func newChange(kind ElementKind, el *element, action ChangeAction, property string) *SemanticChange {
+	description := subject(kind, el)
+	if property != "" {
+		description += " " + property
+	}
+	description += " " + string(action)
+
+	return &SemanticChange{
+		Element:     kind,
+		Action:      action,
+		Key:         el.key,
+		Title:       el.title,
+		Property:    property,
+		Description: description,
+	}
+}
@@ -0,0 +1,75 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestSemanticDiff(t *testing.T) {
+	base, err := simplejson.NewJson([]byte(`{
+		"title": "Hosts",
+		"version": 1,
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A", "expr": "cpu"}]},
+			{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+			{"id": 3, "title": "Disk", "type": "stat", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}}
+		],
+		"templating": {"list": [{"name": "host", "type": "query", "query": "hosts"}]},
+		"annotations": {"list": [{"name": "Deploys", "enable": true}]},
+		"links": [{"title": "Docs", "url": "https://grafana.com"}]
+	}`))
+	require.NoError(t, err)
+
+	changed, err := simplejson.NewJson([]byte(`{
+		"title": "Hosts overview",
+		"version": 2,
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A", "expr": "cpu_usage"}, {"refId": "B", "expr": "load"}]},
+			{"id": 7, "title": "Memory", "type": "bargauge", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+			{"id": 4, "title": "Network", "type": "timeseries", "gridPos": {"x": 0, "y": 16, "w": 24, "h": 8}}
+		],
+		"templating": {"list": [{"name": "host", "type": "query", "query": "hosts"}, {"name": "env", "type": "custom"}]},
+		"annotations": {"list": [{"name": "Deploys", "enable": false}]},
+		"links": []
+	}`))
+	require.NoError(t, err)
+
+	changes, err := SemanticDiff(base, changed)
+	require.NoError(t, err)
+
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		descriptions = append(descriptions, change.Description)
+	}
+	assert.Equal(t, []string{
+		"Dashboard title changed",
+		`Panel "CPU" query A changed`,
+		`Panel "CPU" query B added`,
+		`Panel "Memory" id changed`,
+		`Panel "Memory" visualization changed`,
+		`Panel "Network" added`,
+		`Panel "Disk" removed`,
+		`Variable "env" added`,
+		`Annotation "Deploys" enable changed`,
+		`Link "Docs" removed`,
+	}, descriptions)
+
+	assert.Equal(t, &SemanticChange{
+		Element:     ElementPanel,
+		Action:      ActionChanged,
+		Key:         "1",
+		Title:       "CPU",
+		Property:    "query A",
+		Description: `Panel "CPU" query A changed`,
+	}, changes[1])
+
+	t.Run("should not report changes between identical dashboards", func(t *testing.T) {
+		changes, err := SemanticDiff(base, base)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
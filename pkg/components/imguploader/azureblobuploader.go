@@ -95,6 +95,67 @@ func (az *AzureBlobUploader) Upload(ctx context.Context, imageDiskPath string) (
 	return url, nil
 }
 
+func (az *AzureBlobUploader) Put(ctx context.Context, key string, data []byte) error {
+	blob := NewStorageClient(az.account_name, az.account_key)
+
+	az.log.Debug("Uploading blob to azure_blob", "container_name", az.container_name, "blob_name", key)
+	resp, err := blob.FileUpload(ctx, az.container_name, key, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	return azureError(resp)
+}
+
+func (az *AzureBlobUploader) Get(ctx context.Context, key string) ([]byte, error) {
+	blob := NewStorageClient(az.account_name, az.account_key)
+
+	resp, err := blob.FileRequest(ctx, http.MethodGet, az.container_name, key)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	if err := azureError(resp); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (az *AzureBlobUploader) Delete(ctx context.Context, key string) error {
+	blob := NewStorageClient(az.account_name, az.account_key)
+
+	resp, err := blob.FileRequest(ctx, http.MethodDelete, az.container_name, key)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return azureError(resp)
+}
+
+func azureError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	aerr := &Error{
+		Code:   resp.StatusCode,
+		Status: resp.Status,
+		Body:   body,
+		Header: resp.Header,
+	}
+	aerr.parseXML()
+	return aerr
+}
+
 // --- AZURE LIBRARY
 type Error struct {
 	Code   int
@@ -193,6 +254,25 @@ func (c *StorageClient) FileUpload(ctx context.Context, container, blobName stri
 	return c.transport().RoundTrip(req)
 }
 
+// FileRequest sends a request without a body, such as GET or DELETE, for a blob.
+func (c *StorageClient) FileRequest(ctx context.Context, method, container, blobName string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.absUrl("%s/%s", container, escape(blobName)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	copyHeadersToRequest(req, map[string]string{
+		"x-ms-date":    time.Now().UTC().Format(ms_date_layout),
+		"x-ms-version": version,
+	})
+
+	if err := c.Auth.SignRequest(req); err != nil {
+		return nil, err
+	}
+
+	return c.transport().RoundTrip(req)
+}
+
 func escape(content string) string {
 	content = url.QueryEscape(content)
 	// the Azure's behavior uses %20 to represent whitespace instead of + (plus)
@@ -0,0 +1,70 @@
+package imguploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// BlobStore stores blobs in the external image storage. Unlike uploaded images, blobs are addressed by
+// their key, can be read back and deleted, and are not made public.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore returns the blob store of an external image storage provider, configured by its
+// [external_image_storage.<provider>] section. The local provider stores the blobs in dir.
+func NewBlobStore(provider string, dir string) (BlobStore, error) {
+	if provider == "local" {
+		return NewLocalBlobStore(dir), nil
+	}
+
+	uploader, err := newUploader(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := uploader.(BlobStore)
+	if !ok {
+		return nil, fmt.Errorf("unsupported blob storage provider %q", provider)
+	}
+	return store, nil
+}
+
+// LocalBlobStore stores blobs as files of a directory.
+type LocalBlobStore struct {
+	dir string
+}
+
+func NewLocalBlobStore(dir string) *LocalBlobStore {
+	return &LocalBlobStore{dir: dir}
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path(key), data, 0640)
+}
+
+func (s *LocalBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return ioutil.ReadFile(s.path(key))
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalBlobStore) path(key string) string {
+	// keys are generated by Grafana, Base keeps them from escaping the directory all the same
+	return filepath.Join(s.dir, filepath.Base(key))
+}
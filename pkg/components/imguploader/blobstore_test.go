@@ -0,0 +1,39 @@
+package imguploader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewBlobStore("local", t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "snapshot-abc", []byte("encrypted")))
+	data, err := store.Get(ctx, "snapshot-abc")
+	require.NoError(t, err)
+	require.Equal(t, []byte("encrypted"), data)
+
+	require.NoError(t, store.Delete(ctx, "snapshot-abc"))
+	_, err = store.Get(ctx, "snapshot-abc")
+	require.Error(t, err)
+
+	t.Run("deleting a missing blob should succeed", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, "snapshot-abc"))
+	})
+
+	t.Run("keys should not escape the directory", func(t *testing.T) {
+		dir := t.TempDir()
+		store := NewLocalBlobStore(dir + "/blobs")
+		require.NoError(t, store.Put(ctx, "../escaped", []byte("data")))
+		require.FileExists(t, dir+"/blobs/escaped")
+	})
+}
+
+func TestNewBlobStore(t *testing.T) {
+	_, err := NewBlobStore("ftp", t.TempDir())
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package gcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -72,36 +73,9 @@ func (u *Uploader) Upload(ctx context.Context, imageDiskPath string) (string, er
 
 	key := path.Join(u.path, fileName)
 
-	var keyData []byte
-	if u.KeyFile != "" {
-		u.log.Debug("Opening key file ", u.KeyFile)
-		keyData, err = ioutil.ReadFile(u.KeyFile)
-		if err != nil {
-			return "", err
-		}
-	}
-
-	const scope = storage.ScopeReadWrite
-
-	var client gcsifaces.StorageClient
-	if u.KeyFile != "" {
-		u.log.Debug("Creating Google credentials from JSON")
-		creds, err := google.CredentialsFromJSON(ctx, keyData, scope)
-		if err != nil {
-			return "", err
-		}
-
-		u.log.Debug("Creating GCS client")
-		client, err = newClient(ctx, option.WithCredentials(creds))
-		if err != nil {
-			return "", err
-		}
-	} else {
-		u.log.Debug("Creating GCS client with default application credentials")
-		client, err = newClient(ctx, option.WithScopes(scope))
-		if err != nil {
-			return "", err
-		}
+	client, keyData, err := u.newStorageClient(ctx)
+	if err != nil {
+		return "", err
 	}
 
 	if err := u.uploadFile(ctx, client, imageDiskPath, key); err != nil {
@@ -117,7 +91,7 @@ func (u *Uploader) Upload(ctx context.Context, imageDiskPath string) (string, er
 	if u.KeyFile != "" {
 		jwtData = keyData
 	} else {
-		creds, err := client.FindDefaultCredentials(ctx, scope)
+		creds, err := client.FindDefaultCredentials(ctx, storage.ScopeReadWrite)
 		if err != nil {
 			return "", fmt.Errorf("failed to find default Google credentials: %s", err)
 		}
@@ -142,6 +116,90 @@ func (u *Uploader) Upload(ctx context.Context, imageDiskPath string) (string, er
 	return signedURL, nil
 }
 
+// Put stores a blob in the bucket, blobs are never made public.
+func (u *Uploader) Put(ctx context.Context, key string, data []byte) error {
+	client, _, err := u.newStorageClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	key = path.Join(u.path, key)
+	u.log.Debug("Uploading blob to GCS bucket using SDK", "bucket", u.Bucket, "key", key)
+
+	wc := client.Bucket(u.Bucket).Object(key).NewWriter(ctx)
+	if _, err := wc.Write(data); err != nil {
+		_ = wc.Close()
+		return fmt.Errorf("failed to upload to gs://%s/%s: %s", u.Bucket, key, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("failed to upload to gs://%s/%s: %s", u.Bucket, key, err)
+	}
+	return nil
+}
+
+func (u *Uploader) Get(ctx context.Context, key string) ([]byte, error) {
+	client, _, err := u.newStorageClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := client.Bucket(u.Bucket).Object(path.Join(u.path, key)).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			u.log.Warn("Failed to close reader", "err", err)
+		}
+	}()
+
+	return ioutil.ReadAll(reader)
+}
+
+func (u *Uploader) Delete(ctx context.Context, key string) error {
+	client, _, err := u.newStorageClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = client.Bucket(u.Bucket).Object(path.Join(u.path, key)).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// newStorageClient returns a GCS client and the content of the key file, if there is one.
+func (u *Uploader) newStorageClient(ctx context.Context) (gcsifaces.StorageClient, []byte, error) {
+	var keyData []byte
+	var err error
+	if u.KeyFile != "" {
+		u.log.Debug("Opening key file ", u.KeyFile)
+		keyData, err = ioutil.ReadFile(u.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	const scope = storage.ScopeReadWrite
+
+	if u.KeyFile != "" {
+		u.log.Debug("Creating Google credentials from JSON")
+		creds, err := google.CredentialsFromJSON(ctx, keyData, scope)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		u.log.Debug("Creating GCS client")
+		client, err := newClient(ctx, option.WithCredentials(creds))
+		return client, keyData, err
+	}
+
+	u.log.Debug("Creating GCS client with default application credentials")
+	client, err := newClient(ctx, option.WithScopes(scope))
+	return client, keyData, err
+}
+
 func (u *Uploader) uploadFile(
 	ctx context.Context,
 	client gcsifaces.StorageClient,
@@ -221,6 +279,14 @@ func (o objectWrapper) NewWriter(ctx context.Context) gcsifaces.StorageWriter {
 	return writerWrapper{o.object.NewWriter(ctx)}
 }
 
+func (o objectWrapper) NewReader(ctx context.Context) (io.ReadCloser, error) {
+	return o.object.NewReader(ctx)
+}
+
+func (o objectWrapper) Delete(ctx context.Context) error {
+	return o.object.Delete(ctx)
+}
+
 type writerWrapper struct {
 	*storage.Writer
 }
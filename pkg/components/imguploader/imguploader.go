@@ -32,7 +32,22 @@ var (
 )
 
 func NewImageUploader() (ImageUploader, error) {
-	switch setting.ImageUploadProvider {
+	uploader, err := newUploader(setting.ImageUploadProvider)
+	if err != nil || uploader != nil {
+		return uploader, err
+	}
+
+	if setting.ImageUploadProvider != "" {
+		logger.Error("The external image storage configuration is invalid", "unsupported provider", setting.ImageUploadProvider)
+	}
+
+	return NopImageUploader{}, nil
+}
+
+// newUploader returns the uploader of a provider configured by its [external_image_storage.<provider>] section,
+// or nil if the provider is unknown.
+func newUploader(provider string) (ImageUploader, error) {
+	switch provider {
 	case "s3":
 		s3sec, err := setting.Raw.GetSection("external_image_storage.s3")
 		if err != nil {
@@ -115,11 +130,7 @@ func NewImageUploader() (ImageUploader, error) {
 		return NewLocalImageUploader()
 	}
 
-	if setting.ImageUploadProvider != "" {
-		logger.Error("The external image storage configuration is invalid", "unsupported provider", setting.ImageUploadProvider)
-	}
-
-	return NopImageUploader{}, nil
+	return nil, nil
 }
 
 type s3Info struct {
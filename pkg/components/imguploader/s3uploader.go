@@ -1,8 +1,10 @@
 package imguploader
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -48,27 +51,6 @@ func NewS3Uploader(endpoint, region, bucket, path, acl, accessKey, secretKey str
 }
 
 func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string, error) {
-	sess, err := session.NewSession()
-	if err != nil {
-		return "", err
-	}
-	creds := credentials.NewChainCredentials(
-		[]credentials.Provider{
-			&credentials.StaticProvider{Value: credentials.Value{
-				AccessKeyID:     u.accessKey,
-				SecretAccessKey: u.secretKey,
-			}},
-			&credentials.EnvProvider{},
-			webIdentityProvider(sess),
-			remoteCredProvider(sess),
-		})
-	cfg := &aws.Config{
-		Region:           aws.String(u.region),
-		Endpoint:         aws.String(u.endpoint),
-		S3ForcePathStyle: aws.Bool(u.pathStyleAccess),
-		Credentials:      creds,
-	}
-
 	rand, err := util.GetRandomString(20)
 	if err != nil {
 		return "", err
@@ -89,7 +71,7 @@ func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string,
 		}
 	}()
 
-	sess, err = session.NewSession(cfg)
+	sess, err := u.newSession()
 	if err != nil {
 		return "", err
 	}
@@ -108,6 +90,84 @@ func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string,
 	return result.Location, nil
 }
 
+// Put stores a blob in the bucket with the default ACL of the bucket.
+func (u *S3Uploader) Put(ctx context.Context, key string, data []byte) error {
+	sess, err := u.newSession()
+	if err != nil {
+		return err
+	}
+
+	u.log.Debug("Uploading blob to s3.", "bucket", u.bucket, "path", u.path+key)
+	uploader := s3manager.NewUploader(sess)
+	_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(u.path + key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/octet-stream"),
+	})
+	return err
+}
+
+func (u *S3Uploader) Get(ctx context.Context, key string) ([]byte, error) {
+	sess, err := u.newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(u.path + key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := result.Body.Close(); err != nil {
+			u.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	return ioutil.ReadAll(result.Body)
+}
+
+func (u *S3Uploader) Delete(ctx context.Context, key string) error {
+	sess, err := u.newSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = s3.New(sess).DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(u.path + key),
+	})
+	return err
+}
+
+func (u *S3Uploader) newSession() (*session.Session, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	creds := credentials.NewChainCredentials(
+		[]credentials.Provider{
+			&credentials.StaticProvider{Value: credentials.Value{
+				AccessKeyID:     u.accessKey,
+				SecretAccessKey: u.secretKey,
+			}},
+			&credentials.EnvProvider{},
+			webIdentityProvider(sess),
+			remoteCredProvider(sess),
+		})
+	cfg := &aws.Config{
+		Region:           aws.String(u.region),
+		Endpoint:         aws.String(u.endpoint),
+		S3ForcePathStyle: aws.Bool(u.pathStyleAccess),
+		Credentials:      creds,
+	}
+
+	return session.NewSession(cfg)
+}
+
 func webIdentityProvider(sess client.ConfigProvider) credentials.Provider {
 	svc := sts.New(sess)
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -99,6 +100,76 @@ func (u *WebdavUploader) Upload(ctx context.Context, imgToUpload string) (string
 	return url.String(), nil
 }
 
+func (u *WebdavUploader) Put(ctx context.Context, key string, data []byte) error {
+	res, err := u.do(ctx, http.MethodPut, key, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer closeBody(res)
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent {
+		return webdavError("store blob", res)
+	}
+	return nil
+}
+
+func (u *WebdavUploader) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := u.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(res)
+
+	if res.StatusCode != http.StatusOK {
+		return nil, webdavError("get blob", res)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func (u *WebdavUploader) Delete(ctx context.Context, key string) error {
+	res, err := u.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(res)
+
+	if res.StatusCode >= 300 && res.StatusCode != http.StatusNotFound {
+		return webdavError("delete blob", res)
+	}
+	return nil
+}
+
+func (u *WebdavUploader) do(ctx context.Context, method, key string, body io.Reader) (*http.Response, error) {
+	url, err := url.Parse(u.url)
+	if err != nil {
+		return nil, err
+	}
+	url.Path = path.Join(url.Path, key)
+
+	req, err := http.NewRequestWithContext(ctx, method, url.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if u.username != "" {
+		req.SetBasicAuth(u.username, u.password)
+	}
+	return netClient.Do(req)
+}
+
+func closeBody(res *http.Response) {
+	if err := res.Body.Close(); err != nil {
+		logger.Warn("Failed to close response body", "err", err)
+	}
+}
+
+func webdavError(action string, res *http.Response) error {
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	return fmt.Errorf("failed to %s, statuscode: %d, body: %s", action, res.StatusCode, body)
+}
+
 func NewWebdavImageUploader(url, username, password, public_url string) (*WebdavUploader, error) {
 	return &WebdavUploader{
 		url:        url,
@@ -62,3 +62,17 @@ type DataSourceCreated struct {
 	UID       string    `json:"uid"`
 	OrgID     int64     `json:"org_id"`
 }
+
+type DashboardSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	IsFolder  bool      `json:"is_folder"`
+}
+
+type DashboardDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	OrgID     int64     `json:"org_id"`
+}
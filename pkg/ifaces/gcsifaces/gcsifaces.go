@@ -33,6 +33,10 @@ type StorageBucket interface {
 type StorageObject interface {
 	// NewWriter returns a new StorageWriter.
 	NewWriter(ctx context.Context) StorageWriter
+	// NewReader returns a reader of the object content.
+	NewReader(ctx context.Context) (io.ReadCloser, error)
+	// Delete deletes the object.
+	Delete(ctx context.Context) error
 }
 
 // StorageWriter represents a GCS writer.
@@ -0,0 +1,102 @@
+package geoip
+
+import (
+	"net"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is the approximate location of an IP address.
+type Location struct {
+	City        string
+	Country     string
+	CountryCode string
+}
+
+// String returns a human readable representation of the location.
+func (l *Location) String() string {
+	parts := make([]string, 0, 2)
+	if l.City != "" {
+		parts = append(parts, l.City)
+	}
+	if l.Country != "" {
+		parts = append(parts, l.Country)
+	} else if l.CountryCode != "" {
+		parts = append(parts, l.CountryCode)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Service resolves IP addresses to locations using a local MaxMind DB file
+// such as GeoLite2 City or Country.
+type Service struct {
+	reader *maxminddb.Reader
+	log    log.Logger
+}
+
+// record holds the fields of GeoLite2 and GeoIP2 City and Country records
+// that make up a location.
+type record struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	s := &Service{log: log.New("geoip")}
+	if cfg.LoginSessionGeoIPDatabase == "" {
+		return s
+	}
+
+	reader, err := maxminddb.Open(cfg.LoginSessionGeoIPDatabase)
+	if err != nil {
+		s.log.Error("Failed to open GeoIP database, locations will not be resolved", "path", cfg.LoginSessionGeoIPDatabase, "error", err)
+		return s
+	}
+	s.reader = reader
+	return s
+}
+
+// IsEnabled reports whether a GeoIP database is loaded.
+func (s *Service) IsEnabled() bool {
+	return s != nil && s.reader != nil
+}
+
+// Lookup returns the location of the IP address, or nil if it is unknown.
+func (s *Service) Lookup(ip string) *Location {
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil
+	}
+
+	var r record
+	_, found, err := s.reader.LookupNetwork(parsed, &r)
+	if err != nil {
+		s.log.Debug("Failed to look up IP address", "ip", ip, "error", err)
+		return nil
+	}
+	if !found {
+		return nil
+	}
+
+	location := &Location{
+		City:        r.City.Names["en"],
+		Country:     r.Country.Names["en"],
+		CountryCode: r.Country.ISOCode,
+	}
+	if *location == (Location{}) {
+		return nil
+	}
+	return location
+}
@@ -0,0 +1,202 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const (
+	dataSectionSeparatorSize = 16
+
+	typeString = 2
+	typeMap    = 7
+	typeUint16 = 5
+	typeUint32 = 6
+)
+
+// encode encodes a value using the MaxMind DB data section format.
+// See https://maxmind.github.io/MaxMind-DB/
+func encode(t *testing.T, value interface{}) []byte {
+	t.Helper()
+
+	// control returns the control byte of the type followed by the size,
+	// which takes up to 3 more bytes for sizes of 29 and more.
+	control := func(typeNum int, size int) []byte {
+		var sizeBits int
+		var sizeBytes []byte
+		switch {
+		case size < 29:
+			sizeBits = size
+		case size < 285:
+			sizeBits, sizeBytes = 29, []byte{byte(size - 29)}
+		case size < 65821:
+			sizeBits, sizeBytes = 30, []byte{byte((size - 285) >> 8), byte(size - 285)}
+		default:
+			sizeBits, sizeBytes = 31, []byte{byte((size - 65821) >> 16), byte((size - 65821) >> 8), byte(size - 65821)}
+		}
+		require.Less(t, typeNum, 8)
+		return append([]byte{byte(typeNum<<5 | sizeBits)}, sizeBytes...)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return append(control(typeString, len(v)), v...)
+	case uint16:
+		return append(control(typeUint16, 2), byte(v>>8), byte(v))
+	case uint32:
+		return append(control(typeUint32, 4), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf := control(typeMap, len(v))
+		for _, k := range keys {
+			buf = append(buf, encode(t, k)...)
+			buf = append(buf, encode(t, v[k])...)
+		}
+		return buf
+	}
+	t.Fatalf("unsupported type %T", value)
+	return nil
+}
+
+// buildDatabase returns an IPv6 MaxMind DB with 24 bit records holding a
+// single record for the IPv4 network.
+func buildDatabase(t *testing.T, network string, record map[string]interface{}) []byte {
+	t.Helper()
+
+	_, ipNet, err := net.ParseCIDR(network)
+	require.NoError(t, err)
+	ones, _ := ipNet.Mask.Size()
+	// IPv4 networks are stored under ::/96 rather than ::ffff:0:0/96.
+	ip := append(make([]byte, 12), ipNet.IP.To4()...)
+	prefixLen := 96 + ones
+
+	nodeCount := prefixLen
+	dataPointer := nodeCount + dataSectionSeparatorSize
+
+	var tree []byte
+	putRecord := func(value int) {
+		tree = append(tree, byte(value>>16), byte(value>>8), byte(value))
+	}
+	for i := 0; i < prefixLen; i++ {
+		next := i + 1
+		if next == prefixLen {
+			next = dataPointer
+		}
+		if (ip[i/8]>>(7-uint(i%8)))&1 == 0 {
+			putRecord(next)
+			putRecord(nodeCount)
+		} else {
+			putRecord(nodeCount)
+			putRecord(next)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(tree)
+	buf.Write(make([]byte, dataSectionSeparatorSize))
+	buf.Write(encode(t, record))
+	buf.Write(metadataStartMarker)
+	buf.Write(encode(t, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(6),
+		"database_type":               "GeoLite2-City",
+	}))
+	return buf.Bytes()
+}
+
+func provideTestService(t *testing.T, db []byte) *Service {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	require.NoError(t, os.WriteFile(path, db, 0600))
+
+	cfg := setting.NewCfg()
+	cfg.LoginSessionGeoIPDatabase = path
+	s := ProvideService(cfg)
+	require.True(t, s.IsEnabled())
+	return s
+}
+
+func TestService(t *testing.T) {
+	s := provideTestService(t, buildDatabase(t, "81.2.69.0/24", map[string]interface{}{
+		"city": map[string]interface{}{
+			"names": map[string]interface{}{"en": "London"},
+		},
+		"country": map[string]interface{}{
+			"iso_code": "GB",
+			"names":    map[string]interface{}{"en": "United Kingdom"},
+		},
+	}))
+
+	location := s.Lookup("81.2.69.142")
+	require.Equal(t, &Location{City: "London", Country: "United Kingdom", CountryCode: "GB"}, location)
+	require.Equal(t, "London, United Kingdom", location.String())
+
+	require.Nil(t, s.Lookup("81.2.70.1"))
+	require.Nil(t, s.Lookup("2001:db8::1"))
+	require.Nil(t, s.Lookup("not an ip"))
+}
+
+func TestServiceWithoutDatabase(t *testing.T) {
+	s := ProvideService(setting.NewCfg())
+	require.False(t, s.IsEnabled())
+	require.Nil(t, s.Lookup("81.2.69.142"))
+
+	cfg := setting.NewCfg()
+	cfg.LoginSessionGeoIPDatabase = filepath.Join(t.TempDir(), "missing.mmdb")
+	require.False(t, ProvideService(cfg).IsEnabled())
+}
+
+// TestServiceSizeEncodings covers the sizes of 29 and more, which are encoded
+// in 1 to 3 bytes following the control byte.
+func TestServiceSizeEncodings(t *testing.T) {
+	for _, size := range []int{28, 29, 284, 285, 1000, 65820, 65821, 70000} {
+		t.Run(strconv.Itoa(size), func(t *testing.T) {
+			city := strings.Repeat("a", size)
+			names := map[string]interface{}{"en": "United Kingdom"}
+			// Maps use the same size encoding for their number of entries.
+			for i := 1; len(names) < size%300; i++ {
+				names["lang-"+strconv.Itoa(i)] = "United Kingdom"
+			}
+
+			s := provideTestService(t, buildDatabase(t, "81.2.69.0/24", map[string]interface{}{
+				"city": map[string]interface{}{
+					"names": map[string]interface{}{"en": city},
+				},
+				"country": map[string]interface{}{
+					"iso_code": "GB",
+					"names":    names,
+				},
+			}))
+			require.Equal(t, &Location{City: city, Country: "United Kingdom", CountryCode: "GB"}, s.Lookup("81.2.69.142"))
+		})
+	}
+}
+
+func TestServiceInvalidDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("not a database"), 0600))
+
+	cfg := setting.NewCfg()
+	cfg.LoginSessionGeoIPDatabase = path
+	require.False(t, ProvideService(cfg).IsEnabled())
+}
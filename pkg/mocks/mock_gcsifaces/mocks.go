@@ -6,6 +6,7 @@ package mock_gcsifaces
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	storage "cloud.google.com/go/storage"
@@ -171,6 +172,35 @@ func (mr *MockStorageObjectMockRecorder) NewWriter(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewWriter", reflect.TypeOf((*MockStorageObject)(nil).NewWriter), ctx)
 }
 
+// NewReader mocks base method
+func (m *MockStorageObject) NewReader(ctx context.Context) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewReader", ctx)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewReader indicates an expected call of NewReader
+func (mr *MockStorageObjectMockRecorder) NewReader(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewReader", reflect.TypeOf((*MockStorageObject)(nil).NewReader), ctx)
+}
+
+// Delete mocks base method
+func (m *MockStorageObject) Delete(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockStorageObjectMockRecorder) Delete(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorageObject)(nil).Delete), ctx)
+}
+
 // MockStorageWriter is a mock of StorageWriter interface
 type MockStorageWriter struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"strings"
+)
+
+type DashboardReferenceKind string
+
+const (
+	DashboardReferenceDatasource     DashboardReferenceKind = "datasource"
+	DashboardReferencePanel          DashboardReferenceKind = "panel"
+	DashboardReferenceLibraryElement DashboardReferenceKind = "library_element"
+)
+
+const (
+	mixedDatasource = "-- Mixed --"
+	// maxReferenceValueLength is the length of the string columns of the dashboard_reference table
+	maxReferenceValueLength = 190
+)
+
+// DashboardReference is a reference from a dashboard to a data source, a panel plugin or a library element.
+//
+// Data sources are referenced by uid, by name in dashboards that have not been migrated to uids, or through a
+// template variable, in which case Type is the type of data source the variable selects. A reference without uid,
+// name or variable is a reference to the default data source.
+type DashboardReference struct {
+	Id          int64                  `json:"-"`
+	OrgId       int64                  `json:"-"`
+	DashboardId int64                  `json:"dashboardId"`
+	Kind        DashboardReferenceKind `json:"kind"`
+	Uid         string                 `json:"uid,omitempty"`
+	Name        string                 `json:"name,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Variable    string                 `json:"variable,omitempty"`
+}
+
+// GetReferences returns the data sources, panel plugins and library elements the dashboard uses.
+func (d *Dashboard) GetReferences() []*DashboardReference {
+	c := &referenceCollector{
+		dashboard: d,
+		seen:      make(map[DashboardReference]bool),
+		variables: make(map[string]map[string]interface{}),
+	}
+
+	variables, _ := d.Data.Get("templating").Get("list").Array()
+	for _, v := range variables {
+		if v, ok := v.(map[string]interface{}); ok {
+			c.variables[stringValue(v["name"])] = v
+		}
+	}
+
+	c.addPanels(arrayValue(d.Data.Get("panels").Interface()))
+	for _, row := range arrayValue(d.Data.Get("rows").Interface()) {
+		if row, ok := row.(map[string]interface{}); ok {
+			c.addPanels(arrayValue(row["panels"]))
+		}
+	}
+
+	for _, v := range variables {
+		v, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch stringValue(v["type"]) {
+		case "datasource":
+			c.add(DashboardReference{Kind: DashboardReferenceDatasource, Type: stringValue(v["query"]), Variable: stringValue(v["name"])})
+		case "query":
+			c.addDatasource(v["datasource"])
+		}
+	}
+
+	annotations, _ := d.Data.Get("annotations").Get("list").Array()
+	for _, a := range annotations {
+		a, ok := a.(map[string]interface{})
+		if !ok || a["datasource"] == nil {
+			continue
+		}
+		if builtIn, _ := a["builtIn"].(float64); builtIn == 1 {
+			continue
+		}
+		c.addDatasource(a["datasource"])
+	}
+
+	return c.refs
+}
+
+type referenceCollector struct {
+	dashboard *Dashboard
+	refs      []*DashboardReference
+	seen      map[DashboardReference]bool
+	variables map[string]map[string]interface{}
+}
+
+func (c *referenceCollector) add(ref DashboardReference) {
+	ref.OrgId = c.dashboard.OrgId
+	ref.DashboardId = c.dashboard.Id
+	ref.Uid = truncateReferenceValue(ref.Uid)
+	ref.Name = truncateReferenceValue(ref.Name)
+	ref.Type = truncateReferenceValue(ref.Type)
+	ref.Variable = truncateReferenceValue(ref.Variable)
+	if c.seen[ref] {
+		return
+	}
+	c.seen[ref] = true
+	c.refs = append(c.refs, &ref)
+}
+
+func (c *referenceCollector) addPanels(panels []interface{}) {
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if panelType := stringValue(panel["type"]); panelType != "" && panelType != "row" {
+			c.add(DashboardReference{Kind: DashboardReferencePanel, Type: panelType})
+		}
+
+		if libraryPanel, ok := panel["libraryPanel"].(map[string]interface{}); ok {
+			c.add(DashboardReference{
+				Kind: DashboardReferenceLibraryElement,
+				Uid:  stringValue(libraryPanel["uid"]),
+				Name: stringValue(libraryPanel["name"]),
+			})
+		}
+
+		targets := arrayValue(panel["targets"])
+		mixed := isMixedDatasource(panel["datasource"])
+		// panels without queries, such as text panels, do not use the default data source
+		if !mixed && (panel["datasource"] != nil || len(targets) > 0) {
+			c.addDatasource(panel["datasource"])
+		}
+
+		for _, t := range targets {
+			target, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// the queries of a panel that is not mixed use the data source of the panel when they have none
+			if target["datasource"] != nil || mixed {
+				c.addDatasource(target["datasource"])
+			}
+		}
+
+		// collapsed rows hold their panels
+		c.addPanels(arrayValue(panel["panels"]))
+	}
+}
+
+func (c *referenceCollector) addDatasource(ref interface{}) {
+	switch ref := ref.(type) {
+	case nil:
+		c.add(DashboardReference{Kind: DashboardReferenceDatasource})
+	case string:
+		switch {
+		case ref == "" || ref == "default":
+			c.add(DashboardReference{Kind: DashboardReferenceDatasource})
+		case ref == mixedDatasource:
+		case strings.HasPrefix(ref, "$"):
+			c.addDatasourceVariable(ref, "")
+		default:
+			c.add(DashboardReference{Kind: DashboardReferenceDatasource, Name: ref})
+		}
+	case map[string]interface{}:
+		uid, dsType := stringValue(ref["uid"]), stringValue(ref["type"])
+		switch {
+		case uid == "" && dsType == "":
+			c.add(DashboardReference{Kind: DashboardReferenceDatasource})
+		case uid == mixedDatasource:
+		case strings.HasPrefix(uid, "$"):
+			c.addDatasourceVariable(uid, dsType)
+		default:
+			c.add(DashboardReference{Kind: DashboardReferenceDatasource, Uid: uid, Type: dsType})
+		}
+	}
+}
+
+// addDatasourceVariable adds a reference to the data sources selected by a template variable, such as $ds or
+// ${ds:raw}.
+func (c *referenceCollector) addDatasourceVariable(ref string, dsType string) {
+	name := strings.TrimPrefix(ref, "$")
+	if strings.HasPrefix(name, "{") {
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "{"), "}")
+		if i := strings.Index(name, ":"); i >= 0 {
+			name = name[:i]
+		}
+	}
+
+	if v, ok := c.variables[name]; ok && stringValue(v["type"]) == "datasource" {
+		dsType = stringValue(v["query"])
+	}
+	c.add(DashboardReference{Kind: DashboardReferenceDatasource, Type: dsType, Variable: name})
+}
+
+func isMixedDatasource(ref interface{}) bool {
+	switch ref := ref.(type) {
+	case string:
+		return ref == mixedDatasource
+	case map[string]interface{}:
+		return stringValue(ref["uid"]) == mixedDatasource
+	}
+	return false
+}
+
+func truncateReferenceValue(value string) string {
+	if runes := []rune(value); len(runes) > maxReferenceValueLength {
+		return string(runes[:maxReferenceValueLength])
+	}
+	return value
+}
+
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func arrayValue(value interface{}) []interface{} {
+	a, _ := value.([]interface{})
+	return a
+}
+
+type GetDashboardReferencesQuery struct {
+	DashboardId int64
+	OrgId       int64
+
+	Result []*DashboardReference
+}
+
+// GetDatasourceUsageQuery returns the references of the dashboards of an organization to a data source, made by uid,
+// by name, through a template variable selecting data sources of its type or to the default data source.
+type GetDatasourceUsageQuery struct {
+	OrgId     int64
+	Uid       string
+	Name      string
+	Type      string
+	IsDefault bool
+
+	Result []*DashboardReference
+}
+
+// GetPluginUsageQuery returns the references of the dashboards of an organization to a panel plugin or to the
+// data sources of a data source plugin.
+type GetPluginUsageQuery struct {
+	OrgId    int64
+	PluginId string
+
+	Result []*DashboardReference
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboard_GetReferences(t *testing.T) {
+	json := simplejson.NewFromAny(map[string]interface{}{
+		"title": "References",
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"name": "ds", "type": "datasource", "query": "prometheus"},
+				map[string]interface{}{"name": "host", "type": "query", "datasource": map[string]interface{}{"uid": "loki-uid", "type": "loki"}},
+			},
+		},
+		"annotations": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"builtIn": float64(1), "datasource": "-- Grafana --"},
+				map[string]interface{}{"datasource": "Elastic"},
+			},
+		},
+		"panels": []interface{}{
+			map[string]interface{}{
+				"type":       "timeseries",
+				"datasource": map[string]interface{}{"uid": "${ds}", "type": "prometheus"},
+				"targets":    []interface{}{map[string]interface{}{"expr": "up"}},
+			},
+			map[string]interface{}{
+				"type":       "table",
+				"datasource": "-- Mixed --",
+				"targets": []interface{}{
+					map[string]interface{}{"datasource": map[string]interface{}{"uid": "loki-uid", "type": "loki"}},
+					map[string]interface{}{"datasource": nil},
+				},
+			},
+			map[string]interface{}{"type": "text"},
+			map[string]interface{}{
+				"type":      "row",
+				"collapsed": true,
+				"panels": []interface{}{
+					map[string]interface{}{
+						"type":         "stat",
+						"libraryPanel": map[string]interface{}{"uid": "lib-uid", "name": "Library stat"},
+						"targets":      []interface{}{map[string]interface{}{}},
+					},
+				},
+			},
+		},
+	})
+	dash := NewDashboardFromJson(json)
+	dash.Id = 10
+	dash.OrgId = 2
+
+	refs := dash.GetReferences()
+	values := make([]DashboardReference, 0, len(refs))
+	for _, ref := range refs {
+		assert.Equal(t, int64(10), ref.DashboardId)
+		assert.Equal(t, int64(2), ref.OrgId)
+		values = append(values, *ref)
+	}
+
+	expected := []DashboardReference{
+		{Kind: DashboardReferencePanel, Type: "timeseries"},
+		{Kind: DashboardReferenceDatasource, Type: "prometheus", Variable: "ds"},
+		{Kind: DashboardReferencePanel, Type: "table"},
+		{Kind: DashboardReferenceDatasource, Uid: "loki-uid", Type: "loki"},
+		{Kind: DashboardReferenceDatasource},
+		{Kind: DashboardReferencePanel, Type: "text"},
+		{Kind: DashboardReferencePanel, Type: "stat"},
+		{Kind: DashboardReferenceLibraryElement, Uid: "lib-uid", Name: "Library stat"},
+		{Kind: DashboardReferenceDatasource, Name: "Elastic"},
+	}
+	for i := range expected {
+		expected[i].DashboardId = 10
+		expected[i].OrgId = 2
+	}
+	assert.Equal(t, expected, values)
+}
+
+func TestDashboard_GetReferencesTruncatesValues(t *testing.T) {
+	long := make([]rune, maxReferenceValueLength+10)
+	for i := range long {
+		long[i] = 'é'
+	}
+	dash := NewDashboardFromJson(simplejson.NewFromAny(map[string]interface{}{
+		"panels": []interface{}{map[string]interface{}{"type": string(long)}},
+	}))
+
+	refs := dash.GetReferences()
+	if assert.Len(t, refs, 1) {
+		assert.Equal(t, string(long[:maxReferenceValueLength]), refs[0].Type)
+	}
+}
@@ -6,6 +6,16 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
+// Dashboard snapshot visibility modes
+const (
+	// SnapshotVisibilityPublic lets anyone with the link view the snapshot.
+	SnapshotVisibilityPublic = "public"
+	// SnapshotVisibilityOrg lets the members of the organization of the snapshot view it.
+	SnapshotVisibilityOrg = "org"
+	// SnapshotVisibilityTeams lets the members of the teams of the snapshot view it.
+	SnapshotVisibilityTeams = "teams"
+)
+
 // DashboardSnapshot model
 type DashboardSnapshot struct {
 	Id                int64
@@ -18,23 +28,51 @@ type DashboardSnapshot struct {
 	ExternalUrl       string
 	ExternalDeleteUrl string
 
+	// Visibility is empty for the snapshots created before visibility modes, they are public.
+	Visibility string
+	TeamIds    []int64
+
 	Expires time.Time
 	Created time.Time
 	Updated time.Time
 
 	Dashboard          *simplejson.Json
 	DashboardEncrypted []byte
+	// BlobKey is the key of the encrypted dashboard in the blob storage, if it is not stored in the database.
+	BlobKey string
+}
+
+// IsPublic returns true if anyone with the link can view the snapshot.
+func (s *DashboardSnapshot) IsPublic() bool {
+	return s.Visibility == "" || s.Visibility == SnapshotVisibilityPublic
+}
+
+// DashboardSnapshotView is a view of a dashboard snapshot, UserId is 0 for anonymous views.
+type DashboardSnapshotView struct {
+	Id         int64
+	SnapshotId int64
+	OrgId      int64
+	UserId     int64
+	Viewed     time.Time
+}
+
+type DashboardSnapshotViewDTO struct {
+	UserId int64     `json:"userId"`
+	Login  string    `json:"login"`
+	Viewed time.Time `json:"viewed"`
 }
 
 // DashboardSnapshotDTO without dashboard map
 type DashboardSnapshotDTO struct {
-	Id          int64  `json:"id"`
-	Name        string `json:"name"`
-	Key         string `json:"key"`
-	OrgId       int64  `json:"orgId"`
-	UserId      int64  `json:"userId"`
-	External    bool   `json:"external"`
-	ExternalUrl string `json:"externalUrl"`
+	Id          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Key         string  `json:"key"`
+	OrgId       int64   `json:"orgId"`
+	UserId      int64   `json:"userId"`
+	External    bool    `json:"external"`
+	ExternalUrl string  `json:"externalUrl"`
+	Visibility  string  `json:"visibility"`
+	TeamIds     []int64 `json:"teamIds"`
 
 	Expires time.Time `json:"expires"`
 	Created time.Time `json:"created"`
@@ -57,10 +95,14 @@ type CreateDashboardSnapshotCommand struct {
 	Key       string `json:"key"`
 	DeleteKey string `json:"deleteKey"`
 
+	Visibility string  `json:"visibility"`
+	TeamIds    []int64 `json:"teamIds"`
+
 	OrgId  int64 `json:"-"`
 	UserId int64 `json:"-"`
 
 	DashboardEncrypted []byte `json:"-"`
+	BlobKey            string `json:"-"`
 
 	Result *DashboardSnapshot
 }
@@ -70,16 +112,46 @@ type DeleteDashboardSnapshotCommand struct {
 }
 
 type DeleteExpiredSnapshotsCommand struct {
+	// Before is the time snapshots expired before are deleted, the current time when zero
+	Before time.Time
+
 	DeletedRows int64
 }
 
+type UpdateDashboardSnapshotVisibilityCommand struct {
+	Visibility string  `json:"visibility"`
+	TeamIds    []int64 `json:"teamIds"`
+
+	Key string `json:"-"`
+}
+
+type RecordDashboardSnapshotViewCommand struct {
+	SnapshotId int64
+	OrgId      int64
+	UserId     int64
+}
+
 type GetDashboardSnapshotQuery struct {
 	Key       string
 	DeleteKey string
+	// WithoutDashboard skips loading the dashboard, which can live in the blob storage.
+	WithoutDashboard bool
 
 	Result *DashboardSnapshot
 }
 
+// LoadDashboardSnapshotQuery loads the dashboard of a snapshot queried without it.
+type LoadDashboardSnapshotQuery struct {
+	Snapshot *DashboardSnapshot
+}
+
+type GetDashboardSnapshotViewsQuery struct {
+	SnapshotId int64
+	Limit      int
+
+	Result []*DashboardSnapshotViewDTO
+}
+
 type DashboardSnapshotsList []*DashboardSnapshotDTO
 
 type GetDashboardSnapshotsQuery struct {
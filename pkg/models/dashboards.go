@@ -373,6 +373,28 @@ type DeleteDashboardCommand struct {
 	ForceDeleteFolderRules bool
 }
 
+// RestoreDashboardCommand restores a dashboard, or a folder and the dashboards deleted with it, from the trash.
+type RestoreDashboardCommand struct {
+	Id       int64
+	OrgId    int64
+	FolderId int64  // folder the dashboard is restored into, ignored for folders
+	Uid      string // replaces the uid the dashboard had when it was deleted
+	Title    string // replaces the title the dashboard had when it was deleted
+
+	Result   *Dashboard
+	Children []*Dashboard
+}
+
+type PurgeDashboardCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type PurgeDeletedDashboardsCommand struct {
+	OlderThan   time.Time
+	DeletedRows int64
+}
+
 type DeleteOrphanedProvisionedDashboardsCommand struct {
 	ReaderNames []string
 }
@@ -390,6 +412,30 @@ type GetDashboardQuery struct {
 	Result *Dashboard
 }
 
+// DeletedDashboard is a dashboard or folder in the trash, with the uid and title it had when it was deleted.
+type DeletedDashboard struct {
+	Id       int64
+	OrgId    int64
+	Uid      string
+	Title    string
+	FolderId int64
+	IsFolder bool
+	Deleted  time.Time
+}
+
+type GetDeletedDashboardsQuery struct {
+	OrgId int64
+
+	Result []*DeletedDashboard
+}
+
+type GetDeletedDashboardQuery struct {
+	Id    int64
+	OrgId int64
+
+	Result *DeletedDashboard
+}
+
 type DashboardTagCloudItem struct {
 	Term  string `json:"term"`
 	Count int    `json:"count"`
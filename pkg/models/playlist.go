@@ -1,17 +1,35 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 )
 
 // Typed errors
 var (
-	ErrPlaylistNotFound = errors.New("Playlist not found")
+	ErrPlaylistNotFound             = errors.New("Playlist not found")
+	ErrPlaylistWithSameUIDExists    = errors.New("A playlist with the same uid already exists")
+	ErrPlaylistInvalidUID           = errors.New("uid contains illegal characters")
+	ErrPlaylistUIDTooLong           = errors.New("uid too long, max 40 characters")
+	ErrPlaylistInvalidItemType      = errors.New("Playlist item type is invalid")
+	ErrPlaylistInvalidItemInterval  = errors.New("Playlist item interval is invalid")
+	ErrPlaylistSearchItemIncomplete = errors.New("Playlist search item needs a title, a folder or a tag")
+)
+
+// Playlist item types
+const (
+	PlaylistItemTypeDashboardByID  = "dashboard_by_id"
+	PlaylistItemTypeDashboardByTag = "dashboard_by_tag"
+	// PlaylistItemTypeDashboardByUID is a dashboard shown with the variable values and time range of its options.
+	PlaylistItemTypeDashboardByUID = "dashboard_by_uid"
+	// PlaylistItemTypeDashboardSearch is a search resolved when the playlist is played, its value is the title pattern.
+	PlaylistItemTypeDashboardSearch = "dashboard_search"
 )
 
 // Playlist model
 type Playlist struct {
 	Id       int64  `json:"id"`
+	Uid      string `json:"uid"`
 	Name     string `json:"name"`
 	Interval string `json:"interval"`
 	OrgId    int64  `json:"-"`
@@ -19,6 +37,7 @@ type Playlist struct {
 
 type PlaylistDTO struct {
 	Id       int64             `json:"id"`
+	Uid      string            `json:"uid"`
 	Name     string            `json:"name"`
 	Interval string            `json:"interval"`
 	OrgId    int64             `json:"-"`
@@ -26,12 +45,14 @@ type PlaylistDTO struct {
 }
 
 type PlaylistItemDTO struct {
-	Id         int64  `json:"id"`
-	PlaylistId int64  `json:"playlistid"`
-	Type       string `json:"type"`
-	Title      string `json:"title"`
-	Value      string `json:"value"`
-	Order      int    `json:"order"`
+	Id         int64                `json:"id"`
+	PlaylistId int64                `json:"playlistid"`
+	Type       string               `json:"type"`
+	Title      string               `json:"title"`
+	Value      string               `json:"value"`
+	Order      int                  `json:"order"`
+	Interval   string               `json:"interval,omitempty"`
+	Options    *PlaylistItemOptions `json:"options,omitempty"`
 }
 
 type PlaylistItem struct {
@@ -41,6 +62,31 @@ type PlaylistItem struct {
 	Value      string
 	Order      int
 	Title      string
+	Interval   string
+	Options    *PlaylistItemOptions
+}
+
+// PlaylistItemOptions holds the settings of the dashboard_by_uid and dashboard_search playlist items.
+type PlaylistItemOptions struct {
+	// FolderUIDs and Tags narrow down the dashboards of a search item.
+	FolderUIDs []string `json:"folderUids,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+
+	// Variables, From and To are applied to the dashboards of the item.
+	Variables map[string][]string `json:"variables,omitempty"`
+	From      string              `json:"from,omitempty"`
+	To        string              `json:"to,omitempty"`
+}
+
+func (o *PlaylistItemOptions) FromDB(data []byte) error {
+	return json.Unmarshal(data, o)
+}
+
+func (o *PlaylistItemOptions) ToDB() ([]byte, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
 }
 
 type Playlists []*Playlist
@@ -60,6 +106,7 @@ type UpdatePlaylistCommand struct {
 }
 
 type CreatePlaylistCommand struct {
+	Uid      string            `json:"uid"`
 	Name     string            `json:"name" binding:"Required"`
 	Interval string            `json:"interval"`
 	Items    []PlaylistItemDTO `json:"items"`
@@ -90,6 +137,12 @@ type GetPlaylistByIdQuery struct {
 	Result *Playlist
 }
 
+type GetPlaylistByUidQuery struct {
+	Uid    string
+	OrgId  int64
+	Result *Playlist
+}
+
 type GetPlaylistItemsByIdQuery struct {
 	PlaylistId int64
 	Result     *[]PlaylistItem
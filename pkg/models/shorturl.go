@@ -6,7 +6,10 @@ import (
 )
 
 var (
-	ErrShortURLNotFound = errors.New("short URL not found")
+	ErrShortURLNotFound          = errors.New("short URL not found")
+	ErrShortURLWithSameUIDExists = errors.New("a short URL with the same uid already exists")
+	ErrShortURLInvalidUID        = errors.New("uid contains illegal characters")
+	ErrShortURLUIDTooLong        = errors.New("uid too long, max 40 characters")
 )
 
 type ShortUrl struct {
@@ -17,6 +20,32 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is the unix time after which the short URL stops redirecting, 0 if it does not expire.
+	ExpiresAt int64
+	// Pinned short URLs never expire and are never deleted by the cleanup.
+	Pinned bool
+	Hits   int64
+}
+
+// IsExpired returns true if the short URL has passed its expiry time.
+func (s *ShortUrl) IsExpired(now time.Time) bool {
+	return !s.Pinned && s.ExpiresAt > 0 && s.ExpiresAt <= now.Unix()
+}
+
+// ShortUrlHit counts the hits of a short URL on a day, formatted as YYYY-MM-DD in UTC.
+type ShortUrlHit struct {
+	Id         int64
+	ShortUrlId int64
+	Day        string
+	Hits       int64
+}
+
+type CreateShortUrlCommand struct {
+	Path string
+	// Uid is the alias of the short URL, a random uid is generated when empty.
+	Uid       string
+	ExpiresAt int64
+	Pinned    bool
 }
 
 type DeleteShortUrlCommand struct {
@@ -24,3 +53,27 @@ type DeleteShortUrlCommand struct {
 
 	NumDeleted int64
 }
+
+type ShortUrlSearchHit struct {
+	Id             int64
+	Uid            string
+	Path           string
+	CreatedBy      int64
+	CreatedByLogin string
+	CreatedAt      int64
+	LastSeenAt     int64
+	ExpiresAt      int64
+	Pinned         bool
+	Hits           int64
+}
+
+type SearchShortUrlsQuery struct {
+	OrgId     int64
+	CreatedBy int64
+	// Path filters the short URLs by the prefix of their target path.
+	Path  string
+	Limit int
+	Page  int
+
+	Result []*ShortUrlSearchHit
+}
@@ -56,6 +56,7 @@ type UserToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
+	LastSeenAt    int64
 	UnhashedToken string
 }
 
@@ -20,6 +20,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/reports"
+	"github.com/grafana/grafana/pkg/services/search/fulltext"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
@@ -34,7 +36,8 @@ func ProvideBackgroundServiceRegistry(
 	tracing tracing.Tracer, remoteCache *remotecache.RemoteCache, secretsService *secretsManager.SecretsService,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ *plugindashboards.Service, _ *dashboardsnapshots.Service, _ *pluginsettings.Service,
-	_ *alerting.AlertNotificationService, _ serviceaccounts.Service,
+	_ *alerting.AlertNotificationService, _ serviceaccounts.Service, fullTextSearch *fulltext.Service,
+	reportsService *reports.Service,
 ) *BackgroundServiceRegistry {
 	return NewBackgroundServiceRegistry(
 		httpServer,
@@ -53,7 +56,9 @@ func ProvideBackgroundServiceRegistry(
 		usageStats,
 		tracing,
 		remoteCache,
-		secretsService)
+		secretsService,
+		fullTextSearch,
+		reportsService)
 }
 
 // BackgroundServiceRegistry provides background services.
@@ -29,10 +29,51 @@ type AccessControl interface {
 	RegisterAttributeScopeResolver(scopePrefix string, resolver AttributeScopeResolveFunc)
 }
 
+// PermissionExplainer is implemented by access control services able to tell
+// which roles and assignments grant the permissions of users and teams.
+type PermissionExplainer interface {
+	// ExplainUser evaluates access of the user and lists the permissions involved in the decision
+	ExplainUser(ctx context.Context, user *models.SignedInUser, evaluator Evaluator) (*Explanation, error)
+	// ExplainTeam evaluates the access granted by the roles assigned to a team
+	ExplainTeam(ctx context.Context, orgID, teamID int64, evaluator Evaluator) (*Explanation, error)
+}
+
 type PermissionsProvider interface {
 	GetUserPermissions(ctx context.Context, query GetUserPermissionsQuery) ([]*Permission, error)
 }
 
+// RoleStore persists custom roles and their assignments to users and teams.
+// Fixed and managed roles are neither listed nor modified by it.
+type RoleStore interface {
+	PermissionsProvider
+	// GetRoles returns the custom roles of the organization
+	GetRoles(ctx context.Context, orgID int64) ([]*RoleDTO, error)
+	// GetRole returns a custom role and its permissions
+	GetRole(ctx context.Context, orgID int64, uid string) (*RoleDTO, error)
+	// CreateRole creates a custom role
+	CreateRole(ctx context.Context, orgID int64, cmd CreateRoleCommand) (*RoleDTO, error)
+	// UpdateRole replaces a custom role and its permissions
+	UpdateRole(ctx context.Context, orgID int64, uid string, cmd UpdateRoleCommand) (*RoleDTO, error)
+	// DeleteRole deletes a custom role, its permissions and its assignments
+	DeleteRole(ctx context.Context, orgID int64, uid string) error
+	// GetUserRoles returns the custom roles assigned to the user
+	GetUserRoles(ctx context.Context, orgID, userID int64) ([]*RoleDTO, error)
+	// AddUserRole assigns a custom role to a user
+	AddUserRole(ctx context.Context, orgID, userID int64, uid string) error
+	// RemoveUserRole unassigns a custom role from a user
+	RemoveUserRole(ctx context.Context, orgID, userID int64, uid string) error
+	// GetTeamRoles returns the custom roles assigned to the team
+	GetTeamRoles(ctx context.Context, orgID, teamID int64) ([]*RoleDTO, error)
+	// AddTeamRole assigns a custom role to a team
+	AddTeamRole(ctx context.Context, orgID, teamID int64, uid string) error
+	// RemoveTeamRole unassigns a custom role from a team
+	RemoveTeamRole(ctx context.Context, orgID, teamID int64, uid string) error
+	// GetPermissionOrigins returns the stored permissions of a user or a team along with the roles granting them
+	GetPermissionOrigins(ctx context.Context, query GetPermissionOriginsQuery) ([]PermissionOrigin, error)
+	// GetPermissionsGeneration returns a number that changes whenever stored roles or their assignments change
+	GetPermissionsGeneration(ctx context.Context) (int64, error)
+}
+
 type ResourcePermissionsService interface {
 	// GetPermissions returns all permissions for given resourceID
 	GetPermissions(ctx context.Context, orgID int64, resourceID string) ([]ResourcePermission, error)
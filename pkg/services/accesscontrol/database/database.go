@@ -12,6 +12,9 @@ const (
 	globalOrgID = 0
 )
 
+// permissionGenerationID is the id of the single row of the permission_generation table
+const permissionGenerationID = 1
+
 func ProvideService(sqlStore *sqlstore.SQLStore) *AccessControlStore {
 	return &AccessControlStore{sqlStore}
 }
@@ -47,6 +50,116 @@ func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query acces
 	return result, err
 }
 
+// GetPermissionsGeneration returns the generation of the stored roles, it changes whenever a role, its
+// permissions or its assignments change.
+func (s *AccessControlStore) GetPermissionsGeneration(ctx context.Context) (int64, error) {
+	var generation int64
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.SQL("SELECT generation FROM permission_generation WHERE id = ?", permissionGenerationID).Get(&generation)
+		return err
+	})
+	return generation, err
+}
+
+// bumpPermissionsGeneration invalidates the permissions cached by every instance. The row is inserted by a
+// migration, it is only recreated if it was deleted.
+func bumpPermissionsGeneration(sess *sqlstore.DBSession) error {
+	res, err := sess.Exec("UPDATE permission_generation SET generation = generation + 1 WHERE id = ?", permissionGenerationID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+	_, err = sess.Exec("INSERT INTO permission_generation (id, generation) VALUES (?, 1)", permissionGenerationID)
+	return err
+}
+
+// GetPermissionOrigins returns the stored permissions of a user or a team along with the roles and assignments granting them
+func (s *AccessControlStore) GetPermissionOrigins(ctx context.Context, query accesscontrol.GetPermissionOriginsQuery) ([]accesscontrol.PermissionOrigin, error) {
+	result := make([]accesscontrol.PermissionOrigin, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		const selectPermissions = `SELECT
+			permission.action,
+			permission.scope,
+			role.uid AS role_uid,
+			role.name AS role_name`
+
+		type originsQuery struct {
+			source string
+			sql    string
+			params []interface{}
+		}
+
+		queries := []originsQuery{}
+		if query.TeamID != 0 {
+			queries = append(queries, originsQuery{
+				source: accesscontrol.PermissionSourceTeam,
+				sql: selectPermissions + `, team.id AS team_id, team.name AS team_name
+				FROM permission
+				INNER JOIN role ON role.id = permission.role_id
+				INNER JOIN team_role ON team_role.role_id = role.id
+				INNER JOIN team ON team.id = team_role.team_id
+				WHERE team_role.team_id = ? AND team_role.org_id = ?`,
+				params: []interface{}{query.TeamID, query.OrgID},
+			})
+		} else {
+			queries = append(queries,
+				originsQuery{
+					source: accesscontrol.PermissionSourceUser,
+					sql: selectPermissions + `
+					FROM permission
+					INNER JOIN role ON role.id = permission.role_id
+					INNER JOIN user_role ON user_role.role_id = role.id
+					WHERE user_role.user_id = ? AND (user_role.org_id = ? OR user_role.org_id = ?)`,
+					params: []interface{}{query.UserID, query.OrgID, globalOrgID},
+				},
+				originsQuery{
+					source: accesscontrol.PermissionSourceTeam,
+					sql: selectPermissions + `, team.id AS team_id, team.name AS team_name
+					FROM permission
+					INNER JOIN role ON role.id = permission.role_id
+					INNER JOIN team_role ON team_role.role_id = role.id
+					INNER JOIN team ON team.id = team_role.team_id
+					INNER JOIN team_member ON team_member.team_id = team_role.team_id
+					WHERE team_member.user_id = ? AND team_role.org_id = ?`,
+					params: []interface{}{query.UserID, query.OrgID},
+				},
+			)
+			if len(query.Roles) != 0 {
+				params := make([]interface{}, 0, len(query.Roles)+2)
+				for _, role := range query.Roles {
+					params = append(params, role)
+				}
+				queries = append(queries, originsQuery{
+					source: accesscontrol.PermissionSourceBuiltInRole,
+					sql: selectPermissions + `, builtin_role.role AS built_in_role
+					FROM permission
+					INNER JOIN role ON role.id = permission.role_id
+					INNER JOIN builtin_role ON builtin_role.role_id = role.id
+					WHERE builtin_role.role IN (?` + strings.Repeat(", ?", len(query.Roles)-1) + `)
+					AND (builtin_role.org_id = ? OR builtin_role.org_id = ?)`,
+					params: append(params, query.OrgID, globalOrgID),
+				})
+			}
+		}
+
+		for _, q := range queries {
+			origins := make([]accesscontrol.PermissionOrigin, 0)
+			if err := sess.SQL(q.sql, q.params...).Find(&origins); err != nil {
+				return err
+			}
+			for i := range origins {
+				origins[i].Source = q.source
+			}
+			result = append(result, origins...)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
 func userRolesFilter(orgID, userID int64, roles []string) (string, []interface{}) {
 	q := `
 	WHERE role.id IN (
@@ -108,6 +108,53 @@ func TestAccessControlStore_GetUserPermissions(t *testing.T) {
 	}
 }
 
+func TestAccessControlStore_GetPermissionOrigins(t *testing.T) {
+	store, sql := setupTestEnv(t)
+	ctx := context.Background()
+	user, team := createUserAndTeam(t, sql, 1)
+
+	setPermission := func(resourceID string) accesscontrol.SetResourcePermissionCommand {
+		return accesscontrol.SetResourcePermissionCommand{Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceID: resourceID}
+	}
+	_, err := store.SetUserResourcePermission(ctx, 1, user.Id, setPermission("1"), nil)
+	require.NoError(t, err)
+	_, err = store.SetTeamResourcePermission(ctx, 1, team.Id, setPermission("2"), nil)
+	require.NoError(t, err)
+	_, err = store.SetBuiltInResourcePermission(ctx, 1, "Viewer", setPermission("3"), nil)
+	require.NoError(t, err)
+
+	sources := func(origins []accesscontrol.PermissionOrigin) map[string]accesscontrol.PermissionOrigin {
+		result := map[string]accesscontrol.PermissionOrigin{}
+		for _, o := range origins {
+			assert.Equal(t, "dashboards:read", o.Action)
+			assert.NotEmpty(t, o.RoleName)
+			result[o.Source] = o
+		}
+		return result
+	}
+
+	t.Run("should list user, team and built-in role permissions", func(t *testing.T) {
+		origins, err := store.GetPermissionOrigins(ctx, accesscontrol.GetPermissionOriginsQuery{OrgID: 1, UserID: user.Id, Roles: []string{"Viewer"}})
+		require.NoError(t, err)
+		require.Len(t, origins, 3)
+
+		bySource := sources(origins)
+		assert.Equal(t, "dashboards:id:1", bySource[accesscontrol.PermissionSourceUser].Scope)
+		assert.Equal(t, "dashboards:id:2", bySource[accesscontrol.PermissionSourceTeam].Scope)
+		assert.Equal(t, team.Id, bySource[accesscontrol.PermissionSourceTeam].TeamID)
+		assert.Equal(t, team.Name, bySource[accesscontrol.PermissionSourceTeam].TeamName)
+		assert.Equal(t, "dashboards:id:3", bySource[accesscontrol.PermissionSourceBuiltInRole].Scope)
+		assert.Equal(t, "Viewer", bySource[accesscontrol.PermissionSourceBuiltInRole].BuiltInRole)
+	})
+
+	t.Run("should only list team permissions for a team", func(t *testing.T) {
+		origins, err := store.GetPermissionOrigins(ctx, accesscontrol.GetPermissionOriginsQuery{OrgID: 1, TeamID: team.Id})
+		require.NoError(t, err)
+		require.Len(t, origins, 1)
+		assert.Equal(t, accesscontrol.PermissionSourceTeam, origins[0].Source)
+	})
+}
+
 func createUserAndTeam(t *testing.T, sql *sqlstore.SQLStore, orgID int64) (*models.User, models.Team) {
 	t.Helper()
 
@@ -159,6 +159,10 @@ func (s *AccessControlStore) setResourcePermission(
 		return nil, err
 	}
 
+	if err := bumpPermissionsGeneration(sess); err != nil {
+		return nil, err
+	}
+
 	var permissions []flatResourcePermission
 
 	for action := range missing {
@@ -0,0 +1,379 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// customRolesFilter excludes fixed and managed roles, which are maintained by Grafana.
+const customRolesFilter = "role.name NOT LIKE ? AND role.name NOT LIKE ?"
+
+var customRolesFilterParams = []interface{}{accesscontrol.FixedRolePrefix + "%", accesscontrol.ManagedRolePrefix + "%"}
+
+func (s *AccessControlStore) GetRoles(ctx context.Context, orgID int64) ([]*accesscontrol.RoleDTO, error) {
+	var result []*accesscontrol.RoleDTO
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		roles := make([]*accesscontrol.Role, 0)
+		params := append([]interface{}{orgID}, customRolesFilterParams...)
+		if err := sess.Table("role").Where("role.org_id = ? AND "+customRolesFilter, params...).Asc("name").Find(&roles); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = withPermissions(sess, roles)
+		return err
+	})
+
+	return result, err
+}
+
+func (s *AccessControlStore) GetRole(ctx context.Context, orgID int64, uid string) (*accesscontrol.RoleDTO, error) {
+	var result *accesscontrol.RoleDTO
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		dtos, err := withPermissions(sess, []*accesscontrol.Role{role})
+		if err != nil {
+			return err
+		}
+		result = dtos[0]
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *AccessControlStore) CreateRole(ctx context.Context, orgID int64, cmd accesscontrol.CreateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	if err := accesscontrol.ValidateCustomRole(cmd.Name, cmd.Permissions); err != nil {
+		return nil, err
+	}
+	if cmd.UID != "" && !util.IsValidShortUID(cmd.UID) {
+		return nil, accesscontrol.ErrInvalidRoleUID
+	}
+
+	var result *accesscontrol.RoleDTO
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id = ? AND name = ?", orgID, cmd.Name).Exist(&accesscontrol.Role{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return accesscontrol.ErrRoleAlreadyExists
+		}
+
+		uid := cmd.UID
+		if uid == "" {
+			if uid, err = generateNewRoleUID(sess, orgID); err != nil {
+				return err
+			}
+		} else {
+			// role uids are unique across organizations
+			exists, err := sess.Where("uid = ?", uid).Exist(&accesscontrol.Role{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return accesscontrol.ErrRoleAlreadyExists
+			}
+		}
+
+		version := cmd.Version
+		if version <= 0 {
+			version = 1
+		}
+
+		now := time.Now()
+		role := &accesscontrol.Role{
+			OrgID:       orgID,
+			Version:     version,
+			UID:         uid,
+			Name:        cmd.Name,
+			DisplayName: cmd.DisplayName,
+			Group:       cmd.Group,
+			Description: cmd.Description,
+			Created:     now,
+			Updated:     now,
+		}
+		if _, err := sess.Insert(role); err != nil {
+			return err
+		}
+
+		permissions, err := insertPermissions(sess, role.ID, cmd.Permissions, now)
+		if err != nil {
+			return err
+		}
+
+		result = toRoleDTO(role, permissions)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *AccessControlStore) UpdateRole(ctx context.Context, orgID int64, uid string, cmd accesscontrol.UpdateRoleCommand) (*accesscontrol.RoleDTO, error) {
+	if err := accesscontrol.ValidateCustomRole(cmd.Name, cmd.Permissions); err != nil {
+		return nil, err
+	}
+
+	var result *accesscontrol.RoleDTO
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Version <= role.Version {
+			return accesscontrol.ErrVersionLE
+		}
+
+		if cmd.Name != role.Name {
+			exists, err := sess.Where("org_id = ? AND name = ?", orgID, cmd.Name).Exist(&accesscontrol.Role{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return accesscontrol.ErrRoleAlreadyExists
+			}
+		}
+
+		now := time.Now()
+		role.Version = cmd.Version
+		role.Name = cmd.Name
+		role.DisplayName = cmd.DisplayName
+		role.Description = cmd.Description
+		role.Group = cmd.Group
+		role.Updated = now
+
+		if _, err := sess.ID(role.ID).Cols("version", "name", "display_name", "description", "group_name", "updated").Update(role); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM permission WHERE role_id = ?", role.ID); err != nil {
+			return err
+		}
+
+		permissions, err := insertPermissions(sess, role.ID, cmd.Permissions, now)
+		if err != nil {
+			return err
+		}
+
+		result = toRoleDTO(role, permissions)
+		return bumpPermissionsGeneration(sess)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *AccessControlStore) DeleteRole(ctx context.Context, orgID int64, uid string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		deletes := []string{
+			"DELETE FROM permission WHERE role_id = ?",
+			"DELETE FROM user_role WHERE role_id = ?",
+			"DELETE FROM team_role WHERE role_id = ?",
+			"DELETE FROM builtin_role WHERE role_id = ?",
+			"DELETE FROM role WHERE id = ?",
+		}
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, role.ID); err != nil {
+				return err
+			}
+		}
+
+		return bumpPermissionsGeneration(sess)
+	})
+}
+
+func (s *AccessControlStore) GetUserRoles(ctx context.Context, orgID, userID int64) ([]*accesscontrol.RoleDTO, error) {
+	return s.getAssignedRoles(ctx, "user_role", "user_id", orgID, userID)
+}
+
+func (s *AccessControlStore) AddUserRole(ctx context.Context, orgID, userID int64, uid string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		exists, err := sess.Where("org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, role.ID).Exist(&accesscontrol.UserRole{})
+		if err != nil || exists {
+			return err
+		}
+
+		_, err = sess.Insert(&accesscontrol.UserRole{
+			OrgID:   orgID,
+			UserID:  userID,
+			RoleID:  role.ID,
+			Created: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return bumpPermissionsGeneration(sess)
+	})
+}
+
+func (s *AccessControlStore) RemoveUserRole(ctx context.Context, orgID, userID int64, uid string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM user_role WHERE org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, role.ID); err != nil {
+			return err
+		}
+		return bumpPermissionsGeneration(sess)
+	})
+}
+
+func (s *AccessControlStore) GetTeamRoles(ctx context.Context, orgID, teamID int64) ([]*accesscontrol.RoleDTO, error) {
+	return s.getAssignedRoles(ctx, "team_role", "team_id", orgID, teamID)
+}
+
+func (s *AccessControlStore) AddTeamRole(ctx context.Context, orgID, teamID int64, uid string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		exists, err := sess.Where("org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, role.ID).Exist(&accesscontrol.TeamRole{})
+		if err != nil || exists {
+			return err
+		}
+
+		_, err = sess.Insert(&accesscontrol.TeamRole{
+			OrgID:   orgID,
+			TeamID:  teamID,
+			RoleID:  role.ID,
+			Created: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return bumpPermissionsGeneration(sess)
+	})
+}
+
+func (s *AccessControlStore) RemoveTeamRole(ctx context.Context, orgID, teamID int64, uid string) error {
+	return s.sql.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		role, err := getCustomRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM team_role WHERE org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, role.ID); err != nil {
+			return err
+		}
+		return bumpPermissionsGeneration(sess)
+	})
+}
+
+// getAssignedRoles returns the custom roles assigned through the given assignment table
+func (s *AccessControlStore) getAssignedRoles(ctx context.Context, table, column string, orgID, id int64) ([]*accesscontrol.RoleDTO, error) {
+	var result []*accesscontrol.RoleDTO
+	err := s.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		roles := make([]*accesscontrol.Role, 0)
+		params := append([]interface{}{orgID, id}, customRolesFilterParams...)
+		err := sess.Table("role").
+			Join("INNER", table, table+".role_id = role.id").
+			Where(table+".org_id = ? AND "+table+"."+column+" = ? AND "+customRolesFilter, params...).
+			Asc("role.name").
+			Cols("role.*").
+			Find(&roles)
+		if err != nil {
+			return err
+		}
+
+		result, err = withPermissions(sess, roles)
+		return err
+	})
+
+	return result, err
+}
+
+func getCustomRole(sess *sqlstore.DBSession, orgID int64, uid string) (*accesscontrol.Role, error) {
+	role := &accesscontrol.Role{}
+	has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(role)
+	if err != nil {
+		return nil, err
+	}
+	if !has || role.IsFixed() || strings.HasPrefix(role.Name, accesscontrol.ManagedRolePrefix) {
+		return nil, accesscontrol.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func insertPermissions(sess *sqlstore.DBSession, roleID int64, permissions []accesscontrol.Permission, now time.Time) ([]accesscontrol.Permission, error) {
+	result := make([]accesscontrol.Permission, 0, len(permissions))
+	seen := make(map[accesscontrol.Permission]bool, len(permissions))
+	for _, p := range permissions {
+		key := accesscontrol.Permission{Action: p.Action, Scope: p.Scope}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		permission := accesscontrol.Permission{
+			RoleID:  roleID,
+			Action:  p.Action,
+			Scope:   p.Scope,
+			Created: now,
+			Updated: now,
+		}
+		if _, err := sess.Insert(&permission); err != nil {
+			return nil, err
+		}
+		result = append(result, permission)
+	}
+	return result, nil
+}
+
+func withPermissions(sess *sqlstore.DBSession, roles []*accesscontrol.Role) ([]*accesscontrol.RoleDTO, error) {
+	result := make([]*accesscontrol.RoleDTO, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]accesscontrol.Permission, 0)
+		if err := sess.Where("role_id = ?", role.ID).Asc("action", "scope").Find(&permissions); err != nil {
+			return nil, err
+		}
+		result = append(result, toRoleDTO(role, permissions))
+	}
+	return result, nil
+}
+
+func toRoleDTO(role *accesscontrol.Role, permissions []accesscontrol.Permission) *accesscontrol.RoleDTO {
+	return &accesscontrol.RoleDTO{
+		ID:          role.ID,
+		OrgID:       role.OrgID,
+		Version:     role.Version,
+		UID:         role.UID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Group:       role.Group,
+		Permissions: permissions,
+		Updated:     role.Updated,
+		Created:     role.Created,
+	}
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+func TestAccessControlStore_CustomRoles(t *testing.T) {
+	store, sql := setupTestEnv(t)
+	ctx := context.Background()
+
+	role, err := store.CreateRole(ctx, 1, accesscontrol.CreateRoleCommand{
+		Name:        "custom:alert-rules:editor",
+		DisplayName: "Alert rules editor",
+		Permissions: []accesscontrol.Permission{
+			{Action: "alert.rules:write", Scope: "folders:uid:abc"},
+			{Action: "alert.rules:read", Scope: "folders:uid:abc"},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, role.UID)
+	assert.Equal(t, int64(1), role.Version)
+	assert.Len(t, role.Permissions, 2)
+
+	t.Run("validates roles", func(t *testing.T) {
+		_, err := store.CreateRole(ctx, 1, accesscontrol.CreateRoleCommand{Name: "custom:alert-rules:editor"})
+		assert.ErrorIs(t, err, accesscontrol.ErrRoleAlreadyExists)
+		_, err = store.CreateRole(ctx, 1, accesscontrol.CreateRoleCommand{Name: "fixed:users:writer"})
+		assert.ErrorIs(t, err, accesscontrol.ErrReservedRolePrefix)
+		_, err = store.CreateRole(ctx, 1, accesscontrol.CreateRoleCommand{Name: "invalid", Permissions: []accesscontrol.Permission{{Action: "users:read", Scope: "users*"}}})
+		assert.ErrorIs(t, err, accesscontrol.ErrInvalidScope)
+	})
+
+	t.Run("roles are scoped to the organization", func(t *testing.T) {
+		_, err := store.GetRole(ctx, 2, role.UID)
+		assert.ErrorIs(t, err, accesscontrol.ErrRoleNotFound)
+
+		roles, err := store.GetRoles(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, role.UID, roles[0].UID)
+	})
+
+	t.Run("updates require a greater version", func(t *testing.T) {
+		cmd := accesscontrol.UpdateRoleCommand{
+			Version:     1,
+			Name:        role.Name,
+			Permissions: []accesscontrol.Permission{{Action: "alert.rules:read", Scope: "folders:uid:abc"}},
+		}
+		_, err := store.UpdateRole(ctx, 1, role.UID, cmd)
+		assert.ErrorIs(t, err, accesscontrol.ErrVersionLE)
+
+		cmd.Version = 2
+		updated, err := store.UpdateRole(ctx, 1, role.UID, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated.Version)
+
+		stored, err := store.GetRole(ctx, 1, role.UID)
+		require.NoError(t, err)
+		require.Len(t, stored.Permissions, 1)
+		assert.Equal(t, "alert.rules:read", stored.Permissions[0].Action)
+	})
+
+	t.Run("assigned roles grant their permissions", func(t *testing.T) {
+		user, team := createUserAndTeam(t, sql, 1)
+		teamRole, err := store.CreateRole(ctx, 1, accesscontrol.CreateRoleCommand{
+			Name:        "custom:team",
+			Permissions: []accesscontrol.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, role.UID))
+		require.NoError(t, store.AddUserRole(ctx, 1, user.Id, role.UID), "assigning a role twice is a no-op")
+		require.NoError(t, store.AddTeamRole(ctx, 1, team.Id, teamRole.UID))
+
+		userRoles, err := store.GetUserRoles(ctx, 1, user.Id)
+		require.NoError(t, err)
+		require.Len(t, userRoles, 1)
+		teamRoles, err := store.GetTeamRoles(ctx, 1, team.Id)
+		require.NoError(t, err)
+		require.Len(t, teamRoles, 1)
+
+		permissions, err := store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{OrgID: 1, UserID: user.Id})
+		require.NoError(t, err)
+		assert.Len(t, permissions, 2)
+
+		require.NoError(t, store.RemoveTeamRole(ctx, 1, team.Id, teamRole.UID))
+		permissions, err = store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{OrgID: 1, UserID: user.Id})
+		require.NoError(t, err)
+		assert.Len(t, permissions, 1)
+
+		require.NoError(t, store.DeleteRole(ctx, 1, role.UID))
+		permissions, err = store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{OrgID: 1, UserID: user.Id})
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+
+		_, err = store.GetRole(ctx, 1, role.UID)
+		assert.ErrorIs(t, err, accesscontrol.ErrRoleNotFound)
+	})
+
+	t.Run("managed roles are not custom roles", func(t *testing.T) {
+		user, err := sql.CreateUser(ctx, models.CreateUserCommand{Login: "managed", OrgId: 1})
+		require.NoError(t, err)
+		_, err = store.SetUserResourcePermission(ctx, 1, user.Id, accesscontrol.SetResourcePermissionCommand{
+			Actions:    []string{"dashboards:read"},
+			Resource:   "dashboards",
+			ResourceID: "1",
+		}, nil)
+		require.NoError(t, err)
+
+		roles, err := store.GetRoles(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, roles, 1, "only the custom role remains")
+		userRoles, err := store.GetUserRoles(ctx, 1, user.Id)
+		require.NoError(t, err)
+		assert.Empty(t, userRoles)
+	})
+}
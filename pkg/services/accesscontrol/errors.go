@@ -6,4 +6,11 @@ var (
 	ErrFixedRolePrefixMissing = errors.New("fixed role should be prefixed with '" + FixedRolePrefix + "'")
 	ErrInvalidBuiltinRole     = errors.New("built-in role is not valid")
 	ErrInvalidScope           = errors.New("invalid scope")
+	ErrInvalidAction          = errors.New("invalid action")
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRoleAlreadyExists      = errors.New("a role with the same name or uid already exists")
+	ErrRoleNameMissing        = errors.New("role name is missing")
+	ErrReservedRolePrefix     = errors.New("role name should not be prefixed with '" + FixedRolePrefix + "' or '" + ManagedRolePrefix + "'")
+	ErrInvalidRoleUID         = errors.New("role uid is not valid")
+	ErrVersionLE              = errors.New("the provided role version must be greater than the current version")
 )
@@ -220,3 +220,44 @@ func (a anyEvaluator) GoString() string {
 
 	return fmt.Sprintf("any(%s)", strings.Join(permissions, " "))
 }
+
+// MatchingPermissions returns the permissions satisfying at least one of the permissions required by the evaluator
+func MatchingPermissions(evaluator Evaluator, permissions []PermissionOrigin) []PermissionOrigin {
+	result := make([]PermissionOrigin, 0)
+	for _, p := range permissions {
+		if satisfies(evaluator, p.Action, p.Scope) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func satisfies(evaluator Evaluator, action, scope string) bool {
+	switch e := evaluator.(type) {
+	case permissionEvaluator:
+		if e.Action != action {
+			return false
+		}
+		if len(e.Scopes) == 0 {
+			return true
+		}
+		for _, target := range e.Scopes {
+			if matches, _ := match(scope, target); matches {
+				return true
+			}
+		}
+	case allEvaluator:
+		for _, sub := range e.allOf {
+			if satisfies(sub, action, scope) {
+				return true
+			}
+		}
+	case anyEvaluator:
+		for _, sub := range e.anyOf {
+			if satisfies(sub, action, scope) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -415,3 +415,52 @@ func TestEval(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchingPermissions(t *testing.T) {
+	permissions := []PermissionOrigin{
+		{Action: "dashboards:read", Scope: "dashboards:*", RoleName: "fixed:dashboards:reader"},
+		{Action: "dashboards:read", Scope: "dashboards:uid:other", RoleName: "managed:users:1:permissions"},
+		{Action: "dashboards:write", Scope: "dashboards:uid:abc", RoleName: "managed:users:1:permissions"},
+		{Action: "folders:read", Scope: "folders:*", RoleName: "fixed:folders:reader"},
+	}
+
+	tests := []struct {
+		desc      string
+		evaluator Evaluator
+		expected  []string
+	}{
+		{
+			desc:      "should match permissions with a matching scope",
+			evaluator: EvalPermission("dashboards:read", "dashboards:uid:abc"),
+			expected:  []string{"fixed:dashboards:reader"},
+		},
+		{
+			desc:      "should match any scope when the evaluator has none",
+			evaluator: EvalPermission("dashboards:read"),
+			expected:  []string{"fixed:dashboards:reader", "managed:users:1:permissions"},
+		},
+		{
+			desc: "should match permissions of nested evaluators",
+			evaluator: EvalAll(
+				EvalPermission("dashboards:write", "dashboards:uid:abc"),
+				EvalAny(EvalPermission("folders:read", "folders:uid:general")),
+			),
+			expected: []string{"managed:users:1:permissions", "fixed:folders:reader"},
+		},
+		{
+			desc:      "should not match other actions",
+			evaluator: EvalPermission("dashboards:delete", "dashboards:uid:abc"),
+			expected:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			roles := make([]string, 0)
+			for _, p := range MatchingPermissions(test.evaluator, permissions) {
+				roles = append(roles, p.RoleName)
+			}
+			assert.Equal(t, test.expected, roles)
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -40,6 +42,42 @@ func Middleware(ac accesscontrol.AccessControl) func(web.Handler, accesscontrol.
 	}
 }
 
+// RouteMiddleware is like Middleware, but its handlers also carry their evaluator as route metadata,
+// which the route register records on the routes they are added to. The handlers can only be added
+// to routes of a route register.
+func RouteMiddleware(ac accesscontrol.AccessControl) func(web.Handler, accesscontrol.Evaluator) web.Handler {
+	authorize := Middleware(ac)
+	return func(fallback web.Handler, evaluator accesscontrol.Evaluator) web.Handler {
+		if ac.IsDisabled() {
+			return fallback
+		}
+
+		return routing.HandlerWithMetadata{
+			Handler:  authorize(fallback, evaluator),
+			Metadata: evaluator,
+		}
+	}
+}
+
+// RouteEvaluators returns the evaluators enforced by the access control handlers of a route, found in the
+// metadata of the route, with the scope parameters filled in from params. The evaluators are not evaluated.
+// Handlers created while access control is disabled enforce their fallback and aren't reported.
+func RouteEvaluators(ctx context.Context, params accesscontrol.ScopeParams, metadata []interface{}) ([]accesscontrol.Evaluator, error) {
+	var evaluators []accesscontrol.Evaluator
+	for _, m := range metadata {
+		evaluator, ok := m.(accesscontrol.Evaluator)
+		if !ok {
+			continue
+		}
+		injected, err := evaluator.MutateScopes(ctx, accesscontrol.ScopeInjector(params))
+		if err != nil {
+			return nil, err
+		}
+		evaluators = append(evaluators, injected)
+	}
+	return evaluators, nil
+}
+
 func Deny(c *models.ReqContext, evaluator accesscontrol.Evaluator, err error) {
 	id := newID()
 	if err != nil {
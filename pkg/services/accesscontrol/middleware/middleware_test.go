@@ -1,13 +1,16 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -80,6 +83,47 @@ func TestMiddleware(t *testing.T) {
 	}
 }
 
+func TestRouteEvaluators(t *testing.T) {
+	ac := mock.New()
+	authorize := RouteMiddleware(ac)
+	fallback := func(c *models.ReqContext) {}
+	endpointCalled := false
+	routes := routing.NewRouteRegister()
+	routes.Group("/api/teams", func(r routing.RouteRegister) {
+		r.Put("/:teamId",
+			fallback,
+			authorize(fallback, accesscontrol.EvalPermission("teams:write", accesscontrol.Scope("teams", "*"))),
+			func(c *models.ReqContext) {
+				endpointCalled = true
+			},
+		)
+	}, authorize(fallback, accesscontrol.EvalPermission("teams:read", accesscontrol.ScopeTeamsID)))
+	routes.Get("/api/users", RouteMiddleware(mock.New().WithDisabled())(fallback, accesscontrol.EvalPermission("users:read")))
+
+	match, ok := routes.Lookup(http.MethodPut, "/api/teams/1")
+	require.True(t, ok)
+	for _, h := range match.Handlers {
+		_, isMetadata := h.(routing.HandlerWithMetadata)
+		assert.False(t, isMetadata, "the router should only get the handlers")
+	}
+
+	params := accesscontrol.ScopeParams{OrgID: 1, URLParams: match.Params}
+	evaluators, err := RouteEvaluators(context.Background(), params, match.Metadata)
+	assert.NoError(t, err)
+	assert.False(t, endpointCalled)
+	assert.Equal(t, []accesscontrol.Evaluator{
+		accesscontrol.EvalPermission("teams:read", "teams:id:1"),
+		accesscontrol.EvalPermission("teams:write", "teams:*"),
+	}, evaluators)
+	assert.Empty(t, ac.Calls.Evaluate, "evaluators should be collected without being evaluated")
+
+	match, ok = routes.Lookup(http.MethodGet, "/api/users")
+	require.True(t, ok)
+	evaluators, err = RouteEvaluators(context.Background(), params, match.Metadata)
+	assert.NoError(t, err)
+	assert.Empty(t, evaluators)
+}
+
 func contextProvider() web.Handler {
 	return func(c *web.Context) {
 		reqCtx := &models.ReqContext{
@@ -175,12 +175,71 @@ func (p Permission) OSSPermission() Permission {
 	}
 }
 
+// CreateRoleCommand is the command for creating a custom role
+type CreateRoleCommand struct {
+	UID         string       `json:"uid"`
+	Version     int64        `json:"version"`
+	Name        string       `json:"name"`
+	DisplayName string       `json:"displayName"`
+	Description string       `json:"description"`
+	Group       string       `json:"group"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// UpdateRoleCommand is the command for updating a custom role. The version
+// must be greater than the version of the stored role.
+type UpdateRoleCommand struct {
+	Version     int64        `json:"version"`
+	Name        string       `json:"name"`
+	DisplayName string       `json:"displayName"`
+	Description string       `json:"description"`
+	Group       string       `json:"group"`
+	Permissions []Permission `json:"permissions"`
+}
+
 type GetUserPermissionsQuery struct {
 	OrgID  int64 `json:"-"`
 	UserID int64 `json:"userId"`
 	Roles  []string
 }
 
+// GetPermissionOriginsQuery selects the role assignments to list permissions for.
+// When TeamID is set, only the roles assigned to that team are considered.
+type GetPermissionOriginsQuery struct {
+	OrgID  int64
+	UserID int64
+	TeamID int64
+	Roles  []string
+}
+
+// Sources of a permission, i.e. how the role holding it is assigned
+const (
+	PermissionSourceBuiltInRole = "builtInRole"
+	PermissionSourceUser        = "user"
+	PermissionSourceTeam        = "team"
+)
+
+// PermissionOrigin is a permission along with the role holding it and the assignment of that role
+type PermissionOrigin struct {
+	Action      string `json:"action" xorm:"action"`
+	Scope       string `json:"scope" xorm:"scope"`
+	RoleUID     string `json:"roleUid,omitempty" xorm:"role_uid"`
+	RoleName    string `json:"roleName" xorm:"role_name"`
+	Source      string `json:"source" xorm:"-"`
+	BuiltInRole string `json:"builtInRole,omitempty" xorm:"built_in_role"`
+	TeamID      int64  `json:"teamId,omitempty" xorm:"team_id"`
+	TeamName    string `json:"teamName,omitempty" xorm:"team_name"`
+}
+
+// Explanation is the outcome of an evaluation together with the permissions it relied on
+type Explanation struct {
+	Allowed bool `json:"allowed"`
+	// Evaluator describes the required permissions once attribute scopes are resolved
+	Evaluator string `json:"evaluator"`
+	// Permissions lists the permissions matching at least one of the required permissions
+	Permissions []PermissionOrigin `json:"permissions"`
+}
+
 // ScopeParams holds the parameters used to fill in scope templates
 type ScopeParams struct {
 	OrgID     int64
@@ -264,6 +323,7 @@ const (
 	// We can ignore gosec G101 since this does not contain any credentials.
 	// nolint:gosec
 	ActionUsersPasswordUpdate    = "users.password:update"
+	ActionUsersTwoFactorReset    = "users.twofactor:reset"
 	ActionUsersDelete            = "users:delete"
 	ActionUsersCreate            = "users:create"
 	ActionUsersEnable            = "users:enable"
@@ -322,17 +382,39 @@ const (
 
 	// Team related scopes
 	ScopeTeamsAll = "teams:*"
+
+	// Custom roles actions
+	ActionRolesRead   = "roles:read"
+	ActionRolesWrite  = "roles:write"
+	ActionRolesDelete = "roles:delete"
+
+	// Custom roles assignments actions
+	ActionUsersRolesList   = "users.roles:list"
+	ActionUsersRolesAdd    = "users.roles:add"
+	ActionUsersRolesRemove = "users.roles:remove"
+	ActionTeamsRolesList   = "teams.roles:list"
+	ActionTeamsRolesAdd    = "teams.roles:add"
+	ActionTeamsRolesRemove = "teams.roles:remove"
+
+	// Custom roles scopes
+	ScopeRolesAll = "roles:*"
 )
 
 var (
 	// Team scope
 	ScopeTeamsID = Scope("teams", "id", Parameter(":teamId"))
+
+	// Custom role scope
+	ScopeRolesUID = Scope("roles", "uid", Parameter(":roleUID"))
 )
 
 const RoleGrafanaAdmin = "Grafana Admin"
 
 const FixedRolePrefix = "fixed:"
 
+// ManagedRolePrefix is the prefix of the roles holding resource permissions
+const ManagedRolePrefix = "managed:"
+
 // LicensingPageReaderAccess defines permissions that grant access to the licensing and stats page
 var LicensingPageReaderAccess = EvalAny(
 	EvalPermission(ActionLicensingRead),
@@ -3,7 +3,12 @@ package ossaccesscontrol
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
@@ -13,14 +18,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-func ProvideService(features featuremgmt.FeatureToggles, usageStats usagestats.Service) *OSSAccessControlService {
+// permissionCacheTTL is how long permissions granted by stored roles are cached. Changes to the stored roles
+// and their assignments are effective immediately, the TTL bounds how long changes of team memberships take.
+const permissionCacheTTL = 30 * time.Second
+
+func ProvideService(features featuremgmt.FeatureToggles, usageStats usagestats.Service,
+	store accesscontrol.RoleStore, cache *localcache.CacheService, routeRegister routing.RouteRegister) *OSSAccessControlService {
 	s := &OSSAccessControlService{
 		features:      features,
 		UsageStats:    usageStats,
 		Log:           log.New("accesscontrol"),
 		ScopeResolver: accesscontrol.NewScopeResolver(),
+		store:         store,
+		cache:         cache,
 	}
 	s.registerUsageMetrics()
+	if store != nil && routeRegister != nil {
+		newRolesAPI(s, routeRegister, store).registerEndpoints()
+	}
 	return s
 }
 
@@ -31,6 +46,9 @@ type OSSAccessControlService struct {
 	Log           log.Logger
 	registrations accesscontrol.RegistrationList
 	ScopeResolver accesscontrol.ScopeResolver
+	// store provides the permissions of custom and managed roles, fixed roles only are used when nil
+	store accesscontrol.RoleStore
+	cache *localcache.CacheService
 }
 
 func (ac *OSSAccessControlService) IsDisabled() bool {
@@ -117,9 +135,140 @@ func (ac *OSSAccessControlService) GetUserPermissions(ctx context.Context, user
 		}
 	}
 
+	stored, err := ac.getStoredPermissions(ctx, user, builtinRoles)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stored {
+		p := stored[i].OSSPermission()
+		p.Scope, err = keywordMutator(ctx, p.Scope)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, &p)
+	}
+
 	return permissions, nil
 }
 
+// getStoredPermissions returns the permissions granted to the user by roles stored in the database.
+// They are cached per generation of the stored roles, which every instance changing them increments.
+func (ac *OSSAccessControlService) getStoredPermissions(ctx context.Context, user *models.SignedInUser, builtinRoles []string) ([]*accesscontrol.Permission, error) {
+	if ac.store == nil {
+		return nil, nil
+	}
+
+	var key string
+	if ac.cache != nil {
+		generation, err := ac.store.GetPermissionsGeneration(ctx)
+		if err != nil {
+			return nil, err
+		}
+		key = fmt.Sprintf("rbac-permissions-%d-%d-%d-%s", generation, user.OrgId, user.UserId, strings.Join(builtinRoles, ","))
+		if cached, ok := ac.cache.Get(key); ok {
+			return cached.([]*accesscontrol.Permission), nil
+		}
+	}
+
+	permissions, err := ac.store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{
+		OrgID:  user.OrgId,
+		UserID: user.UserId,
+		Roles:  builtinRoles,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ac.cache != nil {
+		ac.cache.Set(key, permissions, permissionCacheTTL)
+	}
+	return permissions, nil
+}
+
+// ExplainUser evaluates access of the user and lists the permissions matching the evaluator along with their origin
+func (ac *OSSAccessControlService) ExplainUser(ctx context.Context, user *models.SignedInUser, evaluator accesscontrol.Evaluator) (*accesscontrol.Explanation, error) {
+	builtinRoles := ac.GetUserBuiltInRoles(user)
+	origins := make([]accesscontrol.PermissionOrigin, 0)
+	for _, builtin := range builtinRoles {
+		for _, name := range accesscontrol.FixedRoleGrants[builtin] {
+			role, exists := accesscontrol.FixedRoles[name]
+			if !exists {
+				continue
+			}
+			for _, p := range role.Permissions {
+				origins = append(origins, accesscontrol.PermissionOrigin{
+					Action:      p.Action,
+					Scope:       p.Scope,
+					RoleUID:     role.UID,
+					RoleName:    role.Name,
+					Source:      accesscontrol.PermissionSourceBuiltInRole,
+					BuiltInRole: builtin,
+				})
+			}
+		}
+	}
+
+	if ac.store != nil {
+		stored, err := ac.store.GetPermissionOrigins(ctx, accesscontrol.GetPermissionOriginsQuery{
+			OrgID:  user.OrgId,
+			UserID: user.UserId,
+			Roles:  builtinRoles,
+		})
+		if err != nil {
+			return nil, err
+		}
+		origins = append(origins, stored...)
+	}
+
+	var err error
+	keywordMutator := ac.ScopeResolver.GetResolveKeywordScopeMutator(user)
+	for i := range origins {
+		origins[i].Scope, err = keywordMutator(ctx, origins[i].Scope)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ac.explain(ctx, user.OrgId, evaluator, origins)
+}
+
+// ExplainTeam evaluates the access granted by the roles assigned to a team
+func (ac *OSSAccessControlService) ExplainTeam(ctx context.Context, orgID, teamID int64, evaluator accesscontrol.Evaluator) (*accesscontrol.Explanation, error) {
+	origins := make([]accesscontrol.PermissionOrigin, 0)
+	if ac.store != nil {
+		var err error
+		origins, err = ac.store.GetPermissionOrigins(ctx, accesscontrol.GetPermissionOriginsQuery{OrgID: orgID, TeamID: teamID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ac.explain(ctx, orgID, evaluator, origins)
+}
+
+func (ac *OSSAccessControlService) explain(ctx context.Context, orgID int64, evaluator accesscontrol.Evaluator, origins []accesscontrol.PermissionOrigin) (*accesscontrol.Explanation, error) {
+	resolvedEvaluator, err := evaluator.MutateScopes(ctx, ac.ScopeResolver.GetResolveAttributeScopeMutator(orgID))
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string][]string)
+	for _, o := range origins {
+		permissions[o.Action] = append(permissions[o.Action], o.Scope)
+	}
+
+	allowed, err := resolvedEvaluator.Evaluate(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accesscontrol.Explanation{
+		Allowed:     allowed,
+		Evaluator:   resolvedEvaluator.GoString(),
+		Permissions: accesscontrol.MatchingPermissions(resolvedEvaluator, origins),
+	}, nil
+}
+
 func (ac *OSSAccessControlService) GetUserBuiltInRoles(user *models.SignedInUser) []string {
 	roles := []string{string(user.OrgRole)}
 	for _, role := range user.OrgRole.Children() {
@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
 func setupTestEnv(t testing.TB) *OSSAccessControlService {
@@ -147,7 +151,7 @@ func TestUsageMetrics(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			features := featuremgmt.WithFeatures("accesscontrol", tt.enabled)
 
-			s := ProvideService(features, &usagestats.UsageStatsMock{T: t})
+			s := ProvideService(features, &usagestats.UsageStatsMock{T: t}, nil, nil, nil)
 			report, err := s.UsageStats.GetUsageReport(context.Background())
 			assert.Nil(t, err)
 
@@ -648,3 +652,118 @@ func TestOSSAccessControlService_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestOSSAccessControlService_Explain(t *testing.T) {
+	sql := sqlstore.InitTestDB(t)
+	store := database.ProvideService(sql)
+	ac := ProvideService(featuremgmt.WithFeatures(featuremgmt.FlagAccesscontrol), &usagestats.UsageStatsMock{T: t}, store, nil, nil)
+
+	ctx := context.Background()
+	user, err := sql.CreateUser(ctx, models.CreateUserCommand{Login: "user"})
+	require.NoError(t, err)
+	team, err := sql.CreateTeam("team", "", user.OrgId)
+	require.NoError(t, err)
+	require.NoError(t, sql.AddTeamMember(user.Id, user.OrgId, team.Id, false, models.PERMISSION_VIEW))
+
+	role, err := store.CreateRole(ctx, user.OrgId, accesscontrol.CreateRoleCommand{
+		Name:        "custom:dashboards:reader",
+		Permissions: []accesscontrol.Permission{{Action: "dashboards:read", Scope: "dashboards:uid:abc"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.AddTeamRole(ctx, user.OrgId, team.Id, role.UID))
+
+	readDashboard := accesscontrol.EvalPermission("dashboards:read", "dashboards:uid:abc")
+	readRoles := accesscontrol.EvalPermission(accesscontrol.ActionRolesRead, accesscontrol.ScopeRolesAll)
+
+	t.Run("should explain permissions granted through a team", func(t *testing.T) {
+		viewer := &models.SignedInUser{UserId: user.Id, OrgId: user.OrgId, OrgRole: models.ROLE_VIEWER}
+		explanation, err := ac.ExplainUser(ctx, viewer, readDashboard)
+		require.NoError(t, err)
+		assert.True(t, explanation.Allowed)
+		require.Len(t, explanation.Permissions, 1)
+		assert.Equal(t, accesscontrol.PermissionSourceTeam, explanation.Permissions[0].Source)
+		assert.Equal(t, team.Id, explanation.Permissions[0].TeamID)
+		assert.Equal(t, role.Name, explanation.Permissions[0].RoleName)
+
+		explanation, err = ac.ExplainUser(ctx, viewer, readRoles)
+		require.NoError(t, err)
+		assert.False(t, explanation.Allowed)
+		assert.Empty(t, explanation.Permissions)
+	})
+
+	t.Run("should explain permissions granted through fixed roles", func(t *testing.T) {
+		admin := &models.SignedInUser{UserId: user.Id, OrgId: user.OrgId, OrgRole: models.ROLE_ADMIN}
+		explanation, err := ac.ExplainUser(ctx, admin, readRoles)
+		require.NoError(t, err)
+		assert.True(t, explanation.Allowed)
+		require.NotEmpty(t, explanation.Permissions)
+		assert.Equal(t, accesscontrol.PermissionSourceBuiltInRole, explanation.Permissions[0].Source)
+		assert.Equal(t, string(models.ROLE_ADMIN), explanation.Permissions[0].BuiltInRole)
+	})
+
+	t.Run("should explain permissions of a team", func(t *testing.T) {
+		explanation, err := ac.ExplainTeam(ctx, user.OrgId, team.Id, readDashboard)
+		require.NoError(t, err)
+		assert.True(t, explanation.Allowed)
+		assert.Len(t, explanation.Permissions, 1)
+
+		explanation, err = ac.ExplainTeam(ctx, user.OrgId, team.Id, readRoles)
+		require.NoError(t, err)
+		assert.False(t, explanation.Allowed)
+	})
+}
+
+func TestOSSAccessControlService_StoredPermissionsCache(t *testing.T) {
+	sql := sqlstore.InitTestDB(t)
+	store := database.ProvideService(sql)
+	features := featuremgmt.WithFeatures(featuremgmt.FlagAccesscontrol)
+	// two instances sharing the database, each with its own cache
+	instance := ProvideService(features, &usagestats.UsageStatsMock{T: t}, store, localcache.ProvideService(), nil)
+	otherInstance := ProvideService(features, &usagestats.UsageStatsMock{T: t}, store, localcache.ProvideService(), nil)
+
+	ctx := context.Background()
+	user, err := sql.CreateUser(ctx, models.CreateUserCommand{Login: "user"})
+	require.NoError(t, err)
+	role, err := store.CreateRole(ctx, user.OrgId, accesscontrol.CreateRoleCommand{
+		Name:        "custom:dashboards:reader",
+		Permissions: []accesscontrol.Permission{{Action: "dashboards:read", Scope: "dashboards:uid:abc"}},
+	})
+	require.NoError(t, err)
+
+	readDashboard := accesscontrol.EvalPermission("dashboards:read", "dashboards:uid:abc")
+	evaluate := func(ac *OSSAccessControlService) bool {
+		viewer := &models.SignedInUser{UserId: user.Id, OrgId: user.OrgId, OrgRole: models.ROLE_VIEWER}
+		hasAccess, err := ac.Evaluate(ctx, viewer, readDashboard)
+		require.NoError(t, err)
+		return hasAccess
+	}
+
+	require.False(t, evaluate(instance))
+	require.False(t, evaluate(otherInstance))
+
+	t.Run("should cache the stored permissions", func(t *testing.T) {
+		// a change that does not go through the store is not seen
+		err := sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("INSERT INTO user_role (org_id, user_id, role_id, created) VALUES (?, ?, ?, ?)", user.OrgId, user.Id, role.ID, time.Now())
+			return err
+		})
+		require.NoError(t, err)
+		require.False(t, evaluate(instance))
+
+		err = sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Exec("DELETE FROM user_role WHERE user_id = ?", user.Id)
+			return err
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("should pick up role changes made by another instance", func(t *testing.T) {
+		require.NoError(t, store.AddUserRole(ctx, user.OrgId, user.Id, role.UID))
+		require.True(t, evaluate(instance))
+		require.True(t, evaluate(otherInstance))
+
+		require.NoError(t, store.RemoveUserRole(ctx, user.OrgId, user.Id, role.UID))
+		require.False(t, evaluate(instance))
+		require.False(t, evaluate(otherInstance))
+	})
+}
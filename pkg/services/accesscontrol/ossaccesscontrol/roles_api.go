@@ -0,0 +1,361 @@
+package ossaccesscontrol
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var (
+	scopeUsersID = accesscontrol.Scope("users", "id", accesscontrol.Parameter(":userId"))
+)
+
+// rolesAPI exposes the management of custom roles and their assignments
+type rolesAPI struct {
+	ac     *OSSAccessControlService
+	router routing.RouteRegister
+	store  accesscontrol.RoleStore
+}
+
+func newRolesAPI(ac *OSSAccessControlService, router routing.RouteRegister, store accesscontrol.RoleStore) *rolesAPI {
+	return &rolesAPI{ac: ac, router: router, store: store}
+}
+
+func (a *rolesAPI) registerEndpoints() {
+	auth := middleware.RouteMiddleware(a.ac)
+	disable := middleware.Disable(a.ac.IsDisabled())
+	a.router.Group("/api/access-control", func(r routing.RouteRegister) {
+		r.Get("/roles", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionRolesRead, accesscontrol.ScopeRolesAll)), routing.Wrap(a.getRoles))
+		r.Get("/roles/:roleUID", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionRolesRead, accesscontrol.ScopeRolesUID)), routing.Wrap(a.getRole))
+		r.Post("/roles", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionRolesWrite, accesscontrol.ScopeRolesAll)), routing.Wrap(a.createRole))
+		r.Put("/roles/:roleUID", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionRolesWrite, accesscontrol.ScopeRolesUID)), routing.Wrap(a.updateRole))
+		r.Delete("/roles/:roleUID", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionRolesDelete, accesscontrol.ScopeRolesUID)), routing.Wrap(a.deleteRole))
+
+		r.Get("/users/:userId/roles", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionUsersRolesList, scopeUsersID)), routing.Wrap(a.getUserRoles))
+		r.Post("/users/:userId/roles", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionUsersRolesAdd, scopeUsersID)), routing.Wrap(a.addUserRole))
+		r.Delete("/users/:userId/roles/:roleUID", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionUsersRolesRemove, scopeUsersID)), routing.Wrap(a.removeUserRole))
+
+		r.Get("/teams/:teamId/roles", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesList, accesscontrol.ScopeTeamsID)), routing.Wrap(a.getTeamRoles))
+		r.Post("/teams/:teamId/roles", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesAdd, accesscontrol.ScopeTeamsID)), routing.Wrap(a.addTeamRole))
+		r.Delete("/teams/:teamId/roles/:roleUID", auth(disable, accesscontrol.EvalPermission(accesscontrol.ActionTeamsRolesRemove, accesscontrol.ScopeTeamsID)), routing.Wrap(a.removeTeamRole))
+
+		r.Get("/serviceaccounts/:serviceaccountId/roles", auth(disable, accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(a.getServiceAccountRoles))
+		r.Post("/serviceaccounts/:serviceaccountId/roles", auth(disable, accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(a.addServiceAccountRole))
+		r.Delete("/serviceaccounts/:serviceaccountId/roles/:roleUID", auth(disable, accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(a.removeServiceAccountRole))
+	})
+}
+
+type addRoleAssignmentCommand struct {
+	RoleUID string `json:"roleUid"`
+}
+
+func (a *rolesAPI) getRoles(c *models.ReqContext) response.Response {
+	roles, err := a.store.GetRoles(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+func (a *rolesAPI) getRole(c *models.ReqContext) response.Response {
+	role, err := a.store.GetRole(c.Req.Context(), c.OrgId, web.Params(c.Req)[":roleUID"])
+	if err != nil {
+		return roleErrorResponse(err, "Failed to get role")
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+func (a *rolesAPI) createRole(c *models.ReqContext) response.Response {
+	var cmd accesscontrol.CreateRoleCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if resp := a.checkDelegation(c, cmd.Permissions); resp != nil {
+		return resp
+	}
+
+	role, err := a.store.CreateRole(c.Req.Context(), c.OrgId, cmd)
+	if err != nil {
+		return roleErrorResponse(err, "Failed to create role")
+	}
+	return response.JSON(http.StatusCreated, role)
+}
+
+func (a *rolesAPI) updateRole(c *models.ReqContext) response.Response {
+	var cmd accesscontrol.UpdateRoleCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	uid := web.Params(c.Req)[":roleUID"]
+	if resp := a.checkRoleDelegation(c, uid); resp != nil {
+		return resp
+	}
+	if resp := a.checkDelegation(c, cmd.Permissions); resp != nil {
+		return resp
+	}
+
+	role, err := a.store.UpdateRole(c.Req.Context(), c.OrgId, uid, cmd)
+	if err != nil {
+		return roleErrorResponse(err, "Failed to update role")
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+func (a *rolesAPI) deleteRole(c *models.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":roleUID"]
+	if resp := a.checkRoleDelegation(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := a.store.DeleteRole(c.Req.Context(), c.OrgId, uid); err != nil {
+		return roleErrorResponse(err, "Failed to delete role")
+	}
+	return response.Success("Role deleted")
+}
+
+func (a *rolesAPI) getUserRoles(c *models.ReqContext) response.Response {
+	userID, resp := a.orgUserParam(c, ":userId", false)
+	if resp != nil {
+		return resp
+	}
+	return a.userRoles(c, userID)
+}
+
+func (a *rolesAPI) addUserRole(c *models.ReqContext) response.Response {
+	userID, resp := a.orgUserParam(c, ":userId", false)
+	if resp != nil {
+		return resp
+	}
+	return a.addRoleToUser(c, userID)
+}
+
+func (a *rolesAPI) removeUserRole(c *models.ReqContext) response.Response {
+	userID, resp := a.orgUserParam(c, ":userId", false)
+	if resp != nil {
+		return resp
+	}
+	return a.removeRoleFromUser(c, userID)
+}
+
+func (a *rolesAPI) getServiceAccountRoles(c *models.ReqContext) response.Response {
+	userID, resp := a.orgUserParam(c, ":serviceaccountId", true)
+	if resp != nil {
+		return resp
+	}
+	return a.userRoles(c, userID)
+}
+
+func (a *rolesAPI) addServiceAccountRole(c *models.ReqContext) response.Response {
+	userID, resp := a.orgUserParam(c, ":serviceaccountId", true)
+	if resp != nil {
+		return resp
+	}
+	return a.addRoleToUser(c, userID)
+}
+
+func (a *rolesAPI) removeServiceAccountRole(c *models.ReqContext) response.Response {
+	userID, resp := a.orgUserParam(c, ":serviceaccountId", true)
+	if resp != nil {
+		return resp
+	}
+	return a.removeRoleFromUser(c, userID)
+}
+
+func (a *rolesAPI) userRoles(c *models.ReqContext, userID int64) response.Response {
+	roles, err := a.store.GetUserRoles(c.Req.Context(), c.OrgId, userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+func (a *rolesAPI) addRoleToUser(c *models.ReqContext, userID int64) response.Response {
+	var cmd addRoleAssignmentCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if resp := a.checkRoleDelegation(c, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := a.store.AddUserRole(c.Req.Context(), c.OrgId, userID, cmd.RoleUID); err != nil {
+		return roleErrorResponse(err, "Failed to assign role")
+	}
+	return response.Success("Role added")
+}
+
+func (a *rolesAPI) removeRoleFromUser(c *models.ReqContext, userID int64) response.Response {
+	uid := web.Params(c.Req)[":roleUID"]
+	if resp := a.checkRoleDelegation(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := a.store.RemoveUserRole(c.Req.Context(), c.OrgId, userID, uid); err != nil {
+		return roleErrorResponse(err, "Failed to unassign role")
+	}
+	return response.Success("Role removed")
+}
+
+func (a *rolesAPI) getTeamRoles(c *models.ReqContext) response.Response {
+	teamID, resp := a.teamParam(c)
+	if resp != nil {
+		return resp
+	}
+
+	roles, err := a.store.GetTeamRoles(c.Req.Context(), c.OrgId, teamID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get team roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+func (a *rolesAPI) addTeamRole(c *models.ReqContext) response.Response {
+	teamID, resp := a.teamParam(c)
+	if resp != nil {
+		return resp
+	}
+
+	var cmd addRoleAssignmentCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if resp := a.checkRoleDelegation(c, cmd.RoleUID); resp != nil {
+		return resp
+	}
+
+	if err := a.store.AddTeamRole(c.Req.Context(), c.OrgId, teamID, cmd.RoleUID); err != nil {
+		return roleErrorResponse(err, "Failed to assign role")
+	}
+	return response.Success("Role added")
+}
+
+func (a *rolesAPI) removeTeamRole(c *models.ReqContext) response.Response {
+	teamID, resp := a.teamParam(c)
+	if resp != nil {
+		return resp
+	}
+
+	uid := web.Params(c.Req)[":roleUID"]
+	if resp := a.checkRoleDelegation(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := a.store.RemoveTeamRole(c.Req.Context(), c.OrgId, teamID, uid); err != nil {
+		return roleErrorResponse(err, "Failed to unassign role")
+	}
+	return response.Success("Role removed")
+}
+
+// orgUserParam returns the id of the user from the url parameter after
+// verifying that the user is a member of the current organization
+func (a *rolesAPI) orgUserParam(c *models.ReqContext, param string, serviceAccount bool) (int64, response.Response) {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[param], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return 0, response.Error(http.StatusNotFound, "User not found", err)
+		}
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	if query.Result.IsServiceAccount != serviceAccount {
+		if serviceAccount {
+			return 0, response.Error(http.StatusNotFound, "Service account not found", nil)
+		}
+		return 0, response.Error(http.StatusNotFound, "User not found", nil)
+	}
+
+	orgsQuery := models.GetUserOrgListQuery{UserId: userID}
+	if err := bus.Dispatch(c.Req.Context(), &orgsQuery); err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get user organizations", err)
+	}
+	for _, org := range orgsQuery.Result {
+		if org.OrgId == c.OrgId {
+			return userID, nil
+		}
+	}
+	return 0, response.Error(http.StatusNotFound, "User not found", nil)
+}
+
+// teamParam returns the id of the team from the url parameter after verifying
+// that the team belongs to the current organization
+func (a *rolesAPI) teamParam(c *models.ReqContext) (int64, response.Response) {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	query := models.GetTeamByIdQuery{OrgId: c.OrgId, Id: teamID, SignedInUser: c.SignedInUser}
+	if err := bus.Dispatch(c.Req.Context(), &query); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return 0, response.Error(http.StatusNotFound, "Team not found", err)
+		}
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get team", err)
+	}
+	return teamID, nil
+}
+
+// checkRoleDelegation verifies that the signed in user holds all the permissions of the role
+func (a *rolesAPI) checkRoleDelegation(c *models.ReqContext, uid string) response.Response {
+	role, err := a.store.GetRole(c.Req.Context(), c.OrgId, uid)
+	if err != nil {
+		return roleErrorResponse(err, "Failed to get role")
+	}
+	return a.checkDelegation(c, role.Permissions)
+}
+
+// checkDelegation prevents privilege escalation: users can only grant, change or
+// revoke the permissions they hold themselves. Grafana server administrators are exempt.
+func (a *rolesAPI) checkDelegation(c *models.ReqContext, permissions []accesscontrol.Permission) response.Response {
+	if c.IsGrafanaAdmin {
+		return nil
+	}
+
+	for _, p := range permissions {
+		evaluator := accesscontrol.EvalPermission(p.Action)
+		if p.Scope != "" {
+			evaluator = accesscontrol.EvalPermission(p.Action, p.Scope)
+		}
+
+		hasAccess, err := a.ac.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !hasAccess {
+			return response.Error(http.StatusForbidden, "Cannot manage permissions you do not have: "+p.Action+" "+p.Scope, nil)
+		}
+	}
+	return nil
+}
+
+func roleErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, accesscontrol.ErrRoleNotFound):
+		return response.Error(http.StatusNotFound, "Role not found", err)
+	case errors.Is(err, accesscontrol.ErrRoleAlreadyExists), errors.Is(err, accesscontrol.ErrVersionLE):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, accesscontrol.ErrRoleNameMissing),
+		errors.Is(err, accesscontrol.ErrReservedRolePrefix),
+		errors.Is(err, accesscontrol.ErrInvalidRoleUID),
+		errors.Is(err, accesscontrol.ErrInvalidAction),
+		errors.Is(err, accesscontrol.ErrInvalidScope):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,159 @@
+package ossaccesscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourceservices"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRolesAPI(t *testing.T) {
+	sql := sqlstore.InitTestDB(t)
+	store := database.ProvideService(sql)
+	router := routing.NewRouteRegister()
+	ac := ProvideService(featuremgmt.WithFeatures(featuremgmt.FlagAccesscontrol), &usagestats.UsageStatsMock{T: t}, store, localcache.ProvideService(), router)
+	// team permissions share the /api/access-control/teams prefix
+	_, err := resourceservices.ProvideTeamPermissions(router, sql, ac, store)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	admin, err := sql.CreateUser(ctx, models.CreateUserCommand{Login: "admin"})
+	require.NoError(t, err)
+	viewer, err := sql.CreateUser(ctx, models.CreateUserCommand{Login: "viewer", SkipOrgSetup: true})
+	require.NoError(t, err)
+	require.NoError(t, sql.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: admin.OrgId, UserId: viewer.Id, Role: models.ROLE_VIEWER}))
+	team, err := sql.CreateTeam("team", "", admin.OrgId)
+	require.NoError(t, err)
+
+	server := web.New()
+	server.UseMiddleware(web.Renderer(path.Join(setting.StaticRootPath, "views"), "[[", "]]"))
+	server.Use(func(c *web.Context) {
+		c.Map(&models.ReqContext{
+			Context:      c,
+			SignedInUser: &models.SignedInUser{UserId: admin.Id, OrgId: admin.OrgId, OrgRole: models.ROLE_ADMIN},
+			IsSignedIn:   true,
+			SkipCache:    true,
+			Logger:       log.New("test"),
+		})
+	})
+	router.Register(server)
+
+	request := func(t *testing.T, method, url, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	viewerCanReadOrgUsers := func(t *testing.T) bool {
+		t.Helper()
+		user := &models.SignedInUser{UserId: viewer.Id, OrgId: admin.OrgId, OrgRole: models.ROLE_VIEWER}
+		hasAccess, err := ac.Evaluate(ctx, user, accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead, accesscontrol.ScopeUsersAll))
+		require.NoError(t, err)
+		return hasAccess
+	}
+
+	t.Run("cannot grant permissions the user does not have", func(t *testing.T) {
+		recorder := request(t, http.MethodPost, "/api/access-control/roles", `{"name":"custom:users","permissions":[{"action":"users:write","scope":"global:users:*"}]}`)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	var role accesscontrol.RoleDTO
+	t.Run("create a role", func(t *testing.T) {
+		recorder := request(t, http.MethodPost, "/api/access-control/roles", `{"name":"custom:org.users:reader","permissions":[{"action":"org.users:read","scope":"users:*"}]}`)
+		require.Equal(t, http.StatusCreated, recorder.Code)
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&role))
+		assert.Equal(t, int64(1), role.Version)
+
+		recorder = request(t, http.MethodGet, "/api/access-control/roles", "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var roles []accesscontrol.RoleDTO
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&roles))
+		assert.Len(t, roles, 1)
+	})
+
+	t.Run("update a role", func(t *testing.T) {
+		recorder := request(t, http.MethodPut, "/api/access-control/roles/"+role.UID, `{"version":1,"name":"custom:org.users:reader"}`)
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+
+		recorder = request(t, http.MethodPut, "/api/access-control/roles/"+role.UID, `{"version":2,"name":"custom:org.users:reader","displayName":"Org users reader","permissions":[{"action":"org.users:read","scope":"users:*"}]}`)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("assign a role to a user", func(t *testing.T) {
+		require.False(t, viewerCanReadOrgUsers(t))
+
+		recorder := request(t, http.MethodPost, fmt.Sprintf("/api/access-control/users/%d/roles", viewer.Id), `{"roleUid":"`+role.UID+`"}`)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, viewerCanReadOrgUsers(t))
+
+		recorder = request(t, http.MethodGet, fmt.Sprintf("/api/access-control/users/%d/roles", viewer.Id), "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var roles []accesscontrol.RoleDTO
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&roles))
+		require.Len(t, roles, 1)
+		assert.Equal(t, role.UID, roles[0].UID)
+	})
+
+	t.Run("assign a role to a team", func(t *testing.T) {
+		recorder := request(t, http.MethodPost, fmt.Sprintf("/api/access-control/teams/%d/roles", team.Id), `{"roleUid":"`+role.UID+`"}`)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = request(t, http.MethodGet, fmt.Sprintf("/api/access-control/teams/%d/roles", team.Id), "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = request(t, http.MethodDelete, fmt.Sprintf("/api/access-control/teams/%d/roles/%s", team.Id, role.UID), "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("cannot revoke permissions the user does not have", func(t *testing.T) {
+		privileged, err := store.CreateRole(ctx, admin.OrgId, accesscontrol.CreateRoleCommand{
+			Name:        "custom:users:writer",
+			Permissions: []accesscontrol.Permission{{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, store.AddUserRole(ctx, admin.OrgId, viewer.Id, privileged.UID))
+		require.NoError(t, store.AddTeamRole(ctx, admin.OrgId, team.Id, privileged.UID))
+
+		recorder := request(t, http.MethodDelete, fmt.Sprintf("/api/access-control/users/%d/roles/%s", viewer.Id, privileged.UID), "")
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		recorder = request(t, http.MethodDelete, fmt.Sprintf("/api/access-control/teams/%d/roles/%s", team.Id, privileged.UID), "")
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		recorder = request(t, http.MethodPut, "/api/access-control/roles/"+privileged.UID, `{"version":2,"name":"custom:users:writer"}`)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		recorder = request(t, http.MethodDelete, "/api/access-control/roles/"+privileged.UID, "")
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("delete a role", func(t *testing.T) {
+		recorder := request(t, http.MethodDelete, "/api/access-control/roles/"+role.UID, "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.False(t, viewerCanReadOrgUsers(t))
+
+		recorder = request(t, http.MethodGet, "/api/access-control/roles/"+role.UID, "")
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -31,7 +31,7 @@ func newApi(ac accesscontrol.AccessControl, router routing.RouteRegister, manage
 }
 
 func (a *api) registerEndpoints() {
-	auth := middleware.Middleware(a.ac)
+	auth := middleware.RouteMiddleware(a.ac)
 	disable := middleware.Disable(a.ac.IsDisabled())
 	a.router.Group(fmt.Sprintf("/api/access-control/%s", a.service.options.Resource), func(r routing.RouteRegister) {
 		idScope := accesscontrol.Scope(a.service.options.Resource, "id", accesscontrol.Parameter(":resourceID"))
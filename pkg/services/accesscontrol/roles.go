@@ -96,6 +96,62 @@ var (
 		},
 	}
 
+	rolesReaderRole = RoleDTO{
+		Name:        rolesReader,
+		DisplayName: "Role reader",
+		Description: "Read custom roles and their assignments to users and teams within a single organization.",
+		Group:       "Roles",
+		Version:     1,
+		Permissions: []Permission{
+			{
+				Action: ActionRolesRead,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionUsersRolesList,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionTeamsRolesList,
+				Scope:  ScopeTeamsAll,
+			},
+		},
+	}
+
+	rolesWriterRole = RoleDTO{
+		Name:        rolesWriter,
+		DisplayName: "Role writer",
+		Description: "Create, update and delete custom roles and assign them to users and teams within a single organization.",
+		Group:       "Roles",
+		Version:     1,
+		Permissions: ConcatPermissions(rolesReaderRole.Permissions, []Permission{
+			{
+				Action: ActionRolesWrite,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionRolesDelete,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionUsersRolesAdd,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionUsersRolesRemove,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionTeamsRolesAdd,
+				Scope:  ScopeTeamsAll,
+			},
+			{
+				Action: ActionTeamsRolesRemove,
+				Scope:  ScopeTeamsAll,
+			},
+		}),
+	}
+
 	settingsReaderRole = RoleDTO{
 		Version:     4,
 		DisplayName: "Setting reader",
@@ -152,14 +208,18 @@ var (
 	usersWriterRole = RoleDTO{
 		Name:        usersWriter,
 		DisplayName: "User writer",
-		Description: "Read and update all attributes and settings for all users in Grafana: update user information, read user information, create or enable or disable a user, make a user a Grafana administrator, sign out a user, update a user’s authentication token, or update quotas for all users.",
+		Description: "Read and update all attributes and settings for all users in Grafana: update user information, read user information, create or enable or disable a user, make a user a Grafana administrator, sign out a user, update a user’s authentication token, reset a user’s two-factor authentication, or update quotas for all users.",
 		Group:       "User administration (global)",
-		Version:     3,
+		Version:     4,
 		Permissions: ConcatPermissions(usersReaderRole.Permissions, []Permission{
 			{
 				Action: ActionUsersPasswordUpdate,
 				Scope:  ScopeGlobalUsersAll,
 			},
+			{
+				Action: ActionUsersTwoFactorReset,
+				Scope:  ScopeGlobalUsersAll,
+			},
 			{
 				Action: ActionUsersCreate,
 			},
@@ -206,6 +266,8 @@ const (
 	ldapWriter          = "fixed:ldap:writer"
 	orgUsersReader      = "fixed:org.users:reader"
 	orgUsersWriter      = "fixed:org.users:writer"
+	rolesReader         = "fixed:roles:reader"
+	rolesWriter         = "fixed:roles:writer"
 	settingsReader      = "fixed:settings:reader"
 	statsReader         = "fixed:stats:reader"
 	usersReader         = "fixed:users:reader"
@@ -225,6 +287,8 @@ var (
 		ldapWriter:          ldapWriterRole,
 		orgUsersReader:      orgUsersReaderRole,
 		orgUsersWriter:      orgUsersWriterRole,
+		rolesReader:         rolesReaderRole,
+		rolesWriter:         rolesWriterRole,
 		settingsReader:      settingsReaderRole,
 		statsReader:         statsReaderRole,
 		usersReader:         usersReaderRole,
@@ -247,6 +311,8 @@ var (
 		string(models.ROLE_ADMIN): {
 			orgUsersReader,
 			orgUsersWriter,
+			rolesReader,
+			rolesWriter,
 		},
 		string(models.ROLE_EDITOR): {
 			datasourcesExplorer,
@@ -275,6 +341,26 @@ func ValidateFixedRole(role RoleDTO) error {
 	return nil
 }
 
+// ValidateCustomRole errors when a custom role name collides with fixed or
+// managed roles or when one of its permissions is not valid
+func ValidateCustomRole(name string, permissions []Permission) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrRoleNameMissing
+	}
+	if strings.HasPrefix(name, FixedRolePrefix) || strings.HasPrefix(name, ManagedRolePrefix) {
+		return ErrReservedRolePrefix
+	}
+	for _, p := range permissions {
+		if p.Action == "" {
+			return ErrInvalidAction
+		}
+		if p.Scope != "" && !ValidateScope(p.Scope) {
+			return fmt.Errorf("'%s' %w", p.Scope, ErrInvalidScope)
+		}
+	}
+	return nil
+}
+
 // ValidateBuiltInRoles errors when a built-in role does not match expected pattern
 func ValidateBuiltInRoles(builtInRoles []string) error {
 	for _, br := range builtInRoles {
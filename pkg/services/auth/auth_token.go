@@ -23,6 +23,9 @@ var getTime = time.Now
 
 const urgentRotateTime = 1 * time.Minute
 
+// lastSeenUpdateInterval limits how often the last activity of a token is written.
+const lastSeenUpdateInterval = 1 * time.Minute
+
 func ProvideUserAuthTokenService(sqlStore *sqlstore.SQLStore, serverLockService *serverlock.ServerLockService,
 	cfg *setting.Cfg) *UserAuthTokenService {
 	s := &UserAuthTokenService{
@@ -82,12 +85,15 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *models.Use
 		UpdatedAt:     now,
 		SeenAt:        0,
 		RevokedAt:     0,
+		LastSeenAt:    now,
 		AuthTokenSeen: false,
 	}
 
-	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		_, err = dbSession.Insert(&userAuthToken)
-		return err
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, err := dbSession.Insert(&userAuthToken); err != nil {
+			return err
+		}
+		return s.evictExceedingTokens(dbSession, user.Id)
 	})
 
 	if err != nil {
@@ -139,6 +145,24 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 		}
 	}
 
+	if s.isIdle(&model) {
+		return nil, &models.TokenExpiredError{
+			UserID:  model.UserId,
+			TokenID: model.Id,
+		}
+	}
+
+	if model.LastSeenAt < getTime().Add(-lastSeenUpdateInterval).Unix() {
+		model.LastSeenAt = getTime().Unix()
+		err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+			_, err := dbSession.Exec("UPDATE user_auth_token SET last_seen_at = ? WHERE id = ?", model.LastSeenAt, model.Id)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if model.AuthToken != hashedToken && model.PrevAuthToken == hashedToken && model.AuthTokenSeen {
 		modelCopy := model
 		modelCopy.AuthTokenSeen = false
@@ -404,6 +428,9 @@ func (s *UserAuthTokenService) GetUserTokens(ctx context.Context, userId int64)
 		}
 
 		for _, token := range tokens {
+			if s.isIdle(token) {
+				continue
+			}
 			var userToken models.UserToken
 			if err := token.toUserToken(&userToken); err != nil {
 				return err
@@ -440,6 +467,61 @@ func (s *UserAuthTokenService) GetUserRevokedTokens(ctx context.Context, userId
 	return result, err
 }
 
+// evictExceedingTokens soft revokes the oldest sessions of the user exceeding
+// the configured maximum number of concurrent sessions, so that they are still
+// listed as revoked and their clients are told the session was revoked.
+func (s *UserAuthTokenService) evictExceedingTokens(dbSession *sqlstore.DBSession, userId int64) error {
+	if s.Cfg.LoginMaxSessionsPerUser <= 0 {
+		return nil
+	}
+
+	var tokens []*userAuthToken
+	err := dbSession.Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
+		userId,
+		s.createdAfterParam(),
+		s.rotatedAfterParam()).
+		Desc("created_at", "id").
+		Find(&tokens)
+	if err != nil {
+		return err
+	}
+
+	active := 0
+	for _, token := range tokens {
+		if s.isIdle(token) {
+			continue
+		}
+		active++
+		if active <= s.Cfg.LoginMaxSessionsPerUser {
+			continue
+		}
+
+		token.RevokedAt = getTime().Unix()
+		if _, err := dbSession.ID(token.Id).Cols("revoked_at").Update(token); err != nil {
+			return err
+		}
+		s.log.Debug("user auth token evicted", "tokenId", token.Id, "userId", userId, "maxSessions", s.Cfg.LoginMaxSessionsPerUser)
+	}
+
+	return nil
+}
+
+// isIdle reports whether the token has not been used for longer than the idle timeout.
+func (s *UserAuthTokenService) isIdle(token *userAuthToken) bool {
+	if s.Cfg.LoginIdleTimeout <= 0 {
+		return false
+	}
+
+	lastActivity := token.LastSeenAt
+	if token.RotatedAt > lastActivity {
+		lastActivity = token.RotatedAt
+	}
+	if token.CreatedAt > lastActivity {
+		lastActivity = token.CreatedAt
+	}
+	return lastActivity <= getTime().Add(-s.Cfg.LoginIdleTimeout).Unix()
+}
+
 func (s *UserAuthTokenService) createdAfterParam() int64 {
 	return getTime().Add(-s.Cfg.LoginMaxLifetime).Unix()
 }
@@ -474,6 +474,7 @@ func TestUserAuthToken(t *testing.T) {
 			RotatedAt:     4,
 			CreatedAt:     5,
 			UpdatedAt:     6,
+			LastSeenAt:    7,
 			UnhashedToken: "e",
 		}
 		utBytes, err := json.Marshal(ut)
@@ -507,6 +508,7 @@ func TestUserAuthToken(t *testing.T) {
 			RotatedAt:     4,
 			CreatedAt:     5,
 			UpdatedAt:     6,
+			LastSeenAt:    7,
 			UnhashedToken: "e",
 		}
 		uatBytes, err := json.Marshal(uat)
@@ -528,6 +530,99 @@ func TestUserAuthToken(t *testing.T) {
 	})
 }
 
+func TestUserAuthTokenSessionManagement(t *testing.T) {
+	user := &models.User{Id: int64(10)}
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	createToken := func(t *testing.T, ctx *testContext) *models.UserToken {
+		t.Helper()
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), user,
+			net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+		return userToken
+	}
+
+	t.Run("evicts the oldest sessions exceeding the limit", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.Cfg.LoginMaxSessionsPerUser = 2
+
+		first := createToken(t, ctx)
+		getTime = func() time.Time { return now.Add(time.Minute) }
+		second := createToken(t, ctx)
+		getTime = func() time.Time { return now.Add(2 * time.Minute) }
+		third := createToken(t, ctx)
+
+		tokens, err := ctx.tokenService.GetUserTokens(context.Background(), user.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), first.UnhashedToken)
+		var tokenRevokedErr *models.TokenRevokedError
+		require.ErrorAs(t, err, &tokenRevokedErr)
+
+		evicted, err := ctx.getAuthTokenByID(first.Id)
+		require.NoError(t, err)
+		require.NotNil(t, evicted)
+		require.Equal(t, getTime().Unix(), evicted.RevokedAt)
+
+		revoked, err := ctx.tokenService.GetUserRevokedTokens(context.Background(), user.Id)
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		require.Equal(t, first.Id, revoked[0].Id)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), second.UnhashedToken)
+		require.NoError(t, err)
+		_, err = ctx.tokenService.LookupToken(context.Background(), third.UnhashedToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("expires sessions idle for longer than the idle timeout", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.Cfg.LoginIdleTimeout = 30 * time.Minute
+		getTime = func() time.Time { return now }
+		userToken := createToken(t, ctx)
+
+		getTime = func() time.Time { return now.Add(20 * time.Minute) }
+		lookedUp, err := ctx.tokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+		require.NoError(t, err)
+		require.Equal(t, getTime().Unix(), lookedUp.LastSeenAt)
+
+		stored, err := ctx.getAuthTokenByID(userToken.Id)
+		require.NoError(t, err)
+		require.Equal(t, getTime().Unix(), stored.LastSeenAt)
+
+		// Activity moved the idle deadline.
+		getTime = func() time.Time { return now.Add(40 * time.Minute) }
+		_, err = ctx.tokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+		require.NoError(t, err)
+
+		getTime = func() time.Time { return now.Add(71 * time.Minute) }
+		tokens, err := ctx.tokenService.GetUserTokens(context.Background(), user.Id)
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+
+		_, err = ctx.tokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+		var tokenExpiredErr *models.TokenExpiredError
+		require.ErrorAs(t, err, &tokenExpiredErr)
+	})
+
+	t.Run("does not write the last activity on every lookup", func(t *testing.T) {
+		ctx := createTestContext(t)
+		getTime = func() time.Time { return now }
+		userToken := createToken(t, ctx)
+
+		getTime = func() time.Time { return now.Add(30 * time.Second) }
+		_, err := ctx.tokenService.LookupToken(context.Background(), userToken.UnhashedToken)
+		require.NoError(t, err)
+
+		stored, err := ctx.getAuthTokenByID(userToken.Id)
+		require.NoError(t, err)
+		require.Equal(t, now.Unix(), stored.LastSeenAt)
+	})
+}
+
 func createTestContext(t *testing.T) *testContext {
 	t.Helper()
 	maxInactiveDurationVal, _ := time.ParseDuration("168h")
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"net"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	ipv4BindingPrefix = 24
+	ipv6BindingPrefix = 64
+)
+
+// IsClientIPAllowed reports whether the token may be used from clientIP
+// according to the configured session IP binding. Tokens created without a
+// known client address are not bound.
+func IsClientIPAllowed(cfg *setting.Cfg, token *models.UserToken, clientIP net.IP) bool {
+	if cfg.LoginSessionIPBinding == "" || token.ClientIp == "" {
+		return true
+	}
+
+	tokenIP := net.ParseIP(token.ClientIp)
+	if tokenIP == nil || clientIP == nil {
+		return false
+	}
+
+	switch cfg.LoginSessionIPBinding {
+	case setting.SessionIPBindingIP:
+		return tokenIP.Equal(clientIP)
+	case setting.SessionIPBindingSubnet:
+		if tokenIP4, clientIP4 := tokenIP.To4(), clientIP.To4(); tokenIP4 != nil || clientIP4 != nil {
+			if tokenIP4 == nil || clientIP4 == nil {
+				return false
+			}
+			mask := net.CIDRMask(ipv4BindingPrefix, 32)
+			return tokenIP4.Mask(mask).Equal(clientIP4.Mask(mask))
+		}
+		mask := net.CIDRMask(ipv6BindingPrefix, 128)
+		return tokenIP.Mask(mask).Equal(clientIP.Mask(mask))
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"net"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsClientIPAllowed(t *testing.T) {
+	tests := []struct {
+		binding  string
+		tokenIP  string
+		clientIP string
+		allowed  bool
+	}{
+		{binding: "", tokenIP: "192.168.10.11", clientIP: "10.0.0.1", allowed: true},
+		{binding: setting.SessionIPBindingIP, tokenIP: "192.168.10.11", clientIP: "192.168.10.11", allowed: true},
+		{binding: setting.SessionIPBindingIP, tokenIP: "192.168.10.11", clientIP: "192.168.10.12", allowed: false},
+		{binding: setting.SessionIPBindingIP, tokenIP: "", clientIP: "192.168.10.12", allowed: true},
+		{binding: setting.SessionIPBindingSubnet, tokenIP: "192.168.10.11", clientIP: "192.168.10.200", allowed: true},
+		{binding: setting.SessionIPBindingSubnet, tokenIP: "192.168.10.11", clientIP: "192.168.11.11", allowed: false},
+		{binding: setting.SessionIPBindingSubnet, tokenIP: "2001:db8:1:2::1", clientIP: "2001:db8:1:2:ffff::1", allowed: true},
+		{binding: setting.SessionIPBindingSubnet, tokenIP: "2001:db8:1:2::1", clientIP: "2001:db8:1:3::1", allowed: false},
+		{binding: setting.SessionIPBindingSubnet, tokenIP: "192.168.10.11", clientIP: "2001:db8:1:2::1", allowed: false},
+	}
+
+	for _, tc := range tests {
+		cfg := &setting.Cfg{LoginSessionIPBinding: tc.binding}
+		token := &models.UserToken{ClientIp: tc.tokenIP}
+		require.Equal(t, tc.allowed, IsClientIPAllowed(cfg, token, net.ParseIP(tc.clientIP)),
+			"binding %q, token IP %q, client IP %q", tc.binding, tc.tokenIP, tc.clientIP)
+	}
+}
@@ -19,6 +19,7 @@ type userAuthToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
+	LastSeenAt    int64
 	UnhashedToken string `xorm:"-"`
 }
 
@@ -45,6 +46,7 @@ func (uat *userAuthToken) fromUserToken(ut *models.UserToken) error {
 	uat.CreatedAt = ut.CreatedAt
 	uat.UpdatedAt = ut.UpdatedAt
 	uat.RevokedAt = ut.RevokedAt
+	uat.LastSeenAt = ut.LastSeenAt
 	uat.UnhashedToken = ut.UnhashedToken
 
 	return nil
@@ -67,6 +69,7 @@ func (uat *userAuthToken) toUserToken(ut *models.UserToken) error {
 	ut.CreatedAt = uat.CreatedAt
 	ut.UpdatedAt = uat.UpdatedAt
 	ut.RevokedAt = uat.RevokedAt
+	ut.LastSeenAt = uat.LastSeenAt
 	ut.UnhashedToken = uat.UnhashedToken
 
 	return nil
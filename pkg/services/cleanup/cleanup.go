@@ -49,6 +49,7 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpTmpFiles()
 			srv.deleteExpiredSnapshots(ctx)
 			srv.deleteExpiredDashboardVersions(ctx)
+			srv.purgeDeletedDashboards(ctx)
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites(ctx)
 			srv.deleteStaleShortURLs(ctx)
@@ -143,6 +144,21 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) purgeDeletedDashboards(ctx context.Context) {
+	if srv.Cfg.DeletedDashboardRetention <= 0 {
+		return
+	}
+
+	cmd := models.PurgeDeletedDashboardsCommand{
+		OlderThan: time.Now().Add(-srv.Cfg.DeletedDashboardRetention),
+	}
+	if err := bus.Dispatch(ctx, &cmd); err != nil {
+		srv.log.Error("Failed to purge deleted dashboards", "error", err.Error())
+	} else {
+		srv.log.Debug("Purged deleted dashboards", "rows affected", cmd.DeletedRows)
+	}
+}
+
 func (srv *CleanUpService) deleteOldLoginAttempts(ctx context.Context) {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
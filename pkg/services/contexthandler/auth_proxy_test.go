@@ -106,5 +106,5 @@ func getContextHandler(t *testing.T) *ContextHandler {
 	tracer, err := tracing.InitializeTracerForTest()
 	require.NoError(t, err)
 
-	return ProvideService(cfg, userAuthTokenSvc, authJWTSvc, remoteCacheSvc, renderSvc, sqlStore, tracer)
+	return ProvideService(cfg, userAuthTokenSvc, authJWTSvc, remoteCacheSvc, renderSvc, sqlStore, tracer, nil)
 }
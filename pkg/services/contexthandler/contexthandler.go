@@ -17,10 +17,12 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/middleware/cookies"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/contexthandler/authproxy"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -30,13 +32,14 @@ import (
 const (
 	InvalidUsernamePassword = "invalid username or password"
 	InvalidAPIKey           = "invalid API key"
+	TwoFactorBasicAuth      = "basic authentication is not available for users with two-factor authentication"
 )
 
 const ServiceName = "ContextHandler"
 
 func ProvideService(cfg *setting.Cfg, tokenService models.UserTokenService, jwtService models.JWTService,
 	remoteCache *remotecache.RemoteCache, renderService rendering.Service, sqlStore *sqlstore.SQLStore,
-	tracer tracing.Tracer) *ContextHandler {
+	tracer tracing.Tracer, twoFactorService *twofactor.Service) *ContextHandler {
 	return &ContextHandler{
 		Cfg:              cfg,
 		AuthTokenService: tokenService,
@@ -44,6 +47,7 @@ func ProvideService(cfg *setting.Cfg, tokenService models.UserTokenService, jwtS
 		RemoteCache:      remoteCache,
 		RenderService:    renderService,
 		SQLStore:         sqlStore,
+		TwoFactorService: twoFactorService,
 		tracer:           tracer,
 	}
 }
@@ -56,6 +60,7 @@ type ContextHandler struct {
 	RemoteCache      *remotecache.RemoteCache
 	RenderService    rendering.Service
 	SQLStore         *sqlstore.SQLStore
+	TwoFactorService *twofactor.Service
 	tracer           tracing.Tracer
 	// GetTime returns the current time.
 	// Stubbable by tests.
@@ -305,6 +310,19 @@ func (h *ContextHandler) initContextWithBasicAuth(reqContext *models.ReqContext,
 
 	user := authQuery.User
 
+	// The password alone is not enough for users who have to provide a second factor.
+	if h.TwoFactorService != nil && h.TwoFactorService.IsEnabled() && authQuery.AuthModule == "grafana" {
+		required, err := h.TwoFactorService.IsRequired(ctx, user)
+		if err != nil {
+			reqContext.JsonApiErr(500, "Failed to check two-factor authentication", err)
+			return true
+		}
+		if required {
+			reqContext.JsonApiErr(401, TwoFactorBasicAuth, nil)
+			return true
+		}
+	}
+
 	query := models.GetSignedInUserQuery{UserId: user.Id, OrgId: orgID}
 	if err := bus.Dispatch(ctx, &query); err != nil {
 		reqContext.Logger.Error(
@@ -341,6 +359,24 @@ func (h *ContextHandler) initContextWithToken(reqContext *models.ReqContext, org
 		return false
 	}
 
+	if h.Cfg.LoginSessionIPBinding != "" {
+		addr := reqContext.RemoteAddr()
+		ip, err := network.GetIPFromAddress(addr)
+		if err != nil {
+			reqContext.Logger.Debug("Failed to get client IP address", "addr", addr, "err", err)
+			ip = nil
+		}
+		if !auth.IsClientIPAllowed(h.Cfg, token, ip) {
+			reqContext.Logger.Warn("Session used from an unexpected client address, revoking it",
+				"userId", token.UserId, "tokenId", token.Id, "sessionIP", token.ClientIp, "clientIP", ip)
+			if err := h.AuthTokenService.RevokeToken(ctx, token, true); err != nil && !errors.Is(err, models.ErrUserTokenNotFound) {
+				reqContext.Logger.Error("Failed to revoke auth token", "error", err)
+			}
+			reqContext.LookupTokenErr = &models.TokenRevokedError{UserID: token.UserId, TokenID: token.Id}
+			return false
+		}
+	}
+
 	query := models.GetSignedInUserQuery{UserId: token.UserId, OrgId: orgID}
 	if err := bus.Dispatch(ctx, &query); err != nil {
 		reqContext.Logger.Error("Failed to get user with id", "userId", token.UserId, "error", err)
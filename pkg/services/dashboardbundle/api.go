@@ -0,0 +1,103 @@
+package dashboardbundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	maxBundleSize       = 100 << 20
+	maxMultipartMemory  = 10 << 20
+	bundleFormField     = "bundle"
+	optionsFormField    = "options"
+	bundleContentType   = "application/zip"
+	bundleFileExtension = ".zip"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/folders", func(route routing.RouteRegister) {
+		route.Get("/:uid/export", routing.Wrap(s.exportHandler))
+		route.Post("/import", routing.Wrap(s.importHandler))
+	}, middleware.ReqOrgAdmin)
+}
+
+func (s *Service) exportHandler(c *models.ReqContext) response.Response {
+	b, err := s.Export(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toErrorResponse(err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to write bundle", err)
+	}
+
+	return response.Respond(http.StatusOK, buf.Bytes()).
+		SetHeader("Content-Type", bundleContentType).
+		SetHeader("Content-Disposition", fmt.Sprintf("attachment; filename=%q", b.Manifest.Folder.UID+bundleFileExtension))
+}
+
+// importHandler imports the bundle sent in the bundle field of a multipart form, with the ImportOptions in its
+// options field.
+func (s *Service) importHandler(c *models.ReqContext) response.Response {
+	if err := c.Req.ParseMultipartForm(maxMultipartMemory); err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid multipart form", err)
+	}
+
+	file, _, err := c.Req.FormFile(bundleFormField)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Missing %s form field", bundleFormField), err)
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBundleSize+1))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read bundle", err)
+	}
+	if len(data) > maxBundleSize {
+		return response.Error(http.StatusRequestEntityTooLarge, "Bundle is too large", nil)
+	}
+
+	opts := ImportOptions{}
+	if raw := c.Req.FormValue(optionsFormField); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid import options", err)
+		}
+	}
+
+	b, err := Read(data)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+
+	result, err := s.Import(c.Req.Context(), c.SignedInUser, b, opts)
+	if err != nil {
+		if errors.Is(err, ErrImportConflict) {
+			return response.JSON(http.StatusConflict, result)
+		}
+		s.log.Error("Failed to import bundle", "folderUid", b.Manifest.Folder.UID, "error", err)
+		return toErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toErrorResponse(err error) response.Response {
+	if errors.Is(err, ErrInvalidBundle) ||
+		errors.Is(err, ErrUnsupportedBundleVersion) ||
+		errors.Is(err, ErrInvalidMapping) ||
+		errors.Is(err, ErrInvalidImportOptions) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return apierrors.ToFolderErrorResponse(err)
+}
@@ -0,0 +1,245 @@
+package dashboardbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// FormatVersion is the version of the archive format written by Write.
+const FormatVersion = 1
+
+const (
+	manifestPath          = "manifest.json"
+	dashboardsDir         = "dashboards"
+	libraryElementsDir    = "library-elements"
+	alertRulesDir         = "alert-rules"
+	maxArchiveEntrySize   = 50 << 20
+	maxArchiveSize        = 200 << 20
+	maxArchiveEntries     = 10000
+	datasourceInputType   = "datasource"
+	datasourceInputPrefix = "DS_"
+)
+
+var (
+	ErrInvalidBundle            = errors.New("invalid bundle")
+	ErrUnsupportedBundleVersion = errors.New("unsupported bundle version")
+	ErrInvalidMapping           = errors.New("invalid data source mapping")
+	ErrInvalidImportOptions     = errors.New("invalid import options")
+	ErrImportConflict           = errors.New("the bundle conflicts with existing content")
+)
+
+// Bundle is a folder exported with its dashboards, the library elements they use and its alert rules. References to
+// data sources are replaced with inputs, so that they can be mapped to the data sources of another instance.
+type Bundle struct {
+	Manifest        Manifest
+	Dashboards      []*simplejson.Json
+	LibraryElements []*LibraryElement
+	AlertRules      []*AlertRule
+}
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Version        int       `json:"version"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	Exported       time.Time `json:"exported"`
+	Folder         Folder    `json:"folder"`
+	Inputs         []Input   `json:"inputs"`
+}
+
+type Folder struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// Input is a data source used by the content of a bundle. Its references are replaced with ${Name}, in the format of
+// the __inputs of exported dashboards.
+type Input struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	Type       string `json:"type"`
+	PluginID   string `json:"pluginId"`
+	PluginName string `json:"pluginName,omitempty"`
+	// UID is the uid of the data source in the exporting instance. Inputs are mapped to the data source with the same
+	// uid, or else with the same name, when the import does not map them.
+	UID string `json:"uid"`
+}
+
+type LibraryElement struct {
+	UID         string          `json:"uid"`
+	Name        string          `json:"name"`
+	Kind        int64           `json:"kind"`
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Model       json.RawMessage `json:"model"`
+}
+
+// AlertRule is a unified alert rule of the folder.
+type AlertRule struct {
+	UID             string            `json:"uid"`
+	Title           string            `json:"title"`
+	RuleGroup       string            `json:"ruleGroup"`
+	Condition       string            `json:"condition"`
+	Data            []AlertQuery      `json:"data"`
+	IntervalSeconds int64             `json:"intervalSeconds"`
+	For             string            `json:"for"`
+	NoDataState     string            `json:"noDataState"`
+	ExecErrState    string            `json:"execErrState"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	DashboardUID    *string           `json:"dashboardUid,omitempty"`
+	PanelID         *int64            `json:"panelId,omitempty"`
+}
+
+type AlertQuery struct {
+	RefID             string            `json:"refId"`
+	QueryType         string            `json:"queryType"`
+	RelativeTimeRange RelativeTimeRange `json:"relativeTimeRange"`
+	DatasourceUID     string            `json:"datasourceUid"`
+	Model             json.RawMessage   `json:"model"`
+}
+
+type RelativeTimeRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// Write writes the bundle as a zip archive holding the manifest and a JSON file per dashboard, library element and
+// alert rule.
+func (b *Bundle) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	writeJSON := func(name string, value interface{}) error {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	if err := writeJSON(manifestPath, b.Manifest); err != nil {
+		return err
+	}
+	for i, dash := range b.Dashboards {
+		name := dash.Get("uid").MustString(fmt.Sprintf("dashboard-%d", i))
+		if err := writeJSON(path.Join(dashboardsDir, name+".json"), dash); err != nil {
+			return err
+		}
+	}
+	for _, element := range b.LibraryElements {
+		if err := writeJSON(path.Join(libraryElementsDir, element.UID+".json"), element); err != nil {
+			return err
+		}
+	}
+	for _, rule := range b.AlertRules {
+		if err := writeJSON(path.Join(alertRulesDir, rule.UID+".json"), rule); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Read reads a bundle written by Write.
+func Read(data []byte) (*Bundle, error) {
+	return read(data, maxArchiveEntries, maxArchiveSize)
+}
+
+// read reads a bundle of at most maxEntries archive entries, which hold at most maxSize bytes once uncompressed.
+func read(data []byte, maxEntries int, maxSize int64) (*Bundle, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if len(archive.File) > maxEntries {
+		return nil, fmt.Errorf("%w: the archive has more than %d entries", ErrInvalidBundle, maxEntries)
+	}
+
+	b := &Bundle{}
+	hasManifest := false
+	remaining := maxSize
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.UncompressedSize64 > maxArchiveEntrySize {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidBundle, f.Name)
+		}
+		if f.UncompressedSize64 > uint64(remaining) {
+			return nil, fmt.Errorf("%w: the archive holds more than %d bytes", ErrInvalidBundle, maxSize)
+		}
+
+		// The sizes in the headers of the archive are not trusted, the limits are enforced while reading as well.
+		limit := int64(maxArchiveEntrySize)
+		if remaining < limit {
+			limit = remaining
+		}
+		content, err := readEntry(f, limit)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= int64(len(content))
+
+		switch dir, _ := path.Split(f.Name); {
+		case f.Name == manifestPath:
+			hasManifest = true
+			err = json.Unmarshal(content, &b.Manifest)
+		case dir == dashboardsDir+"/":
+			var dash *simplejson.Json
+			if dash, err = simplejson.NewJson(content); err == nil {
+				b.Dashboards = append(b.Dashboards, dash)
+			}
+		case dir == libraryElementsDir+"/":
+			element := &LibraryElement{}
+			if err = json.Unmarshal(content, element); err == nil {
+				b.LibraryElements = append(b.LibraryElements, element)
+			}
+		case dir == alertRulesDir+"/":
+			rule := &AlertRule{}
+			if err = json.Unmarshal(content, rule); err == nil {
+				b.AlertRules = append(b.AlertRules, rule)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+		}
+	}
+
+	if !hasManifest {
+		return nil, fmt.Errorf("%w: %s is missing", ErrInvalidBundle, manifestPath)
+	}
+	if b.Manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedBundleVersion, b.Manifest.Version)
+	}
+	return b, nil
+}
+
+// readEntry reads the content of an archive entry, which must not be larger than limit.
+func readEntry(f *zip.File, limit int64) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer func() { _ = r.Close() }()
+
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: %s is too large", ErrInvalidBundle, f.Name)
+	}
+	return content, nil
+}
@@ -0,0 +1,100 @@
+package dashboardbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestBundle(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(`{"uid": "hosts", "title": "Hosts", "panels": [{"id": 1, "title": "CPU"}]}`))
+	require.NoError(t, err)
+
+	b := &Bundle{
+		Manifest: Manifest{
+			Version:  FormatVersion,
+			Exported: time.Date(2021, 11, 20, 10, 0, 0, 0, time.UTC),
+			Folder:   Folder{UID: "infra", Title: "Infrastructure"},
+			Inputs:   []Input{{Name: "DS_PROMETHEUS", Label: "Prometheus", Type: "datasource", PluginID: "prometheus", UID: "prom"}},
+		},
+		Dashboards:      []*simplejson.Json{dash},
+		LibraryElements: []*LibraryElement{{UID: "cpu", Name: "CPU", Kind: 1, Model: json.RawMessage(`{"title":"CPU"}`)}},
+		AlertRules:      []*AlertRule{{UID: "high-cpu", Title: "High CPU", For: "5m0s", Data: []AlertQuery{{RefID: "A", DatasourceUID: "${DS_PROMETHEUS}", Model: json.RawMessage(`{}`)}}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf))
+
+	t.Run("should read a written bundle", func(t *testing.T) {
+		read, err := Read(buf.Bytes())
+		require.NoError(t, err)
+
+		assert.Equal(t, b.Manifest, read.Manifest)
+		require.Len(t, read.Dashboards, 1)
+		assert.Equal(t, "Hosts", read.Dashboards[0].Get("title").MustString())
+		require.Len(t, read.LibraryElements, 1)
+		assert.Equal(t, "cpu", read.LibraryElements[0].UID)
+		assert.JSONEq(t, `{"title":"CPU"}`, string(read.LibraryElements[0].Model))
+		require.Len(t, read.AlertRules, 1)
+		assert.Equal(t, "High CPU", read.AlertRules[0].Title)
+		assert.Equal(t, "${DS_PROMETHEUS}", read.AlertRules[0].Data[0].DatasourceUID)
+	})
+
+	t.Run("should reject an archive without manifest", func(t *testing.T) {
+		var archive bytes.Buffer
+		w := zip.NewWriter(&archive)
+		_, err := w.Create("dashboards/hosts.json")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		_, err = Read(archive.Bytes())
+		require.ErrorIs(t, err, ErrInvalidBundle)
+	})
+
+	t.Run("should reject a bundle of another version", func(t *testing.T) {
+		other := &Bundle{Manifest: Manifest{Version: FormatVersion + 1}}
+		var archive bytes.Buffer
+		require.NoError(t, other.Write(&archive))
+
+		_, err := Read(archive.Bytes())
+		require.ErrorIs(t, err, ErrUnsupportedBundleVersion)
+	})
+
+	t.Run("should reject an archive with too many entries", func(t *testing.T) {
+		_, err := read(buf.Bytes(), 3, maxArchiveSize)
+		require.ErrorIs(t, err, ErrInvalidBundle)
+		assert.Contains(t, err.Error(), "more than 3 entries")
+
+		_, err = read(buf.Bytes(), 4, maxArchiveSize)
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject an archive larger than the total size limit once uncompressed", func(t *testing.T) {
+		var archive bytes.Buffer
+		w := zip.NewWriter(&archive)
+		for _, name := range []string{"dashboards/a.json", "dashboards/b.json", "dashboards/c.json"} {
+			f, err := w.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(`{"title": "` + strings.Repeat("x", 100) + `"}`))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		_, err := read(archive.Bytes(), maxArchiveEntries, 250)
+		require.ErrorIs(t, err, ErrInvalidBundle)
+		assert.Contains(t, err.Error(), "more than 250 bytes")
+	})
+
+	t.Run("should reject data that is not an archive", func(t *testing.T) {
+		_, err := Read([]byte(`{"dashboard": {}}`))
+		require.ErrorIs(t, err, ErrInvalidBundle)
+	})
+}
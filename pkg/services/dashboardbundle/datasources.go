@@ -0,0 +1,225 @@
+package dashboardbundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var invalidInputNameChars = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// templater replaces the data source references of exported content with inputs.
+type templater struct {
+	byUID  map[string]*models.DataSource
+	byName map[string]*models.DataSource
+	// inputs are the inputs created so far, by data source uid
+	inputs map[string]*Input
+	names  map[string]bool
+}
+
+func newTemplater(dataSources []*models.DataSource) *templater {
+	t := &templater{
+		byUID:  make(map[string]*models.DataSource, len(dataSources)),
+		byName: make(map[string]*models.DataSource, len(dataSources)),
+		inputs: make(map[string]*Input),
+		names:  make(map[string]bool),
+	}
+	for _, ds := range dataSources {
+		t.byUID[ds.Uid] = ds
+		t.byName[ds.Name] = ds
+	}
+	return t
+}
+
+// Inputs returns the inputs created so far, sorted by name.
+func (t *templater) Inputs() []Input {
+	inputs := make([]Input, 0, len(t.inputs))
+	for _, input := range t.inputs {
+		inputs = append(inputs, *input)
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].Name < inputs[j].Name })
+	return inputs
+}
+
+// input returns the input of a data source, creating it on first use.
+func (t *templater) input(ds *models.DataSource) *Input {
+	if input, ok := t.inputs[ds.Uid]; ok {
+		return input
+	}
+
+	base := datasourceInputPrefix + strings.Trim(invalidInputNameChars.ReplaceAllString(strings.ToUpper(ds.Name), "_"), "_")
+	name := base
+	for i := 2; t.names[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	t.names[name] = true
+
+	input := &Input{
+		Name:     name,
+		Label:    ds.Name,
+		Type:     datasourceInputType,
+		PluginID: ds.Type,
+		UID:      ds.Uid,
+	}
+	t.inputs[ds.Uid] = input
+	return input
+}
+
+// lookup returns the data source referenced by a "datasource" property, either by name or as a {type, uid} object.
+// References to variables, to the built-in data sources and to the default data source are not replaced.
+func (t *templater) lookup(ref interface{}) *models.DataSource {
+	switch v := ref.(type) {
+	case string:
+		if v == "" || strings.HasPrefix(v, "$") || strings.HasPrefix(v, "-- ") {
+			return nil
+		}
+		if ds, ok := t.byName[v]; ok {
+			return ds
+		}
+		return t.byUID[v]
+	case map[string]interface{}:
+		uid, _ := v["uid"].(string)
+		if uid == "" || strings.HasPrefix(uid, "$") {
+			return nil
+		}
+		return t.byUID[uid]
+	}
+	return nil
+}
+
+// Template replaces the data source references found in a decoded JSON value with references to inputs, in place.
+// It returns the names of the inputs used by the value.
+func (t *templater) Template(value interface{}) []string {
+	used := make(map[string]bool)
+
+	var visit func(value interface{})
+	visit = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			// keys are sorted so that inputs are named the same way on every export
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				child := v[key]
+				if key == "datasource" {
+					if ds := t.lookup(child); ds != nil {
+						input := t.input(ds)
+						used[input.Name] = true
+						v[key] = map[string]interface{}{"type": ds.Type, "uid": "${" + input.Name + "}"}
+						continue
+					}
+				}
+				visit(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				visit(child)
+			}
+		}
+	}
+	visit(value)
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TemplateRaw is Template for raw JSON.
+func (t *templater) TemplateRaw(data json.RawMessage) (json.RawMessage, []string, error) {
+	if len(data) == 0 {
+		return data, nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, nil, err
+	}
+	used := t.Template(value)
+	if len(used) == 0 {
+		return data, nil, nil
+	}
+
+	templated, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return templated, used, nil
+}
+
+// TemplateUID returns the reference to the input of a data source uid, or the uid itself when it is not the uid of a
+// data source, as for expressions.
+func (t *templater) TemplateUID(uid string) string {
+	ds, ok := t.byUID[uid]
+	if !ok {
+		return uid
+	}
+	return "${" + t.input(ds).Name + "}"
+}
+
+// resolveInputs returns the uid of the data source each input of a bundle is mapped to. Inputs are mapped explicitly
+// by mappings, from input name to data source uid or name, or else to the data source with the uid of the exported
+// data source, or else with its name. Both must have the type of the input.
+func resolveInputs(inputs []Input, mappings map[string]string, dataSources []*models.DataSource) (map[string]string, error) {
+	byUID := make(map[string]*models.DataSource, len(dataSources))
+	byName := make(map[string]*models.DataSource, len(dataSources))
+	for _, ds := range dataSources {
+		byUID[ds.Uid] = ds
+		byName[ds.Name] = ds
+	}
+
+	resolved := make(map[string]string, len(inputs))
+	missing := make([]string, 0)
+	for _, input := range inputs {
+		if input.Type != datasourceInputType {
+			continue
+		}
+
+		if mapped, ok := mappings[input.Name]; ok {
+			ds, ok := byUID[mapped]
+			if !ok {
+				ds, ok = byName[mapped]
+			}
+			if !ok {
+				return nil, fmt.Errorf("%w: data source %q mapped to %s not found", ErrInvalidMapping, mapped, input.Name)
+			}
+			resolved[input.Name] = ds.Uid
+			continue
+		}
+
+		if ds, ok := byUID[input.UID]; ok && ds.Type == input.PluginID {
+			resolved[input.Name] = ds.Uid
+		} else if ds, ok := byName[input.Label]; ok && ds.Type == input.PluginID {
+			resolved[input.Name] = ds.Uid
+		} else {
+			missing = append(missing, input.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: no data source found for %s", ErrInvalidMapping, strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// interpolate replaces the references to inputs in raw JSON with the uids of the data sources they are mapped to.
+func interpolate(data json.RawMessage, resolved map[string]string) json.RawMessage {
+	if len(data) == 0 || len(resolved) == 0 {
+		return data
+	}
+
+	pairs := make([]string, 0, 2*len(resolved))
+	for name, uid := range resolved {
+		pairs = append(pairs, "${"+name+"}", uid)
+	}
+	return json.RawMessage(strings.NewReplacer(pairs...).Replace(string(data)))
+}
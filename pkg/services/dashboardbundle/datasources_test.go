@@ -0,0 +1,91 @@
+package dashboardbundle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestTemplater(t *testing.T) {
+	tmpl := newTemplater([]*models.DataSource{
+		{Uid: "prom", Name: "Prometheus", Type: "prometheus"},
+		{Uid: "prom-eu", Name: "Prometheus (EU)", Type: "prometheus"},
+		{Uid: "loki", Name: "Prometheus-EU", Type: "loki"},
+	})
+
+	var model interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"panels": [
+			{"datasource": "Prometheus", "targets": [{"refId": "A", "datasource": {"type": "prometheus", "uid": "prom-eu"}}]},
+			{"datasource": {"type": "loki", "uid": "loki"}},
+			{"datasource": "$ds"},
+			{"datasource": "-- Mixed --"},
+			{"datasource": null}
+		]
+	}`), &model))
+
+	used := tmpl.Template(model)
+	assert.Equal(t, []string{"DS_PROMETHEUS", "DS_PROMETHEUS_EU", "DS_PROMETHEUS_EU_2"}, used)
+
+	templated, err := json.Marshal(model)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"panels": [
+			{"datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}, "targets": [{"refId": "A", "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS_EU}"}}]},
+			{"datasource": {"type": "loki", "uid": "${DS_PROMETHEUS_EU_2}"}},
+			{"datasource": "$ds"},
+			{"datasource": "-- Mixed --"},
+			{"datasource": null}
+		]
+	}`, string(templated))
+
+	assert.Equal(t, "${DS_PROMETHEUS}", tmpl.TemplateUID("prom"))
+	assert.Equal(t, "__expr__", tmpl.TemplateUID("__expr__"))
+	assert.Equal(t, []Input{
+		{Name: "DS_PROMETHEUS", Label: "Prometheus", Type: "datasource", PluginID: "prometheus", UID: "prom"},
+		{Name: "DS_PROMETHEUS_EU", Label: "Prometheus (EU)", Type: "datasource", PluginID: "prometheus", UID: "prom-eu"},
+		{Name: "DS_PROMETHEUS_EU_2", Label: "Prometheus-EU", Type: "datasource", PluginID: "loki", UID: "loki"},
+	}, tmpl.Inputs())
+}
+
+func TestResolveInputs(t *testing.T) {
+	inputs := []Input{
+		{Name: "DS_PROMETHEUS", Label: "Prometheus", Type: "datasource", PluginID: "prometheus", UID: "prom"},
+		{Name: "DS_LOKI", Label: "Loki", Type: "datasource", PluginID: "loki", UID: "loki"},
+	}
+	dataSources := []*models.DataSource{
+		{Uid: "prod-prom", Name: "Prometheus", Type: "prometheus"},
+		{Uid: "loki", Name: "Logs", Type: "loki"},
+		{Uid: "other-loki", Name: "Other logs", Type: "loki"},
+	}
+
+	t.Run("should map inputs by uid, then by name", func(t *testing.T) {
+		resolved, err := resolveInputs(inputs, nil, dataSources)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"DS_PROMETHEUS": "prod-prom", "DS_LOKI": "loki"}, resolved)
+	})
+
+	t.Run("should prefer explicit mappings by uid or name", func(t *testing.T) {
+		resolved, err := resolveInputs(inputs, map[string]string{"DS_LOKI": "Other logs"}, dataSources)
+		require.NoError(t, err)
+		assert.Equal(t, "other-loki", resolved["DS_LOKI"])
+	})
+
+	t.Run("should fail when an input cannot be mapped", func(t *testing.T) {
+		_, err := resolveInputs(inputs, nil, dataSources[:1])
+		require.ErrorIs(t, err, ErrInvalidMapping)
+		assert.Contains(t, err.Error(), "DS_LOKI")
+
+		_, err = resolveInputs(inputs, map[string]string{"DS_LOKI": "missing"}, dataSources)
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("should interpolate inputs in raw JSON", func(t *testing.T) {
+		model := interpolate(json.RawMessage(`{"datasource": {"uid": "${DS_LOKI}"}, "expr": "${job}"}`), map[string]string{"DS_LOKI": "loki"})
+		assert.JSONEq(t, `{"datasource": {"uid": "loki"}, "expr": "${job}"}`, string(model))
+	})
+}
@@ -0,0 +1,193 @@
+package dashboardbundle
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+const exportSearchPageSize = 1000
+
+// Export exports a folder with its dashboards, the library elements they use and its alert rules.
+func (s *Service) Export(ctx context.Context, user *models.SignedInUser, folderUID string) (*Bundle, error) {
+	folder, err := dashboards.NewFolderService(user.OrgId, user, s.sqlStore).GetFolderByUID(ctx, folderUID)
+	if err != nil {
+		return nil, err
+	}
+
+	dsQuery := &models.GetDataSourcesQuery{OrgId: user.OrgId}
+	if err := s.sqlStore.GetDataSources(ctx, dsQuery); err != nil {
+		return nil, err
+	}
+	t := newTemplater(dsQuery.Result)
+
+	b := &Bundle{
+		Manifest: Manifest{
+			Version:        FormatVersion,
+			GrafanaVersion: s.cfg.BuildVersion,
+			Exported:       time.Now().UTC(),
+			Folder:         Folder{UID: folder.Uid, Title: folder.Title},
+		},
+		Dashboards:      make([]*simplejson.Json, 0),
+		LibraryElements: make([]*LibraryElement, 0),
+		AlertRules:      make([]*AlertRule, 0),
+	}
+
+	elementUIDs := make(map[string]bool)
+	for page := int64(1); ; page++ {
+		query := search.FindPersistedDashboardsQuery{
+			OrgId:        user.OrgId,
+			SignedInUser: user,
+			FolderIds:    []int64{folder.Id},
+			Type:         string(search.DashHitDB),
+			Permission:   models.PERMISSION_VIEW,
+			Limit:        exportSearchPageSize,
+			Page:         page,
+		}
+		if err := s.sqlStore.SearchDashboards(ctx, &query); err != nil {
+			return nil, err
+		}
+
+		for _, hit := range query.Result {
+			dash, err := s.sqlStore.GetDashboard(hit.ID, user.OrgId, "", "")
+			if err != nil {
+				return nil, err
+			}
+			b.Dashboards = append(b.Dashboards, exportDashboard(t, dash, elementUIDs))
+		}
+		if len(query.Result) < exportSearchPageSize {
+			break
+		}
+	}
+
+	uids := make([]string, 0, len(elementUIDs))
+	for uid := range elementUIDs {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	for _, uid := range uids {
+		element, err := s.libraryElementService.GetElement(ctx, user, uid)
+		if err != nil {
+			if errors.Is(err, libraryelements.ErrLibraryElementNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		model, _, err := t.TemplateRaw(element.Model)
+		if err != nil {
+			return nil, err
+		}
+		b.LibraryElements = append(b.LibraryElements, &LibraryElement{
+			UID:         element.UID,
+			Name:        element.Name,
+			Kind:        element.Kind,
+			Type:        element.Type,
+			Description: element.Description,
+			Model:       model,
+		})
+	}
+
+	if s.ruleStore != nil {
+		rulesQuery := &ngmodels.ListNamespaceAlertRulesQuery{OrgID: user.OrgId, NamespaceUID: folder.Uid}
+		if err := s.ruleStore.GetNamespaceAlertRules(rulesQuery); err != nil {
+			return nil, err
+		}
+		for _, rule := range rulesQuery.Result {
+			exported, err := exportAlertRule(t, rule)
+			if err != nil {
+				return nil, err
+			}
+			b.AlertRules = append(b.AlertRules, exported)
+		}
+		sort.Slice(b.AlertRules, func(i, j int) bool { return b.AlertRules[i].UID < b.AlertRules[j].UID })
+	}
+
+	b.Manifest.Inputs = t.Inputs()
+	return b, nil
+}
+
+// exportDashboard returns the model of a dashboard with its data source references replaced with inputs, listed in
+// __inputs so that it can also be imported on its own. The uids of the library panels it uses are added to
+// elementUIDs.
+func exportDashboard(t *templater, dash *models.Dashboard, elementUIDs map[string]bool) *simplejson.Json {
+	data := dash.Data
+	data.Del("id")
+	data.Set("uid", dash.Uid)
+
+	used := t.Template(data.Interface())
+	inputsByName := make(map[string]Input)
+	for _, input := range t.Inputs() {
+		inputsByName[input.Name] = input
+	}
+	inputs := make([]interface{}, 0, len(used))
+	for _, name := range used {
+		input := inputsByName[name]
+		inputs = append(inputs, map[string]interface{}{
+			"name":     input.Name,
+			"label":    input.Label,
+			"type":     input.Type,
+			"pluginId": input.PluginID,
+		})
+	}
+	if len(inputs) > 0 {
+		data.Set("__inputs", inputs)
+	}
+
+	var visit func(panels []interface{})
+	visit = func(panels []interface{}) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			if uid := panel.GetPath("libraryPanel", "uid").MustString(); uid != "" {
+				elementUIDs[uid] = true
+			}
+			visit(panel.Get("panels").MustArray())
+		}
+	}
+	visit(data.Get("panels").MustArray())
+
+	return data
+}
+
+func exportAlertRule(t *templater, rule *ngmodels.AlertRule) (*AlertRule, error) {
+	data := make([]AlertQuery, 0, len(rule.Data))
+	for _, q := range rule.Data {
+		model, _, err := t.TemplateRaw(q.Model)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, AlertQuery{
+			RefID:     q.RefID,
+			QueryType: q.QueryType,
+			RelativeTimeRange: RelativeTimeRange{
+				From: int64(time.Duration(q.RelativeTimeRange.From).Seconds()),
+				To:   int64(time.Duration(q.RelativeTimeRange.To).Seconds()),
+			},
+			DatasourceUID: t.TemplateUID(q.DatasourceUID),
+			Model:         model,
+		})
+	}
+
+	return &AlertRule{
+		UID:             rule.UID,
+		Title:           rule.Title,
+		RuleGroup:       rule.RuleGroup,
+		Condition:       rule.Condition,
+		Data:            data,
+		IntervalSeconds: rule.IntervalSeconds,
+		For:             rule.For.String(),
+		NoDataState:     string(rule.NoDataState),
+		ExecErrState:    string(rule.ExecErrState),
+		Annotations:     rule.Annotations,
+		Labels:          rule.Labels,
+		DashboardUID:    rule.DashboardUID,
+		PanelID:         rule.PanelID,
+	}, nil
+}
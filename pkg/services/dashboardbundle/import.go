@@ -0,0 +1,500 @@
+package dashboardbundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// OverwritePolicy defines what an import does with the content that already exists.
+type OverwritePolicy string
+
+const (
+	// OverwriteFail fails the import when any content already exists.
+	OverwriteFail OverwritePolicy = "fail"
+	// OverwriteSkip keeps the existing content.
+	OverwriteSkip OverwritePolicy = "skip"
+	// OverwriteReplace replaces the existing content.
+	OverwriteReplace OverwritePolicy = "overwrite"
+)
+
+type ItemKind string
+
+const (
+	KindFolder         ItemKind = "folder"
+	KindLibraryElement ItemKind = "library-element"
+	KindDashboard      ItemKind = "dashboard"
+	KindAlertRule      ItemKind = "alert-rule"
+)
+
+type ImportAction string
+
+const (
+	ActionCreate   ImportAction = "create"
+	ActionUpdate   ImportAction = "update"
+	ActionSkip     ImportAction = "skip"
+	ActionConflict ImportAction = "conflict"
+)
+
+type ImportOptions struct {
+	// DatasourceMappings maps the inputs of the bundle to data sources, by uid or name.
+	DatasourceMappings map[string]string `json:"datasourceMappings"`
+	Overwrite          OverwritePolicy   `json:"overwrite"`
+	// DryRun only reports what an import would do.
+	DryRun bool `json:"dryRun"`
+}
+
+// ImportItem is the action taken, or to be taken on a dry run, for an element of a bundle.
+type ImportItem struct {
+	Kind   ItemKind     `json:"kind"`
+	UID    string       `json:"uid"`
+	Title  string       `json:"title"`
+	Action ImportAction `json:"action"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// Datasources are the uids of the data sources the inputs of the bundle are mapped to.
+	Datasources map[string]string `json:"datasources"`
+	Items       []*ImportItem     `json:"items"`
+}
+
+// Import imports a bundle into the folder with the uid of the exported folder, which is created if needed. The import
+// is planned first: it fails without changes if an input cannot be mapped to a data source, or if content exists and
+// the overwrite policy is OverwriteFail. If the import fails while the content is written, the folder, library
+// elements and dashboards it created are deleted again. Content it updated keeps the new version, the previous one
+// remains in the version history of dashboards and library elements.
+func (s *Service) Import(ctx context.Context, user *models.SignedInUser, b *Bundle, opts ImportOptions) (*ImportResult, error) {
+	switch opts.Overwrite {
+	case "":
+		opts.Overwrite = OverwriteFail
+	case OverwriteFail, OverwriteSkip, OverwriteReplace:
+	default:
+		return nil, fmt.Errorf("%w: unknown overwrite policy %q", ErrInvalidImportOptions, opts.Overwrite)
+	}
+
+	dsQuery := &models.GetDataSourcesQuery{OrgId: user.OrgId}
+	if err := s.sqlStore.GetDataSources(ctx, dsQuery); err != nil {
+		return nil, err
+	}
+	resolved, err := resolveInputs(b.Manifest.Inputs, opts.DatasourceMappings, dsQuery.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.plan(ctx, user, b, opts.Overwrite, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: opts.DryRun, Datasources: resolved, Items: p.items}
+	for _, item := range p.items {
+		if item.Action == ActionConflict && !opts.DryRun {
+			return result, ErrImportConflict
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if err := s.execute(ctx, user, b, p, resolved); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// plan holds the action for each element of a bundle, in the order of the bundle, and the existing content it
+// replaces.
+type plan struct {
+	items []*ImportItem
+
+	folder   *models.Folder
+	elements []*ImportItem
+	// existingElements are the versions of the library elements to update
+	existingElements map[string]int64
+	dashboards       []*ImportItem
+	rules            []*ImportItem
+	// upserts are the validated alert rules, in the order of rules
+	upserts []store.UpsertRule
+}
+
+func (p *plan) add(kind ItemKind, uid, title string, exists bool, policy OverwritePolicy) *ImportItem {
+	item := &ImportItem{Kind: kind, UID: uid, Title: title, Action: ActionCreate}
+	if exists {
+		switch policy {
+		case OverwriteReplace:
+			item.Action = ActionUpdate
+		case OverwriteSkip:
+			item.Action, item.Reason = ActionSkip, "already exists"
+		default:
+			item.Action, item.Reason = ActionConflict, "already exists"
+		}
+	}
+	p.items = append(p.items, item)
+	return item
+}
+
+func (s *Service) plan(ctx context.Context, user *models.SignedInUser, b *Bundle, policy OverwritePolicy, resolved map[string]string) (*plan, error) {
+	p := &plan{
+		items:            make([]*ImportItem, 0),
+		existingElements: make(map[string]int64),
+	}
+
+	folderUID := b.Manifest.Folder.UID
+	folder, err := dashboards.NewFolderService(user.OrgId, user, s.sqlStore).GetFolderByUID(ctx, folderUID)
+	switch {
+	case err == nil:
+		// the content of the bundle is imported into the existing folder
+		p.folder = folder
+		p.items = append(p.items, &ImportItem{Kind: KindFolder, UID: folderUID, Title: folder.Title, Action: ActionSkip, Reason: "already exists"})
+	case errors.Is(err, models.ErrFolderNotFound):
+		p.items = append(p.items, &ImportItem{Kind: KindFolder, UID: folderUID, Title: b.Manifest.Folder.Title, Action: ActionCreate})
+	default:
+		return nil, err
+	}
+
+	for _, element := range b.LibraryElements {
+		existing, err := s.libraryElementService.GetElement(ctx, user, element.UID)
+		if err != nil && !errors.Is(err, libraryelements.ErrLibraryElementNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			p.existingElements[element.UID] = existing.Version
+		}
+		p.elements = append(p.elements, p.add(KindLibraryElement, element.UID, element.Name, err == nil, policy))
+	}
+
+	for _, dash := range b.Dashboards {
+		uid := dash.Get("uid").MustString()
+		_, err := s.sqlStore.GetDashboard(0, user.OrgId, uid, "")
+		if err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+			return nil, err
+		}
+		p.dashboards = append(p.dashboards, p.add(KindDashboard, uid, dash.Get("title").MustString(), err == nil, policy))
+	}
+
+	if len(b.AlertRules) == 0 {
+		return p, nil
+	}
+	if s.ruleStore == nil {
+		for _, rule := range b.AlertRules {
+			item := &ImportItem{Kind: KindAlertRule, UID: rule.UID, Title: rule.Title, Action: ActionSkip, Reason: "unified alerting is disabled"}
+			p.items = append(p.items, item)
+			p.rules = append(p.rules, item)
+		}
+		return p, nil
+	}
+
+	// the uids of alert rules are generated, so they are matched by title too
+	var existingRules []*ngmodels.AlertRule
+	byUID := make(map[string]*ngmodels.AlertRule)
+	byTitle := make(map[string]*ngmodels.AlertRule)
+	if p.folder != nil {
+		query := &ngmodels.ListNamespaceAlertRulesQuery{OrgID: user.OrgId, NamespaceUID: folderUID}
+		if err := s.ruleStore.GetNamespaceAlertRules(query); err != nil {
+			return nil, err
+		}
+		existingRules = query.Result
+		for _, rule := range existingRules {
+			byUID[rule.UID] = rule
+			byTitle[rule.Title] = rule
+		}
+	}
+	for _, rule := range b.AlertRules {
+		existing, ok := byUID[rule.UID]
+		if !ok {
+			existing, ok = byTitle[rule.Title]
+		}
+		upsert, err := importAlertRule(rule, user.OrgId, folderUID, existing, resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import alert rule %q: %w", rule.Title, err)
+		}
+		p.rules = append(p.rules, p.add(KindAlertRule, rule.UID, rule.Title, ok, policy))
+		p.upserts = append(p.upserts, upsert)
+	}
+
+	if err := s.validateAlertRules(ctx, user, p, existingRules); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// validateAlertRules validates the alert rules to create or update like the ruler API does: the user must be allowed
+// to save in the folder and to query the data sources of the rules, the condition of a rule must be one of its
+// queries or expressions, its interval a multiple of the scheduler interval and the same for all rules of its group.
+func (s *Service) validateAlertRules(ctx context.Context, user *models.SignedInUser, p *plan, existingRules []*ngmodels.AlertRule) error {
+	if p.folder != nil {
+		g := guardian.New(ctx, p.folder.Id, user.OrgId, user)
+		if canSave, err := g.CanSave(); err != nil || !canSave {
+			if err != nil {
+				return err
+			}
+			return models.ErrFolderAccessDenied
+		}
+	}
+
+	replaced := make(map[string]bool)
+	for i, upsert := range p.upserts {
+		if upsert.Existing != nil && p.rules[i].Action == ActionUpdate {
+			replaced[upsert.Existing.UID] = true
+		}
+	}
+	intervals := make(map[string]int64)
+	for _, rule := range existingRules {
+		if !replaced[rule.UID] {
+			intervals[rule.RuleGroup] = rule.IntervalSeconds
+		}
+	}
+
+	for i, upsert := range p.upserts {
+		if action := p.rules[i].Action; action != ActionCreate && action != ActionUpdate {
+			continue
+		}
+		rule := upsert.New
+		if err := s.ruleStore.ValidateAlertRule(rule); err != nil {
+			return fmt.Errorf("%w: alert rule %q: %s", ErrInvalidBundle, rule.Title, err)
+		}
+		cond := ngmodels.Condition{Condition: rule.Condition, OrgID: user.OrgId, Data: rule.Data}
+		if err := api.ValidateCondition(ctx, cond, user, false, s.dataSourceCache); err != nil {
+			return fmt.Errorf("%w: alert rule %q: %s", ErrInvalidBundle, rule.Title, err)
+		}
+		if err := s.checkQueryAccess(ctx, user, rule.Data); err != nil {
+			return fmt.Errorf("alert rule %q: %w", rule.Title, err)
+		}
+		if interval, ok := intervals[rule.RuleGroup]; ok && interval != rule.IntervalSeconds {
+			return fmt.Errorf("%w: alert rule %q: the rules of group %q must have the same interval", ErrInvalidBundle, rule.Title, rule.RuleGroup)
+		}
+		intervals[rule.RuleGroup] = rule.IntervalSeconds
+	}
+	return nil
+}
+
+// checkQueryAccess checks that the user is allowed to query the data sources of the queries.
+func (s *Service) checkQueryAccess(ctx context.Context, user *models.SignedInUser, data []ngmodels.AlertQuery) error {
+	for _, q := range data {
+		if isExpression, err := q.IsExpression(); err != nil || isExpression {
+			continue
+		}
+		ds, err := s.dataSourceCache.GetDatasourceByUID(ctx, q.DatasourceUID, user, false)
+		if err != nil {
+			return err
+		}
+
+		filter := models.DatasourcesPermissionFilterQuery{User: user, Datasources: []*models.DataSource{ds}}
+		if err := bus.Dispatch(ctx, &filter); err != nil {
+			if !errors.Is(err, bus.ErrHandlerNotFound) {
+				return err
+			}
+		} else if len(filter.Result) == 0 {
+			return models.ErrDataSourceAccessDenied
+		}
+	}
+	return nil
+}
+
+// execute writes the content of a bundle as planned. The content it created is deleted when it fails, the alert
+// rules are written last in a single transaction so they never have to be.
+func (s *Service) execute(ctx context.Context, user *models.SignedInUser, b *Bundle, p *plan, resolved map[string]string) error {
+	created := &createdContent{}
+	if err := s.write(ctx, user, b, p, resolved, created); err != nil {
+		s.rollback(ctx, user, created)
+		return err
+	}
+	return nil
+}
+
+// createdContent is the content created by an import, which is deleted if the import fails.
+type createdContent struct {
+	folder       *models.Folder
+	elementUIDs  []string
+	dashboardIDs []int64
+}
+
+func (s *Service) write(ctx context.Context, user *models.SignedInUser, b *Bundle, p *plan, resolved map[string]string, created *createdContent) error {
+	folder := p.folder
+	if folder == nil {
+		var err error
+		folderService := dashboards.NewFolderService(user.OrgId, user, s.sqlStore)
+		if folder, err = folderService.CreateFolder(ctx, b.Manifest.Folder.Title, b.Manifest.Folder.UID); err != nil {
+			return err
+		}
+		created.folder = folder
+	}
+
+	for i, element := range b.LibraryElements {
+		var err error
+		model := interpolate(element.Model, resolved)
+		switch p.elements[i].Action {
+		case ActionCreate:
+			_, err = s.libraryElementService.CreateElement(ctx, user, libraryelements.CreateLibraryElementCommand{
+				FolderID: folder.Id,
+				Name:     element.Name,
+				Model:    model,
+				Kind:     element.Kind,
+				UID:      element.UID,
+			})
+			if err == nil {
+				created.elementUIDs = append(created.elementUIDs, element.UID)
+			}
+		case ActionUpdate:
+			_, err = s.libraryElementService.PatchElement(ctx, user, libraryelements.PatchLibraryElementCommand{
+				FolderID: folder.Id,
+				Name:     element.Name,
+				Model:    model,
+				Kind:     element.Kind,
+				Version:  p.existingElements[element.UID],
+			}, element.UID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import library element %q: %w", element.Name, err)
+		}
+	}
+
+	inputs := make([]dashboardimport.ImportDashboardInput, 0, len(b.Manifest.Inputs))
+	for _, input := range b.Manifest.Inputs {
+		if uid, ok := resolved[input.Name]; ok {
+			inputs = append(inputs, dashboardimport.ImportDashboardInput{
+				Type:     input.Type,
+				PluginId: input.PluginID,
+				Name:     input.Name,
+				Value:    uid,
+			})
+		}
+	}
+	for i, dash := range b.Dashboards {
+		item := p.dashboards[i]
+		if item.Action != ActionCreate && item.Action != ActionUpdate {
+			continue
+		}
+		imported, err := s.importService.ImportDashboard(ctx, &dashboardimport.ImportDashboardRequest{
+			Dashboard: dash,
+			Inputs:    inputs,
+			FolderId:  folder.Id,
+			Overwrite: item.Action == ActionUpdate,
+			User:      user,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import dashboard %q: %w", item.Title, err)
+		}
+		if item.Action == ActionCreate {
+			created.dashboardIDs = append(created.dashboardIDs, imported.DashboardId)
+		}
+	}
+
+	rules := make([]store.UpsertRule, 0, len(p.upserts))
+	for i, upsert := range p.upserts {
+		if item := p.rules[i]; item.Action == ActionCreate || item.Action == ActionUpdate {
+			rules = append(rules, upsert)
+		}
+	}
+	if len(rules) > 0 {
+		if err := s.ruleStore.UpsertAlertRules(rules); err != nil {
+			return fmt.Errorf("failed to import alert rules: %w", err)
+		}
+	}
+	return nil
+}
+
+// rollback permanently deletes the content created by a failed import, dashboards first as they may use the
+// library elements. Failures are logged, the error of the import is the one returned.
+func (s *Service) rollback(ctx context.Context, user *models.SignedInUser, created *createdContent) {
+	for _, id := range created.dashboardIDs {
+		if err := s.purgeDashboard(ctx, user.OrgId, id); err != nil {
+			s.log.Error("Failed to delete dashboard of failed import", "dashboardId", id, "error", err)
+		}
+	}
+
+	for _, uid := range created.elementUIDs {
+		if err := s.libraryElementService.DeleteElement(ctx, user, uid); err != nil {
+			s.log.Error("Failed to delete library element of failed import", "uid", uid, "error", err)
+		}
+	}
+
+	if created.folder != nil {
+		if err := s.purgeDashboard(ctx, user.OrgId, created.folder.Id); err != nil {
+			s.log.Error("Failed to delete folder of failed import", "uid", created.folder.Uid, "error", err)
+		}
+	}
+}
+
+// purgeDashboard deletes a dashboard or folder without keeping it in the trash.
+func (s *Service) purgeDashboard(ctx context.Context, orgID, id int64) error {
+	if err := s.libraryElementService.DisconnectElementsFromDashboard(ctx, id); err != nil {
+		return err
+	}
+	if err := s.sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: id, OrgId: orgID}); err != nil {
+		return err
+	}
+	return s.sqlStore.PurgeDashboard(ctx, &models.PurgeDashboardCommand{Id: id, OrgId: orgID})
+}
+
+func importAlertRule(rule *AlertRule, orgID int64, namespaceUID string, existing *ngmodels.AlertRule, resolved map[string]string) (store.UpsertRule, error) {
+	forDuration, err := time.ParseDuration(rule.For)
+	if err != nil {
+		return store.UpsertRule{}, fmt.Errorf("%w: invalid for duration %q", ErrInvalidBundle, rule.For)
+	}
+
+	data := make([]ngmodels.AlertQuery, 0, len(rule.Data))
+	for _, q := range rule.Data {
+		datasourceUID := q.DatasourceUID
+		if uid, ok := resolved[inputName(datasourceUID)]; ok {
+			datasourceUID = uid
+		}
+		data = append(data, ngmodels.AlertQuery{
+			RefID:     q.RefID,
+			QueryType: q.QueryType,
+			RelativeTimeRange: ngmodels.RelativeTimeRange{
+				From: ngmodels.Duration(time.Duration(q.RelativeTimeRange.From) * time.Second),
+				To:   ngmodels.Duration(time.Duration(q.RelativeTimeRange.To) * time.Second),
+			},
+			DatasourceUID: datasourceUID,
+			Model:         interpolate(q.Model, resolved),
+		})
+	}
+
+	upsert := store.UpsertRule{
+		Existing: existing,
+		New: ngmodels.AlertRule{
+			OrgID:           orgID,
+			Title:           rule.Title,
+			Condition:       rule.Condition,
+			Data:            data,
+			IntervalSeconds: rule.IntervalSeconds,
+			NamespaceUID:    namespaceUID,
+			DashboardUID:    rule.DashboardUID,
+			PanelID:         rule.PanelID,
+			RuleGroup:       rule.RuleGroup,
+			NoDataState:     ngmodels.NoDataState(rule.NoDataState),
+			ExecErrState:    ngmodels.ExecutionErrorState(rule.ExecErrState),
+			For:             forDuration,
+			Annotations:     rule.Annotations,
+			Labels:          rule.Labels,
+		},
+	}
+	if existing != nil {
+		// the store keeps the rule group of existing rules
+		upsert.New.UID = existing.UID
+		upsert.New.RuleGroup = existing.RuleGroup
+	}
+	return upsert, nil
+}
+
+// inputName returns the name of the input referenced by ${name}.
+func inputName(ref string) string {
+	if len(ref) > 3 && ref[:2] == "${" && ref[len(ref)-1] == '}' {
+		return ref[2 : len(ref)-1]
+	}
+	return ""
+}
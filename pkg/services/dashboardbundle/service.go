@@ -0,0 +1,42 @@
+package dashboardbundle
+
+import (
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, routeRegister routing.RouteRegister,
+	importService dashboardimport.Service, libraryElementService libraryelements.Service, alertNG *ngalert.AlertNG) *Service {
+	s := &Service{
+		cfg:                   cfg,
+		sqlStore:              sqlStore,
+		importService:         importService,
+		libraryElementService: libraryElementService,
+		log:                   log.New("dashboardbundle"),
+	}
+	if alertNG != nil {
+		s.ruleStore = alertNG.RuleStore
+		s.dataSourceCache = alertNG.DataSourceCache
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Service exports folders as bundles and imports them, to move content between instances.
+type Service struct {
+	cfg                   *setting.Cfg
+	sqlStore              *sqlstore.SQLStore
+	importService         dashboardimport.Service
+	libraryElementService libraryelements.Service
+	// ruleStore is nil when unified alerting is disabled
+	ruleStore       store.RuleStore
+	dataSourceCache datasources.CacheService
+	log             log.Logger
+}
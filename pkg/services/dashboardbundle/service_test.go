@@ -0,0 +1,267 @@
+package dashboardbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	sqlStore := sqlstore.InitTestDB(t)
+	staging := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+	prod := &models.SignedInUser{UserId: 1, OrgId: 2, OrgRole: models.ROLE_ADMIN}
+
+	s := &Service{
+		cfg:                   setting.NewCfg(),
+		sqlStore:              sqlStore,
+		importService:         &fakeImportService{sqlStore: sqlStore},
+		libraryElementService: &libraryelements.LibraryElementService{Cfg: setting.NewCfg(), SQLStore: sqlStore},
+		ruleStore:             store.DBstore{SQLStore: sqlStore, BaseInterval: 10 * time.Second, DefaultInterval: time.Minute},
+		dataSourceCache:       datasources.ProvideCacheService(localcache.ProvideService(), sqlStore),
+		log:                   log.New("dashboardbundle"),
+	}
+
+	require.NoError(t, sqlStore.AddDataSource(ctx, &models.AddDataSourceCommand{OrgId: 1, Name: "Prometheus", Type: "prometheus", Uid: "prom-staging", Access: models.DS_ACCESS_PROXY}))
+	require.NoError(t, sqlStore.AddDataSource(ctx, &models.AddDataSourceCommand{OrgId: 2, Name: "Prometheus", Type: "prometheus", Uid: "prom-prod", Access: models.DS_ACCESS_PROXY}))
+	require.NoError(t, sqlStore.AddDataSource(ctx, &models.AddDataSourceCommand{OrgId: 3, Name: "Prometheus", Type: "prometheus", Uid: "prom-dev", Access: models.DS_ACCESS_PROXY}))
+
+	folder, err := dashboards.NewFolderService(1, staging, sqlStore).CreateFolder(ctx, "Infrastructure", "infra")
+	require.NoError(t, err)
+
+	_, err = s.libraryElementService.CreateElement(ctx, staging, libraryelements.CreateLibraryElementCommand{
+		FolderID: folder.Id,
+		Name:     "CPU",
+		Kind:     int64(models.PanelElement),
+		UID:      "cpu",
+		Model:    json.RawMessage(`{"type": "timeseries", "title": "CPU", "datasource": {"type": "prometheus", "uid": "prom-staging"}}`),
+	})
+	require.NoError(t, err)
+
+	dash, err := simplejson.NewJson([]byte(`{
+		"uid": "hosts",
+		"title": "Hosts",
+		"panels": [
+			{"id": 1, "title": "Up", "datasource": "Prometheus", "targets": [{"refId": "A", "expr": "up"}]},
+			{"id": 2, "libraryPanel": {"uid": "cpu", "name": "CPU"}}
+		]
+	}`))
+	require.NoError(t, err)
+	_, err = sqlStore.SaveDashboard(models.SaveDashboardCommand{OrgId: 1, FolderId: folder.Id, Dashboard: dash})
+	require.NoError(t, err)
+
+	err = s.ruleStore.UpsertAlertRules([]store.UpsertRule{{New: ngmodels.AlertRule{
+		OrgID:     1,
+		Title:     "High CPU",
+		Condition: "A",
+		Data: []ngmodels.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     "prom-staging",
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)},
+			Model:             json.RawMessage(`{"expr": "cpu > 0.9"}`),
+		}},
+		IntervalSeconds: 60,
+		NamespaceUID:    "infra",
+		RuleGroup:       "hosts",
+		For:             5 * time.Minute,
+	}}})
+	require.NoError(t, err)
+
+	exported, err := s.Export(ctx, staging, "infra")
+	require.NoError(t, err)
+
+	assert.Equal(t, Folder{UID: "infra", Title: "Infrastructure"}, exported.Manifest.Folder)
+	assert.Equal(t, []Input{{Name: "DS_PROMETHEUS", Label: "Prometheus", Type: "datasource", PluginID: "prometheus", UID: "prom-staging"}}, exported.Manifest.Inputs)
+	require.Len(t, exported.Dashboards, 1)
+	assert.Equal(t, "${DS_PROMETHEUS}", exported.Dashboards[0].Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+	require.Len(t, exported.LibraryElements, 1)
+	assert.Contains(t, string(exported.LibraryElements[0].Model), "${DS_PROMETHEUS}")
+	require.Len(t, exported.AlertRules, 1)
+	assert.Equal(t, "${DS_PROMETHEUS}", exported.AlertRules[0].Data[0].DatasourceUID)
+	assert.Equal(t, int64(600), exported.AlertRules[0].Data[0].RelativeTimeRange.From)
+
+	var buf bytes.Buffer
+	require.NoError(t, exported.Write(&buf))
+	b, err := Read(buf.Bytes())
+	require.NoError(t, err)
+
+	actions := func(result *ImportResult) map[ItemKind]ImportAction {
+		byKind := make(map[ItemKind]ImportAction)
+		for _, item := range result.Items {
+			byKind[item.Kind] = item.Action
+		}
+		return byKind
+	}
+
+	t.Run("should report the planned import on a dry run", func(t *testing.T) {
+		result, err := s.Import(ctx, prod, b, ImportOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"DS_PROMETHEUS": "prom-prod"}, result.Datasources)
+		assert.Equal(t, map[ItemKind]ImportAction{
+			KindFolder:         ActionCreate,
+			KindLibraryElement: ActionCreate,
+			KindDashboard:      ActionCreate,
+			KindAlertRule:      ActionCreate,
+		}, actions(result))
+
+		_, err = sqlStore.GetDashboard(0, 2, "infra", "")
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+	})
+
+	t.Run("should fail when an input cannot be mapped", func(t *testing.T) {
+		_, err := s.Import(ctx, prod, b, ImportOptions{DatasourceMappings: map[string]string{"DS_PROMETHEUS": "missing"}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+
+	t.Run("should reject invalid alert rules", func(t *testing.T) {
+		withRules := func(edit ...func(rule *AlertRule)) *Bundle {
+			invalid := *b
+			invalid.AlertRules = nil
+			for _, fn := range edit {
+				rule := *b.AlertRules[0]
+				fn(&rule)
+				invalid.AlertRules = append(invalid.AlertRules, &rule)
+			}
+			return &invalid
+		}
+
+		for name, invalid := range map[string]*Bundle{
+			"unknown condition":       withRules(func(rule *AlertRule) { rule.Condition = "B" }),
+			"unknown data source":     withRules(func(rule *AlertRule) { rule.Data = []AlertQuery{{RefID: "A", DatasourceUID: "missing"}} }),
+			"interval not a multiple": withRules(func(rule *AlertRule) { rule.IntervalSeconds = 45 }),
+			"intervals of a group differ": withRules(
+				func(rule *AlertRule) {},
+				func(rule *AlertRule) { rule.UID, rule.Title, rule.IntervalSeconds = "low-cpu", "Low CPU", 120 },
+			),
+		} {
+			_, err := s.Import(ctx, prod, invalid, ImportOptions{})
+			require.ErrorIs(t, err, ErrInvalidBundle, name)
+		}
+
+		_, err := sqlStore.GetDashboard(0, 2, "infra", "")
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+	})
+
+	t.Run("should delete the content it created when the import fails", func(t *testing.T) {
+		dev := &models.SignedInUser{UserId: 1, OrgId: 3, OrgRole: models.ROLE_ADMIN}
+		failing := *s
+		failing.ruleStore = &failingRuleStore{RuleStore: s.ruleStore}
+
+		_, err := failing.Import(ctx, dev, b, ImportOptions{})
+		require.Error(t, err)
+
+		_, err = sqlStore.GetDashboard(0, 3, "hosts", "")
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+		_, err = sqlStore.GetDashboard(0, 3, "infra", "")
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+		_, err = s.libraryElementService.GetElement(ctx, dev, "cpu")
+		require.ErrorIs(t, err, libraryelements.ErrLibraryElementNotFound)
+
+		trash := &models.GetDeletedDashboardsQuery{OrgId: 3}
+		require.NoError(t, sqlStore.GetDeletedDashboards(ctx, trash))
+		assert.Empty(t, trash.Result)
+	})
+
+	t.Run("should import the folder with its content", func(t *testing.T) {
+		_, err := s.Import(ctx, prod, b, ImportOptions{})
+		require.NoError(t, err)
+
+		imported, err := sqlStore.GetDashboard(0, 2, "hosts", "")
+		require.NoError(t, err)
+		assert.Equal(t, "prom-prod", imported.Data.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+
+		element, err := s.libraryElementService.GetElement(ctx, prod, "cpu")
+		require.NoError(t, err)
+		assert.Contains(t, string(element.Model), "prom-prod")
+
+		query := &ngmodels.ListNamespaceAlertRulesQuery{OrgID: 2, NamespaceUID: "infra"}
+		require.NoError(t, s.ruleStore.GetNamespaceAlertRules(query))
+		require.Len(t, query.Result, 1)
+		assert.Equal(t, "prom-prod", query.Result[0].Data[0].DatasourceUID)
+		assert.Equal(t, 5*time.Minute, query.Result[0].For)
+	})
+
+	t.Run("should apply the overwrite policy to existing content", func(t *testing.T) {
+		result, err := s.Import(ctx, prod, b, ImportOptions{})
+		require.ErrorIs(t, err, ErrImportConflict)
+		assert.Equal(t, ActionConflict, actions(result)[KindDashboard])
+
+		result, err = s.Import(ctx, prod, b, ImportOptions{Overwrite: OverwriteSkip})
+		require.NoError(t, err)
+		assert.Equal(t, ActionSkip, actions(result)[KindDashboard])
+
+		result, err = s.Import(ctx, prod, b, ImportOptions{Overwrite: OverwriteReplace})
+		require.NoError(t, err)
+		assert.Equal(t, map[ItemKind]ImportAction{
+			KindFolder:         ActionSkip,
+			KindLibraryElement: ActionUpdate,
+			KindDashboard:      ActionUpdate,
+			KindAlertRule:      ActionUpdate,
+		}, actions(result))
+
+		query := &ngmodels.ListNamespaceAlertRulesQuery{OrgID: 2, NamespaceUID: "infra"}
+		require.NoError(t, s.ruleStore.GetNamespaceAlertRules(query))
+		require.Len(t, query.Result, 1)
+		assert.Equal(t, int64(2), query.Result[0].Version)
+	})
+
+	t.Run("should fail when the user cannot save alert rules in the folder", func(t *testing.T) {
+		viewer := &models.SignedInUser{UserId: 2, OrgId: 2, OrgRole: models.ROLE_VIEWER}
+		_, err := s.Import(ctx, viewer, b, ImportOptions{Overwrite: OverwriteReplace})
+		require.ErrorIs(t, err, models.ErrFolderAccessDenied)
+	})
+}
+
+// fakeImportService imports dashboards like the dashboard import service, without library panels.
+type fakeImportService struct {
+	sqlStore *sqlstore.SQLStore
+}
+
+func (s *fakeImportService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
+	data, err := utils.NewDashTemplateEvaluator(req.Dashboard, req.Inputs).Eval()
+	if err != nil {
+		return nil, err
+	}
+
+	dash := models.NewDashboardFromJson(data)
+	dash.FolderId = req.FolderId
+	dash, err = dashboards.NewService(s.sqlStore).ImportDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgId:     req.User.OrgId,
+		Dashboard: dash,
+		Overwrite: req.Overwrite,
+		User:      req.User,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dashboardimport.ImportDashboardResponse{DashboardId: dash.Id, UID: dash.Uid, Title: dash.Title, Imported: true}, nil
+}
+
+// failingRuleStore fails to write alert rules.
+type failingRuleStore struct {
+	store.RuleStore
+}
+
+func (s *failingRuleStore) UpsertAlertRules(rules []store.UpsertRule) error {
+	return errors.New("failed to write alert rules")
+}
@@ -24,6 +24,7 @@ type DashboardService interface {
 	SaveDashboard(ctx context.Context, dto *SaveDashboardDTO, allowUiUpdate bool) (*models.Dashboard, error)
 	ImportDashboard(ctx context.Context, dto *SaveDashboardDTO) (*models.Dashboard, error)
 	DeleteDashboard(ctx context.Context, dashboardId int64, orgId int64) error
+	RestoreDashboard(ctx context.Context, cmd *models.RestoreDashboardCommand, user *models.SignedInUser) error
 	MakeUserAdmin(ctx context.Context, orgID int64, userID, dashboardID int64, setViewAndEditPermissions bool) error
 }
 
@@ -327,6 +328,34 @@ func (dr *dashboardServiceImpl) deleteDashboard(ctx context.Context, dashboardId
 	return bus.Dispatch(ctx, cmd)
 }
 
+// RestoreDashboard restores a dashboard, or a folder and its dashboards, from the trash and extracts the alerts
+// that were removed when they were deleted.
+func (dr *dashboardServiceImpl) RestoreDashboard(ctx context.Context, cmd *models.RestoreDashboardCommand, user *models.SignedInUser) error {
+	cmd.Uid = strings.TrimSpace(cmd.Uid)
+	cmd.Title = strings.TrimSpace(cmd.Title)
+
+	if !util.IsValidShortUID(cmd.Uid) {
+		return models.ErrDashboardInvalidUid
+	} else if util.IsShortUIDTooLong(cmd.Uid) {
+		return models.ErrDashboardUidTooLong
+	}
+
+	if err := dr.dashboardStore.RestoreDashboard(ctx, cmd); err != nil {
+		return err
+	}
+
+	for _, dash := range append([]*models.Dashboard{cmd.Result}, cmd.Children...) {
+		if dash.IsFolder {
+			continue
+		}
+		if err := UpdateAlerting(ctx, dr.dashboardStore, cmd.OrgId, dash, user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (dr *dashboardServiceImpl) ImportDashboard(ctx context.Context, dto *SaveDashboardDTO) (
 	*models.Dashboard, error) {
 	if err := validateDashboardRefreshInterval(dto.Dashboard); err != nil {
@@ -2,34 +2,58 @@ package dashboardsnapshots
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/imguploader"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 type Service struct {
 	Bus            bus.Bus
 	SQLStore       *sqlstore.SQLStore
 	SecretsService secrets.Service
+	// BlobStore stores the encrypted dashboards of new snapshots when a blob storage provider is configured.
+	BlobStore imguploader.BlobStore
+
+	log log.Logger
 }
 
-func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, secretsService secrets.Service) *Service {
+func ProvideService(cfg *setting.Cfg, bus bus.Bus, store *sqlstore.SQLStore, secretsService secrets.Service) (*Service, error) {
 	s := &Service{
 		Bus:            bus,
 		SQLStore:       store,
 		SecretsService: secretsService,
+		log:            log.New("dashboardsnapshots"),
+	}
+
+	if cfg.SnapshotBlobStorageProvider != "" {
+		blobStore, err := imguploader.NewBlobStore(cfg.SnapshotBlobStorageProvider, filepath.Join(cfg.DataPath, "snapshots"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the snapshot blob storage: %w", err)
+		}
+		s.BlobStore = blobStore
 	}
 
 	s.Bus.AddHandler(s.CreateDashboardSnapshot)
 	s.Bus.AddHandler(s.GetDashboardSnapshot)
+	s.Bus.AddHandler(s.LoadDashboardSnapshot)
 	s.Bus.AddHandler(s.DeleteDashboardSnapshot)
 	s.Bus.AddHandler(s.SearchDashboardSnapshots)
 	s.Bus.AddHandler(s.DeleteExpiredSnapshots)
+	s.Bus.AddHandler(s.UpdateDashboardSnapshotVisibility)
+	s.Bus.AddHandler(s.RecordDashboardSnapshotView)
+	s.Bus.AddHandler(s.GetDashboardSnapshotViews)
 
-	return s
+	return s, nil
 }
 
 func (s *Service) CreateDashboardSnapshot(ctx context.Context, cmd *models.CreateDashboardSnapshotCommand) error {
@@ -43,9 +67,22 @@ func (s *Service) CreateDashboardSnapshot(ctx context.Context, cmd *models.Creat
 		return err
 	}
 
-	cmd.DashboardEncrypted = encryptedDashboard
+	if s.BlobStore == nil || cmd.External {
+		cmd.DashboardEncrypted = encryptedDashboard
+		return s.SQLStore.CreateDashboardSnapshot(ctx, cmd)
+	}
 
-	return s.SQLStore.CreateDashboardSnapshot(ctx, cmd)
+	// the key of the snapshot grants access to it, the blob key is generated separately so it is not leaked by the storage
+	cmd.BlobKey = "snapshot-" + util.GenerateShortUID()
+	if err := s.BlobStore.Put(ctx, cmd.BlobKey, encryptedDashboard); err != nil {
+		return fmt.Errorf("failed to store snapshot dashboard: %w", err)
+	}
+
+	if err := s.SQLStore.CreateDashboardSnapshot(ctx, cmd); err != nil {
+		s.deleteBlob(ctx, cmd.BlobKey)
+		return err
+	}
+	return nil
 }
 
 func (s *Service) GetDashboardSnapshot(ctx context.Context, query *models.GetDashboardSnapshotQuery) error {
@@ -54,8 +91,34 @@ func (s *Service) GetDashboardSnapshot(ctx context.Context, query *models.GetDas
 		return err
 	}
 
-	if query.Result.DashboardEncrypted != nil {
-		decryptedDashboard, err := s.SecretsService.Decrypt(ctx, query.Result.DashboardEncrypted)
+	if query.WithoutDashboard {
+		return nil
+	}
+
+	return s.loadDashboard(ctx, query.Result)
+}
+
+func (s *Service) LoadDashboardSnapshot(ctx context.Context, query *models.LoadDashboardSnapshotQuery) error {
+	return s.loadDashboard(ctx, query.Snapshot)
+}
+
+// loadDashboard decrypts the dashboard of a snapshot, reading it from the blob storage if it is stored there.
+func (s *Service) loadDashboard(ctx context.Context, snapshot *models.DashboardSnapshot) error {
+	encryptedDashboard := snapshot.DashboardEncrypted
+	if snapshot.BlobKey != "" {
+		if s.BlobStore == nil {
+			return fmt.Errorf("snapshot %d is stored in the blob storage, but no blob storage provider is configured", snapshot.Id)
+		}
+
+		var err error
+		encryptedDashboard, err = s.BlobStore.Get(ctx, snapshot.BlobKey)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot dashboard: %w", err)
+		}
+	}
+
+	if encryptedDashboard != nil {
+		decryptedDashboard, err := s.SecretsService.Decrypt(ctx, encryptedDashboard)
 		if err != nil {
 			return err
 		}
@@ -65,20 +128,76 @@ func (s *Service) GetDashboardSnapshot(ctx context.Context, query *models.GetDas
 			return err
 		}
 
-		query.Result.Dashboard = dashboard
+		snapshot.Dashboard = dashboard
 	}
 
-	return err
+	return nil
 }
 
 func (s *Service) DeleteDashboardSnapshot(ctx context.Context, cmd *models.DeleteDashboardSnapshotCommand) error {
-	return s.SQLStore.DeleteDashboardSnapshot(ctx, cmd)
+	query := models.GetDashboardSnapshotQuery{DeleteKey: cmd.DeleteKey}
+	if err := s.SQLStore.GetDashboardSnapshot(&query); err != nil {
+		return err
+	}
+
+	if err := s.SQLStore.DeleteDashboardSnapshot(ctx, cmd); err != nil {
+		return err
+	}
+
+	if query.Result.BlobKey != "" {
+		s.deleteBlob(ctx, query.Result.BlobKey)
+	}
+	return nil
 }
 
 func (s *Service) SearchDashboardSnapshots(_ context.Context, query *models.GetDashboardSnapshotsQuery) error {
 	return s.SQLStore.SearchDashboardSnapshots(query)
 }
 
+// DeleteExpiredSnapshots deletes the expired snapshots along with their dashboards in the blob storage. The blobs and
+// the rows are selected with the same cutoff, so that no blob of a deleted snapshot is left behind.
 func (s *Service) DeleteExpiredSnapshots(ctx context.Context, cmd *models.DeleteExpiredSnapshotsCommand) error {
-	return s.SQLStore.DeleteExpiredSnapshots(ctx, cmd)
+	if cmd.Before.IsZero() {
+		cmd.Before = time.Now()
+	}
+
+	var blobKeys []string
+	if setting.SnapShotRemoveExpired {
+		var err error
+		if blobKeys, err = s.SQLStore.GetExpiredDashboardSnapshotBlobKeys(ctx, cmd.Before); err != nil {
+			return err
+		}
+	}
+
+	if err := s.SQLStore.DeleteExpiredSnapshots(ctx, cmd); err != nil {
+		return err
+	}
+
+	for _, key := range blobKeys {
+		s.deleteBlob(ctx, key)
+	}
+	return nil
+}
+
+func (s *Service) UpdateDashboardSnapshotVisibility(ctx context.Context, cmd *models.UpdateDashboardSnapshotVisibilityCommand) error {
+	return s.SQLStore.UpdateDashboardSnapshotVisibility(ctx, cmd)
+}
+
+func (s *Service) RecordDashboardSnapshotView(ctx context.Context, cmd *models.RecordDashboardSnapshotViewCommand) error {
+	return s.SQLStore.RecordDashboardSnapshotView(ctx, cmd)
+}
+
+func (s *Service) GetDashboardSnapshotViews(ctx context.Context, query *models.GetDashboardSnapshotViewsQuery) error {
+	return s.SQLStore.GetDashboardSnapshotViews(ctx, query)
+}
+
+// deleteBlob deletes the dashboard of a snapshot from the blob storage, failures only leave an orphaned blob behind.
+func (s *Service) deleteBlob(ctx context.Context, key string) {
+	if s.BlobStore == nil {
+		s.log.Warn("Cannot delete snapshot dashboard, no blob storage provider is configured", "key", key)
+		return
+	}
+	if err := s.BlobStore.Delete(ctx, key); err != nil {
+		s.log.Warn("Failed to delete snapshot dashboard from the blob storage", "key", key, "err", err)
+	}
 }
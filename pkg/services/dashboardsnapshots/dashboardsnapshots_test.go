@@ -2,11 +2,16 @@ package dashboardsnapshots
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/secrets/database"
 
+	"github.com/grafana/grafana/pkg/components/imguploader"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -70,3 +75,67 @@ func TestDashboardSnapshotsService(t *testing.T) {
 		require.Equal(t, rawDashboard, decrypted)
 	})
 }
+
+func TestDashboardSnapshotsServiceWithBlobStore(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	blobDir := t.TempDir()
+
+	s := &Service{
+		SQLStore:       sqlStore,
+		SecretsService: secretsService,
+		BlobStore:      imguploader.NewLocalBlobStore(blobDir),
+		log:            log.New("dashboardsnapshots.test"),
+	}
+
+	rawDashboard := []byte(`{"id":123}`)
+	dashboard, err := simplejson.NewJson(rawDashboard)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	cmd := models.CreateDashboardSnapshotCommand{
+		Key:       "blob-key",
+		DeleteKey: "blob-delete-key",
+		Dashboard: dashboard,
+	}
+	require.NoError(t, s.CreateDashboardSnapshot(ctx, &cmd))
+	require.NotEmpty(t, cmd.Result.BlobKey)
+	require.Nil(t, cmd.Result.DashboardEncrypted)
+
+	t.Run("get dashboard snapshot should load the dashboard from the blob storage", func(t *testing.T) {
+		query := models.GetDashboardSnapshotQuery{Key: "blob-key", WithoutDashboard: true}
+		require.NoError(t, s.GetDashboardSnapshot(ctx, &query))
+
+		require.NoError(t, s.LoadDashboardSnapshot(ctx, &models.LoadDashboardSnapshotQuery{Snapshot: query.Result}))
+		decrypted, err := query.Result.Dashboard.Encode()
+		require.NoError(t, err)
+		require.Equal(t, rawDashboard, decrypted)
+	})
+
+	t.Run("delete dashboard snapshot should delete the blob", func(t *testing.T) {
+		require.NoError(t, s.DeleteDashboardSnapshot(ctx, &models.DeleteDashboardSnapshotCommand{DeleteKey: "blob-delete-key"}))
+
+		_, err := os.Stat(filepath.Join(blobDir, cmd.Result.BlobKey))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("delete expired snapshots should delete the blobs of the deleted snapshots", func(t *testing.T) {
+		setting.SnapShotRemoveExpired = true
+		t.Cleanup(func() { setting.SnapShotRemoveExpired = false })
+
+		expired := models.CreateDashboardSnapshotCommand{
+			Key:       "expired-key",
+			DeleteKey: "expired-delete-key",
+			Expires:   60,
+			Dashboard: dashboard,
+		}
+		require.NoError(t, s.CreateDashboardSnapshot(ctx, &expired))
+
+		deleteCmd := models.DeleteExpiredSnapshotsCommand{Before: time.Now().Add(time.Hour)}
+		require.NoError(t, s.DeleteExpiredSnapshots(ctx, &deleteCmd))
+		require.Equal(t, int64(1), deleteCmd.DeletedRows)
+
+		_, err := os.Stat(filepath.Join(blobDir, expired.Result.BlobKey))
+		require.True(t, os.IsNotExist(err))
+	})
+}
@@ -51,7 +51,7 @@ type cachedDecryptedJSON struct {
 	json    map[string]string
 }
 
-func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, secretsService secrets.Service, ac accesscontrol.AccessControl) *Service {
+func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, secretsService secrets.Service, ac accesscontrol.AccessControl, provider *ServiceProvider) *Service {
 	s := &Service{
 		Bus:            bus,
 		SQLStore:       store,
@@ -74,9 +74,37 @@ func ProvideService(bus bus.Bus, store *sqlstore.SQLStore, secretsService secret
 
 	ac.RegisterAttributeScopeResolver(NewNameScopeResolver(store))
 
+	if provider != nil {
+		provider.set(s)
+	}
+
 	return s
 }
 
+// ServiceProvider gives access to the data sources service to the services it depends on, such as the core
+// data source plugins, which are created before it.
+type ServiceProvider struct {
+	mu      sync.RWMutex
+	service *Service
+}
+
+func ProvideServiceProvider() *ServiceProvider {
+	return &ServiceProvider{}
+}
+
+// Get returns the data sources service, or nil when it has not been created yet.
+func (p *ServiceProvider) Get() *Service {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.service
+}
+
+func (p *ServiceProvider) set(s *Service) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.service = s
+}
+
 type DataSourceRetriever interface {
 	GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error
 }
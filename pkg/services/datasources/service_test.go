@@ -36,7 +36,7 @@ func TestService(t *testing.T) {
 	})
 
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
-	s := ProvideService(bus.New(), sqlStore, secretsService, &acmock.Mock{})
+	s := ProvideService(bus.New(), sqlStore, secretsService, &acmock.Mock{}, nil)
 
 	var ds *models.DataSource
 
@@ -151,7 +151,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		}
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		rt1, err := dsService.GetHTTPTransport(&ds, provider)
 		require.NoError(t, err)
@@ -184,7 +184,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("tlsAuthWithCACert", true)
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		tlsCaCert, err := secretsService.Encrypt(context.Background(), []byte(caCert), secrets.WithoutScope())
 		require.NoError(t, err)
@@ -234,7 +234,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("tlsAuth", true)
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		tlsClientCert, err := secretsService.Encrypt(context.Background(), []byte(clientCert), secrets.WithoutScope())
 		require.NoError(t, err)
@@ -277,7 +277,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("serverName", "server-name")
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		tlsCaCert, err := secretsService.Encrypt(context.Background(), []byte(caCert), secrets.WithoutScope())
 		require.NoError(t, err)
@@ -314,7 +314,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		json.Set("tlsSkipVerify", true)
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		ds := models.DataSource{
 			Id:       1,
@@ -345,7 +345,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		})
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		encryptedData, err := secretsService.Encrypt(context.Background(), []byte(`Bearer xf5yhfkpsnmgo`), secrets.WithoutScope())
 		require.NoError(t, err)
@@ -404,7 +404,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		})
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		ds := models.DataSource{
 			Id:       1,
@@ -437,7 +437,7 @@ func TestService_GetHttpTransport(t *testing.T) {
 		require.NoError(t, err)
 
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		ds := models.DataSource{
 			Type:     models.DS_ES,
@@ -471,7 +471,7 @@ func TestService_getTimeout(t *testing.T) {
 	}
 
 	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-	dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+	dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 	for _, tc := range testCases {
 		ds := &models.DataSource{
@@ -484,7 +484,7 @@ func TestService_getTimeout(t *testing.T) {
 func TestService_DecryptedValue(t *testing.T) {
 	t.Run("When datasource hasn't been updated, encrypted JSON should be fetched from cache", func(t *testing.T) {
 		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		encryptedJsonData, err := secretsService.EncryptJsonData(
 			context.Background(),
@@ -538,7 +538,7 @@ func TestService_DecryptedValue(t *testing.T) {
 			SecureJsonData: encryptedJsonData,
 		}
 
-		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+		dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 		// Populate cache
 		password, ok := dsService.DecryptedValue(&ds, "password")
@@ -574,7 +574,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			t.Cleanup(func() { ds.JsonData = emptyJsonData; ds.SecureJsonData = emptySecureJsonData })
 
 			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -592,7 +592,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
@@ -611,7 +611,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 			_, err := dsService.httpClientOptions(&ds)
 			assert.Error(t, err)
@@ -625,7 +625,7 @@ func TestService_HTTPClientOptions(t *testing.T) {
 			})
 
 			secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
-			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{})
+			dsService := ProvideService(bus.New(), nil, secretsService, &acmock.Mock{}, nil)
 
 			opts, err := dsService.httpClientOptions(&ds)
 			require.NoError(t, err)
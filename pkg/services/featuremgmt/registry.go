@@ -113,5 +113,10 @@ var (
 			State:           FeatureStateAlpha,
 			RequiresDevMode: true,
 		},
+		{
+			Name:        "scim",
+			Description: "SCIM 2.0 provisioning API for users and teams",
+			State:       FeatureStateAlpha,
+		},
 	}
 )
@@ -82,4 +82,8 @@ const (
 	// FlagValidatedQueries
 	// only execute the query saved in a panel
 	FlagValidatedQueries = "validatedQueries"
+
+	// FlagScim
+	// SCIM 2.0 provisioning API for users and teams
+	FlagScim = "scim"
 )
@@ -32,6 +32,10 @@ type DashboardGuardian interface {
 	// permission.
 	GetACLWithoutDuplicates() ([]*models.DashboardAclInfoDTO, error)
 	GetHiddenACL(*setting.Cfg) ([]*models.DashboardAcl, error)
+
+	// GetMatchingACL returns the ACL items granting at least the permission
+	// to the user, directly, through its organization role or one of its teams.
+	GetMatchingACL(permission models.PermissionType) ([]*models.DashboardAclInfoDTO, error)
 }
 
 type dashboardGuardianImpl struct {
@@ -248,6 +252,42 @@ func (g *dashboardGuardianImpl) GetACLWithoutDuplicates() ([]*models.DashboardAc
 	return result, nil
 }
 
+func (g *dashboardGuardianImpl) GetMatchingACL(permission models.PermissionType) ([]*models.DashboardAclInfoDTO, error) {
+	acl, err := g.GetAcl()
+	if err != nil {
+		return nil, err
+	}
+
+	userTeams := map[int64]bool{}
+	for _, p := range acl {
+		if p.TeamId > 0 {
+			teams, err := g.getTeams(g.ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, team := range teams {
+				userTeams[team.Id] = true
+			}
+			break
+		}
+	}
+
+	result := []*models.DashboardAclInfoDTO{}
+	for _, p := range acl {
+		if p.Permission < permission {
+			continue
+		}
+
+		userMatch := !g.user.IsAnonymous && p.UserId > 0 && p.UserId == g.user.UserId
+		roleMatch := p.Role != nil && *p.Role == g.user.OrgRole
+		if userMatch || roleMatch || userTeams[p.TeamId] {
+			result = append(result, p)
+		}
+	}
+
+	return result, nil
+}
+
 func (g *dashboardGuardianImpl) getTeams(ctx context.Context) ([]*models.TeamDTO, error) {
 	if g.teams != nil {
 		return g.teams, nil
@@ -307,6 +347,7 @@ type FakeDashboardGuardian struct {
 	CheckPermissionBeforeUpdateError error
 	GetAclValue                      []*models.DashboardAclInfoDTO
 	GetHiddenAclValue                []*models.DashboardAcl
+	GetMatchingAclValue              []*models.DashboardAclInfoDTO
 }
 
 func (g *FakeDashboardGuardian) CanSave() (bool, error) {
@@ -345,6 +386,10 @@ func (g *FakeDashboardGuardian) GetHiddenACL(cfg *setting.Cfg) ([]*models.Dashbo
 	return g.GetHiddenAclValue, nil
 }
 
+func (g *FakeDashboardGuardian) GetMatchingACL(permission models.PermissionType) ([]*models.DashboardAclInfoDTO, error) {
+	return g.GetMatchingAclValue, nil
+}
+
 // nolint:unused
 func MockDashboardGuardian(mock *FakeDashboardGuardian) {
 	New = func(_ context.Context, dashId int64, orgId int64, user *models.SignedInUser) DashboardGuardian {
@@ -769,3 +769,44 @@ func TestGuardianGetAclWithoutDuplicates(t *testing.T) {
 		})
 	})
 }
+
+func TestGuardianGetMatchingACL(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	bus.AddHandler("test", func(ctx context.Context, query *models.GetDashboardAclInfoListQuery) error {
+		query.Result = []*models.DashboardAclInfoDTO{
+			{Inherited: true, Role: &viewerRole, Permission: models.PERMISSION_VIEW},
+			{Inherited: true, TeamId: teamID, Permission: models.PERMISSION_EDIT},
+			{Inherited: false, TeamId: otherTeamID, Permission: models.PERMISSION_ADMIN},
+			{Inherited: false, UserId: userID, Permission: models.PERMISSION_ADMIN},
+			{Inherited: false, UserId: otherUserID, Permission: models.PERMISSION_ADMIN},
+			{Inherited: false, Role: &editorRole, Permission: models.PERMISSION_EDIT},
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(ctx context.Context, query *models.GetTeamsByUserQuery) error {
+		query.Result = []*models.TeamDTO{{Id: teamID}}
+		return nil
+	})
+
+	user := &models.SignedInUser{OrgId: orgID, UserId: userID, OrgRole: models.ROLE_VIEWER}
+	g := New(context.Background(), dashboardID, orgID, user)
+
+	acl, err := g.GetMatchingACL(models.PERMISSION_VIEW)
+	require.NoError(t, err)
+	require.Equal(t, []*models.DashboardAclInfoDTO{
+		{Inherited: true, Role: &viewerRole, Permission: models.PERMISSION_VIEW},
+		{Inherited: true, TeamId: teamID, Permission: models.PERMISSION_EDIT},
+		{Inherited: false, UserId: userID, Permission: models.PERMISSION_ADMIN},
+	}, acl)
+
+	acl, err = g.GetMatchingACL(models.PERMISSION_EDIT)
+	require.NoError(t, err)
+	require.Len(t, acl, 2)
+
+	acl, err = g.GetMatchingACL(models.PERMISSION_ADMIN)
+	require.NoError(t, err)
+	require.Equal(t, []*models.DashboardAclInfoDTO{
+		{Inherited: false, UserId: userID, Permission: models.PERMISSION_ADMIN},
+	}, acl)
+}
@@ -3,9 +3,11 @@ package libraryelements
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/web"
@@ -18,6 +20,11 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 		entities.Get("/", middleware.ReqSignedIn, routing.Wrap(l.getAllHandler))
 		entities.Get("/:uid", middleware.ReqSignedIn, routing.Wrap(l.getHandler))
 		entities.Get("/:uid/connections/", middleware.ReqSignedIn, routing.Wrap(l.getConnectionsHandler))
+		entities.Get("/:uid/connections/outdated", middleware.ReqSignedIn, routing.Wrap(l.getOutdatedConnectionsHandler))
+		entities.Get("/:uid/versions", middleware.ReqSignedIn, routing.Wrap(l.getVersionsHandler))
+		entities.Get("/:uid/versions/:version", middleware.ReqSignedIn, routing.Wrap(l.getVersionHandler))
+		entities.Post("/:uid/versions/:version/restore", middleware.ReqSignedIn, routing.Wrap(l.restoreVersionHandler))
+		entities.Get("/:uid/diff", middleware.ReqSignedIn, routing.Wrap(l.diffHandler))
 		entities.Get("/name/:name", middleware.ReqSignedIn, routing.Wrap(l.getByNameHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(l.patchHandler))
 	})
@@ -83,7 +90,7 @@ func (l *LibraryElementService) getAllHandler(c *models.ReqContext) response.Res
 
 // patchHandler handles PATCH /api/library-elements/:uid
 func (l *LibraryElementService) patchHandler(c *models.ReqContext) response.Response {
-	cmd := patchLibraryElementCommand{}
+	cmd := PatchLibraryElementCommand{}
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
@@ -106,6 +113,76 @@ func (l *LibraryElementService) getConnectionsHandler(c *models.ReqContext) resp
 	return response.JSON(200, LibraryElementConnectionsResponse{Result: connections})
 }
 
+// getOutdatedConnectionsHandler handles GET /api/library-elements/:uid/connections/outdated.
+func (l *LibraryElementService) getOutdatedConnectionsHandler(c *models.ReqContext) response.Response {
+	connections, err := l.getOutdatedConnections(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get outdated connections")
+	}
+
+	return response.JSON(200, LibraryElementOutdatedConnectionsResponse{Result: connections})
+}
+
+// getVersionsHandler handles GET /api/library-elements/:uid/versions.
+func (l *LibraryElementService) getVersionsHandler(c *models.ReqContext) response.Response {
+	versions, err := l.getLibraryElementVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(200, LibraryElementVersionsResponse{Result: versions})
+}
+
+// getVersionHandler handles GET /api/library-elements/:uid/versions/:version.
+func (l *LibraryElementService) getVersionHandler(c *models.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	element, err := l.getLibraryElementVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element version")
+	}
+
+	return response.JSON(200, LibraryElementVersionResponse{Result: element})
+}
+
+// restoreVersionHandler handles POST /api/library-elements/:uid/versions/:version/restore.
+func (l *LibraryElementService) restoreVersionHandler(c *models.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	element, err := l.restoreLibraryElementVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to restore library element version")
+	}
+
+	return response.JSON(200, LibraryElementResponse{Result: element})
+}
+
+// diffHandler handles GET /api/library-elements/:uid/diff.
+func (l *LibraryElementService) diffHandler(c *models.ReqContext) response.Response {
+	diffType := dashdiffs.ParseDiffType(c.Query("diffType"))
+	if diffType == dashdiffs.DiffSemantic {
+		return response.Error(http.StatusBadRequest, "semantic diffs are only supported for dashboards", nil)
+	}
+
+	result, err := l.diffLibraryElementVersions(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"],
+		c.QueryInt64("base"), c.QueryInt64("new"), diffType)
+	if err != nil {
+		return toLibraryElementError(err, "Unable to compute diff")
+	}
+
+	if diffType == dashdiffs.DiffDelta {
+		return response.Respond(200, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+
+	return response.Respond(200, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
 // getByNameHandler handles GET /api/library-elements/name/:name/.
 func (l *LibraryElementService) getByNameHandler(c *models.ReqContext) response.Response {
 	elements, err := l.getLibraryElementsByName(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":name"])
@@ -144,5 +221,11 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, errLibraryElementUIDTooLong) {
 		return response.Error(400, errLibraryElementUIDTooLong.Error(), err)
 	}
+	if errors.Is(err, errLibraryElementVersionNotFound) {
+		return response.Error(404, errLibraryElementVersionNotFound.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementVersionsIdentical) {
+		return response.Error(400, errLibraryElementVersionsIdentical.Error(), err)
+	}
 	return response.Error(500, message, err)
 }
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -134,7 +137,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return insertLibraryElementVersion(session, element, "")
 	})
 
 	dto := LibraryElementDTO{
@@ -189,6 +192,9 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 			return errLibraryElementHasConnections
 		}
 
+		if _, err := session.Exec("DELETE FROM library_element_version WHERE element_id=?", element.ID); err != nil {
+			return err
+		}
 		result, err := session.Exec("DELETE FROM library_element WHERE id=?", element.ID)
 		if err != nil {
 			return err
@@ -436,7 +442,7 @@ func (l *LibraryElementService) handleFolderIDPatches(ctx context.Context, eleme
 }
 
 // patchLibraryElement updates a Library Element.
-func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInUser *models.SignedInUser, cmd patchLibraryElementCommand, uid string) (LibraryElementDTO, error) {
+func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInUser *models.SignedInUser, cmd PatchLibraryElementCommand, uid string) (LibraryElementDTO, error) {
 	var dto LibraryElementDTO
 	if err := l.requireSupportedElementKind(cmd.Kind); err != nil {
 		return LibraryElementDTO{}, err
@@ -502,6 +508,9 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return ErrLibraryElementNotFound
 		}
+		if err := insertLibraryElementVersion(session, libraryElement, cmd.Message); err != nil {
+			return err
+		}
 
 		dto = LibraryElementDTO{
 			ID:          libraryElement.ID,
@@ -561,11 +570,12 @@ func (l *LibraryElementService) getConnections(c context.Context, signedInUser *
 
 		for _, connection := range libraryElementConnections {
 			connections = append(connections, LibraryElementConnectionDTO{
-				ID:           connection.ID,
-				Kind:         connection.Kind,
-				ElementID:    connection.ElementID,
-				ConnectionID: connection.ConnectionID,
-				Created:      connection.Created,
+				ID:            connection.ID,
+				Kind:          connection.Kind,
+				ElementID:     connection.ElementID,
+				ConnectionID:  connection.ConnectionID,
+				PinnedVersion: connection.PinnedVersion,
+				Created:       connection.Created,
 				CreatedBy: LibraryElementDTOMetaUser{
 					ID:        connection.CreatedBy,
 					Name:      connection.CreatedByName,
@@ -580,12 +590,13 @@ func (l *LibraryElementService) getConnections(c context.Context, signedInUser *
 	return connections, err
 }
 
-//getElementsForDashboardID gets all elements for a specific dashboard
+// getElementsForDashboardID gets all elements for a specific dashboard
 func (l *LibraryElementService) getElementsForDashboardID(c context.Context, dashboardID int64) (map[string]LibraryElementDTO, error) {
 	libraryElementMap := make(map[string]LibraryElementDTO)
 	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
 		var libraryElements []LibraryElementWithMeta
 		sql := selectLibraryElementDTOWithMeta +
+			", lce.pinned_version" +
 			", coalesce(dashboard.title, 'General') AS folder_name" +
 			", coalesce(dashboard.uid, '') AS folder_uid" +
 			getFromLibraryElementDTOWithMeta(l.SQLStore.Dialect) +
@@ -598,6 +609,17 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 		}
 
 		for _, element := range libraryElements {
+			pinned, err := getPinnedLibraryElementVersion(session, element)
+			if err != nil {
+				return err
+			}
+			if pinned != nil {
+				element.Name = pinned.Name
+				element.Type = pinned.Type
+				element.Description = pinned.Description
+				element.Model = pinned.Model
+			}
+
 			libraryElementMap[element.UID] = LibraryElementDTO{
 				ID:          element.ID,
 				OrgID:       element.OrgID,
@@ -627,6 +649,11 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 					},
 				},
 			}
+			if pinned != nil {
+				dto := libraryElementMap[element.UID]
+				dto.PinnedVersion = pinned.Version
+				libraryElementMap[element.UID] = dto
+			}
 		}
 
 		return nil
@@ -635,8 +662,39 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 	return libraryElementMap, err
 }
 
+// getPinnedLibraryElementVersion returns the version an element is pinned to by a connection, nil if the connection
+// follows the latest version or the pinned version does not exist.
+func getPinnedLibraryElementVersion(session *sqlstore.DBSession, element LibraryElementWithMeta) (*LibraryElementVersion, error) {
+	if element.PinnedVersion <= 0 || element.PinnedVersion >= element.Version {
+		return nil, nil
+	}
+
+	var version LibraryElementVersion
+	exists, err := session.Where("element_id=? AND version=?", element.ID, element.PinnedVersion).Get(&version)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return &version, nil
+}
+
 // connectElementsToDashboardID adds connections for all elements Library Elements in a Dashboard.
 func (l *LibraryElementService) connectElementsToDashboardID(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error {
+	pinnedVersions := make(map[string]int64, len(elementUIDs))
+	for _, elementUID := range elementUIDs {
+		pinnedVersions[elementUID] = 0
+	}
+	return l.connectElementVersionsToDashboardID(c, signedInUser, pinnedVersions, dashboardID)
+}
+
+// connectElementVersionsToDashboardID adds connections for all Library Elements in a Dashboard, pinned to the
+// version of the element they map to, or following the latest version if it is 0.
+func (l *LibraryElementService) connectElementVersionsToDashboardID(c context.Context, signedInUser *models.SignedInUser, pinnedVersions map[string]int64, dashboardID int64) error {
+	elementUIDs := make([]string, 0, len(pinnedVersions))
+	for elementUID := range pinnedVersions {
+		elementUIDs = append(elementUIDs, elementUID)
+	}
+	sort.Strings(elementUIDs)
+
 	err := l.SQLStore.WithTransactionalDbSession(c, func(session *sqlstore.DBSession) error {
 		_, err := session.Exec("DELETE FROM "+models.LibraryElementConnectionTableName+" WHERE kind=1 AND connection_id=?", dashboardID)
 		if err != nil {
@@ -652,11 +710,12 @@ func (l *LibraryElementService) connectElementsToDashboardID(c context.Context,
 			}
 
 			connection := libraryElementConnection{
-				ElementID:    element.ID,
-				Kind:         1,
-				ConnectionID: dashboardID,
-				Created:      time.Now(),
-				CreatedBy:    signedInUser.UserId,
+				ElementID:     element.ID,
+				Kind:          1,
+				ConnectionID:  dashboardID,
+				PinnedVersion: pinnedVersions[elementUID],
+				Created:       time.Now(),
+				CreatedBy:     signedInUser.UserId,
 			}
 			if _, err := session.Insert(&connection); err != nil {
 				if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
@@ -732,6 +791,9 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			if _, err := session.Exec("DELETE FROM library_element_version WHERE element_id=?", elementID.ID); err != nil {
+				return err
+			}
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.OrgId); err != nil {
 			return err
@@ -740,3 +802,175 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 		return nil
 	})
 }
+
+func insertLibraryElementVersion(session *sqlstore.DBSession, element LibraryElement, message string) error {
+	version := LibraryElementVersion{
+		ElementID:   element.ID,
+		Version:     element.Version,
+		Name:        element.Name,
+		Type:        element.Type,
+		Description: element.Description,
+		Model:       element.Model,
+		Message:     message,
+		Created:     element.Updated,
+		CreatedBy:   element.UpdatedBy,
+	}
+	_, err := session.Insert(&version)
+	return err
+}
+
+func toLibraryElementVersionDTO(version libraryElementVersionWithMeta) LibraryElementVersionDTO {
+	return LibraryElementVersionDTO{
+		Version:     version.Version,
+		Name:        version.Name,
+		Type:        version.Type,
+		Description: version.Description,
+		Model:       version.Model,
+		Message:     version.Message,
+		Created:     version.Created,
+		CreatedBy: LibraryElementDTOMetaUser{
+			ID:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarURL: dtos.GetGravatarUrl(version.CreatedByEmail),
+		},
+	}
+}
+
+// getLibraryElementVersions gets the version history of a Library Element, most recent first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser *models.SignedInUser, uid string) ([]LibraryElementVersionDTO, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]libraryElementVersionWithMeta, 0)
+	err = l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		sql := "SELECT lev.id, lev.element_id, lev.version, lev.name, lev.type, lev.description, lev.message, lev.created, lev.created_by" +
+			", u1.login AS created_by_name, u1.email AS created_by_email" +
+			" FROM library_element_version AS lev" +
+			" LEFT JOIN " + l.SQLStore.Dialect.Quote("user") + " AS u1 ON lev.created_by = u1.id" +
+			" WHERE lev.element_id=? ORDER BY lev.version DESC"
+		return session.SQL(sql, element.ID).Find(&versions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]LibraryElementVersionDTO, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, toLibraryElementVersionDTO(version))
+	}
+	return result, nil
+}
+
+// getLibraryElementVersion gets a version of a Library Element.
+func (l *LibraryElementService) getLibraryElementVersion(c context.Context, signedInUser *models.SignedInUser, uid string, version int64) (LibraryElementVersionDTO, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, uid)
+	if err != nil {
+		return LibraryElementVersionDTO{}, err
+	}
+
+	versions := make([]libraryElementVersionWithMeta, 0)
+	err = l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		sql := "SELECT lev.*, u1.login AS created_by_name, u1.email AS created_by_email" +
+			" FROM library_element_version AS lev" +
+			" LEFT JOIN " + l.SQLStore.Dialect.Quote("user") + " AS u1 ON lev.created_by = u1.id" +
+			" WHERE lev.element_id=? AND lev.version=?"
+		return session.SQL(sql, element.ID, version).Find(&versions)
+	})
+	if err != nil {
+		return LibraryElementVersionDTO{}, err
+	}
+	if len(versions) == 0 {
+		return LibraryElementVersionDTO{}, errLibraryElementVersionNotFound
+	}
+
+	return toLibraryElementVersionDTO(versions[0]), nil
+}
+
+// restoreLibraryElementVersion restores a Library Element to a previous version by saving it as a new version.
+func (l *LibraryElementService) restoreLibraryElementVersion(c context.Context, signedInUser *models.SignedInUser, uid string, version int64) (LibraryElementDTO, error) {
+	element, err := l.getLibraryElementByUid(c, signedInUser, uid)
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+	restored, err := l.getLibraryElementVersion(c, signedInUser, uid, version)
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+
+	cmd := PatchLibraryElementCommand{
+		FolderID: -1,
+		Name:     restored.Name,
+		Model:    restored.Model,
+		Kind:     element.Kind,
+		Version:  element.Version,
+		Message:  fmt.Sprintf("Restored from version %d", version),
+	}
+	return l.patchLibraryElement(c, signedInUser, cmd, uid)
+}
+
+// diffLibraryElementVersions computes the diff between the models of two versions of a Library Element.
+func (l *LibraryElementService) diffLibraryElementVersions(c context.Context, signedInUser *models.SignedInUser, uid string, base, new int64, diffType dashdiffs.DiffType) (*dashdiffs.Result, error) {
+	baseVersion, err := l.getLibraryElementVersion(c, signedInUser, uid, base)
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := l.getLibraryElementVersion(c, signedInUser, uid, new)
+	if err != nil {
+		return nil, err
+	}
+
+	baseModel, err := simplejson.NewJson(baseVersion.Model)
+	if err != nil {
+		return nil, err
+	}
+	newModel, err := simplejson.NewJson(newVersion.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dashdiffs.CalculateDataDiff(baseModel, newModel, diffType)
+	if errors.Is(err, dashdiffs.ErrNilDiff) {
+		return nil, errLibraryElementVersionsIdentical
+	}
+	return result, err
+}
+
+// getOutdatedConnections gets the dashboards that are pinned to an older version of a Library Element.
+func (l *LibraryElementService) getOutdatedConnections(c context.Context, signedInUser *models.SignedInUser, uid string) ([]LibraryElementOutdatedConnectionDTO, error) {
+	connections := make([]LibraryElementOutdatedConnectionDTO, 0)
+	err := l.SQLStore.WithDbSession(c, func(session *sqlstore.DBSession) error {
+		element, err := getLibraryElement(l.SQLStore.Dialect, session, uid, signedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		var libraryElementConnections []libraryElementConnectionWithMeta
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT lec.connection_id, lec.pinned_version, dashboard.uid AS dashboard_uid, dashboard.title AS dashboard_title")
+		builder.Write(" FROM " + models.LibraryElementConnectionTableName + " AS lec")
+		builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id")
+		builder.Write(` WHERE lec.element_id=? AND lec.kind=1 AND lec.pinned_version > 0 AND lec.pinned_version < ?`, element.ID, element.Version)
+		if signedInUser.OrgRole != models.ROLE_ADMIN {
+			builder.WriteDashboardPermissionFilter(signedInUser, models.PERMISSION_VIEW)
+		}
+		builder.Write(" ORDER BY lec.pinned_version, dashboard.title")
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&libraryElementConnections); err != nil {
+			return err
+		}
+
+		for _, connection := range libraryElementConnections {
+			connections = append(connections, LibraryElementOutdatedConnectionDTO{
+				ConnectionID:   connection.ConnectionID,
+				DashboardUID:   connection.DashboardUID,
+				DashboardTitle: connection.DashboardTitle,
+				PinnedVersion:  connection.PinnedVersion,
+				LatestVersion:  element.Version,
+			})
+		}
+
+		return nil
+	})
+
+	return connections, err
+}
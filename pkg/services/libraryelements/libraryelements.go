@@ -25,8 +25,11 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, routeRegister
 type Service interface {
 	CreateElement(c context.Context, signedInUser *models.SignedInUser, cmd CreateLibraryElementCommand) (LibraryElementDTO, error)
 	GetElement(c context.Context, signedInUser *models.SignedInUser, UID string) (LibraryElementDTO, error)
+	PatchElement(c context.Context, signedInUser *models.SignedInUser, cmd PatchLibraryElementCommand, UID string) (LibraryElementDTO, error)
+	DeleteElement(c context.Context, signedInUser *models.SignedInUser, UID string) error
 	GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]LibraryElementDTO, error)
 	ConnectElementsToDashboard(c context.Context, signedInUser *models.SignedInUser, elementUIDs []string, dashboardID int64) error
+	ConnectElementVersionsToDashboard(c context.Context, signedInUser *models.SignedInUser, pinnedVersions map[string]int64, dashboardID int64) error
 	DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error
 	DeleteLibraryElementsInFolder(c context.Context, signedInUser *models.SignedInUser, folderUID string) error
 }
@@ -49,6 +52,17 @@ func (l *LibraryElementService) GetElement(c context.Context, signedInUser *mode
 	return l.getLibraryElementByUid(c, signedInUser, UID)
 }
 
+// PatchElement updates an element from a UID.
+func (l *LibraryElementService) PatchElement(c context.Context, signedInUser *models.SignedInUser, cmd PatchLibraryElementCommand, UID string) (LibraryElementDTO, error) {
+	return l.patchLibraryElement(c, signedInUser, cmd, UID)
+}
+
+// DeleteElement deletes an element from a UID.
+func (l *LibraryElementService) DeleteElement(c context.Context, signedInUser *models.SignedInUser, UID string) error {
+	_, err := l.deleteLibraryElement(c, signedInUser, UID)
+	return err
+}
+
 // GetElementsForDashboard gets all connected elements for a specific dashboard.
 func (l *LibraryElementService) GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]LibraryElementDTO, error) {
 	return l.getElementsForDashboardID(c, dashboardID)
@@ -59,6 +73,12 @@ func (l *LibraryElementService) ConnectElementsToDashboard(c context.Context, si
 	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, dashboardID)
 }
 
+// ConnectElementVersionsToDashboard connects elements to a specific dashboard, pinning them to the versions they map to.
+// Elements mapped to version 0 follow the latest version.
+func (l *LibraryElementService) ConnectElementVersionsToDashboard(c context.Context, signedInUser *models.SignedInUser, pinnedVersions map[string]int64, dashboardID int64) error {
+	return l.connectElementVersionsToDashboardID(c, signedInUser, pinnedVersions, dashboardID)
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *LibraryElementService) DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error {
 	return l.disconnectElementsFromDashboardID(c, dashboardID)
@@ -0,0 +1,145 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	patchPanel := func(t *testing.T, sc scenarioContext) {
+		t.Helper()
+
+		cmd := PatchLibraryElementCommand{
+			FolderID: -1,
+			Model: []byte(`
+				{
+				  "datasource": "${DS_GDEV-TESTDATA}",
+				  "id": 1,
+				  "title": "Text - Library Panel",
+				  "type": "graph",
+				  "description": "An updated description"
+				}
+			`),
+			Kind:    int64(models.PanelElement),
+			Version: 1,
+			Message: "Switch to graph",
+		}
+		sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+		sc.reqContext.Req.Body = mockRequestBody(cmd)
+		resp := sc.service.patchHandler(sc.reqContext)
+		require.Equal(t, 200, resp.Status())
+	}
+
+	scenarioWithPanel(t, "When an admin patches a library panel, it should keep the previous versions",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanel(t, sc)
+
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var versions LibraryElementVersionsResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &versions))
+			require.Len(t, versions.Result, 2)
+			require.Equal(t, int64(2), versions.Result[0].Version)
+			require.Equal(t, "Switch to graph", versions.Result[0].Message)
+			require.Equal(t, userInDbName, versions.Result[0].CreatedBy.Name)
+			require.Equal(t, int64(1), versions.Result[1].Version)
+			require.Equal(t, "text", versions.Result[1].Type)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var version LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &version))
+			model, err := simplejson.NewJson(version.Result.Model)
+			require.NoError(t, err)
+			require.Equal(t, "text", model.Get("type").MustString())
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "3"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin restores a version of a library panel, it should save it as a new version",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanel(t, sc)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp := sc.service.restoreVersionHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, int64(3), result.Result.Version)
+			require.Equal(t, "text", result.Result.Type)
+			require.Equal(t, "A description", result.Result.Description)
+
+			versions, err := sc.service.getLibraryElementVersions(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, sc.initialResult.Result.UID)
+			require.NoError(t, err)
+			require.Len(t, versions, 3)
+			require.Equal(t, "Restored from version 1", versions[0].Message)
+		})
+
+	scenarioWithPanel(t, "When an admin compares two versions of a library panel, it should return the diff",
+		func(t *testing.T, sc scenarioContext) {
+			patchPanel(t, sc)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.ctx.Req.URL = &url.URL{RawQuery: "base=1&new=2&diffType=delta"}
+			resp := sc.service.diffHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			require.Contains(t, string(resp.Body()), "graph")
+
+			sc.ctx.Req.URL, sc.ctx.Req.Form = &url.URL{RawQuery: "base=2&new=2&diffType=delta"}, nil
+			resp = sc.service.diffHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+
+			sc.ctx.Req.URL, sc.ctx.Req.Form = &url.URL{RawQuery: "base=1&new=2&diffType=semantic"}, nil
+			resp = sc.service.diffHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When a dashboard is pinned to a version of a library panel, it should get that version and be listed as outdated",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Pinned dashboard",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"panels": []interface{}{}}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementVersionsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser,
+				map[string]int64{sc.initialResult.Result.UID: 1}, dashInDB.Id)
+			require.NoError(t, err)
+
+			patchPanel(t, sc)
+
+			elements, err := sc.service.GetElementsForDashboard(sc.reqContext.Req.Context(), dashInDB.Id)
+			require.NoError(t, err)
+			element := elements[sc.initialResult.Result.UID]
+			require.Equal(t, int64(2), element.Version)
+			require.Equal(t, int64(1), element.PinnedVersion)
+			require.Equal(t, "text", element.Type)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getOutdatedConnectionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var outdated LibraryElementOutdatedConnectionsResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &outdated))
+			require.Equal(t, []LibraryElementOutdatedConnectionDTO{{
+				ConnectionID:   dashInDB.Id,
+				DashboardUID:   dashInDB.Uid,
+				DashboardTitle: "Pinned dashboard",
+				PinnedVersion:  1,
+				LatestVersion:  2,
+			}}, outdated.Result)
+
+			err = sc.service.ConnectElementsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser,
+				[]string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+			elements, err = sc.service.GetElementsForDashboard(sc.reqContext.Req.Context(), dashInDB.Id)
+			require.NoError(t, err)
+			require.Equal(t, int64(0), elements[sc.initialResult.Result.UID].PinnedVersion)
+			require.Equal(t, "graph", elements[sc.initialResult.Result.UID].Type)
+		})
+}
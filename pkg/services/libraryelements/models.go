@@ -53,6 +53,7 @@ type LibraryElementWithMeta struct {
 	ConnectedDashboards int64
 	CreatedBy           int64
 	UpdatedBy           int64
+	PinnedVersion       int64
 	CreatedByName       string
 	CreatedByEmail      string
 	UpdatedByName       string
@@ -72,6 +73,8 @@ type LibraryElementDTO struct {
 	Model       json.RawMessage       `json:"model"`
 	Version     int64                 `json:"version"`
 	Meta        LibraryElementDTOMeta `json:"meta"`
+	// PinnedVersion is the version a dashboard is pinned to, the model is the one of that version.
+	PinnedVersion int64 `json:"pinnedVersion,omitempty"`
 }
 
 // LibraryElementSearchResult is the search result for entities.
@@ -102,14 +105,59 @@ type LibraryElementDTOMetaUser struct {
 	AvatarURL string `json:"avatarUrl"`
 }
 
+// LibraryElementVersion is the model for the versions of library elements.
+type LibraryElementVersion struct {
+	ID          int64 `xorm:"pk autoincr 'id'"`
+	ElementID   int64 `xorm:"element_id"`
+	Version     int64
+	Name        string
+	Type        string
+	Description string
+	Model       json.RawMessage
+	Message     string
+
+	Created   time.Time
+	CreatedBy int64
+}
+
+// libraryElementVersionWithMeta is the model used to retrieve versions with the user that created them.
+type libraryElementVersionWithMeta struct {
+	ID             int64 `xorm:"pk autoincr 'id'"`
+	ElementID      int64 `xorm:"element_id"`
+	Version        int64
+	Name           string
+	Type           string
+	Description    string
+	Model          json.RawMessage
+	Message        string
+	Created        time.Time
+	CreatedBy      int64
+	CreatedByName  string
+	CreatedByEmail string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for library element versions.
+type LibraryElementVersionDTO struct {
+	Version     int64                     `json:"version"`
+	Name        string                    `json:"name"`
+	Type        string                    `json:"type"`
+	Description string                    `json:"description"`
+	Model       json.RawMessage           `json:"model,omitempty"`
+	Message     string                    `json:"message"`
+	Created     time.Time                 `json:"created"`
+	CreatedBy   LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
 // libraryElementConnection is the model for library element connections.
 type libraryElementConnection struct {
 	ID           int64 `xorm:"pk autoincr 'id'"`
 	ElementID    int64 `xorm:"element_id"`
 	Kind         int64 `xorm:"kind"`
 	ConnectionID int64 `xorm:"connection_id"`
-	Created      time.Time
-	CreatedBy    int64
+	// PinnedVersion is the version of the element the connection is pinned to, 0 follows the latest version.
+	PinnedVersion int64 `xorm:"pinned_version"`
+	Created       time.Time
+	CreatedBy     int64
 }
 
 // libraryElementConnectionWithMeta is the model for library element connections with meta.
@@ -118,20 +166,33 @@ type libraryElementConnectionWithMeta struct {
 	ElementID      int64 `xorm:"element_id"`
 	Kind           int64 `xorm:"kind"`
 	ConnectionID   int64 `xorm:"connection_id"`
+	PinnedVersion  int64 `xorm:"pinned_version"`
 	Created        time.Time
 	CreatedBy      int64
 	CreatedByName  string
 	CreatedByEmail string
+	DashboardUID   string `xorm:"dashboard_uid"`
+	DashboardTitle string
 }
 
 // LibraryElementConnectionDTO is the frontend DTO for element connections.
 type LibraryElementConnectionDTO struct {
-	ID           int64                     `json:"id"`
-	Kind         int64                     `json:"kind"`
-	ElementID    int64                     `json:"elementId"`
-	ConnectionID int64                     `json:"connectionId"`
-	Created      time.Time                 `json:"created"`
-	CreatedBy    LibraryElementDTOMetaUser `json:"createdBy"`
+	ID            int64                     `json:"id"`
+	Kind          int64                     `json:"kind"`
+	ElementID     int64                     `json:"elementId"`
+	ConnectionID  int64                     `json:"connectionId"`
+	PinnedVersion int64                     `json:"pinnedVersion"`
+	Created       time.Time                 `json:"created"`
+	CreatedBy     LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
+// LibraryElementOutdatedConnectionDTO is the frontend DTO for dashboards pinned to an older version of an element.
+type LibraryElementOutdatedConnectionDTO struct {
+	ConnectionID   int64  `json:"connectionId"`
+	DashboardUID   string `json:"dashboardUid"`
+	DashboardTitle string `json:"dashboardTitle"`
+	PinnedVersion  int64  `json:"pinnedVersion"`
+	LatestVersion  int64  `json:"latestVersion"`
 }
 
 var (
@@ -153,6 +214,10 @@ var (
 	errLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	errLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// errLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	errLibraryElementVersionNotFound = errors.New("library element version could not be found")
+	// errLibraryElementVersionsIdentical is an error for when two compared versions of a library element are identical.
+	errLibraryElementVersionsIdentical = errors.New("the library element versions are identical")
 )
 
 // Commands
@@ -166,14 +231,16 @@ type CreateLibraryElementCommand struct {
 	UID      string          `json:"uid"`
 }
 
-// patchLibraryElementCommand is the command for patching a LibraryElement
-type patchLibraryElementCommand struct {
+// PatchLibraryElementCommand is the command for patching a LibraryElement
+type PatchLibraryElementCommand struct {
 	FolderID int64           `json:"folderId" binding:"Default(-1)"`
 	Name     string          `json:"name"`
 	Model    json.RawMessage `json:"model,omitempty"`
 	Kind     int64           `json:"kind" binding:"Required"`
 	Version  int64           `json:"version" binding:"Required"`
 	UID      string          `json:"uid"`
+	// Message describes the change in the version history.
+	Message string `json:"message"`
 }
 
 // searchLibraryElementsQuery is the query used for searching for Elements
@@ -208,6 +275,21 @@ type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
 }
 
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionDTO.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementOutdatedConnectionsResponse is a response struct for an array of LibraryElementOutdatedConnectionDTO.
+type LibraryElementOutdatedConnectionsResponse struct {
+	Result []LibraryElementOutdatedConnectionDTO `json:"result"`
+}
+
 // DeleteLibraryElementResponse is the response struct for deleting a library element.
 type DeleteLibraryElementResponse struct {
 	ID      int64  `json:"id"`
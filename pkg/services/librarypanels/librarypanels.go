@@ -115,7 +115,7 @@ func loadLibraryPanelsRecursively(elements map[string]libraryelements.LibraryEle
 		elem := parent.Get("panels").GetIndex(i)
 		elem.Set("gridPos", panelAsJSON.Get("gridPos").MustMap())
 		elem.Set("id", panelAsJSON.Get("id").MustInt64())
-		elem.Set("libraryPanel", map[string]interface{}{
+		header := map[string]interface{}{
 			"uid":         elementInDB.UID,
 			"name":        elementInDB.Name,
 			"type":        elementInDB.Type,
@@ -138,7 +138,11 @@ func loadLibraryPanelsRecursively(elements map[string]libraryelements.LibraryEle
 					"avatarUrl": elementInDB.Meta.UpdatedBy.AvatarURL,
 				},
 			},
-		})
+		}
+		if elementInDB.PinnedVersion > 0 {
+			header["pinnedVersion"] = elementInDB.PinnedVersion
+		}
+		elem.Set("libraryPanel", header)
 	}
 
 	return nil
@@ -182,13 +186,17 @@ func cleanLibraryPanelsRecursively(parent *simplejson.Json) error {
 		// keep only the necessary JSON properties, the rest of the properties should be safely stored in library_panels table
 		gridPos := panelAsJSON.Get("gridPos").MustMap()
 		ID := panelAsJSON.Get("id").MustInt64(int64(i))
+		header := map[string]interface{}{
+			"uid":  UID,
+			"name": name,
+		}
+		if pinnedVersion := libraryPanel.Get("pinnedVersion").MustInt64(); pinnedVersion > 0 {
+			header["pinnedVersion"] = pinnedVersion
+		}
 		parent.Get("panels").SetIndex(i, map[string]interface{}{
-			"id":      ID,
-			"gridPos": gridPos,
-			"libraryPanel": map[string]interface{}{
-				"uid":  UID,
-				"name": name,
-			},
+			"id":           ID,
+			"gridPos":      gridPos,
+			"libraryPanel": header,
 		})
 	}
 
@@ -198,25 +206,22 @@ func cleanLibraryPanelsRecursively(parent *simplejson.Json) error {
 // ConnectLibraryPanelsForDashboard loops through all panels in dashboard JSON and connects any library panels to the dashboard.
 func (lps *LibraryPanelService) ConnectLibraryPanelsForDashboard(c context.Context, signedInUser *models.SignedInUser, dash *models.Dashboard) error {
 	panels := dash.Data.Get("panels").MustArray()
-	libraryPanels := make(map[string]string)
+	libraryPanels := make(map[string]int64)
 	err := connectLibraryPanelsRecursively(c, panels, libraryPanels)
 	if err != nil {
 		return err
 	}
 
-	elementUIDs := make([]string, 0, len(libraryPanels))
-	for libraryPanel := range libraryPanels {
-		elementUIDs = append(elementUIDs, libraryPanel)
-	}
-
-	return lps.LibraryElementService.ConnectElementsToDashboard(c, signedInUser, elementUIDs, dash.Id)
+	return lps.LibraryElementService.ConnectElementVersionsToDashboard(c, signedInUser, libraryPanels, dash.Id)
 }
 
 func isLibraryPanelOrRow(panel *simplejson.Json, panelType string) bool {
 	return panel.Interface() != nil || panelType == "row"
 }
 
-func connectLibraryPanelsRecursively(c context.Context, panels []interface{}, libraryPanels map[string]string) error {
+// connectLibraryPanelsRecursively collects the library panels of a dashboard with the version they are pinned to.
+// When panels using the same library panel are pinned to different versions, the oldest version wins.
+func connectLibraryPanelsRecursively(c context.Context, panels []interface{}, libraryPanels map[string]int64) error {
 	for _, panel := range panels {
 		panelAsJSON := simplejson.NewFromAny(panel)
 		libraryPanel := panelAsJSON.Get("libraryPanel")
@@ -240,9 +245,9 @@ func connectLibraryPanelsRecursively(c context.Context, panels []interface{}, li
 		if len(UID) == 0 {
 			return errLibraryPanelHeaderUIDMissing
 		}
-		_, exists := libraryPanels[UID]
-		if !exists {
-			libraryPanels[UID] = UID
+		pinnedVersion := libraryPanel.Get("pinnedVersion").MustInt64()
+		if current, exists := libraryPanels[UID]; !exists || (pinnedVersion > 0 && (current == 0 || pinnedVersion < current)) {
+			libraryPanels[UID] = pinnedVersion
 		}
 	}
 
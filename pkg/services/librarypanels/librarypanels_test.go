@@ -1045,6 +1045,65 @@ func TestConnectLibraryPanelsForDashboard(t *testing.T) {
 		})
 }
 
+func TestPinnedLibraryPanelsForDashboard(t *testing.T) {
+	scenarioWithLibraryPanel(t, "When an admin stores a dashboard with a pinned library panel, it should keep loading the pinned version",
+		func(t *testing.T, sc scenarioContext) {
+			libraryPanel := func(pinnedVersion int64) map[string]interface{} {
+				header := map[string]interface{}{
+					"uid":  sc.initialResult.Result.UID,
+					"name": sc.initialResult.Result.Name,
+				}
+				if pinnedVersion > 0 {
+					header["pinnedVersion"] = pinnedVersion
+				}
+				return header
+			}
+			dashJSON := map[string]interface{}{
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":           int64(1),
+						"gridPos":      map[string]interface{}{"h": 6, "w": 6, "x": 0, "y": 0},
+						"libraryPanel": libraryPanel(0),
+					},
+					map[string]interface{}{
+						"id":           int64(2),
+						"gridPos":      map[string]interface{}{"h": 6, "w": 6, "x": 6, "y": 0},
+						"libraryPanel": libraryPanel(1),
+					},
+				},
+			}
+			dash := models.Dashboard{
+				Title: "Testing PinnedLibraryPanelsForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+
+			err := sc.service.CleanLibraryPanelsForDashboard(dashInDB)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), dashInDB.Data.Get("panels").GetIndex(1).GetPath("libraryPanel", "pinnedVersion").MustInt64())
+
+			err = sc.service.ConnectLibraryPanelsForDashboard(sc.ctx, sc.user, dashInDB)
+			require.NoError(t, err)
+
+			_, err = sc.elementService.PatchElement(sc.ctx, sc.user, libraryelements.PatchLibraryElementCommand{
+				FolderID: -1,
+				Model:    []byte(`{"id": 1, "title": "Graph - Library Panel", "type": "graph"}`),
+				Kind:     int64(models.PanelElement),
+				Version:  1,
+			}, sc.initialResult.Result.UID)
+			require.NoError(t, err)
+
+			err = sc.service.LoadLibraryPanelsForDashboard(sc.ctx, dashInDB)
+			require.NoError(t, err)
+			for _, panel := range dashInDB.Data.Get("panels").MustArray() {
+				panelAsJSON := simplejson.NewFromAny(panel)
+				require.Equal(t, "text", panelAsJSON.Get("type").MustString())
+				require.Equal(t, int64(1), panelAsJSON.GetPath("libraryPanel", "pinnedVersion").MustInt64())
+				require.Equal(t, int64(2), panelAsJSON.GetPath("libraryPanel", "version").MustInt64())
+			}
+		})
+}
+
 func TestImportLibraryPanelsForDashboard(t *testing.T) {
 	testScenario(t, "When an admin tries to import a dashboard with a library panel that does not exist, it should import the library panel",
 		func(t *testing.T, sc scenarioContext) {
@@ -87,6 +87,9 @@ type AlertNG struct {
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
+
+	// RuleStore persists the alert rules. It is nil when unified alerting is disabled.
+	RuleStore store.RuleStore
 }
 
 func (ng *AlertNG) init() error {
@@ -104,6 +107,7 @@ func (ng *AlertNG) init() error {
 		SQLStore:        ng.SQLStore,
 		Logger:          ng.Log,
 	}
+	ng.RuleStore = store
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
@@ -53,6 +53,7 @@ type RuleStore interface {
 	GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
 	UpsertAlertRules([]UpsertRule) error
 	UpdateRuleGroup(UpdateRuleGroupCmd) error
+	ValidateAlertRule(ngmodels.AlertRule) error
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -227,7 +228,7 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 					r.New.ExecErrState = ngmodels.AlertingErrState
 				}
 
-				if err := st.validateAlertRule(r.New); err != nil {
+				if err := st.ValidateAlertRule(r.New); err != nil {
 					return err
 				}
 
@@ -265,7 +266,7 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 					r.New.NoDataState = r.Existing.NoDataState
 				}
 
-				if err := st.validateAlertRule(r.New); err != nil {
+				if err := st.ValidateAlertRule(r.New); err != nil {
 					return err
 				}
 
@@ -475,8 +476,8 @@ var GenerateNewAlertRuleUID = func(sess *sqlstore.DBSession, orgID int64, ruleTi
 	return "", ngmodels.ErrAlertRuleFailedGenerateUniqueUID
 }
 
-// validateAlertRule validates the alert rule interval and organisation.
-func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
+// ValidateAlertRule validates the alert rule interval and organisation.
+func (st DBstore) ValidateAlertRule(alertRule ngmodels.AlertRule) error {
 	if len(alertRule.Data) == 0 {
 		return fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 	}
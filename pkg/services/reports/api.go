@@ -0,0 +1,175 @@
+package reports
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultRunsLimit = 20
+	maxRunsLimit     = 100
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/dashboard-reports", func(route routing.RouteRegister) {
+		route.Get("/", routing.Wrap(s.getReportsHandler))
+		route.Post("/", routing.Wrap(s.createReportHandler))
+		route.Get("/:uid", routing.Wrap(s.getReportHandler))
+		route.Put("/:uid", routing.Wrap(s.updateReportHandler))
+		route.Delete("/:uid", routing.Wrap(s.deleteReportHandler))
+		route.Post("/:uid/send", routing.Wrap(s.sendReportHandler))
+		route.Get("/:uid/runs", routing.Wrap(s.getRunsHandler))
+	}, middleware.ReqOrgAdmin)
+}
+
+func (s *Service) getReportsHandler(c *models.ReqContext) response.Response {
+	reports, err := s.getReports(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	lastRuns, err := s.getLastRuns(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+
+	result := make([]ReportDTO, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, toReportDTO(report, lastRuns[report.ID]))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) getReportHandler(c *models.ReqContext) response.Response {
+	report, err := s.getReport(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	runs, err := s.getRuns(c.Req.Context(), report.ID, 1)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+
+	var lastRun *ReportRun
+	if len(runs) > 0 {
+		lastRun = runs[0]
+	}
+	return response.JSON(http.StatusOK, toReportDTO(report, lastRun))
+}
+
+func (s *Service) createReportHandler(c *models.ReqContext) response.Response {
+	cmd := SaveReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	report, err := s.CreateReport(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, toReportDTO(report, nil))
+}
+
+func (s *Service) updateReportHandler(c *models.ReqContext) response.Response {
+	cmd := SaveReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	report, err := s.UpdateReport(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, toReportDTO(report, nil))
+}
+
+func (s *Service) deleteReportHandler(c *models.ReqContext) response.Response {
+	if err := s.deleteReport(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"]); err != nil {
+		return toErrorResponse(err)
+	}
+	return response.Success("Report deleted")
+}
+
+// sendReportHandler sends a report and waits for it to be sent.
+func (s *Service) sendReportHandler(c *models.ReqContext) response.Response {
+	cmd := SendReportCommand{}
+	if c.Req.ContentLength > 0 {
+		if err := web.Bind(c.Req, &cmd); err != nil {
+			return response.Error(http.StatusBadRequest, "bad request data", err)
+		}
+	}
+
+	run, err := s.SendReport(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"], cmd)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+	if run.State == StateFailed {
+		return response.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to send report",
+			"error":   run.Error,
+			"run":     toReportRunDTO(run),
+		})
+	}
+	return response.JSON(http.StatusOK, toReportRunDTO(run))
+}
+
+func (s *Service) getRunsHandler(c *models.ReqContext) response.Response {
+	report, err := s.getReport(c.Req.Context(), c.OrgId, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toErrorResponse(err)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	} else if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+	runs, err := s.getRuns(c.Req.Context(), report.ID, limit)
+	if err != nil {
+		return toErrorResponse(err)
+	}
+
+	result := make([]ReportRunDTO, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, toReportRunDTO(run))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toReportDTO(report *Report, lastRun *ReportRun) ReportDTO {
+	dto := ReportDTO{
+		Report:   report,
+		Schedule: report.Schedule(),
+		LastRun:  lastRun,
+	}
+	if report.Enabled && report.NextRun > 0 {
+		next := time.Unix(report.NextRun, 0)
+		dto.NextRun = &next
+	}
+	return dto
+}
+
+func toReportRunDTO(run *ReportRun) ReportRunDTO {
+	return ReportRunDTO{ReportRun: run, ScheduledAt: time.Unix(run.ScheduledAt, 0)}
+}
+
+func toErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrInvalidReport), errors.Is(err, ErrDashboardNotFound):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrReportUIDExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrReportAccessDenied):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Failed to process report request", err)
+}
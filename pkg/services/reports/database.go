@@ -0,0 +1,231 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func (s *Service) insertReport(ctx context.Context, report *Report) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id=? AND uid=?", report.OrgID, report.UID).Exist(&Report{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrReportUIDExists
+		}
+		_, err = sess.Insert(report)
+		return err
+	})
+}
+
+func (s *Service) updateReport(ctx context.Context, report *Report) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		affected, err := sess.ID(report.ID).AllCols().Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Service) getReport(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	report := &Report{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Where("org_id=? AND uid=?", orgID, uid).Get(report)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *Service) getReportByID(ctx context.Context, id int64) (*Report, error) {
+	report := &Report{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.ID(id).Get(report)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *Service) getReports(ctx context.Context, orgID int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id=?", orgID).Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *Service) deleteReport(ctx context.Context, orgID int64, uid string) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		report := &Report{}
+		has, err := sess.Where("org_id=? AND uid=?", orgID, uid).Get(report)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrReportNotFound
+		}
+		if _, err := sess.Exec("DELETE FROM report_run WHERE report_id=?", report.ID); err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM report WHERE id=?", report.ID)
+		return err
+	})
+}
+
+// getRuns returns the latest runs of a report, newest first.
+func (s *Service) getRuns(ctx context.Context, reportID int64, limit int) ([]*ReportRun, error) {
+	runs := make([]*ReportRun, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("report_id=?", reportID).Desc("id").Limit(limit).Find(&runs)
+	})
+	return runs, err
+}
+
+// getLastRuns returns the latest run of every report of an organization that has run, by report id.
+func (s *Service) getLastRuns(ctx context.Context, orgID int64) (map[int64]*ReportRun, error) {
+	runs := make([]*ReportRun, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.SQL(`SELECT * FROM report_run WHERE id IN (
+			SELECT MAX(id) FROM report_run WHERE org_id=? GROUP BY report_id
+		)`, orgID).Find(&runs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byReport := make(map[int64]*ReportRun, len(runs))
+	for _, run := range runs {
+		byReport[run.ReportID] = run
+	}
+	return byReport, nil
+}
+
+// getDueReports returns the enabled reports whose next scheduled run is due.
+func (s *Service) getDueReports(ctx context.Context, now time.Time) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("enabled=? AND next_run>0 AND next_run<=?", true, now.Unix()).Find(&reports)
+	})
+	return reports, err
+}
+
+// scheduleRun moves the next run of a due report, 0 for none, and queues a run for the time it was due. It is a no-op when
+// another instance scheduled the run first.
+func (s *Service) scheduleRun(ctx context.Context, report *Report, nextRun int64) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE report SET next_run=? WHERE id=? AND next_run=?", nextRun, report.ID, report.NextRun)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+
+		_, err = sess.Insert(&ReportRun{
+			OrgID:       report.OrgID,
+			ReportID:    report.ID,
+			Trigger:     TriggerSchedule,
+			Attempt:     1,
+			State:       StatePending,
+			ScheduledAt: report.NextRun,
+			Created:     time.Now(),
+		})
+		return err
+	})
+}
+
+func (s *Service) insertRun(ctx context.Context, run *ReportRun) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(run)
+		return err
+	})
+}
+
+// claimNextRun marks the pending run that was due first before a time as running and returns it, or nil when no run
+// is due. A run is only claimed by one instance.
+func (s *Service) claimNextRun(ctx context.Context, dueBefore time.Time) (*ReportRun, error) {
+	var claimed *ReportRun
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for {
+			run := &ReportRun{}
+			has, err := sess.Where("state=? AND scheduled_at<=?", StatePending, dueBefore.Unix()).Asc("scheduled_at", "id").Get(run)
+			if err != nil || !has {
+				return err
+			}
+
+			started := s.now()
+			affected, err := sess.Where("state=?", StatePending).ID(run.ID).Cols("state", "started").
+				Update(&ReportRun{State: StateRunning, Started: &started})
+			if err != nil {
+				return err
+			}
+			// otherwise another instance claimed the run in the meantime
+			if affected == 1 {
+				run.State = StateRunning
+				run.Started = &started
+				claimed = run
+				return nil
+			}
+		}
+	})
+	return claimed, err
+}
+
+// finishRun records the outcome of a run and reports whether it did, which it does not when the run is no longer
+// running because it was failed as stale in the meantime.
+func (s *Service) finishRun(ctx context.Context, run *ReportRun) (bool, error) {
+	var affected int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		affected, err = sess.Where("state=?", StateRunning).ID(run.ID).Cols("state", "error", "started", "finished").Update(run)
+		return err
+	})
+	return affected == 1, err
+}
+
+// failStaleRuns marks the runs that started before a time and never finished, as when an instance stopped while
+// sending a report, as failed and returns them.
+func (s *Service) failStaleRuns(ctx context.Context, before time.Time) ([]*ReportRun, error) {
+	failed := make([]*ReportRun, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		running := make([]*ReportRun, 0)
+		if err := sess.Where("state=?", StateRunning).Find(&running); err != nil {
+			return err
+		}
+
+		now := s.now()
+		for _, run := range running {
+			if run.Started != nil && run.Started.After(before) {
+				continue
+			}
+			run.State, run.Error, run.Finished = StateFailed, "run did not finish", &now
+			affected, err := sess.Where("state=?", StateRunning).ID(run.ID).Cols("state", "error", "finished").Update(run)
+			if err != nil {
+				return err
+			}
+			if affected == 1 {
+				failed = append(failed, run)
+			}
+		}
+		return nil
+	})
+	return failed, err
+}
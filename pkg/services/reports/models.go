@@ -0,0 +1,155 @@
+package reports
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrReportNotFound     = errors.New("report not found")
+	ErrReportUIDExists    = errors.New("a report with the same uid already exists")
+	ErrInvalidReport      = errors.New("invalid report")
+	ErrDashboardNotFound  = errors.New("report dashboard not found")
+	ErrRendererRequired   = errors.New("reports require the image renderer")
+	ErrNoPanelsToRender   = errors.New("report dashboard has no panels to render")
+	ErrNoRecipients       = errors.New("report has no recipients")
+	ErrReportAccessDenied = errors.New("access denied to the report dashboard")
+)
+
+type Layout string
+
+const (
+	// LayoutSimple renders every panel with the full width of the page.
+	LayoutSimple Layout = "simple"
+	// LayoutGrid arranges panels on pages the way they are arranged on the dashboard.
+	LayoutGrid Layout = "grid"
+)
+
+type Orientation string
+
+const (
+	OrientationPortrait  Orientation = "portrait"
+	OrientationLandscape Orientation = "landscape"
+)
+
+type Format string
+
+const (
+	// FormatPDF attaches a PDF composed from the rendered panels of the dashboard.
+	FormatPDF Format = "pdf"
+	// FormatPNG attaches an image of the whole dashboard.
+	FormatPNG Format = "png"
+	// FormatCSV attaches the data of every panel with queries, one file per panel.
+	FormatCSV Format = "csv"
+)
+
+type RunTrigger string
+
+const (
+	TriggerSchedule RunTrigger = "schedule"
+	TriggerManual   RunTrigger = "manual"
+)
+
+type RunState string
+
+const (
+	StatePending RunState = "pending"
+	StateRunning RunState = "running"
+	StateSuccess RunState = "success"
+	StateFailed  RunState = "failed"
+)
+
+// Report is the definition of a dashboard report: what is rendered, when, and to whom it is sent. Reports are rendered
+// with the permissions of the user who last saved them.
+type Report struct {
+	ID               int64               `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID            int64               `xorm:"org_id" json:"orgId"`
+	UID              string              `xorm:"uid" json:"uid"`
+	Name             string              `json:"name"`
+	UserID           int64               `xorm:"user_id" json:"userId"`
+	DashboardUID     string              `xorm:"dashboard_uid" json:"dashboardUid"`
+	Variables        map[string][]string `json:"variables"`
+	TimeFrom         string              `json:"timeFrom"`
+	TimeTo           string              `json:"timeTo"`
+	Layout           Layout              `json:"layout"`
+	Orientation      Orientation         `json:"orientation"`
+	Formats          []Format            `json:"formats"`
+	ScheduleCron     string              `json:"-"`
+	ScheduleInterval string              `json:"-"`
+	Timezone         string              `json:"-"`
+	Recipients       []string            `json:"recipients"`
+	ReplyTo          string              `json:"replyTo"`
+	Message          string              `json:"message"`
+	Enabled          bool                `json:"enabled"`
+	// NextRun is the unix time of the next scheduled run, 0 when the report is only sent on demand
+	NextRun int64     `json:"-"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Schedule is when a report is sent, either on a cron expression or at a fixed interval from the time the schedule
+// was saved. A report without schedule is only sent on demand.
+type Schedule struct {
+	Cron     string `json:"cron,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+func (r *Report) Schedule() Schedule {
+	return Schedule{Cron: r.ScheduleCron, Interval: r.ScheduleInterval, Timezone: r.Timezone}
+}
+
+// ReportRun is an attempt at sending a report. A failed attempt is followed by a new pending run, with the same
+// recipients, until the configured number of attempts is reached.
+type ReportRun struct {
+	ID       int64      `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID    int64      `xorm:"org_id" json:"-"`
+	ReportID int64      `xorm:"report_id" json:"reportId"`
+	Trigger  RunTrigger `xorm:"triggered_by" json:"trigger"`
+	Attempt  int        `json:"attempt"`
+	State    RunState   `json:"state"`
+	Error    string     `json:"error,omitempty"`
+	// Recipients are those the run is sent to instead of the recipients of the report, empty for the latter
+	Recipients  []string   `json:"recipients,omitempty"`
+	ScheduledAt int64      `json:"-"`
+	Started     *time.Time `json:"started,omitempty"`
+	Finished    *time.Time `json:"finished,omitempty"`
+	Created     time.Time  `json:"created"`
+}
+
+// ReportDTO is a report as returned by the API.
+type ReportDTO struct {
+	*Report
+	Schedule Schedule   `json:"schedule"`
+	NextRun  *time.Time `json:"nextRun,omitempty"`
+	LastRun  *ReportRun `json:"lastRun,omitempty"`
+}
+
+// ReportRunDTO is a run as returned by the API.
+type ReportRunDTO struct {
+	*ReportRun
+	ScheduledAt time.Time `json:"scheduledAt"`
+}
+
+// SaveReportCommand creates a report, or updates the report with the uid of the URL.
+type SaveReportCommand struct {
+	UID          string              `json:"uid"`
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	Variables    map[string][]string `json:"variables"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Layout       Layout              `json:"layout"`
+	Orientation  Orientation         `json:"orientation"`
+	Formats      []Format            `json:"formats"`
+	Schedule     Schedule            `json:"schedule"`
+	Recipients   []string            `json:"recipients"`
+	ReplyTo      string              `json:"replyTo"`
+	Message      string              `json:"message"`
+	Enabled      *bool               `json:"enabled"`
+}
+
+// SendReportCommand sends a report on demand, to the recipients of the report unless others are given.
+type SendReportCommand struct {
+	Recipients []string `json:"recipients"`
+}
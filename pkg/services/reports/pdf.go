@@ -0,0 +1,133 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pageMargin   = 10.0
+	headerHeight = 16.0
+	panelSpacing = 4.0
+	// simplePortraitPanels is the number of panels on a page of a simple portrait report, landscape reports have one.
+	simplePortraitPanels = 3
+)
+
+// placement is where a panel is drawn on a page of the PDF, in millimeters.
+type placement struct {
+	Panel         panel
+	Page          int
+	X, Y, W, H    float64
+	Width, Height int
+}
+
+// layoutPanels places the panels of a report on the pages of a PDF of the given page size.
+func layoutPanels(panels []panel, layout Layout, orientation Orientation, pageWidth, pageHeight float64) []placement {
+	contentWidth := pageWidth - 2*pageMargin
+	contentHeight := pageHeight - 2*pageMargin - headerHeight
+	top := pageMargin + headerHeight
+	placements := make([]placement, 0, len(panels))
+
+	if layout == LayoutGrid {
+		scale := contentWidth / gridWidth
+		page, pageTop := 0, -1
+		for _, p := range panels {
+			h := float64(p.H*gridRowHeight) * scale
+			if h > contentHeight {
+				h = contentHeight
+			}
+			if pageTop < 0 {
+				pageTop = p.Y
+			} else if float64((p.Y-pageTop)*gridRowHeight)*scale+h > contentHeight && p.Y > pageTop {
+				page++
+				pageTop = p.Y
+			}
+			w := float64(p.W*gridWidth/gridColumns) * scale
+			placements = append(placements, placement{
+				Panel:  p,
+				Page:   page,
+				X:      pageMargin + float64(p.X*gridWidth/gridColumns)*scale,
+				Y:      top + float64((p.Y-pageTop)*gridRowHeight)*scale,
+				W:      w - panelSpacing/2,
+				H:      h - panelSpacing/2,
+				Width:  p.W * gridWidth / gridColumns,
+				Height: int(h / scale),
+			})
+		}
+		return placements
+	}
+
+	perPage := simplePortraitPanels
+	if orientation == OrientationLandscape {
+		perPage = 1
+	}
+	h := (contentHeight - float64(perPage-1)*panelSpacing) / float64(perPage)
+	for i, p := range panels {
+		placements = append(placements, placement{
+			Panel:  p,
+			Page:   i / perPage,
+			X:      pageMargin,
+			Y:      top + float64(i%perPage)*(h+panelSpacing),
+			W:      contentWidth,
+			H:      h,
+			Width:  gridWidth,
+			Height: int(h * gridWidth / contentWidth),
+		})
+	}
+	return placements
+}
+
+// renderPDF renders the panels of the dashboard and composes them in a PDF.
+func (r *renderer) renderPDF(ctx context.Context, panels []panel) ([]byte, error) {
+	orientation := "P"
+	if r.report.Orientation == OrientationLandscape {
+		orientation = "L"
+	}
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.SetTitle(r.report.Name, true)
+	pdf.SetAutoPageBreak(false, pageMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+
+	page := -1
+	for _, pl := range layoutPanels(panels, r.report.Layout, r.report.Orientation, pageWidth, pageHeight) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for page < pl.Page {
+			pdf.AddPage()
+			page++
+			r.writeHeader(pdf, tr, pageWidth)
+		}
+
+		path, err := r.renderPanel(ctx, pl.Panel, pl.Width, pl.Height)
+		if err != nil {
+			return nil, err
+		}
+		pdf.ImageOptions(path, pl.X, pl.Y, pl.W, pl.H, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *renderer) writeHeader(pdf *gofpdf.Fpdf, tr func(string) string, pageWidth float64) {
+	pdf.SetXY(pageMargin, pageMargin)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(pageWidth-2*pageMargin, 7, tr(r.dash.Title), "", 1, "L", false, 0, "")
+	pdf.SetX(pageMargin)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(110, 110, 110)
+	pdf.CellFormat(pageWidth-2*pageMargin, 5, tr(fmt.Sprintf("%s to %s", r.report.TimeFrom, r.report.TimeTo)), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
@@ -0,0 +1,69 @@
+package reports
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestDashboardPanels(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 2, "title": "Latency", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A"}]},
+			{"id": 1, "title": "Availability", "type": "stat", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A"}]},
+			{"id": 3, "title": "Details", "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 8, "w": 24, "h": 1}, "panels": [
+				{"id": 4, "title": "Notes", "type": "text", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 4}}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []panel{
+		{ID: 1, Title: "Availability", X: 0, Y: 0, W: 12, H: 8, HasQueries: true},
+		{ID: 2, Title: "Latency", X: 12, Y: 0, W: 12, H: 8, HasQueries: true},
+		{ID: 4, Title: "Notes", X: 0, Y: 9, W: 24, H: 4},
+	}, dashboardPanels(data))
+}
+
+func TestLayoutPanels(t *testing.T) {
+	const pageWidth, pageHeight = 210.0, 297.0
+	panels := []panel{
+		{ID: 1, X: 0, Y: 0, W: 12, H: 8},
+		{ID: 2, X: 12, Y: 0, W: 12, H: 8},
+		{ID: 3, X: 0, Y: 8, W: 24, H: 8},
+		{ID: 4, X: 0, Y: 16, W: 24, H: 40},
+	}
+
+	pages := func(placements []placement) []int {
+		result := make([]int, 0, len(placements))
+		for _, p := range placements {
+			result = append(result, p.Page)
+		}
+		return result
+	}
+
+	t.Run("should place three panels per page in simple portrait layout", func(t *testing.T) {
+		placements := layoutPanels(panels, LayoutSimple, OrientationPortrait, pageWidth, pageHeight)
+		assert.Equal(t, []int{0, 0, 0, 1}, pages(placements))
+		for _, p := range placements {
+			assert.Equal(t, pageWidth-2*pageMargin, p.W)
+		}
+	})
+
+	t.Run("should place one panel per page in simple landscape layout", func(t *testing.T) {
+		placements := layoutPanels(panels, LayoutSimple, OrientationLandscape, pageHeight, pageWidth)
+		assert.Equal(t, []int{0, 1, 2, 3}, pages(placements))
+	})
+
+	t.Run("should keep the dashboard arrangement in grid layout", func(t *testing.T) {
+		placements := layoutPanels(panels, LayoutGrid, OrientationPortrait, pageWidth, pageHeight)
+		assert.Equal(t, []int{0, 0, 0, 1}, pages(placements))
+		assert.Equal(t, placements[0].Y, placements[1].Y)
+		assert.Greater(t, placements[1].X, placements[0].X)
+		assert.Greater(t, placements[2].Y, placements[0].Y)
+		assert.Equal(t, pageMargin+headerHeight, placements[3].Y)
+	})
+}
@@ -0,0 +1,252 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+)
+
+const (
+	renderTimeout = time.Minute
+	// gridColumns is the number of columns of the dashboard grid.
+	gridColumns = 24
+	// gridWidth is the width in pixels the dashboard grid is rendered with.
+	gridWidth = 1200
+	// gridRowHeight is the height in pixels of a row of the dashboard grid, including the margin between panels.
+	gridRowHeight = 38
+)
+
+// panel is a panel of a report dashboard, with its position on the grid.
+type panel struct {
+	ID         int64
+	Title      string
+	X, Y, W, H int
+	HasQueries bool
+}
+
+// dashboardPanels returns the panels of a dashboard in reading order, including the panels of collapsed rows but not
+// the rows themselves.
+func dashboardPanels(data *simplejson.Json) []panel {
+	panels := make([]panel, 0)
+
+	var visit func(list []interface{})
+	visit = func(list []interface{}) {
+		for _, item := range list {
+			p := simplejson.NewFromAny(item)
+			if p.Get("type").MustString() == "row" {
+				visit(p.Get("panels").MustArray())
+				continue
+			}
+
+			gridPos := p.Get("gridPos")
+			panels = append(panels, panel{
+				ID:         p.Get("id").MustInt64(),
+				Title:      p.Get("title").MustString(),
+				X:          gridPos.Get("x").MustInt(),
+				Y:          gridPos.Get("y").MustInt(),
+				W:          gridPos.Get("w").MustInt(gridColumns),
+				H:          gridPos.Get("h").MustInt(8),
+				HasQueries: len(p.Get("targets").MustArray()) > 0,
+			})
+		}
+	}
+	visit(data.Get("panels").MustArray())
+
+	sort.SliceStable(panels, func(i, j int) bool {
+		if panels[i].Y != panels[j].Y {
+			return panels[i].Y < panels[j].Y
+		}
+		return panels[i].X < panels[j].X
+	})
+	return panels
+}
+
+// renderer renders the dashboard of a report with the permissions of the report owner.
+type renderer struct {
+	service  rendering.Service
+	session  rendering.Session
+	authOpts rendering.AuthOpts
+	report   *Report
+	dash     *models.Dashboard
+	limit    int
+}
+
+// query returns the query string of the dashboard URLs of a report.
+func (r *renderer) query(extra url.Values) string {
+	values := url.Values{}
+	values.Set("orgId", strconv.FormatInt(r.report.OrgID, 10))
+	values.Set("from", r.report.TimeFrom)
+	values.Set("to", r.report.TimeTo)
+	for name, vals := range r.report.Variables {
+		for _, v := range vals {
+			values.Add("var-"+name, v)
+		}
+	}
+	for key, vals := range extra {
+		values[key] = vals
+	}
+	return values.Encode()
+}
+
+func (r *renderer) timezone() string {
+	if r.report.Timezone != "" {
+		return r.report.Timezone
+	}
+	return r.dash.Data.Get("timezone").MustString()
+}
+
+// renderPanel renders a panel to a PNG file.
+func (r *renderer) renderPanel(ctx context.Context, p panel, width, height int) (string, error) {
+	result, err := r.service.Render(ctx, rendering.Opts{
+		TimeoutOpts:       rendering.TimeoutOpts{Timeout: renderTimeout},
+		AuthOpts:          r.authOpts,
+		Width:             width,
+		Height:            height,
+		Path:              fmt.Sprintf("d-solo/%s/%s?%s", r.dash.Uid, r.dash.Slug, r.query(url.Values{"panelId": {strconv.FormatInt(p.ID, 10)}})),
+		Timezone:          r.timezone(),
+		ConcurrentLimit:   r.limit,
+		DeviceScaleFactor: 2,
+		Theme:             rendering.ThemeLight,
+	}, r.session)
+	if err != nil {
+		return "", fmt.Errorf("failed to render panel %d: %w", p.ID, err)
+	}
+	return result.FilePath, nil
+}
+
+// renderDashboard renders the whole dashboard to a PNG file.
+func (r *renderer) renderDashboard(ctx context.Context, panels []panel) (string, error) {
+	rows := 0
+	for _, p := range panels {
+		if p.Y+p.H > rows {
+			rows = p.Y + p.H
+		}
+	}
+
+	result, err := r.service.Render(ctx, rendering.Opts{
+		TimeoutOpts:       rendering.TimeoutOpts{Timeout: renderTimeout},
+		AuthOpts:          r.authOpts,
+		Width:             gridWidth,
+		Height:            rows*gridRowHeight + 2*gridRowHeight,
+		Path:              fmt.Sprintf("d/%s/%s?%s", r.dash.Uid, r.dash.Slug, r.query(url.Values{"kiosk": {""}})),
+		Timezone:          r.timezone(),
+		ConcurrentLimit:   r.limit,
+		DeviceScaleFactor: 1,
+		Theme:             rendering.ThemeLight,
+	}, r.session)
+	if err != nil {
+		return "", fmt.Errorf("failed to render dashboard: %w", err)
+	}
+	return result.FilePath, nil
+}
+
+// renderCSV exports the data of a panel to a CSV file.
+func (r *renderer) renderCSV(ctx context.Context, p panel) (string, error) {
+	result, err := r.service.RenderCSV(ctx, rendering.CSVOpts{
+		TimeoutOpts:     rendering.TimeoutOpts{Timeout: renderTimeout},
+		AuthOpts:        r.authOpts,
+		Path:            fmt.Sprintf("d-csv/%s?%s", r.dash.Uid, r.query(url.Values{"panelId": {strconv.FormatInt(p.ID, 10)}})),
+		Timezone:        r.timezone(),
+		ConcurrentLimit: r.limit,
+	}, r.session)
+	if err != nil {
+		return "", fmt.Errorf("failed to export data of panel %d: %w", p.ID, err)
+	}
+	return result.FilePath, nil
+}
+
+// attachment is a file attached to a report email.
+type attachment struct {
+	Name    string
+	Content []byte
+}
+
+// renderAttachments renders the dashboard of a report to the formats of the report.
+func (r *renderer) renderAttachments(ctx context.Context) ([]attachment, error) {
+	panels := dashboardPanels(r.dash.Data)
+	if len(panels) == 0 {
+		return nil, ErrNoPanelsToRender
+	}
+
+	name := fileName(r.report.Name)
+	if name == "" {
+		name = "report"
+	}
+	attachments := make([]attachment, 0)
+	for _, format := range r.report.Formats {
+		switch format {
+		case FormatPDF:
+			content, err := r.renderPDF(ctx, panels)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, attachment{Name: name + ".pdf", Content: content})
+		case FormatPNG:
+			path, err := r.renderDashboard(ctx, panels)
+			if err != nil {
+				return nil, err
+			}
+			content, err := readRendered(path)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, attachment{Name: name + ".png", Content: content})
+		case FormatCSV:
+			for _, p := range panels {
+				if !p.HasQueries {
+					continue
+				}
+				path, err := r.renderCSV(ctx, p)
+				if err != nil {
+					return nil, err
+				}
+				content, err := readRendered(path)
+				if err != nil {
+					return nil, err
+				}
+				panelName := fileName(p.Title)
+				if panelName == "" {
+					panelName = "panel-" + strconv.FormatInt(p.ID, 10)
+				}
+				attachments = append(attachments, attachment{Name: name + "-" + panelName + ".csv", Content: content})
+			}
+		}
+	}
+	return attachments, nil
+}
+
+// readRendered reads and removes a file written by the rendering service.
+func readRendered(path string) ([]byte, error) {
+	// nolint:gosec
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	return content, nil
+}
+
+// fileName returns a name usable in attachment file names.
+func fileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(name))
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	return strings.Trim(name, "-")
+}
@@ -0,0 +1,87 @@
+package reports
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/robfig/cron/v3"
+)
+
+// minInterval is the shortest interval between two runs of a report, the frequency at which due reports are checked.
+const minInterval = time.Minute
+
+// Validate checks that a schedule has at most one of a cron expression and an interval, and that they are valid.
+func (s Schedule) Validate() error {
+	if s.Cron != "" && s.Interval != "" {
+		return fmt.Errorf("%w: a schedule has either a cron expression or an interval", ErrInvalidReport)
+	}
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidReport, s.Timezone)
+	}
+	if s.Cron != "" {
+		if _, err := cron.ParseStandard(s.Cron); err != nil {
+			return fmt.Errorf("%w: invalid cron expression %q: %s", ErrInvalidReport, s.Cron, err)
+		}
+	}
+	if s.Interval != "" {
+		interval, err := gtime.ParseDuration(s.Interval)
+		if err != nil {
+			return fmt.Errorf("%w: invalid interval %q", ErrInvalidReport, s.Interval)
+		}
+		if interval < minInterval {
+			return fmt.Errorf("%w: interval must be at least %s", ErrInvalidReport, minInterval)
+		}
+	}
+	return nil
+}
+
+// IsZero is true for reports that are only sent on demand.
+func (s Schedule) IsZero() bool {
+	return s.Cron == "" && s.Interval == ""
+}
+
+// Next returns the first time after now a report with the schedule is due, given the time the previous run was due,
+// or the zero time when the report is only sent on demand. Runs missed while no instance was running are skipped.
+func (s Schedule) Next(prev, now time.Time) (time.Time, error) {
+	switch {
+	case s.Cron != "":
+		loc, err := s.location()
+		if err != nil {
+			return time.Time{}, err
+		}
+		spec, err := cron.ParseStandard(s.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return spec.Next(now.In(loc)), nil
+	case s.Interval != "":
+		interval, err := gtime.ParseDuration(s.Interval)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if interval < minInterval {
+			interval = minInterval
+		}
+		if prev.IsZero() {
+			return now.Add(interval), nil
+		}
+		next := prev.Add(interval)
+		if !next.After(now) {
+			missed := now.Sub(next)/interval + 1
+			next = next.Add(missed * interval)
+		}
+		return next, nil
+	}
+	return time.Time{}, nil
+}
+
+func (s Schedule) location() (*time.Location, error) {
+	switch s.Timezone {
+	case "":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
@@ -0,0 +1,71 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	// a Saturday
+	now := time.Date(2021, 11, 20, 10, 30, 0, 0, time.UTC)
+
+	t.Run("should run a cron schedule in its timezone", func(t *testing.T) {
+		s := Schedule{Cron: "0 8 * * 1", Timezone: "Europe/Paris"}
+		require.NoError(t, s.Validate())
+
+		next, err := s.Next(time.Time{}, now)
+		require.NoError(t, err)
+		assert.True(t, next.Equal(time.Date(2021, 11, 22, 8, 0, 0, 0, paris)), next.String())
+
+		next, err = s.Next(next, next)
+		require.NoError(t, err)
+		assert.True(t, next.Equal(time.Date(2021, 11, 29, 8, 0, 0, 0, paris)), next.String())
+	})
+
+	t.Run("should run an interval schedule from the previous run", func(t *testing.T) {
+		s := Schedule{Interval: "1d"}
+		require.NoError(t, s.Validate())
+
+		next, err := s.Next(time.Time{}, now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(24*time.Hour), next)
+
+		next, err = s.Next(now.Add(-time.Hour), now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(23*time.Hour), next)
+	})
+
+	t.Run("should skip the runs missed by an interval schedule", func(t *testing.T) {
+		s := Schedule{Interval: "1h"}
+		next, err := s.Next(now.Add(-150*time.Minute), now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(30*time.Minute), next)
+	})
+
+	t.Run("should not schedule reports sent on demand", func(t *testing.T) {
+		s := Schedule{}
+		require.NoError(t, s.Validate())
+		assert.True(t, s.IsZero())
+
+		next, err := s.Next(time.Time{}, now)
+		require.NoError(t, err)
+		assert.True(t, next.IsZero())
+	})
+
+	t.Run("should reject invalid schedules", func(t *testing.T) {
+		for _, s := range []Schedule{
+			{Cron: "0 8 * * 1", Interval: "1d"},
+			{Cron: "every monday"},
+			{Interval: "weekly"},
+			{Interval: "10s"},
+			{Cron: "0 8 * * 1", Timezone: "Mars/Olympus"},
+		} {
+			assert.ErrorIs(t, s.Validate(), ErrInvalidReport, s)
+		}
+	})
+}
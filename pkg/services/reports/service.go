@@ -0,0 +1,454 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// processInterval is how often due reports and pending runs are processed.
+	processInterval = time.Minute
+	// staleRunTimeout is how long a run can be running before it is considered lost.
+	staleRunTimeout = time.Hour
+	// renderSessionExpiry is how long the rendering session of a run lasts without being used.
+	renderSessionExpiry = 5 * time.Minute
+	reportTemplate      = "report"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, bus bus.Bus, routeRegister routing.RouteRegister,
+	renderService rendering.Service, serverLockService *serverlock.ServerLockService) *Service {
+	s := &Service{
+		cfg:           cfg,
+		sqlStore:      sqlStore,
+		bus:           bus,
+		renderService: renderService,
+		serverLock:    serverLockService,
+		log:           log.New("reports"),
+		now:           time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Service manages dashboard reports and sends them on their schedule. Due reports are processed by one instance at a
+// time, guarded by a server lock.
+type Service struct {
+	cfg           *setting.Cfg
+	sqlStore      *sqlstore.SQLStore
+	bus           bus.Bus
+	renderService rendering.Service
+	serverLock    *serverlock.ServerLockService
+	log           log.Logger
+	now           func() time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.DashboardReportsEnabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(processInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// the lock interval is shorter than the ticker so that no tick is skipped because of clock drift
+			err := s.serverLock.LockAndExecute(ctx, "send scheduled reports", processInterval-10*time.Second, s.processDue)
+			if err != nil {
+				s.log.Error("Failed to lock and process scheduled reports", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// processDue queues a run for every due report, then sends the pending runs that are due, new runs and retries.
+func (s *Service) processDue(ctx context.Context) {
+	now := s.now()
+	stale, err := s.failStaleRuns(ctx, now.Add(-staleRunTimeout))
+	if err != nil {
+		s.log.Error("Failed to fail stale report runs", "error", err)
+	}
+	for _, run := range stale {
+		s.log.Warn("Report run did not finish", "report", run.ReportID, "attempt", run.Attempt)
+		s.queueRetry(ctx, run)
+	}
+
+	due, err := s.getDueReports(ctx, now)
+	if err != nil {
+		s.log.Error("Failed to get due reports", "error", err)
+		return
+	}
+	for _, report := range due {
+		next, err := report.Schedule().Next(time.Unix(report.NextRun, 0), now)
+		if err != nil {
+			s.log.Error("Failed to compute next run of report", "report", report.UID, "error", err)
+			continue
+		}
+		var nextRun int64
+		if !next.IsZero() {
+			nextRun = next.Unix()
+		}
+		if err := s.scheduleRun(ctx, report, nextRun); err != nil {
+			s.log.Error("Failed to schedule report run", "report", report.UID, "error", err)
+		}
+	}
+
+	// runs are claimed one at a time so that a run is never left waiting as running while others are sent
+	for ctx.Err() == nil {
+		run, err := s.claimNextRun(ctx, now)
+		if err != nil {
+			s.log.Error("Failed to claim pending report run", "error", err)
+			return
+		}
+		if run == nil {
+			return
+		}
+		s.execute(ctx, run)
+	}
+}
+
+// execute sends the report of a running run, records its outcome and queues the next attempt when it failed. The
+// report is sent to the recipients of the run, or else to its own.
+func (s *Service) execute(ctx context.Context, run *ReportRun) {
+	report, err := s.getReportByID(ctx, run.ReportID)
+	if err == nil {
+		recipients := run.Recipients
+		if len(recipients) == 0 {
+			recipients = report.Recipients
+		}
+		err = s.send(ctx, report, recipients)
+	}
+
+	finished := s.now()
+	run.Finished = &finished
+	if err != nil {
+		s.log.Warn("Failed to send report", "report", run.ReportID, "attempt", run.Attempt, "error", err)
+		run.State = StateFailed
+		run.Error = err.Error()
+	} else {
+		run.State = StateSuccess
+		run.Error = ""
+	}
+	recorded, saveErr := s.finishRun(ctx, run)
+	if saveErr != nil {
+		s.log.Error("Failed to save report run", "report", run.ReportID, "error", saveErr)
+		return
+	}
+	if !recorded {
+		// the run was failed as stale and its retry queued already
+		s.log.Warn("Report run finished after it was failed as stale", "report", run.ReportID, "attempt", run.Attempt)
+		return
+	}
+
+	if run.State == StateFailed && !errors.Is(err, ErrReportNotFound) {
+		s.queueRetry(ctx, run)
+	}
+}
+
+// queueRetry queues the next attempt of a failed run, unless it was the last one.
+func (s *Service) queueRetry(ctx context.Context, run *ReportRun) {
+	if run.Attempt >= s.cfg.DashboardReportsMaxAttempts {
+		return
+	}
+	retry := &ReportRun{
+		OrgID:       run.OrgID,
+		ReportID:    run.ReportID,
+		Trigger:     run.Trigger,
+		Attempt:     run.Attempt + 1,
+		State:       StatePending,
+		Recipients:  run.Recipients,
+		ScheduledAt: run.Finished.Add(s.cfg.DashboardReportsRetryInterval).Unix(),
+		Created:     *run.Finished,
+	}
+	if err := s.insertRun(ctx, retry); err != nil {
+		s.log.Error("Failed to queue report retry", "report", run.ReportID, "error", err)
+	}
+}
+
+// send renders a report with the permissions of its owner and emails it.
+func (s *Service) send(ctx context.Context, report *Report, recipients []string) error {
+	if len(recipients) == 0 {
+		return ErrNoRecipients
+	}
+	if !s.renderService.IsAvailable() {
+		return ErrRendererRequired
+	}
+
+	dash, err := s.sqlStore.GetDashboard(0, report.OrgID, report.DashboardUID, "")
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return ErrDashboardNotFound
+		}
+		return err
+	}
+
+	query := &models.GetSignedInUserQuery{OrgId: report.OrgID, UserId: report.UserID}
+	if err := s.sqlStore.GetSignedInUserWithCacheCtx(ctx, query); err != nil {
+		return fmt.Errorf("failed to get report owner: %w", err)
+	}
+	owner := query.Result
+	if canView, err := guardian.New(ctx, dash.Id, report.OrgID, owner).CanView(); err != nil {
+		return err
+	} else if !canView {
+		return ErrReportAccessDenied
+	}
+
+	authOpts := rendering.AuthOpts{OrgID: report.OrgID, UserID: owner.UserId, OrgRole: owner.OrgRole}
+	session, err := s.renderService.CreateRenderingSession(ctx, authOpts, rendering.SessionOpts{
+		Expiry:                     renderSessionExpiry,
+		RefreshExpiryOnEachRequest: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create rendering session: %w", err)
+	}
+	defer session.Dispose(ctx)
+
+	r := &renderer{
+		service:  s.renderService,
+		session:  session,
+		authOpts: authOpts,
+		report:   report,
+		dash:     dash,
+		limit:    s.cfg.RendererConcurrentRequestLimit,
+	}
+	attachments, err := r.renderAttachments(ctx)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*models.SendEmailAttachFile, 0, len(attachments))
+	for _, a := range attachments {
+		files = append(files, &models.SendEmailAttachFile{Name: a.Name, Content: a.Content})
+	}
+	cmd := &models.SendEmailCommandSync{
+		SendEmailCommand: models.SendEmailCommand{
+			To:       recipients,
+			Template: reportTemplate,
+			Subject:  report.Name,
+			Data: map[string]interface{}{
+				"Name":           report.Name,
+				"Message":        report.Message,
+				"DashboardTitle": dash.Title,
+				"DashboardURL":   models.GetFullDashboardUrl(dash.Uid, dash.Slug) + "?" + r.query(nil),
+				"TimeFrom":       report.TimeFrom,
+				"TimeTo":         report.TimeTo,
+			},
+			AttachedFiles: files,
+		},
+	}
+	if report.ReplyTo != "" {
+		cmd.ReplyTo = []string{report.ReplyTo}
+	}
+	return s.bus.Dispatch(ctx, cmd)
+}
+
+// SendReport sends a report on demand and waits for it to be sent. The run is retried like scheduled runs when it
+// fails.
+func (s *Service) SendReport(ctx context.Context, orgID int64, uid string, cmd SendReportCommand) (*ReportRun, error) {
+	report, err := s.getReport(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEmails(cmd.Recipients); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	run := &ReportRun{
+		OrgID:       report.OrgID,
+		ReportID:    report.ID,
+		Trigger:     TriggerManual,
+		Attempt:     1,
+		State:       StateRunning,
+		Recipients:  cmd.Recipients,
+		ScheduledAt: now.Unix(),
+		Started:     &now,
+		Created:     now,
+	}
+	if err := s.insertRun(ctx, run); err != nil {
+		return nil, err
+	}
+	s.execute(ctx, run)
+	return run, nil
+}
+
+// CreateReport creates a report owned by the signed in user.
+func (s *Service) CreateReport(ctx context.Context, user *models.SignedInUser, cmd SaveReportCommand) (*Report, error) {
+	now := s.now()
+	report := &Report{
+		OrgID:   user.OrgId,
+		UID:     cmd.UID,
+		Enabled: true,
+		Created: now,
+	}
+	if report.UID == "" {
+		report.UID = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(report.UID) {
+		return nil, fmt.Errorf("%w: invalid uid", ErrInvalidReport)
+	}
+	if err := s.apply(ctx, user, report, cmd, now); err != nil {
+		return nil, err
+	}
+	if err := s.insertReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// UpdateReport replaces the definition of a report. The signed in user becomes its owner.
+func (s *Service) UpdateReport(ctx context.Context, user *models.SignedInUser, uid string, cmd SaveReportCommand) (*Report, error) {
+	report, err := s.getReport(ctx, user.OrgId, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, user, report, cmd, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.updateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// apply validates a save command and sets it on a report, computing when the report is next due.
+func (s *Service) apply(ctx context.Context, user *models.SignedInUser, report *Report, cmd SaveReportCommand, now time.Time) error {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if cmd.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidReport)
+	}
+	if cmd.DashboardUID == "" {
+		return fmt.Errorf("%w: dashboard uid is required", ErrInvalidReport)
+	}
+	if cmd.TimeFrom == "" {
+		cmd.TimeFrom = "now-7d"
+	}
+	if cmd.TimeTo == "" {
+		cmd.TimeTo = "now"
+	}
+	switch cmd.Layout {
+	case "":
+		cmd.Layout = LayoutSimple
+	case LayoutSimple, LayoutGrid:
+	default:
+		return fmt.Errorf("%w: unknown layout %q", ErrInvalidReport, cmd.Layout)
+	}
+	switch cmd.Orientation {
+	case "":
+		cmd.Orientation = OrientationPortrait
+	case OrientationPortrait, OrientationLandscape:
+	default:
+		return fmt.Errorf("%w: unknown orientation %q", ErrInvalidReport, cmd.Orientation)
+	}
+	formats := make([]Format, 0, len(cmd.Formats))
+	for _, format := range cmd.Formats {
+		switch format {
+		case FormatPDF, FormatPNG, FormatCSV:
+		default:
+			return fmt.Errorf("%w: unknown format %q", ErrInvalidReport, format)
+		}
+		if !containsFormat(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		formats = append(formats, FormatPDF)
+	}
+	if len(cmd.Recipients) == 0 {
+		return fmt.Errorf("%w: at least one recipient is required", ErrInvalidReport)
+	}
+	if err := validateEmails(cmd.Recipients); err != nil {
+		return err
+	}
+	if cmd.ReplyTo != "" {
+		if err := validateEmails([]string{cmd.ReplyTo}); err != nil {
+			return err
+		}
+	}
+	if err := cmd.Schedule.Validate(); err != nil {
+		return err
+	}
+	if cmd.Variables == nil {
+		cmd.Variables = make(map[string][]string)
+	}
+
+	dash, err := s.sqlStore.GetDashboard(0, user.OrgId, cmd.DashboardUID, "")
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			return ErrDashboardNotFound
+		}
+		return err
+	}
+	if canView, err := guardian.New(ctx, dash.Id, user.OrgId, user).CanView(); err != nil {
+		return err
+	} else if !canView {
+		return ErrReportAccessDenied
+	}
+
+	scheduleChanged := report.Schedule() != cmd.Schedule || (cmd.Enabled != nil && *cmd.Enabled != report.Enabled)
+	if cmd.Enabled != nil {
+		report.Enabled = *cmd.Enabled
+	}
+	if scheduleChanged || report.NextRun == 0 {
+		next, err := cmd.Schedule.Next(time.Time{}, now)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidReport, err)
+		}
+		report.NextRun = 0
+		if !next.IsZero() {
+			report.NextRun = next.Unix()
+		}
+	}
+
+	report.Name = cmd.Name
+	report.UserID = user.UserId
+	report.DashboardUID = dash.Uid
+	report.Variables = cmd.Variables
+	report.TimeFrom = cmd.TimeFrom
+	report.TimeTo = cmd.TimeTo
+	report.Layout = cmd.Layout
+	report.Orientation = cmd.Orientation
+	report.Formats = formats
+	report.ScheduleCron = cmd.Schedule.Cron
+	report.ScheduleInterval = cmd.Schedule.Interval
+	report.Timezone = cmd.Schedule.Timezone
+	report.Recipients = cmd.Recipients
+	report.ReplyTo = cmd.ReplyTo
+	report.Message = cmd.Message
+	report.Updated = now
+	return nil
+}
+
+func validateEmails(emails []string) error {
+	for _, email := range emails {
+		if !util.IsEmail(email) {
+			return fmt.Errorf("%w: invalid email %q", ErrInvalidReport, email)
+		}
+	}
+	return nil
+}
+
+func containsFormat(formats []Format, format Format) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestReports(t *testing.T) {
+	ctx := context.Background()
+	sqlStore := sqlstore.InitTestDB(t)
+	now := time.Date(2021, 11, 20, 10, 30, 0, 0, time.UTC)
+
+	owner, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "manager", Email: "manager@example.com"})
+	require.NoError(t, err)
+	user := &models.SignedInUser{UserId: owner.Id, OrgId: owner.OrgId, OrgRole: models.ROLE_ADMIN}
+
+	dash, err := simplejson.NewJson([]byte(`{
+		"uid": "slo",
+		"title": "SLO",
+		"panels": [
+			{"id": 1, "title": "Availability", "type": "stat", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A"}]},
+			{"id": 2, "title": "Error budget", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A"}]},
+			{"id": 3, "title": "About", "type": "text", "gridPos": {"x": 0, "y": 8, "w": 24, "h": 4}}
+		]
+	}`))
+	require.NoError(t, err)
+	_, err = sqlStore.SaveDashboard(models.SaveDashboardCommand{OrgId: owner.OrgId, Dashboard: dash})
+	require.NoError(t, err)
+
+	renderer := &fakeRenderService{dir: t.TempDir()}
+	emails := make([]*models.SendEmailCommandSync, 0)
+	b := bus.New()
+	b.AddHandler(func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+		emails = append(emails, cmd)
+		return nil
+	})
+
+	cfg := setting.NewCfg()
+	cfg.DashboardReportsEnabled = true
+	cfg.DashboardReportsMaxAttempts = 2
+	cfg.DashboardReportsRetryInterval = 5 * time.Minute
+	s := &Service{
+		cfg:           cfg,
+		sqlStore:      sqlStore,
+		bus:           b,
+		renderService: renderer,
+		log:           log.New("reports.test"),
+		now:           func() time.Time { return now },
+	}
+
+	report, err := s.CreateReport(ctx, user, SaveReportCommand{
+		Name:         "Weekly SLO",
+		DashboardUID: "slo",
+		Variables:    map[string][]string{"service": {"api", "web"}},
+		Formats:      []Format{FormatPDF, FormatCSV, FormatPDF},
+		Schedule:     Schedule{Interval: "1w"},
+		Recipients:   []string{"managers@example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Format{FormatPDF, FormatCSV}, report.Formats)
+	assert.Equal(t, now.Add(7*24*time.Hour).Unix(), report.NextRun)
+
+	t.Run("should validate reports", func(t *testing.T) {
+		_, err := s.CreateReport(ctx, user, SaveReportCommand{Name: "Invalid", DashboardUID: "slo", Recipients: []string{"not an email"}})
+		require.ErrorIs(t, err, ErrInvalidReport)
+
+		_, err = s.CreateReport(ctx, user, SaveReportCommand{Name: "Invalid", DashboardUID: "missing", Recipients: []string{"a@example.com"}})
+		require.ErrorIs(t, err, ErrDashboardNotFound)
+
+		_, err = s.CreateReport(ctx, user, SaveReportCommand{UID: report.UID, Name: "Copy", DashboardUID: "slo", Recipients: []string{"a@example.com"}})
+		require.ErrorIs(t, err, ErrReportUIDExists)
+	})
+
+	t.Run("should send a report on demand", func(t *testing.T) {
+		run, err := s.SendReport(ctx, owner.OrgId, report.UID, SendReportCommand{})
+		require.NoError(t, err)
+		assert.Equal(t, StateSuccess, run.State, run.Error)
+
+		require.Len(t, emails, 1)
+		assert.Equal(t, []string{"managers@example.com"}, emails[0].To)
+		assert.Equal(t, reportTemplate, emails[0].Template)
+		names := make([]string, 0)
+		for _, f := range emails[0].AttachedFiles {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"Weekly-SLO.pdf", "Weekly-SLO-Availability.csv", "Weekly-SLO-Error-budget.csv"}, names)
+		assert.Equal(t, "%PDF", string(emails[0].AttachedFiles[0].Content[:4]))
+
+		assert.Contains(t, renderer.paths, "d-solo/slo/slo?from=now-7d&orgId=1&panelId=3&to=now&var-service=api&var-service=web")
+		assert.Equal(t, owner.Id, renderer.userID)
+	})
+
+	t.Run("should send due reports once and retry failed runs", func(t *testing.T) {
+		emails = emails[:0]
+		now = now.Add(7 * 24 * time.Hour)
+		renderer.err = errors.New("renderer is down")
+
+		s.processDue(ctx)
+		s.processDue(ctx)
+		assert.Empty(t, emails)
+
+		runs, err := s.getRuns(ctx, report.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 3)
+		assert.Equal(t, StatePending, runs[0].State)
+		assert.Equal(t, 2, runs[0].Attempt)
+		assert.Equal(t, StateFailed, runs[1].State)
+		assert.Contains(t, runs[1].Error, "renderer is down")
+		assert.Equal(t, TriggerSchedule, runs[1].Trigger)
+
+		updated, err := s.getReport(ctx, owner.OrgId, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(7*24*time.Hour).Unix(), updated.NextRun)
+
+		now = now.Add(5 * time.Minute)
+		renderer.err = nil
+		s.processDue(ctx)
+		require.Len(t, emails, 1)
+
+		runs, err = s.getRuns(ctx, report.ID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, StateSuccess, runs[0].State)
+		assert.Equal(t, 2, runs[0].Attempt)
+	})
+
+	t.Run("should retry a run sent on demand with its recipients", func(t *testing.T) {
+		emails = emails[:0]
+		renderer.err = errors.New("renderer is down")
+		run, err := s.SendReport(ctx, owner.OrgId, report.UID, SendReportCommand{Recipients: []string{"me@example.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, StateFailed, run.State)
+
+		now = now.Add(5 * time.Minute)
+		renderer.err = nil
+		s.processDue(ctx)
+		require.Len(t, emails, 1)
+		assert.Equal(t, []string{"me@example.com"}, emails[0].To)
+
+		runs, err := s.getRuns(ctx, report.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, StateSuccess, runs[0].State)
+		assert.Equal(t, []string{"me@example.com"}, runs[0].Recipients)
+	})
+
+	t.Run("should stop retrying after the last attempt", func(t *testing.T) {
+		renderer.err = errors.New("renderer is down")
+		run, err := s.SendReport(ctx, owner.OrgId, report.UID, SendReportCommand{Recipients: []string{"me@example.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, StateFailed, run.State)
+
+		now = now.Add(5 * time.Minute)
+		s.processDue(ctx)
+
+		runs, err := s.getRuns(ctx, report.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, StateFailed, runs[0].State)
+		assert.Equal(t, 2, runs[0].Attempt)
+		assert.Equal(t, TriggerManual, runs[0].Trigger)
+
+		lastRuns, err := s.getLastRuns(ctx, owner.OrgId)
+		require.NoError(t, err)
+		assert.Equal(t, runs[0].ID, lastRuns[report.ID].ID)
+	})
+
+	t.Run("should retry stale runs and ignore their late outcome", func(t *testing.T) {
+		emails = emails[:0]
+		renderer.err = nil
+		started := now.Add(-2 * staleRunTimeout)
+		stale := &ReportRun{
+			OrgID:       owner.OrgId,
+			ReportID:    report.ID,
+			Trigger:     TriggerSchedule,
+			Attempt:     1,
+			State:       StateRunning,
+			ScheduledAt: started.Unix(),
+			Started:     &started,
+			Created:     started,
+		}
+		require.NoError(t, s.insertRun(ctx, stale))
+
+		s.processDue(ctx)
+		assert.Empty(t, emails)
+
+		runs, err := s.getRuns(ctx, report.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, StatePending, runs[0].State)
+		assert.Equal(t, 2, runs[0].Attempt)
+		assert.Equal(t, stale.ID, runs[1].ID)
+		assert.Equal(t, StateFailed, runs[1].State)
+
+		finished := now
+		stale.State, stale.Finished = StateSuccess, &finished
+		recorded, err := s.finishRun(ctx, stale)
+		require.NoError(t, err)
+		assert.False(t, recorded)
+
+		now = now.Add(5 * time.Minute)
+		s.processDue(ctx)
+		require.Len(t, emails, 1)
+
+		runs, err = s.getRuns(ctx, report.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, StateSuccess, runs[0].State)
+		assert.Equal(t, StateFailed, runs[1].State)
+	})
+
+	t.Run("should delete a report with its runs", func(t *testing.T) {
+		require.NoError(t, s.deleteReport(ctx, owner.OrgId, report.UID))
+		_, err := s.getReport(ctx, owner.OrgId, report.UID)
+		require.ErrorIs(t, err, ErrReportNotFound)
+
+		runs, err := s.getRuns(ctx, report.ID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, runs)
+	})
+}
+
+type fakeRenderService struct {
+	rendering.Service
+	dir    string
+	err    error
+	paths  []string
+	userID int64
+}
+
+func (s *fakeRenderService) IsAvailable() bool {
+	return true
+}
+
+func (s *fakeRenderService) Render(ctx context.Context, opts rendering.Opts, session rendering.Session) (*rendering.RenderResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.paths = append(s.paths, opts.Path)
+	s.userID = opts.UserID
+
+	path := filepath.Join(s.dir, "panel.png")
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: path}, nil
+}
+
+func (s *fakeRenderService) RenderCSV(ctx context.Context, opts rendering.CSVOpts, session rendering.Session) (*rendering.RenderCSVResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	path := filepath.Join(s.dir, "panel.csv")
+	if err := os.WriteFile(path, []byte("time,value\n"), 0600); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderCSVResult{FilePath: path}, nil
+}
+
+func (s *fakeRenderService) CreateRenderingSession(ctx context.Context, authOpts rendering.AuthOpts, sessionOpts rendering.SessionOpts) (rendering.Session, error) {
+	return &fakeSession{}, nil
+}
+
+type fakeSession struct {
+	rendering.Session
+}
+
+func (s *fakeSession) Dispose(ctx context.Context) {}
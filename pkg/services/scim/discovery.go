@@ -0,0 +1,198 @@
+package scim
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type schemaAttribute struct {
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	MultiValued   bool              `json:"multiValued"`
+	Required      bool              `json:"required"`
+	CaseExact     bool              `json:"caseExact"`
+	Mutability    string            `json:"mutability"`
+	Returned      string            `json:"returned"`
+	Uniqueness    string            `json:"uniqueness"`
+	SubAttributes []schemaAttribute `json:"subAttributes,omitempty"`
+}
+
+type schema struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Attributes  []schemaAttribute `json:"attributes"`
+	Meta        *Meta             `json:"meta,omitempty"`
+}
+
+type resourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+type supported struct {
+	Supported bool `json:"supported"`
+}
+
+type filterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type authenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+type serviceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 supported              `json:"patch"`
+	Bulk                  supported              `json:"bulk"`
+	Filter                filterSupport          `json:"filter"`
+	ChangePassword        supported              `json:"changePassword"`
+	Sort                  supported              `json:"sort"`
+	ETag                  supported              `json:"etag"`
+	AuthenticationSchemes []authenticationScheme `json:"authenticationSchemes"`
+}
+
+func attr(name, typ string) schemaAttribute {
+	return schemaAttribute{Name: name, Type: typ, Mutability: "readWrite", Returned: "default", Uniqueness: "none"}
+}
+
+func multiValuedReference() []schemaAttribute {
+	value := attr("value", "string")
+	value.Mutability = "immutable"
+	display := attr("display", "string")
+	display.Mutability = "readOnly"
+	return []schemaAttribute{value, display}
+}
+
+var schemas = []schema{
+	{
+		Schemas:     []string{SchemaSchema},
+		ID:          SchemaUser,
+		Name:        "User",
+		Description: "Grafana user",
+		Attributes: func() []schemaAttribute {
+			userName := attr("userName", "string")
+			userName.Required = true
+			userName.Uniqueness = "server"
+			name := attr("name", "complex")
+			name.SubAttributes = []schemaAttribute{attr("formatted", "string"), attr("givenName", "string"), attr("familyName", "string")}
+			emails := attr("emails", "complex")
+			emails.MultiValued = true
+			emails.SubAttributes = []schemaAttribute{attr("value", "string"), attr("type", "string"), attr("primary", "boolean")}
+			groups := attr("groups", "complex")
+			groups.MultiValued = true
+			groups.Mutability = "readOnly"
+			groups.SubAttributes = multiValuedReference()
+			return []schemaAttribute{userName, name, attr("displayName", "string"), emails, attr("active", "boolean"), groups}
+		}(),
+	},
+	{
+		Schemas:     []string{SchemaSchema},
+		ID:          SchemaGroup,
+		Name:        "Group",
+		Description: "Grafana team",
+		Attributes: func() []schemaAttribute {
+			displayName := attr("displayName", "string")
+			displayName.Required = true
+			displayName.Uniqueness = "server"
+			members := attr("members", "complex")
+			members.MultiValued = true
+			members.SubAttributes = multiValuedReference()
+			return []schemaAttribute{displayName, members}
+		}(),
+	},
+}
+
+var resourceTypes = []resourceType{
+	{Schemas: []string{SchemaResourceType}, ID: "User", Name: "User", Endpoint: "/Users", Description: "Grafana users", Schema: SchemaUser},
+	{Schemas: []string{SchemaResourceType}, ID: "Group", Name: "Group", Endpoint: "/Groups", Description: "Grafana teams", Schema: SchemaGroup},
+}
+
+// getServiceProviderConfig handles GET /api/scim/v2/ServiceProviderConfig.
+func (s *Service) getServiceProviderConfig(c *models.ReqContext) response.Response {
+	return scimResponse(http.StatusOK, serviceProviderConfig{
+		Schemas: []string{SchemaServiceProviderConfig},
+		Patch:   supported{Supported: true},
+		Filter:  filterSupport{Supported: true, MaxResults: maxCount},
+		AuthenticationSchemes: []authenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Service account token",
+			Description: "Authentication with the token of a Grafana service account with the Admin role",
+			Primary:     true,
+		}},
+	})
+}
+
+// getSchemas handles GET /api/scim/v2/Schemas.
+func (s *Service) getSchemas(c *models.ReqContext) response.Response {
+	result := ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: int64(len(schemas)),
+		StartIndex:   1,
+		ItemsPerPage: len(schemas),
+	}
+	for _, sch := range schemas {
+		result.Resources = append(result.Resources, s.withSchemaMeta(sch))
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+// getSchema handles GET /api/scim/v2/Schemas/:id.
+func (s *Service) getSchema(c *models.ReqContext) response.Response {
+	id := web.Params(c.Req)[":id"]
+	for _, sch := range schemas {
+		if sch.ID == id {
+			return scimResponse(http.StatusOK, s.withSchemaMeta(sch))
+		}
+	}
+	return errorResponse(http.StatusNotFound, "", "Schema not found")
+}
+
+// getResourceTypes handles GET /api/scim/v2/ResourceTypes.
+func (s *Service) getResourceTypes(c *models.ReqContext) response.Response {
+	result := ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: int64(len(resourceTypes)),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+	}
+	for _, rt := range resourceTypes {
+		result.Resources = append(result.Resources, s.withResourceTypeMeta(rt))
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+// getResourceType handles GET /api/scim/v2/ResourceTypes/:id.
+func (s *Service) getResourceType(c *models.ReqContext) response.Response {
+	id := web.Params(c.Req)[":id"]
+	for _, rt := range resourceTypes {
+		if rt.ID == id {
+			return scimResponse(http.StatusOK, s.withResourceTypeMeta(rt))
+		}
+	}
+	return errorResponse(http.StatusNotFound, "", "Resource type not found")
+}
+
+func (s *Service) withSchemaMeta(sch schema) schema {
+	sch.Meta = &Meta{ResourceType: "Schema", Location: s.location("Schemas", sch.ID)}
+	return sch
+}
+
+func (s *Service) withResourceTypeMeta(rt resourceType) resourceType {
+	rt.Meta = &Meta{ResourceType: "ResourceType", Location: s.location("ResourceTypes", rt.ID)}
+	return rt
+}
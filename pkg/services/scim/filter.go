@@ -0,0 +1,529 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter is a node of a parsed SCIM filter expression (RFC 7644, section 3.4.2.2).
+type Filter interface {
+	filterNode()
+}
+
+// AttributeExpression compares an attribute with a value, e.g. `userName eq "bob"`.
+// Value is nil for the `pr` (present) operator.
+type AttributeExpression struct {
+	Path     string
+	Operator string
+	Value    interface{}
+}
+
+// LogicalExpression combines two filters with `and` or `or`.
+type LogicalExpression struct {
+	Operator string
+	Left     Filter
+	Right    Filter
+}
+
+// NotExpression negates a filter.
+type NotExpression struct {
+	Filter Filter
+}
+
+// ValuePathExpression filters the values of a multi-valued attribute,
+// e.g. `emails[type eq "work"]`.
+type ValuePathExpression struct {
+	Path   string
+	Filter Filter
+}
+
+func (AttributeExpression) filterNode() {}
+func (LogicalExpression) filterNode()   {}
+func (NotExpression) filterNode()       {}
+func (ValuePathExpression) filterNode() {}
+
+var comparisonOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// ParseFilter parses a SCIM filter expression.
+func ParseFilter(s string) (Filter, error) {
+	p, err := newFilterParser(s)
+	if err != nil {
+		return nil, err
+	}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, p.peek().text)
+	}
+	return f, nil
+}
+
+// Path is a parsed SCIM attribute path as used by PATCH operations,
+// e.g. `members[value eq "2"]` or `emails[type eq "work"].value`.
+type Path struct {
+	Attribute    string
+	Filter       Filter
+	SubAttribute string
+}
+
+// ParsePath parses a PATCH operation path.
+func ParsePath(s string) (Path, error) {
+	s = strings.TrimSpace(s)
+	open := strings.Index(s, "[")
+	if open == -1 {
+		if s == "" || strings.ContainsAny(s, " ]") {
+			return Path{}, fmt.Errorf("%w: %q", ErrInvalidPath, s)
+		}
+		return Path{Attribute: s}, nil
+	}
+
+	end := strings.LastIndex(s, "]")
+	if end < open {
+		return Path{}, fmt.Errorf("%w: %q", ErrInvalidPath, s)
+	}
+	f, err := ParseFilter(s[open+1 : end])
+	if err != nil {
+		return Path{}, err
+	}
+	path := Path{Attribute: s[:open], Filter: f}
+	if rest := s[end+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") || len(rest) == 1 {
+			return Path{}, fmt.Errorf("%w: %q", ErrInvalidPath, s)
+		}
+		path.SubAttribute = rest[1:]
+	}
+	return path, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func newFilterParser(s string) (*filterParser, error) {
+	var tokens []token
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, text: ")"})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket, text: "["})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket, text: "]"})
+			i++
+		case r == '"':
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == '\\' {
+					j++
+					continue
+				}
+				if runes[j] == '"' {
+					break
+				}
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			var str string
+			if err := json.Unmarshal([]byte(string(runes[i:j+1])), &str); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: str})
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()[]\"", runes[j]) {
+				j++
+			}
+			text := string(runes[i:j])
+			kind := tokenWord
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				kind = tokenNumber
+			}
+			tokens = append(tokens, token{kind: kind, text: text})
+			i = j
+		}
+	}
+	return &filterParser{tokens: tokens}, nil
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("%w: unexpected end of filter", ErrInvalidFilter)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind {
+		return fmt.Errorf("%w: expected %q, got %q", ErrInvalidFilter, text, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpression{Operator: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpression{Operator: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.isKeyword("not") {
+		p.pos++
+		if err := p.expect(tokenOpenParen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return NotExpression{Filter: f}, nil
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case tokenOpenParen:
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case tokenWord:
+		return p.parseAttribute(t.text)
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, t.text)
+	}
+}
+
+func (p *filterParser) parseAttribute(path string) (Filter, error) {
+	if p.peek().kind == tokenOpenBracket {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return ValuePathExpression{Path: path, Filter: f}, nil
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	operator := strings.ToLower(op.text)
+	if op.kind != tokenWord {
+		return nil, fmt.Errorf("%w: expected operator, got %q", ErrInvalidFilter, op.text)
+	}
+	if operator == "pr" {
+		return AttributeExpression{Path: path, Operator: operator}, nil
+	}
+	if !comparisonOperators[operator] {
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op.text)
+	}
+
+	v, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	switch v.kind {
+	case tokenString:
+		value = v.text
+	case tokenNumber:
+		value, _ = strconv.ParseFloat(v.text, 64)
+	case tokenWord:
+		switch strings.ToLower(v.text) {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			return nil, fmt.Errorf("%w: invalid value %q", ErrInvalidFilter, v.text)
+		}
+	default:
+		return nil, fmt.Errorf("%w: invalid value %q", ErrInvalidFilter, v.text)
+	}
+	return AttributeExpression{Path: path, Operator: operator, Value: value}, nil
+}
+
+type attributeKind int
+
+const (
+	kindString attributeKind = iota
+	kindBool
+	kindID
+	kindTime
+)
+
+// attribute maps a SCIM attribute onto a SQL column.
+type attribute struct {
+	column string
+	kind   attributeKind
+	// invert is set for boolean columns stored as the opposite of the SCIM
+	// attribute, e.g. active and is_disabled.
+	invert bool
+	// subquery, when set, is a format string wrapping the condition on column,
+	// for attributes stored outside the main table.
+	subquery string
+}
+
+// filterToSQL converts a filter into a SQL condition for the given attribute
+// mapping. Attribute names are matched case insensitively.
+func filterToSQL(f Filter, attributes map[string]attribute) (string, []interface{}, error) {
+	switch f := f.(type) {
+	case LogicalExpression:
+		left, leftParams, err := filterToSQL(f.Left, attributes)
+		if err != nil {
+			return "", nil, err
+		}
+		right, rightParams, err := filterToSQL(f.Right, attributes)
+		if err != nil {
+			return "", nil, err
+		}
+		op := " AND "
+		if f.Operator == "or" {
+			op = " OR "
+		}
+		return "(" + left + op + right + ")", append(leftParams, rightParams...), nil
+	case NotExpression:
+		cond, params, err := filterToSQL(f.Filter, attributes)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + cond + ")", params, nil
+	case ValuePathExpression:
+		// emails[value eq "x"] is equivalent to emails.value eq "x" for the
+		// single valued columns Grafana stores.
+		return filterToSQL(prefixFilter(f.Path, f.Filter), attributes)
+	case AttributeExpression:
+		attr, ok := lookupAttribute(attributes, f.Path)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unsupported attribute %q", ErrInvalidFilter, f.Path)
+		}
+		cond, params, err := attributeToSQL(attr, f)
+		if err != nil {
+			return "", nil, err
+		}
+		if attr.subquery != "" {
+			cond = fmt.Sprintf(attr.subquery, cond)
+		}
+		return cond, params, nil
+	default:
+		return "", nil, fmt.Errorf("%w: unsupported expression", ErrInvalidFilter)
+	}
+}
+
+func prefixFilter(prefix string, f Filter) Filter {
+	switch f := f.(type) {
+	case LogicalExpression:
+		return LogicalExpression{Operator: f.Operator, Left: prefixFilter(prefix, f.Left), Right: prefixFilter(prefix, f.Right)}
+	case NotExpression:
+		return NotExpression{Filter: prefixFilter(prefix, f.Filter)}
+	case AttributeExpression:
+		return AttributeExpression{Path: prefix + "." + f.Path, Operator: f.Operator, Value: f.Value}
+	default:
+		return f
+	}
+}
+
+func lookupAttribute(attributes map[string]attribute, path string) (attribute, bool) {
+	path = strings.ToLower(path)
+	// Attributes may be qualified with their schema URN.
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		path = strings.TrimPrefix(path, strings.ToLower(schema)+":")
+	}
+	attr, ok := attributes[path]
+	return attr, ok
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func attributeToSQL(attr attribute, f AttributeExpression) (string, []interface{}, error) {
+	if f.Operator == "pr" {
+		if attr.kind == kindString {
+			return "(" + attr.column + " IS NOT NULL AND " + attr.column + " <> '')", nil, nil
+		}
+		return attr.column + " IS NOT NULL", nil, nil
+	}
+
+	switch attr.kind {
+	case kindBool:
+		b, ok := f.Value.(bool)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s expects a boolean", ErrInvalidFilter, f.Path)
+		}
+		if attr.invert {
+			b = !b
+		}
+		switch f.Operator {
+		case "eq":
+			return attr.column + " = ?", []interface{}{b}, nil
+		case "ne":
+			return attr.column + " <> ?", []interface{}{b}, nil
+		}
+	case kindID:
+		var id int64
+		switch v := f.Value.(type) {
+		case string:
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				// Not a Grafana id, nothing can match.
+				return "1 = 0", nil, nil
+			}
+			id = parsed
+		case float64:
+			id = int64(v)
+		default:
+			return "", nil, fmt.Errorf("%w: %s expects an id", ErrInvalidFilter, f.Path)
+		}
+		if op, ok := sqlComparison(f.Operator); ok {
+			return attr.column + " " + op + " ?", []interface{}{id}, nil
+		}
+	case kindTime:
+		s, ok := f.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s expects a date time", ErrInvalidFilter, f.Path)
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+		}
+		if op, ok := sqlComparison(f.Operator); ok {
+			return attr.column + " " + op + " ?", []interface{}{t}, nil
+		}
+	case kindString:
+		s, ok := f.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s expects a string", ErrInvalidFilter, f.Path)
+		}
+		s = strings.ToLower(s)
+		column := "LOWER(" + attr.column + ")"
+		switch f.Operator {
+		case "co":
+			return column + " LIKE ? ESCAPE '!'", []interface{}{"%" + likeEscaper.Replace(s) + "%"}, nil
+		case "sw":
+			return column + " LIKE ? ESCAPE '!'", []interface{}{likeEscaper.Replace(s) + "%"}, nil
+		case "ew":
+			return column + " LIKE ? ESCAPE '!'", []interface{}{"%" + likeEscaper.Replace(s)}, nil
+		}
+		if op, ok := sqlComparison(f.Operator); ok {
+			return column + " " + op + " ?", []interface{}{s}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: operator %q is not supported for %s", ErrInvalidFilter, f.Operator, f.Path)
+}
+
+func sqlComparison(op string) (string, bool) {
+	switch op {
+	case "eq":
+		return "=", true
+	case "ne":
+		return "<>", true
+	case "gt":
+		return ">", true
+	case "ge":
+		return ">=", true
+	case "lt":
+		return "<", true
+	case "le":
+		return "<=", true
+	}
+	return "", false
+}
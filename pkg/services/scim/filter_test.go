@@ -0,0 +1,171 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filter   string
+		expected Filter
+	}{
+		{
+			desc:     "attribute equality",
+			filter:   `userName eq "bjensen"`,
+			expected: AttributeExpression{Path: "userName", Operator: "eq", Value: "bjensen"},
+		},
+		{
+			desc:     "operators are case insensitive",
+			filter:   `userName EQ "bjensen"`,
+			expected: AttributeExpression{Path: "userName", Operator: "eq", Value: "bjensen"},
+		},
+		{
+			desc:     "present",
+			filter:   `title pr`,
+			expected: AttributeExpression{Path: "title", Operator: "pr"},
+		},
+		{
+			desc:     "boolean value",
+			filter:   `active eq false`,
+			expected: AttributeExpression{Path: "active", Operator: "eq", Value: false},
+		},
+		{
+			desc:   "and binds tighter than or",
+			filter: `a eq "1" or b eq "2" and c eq "3"`,
+			expected: LogicalExpression{
+				Operator: "or",
+				Left:     AttributeExpression{Path: "a", Operator: "eq", Value: "1"},
+				Right: LogicalExpression{
+					Operator: "and",
+					Left:     AttributeExpression{Path: "b", Operator: "eq", Value: "2"},
+					Right:    AttributeExpression{Path: "c", Operator: "eq", Value: "3"},
+				},
+			},
+		},
+		{
+			desc:   "grouping and negation",
+			filter: `not (a eq "1" or b eq "2")`,
+			expected: NotExpression{Filter: LogicalExpression{
+				Operator: "or",
+				Left:     AttributeExpression{Path: "a", Operator: "eq", Value: "1"},
+				Right:    AttributeExpression{Path: "b", Operator: "eq", Value: "2"},
+			}},
+		},
+		{
+			desc:   "value path",
+			filter: `emails[type eq "work" and value co "@example.com"]`,
+			expected: ValuePathExpression{Path: "emails", Filter: LogicalExpression{
+				Operator: "and",
+				Left:     AttributeExpression{Path: "type", Operator: "eq", Value: "work"},
+				Right:    AttributeExpression{Path: "value", Operator: "co", Value: "@example.com"},
+			}},
+		},
+		{
+			desc:     "escaped string",
+			filter:   `displayName eq "say \"hi\""`,
+			expected: AttributeExpression{Path: "displayName", Operator: "eq", Value: `say "hi"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := ParseFilter(tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, f)
+		})
+	}
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, filter := range []string{`userName`, `userName xx "a"`, `userName eq`, `(userName eq "a"`, `userName eq "a" and`, `userName eq bob`, `userName eq "a`} {
+			_, err := ParseFilter(filter)
+			require.ErrorIs(t, err, ErrInvalidFilter, filter)
+		}
+	})
+}
+
+func TestParsePath(t *testing.T) {
+	p, err := ParsePath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	require.Equal(t, "emails", p.Attribute)
+	require.Equal(t, "value", p.SubAttribute)
+	require.Equal(t, AttributeExpression{Path: "type", Operator: "eq", Value: "work"}, p.Filter)
+
+	p, err = ParsePath("name.givenName")
+	require.NoError(t, err)
+	require.Equal(t, Path{Attribute: "name.givenName"}, p)
+
+	_, err = ParsePath(`members[value eq "1"]x`)
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestFilterToSQL(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		filter string
+		cond   string
+		params []interface{}
+	}{
+		{
+			desc:   "string equality is case insensitive",
+			filter: `userName eq "BJensen"`,
+			cond:   "LOWER(u.login) = ?",
+			params: []interface{}{"bjensen"},
+		},
+		{
+			desc:   "contains escapes wildcards",
+			filter: `emails.value co "50%_off"`,
+			cond:   "LOWER(u.email) LIKE ? ESCAPE '!'",
+			params: []interface{}{"%50!%!_off%"},
+		},
+		{
+			desc:   "inverted boolean",
+			filter: `active eq true`,
+			cond:   "u.is_disabled = ?",
+			params: []interface{}{false},
+		},
+		{
+			desc:   "attribute stored in another table",
+			filter: `externalId eq "abc"`,
+			cond:   "u.id IN (SELECT user_id FROM user_auth WHERE auth_module = 'scim' AND LOWER(auth_id) = ?)",
+			params: []interface{}{"abc"},
+		},
+		{
+			desc:   "non numeric id matches nothing",
+			filter: `id eq "abc"`,
+			cond:   "1 = 0",
+		},
+		{
+			desc:   "value path and logical operators",
+			filter: `emails[value sw "b"] or not (id eq "2")`,
+			cond:   "(LOWER(u.email) LIKE ? ESCAPE '!' OR NOT (u.id = ?))",
+			params: []interface{}{"b%", int64(2)},
+		},
+		{
+			desc:   "schema qualified attribute",
+			filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "x"`,
+			cond:   "LOWER(u.login) = ?",
+			params: []interface{}{"x"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := ParseFilter(tc.filter)
+			require.NoError(t, err)
+			cond, params, err := filterToSQL(f, userAttributes)
+			require.NoError(t, err)
+			require.Equal(t, tc.cond, cond)
+			require.Equal(t, tc.params, params)
+		})
+	}
+
+	t.Run("unsupported attribute", func(t *testing.T) {
+		f, err := ParseFilter(`title eq "x"`)
+		require.NoError(t, err)
+		_, _, err = filterToSQL(f, userAttributes)
+		require.ErrorIs(t, err, ErrInvalidFilter)
+	})
+}
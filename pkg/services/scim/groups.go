@@ -0,0 +1,346 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var groupAttributes = map[string]attribute{
+	"id":                {column: "team.id", kind: kindID},
+	"displayname":       {column: "team.name"},
+	"members":           {column: "user_id", kind: kindID, subquery: "team.id IN (SELECT team_id FROM team_member WHERE %s)"},
+	"members.value":     {column: "user_id", kind: kindID, subquery: "team.id IN (SELECT team_id FROM team_member WHERE %s)"},
+	"meta.created":      {column: "team.created", kind: kindTime},
+	"meta.lastmodified": {column: "team.updated", kind: kindTime},
+}
+
+// listGroupsHandler handles GET /api/scim/v2/Groups.
+func (s *Service) listGroupsHandler(c *models.ReqContext) response.Response {
+	filter, err := parseFilterParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to parse filter")
+	}
+	startIndex, count := pagination(c)
+
+	teams, total, err := s.searchTeams(c.Req.Context(), c.OrgId, filter, startIndex, count)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to search groups")
+	}
+	resources, err := s.toGroupResources(c.Req.Context(), c.OrgId, teams, c.Query("excludedAttributes") == "members")
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to search groups")
+	}
+
+	result := ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    make([]interface{}, 0, len(resources)),
+	}
+	for _, r := range resources {
+		result.Resources = append(result.Resources, r)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+// getGroupHandler handles GET /api/scim/v2/Groups/:id.
+func (s *Service) getGroupHandler(c *models.ReqContext) response.Response {
+	team, err := s.getTeamByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to get group")
+	}
+	return s.groupResponse(c.Req.Context(), c.OrgId, http.StatusOK, team)
+}
+
+// createGroupHandler handles POST /api/scim/v2/Groups.
+func (s *Service) createGroupHandler(c *models.ReqContext) response.Response {
+	resource := Group{}
+	if err := web.Bind(c.Req, &resource); err != nil {
+		return errorResponse(http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+	if resource.DisplayName == "" {
+		return errorResponse(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+
+	team, err := s.sqlStore.CreateTeam(resource.DisplayName, "", c.OrgId)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to create group")
+	}
+	if err := s.setMembers(c.Req.Context(), c.OrgId, team.Id, resource.Members); err != nil {
+		// Don't leave a half provisioned group behind, the identity provider will retry.
+		if deleteErr := s.sqlStore.DeleteTeam(c.Req.Context(), &models.DeleteTeamCommand{OrgId: c.OrgId, Id: team.Id}); deleteErr != nil {
+			s.log.Error("Failed to clean up group", "teamId", team.Id, "error", deleteErr)
+		}
+		return s.toErrorResponse(err, "Failed to create group")
+	}
+	return s.groupResponse(c.Req.Context(), c.OrgId, http.StatusCreated, &team)
+}
+
+// replaceGroupHandler handles PUT /api/scim/v2/Groups/:id.
+func (s *Service) replaceGroupHandler(c *models.ReqContext) response.Response {
+	team, err := s.getTeamByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+
+	resource := Group{}
+	if err := web.Bind(c.Req, &resource); err != nil {
+		return errorResponse(http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	if team, err = s.updateGroup(c.Req.Context(), c.OrgId, team, &resource); err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+	return s.groupResponse(c.Req.Context(), c.OrgId, http.StatusOK, team)
+}
+
+// patchGroupHandler handles PATCH /api/scim/v2/Groups/:id.
+func (s *Service) patchGroupHandler(c *models.ReqContext) response.Response {
+	team, err := s.getTeamByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+
+	patch := PatchRequest{}
+	if err := web.Bind(c.Req, &patch); err != nil {
+		return errorResponse(http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	resources, err := s.toGroupResources(c.Req.Context(), c.OrgId, []*models.Team{team}, false)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+	doc, err := toDocument(resources[0])
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+	if err := applyPatch(doc, patch.Operations); err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+	resource := &Group{}
+	if err := fromDocument(doc, resource); err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+
+	if team, err = s.updateGroup(c.Req.Context(), c.OrgId, team, resource); err != nil {
+		return s.toErrorResponse(err, "Failed to update group")
+	}
+	return s.groupResponse(c.Req.Context(), c.OrgId, http.StatusOK, team)
+}
+
+// deleteGroupHandler handles DELETE /api/scim/v2/Groups/:id.
+func (s *Service) deleteGroupHandler(c *models.ReqContext) response.Response {
+	team, err := s.getTeamByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to delete group")
+	}
+	if err := s.sqlStore.DeleteTeam(c.Req.Context(), &models.DeleteTeamCommand{OrgId: c.OrgId, Id: team.Id}); err != nil {
+		return s.toErrorResponse(err, "Failed to delete group")
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (s *Service) getTeamByParam(c *models.ReqContext) (*models.Team, error) {
+	id, err := parseID(web.Params(c.Req)[":id"])
+	if err != nil {
+		return nil, err
+	}
+	return s.getTeam(c.Req.Context(), c.OrgId, id)
+}
+
+func (s *Service) groupResponse(ctx context.Context, orgID int64, status int, team *models.Team) response.Response {
+	resources, err := s.toGroupResources(ctx, orgID, []*models.Team{team}, false)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to get group")
+	}
+	return scimResponse(status, resources[0])
+}
+
+func (s *Service) toGroupResources(ctx context.Context, orgID int64, teams []*models.Team, excludeMembers bool) ([]*Group, error) {
+	ids := make([]int64, 0, len(teams))
+	for _, t := range teams {
+		ids = append(ids, t.Id)
+	}
+
+	members := map[int64][]MultiValued{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if len(ids) == 0 || excludeMembers {
+			return nil
+		}
+
+		var memberships []struct {
+			TeamId int64
+			UserId int64
+			Login  string
+		}
+		userTable := s.sqlStore.Dialect.Quote("user")
+		err := sess.Table("team_member").
+			Join("INNER", userTable, "team_member.user_id = "+userTable+".id").
+			Where("team_member.org_id = ?", orgID).
+			In("team_member.team_id", ids).
+			Select("team_member.team_id, team_member.user_id, " + userTable + ".login").
+			Asc("team_member.user_id").
+			Find(&memberships)
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			id := strconv.FormatInt(m.UserId, 10)
+			members[m.TeamId] = append(members[m.TeamId], MultiValued{
+				Value:   id,
+				Display: m.Login,
+				Ref:     s.location("Users", id),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Group, 0, len(teams))
+	for _, t := range teams {
+		id := strconv.FormatInt(t.Id, 10)
+		result = append(result, &Group{
+			Schemas:     []string{SchemaGroup},
+			ID:          id,
+			DisplayName: t.Name,
+			Members:     members[t.Id],
+			Meta: &Meta{
+				ResourceType: "Group",
+				Created:      &t.Created,
+				LastModified: &t.Updated,
+				Location:     s.location("Groups", id),
+				Version:      fmt.Sprintf("W/\"%d\"", t.Updated.Unix()),
+			},
+		})
+	}
+	return result, nil
+}
+
+func (s *Service) searchTeams(ctx context.Context, orgID int64, filter Filter, startIndex, count int) ([]*models.Team, int64, error) {
+	var cond string
+	var params []interface{}
+	if filter != nil {
+		var err error
+		if cond, params, err = filterToSQL(filter, groupAttributes); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	teams := make([]*models.Team, 0)
+	var total int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		sess.Table("team").Where("team.org_id = ?", orgID)
+		if cond != "" {
+			sess.And(cond, params...)
+		}
+		var err error
+		if total, err = sess.Count(&models.Team{}); err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+
+		sess.Table("team").Where("team.org_id = ?", orgID)
+		if cond != "" {
+			sess.And(cond, params...)
+		}
+		return sess.Asc("team.id").Limit(count, startIndex-1).Find(&teams)
+	})
+	return teams, total, err
+}
+
+func (s *Service) getTeam(ctx context.Context, orgID, teamID int64) (*models.Team, error) {
+	team := &models.Team{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Where("org_id = ? AND id = ?", orgID, teamID).Get(team)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (s *Service) updateGroup(ctx context.Context, orgID int64, team *models.Team, resource *Group) (*models.Team, error) {
+	if resource.DisplayName == "" {
+		return nil, fmt.Errorf("%w: displayName is required", ErrInvalidValue)
+	}
+
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		if resource.DisplayName != team.Name {
+			cmd := models.UpdateTeamCommand{Id: team.Id, OrgId: orgID, Name: resource.DisplayName, Email: team.Email}
+			if err := s.sqlStore.UpdateTeam(ctx, &cmd); err != nil {
+				return err
+			}
+		}
+		return s.setMembers(ctx, orgID, team.Id, resource.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getTeam(ctx, orgID, team.Id)
+}
+
+// setMembers makes the team members match the given SCIM members. Members
+// must be users of the organization.
+func (s *Service) setMembers(ctx context.Context, orgID, teamID int64, members []MultiValued) error {
+	wanted := map[int64]bool{}
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: unknown member %q", ErrInvalidValue, m.Value)
+		}
+		wanted[id] = true
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var current []int64
+		if err := sess.Table("team_member").Where("org_id = ? AND team_id = ?", orgID, teamID).Cols("user_id").Find(&current); err != nil {
+			return err
+		}
+
+		existing := map[int64]bool{}
+		for _, id := range current {
+			existing[id] = true
+			if !wanted[id] {
+				if err := sqlstore.RemoveTeamMemberHook(sess, &models.RemoveTeamMemberCommand{OrgId: orgID, TeamId: teamID, UserId: id}); err != nil {
+					return err
+				}
+			}
+		}
+
+		for id := range wanted {
+			if existing[id] {
+				continue
+			}
+			inOrg, err := sess.Table("org_user").Where("org_id = ? AND user_id = ?", orgID, id).Exist()
+			if err != nil {
+				return err
+			}
+			if !inOrg {
+				return fmt.Errorf("%w: unknown member %q", ErrInvalidValue, strconv.FormatInt(id, 10))
+			}
+			if err := sqlstore.AddOrUpdateTeamMemberHook(sess, id, orgID, teamID, false, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,150 @@
+package scim
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+
+	// AuthModule is the auth module stored in user_auth for users provisioned
+	// through SCIM. The auth id holds the externalId sent by the identity provider.
+	AuthModule = "scim"
+
+	contentType = "application/scim+json"
+
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid SCIM filter")
+	ErrInvalidPath   = errors.New("invalid SCIM path")
+	ErrInvalidValue  = errors.New("invalid SCIM value")
+	ErrUniqueness    = errors.New("resource already exists")
+	ErrNotFound      = errors.New("resource not found")
+	ErrForbidden     = errors.New("user belongs to other organizations and can only be modified by a Grafana server admin")
+)
+
+// Meta is the SCIM resource metadata.
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location"`
+	Version      string     `json:"version,omitempty"`
+}
+
+// Name is the SCIM user name complex attribute. Grafana stores a single name
+// so the formatted value is authoritative and the components are informative.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValued is a SCIM multi-valued attribute entry, e.g. an email.
+type MultiValued struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is the SCIM representation of a Grafana user.
+type User struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	ExternalID  string        `json:"externalId,omitempty"`
+	UserName    string        `json:"userName"`
+	Name        *Name         `json:"name,omitempty"`
+	DisplayName string        `json:"displayName,omitempty"`
+	Emails      []MultiValued `json:"emails,omitempty"`
+	Active      *bool         `json:"active,omitempty"`
+	Groups      []MultiValued `json:"groups,omitempty"`
+	Meta        *Meta         `json:"meta,omitempty"`
+}
+
+// PrimaryEmail returns the primary email, or the first one if none is
+// flagged as primary.
+func (u *User) PrimaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// FullName returns the name Grafana should store for the user.
+func (u *User) FullName() string {
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if u.Name.GivenName != "" || u.Name.FamilyName != "" {
+			if u.Name.GivenName == "" || u.Name.FamilyName == "" {
+				return u.Name.GivenName + u.Name.FamilyName
+			}
+			return u.Name.GivenName + " " + u.Name.FamilyName
+		}
+	}
+	return u.DisplayName
+}
+
+// IsActive reports whether the user should be enabled. Active defaults to
+// true when omitted.
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Group is the SCIM representation of a Grafana team.
+type Group struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []MultiValued `json:"members,omitempty"`
+	Meta        *Meta         `json:"meta,omitempty"`
+}
+
+// ListResponse is the envelope for query results.
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// PatchRequest is the body of a PATCH request.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single add, remove or replace operation.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ErrorResponse is the SCIM error message.
+type ErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
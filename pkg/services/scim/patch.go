@@ -0,0 +1,326 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// applyPatch applies PATCH operations (RFC 7644, section 3.5.2) to a resource
+// represented as a decoded JSON object. Attribute names are case insensitive.
+func applyPatch(resource map[string]interface{}, operations []PatchOperation) error {
+	for _, op := range operations {
+		if err := applyOperation(resource, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(resource map[string]interface{}, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidValue, op.Op)
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return fmt.Errorf("%w: remove requires a path", ErrInvalidPath)
+		}
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: operation without path requires an object value", ErrInvalidValue)
+		}
+		for k, v := range values {
+			if err := applyOperation(resource, PatchOperation{Op: kind, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := ParsePath(op.Path)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the parent object for dotted paths such as name.givenName.
+	parent := resource
+	attr := stripSchema(path.Attribute)
+	if i := strings.Index(attr, "."); i != -1 && path.Filter == nil {
+		container := attr[:i]
+		attr = attr[i+1:]
+		key := findKey(resource, container)
+		child, ok := resource[key].(map[string]interface{})
+		if !ok {
+			if kind == "remove" {
+				return nil
+			}
+			child = map[string]interface{}{}
+			resource[key] = child
+		}
+		parent = child
+	}
+	key := findKey(parent, attr)
+
+	if path.Filter == nil {
+		switch kind {
+		case "remove":
+			if op.Value != nil {
+				return removeValues(parent, key, op.Value)
+			}
+			delete(parent, key)
+		case "add":
+			addValue(parent, key, op.Value)
+		case "replace":
+			parent[key] = op.Value
+		}
+		return nil
+	}
+
+	return applyFiltered(parent, key, kind, path, op.Value)
+}
+
+// applyFiltered handles operations on the elements of a multi-valued
+// attribute selected by a value filter, e.g. members[value eq "2"].
+func applyFiltered(parent map[string]interface{}, key, kind string, path Path, value interface{}) error {
+	var elements []interface{}
+	if existing, ok := parent[key].([]interface{}); ok {
+		elements = existing
+	}
+
+	result := make([]interface{}, 0, len(elements))
+	matched := false
+	for _, e := range elements {
+		element, ok := e.(map[string]interface{})
+		if !ok || !matchFilter(path.Filter, element) {
+			result = append(result, e)
+			continue
+		}
+		matched = true
+
+		switch {
+		case kind == "remove" && path.SubAttribute == "":
+			continue
+		case kind == "remove":
+			delete(element, findKey(element, path.SubAttribute))
+		case path.SubAttribute != "":
+			element[findKey(element, path.SubAttribute)] = value
+		default:
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %s requires an object value", ErrInvalidValue, path.Attribute)
+			}
+			for k, v := range values {
+				element[findKey(element, k)] = v
+			}
+		}
+		result = append(result, element)
+	}
+
+	if !matched && kind != "remove" {
+		// Identity providers commonly address a value that does not exist yet,
+		// e.g. emails[type eq "work"].value, so create it from the filter.
+		element := elementFromFilter(path.Filter)
+		if element == nil {
+			return fmt.Errorf("%w: no value matches %s", ErrInvalidPath, path.Attribute)
+		}
+		if path.SubAttribute != "" {
+			element[path.SubAttribute] = value
+		} else if values, ok := value.(map[string]interface{}); ok {
+			for k, v := range values {
+				element[k] = v
+			}
+		}
+		result = append(result, element)
+	}
+
+	parent[key] = result
+	return nil
+}
+
+func addValue(parent map[string]interface{}, key string, value interface{}) {
+	existing, isList := parent[key].([]interface{})
+	values, valueIsList := value.([]interface{})
+	if !isList && (!valueIsList || parent[key] != nil) {
+		parent[key] = value
+		return
+	}
+
+	if !valueIsList {
+		values = []interface{}{value}
+	}
+	for _, v := range values {
+		if !containsValue(existing, v) {
+			existing = append(existing, v)
+		}
+	}
+	parent[key] = existing
+}
+
+// removeValues removes the given values from a multi-valued attribute. Some
+// identity providers send removals of members as a value list rather than a
+// filtered path.
+func removeValues(parent map[string]interface{}, key string, value interface{}) error {
+	existing, ok := parent[key].([]interface{})
+	if !ok {
+		delete(parent, key)
+		return nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	result := make([]interface{}, 0, len(existing))
+	for _, e := range existing {
+		if !containsValue(values, e) {
+			result = append(result, e)
+		}
+	}
+	parent[key] = result
+	return nil
+}
+
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, e := range list {
+		if sameValue(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue compares multi-valued attribute entries by their value
+// sub-attribute when present.
+func sameValue(a, b interface{}) bool {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if aok && bok {
+		return fmt.Sprint(am[findKey(am, "value")]) == fmt.Sprint(bm[findKey(bm, "value")])
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func elementFromFilter(f Filter) map[string]interface{} {
+	switch f := f.(type) {
+	case AttributeExpression:
+		if f.Operator != "eq" {
+			return nil
+		}
+		return map[string]interface{}{f.Path: f.Value}
+	case LogicalExpression:
+		if f.Operator != "and" {
+			return nil
+		}
+		left, right := elementFromFilter(f.Left), elementFromFilter(f.Right)
+		if left == nil || right == nil {
+			return nil
+		}
+		for k, v := range right {
+			left[k] = v
+		}
+		return left
+	}
+	return nil
+}
+
+// matchFilter evaluates a filter against a JSON object in memory.
+func matchFilter(f Filter, element map[string]interface{}) bool {
+	switch f := f.(type) {
+	case LogicalExpression:
+		if f.Operator == "or" {
+			return matchFilter(f.Left, element) || matchFilter(f.Right, element)
+		}
+		return matchFilter(f.Left, element) && matchFilter(f.Right, element)
+	case NotExpression:
+		return !matchFilter(f.Filter, element)
+	case AttributeExpression:
+		v, ok := element[findKey(element, f.Path)]
+		if f.Operator == "pr" {
+			return ok && v != nil && v != ""
+		}
+		return compareValues(v, f.Operator, f.Value)
+	}
+	return false
+}
+
+func compareValues(actual interface{}, op string, expected interface{}) bool {
+	switch e := expected.(type) {
+	case string:
+		a := strings.ToLower(fmt.Sprint(actual))
+		e = strings.ToLower(e)
+		switch op {
+		case "eq":
+			return a == e
+		case "ne":
+			return a != e
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == e
+		case "ne":
+			return a != e
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case bool, nil:
+		switch op {
+		case "eq":
+			return actual == e
+		case "ne":
+			return actual != e
+		}
+	}
+	return false
+}
+
+// findKey returns the key in m matching name case insensitively, or name if
+// there is none.
+func findKey(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func stripSchema(attr string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(attr) > len(schema) && strings.EqualFold(attr[:len(schema)+1], schema+":") {
+			return attr[len(schema)+1:]
+		}
+	}
+	return attr
+}
@@ -0,0 +1,98 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	newGroup := func() map[string]interface{} {
+		return map[string]interface{}{
+			"displayName": "Ops",
+			"members": []interface{}{
+				map[string]interface{}{"value": "1"},
+				map[string]interface{}{"value": "2"},
+			},
+		}
+	}
+
+	t.Run("add members appends missing values", func(t *testing.T) {
+		doc := newGroup()
+		err := applyPatch(doc, []PatchOperation{{Op: "add", Path: "members", Value: []interface{}{
+			map[string]interface{}{"value": "2"},
+			map[string]interface{}{"value": "3"},
+		}}})
+		require.NoError(t, err)
+		require.Len(t, doc["members"], 3)
+	})
+
+	t.Run("remove member by filter", func(t *testing.T) {
+		doc := newGroup()
+		err := applyPatch(doc, []PatchOperation{{Op: "Remove", Path: `members[value eq "1"]`}})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{map[string]interface{}{"value": "2"}}, doc["members"])
+	})
+
+	t.Run("remove members by value list", func(t *testing.T) {
+		doc := newGroup()
+		err := applyPatch(doc, []PatchOperation{{Op: "remove", Path: "members", Value: []interface{}{map[string]interface{}{"value": "2"}}}})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{map[string]interface{}{"value": "1"}}, doc["members"])
+	})
+
+	t.Run("replace without path", func(t *testing.T) {
+		doc := newGroup()
+		err := applyPatch(doc, []PatchOperation{{Op: "replace", Value: map[string]interface{}{"DisplayName": "SRE"}}})
+		require.NoError(t, err)
+		require.Equal(t, "SRE", doc["displayName"])
+	})
+
+	t.Run("replace sub attribute of a filtered value creates it", func(t *testing.T) {
+		doc := map[string]interface{}{"userName": "bob"}
+		err := applyPatch(doc, []PatchOperation{{Op: "replace", Path: `emails[type eq "work"].value`, Value: "bob@example.com"}})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{map[string]interface{}{"type": "work", "value": "bob@example.com"}}, doc["emails"])
+	})
+
+	t.Run("replace nested attribute", func(t *testing.T) {
+		doc := map[string]interface{}{"name": map[string]interface{}{"givenName": "Bob"}}
+		err := applyPatch(doc, []PatchOperation{{Op: "replace", Path: "name.familyName", Value: "Smith"}})
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"givenName": "Bob", "familyName": "Smith"}, doc["name"])
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		err := applyPatch(newGroup(), []PatchOperation{{Op: "move", Path: "members"}})
+		require.ErrorIs(t, err, ErrInvalidValue)
+	})
+}
+
+func TestPatchUser(t *testing.T) {
+	active := true
+	user := &User{
+		Schemas:     []string{SchemaUser},
+		UserName:    "bob",
+		DisplayName: "Bob Smith",
+		Name:        &Name{Formatted: "Bob Smith", GivenName: "Bob", FamilyName: "Smith"},
+		Active:      &active,
+	}
+
+	t.Run("active as a string", func(t *testing.T) {
+		patched, err := patchUser(user, []PatchOperation{{Op: "Replace", Path: "active", Value: "False"}})
+		require.NoError(t, err)
+		require.False(t, patched.IsActive())
+	})
+
+	t.Run("name components take precedence over a stale formatted name", func(t *testing.T) {
+		patched, err := patchUser(user, []PatchOperation{{Op: "replace", Path: "name.familyName", Value: "Jones"}})
+		require.NoError(t, err)
+		require.Equal(t, "Bob Jones", patched.FullName())
+	})
+
+	t.Run("display name", func(t *testing.T) {
+		patched, err := patchUser(user, []PatchOperation{{Op: "replace", Path: "displayName", Value: "Robert"}})
+		require.NoError(t, err)
+		require.Equal(t, "Robert", patched.FullName())
+	})
+}
@@ -0,0 +1,151 @@
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Service exposes a SCIM 2.0 (RFC 7643, RFC 7644) provisioning API on top of
+// Grafana users and teams. Users and groups are provisioned in the
+// organization of the service account used to authenticate.
+type Service struct {
+	cfg              *setting.Cfg
+	sqlStore         *sqlstore.SQLStore
+	authTokenService models.UserTokenService
+	routeRegister    routing.RouteRegister
+	log              log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, sqlStore *sqlstore.SQLStore,
+	authTokenService models.UserTokenService, routeRegister routing.RouteRegister) *Service {
+	s := &Service{
+		cfg:              cfg,
+		sqlStore:         sqlStore,
+		authTokenService: authTokenService,
+		routeRegister:    routeRegister,
+		log:              log.New("scim"),
+	}
+
+	if features.IsEnabled(featuremgmt.FlagScim) {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/scim/v2", func(r routing.RouteRegister) {
+		r.Get("/ServiceProviderConfig", routing.Wrap(s.getServiceProviderConfig))
+		r.Get("/ResourceTypes", routing.Wrap(s.getResourceTypes))
+		r.Get("/ResourceTypes/:id", routing.Wrap(s.getResourceType))
+		r.Get("/Schemas", routing.Wrap(s.getSchemas))
+		r.Get("/Schemas/:id", routing.Wrap(s.getSchema))
+
+		r.Get("/Users", routing.Wrap(s.listUsersHandler))
+		r.Post("/Users", routing.Wrap(s.createUserHandler))
+		r.Get("/Users/:id", routing.Wrap(s.getUserHandler))
+		r.Put("/Users/:id", routing.Wrap(s.replaceUserHandler))
+		r.Patch("/Users/:id", routing.Wrap(s.patchUserHandler))
+		r.Delete("/Users/:id", routing.Wrap(s.deleteUserHandler))
+
+		r.Get("/Groups", routing.Wrap(s.listGroupsHandler))
+		r.Post("/Groups", routing.Wrap(s.createGroupHandler))
+		r.Get("/Groups/:id", routing.Wrap(s.getGroupHandler))
+		r.Put("/Groups/:id", routing.Wrap(s.replaceGroupHandler))
+		r.Patch("/Groups/:id", routing.Wrap(s.patchGroupHandler))
+		r.Delete("/Groups/:id", routing.Wrap(s.deleteGroupHandler))
+	}, reqServiceAccount)
+}
+
+// reqServiceAccount only lets through requests authenticated with the token
+// of a service account that is admin of its organization.
+func reqServiceAccount(c *models.ReqContext) {
+	if !c.IsSignedIn || c.SignedInUser == nil || !c.SignedInUser.IsServiceAccount {
+		errorResponse(http.StatusUnauthorized, "", "SCIM requires a service account token").WriteTo(c)
+		return
+	}
+	if c.OrgRole != models.ROLE_ADMIN {
+		errorResponse(http.StatusForbidden, "", "The service account must have the Admin role").WriteTo(c)
+		return
+	}
+}
+
+func (s *Service) location(resource, id string) string {
+	return s.cfg.AppURL + "api/scim/v2/" + resource + "/" + id
+}
+
+func scimResponse(status int, body interface{}) response.Response {
+	return response.JSON(status, body).SetHeader("Content-Type", contentType)
+}
+
+func errorResponse(status int, scimType string, detail string) *response.NormalResponse {
+	return response.JSON(status, ErrorResponse{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}).SetHeader("Content-Type", contentType)
+}
+
+func (s *Service) toErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrInvalidFilter):
+		return errorResponse(http.StatusBadRequest, "invalidFilter", err.Error())
+	case errors.Is(err, ErrInvalidPath):
+		return errorResponse(http.StatusBadRequest, "invalidPath", err.Error())
+	case errors.Is(err, ErrInvalidValue):
+		return errorResponse(http.StatusBadRequest, "invalidValue", err.Error())
+	case errors.Is(err, ErrUniqueness), errors.Is(err, models.ErrUserAlreadyExists), errors.Is(err, models.ErrTeamNameTaken):
+		return errorResponse(http.StatusConflict, "uniqueness", err.Error())
+	case errors.Is(err, ErrForbidden):
+		return errorResponse(http.StatusForbidden, "", err.Error())
+	case errors.Is(err, ErrNotFound), errors.Is(err, models.ErrUserNotFound), errors.Is(err, models.ErrTeamNotFound):
+		return errorResponse(http.StatusNotFound, "", "Resource not found")
+	}
+	s.log.Error(message, "error", err)
+	return errorResponse(http.StatusInternalServerError, "", message)
+}
+
+// pagination reads the 1-based startIndex and count query parameters.
+func pagination(c *models.ReqContext) (startIndex, count int) {
+	startIndex = c.QueryInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count = defaultCount
+	if c.Query("count") != "" {
+		count = c.QueryInt("count")
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > maxCount {
+		count = maxCount
+	}
+	return startIndex, count
+}
+
+func parseFilterParam(c *models.ReqContext) (Filter, error) {
+	filter := c.Query("filter")
+	if filter == "" {
+		return nil, nil
+	}
+	return ParseFilter(filter)
+}
+
+func parseID(id string) (int64, error) {
+	parsed, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, ErrNotFound
+	}
+	return parsed, nil
+}
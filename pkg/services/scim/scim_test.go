@@ -0,0 +1,342 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+type scimScenario struct {
+	t        *testing.T
+	server   *web.Mux
+	store    *sqlstore.SQLStore
+	revoked  []int64
+	signedIn *models.SignedInUser
+}
+
+func setupScenario(t *testing.T) *scimScenario {
+	t.Helper()
+
+	store := sqlstore.InitTestDB(t)
+	// Creates the organization the service account belongs to.
+	admin, err := store.CreateUser(context.Background(), models.CreateUserCommand{Login: "admin", IsAdmin: true})
+	require.NoError(t, err)
+
+	sc := &scimScenario{
+		t:        t,
+		store:    store,
+		signedIn: &models.SignedInUser{OrgId: admin.OrgId, OrgRole: models.ROLE_ADMIN, IsServiceAccount: true},
+	}
+
+	tokenService := auth.NewFakeUserAuthTokenService()
+	tokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userId int64) error {
+		sc.revoked = append(sc.revoked, userId)
+		return nil
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.AutoAssignOrgRole = "Viewer"
+	routeRegister := routing.NewRouteRegister()
+	ProvideService(cfg, featuremgmt.WithFeatures(featuremgmt.FlagScim), store, tokenService, routeRegister)
+
+	sc.server = web.New()
+	sc.server.Use(func(c *web.Context) {
+		c.Map(&models.ReqContext{
+			Context:      c,
+			IsSignedIn:   true,
+			SignedInUser: sc.signedIn,
+			Logger:       log.New("scim-test"),
+		})
+	})
+	routeRegister.Register(sc.server.Router)
+	return sc
+}
+
+func (sc *scimScenario) request(method, path string, body interface{}, result interface{}) int {
+	sc.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(sc.t, err)
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, path, reader)
+	require.NoError(sc.t, err)
+	req.Header.Set("Content-Type", contentType)
+
+	recorder := httptest.NewRecorder()
+	sc.server.ServeHTTP(recorder, req)
+	if result != nil && recorder.Body.Len() > 0 {
+		require.NoError(sc.t, json.Unmarshal(recorder.Body.Bytes(), result), recorder.Body.String())
+	}
+	return recorder.Code
+}
+
+func TestSCIMAuthentication(t *testing.T) {
+	sc := setupScenario(t)
+
+	sc.signedIn.IsServiceAccount = false
+	require.Equal(t, http.StatusUnauthorized, sc.request(http.MethodGet, "/api/scim/v2/Users", nil, nil))
+
+	sc.signedIn.IsServiceAccount = true
+	sc.signedIn.OrgRole = models.ROLE_EDITOR
+	require.Equal(t, http.StatusForbidden, sc.request(http.MethodGet, "/api/scim/v2/Users", nil, nil))
+}
+
+func TestSCIMUsers(t *testing.T) {
+	sc := setupScenario(t)
+
+	created := User{}
+	code := sc.request(http.MethodPost, "/api/scim/v2/Users", map[string]interface{}{
+		"schemas":    []string{SchemaUser},
+		"userName":   "bjensen",
+		"externalId": "00u1",
+		"name":       map[string]interface{}{"givenName": "Barbara", "familyName": "Jensen"},
+		"emails":     []interface{}{map[string]interface{}{"value": "bjensen@example.com", "primary": true}},
+	}, &created)
+	require.Equal(t, http.StatusCreated, code)
+	require.NotEmpty(t, created.ID)
+	require.Equal(t, "Barbara Jensen", created.DisplayName)
+	require.Equal(t, "00u1", created.ExternalID)
+	require.True(t, created.IsActive())
+	require.Equal(t, "http://localhost:3000/api/scim/v2/Users/"+created.ID, created.Meta.Location)
+
+	t.Run("creating a duplicate user is a conflict", func(t *testing.T) {
+		errResp := ErrorResponse{}
+		code := sc.request(http.MethodPost, "/api/scim/v2/Users", map[string]interface{}{"userName": "bjensen"}, &errResp)
+		require.Equal(t, http.StatusConflict, code)
+		require.Equal(t, "uniqueness", errResp.ScimType)
+	})
+
+	t.Run("filter users", func(t *testing.T) {
+		list := ListResponse{}
+		code := sc.request(http.MethodGet, "/api/scim/v2/Users?filter="+url.QueryEscape(`externalId eq "00u1"`), nil, &list)
+		require.Equal(t, http.StatusOK, code)
+		require.EqualValues(t, 1, list.TotalResults)
+
+		// Grafana server admin is in the organization, service accounts are not listed.
+		code = sc.request(http.MethodGet, "/api/scim/v2/Users?count=1", nil, &list)
+		require.Equal(t, http.StatusOK, code)
+		require.EqualValues(t, 2, list.TotalResults)
+		require.Len(t, list.Resources, 1)
+
+		errResp := ErrorResponse{}
+		code = sc.request(http.MethodGet, "/api/scim/v2/Users?filter="+url.QueryEscape(`userName eq`), nil, &errResp)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Equal(t, "invalidFilter", errResp.ScimType)
+	})
+
+	t.Run("deactivating a user revokes their sessions", func(t *testing.T) {
+		patched := User{}
+		code := sc.request(http.MethodPatch, "/api/scim/v2/Users/"+created.ID, PatchRequest{
+			Schemas:    []string{SchemaPatchOp},
+			Operations: []PatchOperation{{Op: "Replace", Path: "active", Value: false}},
+		}, &patched)
+		require.Equal(t, http.StatusOK, code)
+		require.False(t, patched.IsActive())
+		require.Len(t, sc.revoked, 1)
+
+		query := models.GetUserByIdQuery{Id: sc.revoked[0]}
+		require.NoError(t, sc.store.GetUserById(context.Background(), &query))
+		require.True(t, query.Result.IsDisabled)
+	})
+
+	t.Run("replace user", func(t *testing.T) {
+		replaced := User{}
+		active := true
+		code := sc.request(http.MethodPut, "/api/scim/v2/Users/"+created.ID, User{
+			UserName: "barbara",
+			Name:     &Name{Formatted: "Barbara J"},
+			Emails:   []MultiValued{{Value: "barbara@example.com"}},
+			Active:   &active,
+		}, &replaced)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "barbara", replaced.UserName)
+		require.Equal(t, "barbara@example.com", replaced.PrimaryEmail())
+		require.Empty(t, replaced.ExternalID)
+		require.True(t, replaced.IsActive())
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		sc.revoked = nil
+		require.Equal(t, http.StatusNoContent, sc.request(http.MethodDelete, "/api/scim/v2/Users/"+created.ID, nil, nil))
+		require.Equal(t, http.StatusNotFound, sc.request(http.MethodGet, "/api/scim/v2/Users/"+created.ID, nil, nil))
+		require.Len(t, sc.revoked, 1)
+	})
+}
+
+func TestSCIMUsersOfOtherOrgs(t *testing.T) {
+	sc := setupScenario(t)
+
+	// The user is created in an organization of their own and then added to
+	// the one of the service account.
+	user, err := sc.store.CreateUser(context.Background(), models.CreateUserCommand{Login: "shared", Email: "shared@example.com"})
+	require.NoError(t, err)
+	require.NotEqual(t, sc.signedIn.OrgId, user.OrgId)
+	require.NoError(t, sc.store.AddOrgUser(context.Background(), &models.AddOrgUserCommand{
+		OrgId: sc.signedIn.OrgId, UserId: user.Id, Role: models.ROLE_VIEWER,
+	}))
+	id := strconv.FormatInt(user.Id, 10)
+
+	t.Run("profile changes are forbidden", func(t *testing.T) {
+		code := sc.request(http.MethodPatch, "/api/scim/v2/Users/"+id, PatchRequest{
+			Schemas:    []string{SchemaPatchOp},
+			Operations: []PatchOperation{{Op: "Replace", Path: "active", Value: false}},
+		}, nil)
+		require.Equal(t, http.StatusForbidden, code)
+
+		code = sc.request(http.MethodPatch, "/api/scim/v2/Users/"+id, PatchRequest{
+			Schemas:    []string{SchemaPatchOp},
+			Operations: []PatchOperation{{Op: "Replace", Path: "userName", Value: "taken-over"}},
+		}, nil)
+		require.Equal(t, http.StatusForbidden, code)
+		require.Empty(t, sc.revoked)
+	})
+
+	t.Run("external id changes are forbidden", func(t *testing.T) {
+		// The external id was set by the identity provider of the other
+		// organization.
+		err := sc.store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(&models.UserAuth{UserId: user.Id, AuthModule: AuthModule, AuthId: "00u1", Created: time.Now()})
+			return err
+		})
+		require.NoError(t, err)
+
+		code := sc.request(http.MethodPatch, "/api/scim/v2/Users/"+id, PatchRequest{
+			Schemas:    []string{SchemaPatchOp},
+			Operations: []PatchOperation{{Op: "Replace", Path: "externalId", Value: "00u2"}},
+		}, nil)
+		require.Equal(t, http.StatusForbidden, code)
+
+		code = sc.request(http.MethodPatch, "/api/scim/v2/Users/"+id, PatchRequest{
+			Schemas:    []string{SchemaPatchOp},
+			Operations: []PatchOperation{{Op: "Remove", Path: "externalId"}},
+		}, nil)
+		require.Equal(t, http.StatusForbidden, code)
+
+		fetched := User{}
+		require.Equal(t, http.StatusOK, sc.request(http.MethodGet, "/api/scim/v2/Users/"+id, nil, &fetched))
+		require.Equal(t, "00u1", fetched.ExternalID)
+	})
+
+	t.Run("server admins can change the profile", func(t *testing.T) {
+		sc.signedIn.IsGrafanaAdmin = true
+		t.Cleanup(func() { sc.signedIn.IsGrafanaAdmin = false })
+
+		patched := User{}
+		code := sc.request(http.MethodPatch, "/api/scim/v2/Users/"+id, PatchRequest{
+			Schemas:    []string{SchemaPatchOp},
+			Operations: []PatchOperation{{Op: "Replace", Path: "displayName", Value: "Shared User"}},
+		}, &patched)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "Shared User", patched.DisplayName)
+	})
+
+	t.Run("delete only removes the user from the organization", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, sc.request(http.MethodDelete, "/api/scim/v2/Users/"+id, nil, nil))
+		require.Equal(t, http.StatusNotFound, sc.request(http.MethodGet, "/api/scim/v2/Users/"+id, nil, nil))
+		require.Empty(t, sc.revoked)
+
+		query := models.GetUserByIdQuery{Id: user.Id}
+		require.NoError(t, sc.store.GetUserById(context.Background(), &query))
+		require.False(t, query.Result.IsDisabled)
+	})
+}
+
+func TestSCIMGroups(t *testing.T) {
+	sc := setupScenario(t)
+
+	users := make([]User, 2)
+	for i, login := range []string{"alice", "bob"} {
+		code := sc.request(http.MethodPost, "/api/scim/v2/Users", User{UserName: login}, &users[i])
+		require.Equal(t, http.StatusCreated, code)
+	}
+
+	group := Group{}
+	code := sc.request(http.MethodPost, "/api/scim/v2/Groups", Group{
+		DisplayName: "Ops",
+		Members:     []MultiValued{{Value: users[0].ID}},
+	}, &group)
+	require.Equal(t, http.StatusCreated, code)
+	require.Len(t, group.Members, 1)
+	require.Equal(t, "alice", group.Members[0].Display)
+
+	t.Run("unknown members are rejected", func(t *testing.T) {
+		code := sc.request(http.MethodPost, "/api/scim/v2/Groups", Group{
+			DisplayName: "Dev",
+			Members:     []MultiValued{{Value: "9999"}},
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		list := ListResponse{}
+		sc.request(http.MethodGet, "/api/scim/v2/Groups", nil, &list)
+		require.EqualValues(t, 1, list.TotalResults)
+	})
+
+	t.Run("patch members", func(t *testing.T) {
+		patched := Group{}
+		code := sc.request(http.MethodPatch, "/api/scim/v2/Groups/"+group.ID, PatchRequest{
+			Schemas: []string{SchemaPatchOp},
+			Operations: []PatchOperation{
+				{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": users[1].ID}}},
+				{Op: "remove", Path: `members[value eq "` + users[0].ID + `"]`},
+				{Op: "replace", Path: "displayName", Value: "SRE"},
+			},
+		}, &patched)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "SRE", patched.DisplayName)
+		require.Equal(t, []MultiValued{{Value: users[1].ID, Display: "bob", Ref: "http://localhost:3000/api/scim/v2/Users/" + users[1].ID}}, patched.Members)
+	})
+
+	t.Run("filter groups by member", func(t *testing.T) {
+		list := ListResponse{}
+		code := sc.request(http.MethodGet, "/api/scim/v2/Groups?filter="+url.QueryEscape(`members[value eq "`+users[1].ID+`"]`), nil, &list)
+		require.Equal(t, http.StatusOK, code)
+		require.EqualValues(t, 1, list.TotalResults)
+
+		user := User{}
+		sc.request(http.MethodGet, "/api/scim/v2/Users/"+users[1].ID, nil, &user)
+		require.Len(t, user.Groups, 1)
+		require.Equal(t, "SRE", user.Groups[0].Display)
+	})
+
+	t.Run("delete group", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, sc.request(http.MethodDelete, "/api/scim/v2/Groups/"+group.ID, nil, nil))
+		require.Equal(t, http.StatusNotFound, sc.request(http.MethodGet, "/api/scim/v2/Groups/"+group.ID, nil, nil))
+	})
+}
+
+func TestSCIMDiscovery(t *testing.T) {
+	sc := setupScenario(t)
+
+	config := map[string]interface{}{}
+	require.Equal(t, http.StatusOK, sc.request(http.MethodGet, "/api/scim/v2/ServiceProviderConfig", nil, &config))
+	require.Equal(t, map[string]interface{}{"supported": true}, config["patch"])
+
+	list := ListResponse{}
+	require.Equal(t, http.StatusOK, sc.request(http.MethodGet, "/api/scim/v2/Schemas", nil, &list))
+	require.EqualValues(t, 2, list.TotalResults)
+
+	require.Equal(t, http.StatusOK, sc.request(http.MethodGet, "/api/scim/v2/ResourceTypes/User", nil, nil))
+	require.Equal(t, http.StatusNotFound, sc.request(http.MethodGet, "/api/scim/v2/Schemas/unknown", nil, nil))
+}
@@ -0,0 +1,561 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var userAttributes = map[string]attribute{
+	"id":                {column: "u.id", kind: kindID},
+	"username":          {column: "u.login"},
+	"displayname":       {column: "u.name"},
+	"name.formatted":    {column: "u.name"},
+	"emails":            {column: "u.email"},
+	"emails.value":      {column: "u.email"},
+	"active":            {column: "u.is_disabled", kind: kindBool, invert: true},
+	"externalid":        {column: "auth_id", subquery: "u.id IN (SELECT user_id FROM user_auth WHERE auth_module = '" + AuthModule + "' AND %s)"},
+	"meta.created":      {column: "u.created", kind: kindTime},
+	"meta.lastmodified": {column: "u.updated", kind: kindTime},
+}
+
+// listUsersHandler handles GET /api/scim/v2/Users.
+func (s *Service) listUsersHandler(c *models.ReqContext) response.Response {
+	filter, err := parseFilterParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to parse filter")
+	}
+	startIndex, count := pagination(c)
+
+	users, total, err := s.searchUsers(c.Req.Context(), c.OrgId, filter, startIndex, count)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to search users")
+	}
+	resources, err := s.toUserResources(c.Req.Context(), c.OrgId, users)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to search users")
+	}
+
+	result := ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    make([]interface{}, 0, len(resources)),
+	}
+	for _, r := range resources {
+		result.Resources = append(result.Resources, r)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+// getUserHandler handles GET /api/scim/v2/Users/:id.
+func (s *Service) getUserHandler(c *models.ReqContext) response.Response {
+	user, err := s.getUserByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to get user")
+	}
+	return s.userResponse(c.Req.Context(), c.OrgId, http.StatusOK, user)
+}
+
+// createUserHandler handles POST /api/scim/v2/Users.
+func (s *Service) createUserHandler(c *models.ReqContext) response.Response {
+	resource := User{}
+	if err := web.Bind(c.Req, &resource); err != nil {
+		return errorResponse(http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	user, err := s.createUser(c.Req.Context(), c.OrgId, &resource)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to create user")
+	}
+	return s.userResponse(c.Req.Context(), c.OrgId, http.StatusCreated, user)
+}
+
+// replaceUserHandler handles PUT /api/scim/v2/Users/:id.
+func (s *Service) replaceUserHandler(c *models.ReqContext) response.Response {
+	user, err := s.getUserByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update user")
+	}
+
+	resource := User{}
+	if err := web.Bind(c.Req, &resource); err != nil {
+		return errorResponse(http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	if user, err = s.updateUser(c.Req.Context(), c.SignedInUser, user, &resource); err != nil {
+		return s.toErrorResponse(err, "Failed to update user")
+	}
+	return s.userResponse(c.Req.Context(), c.OrgId, http.StatusOK, user)
+}
+
+// patchUserHandler handles PATCH /api/scim/v2/Users/:id.
+func (s *Service) patchUserHandler(c *models.ReqContext) response.Response {
+	user, err := s.getUserByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update user")
+	}
+
+	patch := PatchRequest{}
+	if err := web.Bind(c.Req, &patch); err != nil {
+		return errorResponse(http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	resources, err := s.toUserResources(c.Req.Context(), c.OrgId, []*models.User{user})
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update user")
+	}
+	resource, err := patchUser(resources[0], patch.Operations)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to update user")
+	}
+
+	if user, err = s.updateUser(c.Req.Context(), c.SignedInUser, user, resource); err != nil {
+		return s.toErrorResponse(err, "Failed to update user")
+	}
+	return s.userResponse(c.Req.Context(), c.OrgId, http.StatusOK, user)
+}
+
+// deleteUserHandler handles DELETE /api/scim/v2/Users/:id.
+func (s *Service) deleteUserHandler(c *models.ReqContext) response.Response {
+	user, err := s.getUserByParam(c)
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to delete user")
+	}
+	if user.IsAdmin {
+		return errorResponse(http.StatusForbidden, "", "Grafana server admins cannot be deprovisioned through SCIM")
+	}
+
+	// Users that belong to other organizations are only removed from this
+	// one and keep their sessions there.
+	cmd := models.RemoveOrgUserCommand{UserId: user.Id, OrgId: c.OrgId, ShouldDeleteOrphanedUser: true}
+	if err := s.sqlStore.RemoveOrgUser(c.Req.Context(), &cmd); err != nil {
+		return s.toErrorResponse(err, "Failed to delete user")
+	}
+
+	if cmd.UserWasDeleted {
+		if err := s.authTokenService.RevokeAllUserTokens(c.Req.Context(), user.Id); err != nil {
+			return s.toErrorResponse(err, "Failed to revoke user sessions")
+		}
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (s *Service) getUserByParam(c *models.ReqContext) (*models.User, error) {
+	id, err := parseID(web.Params(c.Req)[":id"])
+	if err != nil {
+		return nil, err
+	}
+	return s.getUser(c.Req.Context(), c.OrgId, id)
+}
+
+func (s *Service) userResponse(ctx context.Context, orgID int64, status int, user *models.User) response.Response {
+	resources, err := s.toUserResources(ctx, orgID, []*models.User{user})
+	if err != nil {
+		return s.toErrorResponse(err, "Failed to get user")
+	}
+	return scimResponse(status, resources[0])
+}
+
+// patchUser applies PATCH operations to a user through its JSON representation.
+func patchUser(resource *User, operations []PatchOperation) (*User, error) {
+	doc, err := toDocument(resource)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPatch(doc, operations); err != nil {
+		return nil, err
+	}
+
+	// Some identity providers send booleans as strings, e.g. "False".
+	if key := findKey(doc, "active"); doc[key] != nil {
+		if str, ok := doc[key].(string); ok {
+			active, err := strconv.ParseBool(strings.ToLower(str))
+			if err != nil {
+				return nil, fmt.Errorf("%w: active must be a boolean", ErrInvalidValue)
+			}
+			doc[key] = active
+		}
+	}
+
+	patched := &User{}
+	if err := fromDocument(doc, patched); err != nil {
+		return nil, err
+	}
+
+	// Grafana stores a single name, so work out which of the name attributes
+	// the operations changed to know which one to keep.
+	original := Name{}
+	if resource.Name != nil {
+		original = *resource.Name
+	}
+	if patched.Name != nil && *patched.Name != original {
+		if patched.Name.Formatted == original.Formatted {
+			patched.Name.Formatted = ""
+		}
+	} else if patched.DisplayName != resource.DisplayName {
+		patched.Name = nil
+	}
+	return patched, nil
+}
+
+func toDocument(resource interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func fromDocument(doc map[string]interface{}, resource interface{}) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, resource); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidValue, err)
+	}
+	return nil
+}
+
+func (s *Service) toUserResources(ctx context.Context, orgID int64, users []*models.User) ([]*User, error) {
+	ids := make([]int64, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.Id)
+	}
+
+	externalIDs := map[int64]string{}
+	groups := map[int64][]MultiValued{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if len(ids) == 0 {
+			return nil
+		}
+
+		var auths []*models.UserAuth
+		if err := sess.Where("auth_module = ?", AuthModule).In("user_id", ids).Find(&auths); err != nil {
+			return err
+		}
+		for _, a := range auths {
+			externalIDs[a.UserId] = a.AuthId
+		}
+
+		var memberships []struct {
+			UserId int64
+			TeamId int64
+			Name   string
+		}
+		err := sess.Table("team_member").
+			Join("INNER", "team", "team.id = team_member.team_id").
+			Where("team_member.org_id = ?", orgID).
+			In("team_member.user_id", ids).
+			Select("team_member.user_id, team_member.team_id, team.name").
+			Find(&memberships)
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			id := strconv.FormatInt(m.TeamId, 10)
+			groups[m.UserId] = append(groups[m.UserId], MultiValued{
+				Value:   id,
+				Display: m.Name,
+				Ref:     s.location("Groups", id),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*User, 0, len(users))
+	for _, u := range users {
+		id := strconv.FormatInt(u.Id, 10)
+		active := !u.IsDisabled
+		resource := &User{
+			Schemas:     []string{SchemaUser},
+			ID:          id,
+			ExternalID:  externalIDs[u.Id],
+			UserName:    u.Login,
+			DisplayName: u.Name,
+			Active:      &active,
+			Groups:      groups[u.Id],
+			Meta: &Meta{
+				ResourceType: "User",
+				Created:      &u.Created,
+				LastModified: &u.Updated,
+				Location:     s.location("Users", id),
+				Version:      fmt.Sprintf("W/\"%d\"", u.Updated.Unix()),
+			},
+		}
+		if u.Name != "" {
+			resource.Name = &Name{Formatted: u.Name}
+			if i := strings.Index(u.Name, " "); i != -1 {
+				resource.Name.GivenName, resource.Name.FamilyName = u.Name[:i], u.Name[i+1:]
+			} else {
+				resource.Name.GivenName = u.Name
+			}
+		}
+		if u.Email != "" {
+			resource.Emails = []MultiValued{{Value: u.Email, Type: "work", Primary: true}}
+		}
+		result = append(result, resource)
+	}
+	return result, nil
+}
+
+func orgUsersSession(sess *sqlstore.DBSession, orgID int64) *sqlstore.DBSession {
+	sess.Table("user").Alias("u").
+		Join("INNER", "org_user", "org_user.user_id = u.id").
+		Where("org_user.org_id = ? AND u.is_service_account = ?", orgID, false)
+	return sess
+}
+
+func (s *Service) searchUsers(ctx context.Context, orgID int64, filter Filter, startIndex, count int) ([]*models.User, int64, error) {
+	var cond string
+	var params []interface{}
+	if filter != nil {
+		var err error
+		if cond, params, err = filterToSQL(filter, userAttributes); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	users := make([]*models.User, 0)
+	var total int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		countSess := orgUsersSession(sess, orgID)
+		if cond != "" {
+			countSess.And(cond, params...)
+		}
+		var err error
+		if total, err = countSess.Count(&models.User{}); err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+
+		findSess := orgUsersSession(sess, orgID)
+		if cond != "" {
+			findSess.And(cond, params...)
+		}
+		return findSess.Select("u.*").Asc("u.id").Limit(count, startIndex-1).Find(&users)
+	})
+	return users, total, err
+}
+
+func (s *Service) getUser(ctx context.Context, orgID, userID int64) (*models.User, error) {
+	user := &models.User{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := orgUsersSession(sess, orgID).And("u.id = ?", userID).Select("u.*").Get(user)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *Service) createUser(ctx context.Context, orgID int64, resource *User) (*models.User, error) {
+	if resource.UserName == "" {
+		return nil, fmt.Errorf("%w: userName is required", ErrInvalidValue)
+	}
+
+	role := models.RoleType(s.cfg.AutoAssignOrgRole)
+	if !role.IsValid() {
+		role = models.ROLE_VIEWER
+	}
+
+	var user *models.User
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.sqlStore.CreateUser(ctx, models.CreateUserCommand{
+			Login:        resource.UserName,
+			Email:        resource.PrimaryEmail(),
+			Name:         resource.FullName(),
+			IsDisabled:   !resource.IsActive(),
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.sqlStore.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: orgID, UserId: user.Id, Role: role}); err != nil {
+			return err
+		}
+		return s.setExternalID(ctx, user.Id, resource.ExternalID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getUser(ctx, orgID, user.Id)
+}
+
+// updateUser replaces the attributes of user with the ones of resource.
+// Deactivating a user revokes all of their sessions.
+//
+// The login, email, name, active and externalId attributes belong to the user
+// across all organizations, so only users whose single organization is the one
+// of the service account can have them changed, unless the caller is a Grafana
+// server admin.
+func (s *Service) updateUser(ctx context.Context, signedInUser *models.SignedInUser, user *models.User, resource *User) (*models.User, error) {
+	orgID := signedInUser.OrgId
+	if resource.UserName == "" {
+		return nil, fmt.Errorf("%w: userName is required", ErrInvalidValue)
+	}
+	if user.IsAdmin {
+		return nil, fmt.Errorf("%w: Grafana server admins cannot be modified through SCIM", ErrInvalidValue)
+	}
+
+	email := resource.PrimaryEmail()
+	if email == "" {
+		email = resource.UserName
+	}
+	active := resource.IsActive()
+
+	externalID, err := s.getExternalID(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	profileChanged := resource.UserName != user.Login || email != user.Email ||
+		resource.FullName() != user.Name || active == user.IsDisabled
+	if (profileChanged || resource.ExternalID != externalID) && !signedInUser.IsGrafanaAdmin {
+		managed, err := s.isOrgManagedUser(ctx, orgID, user.Id)
+		if err != nil {
+			return nil, err
+		}
+		if !managed {
+			return nil, ErrForbidden
+		}
+	}
+
+	err = s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		if !profileChanged {
+			return s.setExternalID(ctx, user.Id, resource.ExternalID)
+		}
+
+		err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			taken, err := sess.Where("id <> ? AND (login = ? OR email = ?)", user.Id, resource.UserName, email).Exist(&models.User{})
+			if err != nil {
+				return err
+			}
+			if taken {
+				return ErrUniqueness
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.sqlStore.UpdateUser(ctx, &models.UpdateUserCommand{
+			UserId: user.Id,
+			Login:  resource.UserName,
+			Email:  email,
+			Name:   resource.FullName(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if active == user.IsDisabled {
+			err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				_, err := sess.ID(user.Id).Cols("is_disabled").Update(&models.User{IsDisabled: !active})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return s.setExternalID(ctx, user.Id, resource.ExternalID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !active && !user.IsDisabled {
+		if err := s.authTokenService.RevokeAllUserTokens(ctx, user.Id); err != nil {
+			return nil, err
+		}
+		s.log.Info("Deactivated user through SCIM", "userId", user.Id, "orgId", orgID)
+	}
+
+	return s.getUser(ctx, orgID, user.Id)
+}
+
+// isOrgManagedUser reports whether the organization is the only one the user
+// is a member of.
+func (s *Service) isOrgManagedUser(ctx context.Context, orgID, userID int64) (bool, error) {
+	managed := false
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		others, err := sess.Where("user_id = ? AND org_id <> ?", userID, orgID).Count(&models.OrgUser{})
+		managed = others == 0
+		return err
+	})
+	return managed, err
+}
+
+// getExternalID returns the identity provider's id of the user, or an empty
+// string if none is stored.
+func (s *Service) getExternalID(ctx context.Context, userID int64) (string, error) {
+	auth := models.UserAuth{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Where("user_id = ? AND auth_module = ?", userID, AuthModule).Get(&auth)
+		return err
+	})
+	return auth.AuthId, err
+}
+
+// setExternalID stores the identity provider's id of the user in user_auth.
+// The row is updated in place so that it does not become the user's most
+// recent auth entry on every provisioning run.
+func (s *Service) setExternalID(ctx context.Context, userID int64, externalID string) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if externalID == "" {
+			_, err := sess.Exec("DELETE FROM user_auth WHERE user_id = ? AND auth_module = ?", userID, AuthModule)
+			return err
+		}
+
+		existing := models.UserAuth{}
+		has, err := sess.Where("user_id = ? AND auth_module = ?", userID, AuthModule).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(&models.UserAuth{
+				UserId:     userID,
+				AuthModule: AuthModule,
+				AuthId:     externalID,
+				Created:    time.Now(),
+			})
+			return err
+		}
+		if existing.AuthId == externalID {
+			return nil
+		}
+		_, err = sess.Exec("UPDATE user_auth SET auth_id = ? WHERE id = ?", externalID, existing.Id)
+		return err
+	})
+}
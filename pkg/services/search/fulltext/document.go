@@ -0,0 +1,165 @@
+package fulltext
+
+import (
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Field is a part of a dashboard that is indexed and can be used in field filters.
+type Field string
+
+const (
+	FieldTitle        Field = "title"
+	FieldTag          Field = "tag"
+	FieldPanel        Field = "panel"
+	FieldDescription  Field = "description"
+	FieldDatasource   Field = "datasource"
+	FieldQuery        Field = "query"
+	FieldVariable     Field = "variable"
+	FieldLibraryPanel Field = "library"
+)
+
+// fieldBoosts weights matches by the field they are found in, a match in the title is worth more than a match in
+// the text of a query.
+var fieldBoosts = map[Field]float64{
+	FieldTitle:        5,
+	FieldTag:          3,
+	FieldPanel:        3,
+	FieldVariable:     2,
+	FieldLibraryPanel: 2,
+	FieldDatasource:   1.5,
+	FieldDescription:  1,
+	FieldQuery:        1,
+}
+
+// queryKeys are the properties of a panel target that hold the query text for the most common datasources.
+var queryKeys = []string{"expr", "expression", "query", "queryText", "rawSql", "target", "measurement", "metric", "metricName"}
+
+type document struct {
+	id       int64
+	orgID    int64
+	folderID int64
+	version  int
+	title    string
+
+	fields map[Field][]string
+	terms  map[string]termFreqs
+}
+
+// termFreqs counts the occurrences of a term in each field of a document.
+type termFreqs map[Field]int
+
+func newDocument(dash *models.Dashboard) *document {
+	doc := &document{
+		id:       dash.Id,
+		orgID:    dash.OrgId,
+		folderID: dash.FolderId,
+		version:  dash.Version,
+		title:    dash.Title,
+		fields:   make(map[Field][]string),
+		terms:    make(map[string]termFreqs),
+	}
+
+	doc.add(FieldTitle, dash.Title)
+	for _, tag := range dash.GetTags() {
+		doc.add(FieldTag, tag)
+	}
+
+	doc.addPanels(dash.Data.Get("panels").MustArray())
+	for _, row := range dash.Data.Get("rows").MustArray() {
+		if row, ok := row.(map[string]interface{}); ok {
+			doc.addPanels(asArray(row["panels"]))
+		}
+	}
+
+	for _, variable := range dash.Data.Get("templating").Get("list").MustArray() {
+		variable, ok := variable.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		doc.add(FieldVariable, asString(variable["name"]))
+		doc.add(FieldVariable, asString(variable["label"]))
+		doc.addDatasource(variable["datasource"])
+		switch query := variable["query"].(type) {
+		case string:
+			doc.add(FieldQuery, query)
+		case map[string]interface{}:
+			doc.add(FieldQuery, asString(query["query"]))
+		}
+	}
+
+	return doc
+}
+
+func (d *document) addPanels(panels []interface{}) {
+	for _, panel := range panels {
+		panel, ok := panel.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		d.add(FieldPanel, asString(panel["title"]))
+		d.add(FieldDescription, asString(panel["description"]))
+		d.addDatasource(panel["datasource"])
+
+		if libraryPanel, ok := panel["libraryPanel"].(map[string]interface{}); ok {
+			d.add(FieldLibraryPanel, asString(libraryPanel["uid"]))
+			d.add(FieldLibraryPanel, asString(libraryPanel["name"]))
+		}
+
+		for _, target := range asArray(panel["targets"]) {
+			target, ok := target.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			d.addDatasource(target["datasource"])
+			for _, key := range queryKeys {
+				d.add(FieldQuery, asString(target[key]))
+			}
+		}
+
+		// collapsed rows hold their panels
+		d.addPanels(asArray(panel["panels"]))
+	}
+}
+
+// addDatasource indexes a datasource reference, which is either an object with the datasource uid or the name of the
+// datasource in dashboards that have not been migrated yet.
+func (d *document) addDatasource(ref interface{}) {
+	switch ref := ref.(type) {
+	case string:
+		d.add(FieldDatasource, ref)
+	case map[string]interface{}:
+		d.add(FieldDatasource, asString(ref["uid"]))
+	}
+}
+
+func (d *document) add(field Field, value string) {
+	if value == "" {
+		return
+	}
+	for _, existing := range d.fields[field] {
+		if existing == value {
+			return
+		}
+	}
+
+	d.fields[field] = append(d.fields[field], value)
+	for _, term := range tokenize(value) {
+		freqs, ok := d.terms[term]
+		if !ok {
+			freqs = make(termFreqs)
+			d.terms[term] = freqs
+		}
+		freqs[field]++
+	}
+}
+
+func asString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+func asArray(value interface{}) []interface{} {
+	a, _ := value.([]interface{})
+	return a
+}
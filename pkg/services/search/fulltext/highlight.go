@@ -0,0 +1,161 @@
+package fulltext
+
+import (
+	"sort"
+	"unicode"
+)
+
+const (
+	// maxFragmentLength is the number of characters of a field value returned in a highlight.
+	maxFragmentLength = 150
+	// fragmentContext is the number of characters kept before the first match when a value is shortened.
+	fragmentContext = 40
+	// maxHighlights is the number of highlights returned for a dashboard.
+	maxHighlights = 5
+)
+
+// highlightOrder is the order in which the fields of a dashboard are highlighted.
+var highlightOrder = []Field{
+	FieldTitle, FieldPanel, FieldTag, FieldVariable, FieldLibraryPanel, FieldDatasource, FieldDescription, FieldQuery,
+}
+
+// Highlight is a field value, or a fragment of it, where the query matched.
+type Highlight struct {
+	Field Field  `json:"field"`
+	Text  string `json:"text"`
+	// Matches are the positions of the matches in Text, in characters.
+	Matches []Range `json:"matches"`
+}
+
+// Range is a half-open range of characters.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Highlights returns where the query matched the dashboard.
+func (m *Match) Highlights(q Query) []Highlight {
+	highlights := make([]Highlight, 0)
+	for _, field := range highlightOrder {
+		for _, value := range m.doc.fields[field] {
+			matches := findMatches(field, value, q)
+			if len(matches) == 0 {
+				continue
+			}
+
+			text, matches := fragment([]rune(value), matches)
+			highlights = append(highlights, Highlight{Field: field, Text: text, Matches: matches})
+			if len(highlights) == maxHighlights {
+				return highlights
+			}
+		}
+	}
+	return highlights
+}
+
+func findMatches(field Field, value string, q Query) []Range {
+	text := foldRunes(value)
+
+	var matches []Range
+	for _, term := range q.Terms {
+		matches = append(matches, findRunes(text, foldRunes(term), true)...)
+	}
+	for _, phrase := range q.Phrases {
+		matches = append(matches, findRunes(text, foldRunes(phrase), false)...)
+	}
+	for _, filter := range q.Filters {
+		if filter.Field == field {
+			matches = append(matches, findRunes(text, foldRunes(filter.Value), false)...)
+		}
+	}
+
+	return mergeRanges(matches)
+}
+
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// findRunes returns the positions of needle in text, wordStart restricts them to the start of words like terms are
+// matched by the index.
+func findRunes(text, needle []rune, wordStart bool) []Range {
+	var matches []Range
+	if len(needle) == 0 {
+		return matches
+	}
+
+	for i := 0; i+len(needle) <= len(text); i++ {
+		if wordStart && i > 0 && isWordRune(text[i-1]) {
+			continue
+		}
+		if string(text[i:i+len(needle)]) == string(needle) {
+			matches = append(matches, Range{Start: i, End: i + len(needle)})
+		}
+	}
+	return matches
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func mergeRanges(ranges []Range) []Range {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(a, b int) bool { return ranges[a].Start < ranges[b].Start })
+	merged := []Range{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			if r.End > last.End {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// fragment shortens long values around their first match, the matches that are cut off are dropped.
+func fragment(value []rune, matches []Range) (string, []Range) {
+	if len(value) <= maxFragmentLength {
+		return string(value), matches
+	}
+
+	start := matches[0].Start - fragmentContext
+	if start < 0 {
+		start = 0
+	}
+	end := start + maxFragmentLength
+	if end > len(value) {
+		end = len(value)
+		start = end - maxFragmentLength
+	}
+
+	var prefix, suffix string
+	offset := -start
+	if start > 0 {
+		prefix = "…"
+		offset++
+	}
+	if end < len(value) {
+		suffix = "…"
+	}
+
+	shifted := make([]Range, 0, len(matches))
+	for _, m := range matches {
+		if m.Start < start || m.End > end {
+			continue
+		}
+		shifted = append(shifted, Range{Start: m.Start + offset, End: m.End + offset})
+	}
+
+	return prefix + string(value[start:end]) + suffix, shifted
+}
@@ -0,0 +1,224 @@
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// prefixMatchWeight lowers the score of terms that only match the start of a word.
+const prefixMatchWeight = 0.5
+
+// Index is an inverted index of the dashboards of every organization. It is safe for concurrent use.
+type Index struct {
+	mu   sync.RWMutex
+	orgs map[int64]*orgIndex
+}
+
+type orgIndex struct {
+	docs     map[int64]*document
+	postings map[string]map[int64]termFreqs
+}
+
+// Match is a dashboard matching a query.
+type Match struct {
+	DashboardID int64
+	Score       float64
+
+	doc *document
+}
+
+func NewIndex() *Index {
+	return &Index{orgs: make(map[int64]*orgIndex)}
+}
+
+func (i *Index) add(doc *document) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	org, ok := i.orgs[doc.orgID]
+	if !ok {
+		org = &orgIndex{docs: make(map[int64]*document), postings: make(map[string]map[int64]termFreqs)}
+		i.orgs[doc.orgID] = org
+	}
+
+	org.remove(doc.id)
+	org.docs[doc.id] = doc
+	for term, freqs := range doc.terms {
+		postings, ok := org.postings[term]
+		if !ok {
+			postings = make(map[int64]termFreqs)
+			org.postings[term] = postings
+		}
+		postings[doc.id] = freqs
+	}
+}
+
+func (i *Index) remove(orgID int64, id int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if org, ok := i.orgs[orgID]; ok {
+		org.remove(id)
+	}
+}
+
+// versions returns the version of every indexed dashboard by organization and id.
+func (i *Index) versions() map[int64]map[int64]int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	versions := make(map[int64]map[int64]int, len(i.orgs))
+	for orgID, org := range i.orgs {
+		versions[orgID] = make(map[int64]int, len(org.docs))
+		for id, doc := range org.docs {
+			versions[orgID][id] = doc.version
+		}
+	}
+	return versions
+}
+
+func (o *orgIndex) remove(id int64) {
+	doc, ok := o.docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range doc.terms {
+		delete(o.postings[term], id)
+		if len(o.postings[term]) == 0 {
+			delete(o.postings, term)
+		}
+	}
+	delete(o.docs, id)
+}
+
+// Search returns the dashboards of an organization matching the query, the best matches first.
+func (i *Index) Search(orgID int64, q Query) []*Match {
+	if q.IsEmpty() {
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	org, ok := i.orgs[orgID]
+	if !ok {
+		return nil
+	}
+
+	var scores map[int64]float64
+	for _, term := range q.Terms {
+		termScores := org.scoreTerm(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	if scores == nil {
+		scores = make(map[int64]float64, len(org.docs))
+		for id := range org.docs {
+			scores[id] = 0
+		}
+	}
+
+	matches := make([]*Match, 0, len(scores))
+	for id, score := range scores {
+		doc := org.docs[id]
+		if valuesScore, ok := doc.scoreValues(q); ok {
+			matches = append(matches, &Match{DashboardID: id, Score: score + valuesScore, doc: doc})
+		}
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		titleA, titleB := strings.ToLower(matches[a].doc.title), strings.ToLower(matches[b].doc.title)
+		if titleA != titleB {
+			return titleA < titleB
+		}
+		return matches[a].DashboardID < matches[b].DashboardID
+	})
+
+	return matches
+}
+
+// scoreTerm scores the dashboards with a word equal to or starting with term. Rare words and words found in
+// important fields score higher.
+func (o *orgIndex) scoreTerm(term string) map[int64]float64 {
+	scores := make(map[int64]float64)
+	for word, postings := range o.postings {
+		if !strings.HasPrefix(word, term) {
+			continue
+		}
+
+		weight := o.idf(len(postings))
+		if word != term {
+			weight *= prefixMatchWeight
+		}
+
+		for id, freqs := range postings {
+			var score float64
+			for field, tf := range freqs {
+				score += fieldBoosts[field] * float64(tf) / float64(tf+1)
+			}
+			scores[id] += weight * score
+		}
+	}
+	return scores
+}
+
+func (o *orgIndex) idf(docFreq int) float64 {
+	return 1 + math.Log(float64(len(o.docs))/float64(docFreq))
+}
+
+// scoreValues checks that the phrases and field filters of the query match the dashboard and scores them by the
+// fields they are found in.
+func (d *document) scoreValues(q Query) (float64, bool) {
+	var score float64
+	for _, phrase := range q.Phrases {
+		found := false
+		for field, values := range d.fields {
+			for _, value := range values {
+				if containsFold(value, phrase) {
+					score += 2 * fieldBoosts[field]
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	for _, filter := range q.Filters {
+		found := false
+		for _, value := range d.fields[filter.Field] {
+			if containsFold(value, filter.Value) {
+				score += 2 * fieldBoosts[filter.Field]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	return score, true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,169 @@
+package fulltext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected Query
+	}{
+		{query: "", expected: Query{}},
+		{query: "CPU usage", expected: Query{Terms: []string{"cpu", "usage"}}},
+		{query: "node_cpu_seconds_total", expected: Query{Terms: []string{"node", "cpu", "seconds", "total"}}},
+		{query: `"cpu usage" memory`, expected: Query{Terms: []string{"memory"}, Phrases: []string{"cpu usage"}}},
+		{query: "datasource:prom-prod", expected: Query{Filters: []FieldFilter{{Field: FieldDatasource, Value: "prom-prod"}}}},
+		{
+			query: `Panel:"CPU usage" variable:instance`,
+			expected: Query{Filters: []FieldFilter{
+				{Field: FieldPanel, Value: "CPU usage"},
+				{Field: FieldVariable, Value: "instance"},
+			}},
+		},
+		{query: "job:http_requests:rate5m", expected: Query{Terms: []string{"job", "http", "requests", "rate5m"}}},
+		{query: `datasource: "unterminated`, expected: Query{Phrases: []string{"unterminated"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseQuery(tt.query))
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	index := NewIndex()
+	index.add(newDocument(testDashboard(1, 1, map[string]interface{}{
+		"title": "Node exporter",
+		"tags":  []interface{}{"linux"},
+		"panels": []interface{}{
+			map[string]interface{}{
+				"title":       "CPU usage",
+				"description": "Time spent by the CPUs in each mode",
+				"datasource":  map[string]interface{}{"type": "prometheus", "uid": "prom-prod"},
+				"targets": []interface{}{
+					map[string]interface{}{"refId": "A", "expr": `rate(node_cpu_seconds_total{instance="$instance"}[5m])`},
+				},
+			},
+			map[string]interface{}{
+				"type":      "row",
+				"title":     "Disks",
+				"collapsed": true,
+				"panels": []interface{}{
+					map[string]interface{}{
+						"title":        "Disk IO",
+						"libraryPanel": map[string]interface{}{"uid": "disk-io", "name": "Disk IO"},
+					},
+				},
+			},
+		},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"name": "instance", "datasource": "prom-prod", "query": "label_values(up, instance)"},
+			},
+		},
+	})))
+	index.add(newDocument(testDashboard(2, 1, map[string]interface{}{
+		"title": "MySQL overview",
+		"rows": []interface{}{
+			map[string]interface{}{
+				"panels": []interface{}{
+					map[string]interface{}{
+						"title":      "Queries per second",
+						"datasource": "mysql-prod",
+						"targets":    []interface{}{map[string]interface{}{"rawSql": "SELECT count(*) FROM queries"}},
+					},
+				},
+			},
+		},
+	})))
+	index.add(newDocument(testDashboard(3, 2, map[string]interface{}{"title": "CPU usage in another org"})))
+
+	ids := func(matches []*Match) []int64 {
+		result := make([]int64, 0, len(matches))
+		for _, m := range matches {
+			result = append(result, m.DashboardID)
+		}
+		return result
+	}
+
+	t.Run("should match words of every field", func(t *testing.T) {
+		assert.Equal(t, []int64{1}, ids(index.Search(1, ParseQuery("cpu"))))
+		assert.Equal(t, []int64{1}, ids(index.Search(1, ParseQuery("node_cpu_seconds"))))
+		assert.Equal(t, []int64{1}, ids(index.Search(1, ParseQuery("disk-io"))))
+		assert.Equal(t, []int64{2}, ids(index.Search(1, ParseQuery("count queries"))))
+		assert.Equal(t, []int64{3}, ids(index.Search(2, ParseQuery("cpu"))))
+		assert.Empty(t, index.Search(1, ParseQuery("cpu memory")))
+	})
+
+	t.Run("should match the start of words", func(t *testing.T) {
+		assert.Equal(t, []int64{2, 1}, ids(index.Search(1, ParseQuery("prod"))), "ties are sorted by title")
+		assert.Equal(t, []int64{2}, ids(index.Search(1, ParseQuery("mys"))))
+	})
+
+	t.Run("should rank matches by field", func(t *testing.T) {
+		index.add(newDocument(testDashboard(4, 1, map[string]interface{}{"title": "Queries"})))
+		defer index.remove(1, 4)
+
+		assert.Equal(t, []int64{4, 2}, ids(index.Search(1, ParseQuery("queries"))))
+	})
+
+	t.Run("should filter by field", func(t *testing.T) {
+		assert.Equal(t, []int64{1}, ids(index.Search(1, ParseQuery("datasource:prom-prod"))))
+		assert.Equal(t, []int64{2}, ids(index.Search(1, ParseQuery("datasource:mysql-prod"))))
+		assert.Equal(t, []int64{1}, ids(index.Search(1, ParseQuery(`panel:"cpu usage"`))))
+		assert.Equal(t, []int64{1}, ids(index.Search(1, ParseQuery("variable:instance library:disk-io"))))
+		assert.Empty(t, index.Search(1, ParseQuery("title:cpu")))
+		assert.Empty(t, index.Search(1, ParseQuery(`"usage cpu"`)))
+	})
+
+	t.Run("should remove dashboards", func(t *testing.T) {
+		index.add(newDocument(testDashboard(5, 1, map[string]interface{}{"title": "Removed"})))
+		assert.Len(t, index.Search(1, ParseQuery("removed")), 1)
+
+		index.remove(1, 5)
+		assert.Empty(t, index.Search(1, ParseQuery("removed")))
+		assert.NotContains(t, index.orgs[1].postings, "removed")
+	})
+
+	t.Run("should highlight matches", func(t *testing.T) {
+		q := ParseQuery(`cpu datasource:prom`)
+		matches := index.Search(1, q)
+		require.Len(t, matches, 1)
+
+		highlights := matches[0].Highlights(q)
+		require.Len(t, highlights, 4)
+		assert.Equal(t, Highlight{Field: FieldPanel, Text: "CPU usage", Matches: []Range{{Start: 0, End: 3}}}, highlights[0])
+		assert.Equal(t, Highlight{Field: FieldDatasource, Text: "prom-prod", Matches: []Range{{Start: 0, End: 4}}}, highlights[1])
+		assert.Equal(t, FieldDescription, highlights[2].Field)
+		assert.Equal(t, []Range{{Start: 18, End: 21}}, highlights[2].Matches)
+		assert.Equal(t, FieldQuery, highlights[3].Field)
+		assert.Equal(t, []Range{{Start: 10, End: 13}}, highlights[3].Matches)
+	})
+}
+
+func TestFragment(t *testing.T) {
+	value := []rune("début " + strings.Repeat("x", 200) + " cpu")
+	text, matches := fragment(value, []Range{{Start: 207, End: 210}})
+
+	runes := []rune(text)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "…", string(runes[0]))
+	assert.Equal(t, "cpu", string(runes[matches[0].Start:matches[0].End]))
+	assert.Len(t, runes, maxFragmentLength+1)
+}
+
+func testDashboard(id int64, orgID int64, data map[string]interface{}) *models.Dashboard {
+	dash := models.NewDashboardFromJson(simplejson.NewFromAny(data))
+	dash.Id = id
+	dash.OrgId = orgID
+	return dash
+}
@@ -0,0 +1,134 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed full-text search query. Every term, phrase and filter has to match for a dashboard to be part of
+// the results.
+type Query struct {
+	// Terms are matched against the start of the words of every field.
+	Terms []string
+	// Phrases are matched as a whole against every field.
+	Phrases []string
+	// Filters restrict the match of a value to a single field.
+	Filters []FieldFilter
+}
+
+// FieldFilter matches dashboards that have a value containing Value in Field, e.g. `datasource:prom-prod` or
+// `panel:"CPU usage"`.
+type FieldFilter struct {
+	Field Field
+	Value string
+}
+
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0 && len(q.Filters) == 0
+}
+
+// ParseQuery parses a search query made of words, quoted phrases and field filters. Prefixes that are not the name of
+// a field, such as in PromQL recording rule names, are searched as text.
+func ParseQuery(s string) Query {
+	var q Query
+	for _, part := range splitQuery(s) {
+		if part.quoted {
+			if phrase := strings.TrimSpace(part.value); phrase != "" {
+				q.Phrases = append(q.Phrases, phrase)
+			}
+			continue
+		}
+
+		if field, value, ok := parseFieldFilter(part.value); ok {
+			if value = strings.TrimSpace(value); value != "" {
+				q.Filters = append(q.Filters, FieldFilter{Field: field, Value: value})
+			}
+			continue
+		}
+
+		q.Terms = appendUnique(q.Terms, tokenize(part.value)...)
+	}
+	return q
+}
+
+type queryPart struct {
+	value  string
+	quoted bool
+}
+
+// splitQuery splits a query on white space outside of quotes. A field filter is kept as a single part with the quotes
+// of its value removed, an unterminated quote runs until the end of the query.
+func splitQuery(s string) []queryPart {
+	var parts []queryPart
+	var current strings.Builder
+	inQuotes, quoted := false, false
+
+	flush := func() {
+		if current.Len() > 0 || quoted {
+			parts = append(parts, queryPart{value: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			if !inQuotes && current.Len() > 0 && !strings.HasSuffix(current.String(), ":") {
+				flush()
+			}
+			if !inQuotes && current.Len() == 0 {
+				quoted = true
+			}
+			inQuotes = !inQuotes
+			if !inQuotes {
+				flush()
+			}
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return parts
+}
+
+func parseFieldFilter(s string) (Field, string, bool) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return "", "", false
+	}
+
+	field := Field(strings.ToLower(s[:i]))
+	if _, ok := fieldBoosts[field]; !ok {
+		return "", "", false
+	}
+	return field, s[i+1:], true
+}
+
+// tokenize splits a text into lower case words. Words are also split on underscores and dots so that a search for
+// cpu matches a metric named node_cpu_seconds_total.
+func tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return appendUnique(nil, words...)
+}
+
+func appendUnique(values []string, add ...string) []string {
+	for _, value := range add {
+		exists := false
+		for _, existing := range values {
+			if existing == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,256 @@
+package fulltext
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// loadBatchSize is the number of dashboards loaded at once when the index is synced with the database.
+const loadBatchSize = 100
+
+var ErrIndexNotReady = errors.New("full-text index is being built")
+
+func ProvideService(cfg *setting.Cfg, bus bus.Bus, sqlStore *sqlstore.SQLStore) *Service {
+	s := &Service{
+		log:      log.New("search.fulltext"),
+		cfg:      cfg,
+		sqlStore: sqlStore,
+		index:    NewIndex(),
+		ready:    make(chan struct{}),
+	}
+
+	if cfg.FullTextSearchEnabled {
+		bus.AddEventListener(s.handleDashboardSaved)
+		bus.AddEventListener(s.handleDashboardDeleted)
+	}
+
+	return s
+}
+
+// Service keeps a full-text index of the content of dashboards. The dashboards saved and deleted on this instance
+// are indexed as they change, the index is periodically synced with the database to pick up the changes made by
+// other instances.
+type Service struct {
+	log      log.Logger
+	cfg      *setting.Cfg
+	sqlStore *sqlstore.SQLStore
+	index    *Index
+	ready    chan struct{}
+}
+
+// SearchQuery searches the dashboards the signed in user can view.
+type SearchQuery struct {
+	Query        string
+	OrgID        int64
+	SignedInUser *models.SignedInUser
+	FolderIDs    []int64
+	Limit        int64
+	Page         int64
+
+	Result []*Hit
+}
+
+// Hit is a dashboard matching a full-text query, with its relevance score and where the query matched.
+type Hit struct {
+	*search.Hit
+	Score      float64     `json:"score"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.FullTextSearchEnabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	if err := s.Sync(ctx); err != nil {
+		s.log.Error("Failed to build full-text index", "error", err)
+	}
+	close(s.ready)
+
+	ticker := time.NewTicker(s.cfg.FullTextSearchSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				s.log.Error("Failed to sync full-text index", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// IsReady returns whether the initial build of the index is done.
+func (s *Service) IsReady() bool {
+	select {
+	case <-s.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+type dashboardVersion struct {
+	Id      int64
+	OrgId   int64
+	Version int
+}
+
+// Sync indexes the dashboards that have changed in the database since they were indexed, and removes the ones that
+// have been deleted.
+func (s *Service) Sync(ctx context.Context) error {
+	var current []*dashboardVersion
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("dashboard").
+			Where("is_folder = ? AND deleted IS NULL", s.sqlStore.Dialect.BooleanStr(false)).
+			Cols("id", "org_id", "version").
+			Find(&current)
+	})
+	if err != nil {
+		return err
+	}
+
+	indexed := s.index.versions()
+	outdated := make([]int64, 0)
+	for _, dash := range current {
+		if version, ok := indexed[dash.OrgId][dash.Id]; !ok || version != dash.Version {
+			outdated = append(outdated, dash.Id)
+		}
+		delete(indexed[dash.OrgId], dash.Id)
+	}
+
+	for orgID, removed := range indexed {
+		for id := range removed {
+			s.index.remove(orgID, id)
+		}
+	}
+
+	for start := 0; start < len(outdated); start += loadBatchSize {
+		end := start + loadBatchSize
+		if end > len(outdated) {
+			end = len(outdated)
+		}
+
+		var dashboards []*models.Dashboard
+		err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Where("deleted IS NULL").In("id", outdated[start:end]).Find(&dashboards)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, dash := range dashboards {
+			s.index.add(newDocument(dash))
+		}
+	}
+
+	if len(outdated) > 0 {
+		s.log.Debug("Synced full-text index", "indexed", len(outdated))
+	}
+	return nil
+}
+
+func (s *Service) handleDashboardSaved(ctx context.Context, e *events.DashboardSaved) error {
+	if e.IsFolder {
+		return nil
+	}
+
+	dash, err := s.sqlStore.GetDashboard(e.ID, e.OrgID, "", "")
+	if err != nil {
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			s.index.remove(e.OrgID, e.ID)
+			return nil
+		}
+		// the dashboard is indexed by the next sync
+		s.log.Warn("Failed to index dashboard", "id", e.ID, "error", err)
+		return nil
+	}
+
+	s.index.add(newDocument(dash))
+	return nil
+}
+
+func (s *Service) handleDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	s.index.remove(e.OrgID, e.ID)
+	return nil
+}
+
+// Search ranks the dashboards matching the query and filters out the ones the user cannot view.
+func (s *Service) Search(ctx context.Context, query *SearchQuery) error {
+	if !s.IsReady() {
+		return ErrIndexNotReady
+	}
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = 100
+	}
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	skip := (page - 1) * limit
+
+	q := ParseQuery(query.Query)
+	matches := s.index.Search(query.OrgID, q)
+	query.Result = make([]*Hit, 0)
+
+	// matches are checked in batches until the page is full, the user may not be able to view most of them
+	for start := 0; start < len(matches) && int64(len(query.Result)) < limit; start += loadBatchSize {
+		end := start + loadBatchSize
+		if end > len(matches) {
+			end = len(matches)
+		}
+
+		ids := make([]int64, 0, end-start)
+		for _, m := range matches[start:end] {
+			ids = append(ids, m.DashboardID)
+		}
+
+		dashboardQuery := search.FindPersistedDashboardsQuery{
+			OrgId:        query.OrgID,
+			SignedInUser: query.SignedInUser,
+			DashboardIds: ids,
+			FolderIds:    query.FolderIDs,
+			Type:         string(search.DashHitDB),
+			Permission:   models.PERMISSION_VIEW,
+			Limit:        int64(len(ids)),
+		}
+		if err := s.sqlStore.SearchDashboards(ctx, &dashboardQuery); err != nil {
+			return err
+		}
+
+		allowed := make(map[int64]*search.Hit, len(dashboardQuery.Result))
+		for _, hit := range dashboardQuery.Result {
+			allowed[hit.ID] = hit
+		}
+
+		for _, m := range matches[start:end] {
+			hit, ok := allowed[m.DashboardID]
+			if !ok {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			query.Result = append(query.Result, &Hit{Hit: hit, Score: m.Score, Highlights: m.Highlights(q)})
+			if int64(len(query.Result)) == limit {
+				break
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package fulltext
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	store := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.FullTextSearchEnabled = true
+	cfg.FullTextSearchSyncInterval = time.Minute
+	s := ProvideService(cfg, bus.GetBus(), store)
+
+	saveDashboard := func(t *testing.T, folderID int64, isFolder bool, data map[string]interface{}) *models.Dashboard {
+		t.Helper()
+		dash, err := store.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     1,
+			FolderId:  folderID,
+			IsFolder:  isFolder,
+			Dashboard: simplejson.NewFromAny(data),
+		})
+		require.NoError(t, err)
+		return dash
+	}
+
+	search := func(t *testing.T, user *models.SignedInUser, query string) []string {
+		t.Helper()
+		searchQuery := SearchQuery{Query: query, OrgID: 1, SignedInUser: user}
+		require.NoError(t, s.Search(ctx, &searchQuery))
+		titles := make([]string, 0, len(searchQuery.Result))
+		for _, hit := range searchQuery.Result {
+			titles = append(titles, hit.Title)
+		}
+		return titles
+	}
+
+	admin := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+	viewer := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+	cpu := saveDashboard(t, 0, false, map[string]interface{}{
+		"title":  "Hosts",
+		"panels": []interface{}{map[string]interface{}{"title": "CPU usage"}},
+	})
+
+	t.Run("should not search before the index is built", func(t *testing.T) {
+		err := s.Search(ctx, &SearchQuery{Query: "cpu", OrgID: 1, SignedInUser: admin})
+		require.ErrorIs(t, err, ErrIndexNotReady)
+
+		require.NoError(t, s.Sync(ctx))
+		close(s.ready)
+		assert.Equal(t, []string{"Hosts"}, search(t, admin, "cpu"))
+	})
+
+	t.Run("should index saved dashboards and filter out the ones the user cannot view", func(t *testing.T) {
+		folder := saveDashboard(t, 0, true, map[string]interface{}{"title": "Restricted"})
+		require.NoError(t, store.UpdateDashboardACL(ctx, folder.Id, []*models.DashboardAcl{}))
+		saveDashboard(t, folder.Id, false, map[string]interface{}{
+			"title":  "Restricted hosts",
+			"panels": []interface{}{map[string]interface{}{"title": "CPU usage per host"}},
+		})
+
+		assert.Equal(t, []string{"Hosts", "Restricted hosts"}, search(t, admin, "cpu"))
+		assert.Equal(t, []string{"Hosts"}, search(t, viewer, "cpu"))
+		assert.Equal(t, []string{"Restricted hosts"}, search(t, admin, `panel:"per host"`))
+	})
+
+	t.Run("should page results", func(t *testing.T) {
+		searchQuery := SearchQuery{Query: "cpu", OrgID: 1, SignedInUser: admin, Limit: 1, Page: 2}
+		require.NoError(t, s.Search(ctx, &searchQuery))
+		require.Len(t, searchQuery.Result, 1)
+		assert.Equal(t, "Restricted hosts", searchQuery.Result[0].Title)
+		assert.NotEmpty(t, searchQuery.Result[0].Highlights)
+	})
+
+	t.Run("should remove deleted dashboards", func(t *testing.T) {
+		require.NoError(t, store.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: cpu.Id, OrgId: 1}))
+		assert.Equal(t, []string{"Restricted hosts"}, search(t, admin, "cpu"))
+
+		require.NoError(t, store.RestoreDashboard(ctx, &models.RestoreDashboardCommand{Id: cpu.Id, OrgId: 1}))
+		assert.Equal(t, []string{"Hosts", "Restricted hosts"}, search(t, admin, "cpu"))
+	})
+}
@@ -94,6 +94,8 @@ func GetAuthProviderLabel(authModule string) string {
 		return "grafana.com"
 	case "auth.saml":
 		return "SAML"
+	case "scim":
+		return "SCIM"
 	case "ldap", "":
 		return "LDAP"
 	case "jwt":
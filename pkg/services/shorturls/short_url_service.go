@@ -17,10 +17,19 @@ func ProvideService(sqlStore *sqlstore.SQLStore) *ShortURLService {
 	}
 }
 
+// hitsRetention is how long the daily hit counts of short URLs are kept.
+const hitsRetention = 90 * 24 * time.Hour
+
+const dayFormat = "2006-01-02"
+
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *models.SignedInUser, uid string) (*models.ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error)
+	UpdateShortURLExpiry(ctx context.Context, shortURL *models.ShortUrl, expiresAt int64, pinned bool) error
 	UpdateLastSeenAt(ctx context.Context, shortURL *models.ShortUrl) error
+	RecordHit(ctx context.Context, shortURL *models.ShortUrl) error
+	GetDailyHits(ctx context.Context, shortURL *models.ShortUrl, days int) ([]*models.ShortUrlHit, error)
+	SearchShortURLs(ctx context.Context, query *models.SearchShortUrlsQuery) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error
 }
 
@@ -60,18 +69,39 @@ func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *models.
 	})
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, path string) (*models.ShortUrl, error) {
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *models.SignedInUser, cmd *models.CreateShortUrlCommand) (*models.ShortUrl, error) {
 	now := time.Now().Unix()
 	shortURL := models.ShortUrl{
 		OrgId:     user.OrgId,
-		Uid:       util.GenerateShortUID(),
-		Path:      path,
+		Uid:       cmd.Uid,
+		Path:      cmd.Path,
 		CreatedBy: user.UserId,
 		CreatedAt: now,
+		ExpiresAt: cmd.ExpiresAt,
+		Pinned:    cmd.Pinned,
+	}
+
+	if shortURL.Uid == "" {
+		shortURL.Uid = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(shortURL.Uid) {
+		return nil, models.ErrShortURLInvalidUID
+	} else if util.IsShortUIDTooLong(shortURL.Uid) {
+		return nil, models.ErrShortURLUIDTooLong
 	}
+	if shortURL.Pinned {
+		shortURL.ExpiresAt = 0
+	}
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
+		exists, err := session.Where("org_id=? AND uid=?", shortURL.OrgId, shortURL.Uid).Exist(&models.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return models.ErrShortURLWithSameUIDExists
+		}
 
-	err := s.SQLStore.WithDbSession(ctx, func(session *sqlstore.DBSession) error {
-		_, err := session.Insert(&shortURL)
+		_, err = session.Insert(&shortURL)
 		return err
 	})
 	if err != nil {
@@ -81,16 +111,123 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *models.Signed
 	return &shortURL, nil
 }
 
+func (s ShortURLService) UpdateShortURLExpiry(ctx context.Context, shortURL *models.ShortUrl, expiresAt int64, pinned bool) error {
+	if pinned {
+		expiresAt = 0
+	}
+	shortURL.ExpiresAt = expiresAt
+	shortURL.Pinned = pinned
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.ID(shortURL.Id).Cols("expires_at", "pinned").Update(shortURL)
+		return err
+	})
+}
+
+// RecordHit updates the last seen time of a short URL and counts the hit in its total and daily hits. The statements
+// run without a transaction so that the daily hits can be updated again when a concurrent hit inserted them first.
+func (s ShortURLService) RecordHit(ctx context.Context, shortURL *models.ShortUrl) error {
+	now := getTime()
+	day := now.UTC().Format(dayFormat)
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, err := dbSession.Exec("UPDATE short_url SET hits = hits + 1, last_seen_at = ? WHERE id = ?", now.Unix(), shortURL.Id); err != nil {
+			return err
+		}
+		shortURL.Hits++
+		shortURL.LastSeenAt = now.Unix()
+
+		if updated, err := incrementDailyHits(dbSession, shortURL.Id, day); err != nil || updated {
+			return err
+		}
+
+		_, err := dbSession.Insert(&models.ShortUrlHit{ShortUrlId: shortURL.Id, Day: day, Hits: 1})
+		if err != nil && s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+			_, err = incrementDailyHits(dbSession, shortURL.Id, day)
+		}
+		return err
+	})
+}
+
+// incrementDailyHits counts a hit in the daily hits of a short URL and returns whether they exist.
+func incrementDailyHits(dbSession *sqlstore.DBSession, shortURLID int64, day string) (bool, error) {
+	result, err := dbSession.Exec("UPDATE short_url_hit SET hits = hits + 1 WHERE short_url_id = ? AND day = ?", shortURLID, day)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetDailyHits returns the hits of a short URL for each of the last days, including days without hits.
+func (s ShortURLService) GetDailyHits(ctx context.Context, shortURL *models.ShortUrl, days int) ([]*models.ShortUrlHit, error) {
+	today := getTime().UTC()
+	from := today.AddDate(0, 0, 1-days).Format(dayFormat)
+
+	hits := make([]*models.ShortUrlHit, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		return dbSession.Where("short_url_id = ? AND day >= ?", shortURL.Id, from).Find(&hits)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hitsByDay := make(map[string]int64, len(hits))
+	for _, hit := range hits {
+		hitsByDay[hit.Day] = hit.Hits
+	}
+
+	result := make([]*models.ShortUrlHit, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i).Format(dayFormat)
+		result = append(result, &models.ShortUrlHit{ShortUrlId: shortURL.Id, Day: day, Hits: hitsByDay[day]})
+	}
+	return result, nil
+}
+
+func (s ShortURLService) SearchShortURLs(ctx context.Context, query *models.SearchShortUrlsQuery) error {
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		user := s.SQLStore.Dialect.Quote("user")
+		dbSession.Table("short_url").
+			Join("LEFT", user, "short_url.created_by = "+user+".id").
+			Select("short_url.id, short_url.uid, short_url.path, short_url.created_by, "+user+".login AS created_by_login, "+
+				"short_url.created_at, short_url.last_seen_at, short_url.expires_at, short_url.pinned, short_url.hits").
+			Where("short_url.org_id = ?", query.OrgId)
+
+		if query.CreatedBy > 0 {
+			dbSession.And("short_url.created_by = ?", query.CreatedBy)
+		}
+		if query.Path != "" {
+			dbSession.And("short_url.path "+s.SQLStore.Dialect.LikeStr()+" ?", query.Path+"%")
+		}
+
+		if query.Limit > 0 {
+			page := query.Page
+			if page < 1 {
+				page = 1
+			}
+			dbSession.Limit(query.Limit, (page-1)*query.Limit)
+		}
+
+		query.Result = make([]*models.ShortUrlSearchHit, 0)
+		return dbSession.Desc("short_url.created_at", "short_url.id").Find(&query.Result)
+	})
+}
+
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *models.DeleteShortUrlCommand) error {
 	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *sqlstore.DBSession) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		// pinned short urls are kept, others are deleted once expired or, without an expiry, if never visited
+		var rawSql = "DELETE FROM short_url WHERE pinned = " + s.SQLStore.Dialect.BooleanStr(false) + " AND (" +
+			"(expires_at > 0 AND expires_at <= ?) OR " +
+			"((expires_at IS NULL OR expires_at = 0) AND created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)))"
 
-		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
+		if result, err := session.Exec(rawSql, getTime().Unix(), cmd.OlderThan.Unix()); err != nil {
 			return err
 		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
 			return err
 		}
-		return nil
+
+		oldestDay := getTime().Add(-hitsRetention).UTC().Format(dayFormat)
+		_, err := session.Exec("DELETE FROM short_url_hit WHERE day < ? OR short_url_id NOT IN (SELECT id FROM short_url)", oldestDay)
+		return err
 	})
 }
 
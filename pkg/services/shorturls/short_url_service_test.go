@@ -19,7 +19,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: sqlStore}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -49,7 +49,7 @@ func TestShortURLService(t *testing.T) {
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -74,6 +74,89 @@ func TestShortURLService(t *testing.T) {
 		})
 	})
 
+	t.Run("User can create short URLs with an alias", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+
+		shortURL, err := service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/abc", Uid: "oncall-overview"})
+		require.NoError(t, err)
+		require.Equal(t, "oncall-overview", shortURL.Uid)
+
+		_, err = service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/def", Uid: "oncall-overview"})
+		require.Equal(t, models.ErrShortURLWithSameUIDExists, err)
+
+		_, err = service.CreateShortURL(context.Background(), user, &models.CreateShortUrlCommand{Path: "d/def", Uid: "on call"})
+		require.Equal(t, models.ErrShortURLInvalidUID, err)
+
+		otherOrgUser := &models.SignedInUser{UserId: 1, OrgId: 2}
+		_, err = service.CreateShortURL(context.Background(), otherOrgUser, &models.CreateShortUrlCommand{Path: "d/def", Uid: "oncall-overview"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Expired short URLs are deleted unless pinned", func(t *testing.T) {
+		service := ShortURLService{SQLStore: sqlStore}
+		ctx := context.Background()
+
+		expired, err := service.CreateShortURL(ctx, user, &models.CreateShortUrlCommand{Path: "d/abc", ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+		require.NoError(t, err)
+		require.True(t, expired.IsExpired(time.Now()))
+
+		pinned, err := service.CreateShortURL(ctx, user, &models.CreateShortUrlCommand{Path: "d/abc", Pinned: true})
+		require.NoError(t, err)
+
+		unpinned, err := service.CreateShortURL(ctx, user, &models.CreateShortUrlCommand{Path: "d/abc", Pinned: true})
+		require.NoError(t, err)
+		require.NoError(t, service.UpdateShortURLExpiry(ctx, unpinned, time.Now().Add(-time.Minute).Unix(), false))
+
+		cmd := models.DeleteShortUrlCommand{OlderThan: time.Now().Add(time.Hour)}
+		require.NoError(t, service.DeleteStaleShortURLs(ctx, &cmd))
+
+		_, err = service.GetShortURLByUID(ctx, user, expired.Uid)
+		require.Equal(t, models.ErrShortURLNotFound, err)
+		_, err = service.GetShortURLByUID(ctx, user, unpinned.Uid)
+		require.Equal(t, models.ErrShortURLNotFound, err)
+		_, err = service.GetShortURLByUID(ctx, user, pinned.Uid)
+		require.NoError(t, err)
+	})
+
+	t.Run("Hits are counted per day", func(t *testing.T) {
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+
+		service := ShortURLService{SQLStore: sqlStore}
+		ctx := context.Background()
+		shortURL, err := service.CreateShortURL(ctx, user, &models.CreateShortUrlCommand{Path: "d/hits", Uid: "hits"})
+		require.NoError(t, err)
+
+		now := time.Date(2021, time.November, 20, 10, 0, 0, 0, time.UTC)
+		getTime = func() time.Time { return now }
+		require.NoError(t, service.RecordHit(ctx, shortURL))
+		require.NoError(t, service.RecordHit(ctx, shortURL))
+		now = now.Add(48 * time.Hour)
+		require.NoError(t, service.RecordHit(ctx, shortURL))
+
+		shortURL, err = service.GetShortURLByUID(ctx, user, "hits")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), shortURL.Hits)
+		require.Equal(t, now.Unix(), shortURL.LastSeenAt)
+
+		hits, err := service.GetDailyHits(ctx, shortURL, 4)
+		require.NoError(t, err)
+		daily := make([]int64, 0, len(hits))
+		for _, hit := range hits {
+			daily = append(daily, hit.Hits)
+		}
+		require.Equal(t, []int64{0, 2, 0, 1}, daily)
+		require.Equal(t, "2021-11-22", hits[3].Day)
+
+		query := models.SearchShortUrlsQuery{OrgId: user.OrgId, CreatedBy: user.UserId, Path: "d/hi"}
+		require.NoError(t, service.SearchShortURLs(ctx, &query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, "hits", query.Result[0].Uid)
+		require.Equal(t, int64(3), query.Result[0].Hits)
+	})
+
 	t.Run("User cannot look up nonexistent short URLs", func(t *testing.T) {
 		service := ShortURLService{SQLStore: sqlStore}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
@@ -44,10 +45,14 @@ func (ss *SQLStore) addDashboardQueryAndCommandHandlers() {
 	bus.AddHandler("sql", ss.SearchDashboards)
 	bus.AddHandler("sql", ss.DeleteDashboard)
 	bus.AddHandler("sql", ss.GetDashboards)
+	bus.AddHandler("sql", ss.PurgeDeletedDashboards)
 }
 
 var generateNewUid func() string = util.GenerateShortUID
 
+// dashboardNotDeletedFilter excludes the dashboards in the trash, see deleteDashboard.
+const dashboardNotDeletedFilter = "dashboard.deleted IS NULL"
+
 func (ss *SQLStore) SaveDashboard(cmd models.SaveDashboardCommand) (*models.Dashboard, error) {
 	err := ss.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
 		return saveDashboard(sess, &cmd)
@@ -66,7 +71,7 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 
 	if dash.Id > 0 {
 		var existing models.Dashboard
-		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existing)
+		dashWithIdExists, err := sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Where(dashboardNotDeletedFilter).Get(&existing)
 		if err != nil {
 			return err
 		}
@@ -165,8 +170,20 @@ func saveDashboard(sess *DBSession, cmd *models.SaveDashboardCommand) error {
 		}
 	}
 
+	if err := saveDashboardReferences(sess, dash); err != nil {
+		return err
+	}
+
 	cmd.Result = dash
 
+	sess.publishAfterCommit(&events.DashboardSaved{
+		Timestamp: dash.Updated,
+		ID:        dash.Id,
+		UID:       dash.Uid,
+		OrgID:     dash.OrgId,
+		IsFolder:  dash.IsFolder,
+	})
+
 	return nil
 }
 
@@ -194,7 +211,7 @@ func (ss *SQLStore) GetDashboard(id, orgID int64, uid, slug string) (*models.Das
 	}
 
 	dashboard := models.Dashboard{Slug: slug, OrgId: orgID, Id: id, Uid: uid}
-	has, err := ss.engine.Get(&dashboard)
+	has, err := ss.engine.Where(dashboardNotDeletedFilter).Get(&dashboard)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -215,7 +232,7 @@ func (ss *SQLStore) GetFolderByTitle(orgID int64, title string) (*models.Dashboa
 	// there is a unique constraint on org_id, folder_id, title
 	// there are no nested folders so the parent folder id is always 0
 	dashboard := models.Dashboard{OrgId: orgID, FolderId: 0, Title: title}
-	has, err := ss.engine.Table(&models.Dashboard{}).Where("is_folder = " + dialect.BooleanStr(true)).Where("folder_id=0").Get(&dashboard)
+	has, err := ss.engine.Table(&models.Dashboard{}).Where("is_folder = " + dialect.BooleanStr(true)).Where("folder_id=0").Where(dashboardNotDeletedFilter).Get(&dashboard)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +251,7 @@ func GetDashboard(ctx context.Context, query *models.GetDashboardQuery) error {
 		}
 
 		dashboard := models.Dashboard{Slug: query.Slug, OrgId: query.OrgId, Id: query.Id, Uid: query.Uid}
-		has, err := dbSession.Get(&dashboard)
+		has, err := dbSession.Where(dashboardNotDeletedFilter).Get(&dashboard)
 
 		if err != nil {
 			return err
@@ -278,6 +295,8 @@ func (ss *SQLStore) findDashboards(ctx context.Context, query *search.FindPersis
 		filters = append(filters, filter)
 	}
 
+	filters = append(filters, searchstore.NotDeletedFilter{})
+
 	if query.OrgId != 0 {
 		filters = append(filters, searchstore.OrgFilter{OrgId: query.OrgId})
 	} else if query.SignedInUser.OrgId != 0 {
@@ -400,7 +419,7 @@ func GetDashboardTags(ctx context.Context, query *models.GetDashboardTagsQuery)
 						term
 					FROM dashboard
 					INNER JOIN dashboard_tag on dashboard_tag.dashboard_id = dashboard.id
-					WHERE dashboard.org_id=?
+					WHERE dashboard.org_id=? AND dashboard.deleted IS NULL
 					GROUP BY term
 					ORDER BY term`
 
@@ -416,62 +435,26 @@ func (ss *SQLStore) DeleteDashboard(ctx context.Context, cmd *models.DeleteDashb
 	})
 }
 
+// deleteDashboard moves a dashboard, or a folder and its dashboards, to the trash. Trashed dashboards
+// give up their uid and title so that they can be reused, the original ones are kept in the dashboard
+// data and are restored by RestoreDashboard. Folders containing alert rules are not moved to the trash,
+// as the rules can't be hidden with them: they are deleted permanently along with their rules when
+// cmd.ForceDeleteFolderRules is set.
 func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error {
 	dashboard := models.Dashboard{Id: cmd.Id, OrgId: cmd.OrgId}
-	has, err := sess.Get(&dashboard)
+	has, err := sess.Where(dashboardNotDeletedFilter).Get(&dashboard)
 	if err != nil {
 		return err
 	} else if !has {
 		return models.ErrDashboardNotFound
 	}
 
-	deletes := []string{
-		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
-		"DELETE FROM star WHERE dashboard_id = ? ",
-		"DELETE FROM dashboard WHERE id = ?",
-		"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
-		"DELETE FROM dashboard_version WHERE dashboard_id = ?",
-		"DELETE FROM annotation WHERE dashboard_id = ?",
-		"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
-		"DELETE FROM dashboard_acl WHERE dashboard_id = ?",
-	}
+	ids := []int64{dashboard.Id}
+	purge := false
 
 	if dashboard.IsFolder {
-		deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
-
-		dashIds := []struct {
-			Id int64
-		}{}
-		err := sess.SQL("SELECT id FROM dashboard WHERE folder_id = ?", dashboard.Id).Find(&dashIds)
-		if err != nil {
-			return err
-		}
-
-		for _, id := range dashIds {
-			if err := deleteAlertDefinition(id.Id, sess); err != nil {
-				return err
-			}
-		}
-
-		if len(dashIds) > 0 {
-			childrenDeletes := []string{
-				"DELETE FROM dashboard_tag WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
-				"DELETE FROM star WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
-				"DELETE FROM dashboard_version WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
-				"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
-				"DELETE FROM dashboard_provisioning WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
-				"DELETE FROM dashboard_acl WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
-			}
-			for _, sql := range childrenDeletes {
-				_, err := sess.Exec(sql, dashboard.OrgId, dashboard.Id)
-				if err != nil {
-					return err
-				}
-			}
-		}
-
 		var existingRuleID int64
-		exists, err := sess.Table("alert_rule").Where("namespace_uid = (SELECT uid FROM dashboard WHERE id = ?)", dashboard.Id).Cols("id").Get(&existingRuleID)
+		exists, err := sess.Table("alert_rule").Where("namespace_uid = ? AND org_id = ?", dashboard.Uid, dashboard.OrgId).Cols("id").Get(&existingRuleID)
 		if err != nil {
 			return err
 		}
@@ -480,32 +463,67 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 				return fmt.Errorf("folder cannot be deleted: %w", models.ErrFolderContainsAlertRules)
 			}
 
-			// Delete all rules under this folder.
 			deleteNGAlertsByFolder := []string{
-				"DELETE FROM alert_rule WHERE namespace_uid = (SELECT uid FROM dashboard WHERE id = ?)",
-				"DELETE FROM alert_rule_version WHERE rule_namespace_uid = (SELECT uid FROM dashboard WHERE id = ?)",
+				"DELETE FROM alert_rule WHERE namespace_uid = ? AND org_id = ?",
+				"DELETE FROM alert_rule_version WHERE rule_namespace_uid = ? AND rule_org_id = ?",
 			}
 
 			for _, sql := range deleteNGAlertsByFolder {
-				_, err := sess.Exec(sql, dashboard.Id)
+				_, err := sess.Exec(sql, dashboard.Uid, dashboard.OrgId)
 				if err != nil {
 					return err
 				}
 			}
+			purge = true
 		}
-	}
 
-	if err := deleteAlertDefinition(dashboard.Id, sess); err != nil {
-		return err
+		var children []int64
+		err = sess.Table("dashboard").Where("org_id = ? AND folder_id = ? AND deleted IS NULL", dashboard.OrgId, dashboard.Id).Cols("id").Find(&children)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, children...)
 	}
 
-	for _, sql := range deletes {
-		_, err := sess.Exec(sql, dashboard.Id)
-		if err != nil {
+	deleted := time.Now()
+	for _, id := range ids {
+		if err := moveDashboardToTrash(sess, id, dashboard.OrgId, deleted); err != nil {
 			return err
 		}
 	}
 
+	if purge {
+		return purgeDashboard(sess, dashboard.Id, true)
+	}
+	return nil
+}
+
+// moveDashboardToTrash marks a dashboard as deleted. Alerts are removed as they are extracted again from the
+// dashboard when it is restored, and a trashed dashboard is no longer provisioned.
+func moveDashboardToTrash(sess *DBSession, id int64, orgID int64, deleted time.Time) error {
+	if err := deleteAlertDefinition(id, sess); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("DELETE FROM dashboard_provisioning WHERE dashboard_id = ?", id); err != nil {
+		return err
+	}
+
+	uid, err := generateNewDashboardUid(sess, orgID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("UPDATE dashboard SET uid = ?, title = ?, deleted = ? WHERE id = ?", uid, uid, deleted, id); err != nil {
+		return err
+	}
+
+	sess.publishAfterCommit(&events.DashboardDeleted{
+		Timestamp: deleted,
+		ID:        id,
+		OrgID:     orgID,
+	})
+
 	return nil
 }
 
@@ -516,7 +534,7 @@ func (ss *SQLStore) GetDashboards(ctx context.Context, query *models.GetDashboar
 
 	var dashboards = make([]*models.Dashboard, 0)
 
-	err := x.In("id", query.DashboardIds).Find(&dashboards)
+	err := x.In("id", query.DashboardIds).Where(dashboardNotDeletedFilter).Find(&dashboards)
 	query.Result = dashboards
 	return err
 }
@@ -569,7 +587,7 @@ func GetDashboardPermissionsForUser(ctx context.Context, query *models.GetDashbo
 	}
 
 	sql += ` AND
-	d.org_id = ? AND
+	d.org_id = ? AND d.deleted IS NULL AND
 	  (
 		(d.has_acl = ?  AND (da.user_id = ? OR ugm.user_id = ? OR ou.id IS NOT NULL))
 		OR (d.has_acl = ? AND ouRole.id IS NOT NULL)
@@ -593,7 +611,7 @@ func GetDashboardPermissionsForUser(ctx context.Context, query *models.GetDashbo
 
 func GetDashboardsByPluginId(ctx context.Context, query *models.GetDashboardsByPluginIdQuery) error {
 	var dashboards = make([]*models.Dashboard, 0)
-	whereExpr := "org_id=? AND plugin_id=? AND is_folder=" + dialect.BooleanStr(false)
+	whereExpr := "org_id=? AND plugin_id=? AND deleted IS NULL AND is_folder=" + dialect.BooleanStr(false)
 
 	err := x.Where(whereExpr, query.OrgId, query.PluginId).Find(&dashboards)
 	query.Result = dashboards
@@ -605,7 +623,7 @@ type DashboardSlugDTO struct {
 }
 
 func GetDashboardSlugById(ctx context.Context, query *models.GetDashboardSlugByIdQuery) error {
-	var rawSQL = `SELECT slug from dashboard WHERE Id=?`
+	var rawSQL = `SELECT slug from dashboard WHERE Id=? AND deleted IS NULL`
 	var slug = DashboardSlugDTO{}
 
 	exists, err := x.SQL(rawSQL, query.Id).Get(&slug)
@@ -623,7 +641,7 @@ func GetDashboardSlugById(ctx context.Context, query *models.GetDashboardSlugByI
 func GetDashboardsBySlug(ctx context.Context, query *models.GetDashboardsBySlugQuery) error {
 	var dashboards []*models.Dashboard
 
-	if err := x.Where("org_id=? AND slug=?", query.OrgId, query.Slug).Find(&dashboards); err != nil {
+	if err := x.Where("org_id=? AND slug=? AND deleted IS NULL", query.OrgId, query.Slug).Find(&dashboards); err != nil {
 		return err
 	}
 
@@ -633,7 +651,7 @@ func GetDashboardsBySlug(ctx context.Context, query *models.GetDashboardsBySlugQ
 
 func (ss *SQLStore) GetDashboardUIDById(ctx context.Context, query *models.GetDashboardRefByIdQuery) error {
 	return ss.WithDbSession(ctx, func(dbSession *DBSession) error {
-		var rawSQL = `SELECT uid, slug from dashboard WHERE Id=?`
+		var rawSQL = `SELECT uid, slug from dashboard WHERE Id=? AND deleted IS NULL`
 
 		us := &models.DashboardRef{}
 
@@ -657,7 +675,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 
 	if dash.Id > 0 {
 		var err error
-		dashWithIdExists, err = sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Get(&existingById)
+		dashWithIdExists, err = sess.Where("id=? AND org_id=?", dash.Id, dash.OrgId).Where(dashboardNotDeletedFilter).Get(&existingById)
 		if err != nil {
 			return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by ID failed: %w", err)
 		}
@@ -676,7 +694,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 
 	if dash.Uid != "" {
 		var err error
-		dashWithUidExists, err = sess.Where("org_id=? AND uid=?", dash.OrgId, dash.Uid).Get(&existingByUid)
+		dashWithUidExists, err = sess.Where("org_id=? AND uid=?", dash.OrgId, dash.Uid).Where(dashboardNotDeletedFilter).Get(&existingByUid)
 		if err != nil {
 			return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by UID failed: %w", err)
 		}
@@ -685,7 +703,7 @@ func getExistingDashboardByIdOrUidForUpdate(sess *DBSession, dash *models.Dashbo
 	if dash.FolderId > 0 {
 		var existingFolder models.Dashboard
 		folderExists, err := sess.Where("org_id=? AND id=? AND is_folder=?", dash.OrgId, dash.FolderId,
-			dialect.BooleanStr(true)).Get(&existingFolder)
+			dialect.BooleanStr(true)).Where(dashboardNotDeletedFilter).Get(&existingFolder)
 		if err != nil {
 			return isParentFolderChanged, fmt.Errorf("SQL query for folder failed: %w", err)
 		}
@@ -745,7 +763,7 @@ func getExistingDashboardByTitleAndFolder(sess *DBSession, dash *models.Dashboar
 	isParentFolderChanged bool) (bool, error) {
 	var existing models.Dashboard
 	exists, err := sess.Where("org_id=? AND slug=? AND (is_folder=? OR folder_id=?)", dash.OrgId, dash.Slug,
-		dialect.BooleanStr(true), dash.FolderId).Get(&existing)
+		dialect.BooleanStr(true), dash.FolderId).Where(dashboardNotDeletedFilter).Get(&existing)
 	if err != nil {
 		return isParentFolderChanged, fmt.Errorf("SQL query for existing dashboard by org ID or folder ID failed: %w", err)
 	}
@@ -808,7 +826,7 @@ func HasEditPermissionInFolders(ctx context.Context, query *models.HasEditPermis
 		}
 
 		builder := &SQLBuilder{}
-		builder.Write("SELECT COUNT(dashboard.id) AS count FROM dashboard WHERE dashboard.org_id = ? AND dashboard.is_folder = ?",
+		builder.Write("SELECT COUNT(dashboard.id) AS count FROM dashboard WHERE dashboard.org_id = ? AND dashboard.is_folder = ? AND dashboard.deleted IS NULL",
 			query.SignedInUser.OrgId, dialect.BooleanStr(true))
 		builder.WriteDashboardPermissionFilter(query.SignedInUser, models.PERMISSION_EDIT)
 
@@ -834,7 +852,7 @@ func HasAdminPermissionInFolders(ctx context.Context, query *models.HasAdminPerm
 	}
 
 	builder := &SQLBuilder{}
-	builder.Write("SELECT COUNT(dashboard.id) AS count FROM dashboard WHERE dashboard.org_id = ? AND dashboard.is_folder = ?", query.SignedInUser.OrgId, dialect.BooleanStr(true))
+	builder.Write("SELECT COUNT(dashboard.id) AS count FROM dashboard WHERE dashboard.org_id = ? AND dashboard.is_folder = ? AND dashboard.deleted IS NULL", query.SignedInUser.OrgId, dialect.BooleanStr(true))
 	builder.WriteDashboardPermissionFilter(query.SignedInUser, models.PERMISSION_ADMIN)
 
 	type folderCount struct {
@@ -0,0 +1,75 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// saveDashboardReferences replaces the references of a dashboard with the ones of its current version.
+func saveDashboardReferences(sess *DBSession, dash *models.Dashboard) error {
+	if _, err := sess.Exec("DELETE FROM dashboard_reference WHERE dashboard_id = ?", dash.Id); err != nil {
+		return err
+	}
+
+	if dash.IsFolder {
+		return nil
+	}
+
+	for _, ref := range dash.GetReferences() {
+		if _, err := sess.Insert(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *SQLStore) GetDashboardReferences(ctx context.Context, query *models.GetDashboardReferencesQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		query.Result = make([]*models.DashboardReference, 0)
+		return sess.Where("dashboard_id = ? AND org_id = ?", query.DashboardId, query.OrgId).
+			Asc("kind", "id").
+			Find(&query.Result)
+	})
+}
+
+func (ss *SQLStore) GetDatasourceUsage(ctx context.Context, query *models.GetDatasourceUsageQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		filter := "dashboard_reference.uid = ? OR dashboard_reference.name = ? OR " +
+			"(dashboard_reference.variable <> '' AND dashboard_reference.type = ?)"
+		if query.IsDefault {
+			filter += " OR (dashboard_reference.uid = '' AND dashboard_reference.name = '' AND dashboard_reference.variable = '')"
+		}
+
+		query.Result = make([]*models.DashboardReference, 0)
+		return sess.Table("dashboard_reference").
+			Join("INNER", "dashboard", "dashboard.id = dashboard_reference.dashboard_id").
+			Where(dashboardNotDeletedFilter).
+			Where("dashboard_reference.org_id = ? AND dashboard_reference.kind = ?", query.OrgId, models.DashboardReferenceDatasource).
+			Where("("+filter+")", query.Uid, query.Name, query.Type).
+			Cols("dashboard_reference.*").
+			Asc("dashboard_reference.dashboard_id", "dashboard_reference.id").
+			Find(&query.Result)
+	})
+}
+
+func (ss *SQLStore) GetPluginUsage(ctx context.Context, query *models.GetPluginUsageQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		// references made by name or to the default data source do not have the type of the data source
+		datasourceExists := `EXISTS (SELECT 1 FROM data_source WHERE data_source.org_id = dashboard_reference.org_id AND data_source.type = ? AND (
+			(dashboard_reference.uid <> '' AND data_source.uid = dashboard_reference.uid) OR
+			(dashboard_reference.name <> '' AND data_source.name = dashboard_reference.name) OR
+			(dashboard_reference.uid = '' AND dashboard_reference.name = '' AND dashboard_reference.variable = '' AND data_source.is_default = ` + dialect.BooleanStr(true) + `)))`
+
+		query.Result = make([]*models.DashboardReference, 0)
+		return sess.Table("dashboard_reference").
+			Join("INNER", "dashboard", "dashboard.id = dashboard_reference.dashboard_id").
+			Where(dashboardNotDeletedFilter).
+			Where("dashboard_reference.org_id = ?", query.OrgId).
+			Where("((dashboard_reference.kind = ? AND dashboard_reference.type = ?) OR (dashboard_reference.kind = ? AND (dashboard_reference.type = ? OR "+datasourceExists+")))",
+				models.DashboardReferencePanel, query.PluginId, models.DashboardReferenceDatasource, query.PluginId, query.PluginId).
+			Cols("dashboard_reference.*").
+			Asc("dashboard_reference.dashboard_id", "dashboard_reference.id").
+			Find(&query.Result)
+	})
+}
@@ -0,0 +1,148 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestDashboardReferences(t *testing.T) {
+	ctx := context.Background()
+	sqlStore := InitTestDB(t)
+
+	addDataSource := func(t *testing.T, orgID int64, name, dsType string, isDefault bool) *models.DataSource {
+		t.Helper()
+		cmd := models.AddDataSourceCommand{OrgId: orgID, Name: name, Type: dsType, Access: models.DS_ACCESS_PROXY, IsDefault: isDefault}
+		require.NoError(t, sqlStore.AddDataSource(ctx, &cmd))
+		return cmd.Result
+	}
+
+	saveDashboard := func(t *testing.T, orgID int64, data map[string]interface{}) *models.Dashboard {
+		t.Helper()
+		dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     orgID,
+			Overwrite: true,
+			Dashboard: simplejson.NewFromAny(data),
+		})
+		require.NoError(t, err)
+		return dash
+	}
+
+	dashboardIDs := func(refs []*models.DashboardReference) []int64 {
+		ids := make([]int64, 0)
+		for _, ref := range refs {
+			if len(ids) == 0 || ids[len(ids)-1] != ref.DashboardId {
+				ids = append(ids, ref.DashboardId)
+			}
+		}
+		return ids
+	}
+
+	prometheus := addDataSource(t, 1, "Prometheus", "prometheus", true)
+	loki := addDataSource(t, 1, "Loki", "loki", false)
+	addDataSource(t, 2, "Other Prometheus", "prometheus", true)
+
+	byUID := saveDashboard(t, 1, map[string]interface{}{
+		"title": "By uid",
+		"panels": []interface{}{map[string]interface{}{
+			"type":       "timeseries",
+			"datasource": map[string]interface{}{"uid": prometheus.Uid, "type": "prometheus"},
+			"targets":    []interface{}{map[string]interface{}{}},
+		}},
+	})
+	byName := saveDashboard(t, 1, map[string]interface{}{
+		"title": "By name",
+		"panels": []interface{}{map[string]interface{}{
+			"type":       "graph",
+			"datasource": "Loki",
+			"targets":    []interface{}{map[string]interface{}{}},
+		}},
+	})
+	byDefault := saveDashboard(t, 1, map[string]interface{}{
+		"title": "By default",
+		"panels": []interface{}{map[string]interface{}{
+			"type":    "timeseries",
+			"targets": []interface{}{map[string]interface{}{}},
+		}},
+	})
+	byVariable := saveDashboard(t, 1, map[string]interface{}{
+		"title": "By variable",
+		"panels": []interface{}{map[string]interface{}{
+			"type":       "table",
+			"datasource": "$ds",
+			"targets":    []interface{}{map[string]interface{}{}},
+		}},
+		"templating": map[string]interface{}{
+			"list": []interface{}{map[string]interface{}{"name": "ds", "type": "datasource", "query": "loki"}},
+		},
+	})
+	otherOrg := saveDashboard(t, 2, map[string]interface{}{
+		"title": "Other org",
+		"panels": []interface{}{map[string]interface{}{
+			"type":    "timeseries",
+			"targets": []interface{}{map[string]interface{}{}},
+		}},
+	})
+
+	t.Run("should store the references of a dashboard", func(t *testing.T) {
+		query := models.GetDashboardReferencesQuery{DashboardId: byName.Id, OrgId: 1}
+		require.NoError(t, sqlStore.GetDashboardReferences(ctx, &query))
+		require.Len(t, query.Result, 2)
+		assert.Equal(t, models.DashboardReferenceDatasource, query.Result[0].Kind)
+		assert.Equal(t, "Loki", query.Result[0].Name)
+		assert.Equal(t, models.DashboardReferencePanel, query.Result[1].Kind)
+		assert.Equal(t, "graph", query.Result[1].Type)
+	})
+
+	t.Run("should find the dashboards using a data source", func(t *testing.T) {
+		query := models.GetDatasourceUsageQuery{OrgId: 1, Uid: prometheus.Uid, Name: prometheus.Name, Type: prometheus.Type, IsDefault: true}
+		require.NoError(t, sqlStore.GetDatasourceUsage(ctx, &query))
+		assert.Equal(t, []int64{byUID.Id, byDefault.Id}, dashboardIDs(query.Result))
+
+		query = models.GetDatasourceUsageQuery{OrgId: 1, Uid: loki.Uid, Name: loki.Name, Type: loki.Type}
+		require.NoError(t, sqlStore.GetDatasourceUsage(ctx, &query))
+		assert.Equal(t, []int64{byName.Id, byVariable.Id}, dashboardIDs(query.Result))
+	})
+
+	t.Run("should find the dashboards of an organization using a plugin", func(t *testing.T) {
+		query := models.GetPluginUsageQuery{OrgId: 1, PluginId: "prometheus"}
+		require.NoError(t, sqlStore.GetPluginUsage(ctx, &query))
+		assert.Equal(t, []int64{byUID.Id, byDefault.Id}, dashboardIDs(query.Result))
+
+		query = models.GetPluginUsageQuery{OrgId: 1, PluginId: "timeseries"}
+		require.NoError(t, sqlStore.GetPluginUsage(ctx, &query))
+		assert.Equal(t, []int64{byUID.Id, byDefault.Id}, dashboardIDs(query.Result))
+
+		query = models.GetPluginUsageQuery{OrgId: 2, PluginId: "timeseries"}
+		require.NoError(t, sqlStore.GetPluginUsage(ctx, &query))
+		assert.Equal(t, []int64{otherOrg.Id}, dashboardIDs(query.Result))
+	})
+
+	t.Run("should replace the references of a saved dashboard", func(t *testing.T) {
+		saveDashboard(t, 1, map[string]interface{}{
+			"id":     byName.Id,
+			"title":  "By name",
+			"panels": []interface{}{map[string]interface{}{"type": "text"}},
+		})
+
+		query := models.GetDatasourceUsageQuery{OrgId: 1, Uid: loki.Uid, Name: loki.Name, Type: loki.Type}
+		require.NoError(t, sqlStore.GetDatasourceUsage(ctx, &query))
+		assert.Equal(t, []int64{byVariable.Id}, dashboardIDs(query.Result))
+	})
+
+	t.Run("should not find deleted dashboards", func(t *testing.T) {
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: byUID.Id, OrgId: 1}))
+
+		query := models.GetDatasourceUsageQuery{OrgId: 1, Uid: prometheus.Uid, Name: prometheus.Name, Type: prometheus.Type, IsDefault: true}
+		require.NoError(t, sqlStore.GetDatasourceUsage(ctx, &query))
+		assert.Equal(t, []int64{byDefault.Id}, dashboardIDs(query.Result))
+	})
+}
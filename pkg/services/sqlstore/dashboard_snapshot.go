@@ -19,8 +19,17 @@ func (ss *SQLStore) DeleteExpiredSnapshots(ctx context.Context, cmd *models.Dele
 			return nil
 		}
 
+		before := cmd.Before
+		if before.IsZero() {
+			before = time.Now()
+		}
+		deleteViewsSQL := "DELETE FROM dashboard_snapshot_view WHERE snapshot_id IN (SELECT id FROM dashboard_snapshot WHERE expires < ?)"
+		if _, err := sess.Exec(deleteViewsSQL, before); err != nil {
+			return err
+		}
+
 		deleteExpiredSQL := "DELETE FROM dashboard_snapshot WHERE expires < ?"
-		expiredResponse, err := sess.Exec(deleteExpiredSQL, time.Now())
+		expiredResponse, err := sess.Exec(deleteExpiredSQL, before)
 		if err != nil {
 			return err
 		}
@@ -30,6 +39,16 @@ func (ss *SQLStore) DeleteExpiredSnapshots(ctx context.Context, cmd *models.Dele
 	})
 }
 
+// GetExpiredDashboardSnapshotBlobKeys returns the blob keys of the snapshots stored in the blob storage that expired
+// before a time.
+func (ss *SQLStore) GetExpiredDashboardSnapshotBlobKeys(ctx context.Context, before time.Time) ([]string, error) {
+	keys := make([]string, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Table("dashboard_snapshot").Where("expires < ? AND blob_key IS NOT NULL AND blob_key <> ''", before).Cols("blob_key").Find(&keys)
+	})
+	return keys, err
+}
+
 func (ss *SQLStore) CreateDashboardSnapshot(ctx context.Context, cmd *models.CreateDashboardSnapshotCommand) error {
 	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
 		// never
@@ -49,6 +68,9 @@ func (ss *SQLStore) CreateDashboardSnapshot(ctx context.Context, cmd *models.Cre
 			ExternalDeleteUrl:  cmd.ExternalDeleteUrl,
 			Dashboard:          simplejson.New(),
 			DashboardEncrypted: cmd.DashboardEncrypted,
+			BlobKey:            cmd.BlobKey,
+			Visibility:         cmd.Visibility,
+			TeamIds:            cmd.TeamIds,
 			Expires:            expires,
 			Created:            time.Now(),
 			Updated:            time.Now(),
@@ -62,12 +84,66 @@ func (ss *SQLStore) CreateDashboardSnapshot(ctx context.Context, cmd *models.Cre
 
 func (ss *SQLStore) DeleteDashboardSnapshot(ctx context.Context, cmd *models.DeleteDashboardSnapshotCommand) error {
 	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var viewsSQL = "DELETE FROM dashboard_snapshot_view WHERE snapshot_id IN (SELECT id FROM dashboard_snapshot WHERE delete_key=?)"
+		if _, err := sess.Exec(viewsSQL, cmd.DeleteKey); err != nil {
+			return err
+		}
+
 		var rawSQL = "DELETE FROM dashboard_snapshot WHERE delete_key=?"
 		_, err := sess.Exec(rawSQL, cmd.DeleteKey)
 		return err
 	})
 }
 
+func (ss *SQLStore) UpdateDashboardSnapshotVisibility(ctx context.Context, cmd *models.UpdateDashboardSnapshotVisibilityCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		snapshot := &models.DashboardSnapshot{
+			Visibility: cmd.Visibility,
+			TeamIds:    cmd.TeamIds,
+			Updated:    time.Now(),
+		}
+		affected, err := sess.Where(ss.Dialect.Quote("key")+" = ?", cmd.Key).Cols("visibility", "team_ids", "updated").Update(snapshot)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrDashboardSnapshotNotFound
+		}
+		return nil
+	})
+}
+
+func (ss *SQLStore) RecordDashboardSnapshotView(ctx context.Context, cmd *models.RecordDashboardSnapshotViewCommand) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		_, err := sess.Insert(&models.DashboardSnapshotView{
+			SnapshotId: cmd.SnapshotId,
+			OrgId:      cmd.OrgId,
+			UserId:     cmd.UserId,
+			Viewed:     time.Now(),
+		})
+		return err
+	})
+}
+
+// GetDashboardSnapshotViews returns the latest views of a snapshot first.
+func (ss *SQLStore) GetDashboardSnapshotViews(ctx context.Context, query *models.GetDashboardSnapshotViewsQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		views := make([]*models.DashboardSnapshotViewDTO, 0)
+		user := ss.Dialect.Quote("user")
+		sess.Table("dashboard_snapshot_view").
+			Join("LEFT", user, "dashboard_snapshot_view.user_id = "+user+".id").
+			Select("dashboard_snapshot_view.user_id, "+user+".login, dashboard_snapshot_view.viewed").
+			Where("dashboard_snapshot_view.snapshot_id = ?", query.SnapshotId).
+			Desc("dashboard_snapshot_view.viewed", "dashboard_snapshot_view.id")
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		err := sess.Find(&views)
+		query.Result = views
+		return err
+	})
+}
+
 func (ss *SQLStore) GetDashboardSnapshot(query *models.GetDashboardSnapshotQuery) error {
 	snapshot := models.DashboardSnapshot{Key: query.Key, DeleteKey: query.DeleteKey}
 	has, err := x.Get(&snapshot)
@@ -242,6 +242,11 @@ func TestDashboardDataAccess(t *testing.T) {
 
 		require.Equal(t, len(query.Result), 0)
 
+		// the folder isn't moved to the trash as its rules can't be restored
+		deletedQuery := models.GetDeletedDashboardsQuery{OrgId: 1}
+		require.NoError(t, sqlStore.GetDeletedDashboards(context.Background(), &deletedQuery))
+		require.Empty(t, deletedQuery.Result)
+
 		sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
 			var existingRuleID int64
 			exists, err := sess.Table("alert_rule").Where("namespace_uid = (SELECT uid FROM dashboard WHERE id = ?)", savedFolder.Id).Cols("id").Get(&existingRuleID)
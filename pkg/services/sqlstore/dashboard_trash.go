@@ -0,0 +1,278 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type deletedDashboardProjection struct {
+	Id       int64
+	OrgId    int64
+	FolderId int64
+	IsFolder bool
+	Deleted  time.Time
+	Data     *simplejson.Json
+}
+
+// toDeletedDashboard returns the dashboard with the uid and title it had when it was deleted,
+// they are replaced in the dashboard table by deleteDashboard.
+func (p *deletedDashboardProjection) toDeletedDashboard() *models.DeletedDashboard {
+	return &models.DeletedDashboard{
+		Id:       p.Id,
+		OrgId:    p.OrgId,
+		Uid:      p.Data.Get("uid").MustString(),
+		Title:    p.Data.Get("title").MustString(),
+		FolderId: p.FolderId,
+		IsFolder: p.IsFolder,
+		Deleted:  p.Deleted,
+	}
+}
+
+func (ss *SQLStore) GetDeletedDashboards(ctx context.Context, query *models.GetDeletedDashboardsQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		var res []*deletedDashboardProjection
+		err := sess.Table("dashboard").
+			Where("org_id = ? AND deleted IS NOT NULL", query.OrgId).
+			Desc("deleted").
+			Asc("id").
+			Find(&res)
+		if err != nil {
+			return err
+		}
+
+		query.Result = make([]*models.DeletedDashboard, 0, len(res))
+		for _, dashboard := range res {
+			query.Result = append(query.Result, dashboard.toDeletedDashboard())
+		}
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetDeletedDashboard(ctx context.Context, query *models.GetDeletedDashboardQuery) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		dashboard, err := getDeletedDashboard(sess, query.Id, query.OrgId)
+		if err != nil {
+			return err
+		}
+
+		query.Result = dashboard.toDeletedDashboard()
+		return nil
+	})
+}
+
+func getDeletedDashboard(sess *DBSession, id int64, orgID int64) (*deletedDashboardProjection, error) {
+	var dashboard deletedDashboardProjection
+	has, err := sess.Table("dashboard").Where("id = ? AND org_id = ? AND deleted IS NOT NULL", id, orgID).Get(&dashboard)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, models.ErrDashboardNotFound
+	}
+	return &dashboard, nil
+}
+
+// RestoreDashboard restores a dashboard from the trash. The dashboard gets back the uid and title it had when it was
+// deleted unless the command overrides them, and restoring fails if they have been taken by another dashboard in the
+// meantime. Restoring a folder restores the dashboards deleted with it, those whose uid has been taken get a new one.
+func (ss *SQLStore) RestoreDashboard(ctx context.Context, cmd *models.RestoreDashboardCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var dashboard models.Dashboard
+		has, err := sess.Where("id = ? AND org_id = ? AND deleted IS NOT NULL", cmd.Id, cmd.OrgId).Get(&dashboard)
+		if err != nil {
+			return err
+		} else if !has {
+			return models.ErrDashboardNotFound
+		}
+
+		uid := cmd.Uid
+		if uid == "" {
+			uid = dashboard.Data.Get("uid").MustString()
+		}
+		title := cmd.Title
+		if title == "" {
+			title = dashboard.Data.Get("title").MustString()
+		}
+
+		dashboard.FolderId = cmd.FolderId
+		if dashboard.IsFolder {
+			dashboard.FolderId = 0
+		}
+
+		if dashboard.FolderId > 0 {
+			folderExists, err := sess.Where("org_id = ? AND id = ? AND is_folder = ?", cmd.OrgId, dashboard.FolderId,
+				dialect.BooleanStr(true)).Where(dashboardNotDeletedFilter).Exist(&models.Dashboard{})
+			if err != nil {
+				return err
+			}
+			if !folderExists {
+				return models.ErrDashboardFolderNotFound
+			}
+		}
+
+		uidExists, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgId, uid).Exist(&models.Dashboard{})
+		if err != nil {
+			return err
+		}
+		if uidExists {
+			return models.ErrDashboardWithSameUIDExists
+		}
+
+		dashboard.SetUid(uid)
+		dashboard.Title = title
+		dashboard.Data.Set("title", title)
+		dashboard.UpdateSlug()
+
+		if _, err := getExistingDashboardByTitleAndFolder(sess, &dashboard, false, false); err != nil {
+			return err
+		}
+
+		// the dashboards of a folder that were deleted with it have the same deleted timestamp
+		var children []*models.Dashboard
+		if dashboard.IsFolder {
+			err := sess.Where("org_id = ? AND folder_id = ? AND deleted = (SELECT deleted FROM dashboard WHERE id = ?)",
+				cmd.OrgId, dashboard.Id, dashboard.Id).Find(&children)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := restoreDashboard(sess, &dashboard); err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			childUID := child.Data.Get("uid").MustString()
+			uidExists, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgId, childUID).Exist(&models.Dashboard{})
+			if err != nil {
+				return err
+			}
+			if uidExists || childUID == "" {
+				if childUID, err = generateNewDashboardUid(sess, cmd.OrgId); err != nil {
+					return err
+				}
+			}
+
+			child.SetUid(childUID)
+			child.Title = child.Data.Get("title").MustString()
+			child.UpdateSlug()
+			if err := restoreDashboard(sess, child); err != nil {
+				return err
+			}
+		}
+
+		cmd.Result = &dashboard
+		cmd.Children = children
+		return nil
+	})
+}
+
+func restoreDashboard(sess *DBSession, dashboard *models.Dashboard) error {
+	dashboard.SetId(dashboard.Id)
+	if _, err := sess.ID(dashboard.Id).Cols("uid", "title", "slug", "data").MustCols("folder_id").Update(dashboard); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("UPDATE dashboard SET deleted = NULL WHERE id = ?", dashboard.Id); err != nil {
+		return err
+	}
+
+	sess.publishAfterCommit(&events.DashboardSaved{
+		Timestamp: time.Now(),
+		ID:        dashboard.Id,
+		UID:       dashboard.Uid,
+		OrgID:     dashboard.OrgId,
+		IsFolder:  dashboard.IsFolder,
+	})
+
+	return nil
+}
+
+// PurgeDashboard permanently deletes a dashboard in the trash, along with its versions, permissions, tags and
+// annotations. Purging a folder purges its dashboards.
+func (ss *SQLStore) PurgeDashboard(ctx context.Context, cmd *models.PurgeDashboardCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		dashboard, err := getDeletedDashboard(sess, cmd.Id, cmd.OrgId)
+		if err != nil {
+			return err
+		}
+
+		return purgeDashboard(sess, dashboard.Id, dashboard.IsFolder)
+	})
+}
+
+// PurgeDeletedDashboards permanently deletes the dashboards that were moved to the trash before cmd.OlderThan.
+func (ss *SQLStore) PurgeDeletedDashboards(ctx context.Context, cmd *models.PurgeDeletedDashboardsCommand) error {
+	var expired []*deletedDashboardProjection
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		// dashboards come first so that they are not purged along with their folder
+		return sess.Table("dashboard").
+			Where("deleted IS NOT NULL AND deleted < ?", cmd.OlderThan).
+			Asc("is_folder").
+			Cols("id", "org_id", "is_folder").
+			Find(&expired)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dashboard := range expired {
+		err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+			// the dashboard may have been restored or purged with its folder in the meantime
+			if _, err := getDeletedDashboard(sess, dashboard.Id, dashboard.OrgId); err != nil {
+				return err
+			}
+			return purgeDashboard(sess, dashboard.Id, dashboard.IsFolder)
+		})
+		if errors.Is(err, models.ErrDashboardNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows++
+	}
+
+	return nil
+}
+
+func purgeDashboard(sess *DBSession, id int64, isFolder bool) error {
+	deletes := []string{
+		"DELETE FROM dashboard_tag WHERE dashboard_id = ? ",
+		"DELETE FROM dashboard_reference WHERE dashboard_id = ?",
+		"DELETE FROM star WHERE dashboard_id = ? ",
+		"DELETE FROM playlist_item WHERE type = 'dashboard_by_id' AND value = ?",
+		"DELETE FROM dashboard_version WHERE dashboard_id = ?",
+		"DELETE FROM annotation WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_acl WHERE dashboard_id = ?",
+		"DELETE FROM dashboard WHERE id = ?",
+	}
+
+	// only the dashboards of the folder that are in the trash are purged with it
+	if isFolder {
+		childrenDeletes := []string{
+			"DELETE FROM dashboard_tag WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM dashboard_reference WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM star WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM dashboard_version WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM dashboard_provisioning WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM dashboard_acl WHERE dashboard_id IN (SELECT id FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL)",
+			"DELETE FROM dashboard WHERE folder_id = ? AND deleted IS NOT NULL",
+		}
+		deletes = append(childrenDeletes, deletes...)
+	}
+
+	for _, sql := range deletes {
+		if _, err := sess.Exec(sql, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+)
+
+func TestDashboardTrash(t *testing.T) {
+	var sqlStore *SQLStore
+	var folder, dash, childDash *models.Dashboard
+	ctx := context.Background()
+
+	setup := func() {
+		sqlStore = InitTestDB(t)
+		folder = insertTestDashboard(t, sqlStore, "trash folder", 1, 0, true)
+		childDash = insertTestDashboard(t, sqlStore, "trash child", 1, folder.Id, false, "prod")
+		dash = insertTestDashboard(t, sqlStore, "trash dash", 1, 0, false, "prod")
+	}
+
+	searchDashboards := func(t *testing.T) []*search.Hit {
+		t.Helper()
+		query := search.FindPersistedDashboardsQuery{OrgId: 1, SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN}}
+		require.NoError(t, sqlStore.SearchDashboards(ctx, &query))
+		return query.Result
+	}
+
+	deleted := func(t *testing.T) []*models.DeletedDashboard {
+		t.Helper()
+		query := models.GetDeletedDashboardsQuery{OrgId: 1}
+		require.NoError(t, sqlStore.GetDeletedDashboards(ctx, &query))
+		return query.Result
+	}
+
+	t.Run("Deleted dashboards are moved to the trash", func(t *testing.T) {
+		setup()
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}))
+
+		_, err := sqlStore.GetDashboard(0, 1, dash.Uid, "")
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+		assert.Len(t, searchDashboards(t), 2)
+
+		trash := deleted(t)
+		require.Len(t, trash, 1)
+		assert.Equal(t, dash.Uid, trash[0].Uid)
+		assert.Equal(t, dash.Title, trash[0].Title)
+
+		err = sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1})
+		require.ErrorIs(t, err, models.ErrDashboardNotFound)
+	})
+
+	t.Run("The versions of deleted dashboards cannot be read", func(t *testing.T) {
+		setup()
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}))
+
+		versionsQuery := models.GetDashboardVersionsQuery{DashboardId: dash.Id, OrgId: 1}
+		err := sqlStore.GetDashboardVersions(ctx, &versionsQuery)
+		require.ErrorIs(t, err, models.ErrNoVersionsForDashboardId)
+
+		versionQuery := models.GetDashboardVersionQuery{DashboardId: dash.Id, Version: dash.Version, OrgId: 1}
+		err = sqlStore.GetDashboardVersion(ctx, &versionQuery)
+		require.ErrorIs(t, err, models.ErrDashboardVersionNotFound)
+	})
+
+	t.Run("Restoring a dashboard fails when its uid or title have been reused", func(t *testing.T) {
+		setup()
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}))
+
+		_, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+			OrgId:     1,
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"uid": dash.Uid, "title": dash.Title}),
+		})
+		require.NoError(t, err)
+
+		cmd := models.RestoreDashboardCommand{Id: dash.Id, OrgId: 1}
+		require.ErrorIs(t, sqlStore.RestoreDashboard(ctx, &cmd), models.ErrDashboardWithSameUIDExists)
+
+		cmd.Uid = "restored"
+		require.ErrorIs(t, sqlStore.RestoreDashboard(ctx, &cmd), models.ErrDashboardWithSameNameInFolderExists)
+
+		cmd.Title = "trash dash restored"
+		require.NoError(t, sqlStore.RestoreDashboard(ctx, &cmd))
+		assert.Empty(t, deleted(t))
+
+		restored, err := sqlStore.GetDashboard(0, 1, "restored", "")
+		require.NoError(t, err)
+		assert.Equal(t, dash.Id, restored.Id)
+		assert.Equal(t, "trash dash restored", restored.Data.Get("title").MustString())
+	})
+
+	t.Run("Deleting a folder moves its dashboards to the trash and restoring it restores them", func(t *testing.T) {
+		setup()
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}))
+		assert.Len(t, searchDashboards(t), 1)
+		assert.Len(t, deleted(t), 2)
+
+		cmd := models.RestoreDashboardCommand{Id: childDash.Id, OrgId: 1, FolderId: folder.Id}
+		require.ErrorIs(t, sqlStore.RestoreDashboard(ctx, &cmd), models.ErrDashboardFolderNotFound)
+
+		cmd = models.RestoreDashboardCommand{Id: folder.Id, OrgId: 1}
+		require.NoError(t, sqlStore.RestoreDashboard(ctx, &cmd))
+		require.Len(t, cmd.Children, 1)
+		assert.Equal(t, childDash.Uid, cmd.Children[0].Uid)
+		assert.Len(t, searchDashboards(t), 3)
+		assert.Empty(t, deleted(t))
+	})
+
+	t.Run("Deleted dashboards can be purged", func(t *testing.T) {
+		setup()
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: dash.Id, OrgId: 1}))
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}))
+
+		require.ErrorIs(t, sqlStore.PurgeDashboard(ctx, &models.PurgeDashboardCommand{Id: dash.Id, OrgId: 2}), models.ErrDashboardNotFound)
+		require.NoError(t, sqlStore.PurgeDashboard(ctx, &models.PurgeDashboardCommand{Id: dash.Id, OrgId: 1}))
+		assert.Len(t, deleted(t), 2)
+
+		cmd := models.PurgeDeletedDashboardsCommand{OlderThan: time.Now().Add(-time.Hour)}
+		require.NoError(t, sqlStore.PurgeDeletedDashboards(ctx, &cmd))
+		assert.Equal(t, int64(0), cmd.DeletedRows)
+
+		cmd = models.PurgeDeletedDashboardsCommand{OlderThan: time.Now().Add(time.Hour)}
+		require.NoError(t, sqlStore.PurgeDeletedDashboards(ctx, &cmd))
+		assert.Equal(t, int64(2), cmd.DeletedRows)
+		assert.Empty(t, deleted(t))
+
+		versions := models.GetDashboardVersionsQuery{DashboardId: childDash.Id, OrgId: 1}
+		require.ErrorIs(t, sqlStore.GetDashboardVersions(ctx, &versions), models.ErrNoVersionsForDashboardId)
+	})
+
+	t.Run("Purging a folder only purges its dashboards in the trash", func(t *testing.T) {
+		setup()
+		require.NoError(t, sqlStore.DeleteDashboard(ctx, &models.DeleteDashboardCommand{Id: folder.Id, OrgId: 1}))
+		err := sqlStore.WithDbSession(ctx, func(sess *DBSession) error {
+			_, err := sess.Exec("UPDATE dashboard SET folder_id = ? WHERE id = ?", folder.Id, dash.Id)
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, sqlStore.PurgeDashboard(ctx, &models.PurgeDashboardCommand{Id: folder.Id, OrgId: 1}))
+		assert.Empty(t, deleted(t))
+
+		_, err = sqlStore.GetDashboard(0, 1, dash.Uid, "")
+		require.NoError(t, err)
+	})
+}
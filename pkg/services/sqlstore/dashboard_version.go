@@ -21,6 +21,7 @@ func (ss *SQLStore) GetDashboardVersion(ctx context.Context, query *models.GetDa
 		version := models.DashboardVersion{}
 		has, err := sess.Where("dashboard_version.dashboard_id=? AND dashboard_version.version=? AND dashboard.org_id=?", query.DashboardId, query.Version, query.OrgId).
 			Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
+			Where(dashboardNotDeletedFilter).
 			Get(&version)
 
 		if err != nil {
@@ -58,6 +59,7 @@ func (ss *SQLStore) GetDashboardVersions(ctx context.Context, query *models.GetD
 			Join("LEFT", dialect.Quote("user"), `dashboard_version.created_by = `+dialect.Quote("user")+`.id`).
 			Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
 			Where("dashboard_version.dashboard_id=? AND dashboard.org_id=?", query.DashboardId, query.OrgId).
+			Where(dashboardNotDeletedFilter).
 			OrderBy("dashboard_version.version DESC").
 			Limit(query.Limit, query.Start).
 			Find(&query.Result)
@@ -162,4 +162,16 @@ func AddMigration(mg *migrator.Migrator) {
 
 	//-------  indexes ------------------
 	mg.AddMigration("add unique index builtin_role_role_name", migrator.NewAddIndexMigration(seedAssignmentV1, seedAssignmentV1.Indices[0]))
+
+	permissionGenerationV1 := migrator.Table{
+		Name: "permission_generation",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true},
+			{Name: "generation", Type: migrator.DB_BigInt, Nullable: false},
+		},
+	}
+
+	mg.AddMigration("create permission generation table", migrator.NewAddTableMigration(permissionGenerationV1))
+	mg.AddMigration("insert permission generation", migrator.NewRawSQLMigration(
+		"INSERT INTO permission_generation (id, generation) VALUES (1, 0)"))
 }
@@ -230,4 +230,13 @@ func addDashboardMigration(mg *Migrator) {
 		Cols: []string{"is_folder"},
 		Type: IndexType,
 	}))
+
+	mg.AddMigration("Add column deleted in dashboard", NewAddColumnMigration(dashboardV2, &Column{
+		Name: "deleted", Type: DB_DateTime, Nullable: true,
+	}))
+
+	mg.AddMigration("Add index for dashboard_deleted", NewAddIndexMigration(dashboardV2, &Index{
+		Cols: []string{"deleted"},
+		Type: IndexType,
+	}))
 }
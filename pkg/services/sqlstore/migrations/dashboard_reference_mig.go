@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"xorm.io/xorm"
+)
+
+func addDashboardReferenceMigrations(mg *Migrator) {
+	dashboardReferenceV1 := Table{
+		Name: "dashboard_reference",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "type", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "variable", Type: DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id"}},
+			{Cols: []string{"org_id", "kind", "uid"}},
+			{Cols: []string{"org_id", "kind", "name"}},
+			{Cols: []string{"kind", "type"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_reference table", NewAddTableMigration(dashboardReferenceV1))
+	addTableIndicesMigrations(mg, "v1", dashboardReferenceV1)
+
+	mg.AddMigration("populate dashboard_reference table", &populateDashboardReferencesMigration{})
+}
+
+// populateDashboardReferencesMigration extracts the references of the existing dashboards, the references of a
+// dashboard are updated when it is saved.
+type populateDashboardReferencesMigration struct {
+	MigrationBase
+}
+
+func (m *populateDashboardReferencesMigration) SQL(dialect Dialect) string {
+	return "code migration"
+}
+
+type dashboardDataDTO struct {
+	Id    int64
+	OrgId int64
+	Data  *simplejson.Json
+}
+
+func (m *populateDashboardReferencesMigration) Exec(sess *xorm.Session, mg *Migrator) error {
+	var lastID int64
+	for {
+		var dashboards []*dashboardDataDTO
+		err := sess.Table("dashboard").
+			Where("id > ? AND is_folder = ?", lastID, mg.Dialect.BooleanStr(false)).
+			Cols("id", "org_id", "data").
+			Asc("id").
+			Limit(100).
+			Find(&dashboards)
+		if err != nil {
+			return err
+		}
+		if len(dashboards) == 0 {
+			return nil
+		}
+
+		for _, dash := range dashboards {
+			lastID = dash.Id
+			if dash.Data == nil {
+				continue
+			}
+
+			refs := (&models.Dashboard{Id: dash.Id, OrgId: dash.OrgId, Data: dash.Data}).GetReferences()
+			for _, ref := range refs {
+				if _, err := sess.Insert(ref); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
@@ -71,4 +71,33 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add column blob_key to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "blob_key", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+
+	mg.AddMigration("Add column visibility to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "visibility", Type: DB_NVarchar, Length: 40, Nullable: true,
+	}))
+
+	mg.AddMigration("Add column team_ids to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "team_ids", Type: DB_Text, Nullable: true,
+	}))
+
+	snapshotView := Table{
+		Name: "dashboard_snapshot_view",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "snapshot_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "viewed", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"snapshot_id", "viewed"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_snapshot_view table", NewAddTableMigration(snapshotView))
+	addTableIndicesMigrations(mg, "v1", snapshotView)
 }
@@ -68,6 +68,9 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	addKVStoreMigrations(mg)
 	ualert.AddDashboardUIDPanelIDMigration(mg)
 	accesscontrol.AddMigration(mg)
+	addUserTOTPMigrations(mg)
+	addDashboardReferenceMigrations(mg)
+	addReportMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addReportMigrations(mg *Migrator) {
+	reportV1 := Table{
+		Name: "report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "variables", Type: DB_Text, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "layout", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "orientation", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "formats", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "schedule_cron", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "schedule_interval", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "reply_to", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "next_run", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"enabled", "next_run"}, Type: IndexType},
+		},
+	}
+
+	mg.AddMigration("create report table", NewAddTableMigration(reportV1))
+	mg.AddMigration("add unique index report.org_id_uid", NewAddIndexMigration(reportV1, reportV1.Indices[0]))
+	mg.AddMigration("add index report.enabled_next_run", NewAddIndexMigration(reportV1, reportV1.Indices[1]))
+
+	reportRunV1 := Table{
+		Name: "report_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "report_id", Type: DB_BigInt, Nullable: false},
+			{Name: "triggered_by", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "attempt", Type: DB_Int, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: true},
+			{Name: "scheduled_at", Type: DB_BigInt, Nullable: false},
+			{Name: "started", Type: DB_DateTime, Nullable: true},
+			{Name: "finished", Type: DB_DateTime, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"report_id"}, Type: IndexType},
+			{Cols: []string{"state", "scheduled_at"}, Type: IndexType},
+		},
+	}
+
+	mg.AddMigration("create report_run table", NewAddTableMigration(reportRunV1))
+	mg.AddMigration("add index report_run.report_id", NewAddIndexMigration(reportRunV1, reportRunV1.Indices[0]))
+	mg.AddMigration("add index report_run.state_scheduled_at", NewAddIndexMigration(reportRunV1, reportRunV1.Indices[1]))
+}
@@ -24,4 +24,37 @@ func addShortURLMigrations(mg *Migrator) {
 	mg.AddMigration("create short_url table v1", NewAddTableMigration(shortURLV1))
 
 	mg.AddMigration("add index short_url.org_id-uid", NewAddIndexMigration(shortURLV1, shortURLV1.Indices[0]))
+
+	mg.AddMigration("add expires_at column to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "expires_at", Type: DB_BigInt, Nullable: true,
+	}))
+
+	mg.AddMigration("add pinned column to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "pinned", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add hits column to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "hits", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add index short_url.org_id-created_by", NewAddIndexMigration(shortURLV1, &Index{
+		Cols: []string{"org_id", "created_by"},
+	}))
+
+	shortURLHitV1 := Table{
+		Name: "short_url_hit",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "short_url_id", Type: DB_BigInt, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "hits", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"short_url_id", "day"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create short_url_hit table v1", NewAddTableMigration(shortURLHitV1))
+
+	mg.AddMigration("add unique index short_url_hit.short_url_id-day", NewAddIndexMigration(shortURLHitV1, shortURLHitV1.Indices[0]))
 }
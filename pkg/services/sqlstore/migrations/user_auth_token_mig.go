@@ -44,4 +44,16 @@ func addUserAuthTokenMigrations(mg *Migrator) {
 			},
 		),
 	)
+
+	mg.AddMigration(
+		"Add last_seen_at to the user auth token",
+		NewAddColumnMigration(
+			userAuthTokenV1,
+			&Column{
+				Name:     "last_seen_at",
+				Type:     DB_Int,
+				Nullable: true,
+			},
+		),
+	)
 }
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addUserTOTPMigrations(mg *Migrator) {
+	userTOTPV1 := Table{
+		Name: "user_totp",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "last_used_step", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create user_totp table", NewAddTableMigration(userTOTPV1))
+	mg.AddMigration("add unique index user_totp.user_id", NewAddIndexMigration(userTOTPV1, userTOTPV1.Indices[0]))
+
+	recoveryCodeV1 := Table{
+		Name: "user_totp_recovery_code",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "code", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "salt", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: IndexType},
+		},
+	}
+
+	mg.AddMigration("create user_totp_recovery_code table", NewAddTableMigration(recoveryCodeV1))
+	mg.AddMigration("add index user_totp_recovery_code.user_id", NewAddIndexMigration(recoveryCodeV1, recoveryCodeV1.Indices[0]))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func (ss *SQLStore) addPlaylistQueryAndCommandHandlers() {
@@ -13,12 +14,33 @@ func (ss *SQLStore) addPlaylistQueryAndCommandHandlers() {
 	bus.AddHandler("sql", ss.DeletePlaylist)
 	bus.AddHandler("sql", ss.SearchPlaylists)
 	bus.AddHandler("sql", ss.GetPlaylist)
+	bus.AddHandler("sql", ss.GetPlaylistByUid)
 	bus.AddHandler("sql", ss.GetPlaylistItem)
 }
 
 func (ss *SQLStore) CreatePlaylist(ctx context.Context, cmd *models.CreatePlaylistCommand) error {
 	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		uid := cmd.Uid
+		if uid == "" {
+			uid = util.GenerateShortUID()
+		} else {
+			if !util.IsValidShortUID(uid) {
+				return models.ErrPlaylistInvalidUID
+			}
+			if util.IsShortUIDTooLong(uid) {
+				return models.ErrPlaylistUIDTooLong
+			}
+			exists, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgId, uid).Exist(&models.Playlist{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				return models.ErrPlaylistWithSameUIDExists
+			}
+		}
+
 		playlist := models.Playlist{
+			Uid:      uid,
 			Name:     cmd.Name,
 			Interval: cmd.Interval,
 			OrgId:    cmd.OrgId,
@@ -37,12 +59,17 @@ func (ss *SQLStore) CreatePlaylist(ctx context.Context, cmd *models.CreatePlayli
 				Value:      item.Value,
 				Order:      item.Order,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				Options:    item.Options,
 			})
 		}
 
-		_, err = sess.Insert(&playlistItems)
-
 		cmd.Result = &playlist
+		if len(playlistItems) == 0 {
+			return nil
+		}
+
+		_, err = sess.Insert(&playlistItems)
 		return err
 	})
 }
@@ -56,20 +83,24 @@ func (ss *SQLStore) UpdatePlaylist(ctx context.Context, cmd *models.UpdatePlayli
 			Interval: cmd.Interval,
 		}
 
-		existingPlaylist := sess.Where("id = ? AND org_id = ?", cmd.Id, cmd.OrgId).Find(models.Playlist{})
-
-		if existingPlaylist == nil {
+		existingPlaylist := models.Playlist{}
+		exists, err := sess.Where("id = ? AND org_id = ?", cmd.Id, cmd.OrgId).Get(&existingPlaylist)
+		if err != nil {
+			return err
+		}
+		if !exists {
 			return models.ErrPlaylistNotFound
 		}
 
 		cmd.Result = &models.PlaylistDTO{
 			Id:       playlist.Id,
+			Uid:      existingPlaylist.Uid,
 			OrgId:    playlist.OrgId,
 			Name:     playlist.Name,
 			Interval: playlist.Interval,
 		}
 
-		_, err := sess.ID(cmd.Id).Cols("name", "interval").Update(&playlist)
+		_, err = sess.ID(cmd.Id).Cols("name", "interval").Update(&playlist)
 
 		if err != nil {
 			return err
@@ -91,11 +122,16 @@ func (ss *SQLStore) UpdatePlaylist(ctx context.Context, cmd *models.UpdatePlayli
 				Value:      item.Value,
 				Order:      index + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				Options:    item.Options,
 			})
 		}
 
-		_, err = sess.Insert(&playlistItems)
+		if len(playlistItems) == 0 {
+			return nil
+		}
 
+		_, err = sess.Insert(&playlistItems)
 		return err
 	})
 }
@@ -115,6 +151,26 @@ func (ss *SQLStore) GetPlaylist(ctx context.Context, query *models.GetPlaylistBy
 	})
 }
 
+func (ss *SQLStore) GetPlaylistByUid(ctx context.Context, query *models.GetPlaylistByUidQuery) error {
+	if query.Uid == "" || query.OrgId == 0 {
+		return models.ErrCommandValidationFailed
+	}
+
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		playlist := models.Playlist{}
+		exists, err := sess.Where("org_id = ? AND uid = ?", query.OrgId, query.Uid).Get(&playlist)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrPlaylistNotFound
+		}
+
+		query.Result = &playlist
+		return nil
+	})
+}
+
 func (ss *SQLStore) DeletePlaylist(ctx context.Context, cmd *models.DeletePlaylistCommand) error {
 	if cmd.Id == 0 || cmd.OrgId == 0 {
 		return models.ErrCommandValidationFailed
@@ -45,6 +45,54 @@ func TestPlaylistDataAccess(t *testing.T) {
 		})
 	})
 
+	t.Run("Can get playlist by uid with item intervals and options", func(t *testing.T) {
+		items := []models.PlaylistItemDTO{
+			{Title: "EU", Value: "overview", Type: models.PlaylistItemTypeDashboardByUID, Interval: "30s", Options: &models.PlaylistItemOptions{
+				Variables: map[string][]string{"region": {"eu"}},
+				From:      "now-1h",
+				To:        "now",
+			}},
+			{Title: "NOC", Value: "status", Type: models.PlaylistItemTypeDashboardSearch, Options: &models.PlaylistItemOptions{
+				FolderUIDs: []string{"noc"},
+				Tags:       []string{"wall"},
+			}},
+		}
+		cmd := models.CreatePlaylistCommand{Uid: "noc-wall", Name: "NOC wall", Interval: "5m", OrgId: 1, Items: items}
+		err := ss.CreatePlaylist(context.Background(), &cmd)
+		require.NoError(t, err)
+
+		query := models.GetPlaylistByUidQuery{Uid: "noc-wall", OrgId: 1}
+		err = ss.GetPlaylistByUid(context.Background(), &query)
+		require.NoError(t, err)
+		require.Equal(t, cmd.Result.Id, query.Result.Id)
+
+		itemsQuery := models.GetPlaylistItemsByIdQuery{PlaylistId: query.Result.Id}
+		err = ss.GetPlaylistItem(context.Background(), &itemsQuery)
+		require.NoError(t, err)
+		result := *itemsQuery.Result
+		require.Len(t, result, 2)
+		require.Equal(t, "30s", result[0].Interval)
+		require.Equal(t, items[0].Options, result[0].Options)
+		require.Equal(t, items[1].Options, result[1].Options)
+
+		t.Run("Can not reuse the uid of a playlist", func(t *testing.T) {
+			err := ss.CreatePlaylist(context.Background(), &models.CreatePlaylistCommand{Uid: "noc-wall", Name: "Copy", Interval: "5m", OrgId: 1})
+			require.ErrorIs(t, err, models.ErrPlaylistWithSameUIDExists)
+		})
+
+		t.Run("Generates a uid when none is given", func(t *testing.T) {
+			cmd := models.CreatePlaylistCommand{Name: "Lobby", Interval: "5m", OrgId: 1}
+			err := ss.CreatePlaylist(context.Background(), &cmd)
+			require.NoError(t, err)
+			require.NotEmpty(t, cmd.Result.Uid)
+		})
+
+		t.Run("Playlist uids are scoped to the organization", func(t *testing.T) {
+			err := ss.GetPlaylistByUid(context.Background(), &models.GetPlaylistByUidQuery{Uid: "noc-wall", OrgId: 2})
+			require.ErrorIs(t, err, models.ErrPlaylistNotFound)
+		})
+	})
+
 	t.Run("Delete playlist that doesn't exist", func(t *testing.T) {
 		deleteQuery := models.DeletePlaylistCommand{Id: 1, OrgId: 1}
 		err := ss.DeletePlaylist(context.Background(), &deleteQuery)
@@ -59,6 +59,13 @@ func (f TypeFilter) Where() (string, []interface{}) {
 	return "", nil
 }
 
+// NotDeletedFilter excludes the dashboards and folders in the trash.
+type NotDeletedFilter struct{}
+
+func (f NotDeletedFilter) Where() (string, []interface{}) {
+	return "dashboard.deleted IS NULL", nil
+}
+
 type OrgFilter struct {
 	OrgId int64
 }
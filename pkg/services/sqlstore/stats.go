@@ -62,8 +62,8 @@ func GetSystemStats(ctx context.Context, query *models.GetSystemStatsQuery) erro
 	monthlyActiveUserDeadlineDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	sb.Write(`(SELECT COUNT(*) FROM `+dialect.Quote("user")+` WHERE last_seen_at > ?) AS monthly_active_users,`, monthlyActiveUserDeadlineDate)
 
-	sb.Write(`(SELECT COUNT(id) FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ?) AS dashboards,`, dialect.BooleanStr(false))
-	sb.Write(`(SELECT COUNT(id) FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ?) AS folders,`, dialect.BooleanStr(true))
+	sb.Write(`(SELECT COUNT(id) FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ? AND deleted IS NULL) AS dashboards,`, dialect.BooleanStr(false))
+	sb.Write(`(SELECT COUNT(id) FROM `+dialect.Quote("dashboard")+` WHERE is_folder = ? AND deleted IS NULL) AS folders,`, dialect.BooleanStr(true))
 
 	sb.Write(`(
 		SELECT COUNT(acl.id)
@@ -154,7 +154,7 @@ func GetAdminStats(ctx context.Context, query *models.GetAdminStatsQuery) error
 		) AS orgs,
 		(
 			SELECT COUNT(*)
-			FROM ` + dialect.Quote("dashboard") + `WHERE is_folder=` + dialect.BooleanStr(false) + `
+			FROM ` + dialect.Quote("dashboard") + `WHERE is_folder=` + dialect.BooleanStr(false) + ` AND deleted IS NULL
 		) AS dashboards,
 		(
 			SELECT COUNT(*)
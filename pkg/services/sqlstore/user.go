@@ -570,6 +570,7 @@ func (ss *SQLStore) GetSignedInUser(ctx context.Context, query *models.GetSigned
 		u.name           as name,
 		u.help_flags1    as help_flags1,
 		u.last_seen_at   as last_seen_at,
+		u.is_service_account as is_service_account,
 		(SELECT COUNT(*) FROM org_user where org_user.user_id = u.id) as org_count,
 		org.name         as org_name,
 		org_user.role    as org_role,
@@ -799,6 +800,8 @@ func userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_totp WHERE user_id = ?",
+		"DELETE FROM user_totp_recovery_code WHERE user_id = ?",
 	}
 	return deletes
 }
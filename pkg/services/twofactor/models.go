@@ -0,0 +1,68 @@
+package twofactor
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDisabled          = errors.New("two-factor authentication is disabled")
+	ErrNotEnrolled       = errors.New("two-factor authentication is not enabled for the user")
+	ErrAlreadyEnrolled   = errors.New("two-factor authentication is already enabled for the user")
+	ErrInvalidCode       = errors.New("invalid two-factor authentication code")
+	ErrChallengeNotFound = errors.New("two-factor authentication challenge not found or expired")
+	ErrRequiredForAdmins = errors.New("two-factor authentication is required for administrators")
+	ErrTooManyAttempts   = errors.New("too many incorrect two-factor authentication codes for user - login for user temporarily blocked")
+)
+
+// credential is the TOTP secret of a user. The secret is encrypted with the
+// secrets service. A credential that is not enabled is a pending enrollment.
+type credential struct {
+	Id           int64
+	UserId       int64
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+	Created      time.Time
+	Updated      time.Time
+}
+
+func (credential) TableName() string {
+	return "user_totp"
+}
+
+// recoveryCode is a hashed single use code replacing a TOTP code when the
+// user lost access to the authenticator.
+type recoveryCode struct {
+	Id      int64
+	UserId  int64
+	Code    string
+	Salt    string
+	Created time.Time
+}
+
+func (recoveryCode) TableName() string {
+	return "user_totp_recovery_code"
+}
+
+// Status is the two-factor authentication state of a user.
+type Status struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// Enrollment holds what the user needs to configure an authenticator app.
+// URI is meant to be rendered as a QR code.
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// Challenge is the pending second login step of a user whose password was verified.
+// ID identifies the attempts of the challenge in the login attempt store,
+// without revealing the token.
+type Challenge struct {
+	ID     string
+	UserID int64
+}
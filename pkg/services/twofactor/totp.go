@@ -0,0 +1,101 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 HMAC-SHA1 is mandated by RFC 6238 and what authenticator apps implement
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod is the validity of a single code.
+	totpPeriod = 30
+	// totpDigits is the number of digits of a code.
+	totpDigits = 6
+	// totpSkew is the number of periods before and after the current one
+	// that are still accepted to tolerate clock drift.
+	totpSkew = 1
+	// secretSize is the secret length in bytes, 160 bits as recommended by RFC 4226.
+	secretSize = 20
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateSecret returns a new random base32 encoded TOTP secret.
+func generateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+// GenerateCode returns the code an authenticator configured with the secret
+// displays at time t.
+func GenerateCode(secret string, t time.Time) (string, error) {
+	return generateCode(secret, t.Unix()/totpPeriod)
+}
+
+// generateCode returns the code for the given counter, as described in RFC 4226.
+func generateCode(secret string, counter int64) (string, error) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// validateCode checks the code against the periods around t and returns the
+// matched time step. Steps up to and including lastUsedStep are refused so a
+// code cannot be replayed.
+func validateCode(secret string, code string, t time.Time, lastUsedStep int64) (int64, bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := t.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		expected, err := generateCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// provisioningURI returns the otpauth URI authenticator apps read from a QR code.
+// See https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func provisioningURI(issuer string, account string, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
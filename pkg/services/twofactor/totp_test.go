@@ -0,0 +1,78 @@
+package twofactor
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the RFC 6238 test secret "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCode(t *testing.T) {
+	// Test vectors from RFC 6238 appendix B, truncated to 6 digits.
+	testCases := []struct {
+		time     int64
+		expected string
+	}{
+		{time: 59, expected: "287082"},
+		{time: 1111111109, expected: "081804"},
+		{time: 1234567890, expected: "005924"},
+		{time: 2000000000, expected: "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := generateCode(rfcSecret, tc.time/totpPeriod)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, code)
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / totpPeriod
+
+	t.Run("accepts the current code", func(t *testing.T) {
+		matched, ok := validateCode(rfcSecret, "005924", now, 0)
+		require.True(t, ok)
+		require.Equal(t, step, matched)
+	})
+
+	t.Run("tolerates clock drift of one period", func(t *testing.T) {
+		previous, err := generateCode(rfcSecret, step-1)
+		require.NoError(t, err)
+		_, ok := validateCode(rfcSecret, previous, now, 0)
+		require.True(t, ok)
+
+		older, err := generateCode(rfcSecret, step-2)
+		require.NoError(t, err)
+		_, ok = validateCode(rfcSecret, older, now, 0)
+		require.False(t, ok)
+	})
+
+	t.Run("refuses replayed codes", func(t *testing.T) {
+		_, ok := validateCode(rfcSecret, "005924", now, step)
+		require.False(t, ok)
+	})
+
+	t.Run("refuses malformed codes", func(t *testing.T) {
+		_, ok := validateCode(rfcSecret, "5924", now, 0)
+		require.False(t, ok)
+	})
+}
+
+func TestProvisioningURI(t *testing.T) {
+	secret, err := generateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	uri, err := url.Parse(provisioningURI("Grafana", "admin@example.com", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Grafana:admin@example.com", uri.Path)
+	require.Equal(t, secret, uri.Query().Get("secret"))
+	require.Equal(t, "Grafana", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,504 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	challengeTTL         = 5 * time.Minute
+	challengeMaxAttempts = 5
+	challengeKeyPrefix   = "two-factor-challenge-"
+	// challengeAttemptPrefix prefixes the challenge ID in the username of the
+	// login attempts recorded for each code submitted to a challenge.
+	challengeAttemptPrefix = "two-factor-challenge:"
+
+	// Failed codes count as failed logins of the user, with the same limit
+	// and window as the login brute force protection.
+	maxInvalidLoginAttempts = 5
+	loginAttemptsWindow     = 5 * time.Minute
+
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+func init() {
+	remotecache.Register(&Challenge{})
+}
+
+// Service manages TOTP based two-factor authentication of Grafana-managed users.
+type Service struct {
+	cfg            *setting.Cfg
+	sqlStore       *sqlstore.SQLStore
+	secretsService secrets.Service
+	remoteCache    *remotecache.RemoteCache
+	log            log.Logger
+
+	// now returns the current time.
+	// Stubbable by tests.
+	now func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, secretsService secrets.Service,
+	remoteCache *remotecache.RemoteCache) *Service {
+	return &Service{
+		cfg:            cfg,
+		sqlStore:       sqlStore,
+		secretsService: secretsService,
+		remoteCache:    remoteCache,
+		log:            log.New("twofactor"),
+		now:            time.Now,
+	}
+}
+
+// IsEnabled reports whether two-factor authentication is enabled on the server.
+func (s *Service) IsEnabled() bool {
+	return s.cfg.TwoFactorEnabled
+}
+
+// GetStatus returns the two-factor authentication state of the user.
+func (s *Service) GetStatus(ctx context.Context, user *models.User) (*Status, error) {
+	status := &Status{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		cred, err := getCredential(sess, user.Id)
+		if err != nil {
+			return err
+		}
+		if cred != nil && cred.Enabled {
+			status.Enabled = true
+			count, err := sess.Where("user_id = ?", user.Id).Count(&recoveryCode{})
+			if err != nil {
+				return err
+			}
+			status.RecoveryCodesRemaining = int(count)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status.Required, err = s.IsEnforced(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	status.Required = status.Required || status.Enabled
+	return status, nil
+}
+
+// IsEnrolled reports whether the user has a verified authenticator.
+func (s *Service) IsEnrolled(ctx context.Context, userID int64) (bool, error) {
+	enrolled := false
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		cred, err := getCredential(sess, userID)
+		enrolled = cred != nil && cred.Enabled
+		return err
+	})
+	return enrolled, err
+}
+
+// IsEnforced reports whether the server requires the user to enroll.
+func (s *Service) IsEnforced(ctx context.Context, user *models.User) (bool, error) {
+	if !s.cfg.TwoFactorEnforceForAdmins {
+		return false, nil
+	}
+	if user.IsAdmin {
+		return true, nil
+	}
+
+	isOrgAdmin := false
+	err := s.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		count, err := sess.Table("org_user").Where("user_id = ? AND role = ?", user.Id, models.ROLE_ADMIN).Count()
+		isOrgAdmin = count > 0
+		return err
+	})
+	return isOrgAdmin, err
+}
+
+// IsRequired reports whether the user has to provide a second factor to sign
+// in, either because they enrolled or because the server enforces it.
+func (s *Service) IsRequired(ctx context.Context, user *models.User) (bool, error) {
+	enrolled, err := s.IsEnrolled(ctx, user.Id)
+	if err != nil || enrolled {
+		return enrolled, err
+	}
+	return s.IsEnforced(ctx, user)
+}
+
+// StartEnrollment generates a new secret for the user. The secret is pending
+// until CompleteEnrollment is called with a code generated from it.
+func (s *Service) StartEnrollment(ctx context.Context, user *models.User) (*Enrollment, error) {
+	if !s.IsEnabled() {
+		return nil, ErrDisabled
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.secretsService.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		cred, err := getCredential(sess, user.Id)
+		if err != nil {
+			return err
+		}
+		if cred != nil && cred.Enabled {
+			return ErrAlreadyEnrolled
+		}
+		if _, err := sess.Where("user_id = ?", user.Id).Delete(&credential{}); err != nil {
+			return err
+		}
+
+		now := s.now()
+		_, err = sess.Insert(&credential{
+			UserId:  user.Id,
+			Secret:  base64.StdEncoding.EncodeToString(encrypted),
+			Created: now,
+			Updated: now,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account := user.Login
+	if user.Email != "" {
+		account = user.Email
+	}
+	return &Enrollment{Secret: secret, URI: provisioningURI(s.cfg.TwoFactorIssuer, account, secret)}, nil
+}
+
+// CompleteEnrollment enables two-factor authentication once the user proved
+// the authenticator is configured. It returns the recovery codes, which are
+// only shown once.
+func (s *Service) CompleteEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	var codes []string
+	err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		cred, err := getCredential(sess, userID)
+		if err != nil {
+			return err
+		}
+		if cred == nil {
+			return ErrNotEnrolled
+		}
+		if cred.Enabled {
+			return ErrAlreadyEnrolled
+		}
+
+		if err := s.verifyTOTP(ctx, sess, cred, code); err != nil {
+			return err
+		}
+		cred.Enabled = true
+		if _, err := sess.ID(cred.Id).Cols("enabled").Update(cred); err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(sess, userID)
+		return err
+	})
+	return codes, err
+}
+
+// Verify checks a TOTP code or consumes a recovery code of an enrolled user.
+func (s *Service) Verify(ctx context.Context, userID int64, code string) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		cred, err := getCredential(sess, userID)
+		if err != nil {
+			return err
+		}
+		if cred == nil || !cred.Enabled {
+			return ErrNotEnrolled
+		}
+
+		if isTOTPCode(code) {
+			return s.verifyTOTP(ctx, sess, cred, code)
+		}
+		return verifyRecoveryCode(sess, userID, code)
+	})
+}
+
+// RegenerateRecoveryCodes invalidates the recovery codes of the user and
+// returns new ones.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	var codes []string
+	err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		cred, err := getCredential(sess, userID)
+		if err != nil {
+			return err
+		}
+		if cred == nil || !cred.Enabled {
+			return ErrNotEnrolled
+		}
+
+		codes, err = s.replaceRecoveryCodes(sess, userID)
+		return err
+	})
+	return codes, err
+}
+
+// Disable removes the authenticator and recovery codes of the user.
+func (s *Service) Disable(ctx context.Context, userID int64) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Where("user_id = ?", userID).Delete(&credential{}); err != nil {
+			return err
+		}
+		_, err := sess.Where("user_id = ?", userID).Delete(&recoveryCode{})
+		return err
+	})
+}
+
+// CreateChallenge stores the pending second login step of the user and
+// returns the token identifying it.
+func (s *Service) CreateChallenge(ctx context.Context, userID int64) (string, error) {
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return "", err
+	}
+	id, err := util.GetRandomString(16)
+	if err != nil {
+		return "", err
+	}
+	if err := s.remoteCache.Set(ctx, challengeKeyPrefix+token, &Challenge{ID: id, UserID: userID}, challengeTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CompleteChallenge verifies the code for the challenge and returns the user
+// it was created for. A user who enrolls during login gets their recovery
+// codes. The challenge is removed after success or too many attempts.
+//
+// Every attempt is recorded in the login attempt store before the code is
+// checked, so that concurrent attempts cannot exceed the limit of the
+// challenge. Failed codes are also recorded against the user, which blocks
+// both further codes and password logins of the user for a while, like failed
+// passwords do.
+func (s *Service) CompleteChallenge(ctx context.Context, token string, code string, ipAddress string) (int64, []string, error) {
+	key := challengeKeyPrefix + token
+	value, err := s.remoteCache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return 0, nil, ErrChallengeNotFound
+		}
+		return 0, nil, err
+	}
+	challenge, ok := value.(*Challenge)
+	if !ok || token == "" || challenge.ID == "" {
+		return 0, nil, ErrChallengeNotFound
+	}
+
+	attempts, err := s.recordAttempt(ctx, challengeAttemptPrefix+challenge.ID, ipAddress, time.Now().Add(-challengeTTL))
+	if err != nil {
+		return 0, nil, err
+	}
+	if attempts > challengeMaxAttempts {
+		s.deleteChallenge(ctx, key)
+		return 0, nil, ErrChallengeNotFound
+	}
+
+	query := models.GetUserByIdQuery{Id: challenge.UserID}
+	if err := s.sqlStore.GetUserById(ctx, &query); err != nil {
+		return 0, nil, err
+	}
+	user := query.Result
+
+	if !s.cfg.DisableBruteForceLoginProtection {
+		failed := models.GetUserLoginAttemptCountQuery{Username: user.Login, Since: time.Now().Add(-loginAttemptsWindow)}
+		if err := sqlstore.GetUserLoginAttemptCount(ctx, &failed); err != nil {
+			return 0, nil, err
+		}
+		if failed.Result >= maxInvalidLoginAttempts {
+			s.deleteChallenge(ctx, key)
+			return 0, nil, ErrTooManyAttempts
+		}
+	}
+
+	enrolled, err := s.IsEnrolled(ctx, user.Id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var codes []string
+	if enrolled {
+		err = s.Verify(ctx, user.Id, code)
+	} else {
+		codes, err = s.CompleteEnrollment(ctx, user.Id, code)
+	}
+
+	if errors.Is(err, ErrInvalidCode) {
+		s.recordFailedCode(ctx, user, ipAddress)
+		if attempts >= challengeMaxAttempts {
+			s.deleteChallenge(ctx, key)
+		}
+		return 0, nil, err
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.deleteChallenge(ctx, key)
+	return user.Id, codes, nil
+}
+
+// recordAttempt stores a login attempt for username and returns the number of
+// attempts for it since the given time, including this one.
+func (s *Service) recordAttempt(ctx context.Context, username string, ipAddress string, since time.Time) (int64, error) {
+	cmd := models.CreateLoginAttemptCommand{Username: username, IpAddress: ipAddress}
+	if err := s.sqlStore.CreateLoginAttempt(ctx, &cmd); err != nil {
+		return 0, err
+	}
+	query := models.GetUserLoginAttemptCountQuery{Username: username, Since: since}
+	if err := sqlstore.GetUserLoginAttemptCount(ctx, &query); err != nil {
+		return 0, err
+	}
+	return query.Result, nil
+}
+
+// recordFailedCode counts a failed code as a failed login for the login and
+// the email of the user, since users can sign in with either.
+func (s *Service) recordFailedCode(ctx context.Context, user *models.User, ipAddress string) {
+	if s.cfg.DisableBruteForceLoginProtection {
+		return
+	}
+	usernames := []string{user.Login}
+	if user.Email != "" && user.Email != user.Login {
+		usernames = append(usernames, user.Email)
+	}
+	for _, username := range usernames {
+		cmd := models.CreateLoginAttemptCommand{Username: username, IpAddress: ipAddress}
+		if err := s.sqlStore.CreateLoginAttempt(ctx, &cmd); err != nil {
+			s.log.Error("Failed to record failed two-factor authentication attempt", "err", err)
+		}
+	}
+}
+
+func (s *Service) deleteChallenge(ctx context.Context, key string) {
+	if err := s.remoteCache.Delete(ctx, key); err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.log.Error("Failed to delete two-factor challenge", "err", err)
+	}
+}
+
+func (s *Service) verifyTOTP(ctx context.Context, sess *sqlstore.DBSession, cred *credential, code string) error {
+	encrypted, err := base64.StdEncoding.DecodeString(cred.Secret)
+	if err != nil {
+		return err
+	}
+	secret, err := s.secretsService.Decrypt(ctx, encrypted)
+	if err != nil {
+		return err
+	}
+
+	step, ok := validateCode(string(secret), code, s.now(), cred.LastUsedStep)
+	if !ok {
+		return ErrInvalidCode
+	}
+
+	// a concurrent request with the same code may have used the step since the credential was read
+	cred.LastUsedStep = step
+	cred.Updated = s.now()
+	affected, err := sess.Where("id = ? AND last_used_step < ?", cred.Id, step).Cols("last_used_step", "updated").Update(cred)
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+func (s *Service) replaceRecoveryCodes(sess *sqlstore.DBSession, userID int64) ([]string, error) {
+	if _, err := sess.Where("user_id = ?", userID).Delete(&recoveryCode{}); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(recoveryCodeLength, []byte(recoveryCodeAlphabet)...)
+		if err != nil {
+			return nil, err
+		}
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return nil, err
+		}
+		hashed, err := util.EncodePassword(code, salt)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := sess.Insert(&recoveryCode{UserId: userID, Code: hashed, Salt: salt, Created: s.now()}); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return codes, nil
+}
+
+func verifyRecoveryCode(sess *sqlstore.DBSession, userID int64, code string) error {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != recoveryCodeLength {
+		return ErrInvalidCode
+	}
+
+	var stored []*recoveryCode
+	if err := sess.Where("user_id = ?", userID).Find(&stored); err != nil {
+		return err
+	}
+	for _, rc := range stored {
+		hashed, err := util.EncodePassword(code, rc.Salt)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(hashed), []byte(rc.Code)) == 1 {
+			// only the request that deletes the code may use it
+			affected, err := sess.ID(rc.Id).Delete(&recoveryCode{})
+			if err != nil {
+				return err
+			}
+			if affected != 1 {
+				return ErrInvalidCode
+			}
+			return nil
+		}
+	}
+	return ErrInvalidCode
+}
+
+func getCredential(sess *sqlstore.DBSession, userID int64) (*credential, error) {
+	cred := &credential{}
+	has, err := sess.Where("user_id = ?", userID).Get(cred)
+	if err != nil || !has {
+		return nil, err
+	}
+	return cred, nil
+}
+
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,265 @@
+package twofactor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestService(t *testing.T) (*Service, *sqlstore.SQLStore) {
+	t.Helper()
+
+	store := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.TwoFactorEnabled = true
+	cfg.TwoFactorIssuer = "Grafana"
+
+	s := ProvideService(cfg, store, fakes.NewFakeSecretsService(), remotecache.NewFakeStore(t))
+	now := time.Unix(1234567890, 0)
+	s.now = func() time.Time { return now }
+	return s, store
+}
+
+// enroll enables two-factor authentication for the user and returns the secret
+// and the recovery codes.
+func enroll(t *testing.T, s *Service, user *models.User) (string, []string) {
+	t.Helper()
+
+	enrollment, err := s.StartEnrollment(context.Background(), user)
+	require.NoError(t, err)
+	code, err := generateCode(enrollment.Secret, s.now().Unix()/totpPeriod)
+	require.NoError(t, err)
+	codes, err := s.CompleteEnrollment(context.Background(), user.Id, code)
+	require.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+func TestEnrollment(t *testing.T) {
+	s, store := setupTestService(t)
+	ctx := context.Background()
+	user, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "editor", Email: "editor@example.com"})
+	require.NoError(t, err)
+
+	enrollment, err := s.StartEnrollment(ctx, user)
+	require.NoError(t, err)
+	require.Contains(t, enrollment.URI, "otpauth://totp/Grafana:editor@example.com")
+
+	enrolled, err := s.IsEnrolled(ctx, user.Id)
+	require.NoError(t, err)
+	require.False(t, enrolled, "a pending enrollment does not enable two-factor authentication")
+
+	_, err = s.CompleteEnrollment(ctx, user.Id, "000000")
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	code, err := generateCode(enrollment.Secret, s.now().Unix()/totpPeriod)
+	require.NoError(t, err)
+	codes, err := s.CompleteEnrollment(ctx, user.Id, code)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+
+	status, err := s.GetStatus(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, &Status{Enabled: true, Required: true, RecoveryCodesRemaining: recoveryCodeCount}, status)
+
+	_, err = s.StartEnrollment(ctx, user)
+	require.ErrorIs(t, err, ErrAlreadyEnrolled)
+
+	require.NoError(t, s.Disable(ctx, user.Id))
+	status, err = s.GetStatus(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, &Status{}, status)
+}
+
+func TestVerify(t *testing.T) {
+	s, store := setupTestService(t)
+	ctx := context.Background()
+	user, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "editor"})
+	require.NoError(t, err)
+	secret, codes := enroll(t, s, user)
+
+	t.Run("the enrollment code cannot be replayed", func(t *testing.T) {
+		code, err := generateCode(secret, s.now().Unix()/totpPeriod)
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Verify(ctx, user.Id, code), ErrInvalidCode)
+	})
+
+	t.Run("next code", func(t *testing.T) {
+		code, err := generateCode(secret, s.now().Unix()/totpPeriod+1)
+		require.NoError(t, err)
+		require.NoError(t, s.Verify(ctx, user.Id, code))
+	})
+
+	t.Run("a code used by a concurrent request is rejected", func(t *testing.T) {
+		later := s.now().Add(2 * totpPeriod * time.Second)
+		s.now = func() time.Time { return later }
+		step := later.Unix() / totpPeriod
+		code, err := generateCode(secret, step)
+		require.NoError(t, err)
+
+		results := make(chan error, 5)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(results); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- s.Verify(ctx, user.Id, code)
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		verified := 0
+		for err := range results {
+			if err == nil {
+				verified++
+				continue
+			}
+			require.ErrorIs(t, err, ErrInvalidCode)
+		}
+		require.Equal(t, 1, verified)
+
+		// the credential read by a request before the code was used
+		err = store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			cred, err := getCredential(sess, user.Id)
+			require.NoError(t, err)
+			cred.LastUsedStep = step - 1
+			return s.verifyTOTP(ctx, sess, cred, code)
+		})
+		require.ErrorIs(t, err, ErrInvalidCode)
+	})
+
+	t.Run("recovery codes are single use", func(t *testing.T) {
+		require.NoError(t, s.Verify(ctx, user.Id, codes[0]))
+		require.ErrorIs(t, s.Verify(ctx, user.Id, codes[0]), ErrInvalidCode)
+
+		status, err := s.GetStatus(ctx, user)
+		require.NoError(t, err)
+		require.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+	})
+
+	t.Run("regenerating recovery codes invalidates previous ones", func(t *testing.T) {
+		newCodes, err := s.RegenerateRecoveryCodes(ctx, user.Id)
+		require.NoError(t, err)
+		require.Len(t, newCodes, recoveryCodeCount)
+		require.ErrorIs(t, s.Verify(ctx, user.Id, codes[1]), ErrInvalidCode)
+		require.NoError(t, s.Verify(ctx, user.Id, newCodes[1]))
+	})
+}
+
+func TestChallenge(t *testing.T) {
+	s, store := setupTestService(t)
+	ctx := context.Background()
+	user, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "editor"})
+	require.NoError(t, err)
+	_, codes := enroll(t, s, user)
+
+	t.Run("valid code", func(t *testing.T) {
+		token, err := s.CreateChallenge(ctx, user.Id)
+		require.NoError(t, err)
+
+		userID, _, err := s.CompleteChallenge(ctx, token, codes[0], "127.0.0.1")
+		require.NoError(t, err)
+		require.Equal(t, user.Id, userID)
+
+		_, _, err = s.CompleteChallenge(ctx, token, codes[1], "127.0.0.1")
+		require.ErrorIs(t, err, ErrChallengeNotFound)
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		token, err := s.CreateChallenge(ctx, user.Id)
+		require.NoError(t, err)
+
+		for i := 0; i < challengeMaxAttempts; i++ {
+			_, _, err := s.CompleteChallenge(ctx, token, "000000", "127.0.0.1")
+			require.ErrorIs(t, err, ErrInvalidCode)
+		}
+		_, _, err = s.CompleteChallenge(ctx, token, codes[1], "127.0.0.1")
+		require.ErrorIs(t, err, ErrChallengeNotFound)
+	})
+
+	t.Run("attempts are counted outside of the challenge", func(t *testing.T) {
+		s.cfg.DisableBruteForceLoginProtection = true
+		t.Cleanup(func() { s.cfg.DisableBruteForceLoginProtection = false })
+
+		token, err := s.CreateChallenge(ctx, user.Id)
+		require.NoError(t, err)
+		value, err := s.remoteCache.Get(ctx, challengeKeyPrefix+token)
+		require.NoError(t, err)
+
+		for i := 0; i < challengeMaxAttempts; i++ {
+			_, _, err := s.CompleteChallenge(ctx, token, "000000", "127.0.0.1")
+			require.ErrorIs(t, err, ErrInvalidCode)
+		}
+
+		// A concurrent request that read the challenge before it was removed.
+		require.NoError(t, s.remoteCache.Set(ctx, challengeKeyPrefix+token, value, challengeTTL))
+		_, _, err = s.CompleteChallenge(ctx, token, codes[1], "127.0.0.1")
+		require.ErrorIs(t, err, ErrChallengeNotFound)
+	})
+
+	t.Run("failed codes block the user", func(t *testing.T) {
+		// The failed codes of the previous tests count as failed logins.
+		query := models.GetUserLoginAttemptCountQuery{Username: "editor", Since: time.Now().Add(-time.Minute)}
+		require.NoError(t, sqlstore.GetUserLoginAttemptCount(ctx, &query))
+		require.EqualValues(t, challengeMaxAttempts, query.Result)
+
+		token, err := s.CreateChallenge(ctx, user.Id)
+		require.NoError(t, err)
+		_, _, err = s.CompleteChallenge(ctx, token, codes[1], "127.0.0.1")
+		require.ErrorIs(t, err, ErrTooManyAttempts)
+	})
+
+	t.Run("enrollment during login", func(t *testing.T) {
+		other, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "other"})
+		require.NoError(t, err)
+		enrollment, err := s.StartEnrollment(ctx, other)
+		require.NoError(t, err)
+		token, err := s.CreateChallenge(ctx, other.Id)
+		require.NoError(t, err)
+
+		code, err := generateCode(enrollment.Secret, s.now().Unix()/totpPeriod)
+		require.NoError(t, err)
+		userID, codes, err := s.CompleteChallenge(ctx, token, code, "127.0.0.1")
+		require.NoError(t, err)
+		require.Equal(t, other.Id, userID)
+		require.Len(t, codes, recoveryCodeCount)
+	})
+}
+
+func TestIsRequired(t *testing.T) {
+	s, store := setupTestService(t)
+	ctx := context.Background()
+
+	admin, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "admin", IsAdmin: true})
+	require.NoError(t, err)
+	orgAdmin, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "orgadmin", SkipOrgSetup: true})
+	require.NoError(t, err)
+	require.NoError(t, store.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: admin.OrgId, UserId: orgAdmin.Id, Role: models.ROLE_ADMIN}))
+	viewer, err := store.CreateUser(ctx, models.CreateUserCommand{Login: "viewer", SkipOrgSetup: true})
+	require.NoError(t, err)
+	require.NoError(t, store.AddOrgUser(ctx, &models.AddOrgUserCommand{OrgId: admin.OrgId, UserId: viewer.Id, Role: models.ROLE_VIEWER}))
+
+	for _, user := range []*models.User{admin, orgAdmin, viewer} {
+		required, err := s.IsRequired(ctx, user)
+		require.NoError(t, err)
+		require.False(t, required, user.Login)
+	}
+
+	s.cfg.TwoFactorEnforceForAdmins = true
+	for _, tc := range []struct {
+		user     *models.User
+		required bool
+	}{{admin, true}, {orgAdmin, true}, {viewer, false}} {
+		required, err := s.IsRequired(ctx, tc.user)
+		require.NoError(t, err)
+		require.Equal(t, tc.required, required, tc.user.Login)
+	}
+}
@@ -54,6 +54,12 @@ const (
 	authProxySyncTTL = 60
 )
 
+// Values of login_session_ip_binding.
+const (
+	SessionIPBindingIP     = "ip"
+	SessionIPBindingSubnet = "subnet"
+)
+
 // zoneInfo names environment variable for setting the path to look for the timezone database in go
 const zoneInfo = "ZONEINFO"
 
@@ -238,6 +244,11 @@ type Cfg struct {
 	RendererCallbackUrl            string
 	RendererConcurrentRequestLimit int
 
+	// Dashboard reports
+	DashboardReportsEnabled       bool
+	DashboardReportsMaxAttempts   int
+	DashboardReportsRetryInterval time.Duration
+
 	// Security
 	DisableInitAdminCreation          bool
 	DisableBruteForceLoginProtection  bool
@@ -276,18 +287,30 @@ type Cfg struct {
 	MetricsGrafanaEnvironmentInfo    map[string]string
 
 	// Dashboards
-	DefaultHomeDashboardPath string
+	DefaultHomeDashboardPath   string
+	DeletedDashboardRetention  time.Duration
+	FullTextSearchEnabled      bool
+	FullTextSearchSyncInterval time.Duration
 
 	// Auth
 	LoginCookieName              string
 	LoginMaxInactiveLifetime     time.Duration
 	LoginMaxLifetime             time.Duration
 	TokenRotationIntervalMinutes int
+	LoginIdleTimeout             time.Duration
+	LoginMaxSessionsPerUser      int
+	LoginSessionIPBinding        string
+	LoginSessionGeoIPDatabase    string
 	SigV4AuthEnabled             bool
 	BasicAuthEnabled             bool
 	AdminUser                    string
 	AdminPassword                string
 
+	// Two-factor authentication
+	TwoFactorEnabled          bool
+	TwoFactorEnforceForAdmins bool
+	TwoFactorIssuer           string
+
 	// AWS Plugin Auth
 	AWSAllowedAuthProviders []string
 	AWSAssumeRoleEnabled    bool
@@ -334,6 +357,10 @@ type Cfg struct {
 	DataProxyIdleConnTimeout       int
 	ResponseLimit                  int64
 	DataProxyRowLimit              int64
+	DataProxyByteLimit             int64
+
+	// SQLite data source
+	SQLiteDatasourceAllowedPaths []string
 
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
@@ -371,6 +398,9 @@ type Cfg struct {
 
 	// Snapshots
 	SnapshotPublicMode bool
+	// SnapshotBlobStorageProvider is the external image storage provider snapshot dashboards are stored in,
+	// they are stored in the database when empty.
+	SnapshotBlobStorageProvider string
 
 	ErrTemplateName string
 
@@ -886,6 +916,8 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		return err
 	}
 
+	readSQLiteDatasourceSettings(iniFile, cfg)
+
 	if err := readSecuritySettings(iniFile, cfg); err != nil {
 		return err
 	}
@@ -900,6 +932,13 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	deletedRetention, err := gtime.ParseDuration(valueAsString(dashboards, "deleted_dashboards_retention", "30d"))
+	if err != nil {
+		return err
+	}
+	cfg.DeletedDashboardRetention = deletedRetention
+	cfg.FullTextSearchEnabled = dashboards.Key("full_text_search_enabled").MustBool(false)
+	cfg.FullTextSearchSyncInterval = dashboards.Key("full_text_search_sync_interval").MustDuration(5 * time.Minute)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -910,6 +949,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	if err := cfg.readRenderingSettings(iniFile); err != nil {
 		return err
 	}
+	cfg.readDashboardReportsSettings(iniFile)
 
 	cfg.TempDataLifetime = iniFile.Section("paths").Key("temp_data_lifetime").MustDuration(time.Second * 3600 * 24)
 	cfg.MetricsEndpointEnabled = iniFile.Section("metrics").Key("enabled").MustBool(true)
@@ -1242,6 +1282,22 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
 
+	if idleTimeout := valueAsString(auth, "login_idle_timeout", ""); idleTimeout != "" {
+		cfg.LoginIdleTimeout, err = gtime.ParseDuration(idleTimeout)
+		if err != nil {
+			return err
+		}
+	}
+	cfg.LoginMaxSessionsPerUser = auth.Key("login_maximum_sessions_per_user").MustInt(0)
+	cfg.LoginSessionIPBinding = valueAsString(auth, "login_session_ip_binding", "")
+	switch cfg.LoginSessionIPBinding {
+	case "", SessionIPBindingIP, SessionIPBindingSubnet:
+	default:
+		return fmt.Errorf("invalid login_session_ip_binding %q, expected %q or %q", cfg.LoginSessionIPBinding,
+			SessionIPBindingIP, SessionIPBindingSubnet)
+	}
+	cfg.LoginSessionGeoIPDatabase = valueAsString(auth, "login_session_geoip_database", "")
+
 	DisableLoginForm = auth.Key("disable_login_form").MustBool(false)
 	DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
 	OAuthAutoLogin = auth.Key("oauth_auto_login").MustBool(false)
@@ -1252,6 +1308,12 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	SigV4AuthEnabled = auth.Key("sigv4_auth_enabled").MustBool(false)
 	cfg.SigV4AuthEnabled = SigV4AuthEnabled
 
+	// two-factor authentication
+	authTwoFactor := iniFile.Section("auth.two_factor")
+	cfg.TwoFactorEnabled = authTwoFactor.Key("enabled").MustBool(false)
+	cfg.TwoFactorEnforceForAdmins = authTwoFactor.Key("enforce_for_admins").MustBool(false)
+	cfg.TwoFactorIssuer = valueAsString(authTwoFactor, "issuer", "Grafana")
+
 	// anonymous access
 	AnonymousEnabled = iniFile.Section("auth.anonymous").Key("enabled").MustBool(false)
 	cfg.AnonymousEnabled = AnonymousEnabled
@@ -1384,6 +1446,16 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	return nil
 }
 
+func (cfg *Cfg) readDashboardReportsSettings(iniFile *ini.File) {
+	reporting := iniFile.Section("reporting")
+	cfg.DashboardReportsEnabled = reporting.Key("enabled").MustBool(true)
+	cfg.DashboardReportsMaxAttempts = reporting.Key("max_attempts").MustInt(3)
+	if cfg.DashboardReportsMaxAttempts < 1 {
+		cfg.DashboardReportsMaxAttempts = 1
+	}
+	cfg.DashboardReportsRetryInterval = reporting.Key("retry_interval").MustDuration(5 * time.Minute)
+}
+
 func readAlertingSettings(iniFile *ini.File) error {
 	alerting := iniFile.Section("alerting")
 	enabled, err := alerting.Key("enabled").Bool()
@@ -1416,6 +1488,7 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	SnapShotRemoveExpired = snapshots.Key("snapshot_remove_expired").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.SnapshotBlobStorageProvider = valueAsString(snapshots, "blob_storage_provider", "")
 
 	return nil
 }
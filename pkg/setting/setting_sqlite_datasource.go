@@ -0,0 +1,17 @@
+package setting
+
+import (
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/util"
+	"gopkg.in/ini.v1"
+)
+
+func readSQLiteDatasourceSettings(iniFile *ini.File, cfg *Cfg) {
+	section := iniFile.Section("sqlite_datasource")
+
+	cfg.SQLiteDatasourceAllowedPaths = nil
+	for _, path := range util.SplitString(section.Key("allowed_paths").String()) {
+		cfg.SQLiteDatasourceAllowedPaths = append(cfg.SQLiteDatasourceAllowedPaths, filepath.Clean(makeAbsolute(path, HomePath)))
+	}
+}
@@ -29,7 +29,12 @@ func (e *cloudWatchExecutor) executeAnnotationQuery(ctx context.Context, model *
 	actionPrefix := model.Get("actionPrefix").MustString("")
 	alarmNamePrefix := model.Get("alarmNamePrefix").MustString("")
 
-	cli, err := e.getCWClient(region, pluginCtx)
+	dsInfo, err := e.getDSInfo(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := e.getCWClient(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,340 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	apiCallMade      = "made"
+	apiCallThrottled = "throttled"
+	apiCallCached    = "cached"
+)
+
+// maxAPICallWait is how long an API call waits for the budget of the data source before it is throttled.
+const maxAPICallWait = 5 * time.Second
+
+// defaultAPICallsBurst is the number of API calls a data source with a budget can make at once.
+const defaultAPICallsBurst = 10
+
+// maxCachedResponses is the number of responses the cache of a data source holds at most.
+const maxCachedResponses = 1000
+
+var errAPICallBudgetExceeded = errors.New("the AWS API call budget of the data source is exceeded, try again later")
+
+var apiCallsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "aws_cloudwatch_api_calls_total",
+		Help:      "counter for AWS API calls of CloudWatch data sources, by whether they were made, throttled by the budget of the data source or by AWS, or answered from its cache",
+	},
+	[]string{"datasource", "api", "result"},
+)
+
+func init() {
+	prometheus.MustRegister(apiCallsCounter)
+}
+
+// apiCalls holds the API call budget and the response cache of a data source, which are shared by the clients
+// of all its regions. A nil apiCalls makes every call without a budget or cache.
+type apiCalls struct {
+	// datasourceUID labels the API call metrics of the data source. It is empty for data sources without a
+	// budget or cache, which are counted together, so that only those data sources add series to the metrics.
+	datasourceUID  string
+	limiter        *rate.Limiter
+	listMetricsTTL time.Duration
+	metricDataTTL  time.Duration
+	cache          responseCache
+}
+
+// newAPICalls returns the API calls of the data source with the given UID. A budget of zero calls per second is no budget, and
+// a zero TTL disables the cache of the responses.
+func newAPICalls(datasourceUID string, callsPerSecond float64, burst int, listMetricsTTL, metricDataTTL time.Duration) *apiCalls {
+	calls := &apiCalls{
+		listMetricsTTL: listMetricsTTL,
+		metricDataTTL:  metricDataTTL,
+		cache:          responseCache{entries: make(map[string]cacheEntry)},
+	}
+	if callsPerSecond > 0 || listMetricsTTL > 0 || metricDataTTL > 0 {
+		calls.datasourceUID = datasourceUID
+	}
+	if callsPerSecond > 0 {
+		if burst <= 0 {
+			burst = defaultAPICallsBurst
+		}
+		calls.limiter = rate.NewLimiter(rate.Limit(callsPerSecond), burst)
+	}
+	return calls
+}
+
+// wait waits until the budget allows the API call. The call is throttled if that takes longer than maxAPICallWait.
+func (c *apiCalls) wait(ctx context.Context, api string) error {
+	if c.limiter != nil {
+		reservation := c.limiter.Reserve()
+		if !reservation.OK() || reservation.Delay() > maxAPICallWait {
+			reservation.Cancel()
+			c.count(api, apiCallThrottled)
+			return errAPICallBudgetExceeded
+		}
+
+		if delay := reservation.Delay(); delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				reservation.Cancel()
+				return ctx.Err()
+			}
+		}
+	}
+
+	c.count(api, apiCallMade)
+	return nil
+}
+
+func (c *apiCalls) count(api, result string) {
+	apiCallsCounter.WithLabelValues(c.datasourceUID, api, result).Inc()
+}
+
+// done counts a call that was made as throttled if AWS rejected it for exceeding the rate limit of the account,
+// and returns its error.
+func (c *apiCalls) done(api string, err error) error {
+	if err != nil && request.IsErrorThrottle(err) {
+		c.count(api, apiCallThrottled)
+	}
+	return err
+}
+
+type withoutCacheKey struct{}
+
+// withoutCache returns a context whose GetMetricData calls are neither answered from nor stored in the cache.
+func withoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutCacheKey{}, true)
+}
+
+// cloudWatchClient returns the client with the budget and cache of the data source.
+func (c *apiCalls) cloudWatchClient(client cloudwatchiface.CloudWatchAPI, region string) cloudwatchiface.CloudWatchAPI {
+	if c == nil {
+		return client
+	}
+	return &budgetedCWClient{CloudWatchAPI: client, calls: c, region: region}
+}
+
+// logsClient returns the client with the budget of the data source.
+func (c *apiCalls) logsClient(client cloudwatchlogsiface.CloudWatchLogsAPI) cloudwatchlogsiface.CloudWatchLogsAPI {
+	if c == nil {
+		return client
+	}
+	return &budgetedCWLogsClient{CloudWatchLogsAPI: client, calls: c}
+}
+
+// cacheKey returns the key of the cached response of an API call, which is empty if the call is not cached.
+func cacheKey(api, region string, input interface{}) string {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return ""
+	}
+	return api + "/" + region + "/" + string(b)
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// responseCache is a cache of the responses of a data source.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func (c *responseCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *responseCache) set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedResponses {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCachedResponses {
+			return
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expires: time.Now().Add(ttl)}
+}
+
+// budgetedCWClient is a CloudWatch client that applies the budget of the data source to its calls and
+// caches the responses of GetMetricData and ListMetrics.
+type budgetedCWClient struct {
+	cloudwatchiface.CloudWatchAPI
+	calls  *apiCalls
+	region string
+}
+
+func (c *budgetedCWClient) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput,
+	opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
+	var key string
+	if c.calls.metricDataTTL > 0 && ctx.Value(withoutCacheKey{}) == nil {
+		key = cacheKey("GetMetricData", c.region, input)
+		if output, ok := c.calls.cache.get(key); ok {
+			c.calls.count("GetMetricData", apiCallCached)
+			return output.(*cloudwatch.GetMetricDataOutput), nil
+		}
+	}
+
+	if err := c.calls.wait(ctx, "GetMetricData"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchAPI.GetMetricDataWithContext(ctx, input, opts...)
+	if err != nil {
+		return nil, c.calls.done("GetMetricData", err)
+	}
+	metrics.MAwsCloudWatchGetMetricData.Add(float64(len(input.MetricDataQueries)))
+
+	if key != "" {
+		c.calls.cache.set(key, output, c.calls.metricDataTTL)
+	}
+	return output, nil
+}
+
+type listMetricsPage struct {
+	output   *cloudwatch.ListMetricsOutput
+	lastPage bool
+}
+
+func (c *budgetedCWClient) ListMetricsPages(input *cloudwatch.ListMetricsInput,
+	fn func(*cloudwatch.ListMetricsOutput, bool) bool) error {
+	var key string
+	if c.calls.listMetricsTTL > 0 {
+		key = cacheKey("ListMetrics", c.region, input)
+		if pages, ok := c.calls.cache.get(key); ok {
+			for _, page := range pages.([]listMetricsPage) {
+				c.calls.count("ListMetrics", apiCallCached)
+				if !fn(page.output, page.lastPage) {
+					break
+				}
+			}
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	if err := c.calls.wait(ctx, "ListMetrics"); err != nil {
+		return err
+	}
+
+	// every page is a call, so the budget is applied before the next page is requested
+	var pages []listMetricsPage
+	var budgetErr error
+	complete := false
+	err := c.CloudWatchAPI.ListMetricsPages(input, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		metrics.MAwsCloudWatchListMetrics.Inc()
+		pages = append(pages, listMetricsPage{output: page, lastPage: lastPage})
+		complete = lastPage
+		if !fn(page, lastPage) || lastPage {
+			return false
+		}
+		budgetErr = c.calls.wait(ctx, "ListMetrics")
+		return budgetErr == nil
+	})
+	if err != nil {
+		return c.calls.done("ListMetrics", err)
+	}
+	if budgetErr != nil {
+		return budgetErr
+	}
+
+	// the pages are only cached if the caller did not stop before the last one, so that the cache holds all of them
+	if key != "" && complete {
+		c.calls.cache.set(key, pages, c.calls.listMetricsTTL)
+	}
+	return nil
+}
+
+// budgetedCWLogsClient is a CloudWatch Logs client that applies the budget of the data source to its calls.
+type budgetedCWLogsClient struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	calls *apiCalls
+}
+
+func (c *budgetedCWLogsClient) StartQueryWithContext(ctx aws.Context, input *cloudwatchlogs.StartQueryInput,
+	opts ...request.Option) (*cloudwatchlogs.StartQueryOutput, error) {
+	if err := c.calls.wait(ctx, "StartQuery"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchLogsAPI.StartQueryWithContext(ctx, input, opts...)
+	return output, c.calls.done("StartQuery", err)
+}
+
+func (c *budgetedCWLogsClient) StopQueryWithContext(ctx aws.Context, input *cloudwatchlogs.StopQueryInput,
+	opts ...request.Option) (*cloudwatchlogs.StopQueryOutput, error) {
+	if err := c.calls.wait(ctx, "StopQuery"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchLogsAPI.StopQueryWithContext(ctx, input, opts...)
+	return output, c.calls.done("StopQuery", err)
+}
+
+func (c *budgetedCWLogsClient) GetQueryResultsWithContext(ctx aws.Context, input *cloudwatchlogs.GetQueryResultsInput,
+	opts ...request.Option) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	if err := c.calls.wait(ctx, "GetQueryResults"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchLogsAPI.GetQueryResultsWithContext(ctx, input, opts...)
+	return output, c.calls.done("GetQueryResults", err)
+}
+
+func (c *budgetedCWLogsClient) GetLogEventsWithContext(ctx aws.Context, input *cloudwatchlogs.GetLogEventsInput,
+	opts ...request.Option) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	if err := c.calls.wait(ctx, "GetLogEvents"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchLogsAPI.GetLogEventsWithContext(ctx, input, opts...)
+	return output, c.calls.done("GetLogEvents", err)
+}
+
+func (c *budgetedCWLogsClient) DescribeLogGroupsWithContext(ctx aws.Context, input *cloudwatchlogs.DescribeLogGroupsInput,
+	opts ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	if err := c.calls.wait(ctx, "DescribeLogGroups"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchLogsAPI.DescribeLogGroupsWithContext(ctx, input, opts...)
+	return output, c.calls.done("DescribeLogGroups", err)
+}
+
+func (c *budgetedCWLogsClient) GetLogGroupFieldsWithContext(ctx aws.Context, input *cloudwatchlogs.GetLogGroupFieldsInput,
+	opts ...request.Option) (*cloudwatchlogs.GetLogGroupFieldsOutput, error) {
+	if err := c.calls.wait(ctx, "GetLogGroupFields"); err != nil {
+		return nil, err
+	}
+	output, err := c.CloudWatchLogsAPI.GetLogGroupFieldsWithContext(ctx, input, opts...)
+	return output, c.calls.done("GetLogGroupFields", err)
+}
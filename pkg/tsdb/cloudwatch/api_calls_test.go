@@ -0,0 +1,360 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCWClient struct {
+	FakeCWClient
+
+	metricDataInputs []*cloudwatch.GetMetricDataInput
+	listMetricsCalls int
+	err              error
+}
+
+func (c *countingCWClient) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput,
+	opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
+	c.metricDataInputs = append(c.metricDataInputs, input)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.FakeCWClient.GetMetricDataWithContext(ctx, input, opts...)
+}
+
+func (c *countingCWClient) ListMetricsPages(input *cloudwatch.ListMetricsInput, fn func(*cloudwatch.ListMetricsOutput, bool) bool) error {
+	return c.FakeCWClient.ListMetricsPages(input, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		c.listMetricsCalls++
+		return fn(page, lastPage)
+	})
+}
+
+func apiCallsCount(datasource, api, result string) float64 {
+	return testutil.ToFloat64(apiCallsCounter.WithLabelValues(datasource, api, result))
+}
+
+func TestAPICalls(t *testing.T) {
+	metricDataInput := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(time.Unix(0, 0)),
+		EndTime:   aws.Time(time.Unix(3600, 0)),
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{
+			{Id: aws.String("a"), Expression: aws.String("SEARCH('{AWS/EC2}', 'Average', 300)")},
+		},
+	}
+
+	t.Run("GetMetricData responses are cached by input and region", func(t *testing.T) {
+		calls := newAPICalls("metric-data-cache", 0, 0, 0, time.Minute)
+		fake := &countingCWClient{}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+			require.NoError(t, err)
+		}
+		_, err := calls.cloudWatchClient(fake, "eu-west-1").GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.NoError(t, err)
+
+		assert.Len(t, fake.metricDataInputs, 2)
+		assert.Equal(t, float64(2), apiCallsCount("metric-data-cache", "GetMetricData", apiCallMade))
+		assert.Equal(t, float64(2), apiCallsCount("metric-data-cache", "GetMetricData", apiCallCached))
+	})
+
+	t.Run("GetMetricData responses are not cached without a TTL", func(t *testing.T) {
+		calls := newAPICalls("metric-data-no-cache", 0, 0, 0, 0)
+		fake := &countingCWClient{}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+			require.NoError(t, err)
+		}
+
+		assert.Len(t, fake.metricDataInputs, 3)
+		assert.Equal(t, float64(0), apiCallsCount("metric-data-no-cache", "GetMetricData", apiCallCached))
+	})
+
+	t.Run("data sources without a budget or cache are not labelled with their UID", func(t *testing.T) {
+		calls := newAPICalls("no-budget-or-cache", 0, 0, 0, 0)
+		client := calls.cloudWatchClient(&countingCWClient{}, "us-east-1")
+		made := apiCallsCount("", "GetMetricData", apiCallMade)
+
+		_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.NoError(t, err)
+
+		assert.Equal(t, made+1, apiCallsCount("", "GetMetricData", apiCallMade))
+		assert.Equal(t, float64(0), apiCallsCount("no-budget-or-cache", "GetMetricData", apiCallMade))
+	})
+
+	t.Run("ListMetrics pages are cached and replayed", func(t *testing.T) {
+		calls := newAPICalls("list-metrics-cache", 0, 0, time.Minute, 0)
+		fake := &countingCWClient{FakeCWClient: FakeCWClient{
+			Metrics:        []*cloudwatch.Metric{{MetricName: aws.String("a")}, {MetricName: aws.String("b")}, {MetricName: aws.String("c")}},
+			MetricsPerPage: 1,
+		}}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+		input := &cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/EC2")}
+
+		var names [2][]string
+		for i := range names {
+			err := client.ListMetricsPages(input, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+				for _, metric := range page.Metrics {
+					names[i] = append(names[i], *metric.MetricName)
+				}
+				return !lastPage
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, names[0])
+		assert.Equal(t, names[0], names[1])
+		assert.Equal(t, 3, fake.listMetricsCalls)
+		assert.Equal(t, float64(3), apiCallsCount("list-metrics-cache", "ListMetrics", apiCallMade))
+		assert.Equal(t, float64(3), apiCallsCount("list-metrics-cache", "ListMetrics", apiCallCached))
+	})
+
+	t.Run("ListMetrics pages are only cached when all of them were fetched", func(t *testing.T) {
+		calls := newAPICalls("list-metrics-partial", 0, 0, time.Minute, 0)
+		fake := &countingCWClient{FakeCWClient: FakeCWClient{
+			Metrics:        []*cloudwatch.Metric{{MetricName: aws.String("a")}, {MetricName: aws.String("b")}, {MetricName: aws.String("c")}},
+			MetricsPerPage: 1,
+		}}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+		input := &cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/EC2")}
+
+		listMetrics := func(pageLimit int) []string {
+			t.Helper()
+			var names []string
+			pages := 0
+			err := client.ListMetricsPages(input, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+				for _, metric := range page.Metrics {
+					names = append(names, *metric.MetricName)
+				}
+				pages++
+				return !lastPage && pages < pageLimit
+			})
+			require.NoError(t, err)
+			return names
+		}
+
+		assert.Equal(t, []string{"a"}, listMetrics(1))
+		assert.Equal(t, []string{"a", "b", "c"}, listMetrics(10))
+		assert.Equal(t, []string{"a", "b", "c"}, listMetrics(10))
+		assert.Equal(t, 4, fake.listMetricsCalls)
+		assert.Equal(t, float64(3), apiCallsCount("list-metrics-partial", "ListMetrics", apiCallCached))
+	})
+
+	t.Run("calls throttled by AWS are counted", func(t *testing.T) {
+		calls := newAPICalls("aws-throttling", 100, 0, 0, 0)
+		fake := &countingCWClient{err: awserr.New("ThrottlingException", "Rate exceeded", nil)}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+
+		_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.Error(t, err)
+
+		fake.err = awserr.New("ValidationError", "invalid query", nil)
+		_, err = client.GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.Error(t, err)
+
+		assert.Equal(t, float64(2), apiCallsCount("aws-throttling", "GetMetricData", apiCallMade))
+		assert.Equal(t, float64(1), apiCallsCount("aws-throttling", "GetMetricData", apiCallThrottled))
+	})
+
+	t.Run("calls over the budget are throttled", func(t *testing.T) {
+		calls := newAPICalls("budget", 0.1, 1, 0, 0)
+		fake := &countingCWClient{}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+
+		_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.NoError(t, err)
+		_, err = client.GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.ErrorIs(t, err, errAPICallBudgetExceeded)
+
+		// the budget is shared by the clients of all regions and the logs client
+		_, err = calls.cloudWatchClient(fake, "eu-west-1").GetMetricDataWithContext(context.Background(), metricDataInput)
+		require.ErrorIs(t, err, errAPICallBudgetExceeded)
+		_, err = calls.logsClient(FakeCWLogsClient{}).StartQueryWithContext(context.Background(), &cloudwatchlogs.StartQueryInput{})
+		require.ErrorIs(t, err, errAPICallBudgetExceeded)
+
+		assert.Len(t, fake.metricDataInputs, 1)
+		assert.Equal(t, float64(1), apiCallsCount("budget", "GetMetricData", apiCallMade))
+		assert.Equal(t, float64(2), apiCallsCount("budget", "GetMetricData", apiCallThrottled))
+		assert.Equal(t, float64(1), apiCallsCount("budget", "StartQuery", apiCallThrottled))
+	})
+
+	t.Run("calls within the budget wait for it", func(t *testing.T) {
+		calls := newAPICalls("budget-wait", 20, 1, 0, 0)
+		fake := &countingCWClient{}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+			require.NoError(t, err)
+		}
+
+		assert.Len(t, fake.metricDataInputs, 3)
+		assert.Equal(t, float64(0), apiCallsCount("budget-wait", "GetMetricData", apiCallThrottled))
+	})
+
+	t.Run("cached responses are not charged to the budget", func(t *testing.T) {
+		calls := newAPICalls("budget-cache", 0.1, 1, 0, time.Minute)
+		fake := &countingCWClient{}
+		client := calls.cloudWatchClient(fake, "us-east-1")
+
+		for i := 0; i < 3; i++ {
+			_, err := client.GetMetricDataWithContext(context.Background(), metricDataInput)
+			require.NoError(t, err)
+		}
+
+		assert.Len(t, fake.metricDataInputs, 1)
+		assert.Equal(t, float64(0), apiCallsCount("budget-cache", "GetMetricData", apiCallThrottled))
+	})
+
+	t.Run("a nil apiCalls returns the clients", func(t *testing.T) {
+		var calls *apiCalls
+		fake := &countingCWClient{}
+		assert.Same(t, fake, calls.cloudWatchClient(fake, "us-east-1"))
+	})
+}
+
+func TestAPICallsSettings(t *testing.T) {
+	f := NewInstanceSettings(httpclient.NewProvider())
+
+	t.Run("API call settings are read from the JSON data", func(t *testing.T) {
+		model, err := f(backend.DataSourceInstanceSettings{
+			UID:  "cw-uid",
+			Name: "cloudwatch",
+			JSONData: []byte(`{"listMetricsCacheTTL": "1h", "metricDataCacheTTL": "5m",
+				"apiCallsPerSecond": 2.5, "apiCallsBurst": 5}`),
+		})
+		require.NoError(t, err)
+
+		calls := model.(datasourceInfo).apiCalls
+		require.NotNil(t, calls)
+		assert.Equal(t, "cw-uid", calls.datasourceUID)
+		assert.Equal(t, time.Hour, calls.listMetricsTTL)
+		assert.Equal(t, 5*time.Minute, calls.metricDataTTL)
+		require.NotNil(t, calls.limiter)
+		assert.Equal(t, 2.5, float64(calls.limiter.Limit()))
+		assert.Equal(t, 5, calls.limiter.Burst())
+	})
+
+	t.Run("there is no budget or cache by default", func(t *testing.T) {
+		model, err := f(backend.DataSourceInstanceSettings{JSONData: []byte(`{}`)})
+		require.NoError(t, err)
+
+		calls := model.(datasourceInfo).apiCalls
+		assert.Empty(t, calls.datasourceUID)
+		assert.Nil(t, calls.limiter)
+		assert.Zero(t, calls.listMetricsTTL)
+		assert.Zero(t, calls.metricDataTTL)
+	})
+
+	t.Run("the burst defaults to 10 calls", func(t *testing.T) {
+		model, err := f(backend.DataSourceInstanceSettings{JSONData: []byte(`{"apiCallsPerSecond": 1}`)})
+		require.NoError(t, err)
+		assert.Equal(t, defaultAPICallsBurst, model.(datasourceInfo).apiCalls.limiter.Burst())
+	})
+
+	t.Run("an invalid TTL is an error", func(t *testing.T) {
+		_, err := f(backend.DataSourceInstanceSettings{JSONData: []byte(`{"metricDataCacheTTL": "five minutes"}`)})
+		require.Error(t, err)
+	})
+}
+
+func TestAlignToPeriod(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 2, 10, 0, time.UTC)
+	end := time.Date(2021, 1, 1, 1, 7, 40, 0, time.UTC)
+
+	t.Run("the time range is aligned to the smallest period", func(t *testing.T) {
+		alignedStart, alignedEnd := alignToPeriod(start, end, []*cloudWatchQuery{{Period: 300}, {Period: 60}})
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 2, 0, 0, time.UTC), alignedStart)
+		assert.Equal(t, time.Date(2021, 1, 1, 1, 7, 0, 0, time.UTC), alignedEnd)
+	})
+
+	t.Run("a time range shorter than the period is kept", func(t *testing.T) {
+		alignedStart, alignedEnd := alignToPeriod(start, start.Add(time.Minute), []*cloudWatchQuery{{Period: 300}})
+		assert.Equal(t, start, alignedStart)
+		assert.Equal(t, start.Add(time.Minute), alignedEnd)
+	})
+}
+
+func TestTimeSeriesQueryCache(t *testing.T) {
+	origNewCWClient := NewCWClient
+	t.Cleanup(func() {
+		NewCWClient = origNewCWClient
+	})
+
+	fake := &countingCWClient{}
+	NewCWClient = func(sess *session.Session) cloudwatchiface.CloudWatchAPI {
+		return fake
+	}
+
+	calls := newAPICalls("time-series-cache", 0, 0, 0, time.Minute)
+	im := datasource.NewInstanceManager(func(s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return datasourceInfo{apiCalls: calls}, nil
+	})
+	executor := newExecutor(im, newTestConfig(), fakeSessionCache{})
+
+	query := func(from time.Time) {
+		t.Helper()
+		_, err := executor.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
+			},
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+					JSON: json.RawMessage(`{
+						"type":      "timeSeriesQuery",
+						"subtype":   "metrics",
+						"namespace": "AWS/EC2",
+						"metricName": "NetworkOut",
+						"dimensions": {"InstanceId": "i-00645d91ed77d87ac"},
+						"region": "us-east-2",
+						"id": "a",
+						"statistics": ["Maximum"],
+						"period": "300",
+						"matchExact": true,
+						"refId": "A"
+					}`),
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// refreshes of a relative time range within a period are answered from the cache,
+	// except for the latest, partial period
+	from := time.Date(2021, 1, 1, 0, 0, 10, 0, time.UTC)
+	query(from)
+	query(from.Add(time.Minute))
+	require.Len(t, fake.metricDataInputs, 3)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), fake.metricDataInputs[0].StartTime.UTC())
+	assert.Equal(t, time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC), fake.metricDataInputs[0].EndTime.UTC())
+	for i, end := range []time.Time{from.Add(time.Hour), from.Add(time.Hour + time.Minute)} {
+		partial := fake.metricDataInputs[i+1]
+		assert.Equal(t, time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC), partial.StartTime.UTC())
+		assert.Equal(t, end, partial.EndTime.UTC())
+	}
+
+	query(from.Add(5 * time.Minute))
+	require.Len(t, fake.metricDataInputs, 5)
+}
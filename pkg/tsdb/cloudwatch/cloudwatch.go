@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -43,6 +44,10 @@ type datasourceInfo struct {
 	secretKey string
 
 	datasourceID int64
+	name         string
+
+	// apiCalls holds the API call budget and the response cache of the data source.
+	apiCalls *apiCalls
 
 	HTTPClient *http.Client
 }
@@ -95,6 +100,11 @@ func NewInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			Endpoint      string `json:"endpoint"`
 			Namespace     string `json:"customMetricsNamespaces"`
 			AuthType      string `json:"authType"`
+
+			ListMetricsCacheTTL string  `json:"listMetricsCacheTTL"`
+			MetricDataCacheTTL  string  `json:"metricDataCacheTTL"`
+			APICallsPerSecond   float64 `json:"apiCallsPerSecond"`
+			APICallsBurst       int     `json:"apiCallsBurst"`
 		}{}
 
 		err := json.Unmarshal(settings.JSONData, &jsonData)
@@ -107,6 +117,15 @@ func NewInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, fmt.Errorf("error creating http client: %w", err)
 		}
 
+		listMetricsTTL, err := parseCacheTTL(jsonData.ListMetricsCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: invalid list metrics cache TTL: %w", err)
+		}
+		metricDataTTL, err := parseCacheTTL(jsonData.MetricDataCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: invalid metric data cache TTL: %w", err)
+		}
+
 		model := datasourceInfo{
 			profile:       jsonData.Profile,
 			region:        jsonData.Region,
@@ -115,7 +134,10 @@ func NewInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			endpoint:      jsonData.Endpoint,
 			namespace:     jsonData.Namespace,
 			datasourceID:  settings.ID,
-			HTTPClient:    httpClient,
+			name:          settings.Name,
+			apiCalls: newAPICalls(settings.UID, jsonData.APICallsPerSecond, jsonData.APICallsBurst,
+				listMetricsTTL, metricDataTTL),
+			HTTPClient: httpClient,
 		}
 
 		at := awsds.AuthTypeDefault
@@ -155,12 +177,7 @@ type cloudWatchExecutor struct {
 	sessions SessionCache
 }
 
-func (e *cloudWatchExecutor) newSession(region string, pluginCtx backend.PluginContext) (*session.Session, error) {
-	dsInfo, err := e.getDSInfo(pluginCtx)
-	if err != nil {
-		return nil, err
-	}
-
+func (e *cloudWatchExecutor) newSession(region string, dsInfo *datasourceInfo) (*session.Session, error) {
 	if region == defaultRegion {
 		region = dsInfo.region
 	}
@@ -182,27 +199,32 @@ func (e *cloudWatchExecutor) newSession(region string, pluginCtx backend.PluginC
 	})
 }
 
-func (e *cloudWatchExecutor) getCWClient(region string, pluginCtx backend.PluginContext) (cloudwatchiface.CloudWatchAPI, error) {
-	sess, err := e.newSession(region, pluginCtx)
+func (e *cloudWatchExecutor) getCWClient(region string, dsInfo *datasourceInfo) (cloudwatchiface.CloudWatchAPI, error) {
+	sess, err := e.newSession(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
-	return NewCWClient(sess), nil
+	return dsInfo.apiCalls.cloudWatchClient(NewCWClient(sess), region), nil
 }
 
-func (e *cloudWatchExecutor) getCWLogsClient(region string, pluginCtx backend.PluginContext) (cloudwatchlogsiface.CloudWatchLogsAPI, error) {
-	sess, err := e.newSession(region, pluginCtx)
+func (e *cloudWatchExecutor) getCWLogsClient(region string, dsInfo *datasourceInfo) (cloudwatchlogsiface.CloudWatchLogsAPI, error) {
+	sess, err := e.newSession(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	logsClient := NewCWLogsClient(sess)
+	logsClient := dsInfo.apiCalls.logsClient(NewCWLogsClient(sess))
 
 	return logsClient, nil
 }
 
 func (e *cloudWatchExecutor) getEC2Client(region string, pluginCtx backend.PluginContext) (ec2iface.EC2API, error) {
-	sess, err := e.newSession(region, pluginCtx)
+	dsInfo, err := e.getDSInfo(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := e.newSession(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -212,7 +234,12 @@ func (e *cloudWatchExecutor) getEC2Client(region string, pluginCtx backend.Plugi
 
 func (e *cloudWatchExecutor) getRGTAClient(region string, pluginCtx backend.PluginContext) (resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI,
 	error) {
-	sess, err := e.newSession(region, pluginCtx)
+	dsInfo, err := e.getDSInfo(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := e.newSession(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -299,6 +326,11 @@ func (e *cloudWatchExecutor) QueryData(ctx context.Context, req *backend.QueryDa
 func (e *cloudWatchExecutor) executeLogAlertQuery(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
+	dsInfo, err := e.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, q := range req.Queries {
 		model, err := simplejson.NewJson(q.JSON)
 		if err != nil {
@@ -310,14 +342,10 @@ func (e *cloudWatchExecutor) executeLogAlertQuery(ctx context.Context, req *back
 
 		region := model.Get("region").MustString(defaultRegion)
 		if region == defaultRegion {
-			dsInfo, err := e.getDSInfo(req.PluginContext)
-			if err != nil {
-				return nil, err
-			}
 			model.Set("region", dsInfo.region)
 		}
 
-		logsClient, err := e.getCWLogsClient(region, req.PluginContext)
+		logsClient, err := e.getCWLogsClient(region, dsInfo)
 		if err != nil {
 			return nil, err
 		}
@@ -370,6 +398,21 @@ func (e *cloudWatchExecutor) getDSInfo(pluginCtx backend.PluginContext) (*dataso
 	return &instance, nil
 }
 
+// parseCacheTTL parses the TTL of cached responses, such as 5m. An empty TTL disables the cache.
+func parseCacheTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	d, err := gtime.ParseDuration(ttl)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", ttl)
+	}
+	return d, nil
+}
+
 func isTerminated(queryStatus string) bool {
 	return queryStatus == "Complete" || queryStatus == "Cancelled" || queryStatus == "Failed" || queryStatus == "Timeout"
 }
@@ -6,7 +6,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
-	"github.com/grafana/grafana/pkg/infra/metrics"
 )
 
 func (e *cloudWatchExecutor) executeRequest(ctx context.Context, client cloudwatchiface.CloudWatchAPI,
@@ -24,7 +23,6 @@ func (e *cloudWatchExecutor) executeRequest(ctx context.Context, client cloudwat
 		}
 
 		mdo = append(mdo, resp)
-		metrics.MAwsCloudWatchGetMetricData.Add(float64(len(metricDataInput.MetricDataQueries)))
 
 		if resp.NextToken == nil || *resp.NextToken == "" {
 			break
@@ -95,7 +95,7 @@ func (e *cloudWatchExecutor) executeLogAction(ctx context.Context, model *simple
 	defaultRegion := dsInfo.region
 
 	region := model.Get("region").MustString(defaultRegion)
-	logsClient, err := e.getCWLogsClient(region, pluginCtx)
+	logsClient, err := e.getCWLogsClient(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
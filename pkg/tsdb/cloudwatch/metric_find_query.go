@@ -18,7 +18,6 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
-	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -520,7 +519,12 @@ func (e *cloudWatchExecutor) handleGetResourceArns(ctx context.Context, paramete
 }
 
 func (e *cloudWatchExecutor) listMetrics(region string, params *cloudwatch.ListMetricsInput, pluginCtx backend.PluginContext) ([]*cloudwatch.Metric, error) {
-	client, err := e.getCWClient(region, pluginCtx)
+	dsInfo, err := e.getDSInfo(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := e.getCWClient(region, dsInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -531,7 +535,6 @@ func (e *cloudWatchExecutor) listMetrics(region string, params *cloudwatch.ListM
 	pageNum := 0
 	err = client.ListMetricsPages(params, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
 		pageNum++
-		metrics.MAwsCloudWatchListMetrics.Inc()
 		metrics, err := awsutil.ValuesAtPath(page, "Metrics")
 		if err == nil {
 			for _, metric := range metrics {
@@ -3,6 +3,7 @@ package cloudwatch
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -37,6 +38,12 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 		return backend.NewQueryDataResponse(), nil
 	}
 
+	dsInfo, err := e.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	alignTimeRange := dsInfo.apiCalls != nil && dsInfo.apiCalls.metricDataTTL > 0
+
 	resultChan := make(chan *responseWrapper, len(req.Queries))
 	eg, ectx := errgroup.WithContext(ctx)
 	for r, q := range requestQueriesByRegion {
@@ -56,12 +63,17 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 				}
 			}()
 
-			client, err := e.getCWClient(region, req.PluginContext)
+			client, err := e.getCWClient(region, dsInfo)
 			if err != nil {
 				return err
 			}
 
-			metricDataInput, err := e.buildMetricDataInput(startTime, endTime, requestQueries)
+			requestStart, requestEnd := startTime, endTime
+			if alignTimeRange {
+				requestStart, requestEnd = alignToPeriod(startTime, endTime, requestQueries)
+			}
+
+			metricDataInput, err := e.buildMetricDataInput(requestStart, requestEnd, requestQueries)
 			if err != nil {
 				return err
 			}
@@ -71,7 +83,21 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 				return err
 			}
 
-			res, err := e.parseResponse(startTime, endTime, mdo, requestQueries)
+			// the latest, partial period still changes, so it is requested separately and not cached
+			if requestEnd.Before(endTime) {
+				partialInput, err := e.buildMetricDataInput(requestEnd, endTime, requestQueries)
+				if err != nil {
+					return err
+				}
+
+				partial, err := e.executeRequest(withoutCache(ectx), client, partialInput)
+				if err != nil {
+					return err
+				}
+				mdo = append(mdo, partial...)
+			}
+
+			res, err := e.parseResponse(requestStart, endTime, mdo, requestQueries)
 			if err != nil {
 				return err
 			}
@@ -100,3 +126,27 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, req *ba
 
 	return resp, nil
 }
+
+// alignToPeriod aligns the time range to the smallest period of the queries, so that the requests of a
+// relative time range stay the same for a period and their responses can be cached. The time range is
+// kept if it is shorter than the period. Otherwise both ends are rounded down to full periods, and the
+// latest, partial period after the aligned end has to be requested on its own.
+func alignToPeriod(startTime time.Time, endTime time.Time, queries []*cloudWatchQuery) (time.Time, time.Time) {
+	period := 0
+	for _, query := range queries {
+		if query.Period > 0 && (period == 0 || query.Period < period) {
+			period = query.Period
+		}
+	}
+	if period == 0 {
+		return startTime, endTime
+	}
+
+	p := int64(period)
+	alignedStart := time.Unix(startTime.Unix()/p*p, 0).In(startTime.Location())
+	alignedEnd := time.Unix(endTime.Unix()/p*p, 0).In(endTime.Location())
+	if !alignedStart.Before(alignedEnd) {
+		return startTime, endTime
+	}
+	return alignedStart, alignedEnd
+}
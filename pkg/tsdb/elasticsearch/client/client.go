@@ -36,6 +36,15 @@ type DatasourceInfo struct {
 	MaxConcurrentShardRequests int64
 	IncludeFrozen              bool
 	XPack                      bool
+	LogMessageField            string
+	LogLevelField              string
+}
+
+// ConfiguredFields holds the document fields configured on the datasource
+type ConfiguredFields struct {
+	TimeField       string
+	LogMessageField string
+	LogLevelField   string
 }
 
 const loggerName = "tsdb.elasticsearch.client"
@@ -52,9 +61,12 @@ var newDatasourceHttpClient = func(httpClientProvider httpclient.Provider, ds *D
 type Client interface {
 	GetVersion() *semver.Version
 	GetTimeField() string
+	GetConfiguredFields() ConfiguredFields
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	OpenPointInTime(keepAlive string) (string, error)
+	ClosePointInTime(id string) error
 	EnableDebug()
 }
 
@@ -102,6 +114,14 @@ func (c *baseClientImpl) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
+	return ConfiguredFields{
+		TimeField:       c.timeField,
+		LogMessageField: c.ds.LogMessageField,
+		LogLevelField:   c.ds.LogLevelField,
+	}
+}
+
 func (c *baseClientImpl) GetMinInterval(queryInterval string) (time.Duration, error) {
 	timeInterval := c.ds.TimeInterval
 	return intervalv2.GetIntervalFrom(queryInterval, timeInterval, 0, 5*time.Second)
@@ -118,7 +138,18 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
+}
+
+func (c *baseClientImpl) executeJSONRequest(method, uriPath, uriQuery string, body interface{}) (*response, error) {
+	var bytes []byte
+	if body != nil {
+		var err error
+		if bytes, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	return c.executeRequest(method, uriPath, uriQuery, "application/json", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -151,7 +182,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*response, error) {
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
 		return nil, err
@@ -160,8 +191,8 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 	u.RawQuery = uriQuery
 
 	var req *http.Request
-	if method == http.MethodPost {
-		req, err = http.NewRequest(http.MethodPost, u.String(), bytes.NewBuffer(body))
+	if method == http.MethodPost || method == http.MethodDelete {
+		req, err = http.NewRequest(method, u.String(), bytes.NewBuffer(body))
 	} else {
 		req, err = http.NewRequest(http.MethodGet, u.String(), nil)
 	}
@@ -180,7 +211,7 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 		}
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	httpClient, err := newDatasourceHttpClient(c.httpClientProvider, c.ds)
 	if err != nil {
@@ -207,7 +238,7 @@ func (c *baseClientImpl) ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearch
 	clientLog.Debug("Executing multisearch", "search requests", len(r.Requests))
 
 	multiRequests := c.createMultiSearchRequests(r.Requests)
-	queryParams := c.getMultiSearchQueryParameters()
+	queryParams := c.getMultiSearchQueryParameters(r.Requests)
 	clientRes, err := c.executeBatchRequest("_msearch", queryParams, multiRequests)
 	if err != nil {
 		return nil, err
@@ -283,6 +314,12 @@ func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchReque
 			interval: searchReq.Interval,
 		}
 
+		if searchReq.PointInTime != nil {
+			// a point in time determines the indices of the search, which must not be set again
+			delete(mr.header, "index")
+			delete(mr.header, "ignore_unavailable")
+		}
+
 		if c.version.Major() < 5 {
 			mr.header["search_type"] = "count"
 		} else {
@@ -303,7 +340,7 @@ func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchReque
 	return multiRequests
 }
 
-func (c *baseClientImpl) getMultiSearchQueryParameters() string {
+func (c *baseClientImpl) getMultiSearchQueryParameters(searchRequests []*SearchRequest) string {
 	var qs []string
 
 	if c.version.Major() >= 7 {
@@ -316,13 +353,72 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 
 	allowedFrozenIndicesVersionRange, _ := semver.NewConstraint(">=6.6.0")
 
-	if (allowedFrozenIndicesVersionRange.Check(c.version)) && c.ds.IncludeFrozen && c.ds.XPack {
+	// the indices options of a point in time are those it was opened with
+	if (allowedFrozenIndicesVersionRange.Check(c.version)) && c.ds.IncludeFrozen && c.ds.XPack && !usePointInTime(searchRequests) {
 		qs = append(qs, "ignore_throttled=false")
 	}
 
 	return strings.Join(qs, "&")
 }
 
+// OpenPointInTime opens a point in time on the indices of the client, which keeps the view of the indices
+// searches with the point in time get for the keep alive duration.
+func (c *baseClientImpl) OpenPointInTime(keepAlive string) (string, error) {
+	queryParams := url.Values{}
+	queryParams.Set("keep_alive", keepAlive)
+	queryParams.Set("ignore_unavailable", "true")
+	if c.ds.IncludeFrozen && c.ds.XPack {
+		queryParams.Set("ignore_throttled", "false")
+	}
+
+	clientRes, err := c.executeJSONRequest(http.MethodPost, strings.Join(c.indices, ",")+"/_pit", queryParams.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	res := clientRes.httpResponse
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			clientLog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	var pit struct {
+		ID    string                 `json:"id"`
+		Error map[string]interface{} `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		return "", err
+	}
+	if pit.ID == "" {
+		return "", fmt.Errorf("failed to open point in time, status %d: %v", res.StatusCode, pit.Error["reason"])
+	}
+	return pit.ID, nil
+}
+
+// ClosePointInTime closes a point in time, releasing the resources it holds before it expires.
+func (c *baseClientImpl) ClosePointInTime(id string) error {
+	clientRes, err := c.executeJSONRequest(http.MethodDelete, "_pit", "", map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+	if err := clientRes.httpResponse.Body.Close(); err != nil {
+		clientLog.Warn("Failed to close response body", "err", err)
+	}
+	if clientRes.httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to close point in time, status %d", clientRes.httpResponse.StatusCode)
+	}
+	return nil
+}
+
+func usePointInTime(searchRequests []*SearchRequest) bool {
+	for _, r := range searchRequests {
+		if r.PointInTime != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder(c.GetVersion())
 }
@@ -315,6 +315,67 @@ func TestClient_ExecuteMultisearch(t *testing.T) {
 	})
 }
 
+func TestClient_PointInTime(t *testing.T) {
+	version, err := semver.NewVersion("7.12.0")
+	require.NoError(t, err)
+	ds := &DatasourceInfo{
+		Database:      "[metrics-]YYYY.MM.DD",
+		ESVersion:     version,
+		TimeField:     "@timestamp",
+		Interval:      "Daily",
+		IncludeFrozen: true,
+		XPack:         true,
+	}
+
+	httpClientScenario(t, "Given a fake http client when opening a point in time", ds, func(sc *scenarioContext) {
+		sc.responseBody = `{ "id": "pit-id" }`
+
+		id, err := sc.client.OpenPointInTime("1m")
+		require.NoError(t, err)
+		assert.Equal(t, "pit-id", id)
+
+		require.NotNil(t, sc.request)
+		assert.Equal(t, http.MethodPost, sc.request.Method)
+		assert.Equal(t, "/metrics-2018.05.15/_pit", sc.request.URL.Path)
+		assert.Equal(t, "ignore_throttled=false&ignore_unavailable=true&keep_alive=1m", sc.request.URL.RawQuery)
+	})
+
+	httpClientScenario(t, "Given a fake http client when searching a point in time", ds, func(sc *scenarioContext) {
+		sc.responseBody = `{ "responses": [ { "pit_id": "next-pit-id", "hits": { "hits": [] }, "status": 200 } ] }`
+
+		msb := sc.client.MultiSearch()
+		msb.Search(intervalv2.Interval{}).PointInTime("pit-id", "1m")
+		ms, err := msb.Build()
+		require.NoError(t, err)
+		res, err := sc.client.ExecuteMultisearch(ms)
+		require.NoError(t, err)
+		assert.Equal(t, "next-pit-id", res.Responses[0].PitID)
+
+		assert.Equal(t, "max_concurrent_shard_requests=5", sc.request.URL.RawQuery)
+		headerBytes, err := sc.requestBody.ReadBytes('\n')
+		require.NoError(t, err)
+		jHeader, err := simplejson.NewJson(headerBytes)
+		require.NoError(t, err)
+		assert.Empty(t, jHeader.Get("index").MustString())
+		_, hasIgnoreUnavailable := jHeader.CheckGet("ignore_unavailable")
+		assert.False(t, hasIgnoreUnavailable)
+
+		jBody, err := simplejson.NewJson(sc.requestBody.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "pit-id", jBody.GetPath("pit", "id").MustString())
+	})
+
+	httpClientScenario(t, "Given a fake http client when closing a point in time", ds, func(sc *scenarioContext) {
+		sc.responseBody = `{ "succeeded": true, "num_freed": 1 }`
+
+		require.NoError(t, sc.client.ClosePointInTime("pit-id"))
+		assert.Equal(t, http.MethodDelete, sc.request.Method)
+		assert.Equal(t, "/_pit", sc.request.URL.Path)
+		assert.Equal(t, "application/json", sc.request.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"id": "pit-id"}`, sc.requestBody.String())
+	})
+}
+
 func createMultisearchForTest(t *testing.T, c Client) (*MultiSearchRequest, error) {
 	t.Helper()
 
@@ -35,6 +35,9 @@ type SearchRequest struct {
 	Interval    intervalv2.Interval
 	Size        int
 	Sort        map[string]interface{}
+	SortOrder   []string
+	SearchAfter []interface{}
+	PointInTime *PointInTime
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
@@ -45,10 +48,25 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	root := make(map[string]interface{})
 
 	root["size"] = r.Size
-	if len(r.Sort) > 0 {
+	if len(r.Sort) > 1 && len(r.SortOrder) == len(r.Sort) {
+		// the order of the sort fields matters, which a single object does not preserve
+		sort := make([]map[string]interface{}, 0, len(r.SortOrder))
+		for _, field := range r.SortOrder {
+			sort = append(sort, map[string]interface{}{field: r.Sort[field]})
+		}
+		root["sort"] = sort
+	} else if len(r.Sort) > 0 {
 		root["sort"] = r.Sort
 	}
 
+	if len(r.SearchAfter) > 0 {
+		root["search_after"] = r.SearchAfter
+	}
+
+	if r.PointInTime != nil {
+		root["pit"] = r.PointInTime
+	}
+
 	for key, value := range r.CustomProps {
 		root[key] = value
 	}
@@ -62,6 +80,12 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(root)
 }
 
+// PointInTime represents the point in time a search request is executed against
+type PointInTime struct {
+	ID        string `json:"id"`
+	KeepAlive string `json:"keep_alive"`
+}
+
 // SearchResponseHits represents search response hits
 type SearchResponseHits struct {
 	Hits []map[string]interface{}
@@ -72,6 +96,7 @@ type SearchResponse struct {
 	Error        map[string]interface{} `json:"error"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 	Hits         *SearchResponseHits    `json:"hits"`
+	PitID        string                 `json:"pit_id"`
 }
 
 // MultiSearchRequest represents a multi search request
@@ -14,6 +14,9 @@ type SearchRequestBuilder struct {
 	index        string
 	size         int
 	sort         map[string]interface{}
+	sortOrder    []string
+	searchAfter  []interface{}
+	pointInTime  *PointInTime
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]interface{}
@@ -38,6 +41,9 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Interval:    b.interval,
 		Size:        b.size,
 		Sort:        b.sort,
+		SortOrder:   b.sortOrder,
+		SearchAfter: b.searchAfter,
+		PointInTime: b.pointInTime,
 		CustomProps: b.customProps,
 	}
 
@@ -80,8 +86,38 @@ func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchReque
 		props["unmapped_type"] = unmappedType
 	}
 
+	b.addSort(field, props)
+
+	return b
+}
+
+// SortAsc adds an ascending sort to the search request
+func (b *SearchRequestBuilder) SortAsc(field string) *SearchRequestBuilder {
+	b.addSort(field, map[string]string{
+		"order": "asc",
+	})
+
+	return b
+}
+
+func (b *SearchRequestBuilder) addSort(field string, props map[string]string) {
+	if _, exists := b.sort[field]; !exists {
+		b.sortOrder = append(b.sortOrder, field)
+	}
 	b.sort[field] = props
+}
+
+// SearchAfter sets the sort values of the last document of the previous page,
+// the search request returns the documents that follow it
+func (b *SearchRequestBuilder) SearchAfter(sortValues []interface{}) *SearchRequestBuilder {
+	b.searchAfter = sortValues
+	return b
+}
 
+// PointInTime executes the search request against a point in time opened with the keep alive duration,
+// which the search extends
+func (b *SearchRequestBuilder) PointInTime(id, keepAlive string) *SearchRequestBuilder {
+	b.pointInTime = &PointInTime{ID: id, KeepAlive: keepAlive}
 	return b
 }
 
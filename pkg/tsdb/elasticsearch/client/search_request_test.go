@@ -106,6 +106,35 @@ func TestSearchRequest(t *testing.T) {
 		})
 	})
 
+	t.Run("When adding several sorts, search after and point in time", func(t *testing.T) {
+		b := setup()
+		b.SortDesc(timeField, "boolean")
+		b.SortAsc("_shard_doc")
+		b.SearchAfter([]interface{}{float64(1526406600000), float64(42)})
+		b.PointInTime("pit-id", "1m")
+
+		t.Run("When marshal to JSON should keep the order of the sorts", func(t *testing.T) {
+			sr, err := b.Build()
+			require.Nil(t, err)
+			body, err := json.Marshal(sr)
+			require.Nil(t, err)
+			json, err := simplejson.NewJson(body)
+			require.Nil(t, err)
+
+			sort := json.Get("sort")
+			require.Len(t, sort.MustArray(), 2)
+			require.Equal(t, "desc", sort.GetIndex(0).GetPath(timeField, "order").MustString())
+			require.Equal(t, "asc", sort.GetIndex(1).GetPath("_shard_doc", "order").MustString())
+
+			searchAfter := json.Get("search_after")
+			require.Equal(t, int64(1526406600000), searchAfter.GetIndex(0).MustInt64())
+			require.Equal(t, int64(42), searchAfter.GetIndex(1).MustInt64())
+
+			require.Equal(t, "pit-id", json.GetPath("pit", "id").MustString())
+			require.Equal(t, "1m", json.GetPath("pit", "keep_alive").MustString())
+		})
+	})
+
 	t.Run("When adding doc value field", func(t *testing.T) {
 		b := setup()
 		b.AddDocValueField(timeField)
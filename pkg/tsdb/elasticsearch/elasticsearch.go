@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
@@ -22,15 +23,17 @@ type Service struct {
 	httpClientProvider httpclient.Provider
 	intervalCalculator intervalv2.Calculator
 	im                 instancemgmt.InstanceManager
+	rowLimit           int64
 }
 
-func ProvideService(httpClientProvider httpclient.Provider) *Service {
+func ProvideService(cfg *setting.Cfg, httpClientProvider httpclient.Provider) *Service {
 	eslog.Debug("initializing")
 
 	return &Service{
 		im:                 datasource.NewInstanceManager(newInstanceSettings()),
 		httpClientProvider: httpClientProvider,
 		intervalCalculator: intervalv2.NewCalculator(),
+		rowLimit:           cfg.DataProxyRowLimit,
 	}
 }
 
@@ -49,7 +52,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	query := newTimeSeriesQuery(client, req.Queries, s.intervalCalculator)
+	query := newTimeSeriesQuery(client, req.Queries, s.intervalCalculator, s.rowLimit)
 	return query.execute()
 }
 
@@ -110,6 +113,16 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			xpack = false
 		}
 
+		logMessageField, ok := jsonData["logMessageField"].(string)
+		if !ok {
+			logMessageField = ""
+		}
+
+		logLevelField, ok := jsonData["logLevelField"].(string)
+		if !ok {
+			logLevelField = ""
+		}
+
 		model := es.DatasourceInfo{
 			ID:                         settings.ID,
 			URL:                        settings.URL,
@@ -122,6 +135,8 @@ func newInstanceSettings() datasource.InstanceFactoryFunc {
 			TimeInterval:               timeInterval,
 			IncludeFrozen:              includeFrozen,
 			XPack:                      xpack,
+			LogMessageField:            logMessageField,
+			LogLevelField:              logLevelField,
 		}
 		return model, nil
 	}
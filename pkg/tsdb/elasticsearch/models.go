@@ -1,6 +1,8 @@
 package elasticsearch
 
 import (
+	"strconv"
+
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -54,6 +56,8 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 	"rate":           "Rate",
 }
 
@@ -112,6 +116,32 @@ func isPipelineAggWithMultipleBucketPaths(metricType string) bool {
 	return false
 }
 
+// isDocumentQuery returns true for queries that return documents instead of aggregations.
+func isDocumentQuery(q *Query) bool {
+	if len(q.BucketAggs) > 0 || len(q.Metrics) == 0 {
+		return false
+	}
+	return q.Metrics[0].Type == logsType || q.Metrics[0].Type == rawDataType
+}
+
+// documentQuerySize returns the number of documents a logs or raw data query asks for.
+func documentQuerySize(q *Query) int {
+	settingName := "size"
+	if q.Metrics[0].Type == logsType {
+		settingName = "limit"
+	}
+
+	setting := q.Metrics[0].Settings.Get(settingName)
+	if size, err := setting.Int(); err == nil && size > 0 {
+		return size
+	}
+	// the query editor stores the size as a string
+	if size, err := strconv.Atoi(setting.MustString()); err == nil && size > 0 {
+		return size
+	}
+	return defaultDocumentSize
+}
+
 func describeMetric(metricType, field string) string {
 	text := metricAggType[metricType]
 	if metricType == countType {
@@ -1,7 +1,9 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
@@ -20,6 +22,9 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -28,17 +33,27 @@ const (
 	geohashGridType = "geohash_grid"
 )
 
+const (
+	// defaultDocumentSize is the number of documents returned when the query does not set a size
+	defaultDocumentSize = 500
+	// documentPageSize is the number of documents requested at once, the default
+	// index.max_result_window of Elasticsearch
+	documentPageSize = 10000
+)
+
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields es.ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo, configuredFields es.ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -74,6 +89,14 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 
 		queryRes := backend.DataResponse{}
 
+		if isDocumentQuery(target) {
+			frame := rp.processDocuments(res.Hits, target)
+			frame.Meta.Custom = debugInfo
+			queryRes.Frames = data.Frames{frame}
+			result.Responses[target.RefID] = queryRes
+			continue
+		}
+
 		props := make(map[string]string)
 		err := rp.processBuckets(res.Aggregations, target, &queryRes, props, 0)
 		if err != nil {
@@ -92,6 +115,233 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 	return &result, nil
 }
 
+// processDocuments flattens the hits of a logs or raw data query into a single frame with a
+// typed field per document property, the time field first.
+func (rp *responseParser) processDocuments(hits *es.SearchResponseHits, target *Query) *data.Frame {
+	isLogs := target.Metrics[0].Type == logsType
+	timeField := rp.ConfiguredFields.TimeField
+	messageField := rp.ConfiguredFields.LogMessageField
+	levelField := rp.ConfiguredFields.LogLevelField
+
+	var documents []map[string]interface{}
+	var times []*time.Time
+	propNames := map[string]bool{}
+
+	if hits != nil {
+		documents = make([]map[string]interface{}, 0, len(hits.Hits))
+		times = make([]*time.Time, 0, len(hits.Hits))
+		for _, hit := range hits.Hits {
+			doc := map[string]interface{}{}
+			source, _ := hit["_source"].(map[string]interface{})
+			flattenDocument(doc, "", source)
+			for _, metaField := range []string{"_id", "_type", "_index"} {
+				if value, ok := hit[metaField]; ok && value != nil {
+					doc[metaField] = value
+				}
+			}
+			if isLogs && (messageField == "" || doc[messageField] == nil) {
+				doc["_source"] = source
+			}
+			if isLogs && levelField != "" && levelField != "level" && doc[levelField] != nil {
+				doc["level"] = doc[levelField]
+			}
+
+			for name := range doc {
+				propNames[name] = true
+			}
+			documents = append(documents, doc)
+			times = append(times, documentTime(hit, doc, timeField))
+		}
+	}
+
+	fields := []*data.Field{data.NewField(timeField, nil, times)}
+	names := sortDocumentFields(propNames, timeField, messageField, isLogs)
+	for _, name := range names {
+		values := make([]interface{}, 0, len(documents))
+		for _, doc := range documents {
+			values = append(values, doc[name])
+		}
+		fields = append(fields, newDocumentField(name, values))
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+	if isLogs {
+		frame.Meta.PreferredVisualization = data.VisTypeLogs
+	}
+	return frame
+}
+
+// sortDocumentFields orders the document properties: the log message and level first for logs,
+// then the document properties by name and finally the Elasticsearch metadata fields.
+func sortDocumentFields(propNames map[string]bool, timeField, messageField string, isLogs bool) []string {
+	first := []string{}
+	if isLogs {
+		for _, name := range []string{messageField, "_source", "level"} {
+			if name != "" && propNames[name] {
+				first = append(first, name)
+			}
+		}
+	}
+	metaFields := []string{}
+	for _, name := range []string{"_id", "_type", "_index"} {
+		if propNames[name] {
+			metaFields = append(metaFields, name)
+		}
+	}
+
+	skip := map[string]bool{timeField: true}
+	for _, name := range first {
+		skip[name] = true
+	}
+	for _, name := range metaFields {
+		skip[name] = true
+	}
+
+	names := make([]string, 0, len(propNames))
+	for name := range propNames {
+		if !skip[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := append(first, names...)
+	return append(result, metaFields...)
+}
+
+// flattenDocument copies the properties of a nested document into target, with the
+// names of nested properties joined by dots.
+func flattenDocument(target map[string]interface{}, prefix string, source map[string]interface{}) {
+	for key, value := range source {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenDocument(target, name, nested)
+			continue
+		}
+		if value != nil {
+			target[name] = value
+		}
+	}
+}
+
+// documentTime returns the time of a document from its source, its doc value fields or its sort value.
+func documentTime(hit map[string]interface{}, doc map[string]interface{}, timeField string) *time.Time {
+	if t, ok := parseDocumentTime(doc[timeField]); ok {
+		return &t
+	}
+
+	if fields, ok := hit["fields"].(map[string]interface{}); ok {
+		if values, ok := fields[timeField].([]interface{}); ok && len(values) > 0 {
+			if t, ok := parseDocumentTime(values[0]); ok {
+				return &t
+			}
+		}
+	}
+
+	if sortValues, ok := hit["sort"].([]interface{}); ok && len(sortValues) > 0 {
+		if t, ok := parseDocumentTime(sortValues[0]); ok {
+			return &t
+		}
+	}
+
+	return nil
+}
+
+// parseDocumentTime parses a date in ISO 8601 format or in milliseconds since the epoch.
+func parseDocumentTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(0, int64(v*float64(time.Millisecond))).UTC(), true
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UTC(), true
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(0, ms*int64(time.Millisecond)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// newDocumentField creates a field typed after its values. Numbers and booleans keep their
+// type, other values are stored as strings, arrays and mixed values as JSON.
+func newDocumentField(name string, values []interface{}) *data.Field {
+	const (
+		kindNumber = "number"
+		kindBool   = "bool"
+		kindString = "string"
+	)
+
+	kind := ""
+	for _, value := range values {
+		valueKind := kindString
+		switch value.(type) {
+		case nil:
+			continue
+		case float64:
+			valueKind = kindNumber
+		case bool:
+			valueKind = kindBool
+		}
+		if kind == "" {
+			kind = valueKind
+		} else if kind != valueKind {
+			kind = kindString
+		}
+	}
+
+	switch kind {
+	case kindNumber:
+		numbers := make([]*float64, 0, len(values))
+		for _, value := range values {
+			if n, ok := value.(float64); ok {
+				numbers = append(numbers, &n)
+			} else {
+				numbers = append(numbers, nil)
+			}
+		}
+		return data.NewField(name, nil, numbers)
+	case kindBool:
+		bools := make([]*bool, 0, len(values))
+		for _, value := range values {
+			if b, ok := value.(bool); ok {
+				bools = append(bools, &b)
+			} else {
+				bools = append(bools, nil)
+			}
+		}
+		return data.NewField(name, nil, bools)
+	default:
+		strs := make([]*string, 0, len(values))
+		for _, value := range values {
+			strs = append(strs, documentValueToString(value))
+		}
+		return data.NewField(name, nil, strs)
+	}
+}
+
+func documentValueToString(value interface{}) *string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return &v
+	default:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			str := fmt.Sprintf("%v", v)
+			return &str
+		}
+		str := string(bytes)
+		return &str
+	}
+}
+
 func (rp *responseParser) processBuckets(aggs map[string]interface{}, target *Query,
 	queryResult *backend.DataResponse, props map[string]string, depth int) error {
 	var err error
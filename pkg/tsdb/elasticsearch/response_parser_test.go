@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1061,6 +1062,126 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestDocumentResponseParser(t *testing.T) {
+	response := `{
+		"responses": [
+			{
+				"hits": {
+					"total": { "value": 2, "relation": "eq" },
+					"hits": [
+						{
+							"_id": "fdsfs",
+							"_type": "_doc",
+							"_index": "mock-index",
+							"_source": {
+								"@timestamp": "2019-06-24T09:51:19.765Z",
+								"host": { "name": "app-1", "cpu": { "cores": 4 } },
+								"message": "hello, i am a message",
+								"level": "debug",
+								"success": true,
+								"tags": ["a", "b"]
+							},
+							"sort": [1561369879765]
+						},
+						{
+							"_id": "kdospaidopa",
+							"_type": "_doc",
+							"_index": "mock-index",
+							"_source": {
+								"@timestamp": "2019-06-24T09:52:19.765Z",
+								"host": { "name": "app-2" },
+								"message": "hello, i am also message",
+								"level": "error",
+								"success": false
+							},
+							"sort": [1561369939765]
+						}
+					]
+				}
+			}
+		]
+	}`
+
+	t.Run("Raw data query flattens documents into typed fields", func(t *testing.T) {
+		rp, err := newResponseParserForTest(map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "raw_data", "id": "1" }]
+			}`,
+		}, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+
+		names := []string{}
+		for _, field := range frame.Fields {
+			names = append(names, field.Name)
+		}
+		require.Equal(t, []string{"@timestamp", "host.cpu.cores", "host.name", "level", "message", "success", "tags", "_id", "_type", "_index"}, names)
+
+		timeField := frame.Fields[0]
+		require.Equal(t, data.FieldTypeNullableTime, timeField.Type())
+		require.Equal(t, time.Date(2019, 6, 24, 9, 51, 19, 765000000, time.UTC), *timeField.At(0).(*time.Time))
+
+		cores := frame.Fields[1]
+		require.Equal(t, data.FieldTypeNullableFloat64, cores.Type())
+		require.Equal(t, float64(4), *cores.At(0).(*float64))
+		require.Nil(t, cores.At(1))
+
+		success := frame.Fields[5]
+		require.Equal(t, data.FieldTypeNullableBool, success.Type())
+		require.False(t, *success.At(1).(*bool))
+
+		tags := frame.Fields[6]
+		require.Equal(t, data.FieldTypeNullableString, tags.Type())
+		require.Equal(t, `["a","b"]`, *tags.At(0).(*string))
+	})
+
+	t.Run("Logs query uses the configured message and level fields", func(t *testing.T) {
+		rp, err := newResponseParserForTest(map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "logs", "id": "1" }]
+			}`,
+		}, response)
+		require.NoError(t, err)
+		rp.ConfiguredFields.LogMessageField = "message"
+		rp.ConfiguredFields.LogLevelField = "host.name"
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, data.VisTypeLogs, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, "@timestamp", frame.Fields[0].Name)
+		require.Equal(t, "message", frame.Fields[1].Name)
+		require.Equal(t, "hello, i am a message", *frame.Fields[1].At(0).(*string))
+		require.Equal(t, "level", frame.Fields[2].Name)
+		require.Equal(t, "app-2", *frame.Fields[2].At(1).(*string))
+	})
+
+	t.Run("Logs query without message field returns the source as log line", func(t *testing.T) {
+		rp, err := newResponseParserForTest(map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "logs", "id": "1" }]
+			}`,
+		}, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, "_source", frame.Fields[1].Name)
+		require.Contains(t, *frame.Fields[1].At(0).(*string), `"message":"hello, i am a message"`)
+	})
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1092,5 +1213,5 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 		return nil, err
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, es.ConfiguredFields{TimeField: "@timestamp"}), nil
 }
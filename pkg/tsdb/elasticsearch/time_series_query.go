@@ -8,23 +8,30 @@ import (
 
 	"github.com/Masterminds/semver"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
 
+// pointInTimeKeepAlive is how long the point in time of a paged document query is kept between two pages.
+const pointInTimeKeepAlive = "1m"
+
 type timeSeriesQuery struct {
 	client             es.Client
 	dataQueries        []backend.DataQuery
 	intervalCalculator intervalv2.Calculator
+	// rowLimit is the number of documents a logs or raw data query returns at most
+	rowLimit int64
 }
 
 var newTimeSeriesQuery = func(client es.Client, dataQuery []backend.DataQuery,
-	intervalCalculator intervalv2.Calculator) *timeSeriesQuery {
+	intervalCalculator intervalv2.Calculator, rowLimit int64) *timeSeriesQuery {
 	return &timeSeriesQuery{
 		client:             client,
 		dataQueries:        dataQuery,
 		intervalCalculator: intervalCalculator,
+		rowLimit:           rowLimit,
 	}
 }
 
@@ -58,8 +65,17 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
-	return rp.getTimeSeries()
+	if err := e.fetchDocumentPages(queries, res, from, to); err != nil {
+		return &backend.QueryDataResponse{}, err
+	}
+
+	rp := newResponseParser(res.Responses, queries, res.DebugInfo, e.client.GetConfiguredFields())
+	response, err := rp.getTimeSeries()
+	if err != nil {
+		return response, err
+	}
+	e.addDocumentLimitNotices(queries, response)
+	return response, nil
 }
 
 func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64,
@@ -72,24 +88,30 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 
 	b := ms.Search(interval)
 	b.Size(0)
-	filters := b.Query().Bool().Filter()
-	filters.AddDateRangeFilter(e.client.GetTimeField(), to, from, es.DateFormatEpochMS)
-
-	if q.RawQuery != "" {
-		filters.AddQueryStringFilter(q.RawQuery, true)
-	}
+	e.addFilters(b, q, from, to)
 
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+		if len(q.Metrics) == 0 || (q.Metrics[0].Type != rawDocumentType && !isDocumentQuery(q)) {
 			result.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("invalid query, missing metrics and aggregations"),
 			}
 			return nil
 		}
+
+		if isDocumentQuery(q) {
+			// documents that do not fit in a page are fetched by fetchDocumentPages, the search only reports errors
+			if size := e.documentSize(q); size <= documentPageSize {
+				b.Size(size)
+				b.SortDesc(e.client.GetTimeField(), "boolean")
+				b.AddDocValueField(e.client.GetTimeField())
+			}
+			return nil
+		}
+
 		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+		b.Size(metric.Settings.Get("size").MustInt(defaultDocumentSize))
+		b.SortDesc(e.client.GetTimeField(), "boolean")
+		b.AddDocValueField(e.client.GetTimeField())
 		return nil
 	}
 
@@ -182,6 +204,153 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+func (e *timeSeriesQuery) addFilters(b *es.SearchRequestBuilder, q *Query, from, to int64) {
+	filters := b.Query().Bool().Filter()
+	filters.AddDateRangeFilter(e.client.GetTimeField(), to, from, es.DateFormatEpochMS)
+
+	if q.RawQuery != "" {
+		filters.AddQueryStringFilter(q.RawQuery, true)
+	}
+}
+
+// canPageDocuments returns true when the documents of a query can be fetched in pages, against a point in time
+// and sorted on the shard document as tie-breaker, which Elasticsearch supports from 7.12.
+func (e *timeSeriesQuery) canPageDocuments() bool {
+	constraint, _ := semver.NewConstraint(">=7.12.0")
+	return constraint.Check(e.client.GetVersion())
+}
+
+// documentLimit returns the number of documents a logs or raw data query returns at most.
+func (e *timeSeriesQuery) documentLimit() int {
+	limit := int(e.rowLimit)
+	if !e.canPageDocuments() && limit > documentPageSize {
+		limit = documentPageSize
+	}
+	return limit
+}
+
+// documentSize returns the number of documents requested for a logs or raw data query.
+func (e *timeSeriesQuery) documentSize(q *Query) int {
+	return minInt(documentQuerySize(q), e.documentLimit())
+}
+
+// fetchDocumentPages requests the documents of the logs and raw data queries that do not fit in a page,
+// setting them as the hits of the query response.
+func (e *timeSeriesQuery) fetchDocumentPages(queries []*Query, res *es.MultiSearchResponse, from, to int64) error {
+	for i, q := range queries {
+		if !isDocumentQuery(q) || i >= len(res.Responses) {
+			continue
+		}
+
+		r := res.Responses[i]
+		size := e.documentSize(q)
+		if r.Error != nil || size <= documentPageSize {
+			continue
+		}
+		if err := e.fetchDocuments(q, r, size, from, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchDocuments requests the documents of a query newest first, in pages fetched with search_after against a
+// point in time. The shard document is used as tie-breaker, so that pages neither skip nor repeat documents.
+func (e *timeSeriesQuery) fetchDocuments(q *Query, r *es.SearchResponse, size int, from, to int64) error {
+	pit, err := e.client.OpenPointInTime(pointInTimeKeepAlive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := e.client.ClosePointInTime(pit); err != nil {
+			eslog.Warn("Failed to close point in time", "error", err)
+		}
+	}()
+
+	hits := make([]map[string]interface{}, 0, size)
+	var searchAfter []interface{}
+	for len(hits) < size {
+		requested := minInt(size-len(hits), documentPageSize)
+		ms := e.client.MultiSearch()
+		b := ms.Search(intervalv2.Interval{})
+		e.addFilters(b, q, from, to)
+		b.Size(requested)
+		b.SortDesc(e.client.GetTimeField(), "boolean")
+		b.SortAsc("_shard_doc")
+		b.AddDocValueField(e.client.GetTimeField())
+		b.PointInTime(pit, pointInTimeKeepAlive)
+		if len(searchAfter) > 0 {
+			b.SearchAfter(searchAfter)
+		}
+
+		req, err := ms.Build()
+		if err != nil {
+			return err
+		}
+		pageRes, err := e.client.ExecuteMultisearch(req)
+		if err != nil {
+			return err
+		}
+		if len(pageRes.Responses) == 0 {
+			break
+		}
+
+		page := pageRes.Responses[0]
+		if page.Error != nil {
+			r.Error = page.Error
+			return nil
+		}
+		// the id of a point in time can change with every search, the latest one must be used
+		if page.PitID != "" {
+			pit = page.PitID
+		}
+		if page.Hits == nil || len(page.Hits.Hits) == 0 {
+			break
+		}
+		hits = append(hits, page.Hits.Hits...)
+		if len(page.Hits.Hits) < requested {
+			break
+		}
+		sortValues, ok := page.Hits.Hits[len(page.Hits.Hits)-1]["sort"].([]interface{})
+		if !ok {
+			break
+		}
+		searchAfter = sortValues
+	}
+
+	r.Hits = &es.SearchResponseHits{Hits: hits}
+	return nil
+}
+
+// addDocumentLimitNotices warns about the logs and raw data queries that asked for more documents than they
+// returned because of the document limit.
+func (e *timeSeriesQuery) addDocumentLimitNotices(queries []*Query, response *backend.QueryDataResponse) {
+	limit := e.documentLimit()
+	for _, q := range queries {
+		if !isDocumentQuery(q) || documentQuerySize(q) <= limit {
+			continue
+		}
+		res, ok := response.Responses[q.RefID]
+		if !ok || len(res.Frames) == 0 || res.Frames[0].Rows() < limit {
+			continue
+		}
+
+		text := fmt.Sprintf("Only the first %d documents are returned, the limit of the data source", limit)
+		if limit == documentPageSize && !e.canPageDocuments() {
+			text = fmt.Sprintf("Only the first %d documents are returned, Elasticsearch versions before 7.12 cannot page through documents", limit)
+		}
+		res.Frames[0].AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: text})
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func setFloatPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
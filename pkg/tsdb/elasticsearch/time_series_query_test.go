@@ -2,6 +2,7 @@ package elasticsearch
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -377,6 +378,112 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			require.Equal(t, sr.Size, 1337)
 		})
 
+		t.Run("With logs metric", func(t *testing.T) {
+			c := newFakeClient("7.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": "100" }	}]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 100, sr.Size)
+			require.Equal(t, []string{"@timestamp"}, sr.SortOrder)
+			require.Equal(t, []string{"@timestamp"}, sr.CustomProps["docvalue_fields"])
+			require.Empty(t, sr.Aggs)
+		})
+
+		t.Run("With raw data metric honoring the configured time field", func(t *testing.T) {
+			c := newFakeClient("7.0.0")
+			c.timeField = "timestamp"
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": {}	}]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, defaultDocumentSize, sr.Size)
+			require.Equal(t, []string{"timestamp"}, sr.SortOrder)
+			require.Equal(t, "timestamp", sr.Query.Bool.Filters[0].(*es.RangeFilter).Key)
+		})
+
+		t.Run("With raw data metric larger than a page", func(t *testing.T) {
+			c := newFakeClient("7.12.0")
+			c.multiSearchResponses = []*es.MultiSearchResponse{
+				newHitsResponse(0, 0),
+				newHitsResponse(documentPageSize, 0),
+				newHitsResponse(documentPageSize, documentPageSize),
+				newHitsResponse(3, 2*documentPageSize),
+			}
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": 25000 }	}]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			require.Len(t, c.multisearchRequests, 4)
+			require.Equal(t, 0, c.multisearchRequests[0].Requests[0].Size)
+
+			first := c.multisearchRequests[1].Requests[0]
+			require.Equal(t, documentPageSize, first.Size)
+			require.Equal(t, []string{"@timestamp", "_shard_doc"}, first.SortOrder)
+			require.Equal(t, &es.PointInTime{ID: "pit-1", KeepAlive: pointInTimeKeepAlive}, first.PointInTime)
+			require.Empty(t, first.SearchAfter)
+			second := c.multisearchRequests[2].Requests[0]
+			require.Equal(t, []interface{}{float64(documentPageSize - 1)}, second.SearchAfter)
+			third := c.multisearchRequests[3].Requests[0]
+			require.Equal(t, 25000-2*documentPageSize, third.Size)
+			require.Equal(t, []string{"pit-1"}, c.closedPointsInTime)
+
+			frame := res.Responses[""].Frames[0]
+			require.Equal(t, 2*documentPageSize+3, frame.Rows())
+			require.Empty(t, frame.Meta.Notices)
+		})
+
+		t.Run("With raw data metric larger than the row limit", func(t *testing.T) {
+			c := newFakeClient("7.12.0")
+			c.multiSearchResponses = []*es.MultiSearchResponse{
+				newHitsResponse(0, 0),
+				newHitsResponse(documentPageSize, 0),
+				newHitsResponse(5000, documentPageSize),
+			}
+			res, err := executeTsdbQueryWithRowLimit(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": 25000 }	}]
+			}`, from, to, 15*time.Second, 15000)
+			require.NoError(t, err)
+			require.Len(t, c.multisearchRequests, 3)
+			require.Equal(t, 5000, c.multisearchRequests[2].Requests[0].Size)
+
+			frame := res.Responses[""].Frames[0]
+			require.Equal(t, 15000, frame.Rows())
+			require.Len(t, frame.Meta.Notices, 1)
+			require.Contains(t, frame.Meta.Notices[0].Text, "Only the first 15000 documents are returned")
+		})
+
+		t.Run("With raw data metric larger than a page before 7.12", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			c.multiSearchResponse = newHitsResponse(documentPageSize, 0)
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": 25000 }	}]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			require.Len(t, c.multisearchRequests, 1)
+			require.Equal(t, documentPageSize, c.multisearchRequests[0].Requests[0].Size)
+			require.Empty(t, c.closedPointsInTime)
+
+			frame := res.Responses[""].Frames[0]
+			require.Equal(t, documentPageSize, frame.Rows())
+			require.Len(t, frame.Meta.Notices, 1)
+			require.Contains(t, frame.Meta.Notices[0].Text, "before 7.12")
+		})
+
 		t.Run("With date histogram agg", func(t *testing.T) {
 			c := newFakeClient("5.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -1187,9 +1294,13 @@ type fakeClient struct {
 	version             *semver.Version
 	timeField           string
 	multiSearchResponse *es.MultiSearchResponse
-	multiSearchError    error
-	builder             *es.MultiSearchRequestBuilder
-	multisearchRequests []*es.MultiSearchRequest
+	// multiSearchResponses are returned one per request before multiSearchResponse
+	multiSearchResponses []*es.MultiSearchResponse
+	multiSearchError     error
+	builder              *es.MultiSearchRequestBuilder
+	multisearchRequests  []*es.MultiSearchRequest
+	pointsInTime         int
+	closedPointsInTime   []string
 }
 
 func newFakeClient(versionString string) *fakeClient {
@@ -1212,15 +1323,49 @@ func (c *fakeClient) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
+	return es.ConfiguredFields{TimeField: c.timeField}
+}
+
 func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return 15 * time.Second, nil
 }
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchResponses) > 0 {
+		res := c.multiSearchResponses[0]
+		c.multiSearchResponses = c.multiSearchResponses[1:]
+		return res, c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+// newHitsResponse returns a response with count documents sorted by a sequence number starting at offset.
+func newHitsResponse(count int, offset int) *es.MultiSearchResponse {
+	hits := make([]map[string]interface{}, 0, count)
+	for i := offset; i < offset+count; i++ {
+		hits = append(hits, map[string]interface{}{
+			"_id":     fmt.Sprintf("%d", i),
+			"_source": map[string]interface{}{"seq": float64(i)},
+			"sort":    []interface{}{float64(i)},
+		})
+	}
+	return &es.MultiSearchResponse{
+		Responses: []*es.SearchResponse{{Hits: &es.SearchResponseHits{Hits: hits}}},
+	}
+}
+
+func (c *fakeClient) OpenPointInTime(keepAlive string) (string, error) {
+	c.pointsInTime++
+	return fmt.Sprintf("pit-%d", c.pointsInTime), nil
+}
+
+func (c *fakeClient) ClosePointInTime(id string) error {
+	c.closedPointsInTime = append(c.closedPointsInTime, id)
+	return nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder(c.version)
 	return c.builder
@@ -1236,7 +1381,15 @@ func newDataQuery(body string) (backend.QueryDataRequest, error) {
 	}, nil
 }
 
+// testRowLimit is the default row limit of the data proxy.
+const testRowLimit = 1000000
+
 func executeTsdbQuery(c es.Client, body string, from, to time.Time, minInterval time.Duration) (
+	*backend.QueryDataResponse, error) {
+	return executeTsdbQueryWithRowLimit(c, body, from, to, minInterval, testRowLimit)
+}
+
+func executeTsdbQueryWithRowLimit(c es.Client, body string, from, to time.Time, minInterval time.Duration, rowLimit int64) (
 	*backend.QueryDataResponse, error) {
 	timeRange := backend.TimeRange{
 		From: from,
@@ -1250,7 +1403,7 @@ func executeTsdbQuery(c es.Client, body string, from, to time.Time, minInterval
 			},
 		},
 	}
-	query := newTimeSeriesQuery(c, dataRequest.Queries, intervalv2.NewCalculator(intervalv2.CalculatorOptions{MinInterval: minInterval}))
+	query := newTimeSeriesQuery(c, dataRequest.Queries, intervalv2.NewCalculator(intervalv2.CalculatorOptions{MinInterval: minInterval}), rowLimit)
 	return query.execute()
 }
 
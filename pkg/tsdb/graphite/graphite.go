@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -31,9 +32,10 @@ import (
 )
 
 type Service struct {
-	logger log.Logger
-	im     instancemgmt.InstanceManager
-	tracer tracing.Tracer
+	logger          log.Logger
+	im              instancemgmt.InstanceManager
+	tracer          tracing.Tracer
+	resourceHandler backend.CallResourceHandler
 }
 
 const (
@@ -41,18 +43,25 @@ const (
 	TargetModelField     = "target"
 )
 
+// defaultMaxDataPoints is the number of data points Graphite consolidates series to when the
+// query does not set it.
+const defaultMaxDataPoints = 500
+
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
-	return &Service{
+	s := &Service{
 		logger: log.New("tsdb.graphite"),
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 		tracer: tracer,
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+	functions  *functionsCache
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -71,6 +80,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			HTTPClient: client,
 			URL:        settings.URL,
 			Id:         settings.ID,
+			functions:  &functionsCache{},
 		}
 
 		return model, nil
@@ -86,6 +96,10 @@ func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, e
 	return &instance, nil
 }
 
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	if len(req.Queries) == 0 {
 		return nil, fmt.Errorf("query contains no queries")
@@ -105,11 +119,19 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		https://graphite-api.readthedocs.io/en/latest/api.html#from-until
 	*/
 	from, until := epochMStoGraphiteTime(q.TimeRange)
+
+	// Graphite consolidates the series of the response to at most maxDataPoints points, using the
+	// consolidateBy function of the target or the average.
+	maxDataPoints := q.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMaxDataPoints
+	}
+
 	formData := url.Values{
 		"from":          []string{from},
 		"until":         []string{until},
 		"format":        []string{"json"},
-		"maxDataPoints": []string{"500"},
+		"maxDataPoints": []string{strconv.FormatInt(maxDataPoints, 10)},
 	}
 
 	// Calculate and get the last target of Graphite Request
@@ -0,0 +1,191 @@
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/sync/singleflight"
+)
+
+// functionsCacheTTL is how long the function catalog of a Graphite server is cached. The catalog only
+// changes when Graphite is upgraded.
+const functionsCacheTTL = time.Hour
+
+type resourceResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// functionsCache holds the function catalog of a data source.
+type functionsCache struct {
+	mu       sync.Mutex
+	response *resourceResponse
+	expires  time.Time
+	// fetches makes the requests that miss the cache at the same time share a single fetch of the catalog
+	fetches singleflight.Group
+}
+
+// get returns the cached function catalog, or nil when it is missing or expired.
+func (c *functionsCache) get() *resourceResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.response != nil && time.Now().Before(c.expires) {
+		return c.response
+	}
+	return nil
+}
+
+func (c *functionsCache) set(res *resourceResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.response = res
+	c.expires = time.Now().Add(functionsCacheTTL)
+}
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleResourceReq("metrics/find", "query", "from", "until"))
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleResourceReq("tags/autoComplete/tags", "expr", "tagPrefix", "limit", "from", "until"))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleResourceReq("tags/autoComplete/values", "expr", "tag", "valuePrefix", "limit", "from", "until"))
+	mux.HandleFunc("/functions", s.handleFunctions)
+	return mux
+}
+
+// handleResourceReq forwards the request to the Graphite endpoint with the given query parameters of the
+// request, which may be passed in the URL or as a form.
+func (s *Service) handleResourceReq(endpoint string, params ...string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			writeResponse(rw, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+			return
+		}
+
+		if err := req.ParseForm(); err != nil {
+			writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid request %v", err))
+			return
+		}
+
+		query := url.Values{}
+		for _, param := range params {
+			if values, ok := req.Form[param]; ok {
+				query[param] = values
+			}
+		}
+
+		res, err := s.doResourceRequest(req.Context(), dsInfo, endpoint, query)
+		if err != nil {
+			writeResponse(rw, http.StatusBadGateway, fmt.Sprintf("unexpected error %v", err))
+			return
+		}
+		s.writeResourceResponse(rw, res)
+	}
+}
+
+// handleFunctions returns the function catalog of the Graphite server, which is cached per data source.
+func (s *Service) handleFunctions(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeResponse(rw, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+
+	// the lock is not held while fetching, a slow Graphite server must not block the other requests
+	if res := dsInfo.functions.get(); res != nil {
+		s.writeResourceResponse(rw, res)
+		return
+	}
+
+	v, err, _ := dsInfo.functions.fetches.Do("functions", func() (interface{}, error) {
+		// the fetch is shared by the waiting requests, so it is not cancelled along with the one that started it
+		res, err := s.doResourceRequest(context.Background(), dsInfo, "functions", url.Values{})
+		if err == nil && res.status == http.StatusOK {
+			dsInfo.functions.set(res)
+		}
+		return res, err
+	})
+	if err != nil {
+		writeResponse(rw, http.StatusBadGateway, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+	s.writeResourceResponse(rw, v.(*resourceResponse))
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, endpoint string, query url.Values) (*resourceResponse, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Resource request failed", "endpoint", endpoint, "status", res.Status, "body", string(body))
+	}
+
+	return &resourceResponse{
+		status:      res.StatusCode,
+		contentType: res.Header.Get("Content-Type"),
+		body:        body,
+	}, nil
+}
+
+// writeResourceResponse writes the response of Graphite. Like the data proxy, it turns an authentication failure of
+// Graphite into a bad request, which the frontend does not take for an expired session of the user.
+func (s *Service) writeResourceResponse(rw http.ResponseWriter, res *resourceResponse) {
+	if res.status == http.StatusUnauthorized || res.status == http.StatusForbidden {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("Authentication to data source failed: %s", res.body))
+		return
+	}
+	if res.contentType != "" {
+		rw.Header().Set("Content-Type", res.contentType)
+	}
+	rw.WriteHeader(res.status)
+	if _, err := rw.Write(res.body); err != nil {
+		s.logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResponse(rw http.ResponseWriter, code int, msg string) {
+	rw.WriteHeader(code)
+	_, _ = rw.Write([]byte(msg))
+}
@@ -0,0 +1,211 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResources(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	graphite := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		switch req.URL.Path {
+		case "/graphite/metrics/find":
+			rw.Header().Set("Content-Type", "application/json")
+			_, _ = rw.Write([]byte(`[{"text": "servers", "expandable": 1}]`))
+		case "/graphite/tags/autoComplete/tags", "/graphite/tags/autoComplete/values":
+			_, _ = rw.Write([]byte(`["a", "b"]`))
+		case "/graphite/functions":
+			rw.Header().Set("Content-Type", "application/json")
+			_, _ = rw.Write([]byte(`{"sumSeries": {}}`))
+		case "/slow/functions":
+			time.Sleep(100 * time.Millisecond)
+			rw.Header().Set("Content-Type", "application/json")
+			_, _ = rw.Write([]byte(`{"sumSeries": {}}`))
+		case "/denied/metrics/find":
+			rw.WriteHeader(http.StatusUnauthorized)
+			_, _ = rw.Write([]byte("invalid token"))
+		case "/denied/functions":
+			rw.WriteHeader(http.StatusForbidden)
+			_, _ = rw.Write([]byte("invalid token"))
+		case "/graphite/render":
+			require.NoError(t, req.ParseForm())
+			_, _ = rw.Write([]byte(`[]`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(graphite.Close)
+
+	tracer, err := tracing.InitializeTracerForTest()
+	require.NoError(t, err)
+	s := ProvideService(httpclient.NewProvider(), tracer)
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1, URL: graphite.URL + "/graphite"},
+	}
+
+	t.Run("metrics/find forwards the query of a form", func(t *testing.T) {
+		requests = nil
+		res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "metrics/find",
+			URL:     "metrics/find?from=1&until=2&format=pickle",
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Body:    []byte("query=apps.*"),
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `[{"text": "servers", "expandable": 1}]`, string(res.Body))
+		require.Equal(t, []string{"application/json"}, res.Headers["Content-Type"])
+		require.Len(t, requests, 1)
+		require.Equal(t, http.MethodGet, requests[0].Method)
+		require.Equal(t, url.Values{"query": {"apps.*"}, "from": {"1"}, "until": {"2"}}, requests[0].URL.Query())
+	})
+
+	t.Run("tags autocomplete forwards the tag parameters", func(t *testing.T) {
+		requests = nil
+		res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/values",
+			URL:    "tags/autoComplete/values?expr=env%3Dprod&expr=dc%3Deu&tag=host&valuePrefix=web&limit=100",
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, "/graphite/tags/autoComplete/values", requests[0].URL.Path)
+		require.Equal(t, url.Values{
+			"expr":        {"env=prod", "dc=eu"},
+			"tag":         {"host"},
+			"valuePrefix": {"web"},
+			"limit":       {"100"},
+		}, requests[0].URL.Query())
+
+		res = callResource(t, s, pluginCtx, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/tags",
+			URL:    "tags/autoComplete/tags?tagPrefix=ho",
+		})
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, "/graphite/tags/autoComplete/tags", requests[1].URL.Path)
+	})
+
+	t.Run("authentication failures of Graphite are bad requests", func(t *testing.T) {
+		denied := backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 2, URL: graphite.URL + "/denied"},
+		}
+		for _, path := range []string{"metrics/find", "functions"} {
+			res := callResource(t, s, denied, &backend.CallResourceRequest{Method: http.MethodGet, Path: path, URL: path})
+			require.Equal(t, http.StatusBadRequest, res.Status)
+			require.Equal(t, "Authentication to data source failed: invalid token", string(res.Body))
+		}
+	})
+
+	t.Run("functions are cached", func(t *testing.T) {
+		requests = nil
+		for i := 0; i < 2; i++ {
+			res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{Method: http.MethodGet, Path: "functions", URL: "functions"})
+			require.Equal(t, http.StatusOK, res.Status)
+			require.JSONEq(t, `{"sumSeries": {}}`, string(res.Body))
+		}
+		require.Len(t, requests, 1)
+
+		dsInfo, err := s.getDSInfo(pluginCtx)
+		require.NoError(t, err)
+		dsInfo.functions.expires = time.Now()
+		callResource(t, s, pluginCtx, &backend.CallResourceRequest{Method: http.MethodGet, Path: "functions", URL: "functions"})
+		require.Len(t, requests, 2)
+	})
+
+	t.Run("concurrent cache misses fetch the functions once", func(t *testing.T) {
+		requests = nil
+		slow := backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 3, URL: graphite.URL + "/slow"},
+		}
+
+		var wg sync.WaitGroup
+		senders := make([]*fakeSender, 10)
+		errs := make([]error, len(senders))
+		for i := range senders {
+			senders[i] = &fakeSender{}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				req := &backend.CallResourceRequest{PluginContext: slow, Method: http.MethodGet, Path: "functions", URL: "functions"}
+				errs[i] = s.CallResource(context.Background(), req, senders[i])
+			}(i)
+		}
+		wg.Wait()
+
+		for i, sender := range senders {
+			require.NoError(t, errs[i])
+			require.Equal(t, http.StatusOK, sender.res.Status)
+			require.JSONEq(t, `{"sumSeries": {}}`, string(sender.res.Body))
+		}
+		require.Len(t, requests, 1)
+	})
+
+	t.Run("other Graphite endpoints are not available", func(t *testing.T) {
+		requests = nil
+		res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{Method: http.MethodGet, Path: "render", URL: "render?target=a"})
+		require.Equal(t, http.StatusNotFound, res.Status)
+
+		res = callResource(t, s, pluginCtx, &backend.CallResourceRequest{Method: http.MethodDelete, Path: "metrics/find", URL: "metrics/find"})
+		require.Equal(t, http.StatusMethodNotAllowed, res.Status)
+		require.Empty(t, requests)
+	})
+
+	t.Run("render consolidates series to the max data points of the query", func(t *testing.T) {
+		requests = nil
+		_, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{{
+				RefID:         "A",
+				JSON:          []byte(`{"target": "apps.*.count"}`),
+				MaxDataPoints: 1200,
+				TimeRange:     backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)},
+			}},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		require.Equal(t, "json", requests[0].PostForm.Get("format"))
+		require.Equal(t, "1200", requests[0].PostForm.Get("maxDataPoints"))
+	})
+}
+
+func callResource(t *testing.T, s *Service, pluginCtx backend.PluginContext, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+	t.Helper()
+
+	req.PluginContext = pluginCtx
+	sender := &fakeSender{}
+	err := s.CallResource(context.Background(), req, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.res)
+	sender.res.Body = []byte(strings.TrimSpace(string(sender.res.Body)))
+	return sender.res
+}
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	if s.res == nil {
+		s.res = res
+	} else {
+		s.res.Body = append(s.res.Body, res.Body...)
+	}
+	return nil
+}
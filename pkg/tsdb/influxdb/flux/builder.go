@@ -254,6 +254,14 @@ func (e maxPointsExceededError) Error() string {
 	return fmt.Sprintf("max data points limit exceeded (count is %d)", e.Count)
 }
 
+type maxSeriesExceededError struct {
+	Count int
+}
+
+func (e maxSeriesExceededError) Error() string {
+	return fmt.Sprintf("max series limit exceeded (count is %d)", e.Count)
+}
+
 func getTableID(record *query.FluxRecord, groupColumns []string) []interface{} {
 	result := make([]interface{}, len(groupColumns))
 
@@ -295,10 +303,12 @@ func isTableIDEqual(id1 []interface{}, id2 []interface{}) bool {
 func (fb *frameBuilder) Append(record *query.FluxRecord) error {
 	table := getTableID(record, fb.groupKeyColumnNames)
 	if (fb.currentGroupKey == nil) || !isTableIDEqual(table, fb.currentGroupKey) {
-		fb.totalSeries++
-		if fb.totalSeries > fb.maxSeries {
-			return fmt.Errorf("results are truncated, max series reached (%d)", fb.maxSeries)
+		// the record is not added when a limit is reached,
+		// so the frames hold the results up to the limit
+		if fb.totalSeries >= fb.maxSeries {
+			return maxSeriesExceededError{Count: fb.maxSeries}
 		}
+		fb.totalSeries++
 
 		// labels have the same value for every row in the same "table",
 		// so we collect them here
@@ -372,6 +382,10 @@ func (fb *frameBuilder) Append(record *query.FluxRecord) error {
 		fb.currentGroupKey = table
 	}
 
+	if fb.active.Fields[0].Len() >= fb.maxPoints {
+		return maxPointsExceededError{Count: fb.maxPoints}
+	}
+
 	for idx, col := range fb.columns {
 		val, err := col.converter.Converter(record.ValueByKey(col.name))
 		if err != nil {
@@ -381,10 +395,5 @@ func (fb *frameBuilder) Append(record *query.FluxRecord) error {
 		fb.active.Fields[idx].Append(val)
 	}
 
-	pointsCount := fb.active.Fields[0].Len()
-	if pointsCount > fb.maxPoints {
-		return maxPointsExceededError{Count: pointsCount}
-	}
-
 	return nil
 }
@@ -14,7 +14,8 @@ import (
 const maxPointsEnforceFactor float64 = 10
 
 // executeQuery runs a flux query using the queryModel to interpolate the query and the runner to execute it.
-// maxSeries somehow limits the response.
+// maxSeries limits the number of series of the response. When a limit is reached, the series read so far
+// are returned with a warning notice.
 func executeQuery(ctx context.Context, query queryModel, runner queryRunner, maxSeries int) (dr backend.DataResponse) {
 	dr = backend.DataResponse{}
 
@@ -22,6 +23,7 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 
 	glog.Debug("Executing Flux query", "flux", flux)
 
+	var notice *data.Notice
 	tables, err := runner.runQuery(ctx, flux)
 	if err != nil {
 		glog.Warn("Flux query failed", "err", err, "query", flux)
@@ -33,11 +35,12 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 		dr = readDataFrames(tables, maxPointsEnforced, maxSeries)
 
 		if dr.Error != nil {
-			// we check if a too-many-data-points error happened, and if it is so,
-			// we improve the error-message.
+			// we check if a limit was reached, and if it is so, we return the partial
+			// results with a notice instead of the error.
 			// (we have to do it in such a complicated way, because at the point where
-			// the error happens, there is not enough info to create a nice error message)
+			// the error happens, there is not enough info to create a nice message)
 			var maxPointError maxPointsExceededError
+			var maxSeriesError maxSeriesExceededError
 			if errors.As(dr.Error, &maxPointError) {
 				text := fmt.Sprintf("A query returned too many datapoints and the results have been truncated at %d points to prevent memory issues. At the current graph size, Grafana can only draw %d.", maxPointError.Count, query.MaxDataPoints)
 				// we recommend to the user to use AggregateWindow(), but only if it is not already used
@@ -45,7 +48,13 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 					text += " Try using the aggregateWindow() function in your query to reduce the number of points returned."
 				}
 
-				dr.Error = fmt.Errorf(text)
+				notice = &data.Notice{Severity: data.NoticeSeverityWarning, Text: text}
+				dr.Error = nil
+			} else if errors.As(dr.Error, &maxSeriesError) {
+				text := fmt.Sprintf("A query returned too many series and the results have been truncated at %d series to prevent memory issues. Try grouping or filtering the results of your query to reduce the number of series returned, or increase the max series of the data source.", maxSeriesError.Count)
+
+				notice = &data.Notice{Severity: data.NoticeSeverityWarning, Text: text}
+				dr.Error = nil
 			}
 		}
 	}
@@ -59,6 +68,9 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 		firstFrame.SetMeta(&data.FrameMeta{})
 	}
 	firstFrame.Meta.ExecutedQueryString = flux
+	if notice != nil {
+		firstFrame.AppendNotices(*notice)
+	}
 	return dr
 }
 
@@ -98,6 +110,13 @@ func readDataFrames(result *api.QueryTableResult, maxPoints int, maxSeries int)
 		}
 	}
 
+	// when the reading stopped early, the rest of the response is not read
+	if dr.Error != nil {
+		if err := result.Close(); err != nil {
+			glog.Warn("Failed to close query result", "err", err)
+		}
+	}
+
 	// Add the inprogress record
 	if builder.frames != nil {
 		for _, frame := range builder.frames {
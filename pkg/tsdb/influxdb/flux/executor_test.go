@@ -247,24 +247,48 @@ func assertDataResponseDimensions(t *testing.T, dr *backend.DataResponse, rows i
 
 func TestMaxDataPointsExceededNoAggregate(t *testing.T) {
 	// unfortunately the golden-response style tests do not support
-	// responses that contain errors, so we can only do manual checks
+	// responses that contain notices, so we can only do manual checks
 	// on the DataResponse
 	dr := executeMockedQuery(t, "max_data_points_exceeded", queryModel{MaxDataPoints: 2})
 
-	// it should contain the error-message
-	require.EqualError(t, dr.Error, "A query returned too many datapoints and the results have been truncated at 21 points to prevent memory issues. At the current graph size, Grafana can only draw 2. Try using the aggregateWindow() function in your query to reduce the number of points returned.")
-	assertDataResponseDimensions(t, dr, 2, 21)
+	// it should contain the partial results with a warning
+	require.NoError(t, dr.Error)
+	require.Equal(t, []data.Notice{{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "A query returned too many datapoints and the results have been truncated at 20 points to prevent memory issues. At the current graph size, Grafana can only draw 2. Try using the aggregateWindow() function in your query to reduce the number of points returned.",
+	}}, dr.Frames[0].Meta.Notices)
+	assertDataResponseDimensions(t, dr, 2, 20)
 }
 
 func TestMaxDataPointsExceededWithAggregate(t *testing.T) {
 	// unfortunately the golden-response style tests do not support
-	// responses that contain errors, so we can only do manual checks
+	// responses that contain notices, so we can only do manual checks
 	// on the DataResponse
 	dr := executeMockedQuery(t, "max_data_points_exceeded", queryModel{RawQuery: "aggregateWindow()", MaxDataPoints: 2})
 
-	// it should contain the error-message
-	require.EqualError(t, dr.Error, "A query returned too many datapoints and the results have been truncated at 21 points to prevent memory issues. At the current graph size, Grafana can only draw 2.")
-	assertDataResponseDimensions(t, dr, 2, 21)
+	// it should contain the partial results with a warning
+	require.NoError(t, dr.Error)
+	require.Equal(t, []data.Notice{{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "A query returned too many datapoints and the results have been truncated at 20 points to prevent memory issues. At the current graph size, Grafana can only draw 2.",
+	}}, dr.Frames[0].Meta.Notices)
+	assertDataResponseDimensions(t, dr, 2, 20)
+}
+
+func TestMaxSeriesExceeded(t *testing.T) {
+	runner := &MockRunner{
+		testDataPath: "grouping.csv",
+	}
+
+	dr := executeQuery(context.Background(), queryModel{MaxDataPoints: 100}, runner, 2)
+
+	// it should contain the series up to the limit with a warning
+	require.NoError(t, dr.Error)
+	require.Len(t, dr.Frames, 2)
+	require.Equal(t, []data.Notice{{
+		Severity: data.NoticeSeverityWarning,
+		Text:     "A query returned too many series and the results have been truncated at 2 series to prevent memory issues. Try grouping or filtering the results of your query to reduce the number of series returned, or increase the max series of the data source.",
+	}}, dr.Frames[0].Meta.Notices)
 }
 
 func TestMultivalue(t *testing.T) {
@@ -30,6 +30,9 @@ func TestSuccessResponse(t *testing.T) {
 		{name: "parse a matrix response with NaN", filepath: "matrix_nan"},
 		// you can produce Infinity by using `quantile_over_time(42,` (value larger than 1)
 		{name: "parse a matrix response with Infinity", filepath: "matrix_inf"},
+		{name: "parse a vector response", filepath: "vector_simple"},
+		// identical lines in the same stream get a different id
+		{name: "parse a streams response", filepath: "streams_simple"},
 	}
 
 	for _, test := range tt {
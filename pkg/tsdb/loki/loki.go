@@ -5,9 +5,12 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -19,8 +22,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/loki/pkg/logcli/client"
 	"github.com/grafana/loki/pkg/loghttp"
-	"github.com/grafana/loki/pkg/logproto"
 	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
@@ -44,6 +47,13 @@ var (
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 )
 
+const (
+	// defaultMaxLines is the line limit used when the data source does not configure one.
+	defaultMaxLines = 1000
+	// maxConcurrentQueries is the number of queries of a request that are sent to Loki at the same time.
+	maxConcurrentQueries = 10
+)
+
 type datasourceInfo struct {
 	HTTPClient        *http.Client
 	URL               string
@@ -51,6 +61,13 @@ type datasourceInfo struct {
 	BasicAuthUser     string
 	BasicAuthPassword string
 	TimeInterval      string `json:"timeInterval"`
+	MaxLines          int
+}
+
+type datasourceJSONData struct {
+	TimeInterval string `json:"timeInterval"`
+	// MaxLines is stored as a string by the data source settings page, older data sources may have a number.
+	MaxLines interface{} `json:"maxLines"`
 }
 
 type QueryModel struct {
@@ -60,6 +77,9 @@ type QueryModel struct {
 	Interval     string `json:"interval"`
 	IntervalMS   int    `json:"intervalMS"`
 	Resolution   int64  `json:"resolution"`
+	Instant      bool   `json:"instant"`
+	MaxLines     int    `json:"maxLines"`
+	Direction    string `json:"direction"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -79,7 +99,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
-		jsonData := datasourceInfo{}
+		jsonData := datasourceJSONData{}
 		err = json.Unmarshal(settings.JSONData, &jsonData)
 		if err != nil {
 			return nil, fmt.Errorf("error reading settings: %w", err)
@@ -92,21 +112,76 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			TimeInterval:      jsonData.TimeInterval,
 			BasicAuthUser:     settings.BasicAuthUser,
 			BasicAuthPassword: settings.DecryptedSecureJSONData["basicAuthPassword"],
+			MaxLines:          parseMaxLinesSetting(jsonData.MaxLines),
 		}
 		return model, nil
 	}
 }
 
+func parseMaxLinesSetting(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		maxLines, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0
+		}
+		return maxLines
+	default:
+		return 0
+	}
+}
+
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
-	queryRes := backend.DataResponse{}
 
 	dsInfo, err := s.getDSInfo(req.PluginContext)
 	if err != nil {
 		return result, err
 	}
 
-	client := &client.DefaultClient{
+	queries, err := parseQuery(dsInfo, req)
+	if err != nil {
+		return result, err
+	}
+
+	var mu sync.Mutex
+	limiter := make(chan struct{}, maxConcurrentQueries)
+	eg, ectx := errgroup.WithContext(ctx)
+	client := newClient(ectx, dsInfo)
+	for _, query := range queries {
+		query := query
+		eg.Go(func() error {
+			select {
+			case limiter <- struct{}{}:
+			case <-ectx.Done():
+				return ectx.Err()
+			}
+			defer func() { <-limiter }()
+
+			frames, err := s.executeQuery(ectx, client, query)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Responses[query.RefID] = backend.DataResponse{Frames: frames}
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// newClient returns a Loki client sending its requests with ctx, so that they are cancelled along with the
+// request of the queries.
+func newClient(ctx context.Context, dsInfo *datasourceInfo) *client.DefaultClient {
+	return &client.DefaultClient{
 		Address:  dsInfo.URL,
 		Username: dsInfo.BasicAuthUser,
 		Password: dsInfo.BasicAuthPassword,
@@ -114,31 +189,30 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			InsecureSkipVerify: dsInfo.TLSClientConfig.InsecureSkipVerify,
 		},
 		Tripperware: func(t http.RoundTripper) http.RoundTripper {
-			return dsInfo.HTTPClient.Transport
+			return &contextRoundTripper{ctx: ctx, next: dsInfo.HTTPClient.Transport}
 		},
 	}
+}
 
-	queries, err := parseQuery(req)
-	if err != nil {
-		return result, err
-	}
+// contextRoundTripper sets the context of the requests of the logcli client, which does not take one.
+type contextRoundTripper struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
 
-	for _, query := range queries {
-		s.plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr)
-		_, span := s.tracer.Start(ctx, "alerting.loki")
-		span.SetAttributes("expr", query.Expr, attribute.Key("expr").String(query.Expr))
-		span.SetAttributes("start_unixnano", query.Start, attribute.Key("start_unixnano").Int64(query.Start.UnixNano()))
-		span.SetAttributes("stop_unixnano", query.End, attribute.Key("stop_unixnano").Int64(query.End.UnixNano()))
-		defer span.End()
-
-		frames, err := runQuery(client, query)
-		if err != nil {
-			return result, err
-		}
-		queryRes.Frames = frames
-		result.Responses[query.RefID] = queryRes
-	}
-	return result, nil
+func (rt *contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.next.RoundTrip(req.WithContext(rt.ctx))
+}
+
+func (s *Service) executeQuery(ctx context.Context, client *client.DefaultClient, query *lokiQuery) (data.Frames, error) {
+	s.plog.Debug("Sending query", "type", query.QueryType, "start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr)
+	_, span := s.tracer.Start(ctx, "alerting.loki")
+	span.SetAttributes("expr", query.Expr, attribute.Key("expr").String(query.Expr))
+	span.SetAttributes("start_unixnano", query.Start, attribute.Key("start_unixnano").Int64(query.Start.UnixNano()))
+	span.SetAttributes("stop_unixnano", query.End, attribute.Key("stop_unixnano").Int64(query.End.UnixNano()))
+	defer span.End()
+
+	return runQuery(client, query)
 }
 
 //If legend (using of name or pattern instead of time series name) is used, use that name/pattern for formatting
@@ -161,24 +235,37 @@ func formatLegend(metric model.Metric, query *lokiQuery) string {
 }
 
 func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames, error) {
-	frames := data.Frames{}
+	switch result := value.Data.Result.(type) {
+	case loghttp.Matrix:
+		return parseMatrix(result, query), nil
+	case loghttp.Vector:
+		return parseVector(result, query), nil
+	case loghttp.Scalar:
+		return parseScalar(result), nil
+	case loghttp.Streams:
+		return parseStreams(result), nil
+	default:
+		return data.Frames{}, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
+	}
+}
 
-	//We are currently processing only matrix results (for alerting)
-	matrix, ok := value.Data.Result.(loghttp.Matrix)
-	if !ok {
-		return frames, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
+func labelsToTags(metric model.Metric) map[string]string {
+	tags := make(map[string]string, len(metric))
+	for k, v := range metric {
+		tags[string(k)] = string(v)
 	}
+	return tags
+}
+
+func parseMatrix(matrix loghttp.Matrix, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
 
 	for _, v := range matrix {
 		name := formatLegend(v.Metric, query)
-		tags := make(map[string]string, len(v.Metric))
+		tags := labelsToTags(v.Metric)
 		timeVector := make([]time.Time, 0, len(v.Values))
 		values := make([]float64, 0, len(v.Values))
 
-		for k, v := range v.Metric {
-			tags[string(k)] = string(v)
-		}
-
 		for _, k := range v.Values {
 			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
 			values = append(values, float64(k.Value))
@@ -189,19 +276,92 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames,
 			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
 	}
 
-	return frames, nil
+	return frames
 }
 
-// we extracted this part of the functionality to make it easy to unit-test it
-func runQuery(client *client.DefaultClient, query *lokiQuery) (data.Frames, error) {
-	// `limit` only applies to log-producing queries, and we
-	// currently only support metric queries, so this can be set to any value.
-	limit := 1
+func parseVector(vector loghttp.Vector, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+
+	for _, v := range vector {
+		name := formatLegend(v.Metric, query)
+		tags := labelsToTags(v.Metric)
 
-	// we do not use `interval`, so we set it to zero
-	interval := time.Duration(0)
+		frames = append(frames, data.NewFrame(name,
+			data.NewField("time", nil, []time.Time{time.Unix(v.Timestamp.Unix(), 0).UTC()}),
+			data.NewField("value", tags, []float64{float64(v.Value)}).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+	}
 
-	value, err := client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+	return frames
+}
+
+func parseScalar(scalar loghttp.Scalar) data.Frames {
+	return data.Frames{data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(scalar.Timestamp.Unix(), 0).UTC()}),
+		data.NewField("value", nil, []float64{float64(scalar.Value)}))}
+}
+
+// parseStreams returns a frame per log stream, with the labels of the stream on the line field.
+func parseStreams(streams loghttp.Streams) data.Frames {
+	frames := data.Frames{}
+	usedIDs := map[string]int{}
+
+	for _, stream := range streams {
+		labels := data.Labels(stream.Labels)
+		labelsText := stream.Labels.String()
+
+		timeVector := make([]time.Time, 0, len(stream.Entries))
+		tsNsVector := make([]string, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+		ids := make([]string, 0, len(stream.Entries))
+
+		for _, entry := range stream.Entries {
+			tsNs := strconv.FormatInt(entry.Timestamp.UnixNano(), 10)
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			tsNsVector = append(tsNsVector, tsNs)
+			lines = append(lines, entry.Line)
+			ids = append(ids, makeLineID(usedIDs, tsNs, labelsText, entry.Line))
+		}
+
+		frame := data.NewFrame(labelsText,
+			data.NewField("ts", nil, timeVector),
+			data.NewField("line", labels, lines),
+			data.NewField("id", nil, ids),
+			data.NewField("tsNs", nil, tsNsVector))
+		frame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeLogs})
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+// makeLineID returns an id for a log line that is stable between requests,
+// identical lines of the same stream and timestamp get a numeric suffix.
+func makeLineID(usedIDs map[string]int, tsNs string, labels string, line string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(labels))
+	_, _ = h.Write([]byte(line))
+	id := fmt.Sprintf("%s_%x", tsNs, h.Sum64())
+
+	count := usedIDs[id]
+	usedIDs[id] = count + 1
+	if count > 0 {
+		return fmt.Sprintf("%s_%d", id, count)
+	}
+	return id
+}
+
+// we extracted this part of the functionality to make it easy to unit-test it
+func runQuery(client *client.DefaultClient, query *lokiQuery) (data.Frames, error) {
+	var value *loghttp.QueryResponse
+	var err error
+
+	if query.QueryType == QueryTypeInstant {
+		value, err = client.Query(query.Expr, query.MaxLines, query.End, query.Direction, false)
+	} else {
+		// we do not use `interval`, so we set it to zero
+		interval := time.Duration(0)
+		value, err = client.QueryRange(query.Expr, query.MaxLines, query.Start, query.End, query.Direction, query.Step, interval, false)
+	}
 	if err != nil {
 		return data.Frames{}, err
 	}
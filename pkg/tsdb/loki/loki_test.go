@@ -1,11 +1,23 @@
 package loki
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/loki/pkg/loghttp"
 	p "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
@@ -41,11 +53,11 @@ func TestLoki(t *testing.T) {
 }
 
 func TestParseResponse(t *testing.T) {
-	t.Run("value is not of type matrix", func(t *testing.T) {
+	t.Run("value is of an unsupported type", func(t *testing.T) {
 		queryRes := data.Frames{}
 		value := loghttp.QueryResponse{
 			Data: loghttp.QueryResponseData{
-				Result: loghttp.Vector{},
+				ResultType: "unknown",
 			},
 		}
 		res, err := parseResponse(&value, nil)
@@ -53,6 +65,30 @@ func TestParseResponse(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("vector response should be parsed normally", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Vector{
+					p.Sample{
+						Metric:    p.Metric{"app": "Application"},
+						Value:     42,
+						Timestamp: 1000,
+					},
+				},
+			},
+		}
+
+		query := &lokiQuery{
+			LegendFormat: "legend {{app}}",
+		}
+		frames, err := parseResponse(&value, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, "legend Application", frames[0].Name)
+		require.Equal(t, time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC), frames[0].Fields[0].At(0))
+		require.Equal(t, float64(42), frames[0].Fields[1].At(0))
+	})
+
 	t.Run("response should be parsed normally", func(t *testing.T) {
 		values := []p.SamplePair{
 			{Value: 1, Timestamp: 1000},
@@ -96,3 +132,109 @@ func TestParseResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestQueryData(t *testing.T) {
+	newService := func(t *testing.T, handler http.HandlerFunc) *Service {
+		t.Helper()
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		tracer, err := tracing.InitializeTracerForTest()
+		require.NoError(t, err)
+		return &Service{
+			im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+				return &datasourceInfo{HTTPClient: server.Client(), URL: server.URL, TLSClientConfig: &tls.Config{}}, nil
+			}),
+			plog:   log.New("tsdb.loki"),
+			tracer: tracer,
+		}
+	}
+
+	newRequest := func(exprs ...string) *backend.QueryDataRequest {
+		req := &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+		}
+		for i, expr := range exprs {
+			req.Queries = append(req.Queries, backend.DataQuery{
+				RefID:     string(rune('A' + i)),
+				JSON:      []byte(fmt.Sprintf(`{"expr": %q, "instant": true}`, expr)),
+				TimeRange: backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()},
+				Interval:  time.Minute,
+			})
+		}
+		return req
+	}
+
+	vectorResponse := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"app":"backend"},"value":[1639125366.989,"1"]}]}}`))
+	}
+
+	t.Run("queries are executed concurrently", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		service := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+
+			vectorResponse(w)
+		})
+
+		expr := `count_over_time({app="backend"}[1m])`
+		res, err := service.QueryData(context.Background(), newRequest(expr, expr, expr))
+		require.NoError(t, err)
+		require.Len(t, res.Responses, 3)
+		for _, refID := range []string{"A", "B", "C"} {
+			require.NoError(t, res.Responses[refID].Error)
+			require.Len(t, res.Responses[refID].Frames, 1)
+		}
+		require.Greater(t, maxInFlight, 1)
+	})
+
+	t.Run("a failing query fails the request", func(t *testing.T) {
+		service := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Query().Get("query"), "broken") {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte("parse error"))
+				return
+			}
+			vectorResponse(w)
+		})
+
+		_, err := service.QueryData(context.Background(), newRequest(`count_over_time({app="backend"}[1m])`, "broken"))
+		require.Error(t, err)
+	})
+
+	t.Run("cancelling the request cancels the queries sent to Loki", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		service := newService(t, func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-time.After(5 * time.Second):
+				vectorResponse(w)
+			}
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := service.QueryData(ctx, newRequest(`count_over_time({app="backend"}[1m])`))
+		require.Error(t, err)
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("the query sent to Loki was not cancelled")
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/loki/pkg/logproto"
 )
 
 const (
@@ -52,7 +53,35 @@ func interpolateVariables(expr string, interval time.Duration, timeRange time.Du
 	return expr
 }
 
-func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
+// parseQueryType returns the instant query type when the query model asks for it, range otherwise.
+func parseQueryType(model *QueryModel) QueryType {
+	if model.Instant || QueryType(model.QueryType) == QueryTypeInstant {
+		return QueryTypeInstant
+	}
+	return QueryTypeRange
+}
+
+// parseDirection returns the direction in which log lines are returned, newest first unless asked otherwise.
+func parseDirection(direction string) logproto.Direction {
+	if strings.EqualFold(direction, logproto.FORWARD.String()) {
+		return logproto.FORWARD
+	}
+	return logproto.BACKWARD
+}
+
+// parseMaxLines returns the line limit of a query, which can never exceed the limit of the data source.
+func parseMaxLines(model *QueryModel, dsInfo *datasourceInfo) int {
+	maxLines := dsInfo.MaxLines
+	if maxLines <= 0 {
+		maxLines = defaultMaxLines
+	}
+	if model.MaxLines > 0 && model.MaxLines < maxLines {
+		return model.MaxLines
+	}
+	return maxLines
+}
+
+func parseQuery(dsInfo *datasourceInfo, queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 	qs := []*lokiQuery{}
 	for _, query := range queryContext.Queries {
 		model := &QueryModel{}
@@ -78,7 +107,10 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 
 		qs = append(qs, &lokiQuery{
 			Expr:         expr,
+			QueryType:    parseQueryType(model),
 			Step:         step,
+			MaxLines:     parseMaxLines(model, dsInfo),
+			Direction:    parseDirection(model.Direction),
 			LegendFormat: model.LegendFormat,
 			Start:        start,
 			End:          end,
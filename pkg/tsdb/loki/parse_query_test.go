@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/loki/pkg/logproto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,10 +30,47 @@ func TestParseQuery(t *testing.T) {
 				},
 			},
 		}
-		models, err := parseQuery(queryContext)
+		models, err := parseQuery(&datasourceInfo{}, queryContext)
 		require.NoError(t, err)
 		require.Equal(t, time.Second*15, models[0].Step)
 		require.Equal(t, "go_goroutines 15s 15000 3000s 3000 3000000", models[0].Expr)
+		require.Equal(t, QueryTypeRange, models[0].QueryType)
+		require.Equal(t, defaultMaxLines, models[0].MaxLines)
+		require.Equal(t, logproto.BACKWARD, models[0].Direction)
+	})
+	t.Run("parsing instant log query model", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`
+					{
+						"expr": "{app=\"backend\"}",
+						"instant": true,
+						"maxLines": 50,
+						"direction": "FORWARD",
+						"refId": "A"
+					}`,
+					),
+					TimeRange: backend.TimeRange{
+						From: time.Now().Add(-3000 * time.Second),
+						To:   time.Now(),
+					},
+					Interval: time.Second * 15,
+				},
+			},
+		}
+		models, err := parseQuery(&datasourceInfo{MaxLines: 100}, queryContext)
+		require.NoError(t, err)
+		require.Equal(t, QueryTypeInstant, models[0].QueryType)
+		require.Equal(t, 50, models[0].MaxLines)
+		require.Equal(t, logproto.FORWARD, models[0].Direction)
+	})
+	t.Run("max lines of a query can not exceed the data source limit", func(t *testing.T) {
+		require.Equal(t, 100, parseMaxLines(&QueryModel{MaxLines: 5000}, &datasourceInfo{MaxLines: 100}))
+		require.Equal(t, defaultMaxLines, parseMaxLines(&QueryModel{}, &datasourceInfo{}))
+		require.Equal(t, 20, parseMaxLinesSetting("20"))
+		require.Equal(t, 20, parseMaxLinesSetting(float64(20)))
+		require.Equal(t, 0, parseMaxLinesSetting("twenty"))
 	})
 	t.Run("interpolate variables, range between 1s and 0.5s", func(t *testing.T) {
 		expr := "go_goroutines $__interval $__interval_ms $__range $__range_s $__range_ms"
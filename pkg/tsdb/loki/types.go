@@ -1,10 +1,24 @@
 package loki
 
-import "time"
+import (
+	"time"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+type QueryType string
+
+const (
+	QueryTypeRange   QueryType = "range"
+	QueryTypeInstant QueryType = "instant"
+)
 
 type lokiQuery struct {
 	Expr         string
+	QueryType    QueryType
 	Step         time.Duration
+	MaxLines     int
+	Direction    logproto.Direction
 	LegendFormat string
 	Start        time.Time
 	End          time.Time
@@ -82,12 +82,16 @@ func newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
 		if cfg.Env == setting.Dev {
 			logger.Debug("getEngine", "connection", cnnstr)
 		}
+		// The driver aborts the batch in the server when the query context is cancelled, which is
+		// enough to enforce the query timeout without a governor.
 		config := sqleng.DataPluginConfiguration{
 			DriverName:        "mssql",
 			ConnectionString:  cnnstr,
 			DSInfo:            dsInfo,
 			MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			ByteLimit:         cfg.DataProxyByteLimit,
+			ParamPlaceholder:  func(position int) string { return fmt.Sprintf("@p%d", position) },
 		}
 
 		queryResultTransformer := mssqlQueryResultTransformer{
@@ -116,6 +116,8 @@ func newInstanceSettings(cfg *setting.Cfg, httpClientProvider httpclient.Provide
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			ByteLimit:         cfg.DataProxyByteLimit,
+			QueryGovernor:     mysqlQueryGovernor{},
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{
@@ -150,6 +152,9 @@ type mysqlQueryResultTransformer struct {
 func (t *mysqlQueryResultTransformer) TransformQueryError(err error) error {
 	var driverErr *mysql.MySQLError
 	if errors.As(err, &driverErr) {
+		if driverErr.Number == mysqlerr.ER_QUERY_TIMEOUT {
+			return sqleng.ErrQueryTimeout
+		}
 		if driverErr.Number != mysqlerr.ER_PARSE_ERROR && driverErr.Number != mysqlerr.ER_BAD_FIELD_ERROR &&
 			driverErr.Number != mysqlerr.ER_NO_SUCH_TABLE {
 			t.log.Error("query error", "err", err)
@@ -162,6 +167,22 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(err error) error {
 
 var errQueryFailed = errors.New("query failed - please inspect Grafana server log for details")
 
+// mysqlQueryGovernor limits SELECT statements with max_execution_time, which MariaDB does not support.
+// The driver only closes the connection when a query is cancelled, so the query is killed explicitly.
+type mysqlQueryGovernor struct{}
+
+func (mysqlQueryGovernor) StatementTimeout(timeout time.Duration) string {
+	return fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())
+}
+
+func (mysqlQueryGovernor) ConnectionIDQuery() string {
+	return "SELECT CONNECTION_ID()"
+}
+
+func (mysqlQueryGovernor) KillQuery(connectionID string) string {
+	return "KILL QUERY " + connectionID
+}
+
 func (t *mysqlQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	// For the MySQL driver , we have these possible data types:
 	// https://www.w3schools.com/sql/sql_datatypes.asp#:~:text=In%20MySQL%20there%20are%20three,numeric%2C%20and%20date%20and%20time.
@@ -0,0 +1,105 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// queryExpression runs a query of the expression API, /api/query/exp, which is available since OpenTSDB 2.3.
+// The expression of the query holds the filters, metrics, expressions and outputs of the request. Its time
+// is set to the time range of the query.
+func (s *Service) queryExpression(ctx context.Context, dsInfo *datasourceInfo, query backend.DataQuery) (data.Frames, error) {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	expression, err := buildExpression(query, model)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := s.createPostRequest(dsInfo, "api/query/exp", expression)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parseExpressionResponse(res)
+}
+
+func buildExpression(query backend.DataQuery, model *simplejson.Json) (map[string]interface{}, error) {
+	expression, err := model.Get("expression").Map()
+	if err != nil {
+		return nil, fmt.Errorf("expression query has no expression")
+	}
+
+	timeSpan, ok := expression["time"].(map[string]interface{})
+	if !ok {
+		timeSpan = make(map[string]interface{})
+	}
+	timeSpan["start"] = query.TimeRange.From.UnixNano() / int64(time.Millisecond)
+	timeSpan["end"] = query.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	if _, ok := timeSpan["aggregator"]; !ok {
+		timeSpan["aggregator"] = "avg"
+	}
+	expression["time"] = timeSpan
+
+	return expression, nil
+}
+
+func (s *Service) parseExpressionResponse(res *http.Response) (data.Frames, error) {
+	body, err := s.readResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var response OpenTsdbExpressionResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		s.logger.Info("Failed to unmarshal opentsdb expression response", "error", err, "status", res.Status, "body", string(body))
+		return nil, err
+	}
+
+	frames := data.Frames{}
+	for _, output := range response.Outputs {
+		name := output.Alias
+		if name == "" {
+			name = output.ID
+		}
+
+		// The first series of the meta data is the timestamp.
+		for _, meta := range output.Meta {
+			if meta.Index == 0 {
+				continue
+			}
+
+			points := make([]point, 0, len(output.DataPoints))
+			for _, row := range output.DataPoints {
+				if len(row) <= meta.Index || row[0].Value == nil {
+					return nil, fmt.Errorf("invalid data points of output %q", output.ID)
+				}
+				timestamp := int64(*row[0].Value)
+				points = append(points, point{time.Unix(0, timestamp*int64(time.Millisecond)).UTC(), row[meta.Index].Value})
+			}
+
+			frame := newFrame(name, points, meta.CommonTags)
+			if len(meta.AggregatedTags) > 0 {
+				frame.Meta = &data.FrameMeta{Custom: &frameMeta{AggregateTags: meta.AggregatedTags}}
+			}
+			frames = append(frames, frame)
+		}
+	}
+	return frames, nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
@@ -24,24 +26,37 @@ import (
 )
 
 type Service struct {
-	logger log.Logger
-	im     instancemgmt.InstanceManager
+	logger          log.Logger
+	im              instancemgmt.InstanceManager
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		logger: log.New("tsdb.opentsdb"),
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
+// expressionQueryType is the query type of queries of the expression API.
+const expressionQueryType = "expression"
+
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient   *http.Client
+	URL          string
+	TSDBVersion  int
+	MsResolution bool
 }
 
 type DsAccess string
 
+type jsonData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
+}
+
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
 	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions()
@@ -54,9 +69,18 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := jsonData{TSDBVersion: 1, TSDBResolution: 1}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:   client,
+			URL:          settings.URL,
+			TSDBVersion:  jsonData.TSDBVersion,
+			MsResolution: jsonData.TSDBResolution == 2,
 		}
 
 		return model, nil
@@ -64,16 +88,39 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
+	dsInfo, err := s.getDSInfo(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	q := req.Queries[0]
+	result := backend.NewQueryDataResponse()
+	for _, query := range req.Queries {
+		var frames data.Frames
+		var err error
+		if query.QueryType == expressionQueryType {
+			frames, err = s.queryExpression(ctx, dsInfo, query)
+		} else {
+			frames, err = s.queryMetric(ctx, dsInfo, query)
+		}
+		result.Responses[query.RefID] = backend.DataResponse{Frames: frames, Error: err}
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	return result, nil
+}
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+// queryMetric runs a query of a single metric with the query API, /api/query.
+func (s *Service) queryMetric(ctx context.Context, dsInfo *datasourceInfo, query backend.DataQuery) (data.Frames, error) {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	tsdbQuery := OpenTsdbQuery{
+		Start:             query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+		End:               query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+		Queries:           []map[string]interface{}{s.buildMetric(query, dsInfo)},
+		MsResolution:      dsInfo.MsResolution,
+		GlobalAnnotations: model.Get("globalAnnotations").MustBool(),
 	}
 
 	// TODO: Don't use global variable
@@ -81,35 +128,29 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		s.logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
-
-	result, err := s.parseResponse(res)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
-	return result, nil
+	return s.parseResponse(res, dsInfo.MsResolution)
 }
 
 func (s *Service) createRequest(dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
+	return s.createPostRequest(dsInfo, "api/query", data)
+}
+
+func (s *Service) createPostRequest(dsInfo *datasourceInfo, endpoint string, data interface{}) (*http.Request, error) {
 	u, err := url.Parse(dsInfo.URL)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = path.Join(u.Path, "api/query")
+	u.Path = path.Join(u.Path, endpoint)
 
 	postData, err := json.Marshal(data)
 	if err != nil {
@@ -127,9 +168,8 @@ func (s *Service) createRequest(dsInfo *datasourceInfo, data OpenTsdbQuery) (*ht
 	return req, nil
 }
 
-func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse, error) {
-	resp := backend.NewQueryDataResponse()
-
+// readResponse reads the body of a response, which must be successful.
+func (s *Service) readResponse(res *http.Response) ([]byte, error) {
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -142,9 +182,22 @@ func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse,
 
 	if res.StatusCode/100 != 2 {
 		s.logger.Info("Request failed", "status", res.Status, "body", string(body))
+		var errorResponse OpenTsdbErrorResponse
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+			return nil, fmt.Errorf("request failed, status: %s, error: %s", res.Status, errorResponse.Error.Message)
+		}
 		return nil, fmt.Errorf("request failed, status: %s", res.Status)
 	}
 
+	return body, nil
+}
+
+func (s *Service) parseResponse(res *http.Response, msResolution bool) (data.Frames, error) {
+	body, err := s.readResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
 	var responseData []OpenTsdbResponse
 	err = json.Unmarshal(body, &responseData)
 	if err != nil {
@@ -154,30 +207,71 @@ func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse,
 
 	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-
+		points := make([]point, 0, len(val.DataPoints))
 		for timeString, value := range val.DataPoints {
 			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				s.logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			if msResolution {
+				points = append(points, point{time.Unix(0, timestamp*int64(time.Millisecond)).UTC(), value.Value})
+			} else {
+				points = append(points, point{time.Unix(timestamp, 0).UTC(), value.Value})
+			}
+		}
+
+		frame := newFrame(val.Metric, points, val.Tags)
+		if len(val.AggregateTags) > 0 || len(val.Annotations) > 0 || len(val.GlobalAnnotations) > 0 {
+			frame.Meta = &data.FrameMeta{
+				Custom: &frameMeta{
+					AggregateTags:     val.AggregateTags,
+					Annotations:       val.Annotations,
+					GlobalAnnotations: val.GlobalAnnotations,
+				},
+			}
 		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", nil, values)))
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// frameMeta is the custom metadata of the frames of a query.
+type frameMeta struct {
+	AggregateTags     []string             `json:"aggregateTags,omitempty"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations,omitempty"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations,omitempty"`
+}
+
+type point struct {
+	time  time.Time
+	value *float64
+}
+
+// newFrame returns a frame of the points, which are sorted by time.
+func newFrame(name string, points []point, tags map[string]string) *data.Frame {
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].time.Before(points[j].time)
+	})
+
+	timeVector := make([]time.Time, 0, len(points))
+	values := make([]*float64, 0, len(points))
+	for _, p := range points {
+		timeVector = append(timeVector, p.time)
+		values = append(values, p.value)
+	}
+
+	var labels data.Labels
+	if len(tags) > 0 {
+		labels = data.Labels(tags)
 	}
-	result := resp.Responses["A"]
-	result.Frames = frames
-	resp.Responses["A"] = result
-	return resp, nil
+
+	return data.NewFrame(name,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", labels, values))
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
+func (s *Service) buildMetric(query backend.DataQuery, dsInfo *datasourceInfo) map[string]interface{} {
 	metric := make(map[string]interface{})
 
 	model, err := simplejson.NewJson(query.JSON)
@@ -187,18 +281,19 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 
 	// Setting metric and aggregator
 	metric["metric"] = model.Get("metric").MustString()
-	metric["aggregator"] = model.Get("aggregator").MustString()
+	metric["aggregator"] = model.Get("aggregator").MustString("avg")
 
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
 	if !disableDownsampling {
 		downsampleInterval := model.Get("downsampleInterval").MustString()
 		if downsampleInterval == "" {
-			downsampleInterval = "1m" // default value for blank
+			downsampleInterval = formatInterval(query.Interval)
 		}
-		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
+		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString("avg")
+		fillPolicy := model.Get("downsampleFillPolicy").MustString()
+		if fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -208,40 +303,80 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 	if model.Get("shouldComputeRate").MustBool() {
 		metric["rate"] = true
 		rateOptions := make(map[string]interface{})
-		rateOptions["counter"] = model.Get("isCounter").MustBool()
+		isCounter := model.Get("isCounter").MustBool()
+		rateOptions["counter"] = isCounter
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
-		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
-		}
+		if isCounter {
+			counterMax, counterMaxCheck := numberValue(model, "counterMax")
+			if counterMaxCheck {
+				rateOptions["counterMax"] = counterMax
+			}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
-		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
-		}
+			resetValue, resetValueCheck := numberValue(model, "counterResetValue")
+			if resetValueCheck {
+				rateOptions["resetValue"] = resetValue
+			}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
-			rateOptions["dropResets"] = true
+			// Resets can only be dropped since OpenTSDB 2.2.
+			if dsInfo.TSDBVersion >= 2 {
+				if dropResets, ok := model.CheckGet("dropResets"); ok {
+					rateOptions["dropResets"] = dropResets.MustBool()
+				} else if !counterMaxCheck && resetValue == 0 {
+					rateOptions["dropResets"] = true
+				}
+			}
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
-	}
-
-	// Setting filters
+	// Setting filters, which replace the tags since OpenTSDB 2.2
 	filters, filtersCheck := model.CheckGet("filters")
 	if filtersCheck && len(filters.MustArray()) > 0 {
 		metric["filters"] = filters.MustArray()
+	} else {
+		tags, tagsCheck := model.CheckGet("tags")
+		if tagsCheck && len(tags.MustMap()) > 0 {
+			metric["tags"] = tags.MustMap()
+		}
+	}
+
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
 }
 
+// numberValue returns the number of the key of the model. The query editor stores numbers as strings.
+func numberValue(model *simplejson.Json, key string) (float64, bool) {
+	value, ok := model.CheckGet(key)
+	if !ok {
+		return 0, false
+	}
+
+	if str, err := value.String(); err == nil {
+		number, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		return number, err == nil
+	}
+
+	number, err := value.Float64()
+	return number, err == nil
+}
+
+// formatInterval formats the interval of a query as a downsampling interval, which is a minute if the query has no
+// interval.
+func formatInterval(interval time.Duration) string {
+	switch {
+	case interval <= 0:
+		return "1m"
+	case interval%time.Second == 0:
+		return fmt.Sprintf("%ds", interval/time.Second)
+	default:
+		return fmt.Sprintf("%dms", interval/time.Millisecond)
+	}
+}
+
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(pluginCtx)
 	if err != nil {
@@ -255,3 +390,7 @@ func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, e
 
 	return instance, nil
 }
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
@@ -2,6 +2,7 @@ package opentsdb
 
 import (
 	"io/ioutil"
+	"math"
 	"net/http"
 	"strings"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +21,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	service := &Service{
 		logger: log.New("test"),
 	}
+	dsInfo := &datasourceInfo{TSDBVersion: 1}
 
 	t.Run("create request", func(t *testing.T) {
 		req, err := service.createRequest(&datasourceInfo{}, OpenTsdbQuery{})
@@ -35,7 +38,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(&http.Response{Body: ioutil.NopCloser(strings.NewReader(response))})
+		result, err := service.parseResponse(&http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}, false)
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -55,18 +58,16 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			data.NewField("time", nil, []time.Time{
 				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
 			}),
-			data.NewField("value", nil, []float64{
-				50}),
+			data.NewField("value", nil, []*float64{
+				pointer(50)}),
 		)
 
 		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(&resp)
+		frames, err := service.parseResponse(&resp, false)
 		require.NoError(t, err)
 
-		frame := result.Responses["A"]
-
-		if diff := cmp.Diff(testFrame, frame.Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+		if diff := cmp.Diff(testFrame, frames[0], data.FrameTestCompareOptions()...); diff != "" {
 			t.Errorf("Result mismatch (-want +got):\n%s", diff)
 		}
 	})
@@ -85,7 +86,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, dsInfo)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -107,7 +108,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, dsInfo)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -128,7 +129,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, dsInfo)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -154,7 +155,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, dsInfo)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -185,7 +186,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, dsInfo)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -220,7 +221,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, dsInfo)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -239,4 +240,181 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric with counter options of the query editor", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "net.bytes",
+						"aggregator": "sum",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true,
+						"counterMax": "65535",
+						"counterResetValue": ""
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, dsInfo)
+
+		require.Equal(t, map[string]interface{}{"counter": true, "counterMax": float64(65535)}, metric["rateOptions"])
+	})
+
+	t.Run("Build metric drops counter resets since OpenTSDB 2.2", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "net.bytes",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, &datasourceInfo{TSDBVersion: 2})
+		require.Equal(t, map[string]interface{}{"counter": true, "dropResets": true}, metric["rateOptions"])
+
+		metric = service.buildMetric(query, dsInfo)
+		require.Equal(t, map[string]interface{}{"counter": true}, metric["rateOptions"])
+
+		query.JSON = []byte(`{"metric": "net.bytes", "shouldComputeRate": true, "isCounter": true, "dropResets": false}`)
+		metric = service.buildMetric(query, &datasourceInfo{TSDBVersion: 2})
+		require.Equal(t, false, metric["rateOptions"].(map[string]interface{})["dropResets"])
+	})
+
+	t.Run("Build metric with filters and explicit tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			Interval: 500 * time.Millisecond,
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"downsampleAggregator": "max",
+						"downsampleFillPolicy": "",
+						"explicitTags": true,
+						"tags": {
+							"env": "prod"
+						},
+						"filters": [
+							{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true}
+						]
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, dsInfo)
+
+		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, "500ms-max", metric["downsample"])
+		require.Nil(t, metric["tags"])
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true},
+		}, metric["filters"])
+		require.Equal(t, true, metric["explicitTags"])
+	})
+
+	t.Run("Parse response with tags, fill values and annotations", func(t *testing.T) {
+		response := `
+		[
+			{
+				"metric": "test",
+				"tags": {"host": "web01"},
+				"aggregateTags": ["dc"],
+				"dps": {
+					"1405544146100": "NaN",
+					"1405544146000": 50.0,
+					"1405544146200": null
+				},
+				"annotations": [{"description": "deploy", "startTime": 1405544146}]
+			}
+		]`
+
+		resp := http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+		frames, err := service.parseResponse(&resp, true)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		require.Equal(t, time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC), frame.Fields[0].At(0))
+		require.Equal(t, time.Date(2014, 7, 16, 20, 55, 46, int(200*time.Millisecond), time.UTC), frame.Fields[0].At(2))
+		require.Equal(t, data.Labels{"host": "web01"}, frame.Fields[1].Labels)
+		require.Equal(t, 50.0, *frame.Fields[1].At(0).(*float64))
+		require.True(t, math.IsNaN(*frame.Fields[1].At(1).(*float64)))
+		require.Nil(t, frame.Fields[1].At(2))
+		require.Equal(t, &frameMeta{
+			AggregateTags: []string{"dc"},
+			Annotations:   []OpenTsdbAnnotation{{Description: "deploy", StartTime: 1405544146}},
+		}, frame.Meta.Custom)
+	})
+
+	t.Run("Parse response should return the error of OpenTSDB", func(t *testing.T) {
+		response := `{"error": {"code": 400, "message": "No such name for 'metrics': 'unknown'"}}`
+
+		resp := http.Response{StatusCode: 400, Status: "400 Bad Request", Body: ioutil.NopCloser(strings.NewReader(response))}
+		_, err := service.parseResponse(&resp, false)
+		require.EqualError(t, err, "request failed, status: 400 Bad Request, error: No such name for 'metrics': 'unknown'")
+	})
+
+	t.Run("Build expression with the time range of the query", func(t *testing.T) {
+		query := backend.DataQuery{
+			TimeRange: backend.TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)},
+			JSON: []byte(`
+					{
+						"expression": {
+							"time": {"aggregator": "sum", "start": "1h-ago"},
+							"metrics": [{"id": "a", "metric": "sys.cpu.user"}],
+							"expressions": [{"id": "e", "expr": "a * 2"}]
+						}
+					}`,
+			),
+		}
+		model, err := simplejson.NewJson(query.JSON)
+		require.NoError(t, err)
+
+		expression, err := buildExpression(query, model)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"start": int64(1000000), "end": int64(2000000), "aggregator": "sum"}, expression["time"])
+		require.Len(t, expression["metrics"], 1)
+
+		_, err = buildExpression(query, simplejson.New())
+		require.Error(t, err)
+	})
+
+	t.Run("Parse expression response", func(t *testing.T) {
+		response := `
+		{
+			"outputs": [
+				{
+					"id": "e",
+					"alias": "doubled",
+					"dps": [[1405544146000, 1, 2], [1405544147000, "NaN", null]],
+					"meta": [
+						{"index": 0, "metrics": ["timestamp"]},
+						{"index": 1, "metrics": ["sys.cpu.user"], "commonTags": {"host": "web01"}, "aggregatedTags": []},
+						{"index": 2, "metrics": ["sys.cpu.user"], "commonTags": {"host": "web02"}, "aggregatedTags": ["cpu"]}
+					]
+				}
+			]
+		}`
+
+		resp := http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+		frames, err := service.parseExpressionResponse(&resp)
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		require.Equal(t, "doubled", frames[0].Name)
+		require.Equal(t, data.Labels{"host": "web01"}, frames[0].Fields[1].Labels)
+		require.Equal(t, 1.0, *frames[0].Fields[1].At(0).(*float64))
+		require.Equal(t, time.Date(2014, 7, 16, 20, 55, 47, 0, time.UTC), frames[0].Fields[0].At(1))
+		require.Nil(t, frames[0].Meta)
+
+		require.Equal(t, data.Labels{"host": "web02"}, frames[1].Fields[1].Labels)
+		require.Nil(t, frames[1].Fields[1].At(1))
+		require.Equal(t, &frameMeta{AggregateTags: []string{"cpu"}}, frames[1].Meta.Custom)
+	})
+}
+
+func pointer(value float64) *float64 {
+	return &value
 }
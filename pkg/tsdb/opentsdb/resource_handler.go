@@ -0,0 +1,101 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handleResourceReq("api/suggest", "type", "q", "max"))
+	mux.HandleFunc("/api/search/lookup", s.handleResourceReq("api/search/lookup", "m", "limit", "useMeta"))
+	return mux
+}
+
+// handleResourceReq forwards the request to the OpenTSDB endpoint with the given query parameters of the request.
+func (s *Service) handleResourceReq(endpoint string, params ...string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeResponse(rw, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("unexpected error %v", err))
+			return
+		}
+
+		query := url.Values{}
+		for _, param := range params {
+			if values, ok := req.URL.Query()[param]; ok {
+				query[param] = values
+			}
+		}
+
+		if err := s.doResourceRequest(req.Context(), rw, dsInfo, endpoint, query); err != nil {
+			writeResponse(rw, http.StatusBadGateway, fmt.Sprintf("unexpected error %v", err))
+		}
+	}
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, rw http.ResponseWriter, dsInfo *datasourceInfo, endpoint string, query url.Values) error {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Resource request failed", "endpoint", endpoint, "status", res.Status, "body", string(body))
+	}
+
+	// like the data proxy, an authentication failure of OpenTSDB is a bad request, which the frontend does not take
+	// for an expired session of the user
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("Authentication to data source failed: %s", body))
+		return nil
+	}
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "" {
+		rw.Header().Set("Content-Type", contentType)
+	}
+	rw.WriteHeader(res.StatusCode)
+	if _, err := rw.Write(body); err != nil {
+		s.logger.Error("Unable to write HTTP response", "error", err)
+	}
+	return nil
+}
+
+func writeResponse(rw http.ResponseWriter, code int, msg string) {
+	rw.WriteHeader(code)
+	_, _ = rw.Write([]byte(msg))
+}
@@ -0,0 +1,157 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResources(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	opentsdb := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req)
+		switch req.URL.Path {
+		case "/opentsdb/api/suggest":
+			rw.Header().Set("Content-Type", "application/json")
+			_, _ = rw.Write([]byte(`["sys.cpu.user"]`))
+		case "/opentsdb/api/search/lookup":
+			_, _ = rw.Write([]byte(`{"results": [{"tags": {"host": "web01"}}]}`))
+		case "/opentsdb/api/query":
+			var body map[string]interface{}
+			b, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(b, &body))
+			bodies = append(bodies, body)
+			if body["queries"].([]interface{})[0].(map[string]interface{})["metric"] == "unknown" {
+				rw.WriteHeader(http.StatusBadRequest)
+				_, _ = rw.Write([]byte(`{"error": {"code": 400, "message": "No such name for 'metrics': 'unknown'"}}`))
+				return
+			}
+			_, _ = rw.Write([]byte(`[{"metric": "sys.cpu.user", "tags": {}, "dps": {"1405544146000": 1}}]`))
+		case "/denied/api/suggest":
+			rw.WriteHeader(http.StatusForbidden)
+			_, _ = rw.Write([]byte("access denied"))
+		case "/opentsdb/api/query/exp":
+			_, _ = rw.Write([]byte(`{"outputs": [{"id": "e", "dps": [[1405544146000, 2]], "meta": [{"index": 0}, {"index": 1}]}]}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(opentsdb.Close)
+
+	s := ProvideService(httpclient.NewProvider())
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			ID:       1,
+			URL:      opentsdb.URL + "/opentsdb",
+			JSONData: []byte(`{"tsdbVersion": 3, "tsdbResolution": 2}`),
+		},
+	}
+
+	t.Run("suggest forwards the suggest parameters", func(t *testing.T) {
+		requests = nil
+		res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/suggest",
+			URL:    "api/suggest?type=metrics&q=sys&max=1000&other=1",
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["sys.cpu.user"]`, string(res.Body))
+		require.Equal(t, []string{"application/json"}, res.Headers["Content-Type"])
+		require.Len(t, requests, 1)
+		require.Equal(t, url.Values{"type": {"metrics"}, "q": {"sys"}, "max": {"1000"}}, requests[0].URL.Query())
+	})
+
+	t.Run("lookup forwards the tag value lookup", func(t *testing.T) {
+		requests = nil
+		res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/search/lookup",
+			URL:    "api/search/lookup?m=sys.cpu.user%7Bhost%3D*%7D&limit=1000",
+		})
+
+		require.Equal(t, http.StatusOK, res.Status)
+		require.Equal(t, url.Values{"m": {"sys.cpu.user{host=*}"}, "limit": {"1000"}}, requests[0].URL.Query())
+	})
+
+	t.Run("authentication failures of OpenTSDB are bad requests", func(t *testing.T) {
+		denied := backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 2, URL: opentsdb.URL + "/denied"},
+		}
+		res := callResource(t, s, denied, &backend.CallResourceRequest{Method: http.MethodGet, Path: "api/suggest", URL: "api/suggest?q=sys"})
+		require.Equal(t, http.StatusBadRequest, res.Status)
+		require.Equal(t, "Authentication to data source failed: access denied", string(res.Body))
+	})
+
+	t.Run("other OpenTSDB endpoints are not available", func(t *testing.T) {
+		requests = nil
+		res := callResource(t, s, pluginCtx, &backend.CallResourceRequest{Method: http.MethodGet, Path: "api/query", URL: "api/query"})
+		require.Equal(t, http.StatusNotFound, res.Status)
+
+		res = callResource(t, s, pluginCtx, &backend.CallResourceRequest{Method: http.MethodPost, Path: "api/suggest", URL: "api/suggest"})
+		require.Equal(t, http.StatusMethodNotAllowed, res.Status)
+		require.Empty(t, requests)
+	})
+
+	t.Run("queries are sent separately and answered by ref ID", func(t *testing.T) {
+		bodies = nil
+		timeRange := backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)}
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{
+				{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "sys.cpu.user", "disableDownsampling": true}`)},
+				{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "unknown", "disableDownsampling": true}`)},
+				{RefID: "C", TimeRange: timeRange, QueryType: "expression", JSON: []byte(`{"expression": {"expressions": [{"id": "e", "expr": "a"}]}}`)},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, bodies, 2)
+		require.Equal(t, true, bodies[0]["msResolution"])
+
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		require.Equal(t, time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC), res.Responses["A"].Frames[0].Fields[0].At(0))
+
+		require.EqualError(t, res.Responses["B"].Error, "request failed, status: 400 Bad Request, error: No such name for 'metrics': 'unknown'")
+
+		require.NoError(t, res.Responses["C"].Error)
+		require.Len(t, res.Responses["C"].Frames, 1)
+		require.Equal(t, "e", res.Responses["C"].Frames[0].Name)
+	})
+}
+
+func callResource(t *testing.T, s *Service, pluginCtx backend.PluginContext, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+	t.Helper()
+
+	req.PluginContext = pluginCtx
+	sender := &fakeSender{}
+	err := s.CallResource(context.Background(), req, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.res)
+	return sender.res
+}
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	if s.res == nil {
+		s.res = res
+	} else {
+		s.res.Body = append(s.res.Body, res.Body...)
+	}
+	return nil
+}
@@ -1,12 +1,89 @@
 package opentsdb
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
 type OpenTsdbQuery struct {
-	Start   int64                    `json:"start"`
-	End     int64                    `json:"end"`
-	Queries []map[string]interface{} `json:"queries"`
+	Start             int64                    `json:"start"`
+	End               int64                    `json:"end"`
+	Queries           []map[string]interface{} `json:"queries"`
+	MsResolution      bool                     `json:"msResolution,omitempty"`
+	GlobalAnnotations bool                     `json:"globalAnnotations,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric            string                `json:"metric"`
+	Tags              map[string]string     `json:"tags"`
+	AggregateTags     []string              `json:"aggregateTags"`
+	DataPoints        map[string]PointValue `json:"dps"`
+	Annotations       []OpenTsdbAnnotation  `json:"annotations"`
+	GlobalAnnotations []OpenTsdbAnnotation  `json:"globalAnnotations"`
+}
+
+type OpenTsdbAnnotation struct {
+	TSUID       string            `json:"tsuid,omitempty"`
+	Description string            `json:"description"`
+	Notes       string            `json:"notes,omitempty"`
+	StartTime   int64             `json:"startTime"`
+	EndTime     int64             `json:"endTime,omitempty"`
+	Custom      map[string]string `json:"custom,omitempty"`
+}
+
+// OpenTsdbExpressionResponse is the response of the expression API, /api/query/exp.
+type OpenTsdbExpressionResponse struct {
+	Outputs []OpenTsdbExpressionOutput `json:"outputs"`
+}
+
+type OpenTsdbExpressionOutput struct {
+	ID    string `json:"id"`
+	Alias string `json:"alias"`
+	// DataPoints are rows of a timestamp in milliseconds followed by a value of every series of the output.
+	DataPoints [][]PointValue                 `json:"dps"`
+	Meta       []OpenTsdbExpressionSeriesMeta `json:"meta"`
+}
+
+type OpenTsdbExpressionSeriesMeta struct {
+	Index          int               `json:"index"`
+	Metrics        []string          `json:"metrics"`
+	CommonTags     map[string]string `json:"commonTags"`
+	AggregatedTags []string          `json:"aggregatedTags"`
+}
+
+type OpenTsdbErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// PointValue is the value of a data point. Values that the null fill policy adds are null, and OpenTSDB
+// writes the values that the nan fill policy adds as the string "NaN".
+type PointValue struct {
+	Value *float64
+}
+
+func (v *PointValue) UnmarshalJSON(b []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		v.Value = nil
+	case float64:
+		v.Value = &value
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid data point value %q", value)
+		}
+		v.Value = &f
+	default:
+		return fmt.Errorf("invalid data point value %s", string(b))
+	}
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -92,6 +93,9 @@ func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFacto
 			DSInfo:            dsInfo,
 			MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			ByteLimit:         cfg.DataProxyByteLimit,
+			QueryGovernor:     postgresQueryGovernor{},
+			ParamPlaceholder:  func(position int) string { return fmt.Sprintf("$%d", position) },
 		}
 
 		queryResultTransformer := postgresQueryResultTransformer{
@@ -176,6 +180,22 @@ func (t *postgresQueryResultTransformer) TransformQueryError(err error) error {
 	return err
 }
 
+// postgresQueryGovernor limits queries with statement_timeout. The driver sends a cancel request to the
+// server when a query is cancelled, so there is no need to kill queries explicitly.
+type postgresQueryGovernor struct{}
+
+func (postgresQueryGovernor) StatementTimeout(timeout time.Duration) string {
+	return fmt.Sprintf("SET statement_timeout = %d", timeout.Milliseconds())
+}
+
+func (postgresQueryGovernor) ConnectionIDQuery() string {
+	return ""
+}
+
+func (postgresQueryGovernor) KillQuery(connectionID string) string {
+	return ""
+}
+
 func (t *postgresQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	return []sqlutil.StringConverter{
 		{
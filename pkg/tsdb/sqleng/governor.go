@@ -0,0 +1,237 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrQueryTimeout is returned when a query runs longer than the query timeout of the data source.
+var ErrQueryTimeout = errors.New("query exceeded the timeout")
+
+// killQueryTimeout bounds the time spent killing a cancelled query in the server.
+const killQueryTimeout = 5 * time.Second
+
+const (
+	queryStatusSuccess   = "success"
+	queryStatusError     = "error"
+	queryStatusTimeout   = "timeout"
+	queryStatusCancelled = "cancelled"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Subsystem: "sql_datasource",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of SQL data source queries, including the conversion of the result",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"driver", "status"})
+
+	queryRows = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Subsystem: "sql_datasource",
+		Name:      "query_result_rows",
+		Help:      "Number of rows returned by SQL data source queries",
+		Buckets:   prometheus.ExponentialBuckets(1, 10, 7),
+	}, []string{"driver"})
+
+	queryBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Subsystem: "sql_datasource",
+		Name:      "query_result_bytes",
+		Help:      "Approximate size in bytes of the results returned by SQL data source queries",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+	}, []string{"driver"})
+)
+
+// QueryGovernor bounds the execution of queries in the server using the native mechanisms of a SQL dialect.
+// The query timeout of the data source is always enforced with the query context, which makes drivers that
+// support cancellation abort the query in the server.
+type QueryGovernor interface {
+	// StatementTimeout returns the statement that limits the execution time of the queries of a session,
+	// or an empty string if the dialect has no such setting.
+	StatementTimeout(timeout time.Duration) string
+	// ConnectionIDQuery returns the query that selects the server-side ID of the current connection,
+	// or an empty string if the driver aborts the query in the server when its context is cancelled.
+	ConnectionIDQuery() string
+	// KillQuery returns the statement that aborts the query running on the connection with the given ID.
+	KillQuery(connectionID string) string
+}
+
+type queryStats struct {
+	status string
+	rows   int
+	bytes  int64
+}
+
+// queryStatus tells apart queries that failed, that were cancelled with their request and that ran
+// into the query timeout of the data source.
+func queryStatus(requestContext, queryContext context.Context) string {
+	switch {
+	case requestContext.Err() != nil:
+		return queryStatusCancelled
+	case queryContext.Err() != nil:
+		return queryStatusTimeout
+	default:
+		return queryStatusError
+	}
+}
+
+func (e *DataSourceHandler) observeQuery(stats queryStats, duration time.Duration) {
+	if stats.status == "" {
+		stats.status = queryStatusSuccess
+	}
+
+	queryDuration.WithLabelValues(e.driverName, stats.status).Observe(duration.Seconds())
+	if stats.status == queryStatusSuccess {
+		queryRows.WithLabelValues(e.driverName).Observe(float64(stats.rows))
+		queryBytes.WithLabelValues(e.driverName).Observe(float64(stats.bytes))
+	}
+
+	e.log.Debug("Query executed", "status", stats.status, "duration", duration, "rows", stats.rows, "bytes", stats.bytes)
+}
+
+// governQuery applies the statement timeout of the data source to conn and, for drivers that leave
+// cancelled queries running in the server, kills the query once ctx is done. The returned function
+// must be called when the query has finished and before conn is released.
+func (e *DataSourceHandler) governQuery(ctx context.Context, conn *sql.Conn) (func(), error) {
+	if e.queryGovernor == nil {
+		return func() {}, nil
+	}
+
+	if e.queryTimeout > 0 {
+		if stmt := e.queryGovernor.StatementTimeout(e.queryTimeout); stmt != "" {
+			// Not every server version supports the setting, the query context still enforces the timeout.
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				e.log.Warn("Failed to set statement timeout", "err", err)
+			}
+		}
+	}
+
+	idQuery := e.queryGovernor.ConnectionIDQuery()
+	if idQuery == "" {
+		return func() {}, nil
+	}
+
+	var connectionID string
+	if err := conn.QueryRowContext(ctx, idQuery).Scan(&connectionID); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			killCtx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+			defer cancel()
+			if _, err := e.engine.DB().ExecContext(killCtx, e.queryGovernor.KillQuery(connectionID)); err != nil {
+				e.log.Warn("Failed to kill cancelled query", "connectionId", connectionID, "err", err)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}, nil
+}
+
+// frameFromRows converts rows into a frame like sqlutil.FrameFromRows, and stops reading rows once they exceed
+// byteLimit bytes. If byteLimit is less than or equal to 0, there is no byte limit.
+func frameFromRows(rows *sql.Rows, rowLimit, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	scanner, converters, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, converters...)
+	var size int64
+	for rows.Next() {
+		if int64(frame.Rows()) == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+			})
+			break
+		}
+
+		r := scanner.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, err
+		}
+		if err := sqlutil.Append(frame, r, converters...); err != nil {
+			return nil, err
+		}
+
+		last := frame.Rows() - 1
+		size += frameRowSize(frame, last)
+		if byteLimit > 0 && size > byteLimit {
+			frame.DeleteRow(last)
+			frame.AppendNotices(ByteLimitNotice(last, byteLimit))
+			break
+		}
+	}
+	return frame, nil
+}
+
+// ByteLimitNotice is the notice of a result limited to rows rows because the SQL byte limit was reached.
+func ByteLimitNotice(rows int, byteLimit int64) data.Notice {
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("Results have been limited to %v rows because the SQL byte limit of %v was reached", rows, byteLimit),
+	}
+}
+
+// ValueSize returns the approximate size of a value counted towards the SQL byte limit: the length of strings and
+// byte slices, 8 bytes otherwise.
+func ValueSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case string:
+		return int64(len(v))
+	case *string:
+		if v != nil {
+			return int64(len(*v))
+		}
+		return 0
+	case []byte:
+		return int64(len(v))
+	default:
+		return 8
+	}
+}
+
+// frameBytes returns the approximate size of the rows of frame.
+func frameBytes(frame *data.Frame) int64 {
+	var size int64
+	for i := 0; i < frame.Rows(); i++ {
+		size += frameRowSize(frame, i)
+	}
+	return size
+}
+
+func frameRowSize(frame *data.Frame, rowIdx int) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		size += ValueSize(field.At(rowIdx))
+	}
+	return size
+}
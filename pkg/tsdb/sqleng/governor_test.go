@@ -0,0 +1,167 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// endlessQuery keeps SQLite busy until the query is interrupted.
+const endlessQuery = "SELECT value FROM test WHERE (WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT max(x) FROM c) > 0"
+
+func TestQueryGovernance(t *testing.T) {
+	t.Run("Query timeout aborts the query", func(t *testing.T) {
+		handler := newTestHandler(t, DataPluginConfiguration{})
+		handler.queryTimeout = 50 * time.Millisecond
+
+		res := runTestQuery(t, context.Background(), handler, endlessQuery)
+		require.ErrorIs(t, res.Error, ErrQueryTimeout)
+	})
+
+	t.Run("Governor applies the statement timeout and kills cancelled queries", func(t *testing.T) {
+		governor := &testQueryGovernor{killed: make(chan string, 1)}
+		handler := newTestHandler(t, DataPluginConfiguration{QueryGovernor: governor})
+		handler.queryTimeout = time.Minute
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		time.AfterFunc(50*time.Millisecond, cancel)
+		res := runTestQuery(t, ctx, handler, endlessQuery)
+		require.Error(t, res.Error)
+		require.NotErrorIs(t, res.Error, ErrQueryTimeout)
+
+		require.Equal(t, time.Minute, governor.timeout)
+		select {
+		case id := <-governor.killed:
+			require.Equal(t, "42", id)
+		default:
+			t.Fatal("expected the cancelled query to be killed")
+		}
+	})
+
+	t.Run("Governor does not kill finished queries", func(t *testing.T) {
+		governor := &testQueryGovernor{killed: make(chan string, 1)}
+		handler := newTestHandler(t, DataPluginConfiguration{QueryGovernor: governor})
+
+		res := runTestQuery(t, context.Background(), handler, "SELECT value FROM test")
+		require.NoError(t, res.Error)
+		require.Empty(t, governor.killed)
+		require.Zero(t, governor.timeout)
+	})
+
+	t.Run("Byte limit truncates the result", func(t *testing.T) {
+		handler := newTestHandler(t, DataPluginConfiguration{ByteLimit: 8})
+
+		res := runTestQuery(t, context.Background(), handler, "SELECT value FROM test")
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		require.Contains(t, frame.Meta.Notices[0].Text, "byte limit of 8")
+	})
+
+	t.Run("Byte limit stops reading rows", func(t *testing.T) {
+		handler := newTestHandler(t, DataPluginConfiguration{ByteLimit: 6})
+
+		// reading the last row fails with an integer overflow
+		res := runTestQuery(t, context.Background(), handler, "SELECT value FROM test WHERE CASE WHEN value = 'ijkl' THEN abs(-9223372036854775808) ELSE 1 END")
+		require.NoError(t, res.Error)
+		require.Equal(t, 1, res.Frames[0].Rows())
+		require.Contains(t, res.Frames[0].Meta.Notices[0].Text, "limited to 1 rows")
+	})
+
+	t.Run("Frame size counts the length of strings", func(t *testing.T) {
+		frame := data.NewFrame("", data.NewField("value", nil, []*string{strPtr("abcd"), nil, strPtr("ef")}))
+		require.Equal(t, int64(6), frameBytes(frame))
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newTestHandler(t *testing.T, config DataPluginConfiguration) *DataSourceHandler {
+	t.Helper()
+
+	config.DriverName = "sqlite3"
+	config.ConnectionString = filepath.Join(t.TempDir(), "test.db")
+	config.RowLimit = 1000
+	handler, err := NewQueryDataHandler(config, &textQueryResultTransformer{}, &testMacroEngine{}, log.New("test"))
+	require.NoError(t, err)
+	t.Cleanup(handler.Dispose)
+
+	_, err = handler.engine.Exec("CREATE TABLE test (value TEXT)")
+	require.NoError(t, err)
+	_, err = handler.engine.Exec("INSERT INTO test (value) VALUES ('abcd'), ('efgh'), ('ijkl')")
+	require.NoError(t, err)
+	return handler
+}
+
+func runTestQuery(t *testing.T, ctx context.Context, handler *DataSourceHandler, rawSQL string) backend.DataResponse {
+	t.Helper()
+
+	query, err := json.Marshal(QueryJson{RawSql: rawSQL, Format: "table"})
+	require.NoError(t, err)
+	res, err := handler.QueryData(ctx, &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{RefID: "A", JSON: query}},
+	})
+	require.NoError(t, err)
+	return res.Responses["A"]
+}
+
+// textQueryResultTransformer scans SQLite TEXT columns, whose scan type is unknown before the first row.
+type textQueryResultTransformer struct {
+	testQueryResultTransformer
+}
+
+func (t *textQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{
+		{
+			Name:           "handle TEXT",
+			InputScanKind:  reflect.Interface,
+			InputTypeName:  "TEXT",
+			ConversionFunc: func(in *string) (*string, error) { return in, nil },
+			Replacer: &sqlutil.StringFieldReplacer{
+				OutputFieldType: data.FieldTypeNullableString,
+				ReplaceFunc:     func(in *string) (interface{}, error) { return in, nil },
+			},
+		},
+	}
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+type testQueryGovernor struct {
+	timeout time.Duration
+	killed  chan string
+}
+
+func (g *testQueryGovernor) StatementTimeout(timeout time.Duration) string {
+	g.timeout = timeout
+	return fmt.Sprintf("PRAGMA busy_timeout = %d", timeout.Milliseconds())
+}
+
+func (g *testQueryGovernor) ConnectionIDQuery() string {
+	return "SELECT 42"
+}
+
+func (g *testQueryGovernor) KillQuery(connectionID string) string {
+	g.killed <- connectionID
+	return "SELECT 1"
+}
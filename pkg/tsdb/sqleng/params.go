@@ -0,0 +1,239 @@
+package sqleng
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// maxQueryParams stays below the smallest limit of bind parameters of the supported dialects,
+// which is 2100 for MSSQL.
+const maxQueryParams = 2000
+
+// maxQueryParamValueLength is the maximum length of a bound value, in bytes.
+const maxQueryParamValueLength = 10000
+
+// queryParamRegex matches ${name:param} and ${name:param:type}, where type is one of the value
+// types of queryParamValueTypes.
+var queryParamRegex = regexp.MustCompile(`\$\{(\w+):param(?::(\w+))?\}`)
+
+var ErrInvalidQueryParam = errors.New("invalid query parameter")
+
+// QueryParam is a template variable the query refers to as ${name:param}. Its values are passed
+// to the database as bind parameters instead of being interpolated into the query.
+type QueryParam struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Multi  bool     `json:"multi"`
+	Values []string `json:"values"`
+}
+
+// validate checks that the parameter can be bound: the type of its variable supports it, a
+// single-value variable has at most one value, the values are not too long and the values of
+// interval variables are valid intervals.
+func (p QueryParam) validate() error {
+	switch p.Type {
+	case "query", "custom":
+	case "constant", "textbox":
+		if p.Multi {
+			return fmt.Errorf("%w: %s variable %q cannot have multiple values", ErrInvalidQueryParam, p.Type, p.Name)
+		}
+	case "interval":
+		for _, v := range p.Values {
+			if _, err := gtime.ParseInterval(v); err != nil {
+				return fmt.Errorf("%w: %q is not a valid interval for variable %q", ErrInvalidQueryParam, v, p.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s variable %q cannot be bound as a parameter", ErrInvalidQueryParam, p.Type, p.Name)
+	}
+
+	if !p.Multi && len(p.Values) > 1 {
+		return fmt.Errorf("%w: variable %q is not multi-value but has %d values", ErrInvalidQueryParam, p.Name, len(p.Values))
+	}
+
+	for _, v := range p.Values {
+		if len(v) > maxQueryParamValueLength {
+			return fmt.Errorf("%w: a value of variable %q is longer than %d bytes", ErrInvalidQueryParam, p.Name, maxQueryParamValueLength)
+		}
+	}
+
+	return nil
+}
+
+// queryParamValueTypes converts the values of a variable to the type a reference declares with
+// ${name:param:type}, values that are not of the type are rejected. References without a type
+// bind the values as strings.
+var queryParamValueTypes = map[string]func(value string) (interface{}, error){
+	"string": func(value string) (interface{}, error) {
+		return value, nil
+	},
+	"number": func(value string) (interface{}, error) {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errors.New("not a number")
+		}
+		return f, nil
+	},
+	"boolean": func(value string) (interface{}, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("not a boolean")
+		}
+		return b, nil
+	},
+	// Dates are RFC 3339 timestamps or epoch milliseconds, like the values of ${__from} and ${__to}.
+	"date": func(value string) (interface{}, error) {
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.UnixMilli(ms).UTC(), nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, errors.New("not a date")
+		}
+		return t.UTC(), nil
+	},
+}
+
+// bindValues returns the values of the parameter converted to valueType.
+func (p QueryParam) bindValues(valueType string) ([]interface{}, error) {
+	if valueType == "" {
+		valueType = "string"
+	}
+	convert, ok := queryParamValueTypes[valueType]
+	if !ok {
+		return nil, fmt.Errorf("%w: ${%s:param:%s} has an unknown type, use one of string, number, boolean or date", ErrInvalidQueryParam, p.Name, valueType)
+	}
+
+	values := make([]interface{}, 0, len(p.Values))
+	for _, v := range p.Values {
+		converted, err := convert(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q of variable %q is %v", ErrInvalidQueryParam, v, p.Name, err)
+		}
+		values = append(values, converted)
+	}
+	return values, nil
+}
+
+// questionMarkPlaceholder is the default placeholder of bind parameters.
+func questionMarkPlaceholder(position int) string {
+	return "?"
+}
+
+// bindQueryParams replaces the ${name:param} references of sql by bind parameter placeholders
+// and returns the matching arguments. Multi-value variables expand to a comma separated list of
+// placeholders, variables without a value are bound to NULL. References within quoted literals,
+// quoted identifiers and comments are left as they are.
+func bindQueryParams(sql string, params []QueryParam, placeholder func(position int) string) (string, []interface{}, error) {
+	byName := make(map[string]QueryParam, len(params))
+	for _, p := range params {
+		if err := p.validate(); err != nil {
+			return "", nil, err
+		}
+		byName[p.Name] = p
+	}
+
+	var args []interface{}
+	var bindErr error
+	bound := replaceOutsideLiterals(sql, func(code string) string {
+		return queryParamRegex.ReplaceAllStringFunc(code, func(match string) string {
+			if bindErr != nil {
+				return match
+			}
+
+			submatches := queryParamRegex.FindStringSubmatch(match)
+			name, valueType := submatches[1], submatches[2]
+			p, ok := byName[name]
+			if !ok {
+				bindErr = fmt.Errorf("%w: ${%s:param} has no value, add a template variable %q to the dashboard (queries from alert rules have no template variables)", ErrInvalidQueryParam, name, name)
+				return match
+			}
+
+			values, err := p.bindValues(valueType)
+			if err != nil {
+				bindErr = err
+				return match
+			}
+			if len(values) == 0 {
+				values = append(values, nil)
+			}
+
+			placeholders := make([]string, 0, len(values))
+			for _, v := range values {
+				args = append(args, v)
+				placeholders = append(placeholders, placeholder(len(args)))
+			}
+			return strings.Join(placeholders, ", ")
+		})
+	})
+	if bindErr != nil {
+		return "", nil, bindErr
+	}
+
+	if len(args) > maxQueryParams {
+		return "", nil, fmt.Errorf("%w: the query has %d bind parameters, the maximum is %d", ErrInvalidQueryParam, len(args), maxQueryParams)
+	}
+
+	return bound, args, nil
+}
+
+// replaceOutsideLiterals returns sql with the parts that are not within quoted literals, quoted
+// identifiers or comments replaced by replace.
+func replaceOutsideLiterals(sql string, replace func(code string) string) string {
+	var b strings.Builder
+	start := 0
+	for i := 0; i < len(sql); {
+		end := literalEnd(sql, i)
+		if end == i {
+			i++
+			continue
+		}
+		b.WriteString(replace(sql[start:i]))
+		b.WriteString(sql[i:end])
+		i, start = end, end
+	}
+	b.WriteString(replace(sql[start:]))
+	return b.String()
+}
+
+// literalEnd returns the end of the quoted literal, quoted identifier or comment starting at
+// position i of sql, or i if none starts there. Quotes within quoted literals and identifiers
+// are escaped by doubling them.
+func literalEnd(sql string, i int) int {
+	switch {
+	case sql[i] == '\'' || sql[i] == '"' || sql[i] == '`':
+		quote := sql[i]
+		for j := i + 1; j < len(sql); j++ {
+			if sql[j] != quote {
+				continue
+			}
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+		return len(sql)
+	case strings.HasPrefix(sql[i:], "--"):
+		if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+			return i + j + 1
+		}
+		return len(sql)
+	case strings.HasPrefix(sql[i:], "/*"):
+		if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+			return i + 2 + j + 2
+		}
+		return len(sql)
+	}
+	return i
+}
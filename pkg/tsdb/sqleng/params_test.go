@@ -0,0 +1,139 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindQueryParams(t *testing.T) {
+	postgresPlaceholder := func(position int) string { return fmt.Sprintf("$%d", position) }
+
+	t.Run("Binds single and multi-value variables", func(t *testing.T) {
+		sql, args, err := bindQueryParams(
+			"SELECT * FROM t WHERE host IN (${host:param}) AND env = ${env:param} AND dc = ${env:param}",
+			[]QueryParam{
+				{Name: "host", Type: "query", Multi: true, Values: []string{"a", "b'; DROP TABLE t; --"}},
+				{Name: "env", Type: "custom", Values: []string{"prod"}},
+			}, postgresPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM t WHERE host IN ($1, $2) AND env = $3 AND dc = $4", sql)
+		require.Equal(t, []interface{}{"a", "b'; DROP TABLE t; --", "prod", "prod"}, args)
+	})
+
+	t.Run("Uses question marks by default", func(t *testing.T) {
+		sql, args, err := bindQueryParams("SELECT ${a:param}, ${a:param}", []QueryParam{{Name: "a", Type: "textbox", Values: []string{"x"}}}, questionMarkPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT ?, ?", sql)
+		require.Len(t, args, 2)
+	})
+
+	t.Run("Binds variables without a value to NULL", func(t *testing.T) {
+		sql, args, err := bindQueryParams("SELECT * FROM t WHERE host IN (${host:param})", []QueryParam{{Name: "host", Type: "query", Multi: true}}, questionMarkPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM t WHERE host IN (?)", sql)
+		require.Equal(t, []interface{}{nil}, args)
+	})
+
+	t.Run("Leaves other variable syntaxes alone", func(t *testing.T) {
+		sql, args, err := bindQueryParams("SELECT '${host}', '${host:csv}'", nil, questionMarkPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT '${host}', '${host:csv}'", sql)
+		require.Empty(t, args)
+	})
+
+	t.Run("Leaves references within literals and comments alone", func(t *testing.T) {
+		sql, args, err := bindQueryParams(
+			"SELECT '${a:param}', 'it''s ${a:param}', \"${a:param}\" FROM t -- ${a:param}\nWHERE a = ${a:param} /* ${b:param} */",
+			[]QueryParam{{Name: "a", Type: "textbox", Values: []string{"x"}}}, postgresPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT '${a:param}', 'it''s ${a:param}', \"${a:param}\" FROM t -- ${a:param}\nWHERE a = $1 /* ${b:param} */", sql)
+		require.Equal(t, []interface{}{"x"}, args)
+	})
+
+	t.Run("Converts values to the declared type", func(t *testing.T) {
+		sql, args, err := bindQueryParams(
+			"SELECT * FROM t WHERE id IN (${id:param:number}) AND enabled = ${enabled:param:boolean} AND ts > ${from:param:date} AND ts < ${to:param:date} AND name = ${id:param:string}",
+			[]QueryParam{
+				{Name: "id", Type: "query", Multi: true, Values: []string{"1", "2.5"}},
+				{Name: "enabled", Type: "custom", Values: []string{"true"}},
+				{Name: "from", Type: "constant", Values: []string{"1639125366989"}},
+				{Name: "to", Type: "textbox", Values: []string{"2021-12-10T09:00:00+01:00"}},
+			}, questionMarkPlaceholder)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM t WHERE id IN (?, ?) AND enabled = ? AND ts > ? AND ts < ? AND name = ?, ?", sql)
+		require.Equal(t, []interface{}{
+			int64(1), 2.5, true,
+			time.Date(2021, 12, 10, 8, 36, 6, 989000000, time.UTC),
+			time.Date(2021, 12, 10, 8, 0, 0, 0, time.UTC),
+			"1", "2.5",
+		}, args)
+	})
+
+	invalid := []struct {
+		desc   string
+		sql    string
+		params []QueryParam
+	}{
+		{desc: "missing variable", sql: "SELECT ${host:param}"},
+		{desc: "unsupported variable type", sql: "SELECT ${ds:param}", params: []QueryParam{{Name: "ds", Type: "datasource", Values: []string{"a"}}}},
+		{desc: "several values for a single-value variable", sql: "SELECT ${a:param}", params: []QueryParam{{Name: "a", Type: "query", Values: []string{"a", "b"}}}},
+		{desc: "multi-value textbox", sql: "SELECT ${a:param}", params: []QueryParam{{Name: "a", Type: "textbox", Multi: true, Values: []string{"a"}}}},
+		{desc: "invalid interval", sql: "SELECT ${i:param}", params: []QueryParam{{Name: "i", Type: "interval", Values: []string{"1m; DROP TABLE t"}}}},
+		{desc: "a value that is not a number", sql: "SELECT ${a:param:number}", params: []QueryParam{{Name: "a", Type: "textbox", Values: []string{"1 OR 1=1"}}}},
+		{desc: "a value that is not a boolean", sql: "SELECT ${a:param:boolean}", params: []QueryParam{{Name: "a", Type: "textbox", Values: []string{"yes"}}}},
+		{desc: "a value that is not a date", sql: "SELECT ${a:param:date}", params: []QueryParam{{Name: "a", Type: "textbox", Values: []string{"yesterday"}}}},
+		{desc: "an unknown value type", sql: "SELECT ${a:param:json}", params: []QueryParam{{Name: "a", Type: "textbox", Values: []string{"{}"}}}},
+		{desc: "a too long value", sql: "SELECT ${a:param}", params: []QueryParam{{Name: "a", Type: "textbox", Values: []string{strings.Repeat("a", maxQueryParamValueLength+1)}}}},
+		{desc: "too many parameters", sql: "SELECT ${a:param}", params: []QueryParam{{Name: "a", Type: "query", Multi: true, Values: make([]string, maxQueryParams+1)}}},
+	}
+	for _, tc := range invalid {
+		t.Run("Rejects "+tc.desc, func(t *testing.T) {
+			_, _, err := bindQueryParams(tc.sql, tc.params, questionMarkPlaceholder)
+			require.ErrorIs(t, err, ErrInvalidQueryParam)
+		})
+	}
+
+	t.Run("Tells which template variable is missing", func(t *testing.T) {
+		_, _, err := bindQueryParams("SELECT ${host:param}", nil, questionMarkPlaceholder)
+		require.EqualError(t, err, `invalid query parameter: ${host:param} has no value, add a template variable "host" to the dashboard (queries from alert rules have no template variables)`)
+	})
+
+	t.Run("Tells which variable has an invalid value", func(t *testing.T) {
+		_, _, err := bindQueryParams("SELECT ${id:param:number}", []QueryParam{{Name: "id", Type: "textbox", Values: []string{"1; DROP TABLE t"}}}, questionMarkPlaceholder)
+		require.EqualError(t, err, `invalid query parameter: "1; DROP TABLE t" of variable "id" is not a number`)
+	})
+
+	t.Run("Query binds the values of the variables", func(t *testing.T) {
+		handler := newTestHandler(t, DataPluginConfiguration{})
+
+		run := func(values ...string) backend.DataResponse {
+			query, err := json.Marshal(QueryJson{
+				RawSql: "SELECT value FROM test WHERE value IN (${value:param})",
+				Format: "table",
+				Params: []QueryParam{{Name: "value", Type: "query", Multi: true, Values: values}},
+			})
+			require.NoError(t, err)
+			res, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+				Queries: []backend.DataQuery{{RefID: "A", JSON: query}},
+			})
+			require.NoError(t, err)
+			return res.Responses["A"]
+		}
+
+		res := run("abcd", "ijkl")
+		require.NoError(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		require.Equal(t, "SELECT value FROM test WHERE value IN (?, ?)", res.Frames[0].Meta.ExecutedQueryString)
+
+		res = run("abcd' OR '1' = '1")
+		require.NoError(t, res.Error)
+		require.Equal(t, 0, res.Frames[0].Rows())
+	})
+}
@@ -19,7 +19,6 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/util/errutil"
-	"xorm.io/core"
 	"xorm.io/xorm"
 )
 
@@ -64,6 +63,8 @@ type JsonData struct {
 	Encrypt             string `json:"encrypt"`
 	Servername          string `json:"servername"`
 	TimeInterval        string `json:"timeInterval"`
+	QueryTimeout        int    `json:"queryTimeout"`
+	Path                string `json:"path"`
 }
 
 type DataSourceInfo struct {
@@ -84,6 +85,17 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	ByteLimit         int64
+	QueryGovernor     QueryGovernor
+	// ParamPlaceholder returns the placeholder of the bind parameter at the given 1-based position,
+	// defaults to a question mark.
+	ParamPlaceholder func(position int) string
+	// Engine is used instead of an engine opened from DriverName and ConnectionString. The
+	// handler leaves its configuration to the caller and does not close it when disposed.
+	Engine *xorm.Engine
+	// FrameFromRows converts the rows of a query into a frame and stops reading rows at the row limit, or once they
+	// exceed the byte limit. It defaults to a conversion like sqlutil.FrameFromRows.
+	FrameFromRows func(rows *sql.Rows, rowLimit, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, error)
 }
 type DataSourceHandler struct {
 	macroEngine            SQLMacroEngine
@@ -94,14 +106,22 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
+	queryTimeout           time.Duration
+	queryGovernor          QueryGovernor
+	driverName             string
+	paramPlaceholder       func(position int) string
+	frameFromRows          func(rows *sql.Rows, rowLimit, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, error)
+	sharedEngine           bool
 }
 type QueryJson struct {
-	RawSql       string  `json:"rawSql"`
-	Fill         bool    `json:"fill"`
-	FillInterval float64 `json:"fillInterval"`
-	FillMode     string  `json:"fillMode"`
-	FillValue    float64 `json:"fillValue"`
-	Format       string  `json:"format"`
+	RawSql       string       `json:"rawSql"`
+	Fill         bool         `json:"fill"`
+	FillInterval float64      `json:"fillInterval"`
+	FillMode     string       `json:"fillMode"`
+	FillValue    float64      `json:"fillValue"`
+	Format       string       `json:"format"`
+	Params       []QueryParam `json:"params"`
 }
 
 func (e *DataSourceHandler) transformQueryError(err error) error {
@@ -132,6 +152,20 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.ByteLimit,
+		queryTimeout:           time.Duration(config.DSInfo.JsonData.QueryTimeout) * time.Second,
+		queryGovernor:          config.QueryGovernor,
+		driverName:             config.DriverName,
+		paramPlaceholder:       config.ParamPlaceholder,
+		frameFromRows:          config.FrameFromRows,
+	}
+
+	if queryDataHandler.paramPlaceholder == nil {
+		queryDataHandler.paramPlaceholder = questionMarkPlaceholder
+	}
+
+	if queryDataHandler.frameFromRows == nil {
+		queryDataHandler.frameFromRows = frameFromRows
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -142,6 +176,12 @@ func NewQueryDataHandler(config DataPluginConfiguration, queryResultTransformer
 		queryDataHandler.metricColumnTypes = config.MetricColumnTypes
 	}
 
+	if config.Engine != nil {
+		queryDataHandler.engine = config.Engine
+		queryDataHandler.sharedEngine = true
+		return &queryDataHandler, nil
+	}
+
 	engine, err := NewXormEngine(config.DriverName, config.ConnectionString)
 	if err != nil {
 		return nil, err
@@ -162,7 +202,7 @@ type DBDataResponse struct {
 
 func (e *DataSourceHandler) Dispose() {
 	e.log.Debug("Disposing engine...")
-	if e.engine != nil {
+	if e.engine != nil && !e.sharedEngine {
 		if err := e.engine.Close(); err != nil {
 			e.log.Error("Failed to dispose engine", "error", err)
 		}
@@ -232,7 +272,25 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	timeRange := query.TimeRange
 
+	requestContext := queryContext
+	if e.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, e.queryTimeout)
+		defer cancel()
+	}
+
+	var stats queryStats
+	start := time.Now()
+	defer func() {
+		e.observeQuery(stats, time.Since(start))
+	}()
+
 	errAppendDebug := func(frameErr string, err error, query string) {
+		stats.status = queryStatus(requestContext, queryContext)
+		if stats.status == queryStatusTimeout {
+			err = fmt.Errorf("%w of %s: %s", ErrQueryTimeout, e.queryTimeout, err)
+		}
+
 		var emptyFrame data.Frame
 		emptyFrame.SetMeta(&data.FrameMeta{
 			ExecutedQueryString: query,
@@ -256,11 +314,32 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	session := e.engine.NewSession()
-	defer session.Close()
-	db := session.DB()
+	boundQuery, args, err := bindQueryParams(interpolatedQuery, queryJson.Params, e.paramPlaceholder)
+	if err != nil {
+		errAppendDebug("binding parameters failed", err, interpolatedQuery)
+		return
+	}
+	interpolatedQuery = boundQuery
+
+	conn, err := e.engine.DB().Conn(queryContext)
+	if err != nil {
+		errAppendDebug("db connection error", e.transformQueryError(err), interpolatedQuery)
+		return
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			e.log.Warn("Failed to close connection", "err", err)
+		}
+	}()
+
+	release, err := e.governQuery(queryContext, conn)
+	if err != nil {
+		errAppendDebug("failed to apply query limits", e.transformQueryError(err), interpolatedQuery)
+		return
+	}
+	defer release()
 
-	rows, err := db.QueryContext(queryContext, interpolatedQuery)
+	rows, err := conn.QueryContext(queryContext, interpolatedQuery, args...)
 	if err != nil {
 		errAppendDebug("db query error", e.transformQueryError(err), interpolatedQuery)
 		return
@@ -279,12 +358,21 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := e.frameFromRows(rows, e.rowLimit, e.byteLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
 	}
 
+	// The rows stop at the first error, e.g. when the query is cancelled, which FrameFromRows does not report.
+	if err := rows.Err(); err != nil {
+		errAppendDebug("db query error", e.transformQueryError(err), interpolatedQuery)
+		return
+	}
+
+	stats.bytes = frameBytes(frame)
+	stats.rows = frame.Rows()
+
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
 	}
@@ -385,7 +473,7 @@ var Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, tim
 }
 
 func (e *DataSourceHandler) newProcessCfg(query backend.DataQuery, queryContext context.Context,
-	rows *core.Rows, interpolatedQuery string) (*dataQueryModel, error) {
+	rows *sql.Rows, interpolatedQuery string) (*dataQueryModel, error) {
 	columnNames, err := rows.Columns()
 	if err != nil {
 		return nil, err
@@ -492,7 +580,7 @@ type dataQueryModel struct {
 	timeIndex         int
 	timeEndIndex      int
 	metricIndex       int
-	rows              *core.Rows
+	rows              *sql.Rows
 	metricPrefix      bool
 	queryContext      context.Context
 }
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// frameFromRows converts rows into a frame. The columns of SQLite have no fixed type and
+// expressions have no declared type at all, so the type of each field is inferred from the
+// values of its column: integers, floats, times or booleans, and strings otherwise. It stops reading rows once they
+// exceed byteLimit bytes.
+func frameFromRows(rows *sql.Rows, rowLimit, byteLimit int64, _ ...sqlutil.Converter) (*data.Frame, error) {
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame("")
+	var values [][]interface{}
+	var size int64
+	for rows.Next() {
+		if int64(len(values)) == rowLimit {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
+			})
+			break
+		}
+
+		row := make([]interface{}, len(names))
+		dest := make([]interface{}, len(names))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		for _, v := range row {
+			size += sqleng.ValueSize(v)
+		}
+		if byteLimit > 0 && size > byteLimit {
+			frame.AppendNotices(sqleng.ByteLimitNotice(len(values), byteLimit))
+			break
+		}
+		values = append(values, row)
+	}
+
+	for i, name := range names {
+		frame.Fields = append(frame.Fields, newField(name, values, i))
+	}
+
+	return frame, nil
+}
+
+func newField(name string, rows [][]interface{}, column int) *data.Field {
+	fieldType := inferFieldType(rows, column)
+	field := data.NewFieldFromFieldType(fieldType, len(rows))
+	field.Name = name
+
+	for i, row := range rows {
+		v := row[column]
+		if v == nil {
+			continue
+		}
+
+		switch fieldType {
+		case data.FieldTypeNullableInt64:
+			n := v.(int64)
+			field.Set(i, &n)
+		case data.FieldTypeNullableFloat64:
+			if n, ok := v.(int64); ok {
+				v = float64(n)
+			}
+			f := v.(float64)
+			field.Set(i, &f)
+		case data.FieldTypeNullableTime:
+			t := v.(time.Time)
+			field.Set(i, &t)
+		case data.FieldTypeNullableBool:
+			b := v.(bool)
+			field.Set(i, &b)
+		default:
+			s := toString(v)
+			field.Set(i, &s)
+		}
+	}
+
+	return field
+}
+
+// inferFieldType returns the nullable field type that holds all the values of column. Columns
+// without values and columns that mix numbers, times and booleans are strings.
+func inferFieldType(rows [][]interface{}, column int) data.FieldType {
+	var ints, floats, times, bools, others bool
+	for _, row := range rows {
+		switch row[column].(type) {
+		case nil:
+		case int64:
+			ints = true
+		case float64:
+			floats = true
+		case time.Time:
+			times = true
+		case bool:
+			bools = true
+		default:
+			others = true
+		}
+	}
+
+	numbers := ints || floats
+	switch {
+	case others:
+		return data.FieldTypeNullableString
+	case numbers && !times && !bools:
+		if floats {
+			return data.FieldTypeNullableFloat64
+		}
+		return data.FieldTypeNullableInt64
+	case times && !numbers && !bools:
+		return data.FieldTypeNullableTime
+	case bools && !numbers && !times:
+		return data.FieldTypeNullableBool
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
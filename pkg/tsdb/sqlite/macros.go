@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+const rsIdentifier = `([_a-zA-Z0-9]+)`
+const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+
+var macroRegex = regexp.MustCompile(sExpr)
+
+// dateTimeFormat is the format of the SQLite date and time functions, e.g. datetime('now').
+const dateTimeFormat = "2006-01-02 15:04:05"
+
+type sqliteMacroEngine struct {
+	*sqleng.SQLMacroEngineBase
+}
+
+func newSqliteMacroEngine() sqleng.SQLMacroEngine {
+	return &sqliteMacroEngine{SQLMacroEngineBase: sqleng.NewSQLMacroEngineBase()}
+}
+
+func (m *sqliteMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	var macroError error
+
+	sql = m.ReplaceAllStringSubmatchFunc(macroRegex, sql, func(groups []string) string {
+		args := strings.Split(groups[2], ",")
+		for i, arg := range args {
+			args[i] = strings.Trim(arg, " ")
+		}
+		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
+		if err != nil && macroError == nil {
+			macroError = err
+			return "macro_error()"
+		}
+		return res
+	})
+
+	if macroError != nil {
+		return "", macroError
+	}
+
+	return sql, nil
+}
+
+// unixEpoch converts a time column holding text in a format of the SQLite date and time functions into seconds.
+func unixEpoch(column string) string {
+	return fmt.Sprintf("CAST(strftime('%%s', %s) AS INTEGER)", column)
+}
+
+func dateTime(t time.Time) string {
+	return fmt.Sprintf("'%s'", t.UTC().Format(dateTimeFormat))
+}
+
+func (m *sqliteMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
+	switch name {
+	case "__timeEpoch", "__time":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s as time_sec", unixEpoch(args[0])), nil
+	case "__timeFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+
+		return fmt.Sprintf("%s BETWEEN %s AND %s", args[0], dateTime(timeRange.From), dateTime(timeRange.To)), nil
+	case "__timeFrom":
+		return dateTime(timeRange.From), nil
+	case "__timeTo":
+		return dateTime(timeRange.To), nil
+	case "__timeGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval", name)
+		}
+		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+		if err != nil {
+			return "", fmt.Errorf("error parsing interval %v", args[1])
+		}
+		if len(args) == 3 {
+			err := sqleng.SetupFillmode(query, interval, args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s / %.0f * %.0f", unixEpoch(args[0]), interval.Seconds(), interval.Seconds()), nil
+	case "__timeGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
+		if err == nil {
+			return tg + " AS \"time\"", nil
+		}
+		return "", err
+	case "__unixEpochFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().Unix(), args[0], timeRange.To.UTC().Unix()), nil
+	case "__unixEpochFrom":
+		return fmt.Sprintf("%d", timeRange.From.UTC().Unix()), nil
+	case "__unixEpochTo":
+		return fmt.Sprintf("%d", timeRange.To.UTC().Unix()), nil
+	case "__unixEpochNanoFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().UnixNano(), args[0], timeRange.To.UTC().UnixNano()), nil
+	case "__unixEpochNanoFrom":
+		return fmt.Sprintf("%d", timeRange.From.UTC().UnixNano()), nil
+	case "__unixEpochNanoTo":
+		return fmt.Sprintf("%d", timeRange.To.UTC().UnixNano()), nil
+	case "__unixEpochGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+		}
+		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'`))
+		if err != nil {
+			return "", fmt.Errorf("error parsing interval %v", args[1])
+		}
+		if len(args) == 3 {
+			err := sqleng.SetupFillmode(query, interval, args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("CAST(%s AS INTEGER) / %.0f * %.0f", args[0], interval.Seconds(), interval.Seconds()), nil
+	case "__unixEpochGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
+		if err == nil {
+			return tg + " AS \"time\"", nil
+		}
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown macro %v", name)
+	}
+}
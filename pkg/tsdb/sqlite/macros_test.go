@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSqliteMacroEngine()
+	query := &backend.DataQuery{}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	t.Run("interpolate __time function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "select CAST(strftime('%s', time_column) AS INTEGER) as time_sec", sql)
+	})
+
+	t.Run("interpolate __timeFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "WHERE time_column BETWEEN '2018-04-12 18:00:00' AND '2018-04-12 18:05:00'", sql)
+	})
+
+	t.Run("interpolate __timeFrom and __timeTo functions in UTC", func(t *testing.T) {
+		local := backend.TimeRange{From: from.In(time.FixedZone("UTC+2", 2*60*60)), To: to}
+		sql, err := engine.Interpolate(query, local, "select $__timeFrom(), $__timeTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, "select '2018-04-12 18:00:00', '2018-04-12 18:05:00'", sql)
+	})
+
+	t.Run("interpolate __timeGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column , '5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("interpolate __timeGroup function with fill", func(t *testing.T) {
+		fillQuery := &backend.DataQuery{JSON: []byte("{}")}
+		_, err := engine.Interpolate(fillQuery, timeRange, "GROUP BY $__timeGroup(time_column, '5m', NULL)")
+		require.NoError(t, err)
+	})
+
+	t.Run("interpolate __unixEpoch functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__unixEpochFilter(time) AND time > $__unixEpochFrom() AND time < $__unixEpochTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("WHERE time >= %d AND time <= %d AND time > %d AND time < %d", from.Unix(), to.Unix(), from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __unixEpochNano functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__unixEpochNanoFilter(time) AND time > $__unixEpochNanoFrom() AND time < $__unixEpochNanoTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("WHERE time >= %d AND time <= %d AND time > %d AND time < %d", from.UnixNano(), to.UnixNano(), from.UnixNano(), to.UnixNano()), sql)
+	})
+
+	t.Run("interpolate __unixEpochGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__unixEpochGroup(time_column,'5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__unixEpochGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY CAST(time_column AS INTEGER) / 300 * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("fail on unknown macros and missing arguments", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "select $__unknown(time)")
+		require.Error(t, err)
+		_, err = engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time)")
+		require.Error(t, err)
+	})
+}
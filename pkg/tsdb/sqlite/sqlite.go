@@ -0,0 +1,287 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+	"xorm.io/xorm"
+)
+
+// driverName is the SQLite driver that only lets connections read the database file.
+const driverName = "sqlite3_datasource"
+
+// sqliteRecursive is the authorizer action of recursive common table expressions,
+// which go-sqlite3 does not export.
+const sqliteRecursive = 33
+
+var logger = log.New("tsdb.sqlite")
+
+var (
+	errNoPath          = errors.New("no database file configured")
+	errRelativePath    = errors.New("the path of the database file must be absolute")
+	errPathNotAllowed  = errors.New("the database file is not in a directory allowed by the sqlite_datasource allowed_paths setting")
+	errNoAllowedPaths  = errors.New("the SQLite data source is disabled, configure the directories it can read with the sqlite_datasource allowed_paths setting")
+	errQueryNotAllowed = errors.New("only SELECT statements are allowed")
+)
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(authorize)
+			return nil
+		},
+	})
+	core.RegisterDriver(driverName, core.QueryDriver("sqlite3"))
+}
+
+// authorize denies everything but reading, so that queries can neither change the database
+// nor attach other files than the one of the data source.
+func authorize(action int, _, _, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	default:
+		return sqlite3.SQLITE_DENY
+	}
+}
+
+type Service struct {
+	im      instancemgmt.InstanceManager
+	engines *engineCache
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	engines := &engineCache{engines: map[string]*cachedEngine{}}
+	return &Service{
+		im:      datasource.NewInstanceManager(newInstanceSettings(cfg, engines)),
+		engines: engines,
+	}
+}
+
+// instance is the data source handler of a data source. Data sources that read the same file
+// share its engine, which is closed once the last of them is disposed.
+type instance struct {
+	*sqleng.DataSourceHandler
+	engine  *xorm.Engine
+	release func()
+}
+
+func (i *instance) Dispose() {
+	i.DataSourceHandler.Dispose()
+	i.release()
+}
+
+func newInstanceSettings(cfg *setting.Cfg, engines *engineCache) datasource.InstanceFactoryFunc {
+	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{}
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		path, err := resolvePath(jsonData.Path, cfg.SQLiteDatasourceAllowedPaths)
+		if err != nil {
+			return nil, err
+		}
+
+		engine, release, err := engines.acquire(path)
+		if err != nil {
+			return nil, err
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DriverName:        driverName,
+			DSInfo:            dsInfo,
+			Engine:            engine,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "text", "varchar", "char"},
+			RowLimit:          cfg.DataProxyRowLimit,
+			ByteLimit:         cfg.DataProxyByteLimit,
+			FrameFromRows:     frameFromRows,
+		}
+
+		handler, err := sqleng.NewQueryDataHandler(config, &sqliteQueryResultTransformer{}, newSqliteMacroEngine(), logger)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		return &instance{DataSourceHandler: handler, engine: engine, release: release}, nil
+	}
+}
+
+// resolvePath returns the path of the database file with its symbolic links resolved, provided that
+// it is inside one of the allowed directories.
+func resolvePath(path string, allowedPaths []string) (string, error) {
+	if len(allowedPaths) == 0 {
+		return "", errNoAllowedPaths
+	}
+	if path == "" {
+		return "", errNoPath
+	}
+	if !filepath.IsAbs(path) {
+		return "", errRelativePath
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open database file: %w", err)
+	}
+
+	for _, allowed := range allowedPaths {
+		dir, err := filepath.EvalSymlinks(allowed)
+		if err != nil {
+			logger.Warn("Failed to resolve allowed path", "path", allowed, "err", err)
+			continue
+		}
+
+		rel, err := filepath.Rel(dir, resolved)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return resolved, nil
+	}
+
+	return "", errPathNotAllowed
+}
+
+// connectionString opens the file read-only, queries wait for up to five seconds while the
+// application writing the file holds a lock on it.
+func connectionString(path string) string {
+	uri := url.URL{Scheme: "file", Path: path}
+	return uri.String() + "?mode=ro&_query_only=true&_busy_timeout=5000"
+}
+
+type cachedEngine struct {
+	engine *xorm.Engine
+	refs   int
+}
+
+// engineCache keeps a single engine per database file.
+type engineCache struct {
+	mu      sync.Mutex
+	engines map[string]*cachedEngine
+}
+
+// acquire returns the engine of the database file at path and the function that releases it.
+func (c *engineCache) acquire(path string) (*xorm.Engine, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.engines[path]
+	if !ok {
+		engine, err := sqleng.NewXormEngine(driverName, connectionString(path))
+		if err != nil {
+			return nil, nil, err
+		}
+		engine.SetMaxIdleConns(2)
+		engine.SetConnMaxLifetime(time.Hour)
+
+		cached = &cachedEngine{engine: engine}
+		c.engines[path] = cached
+	}
+	cached.refs++
+
+	var once sync.Once
+	return cached.engine, func() {
+		once.Do(func() { c.release(path) })
+	}, nil
+}
+
+func (c *engineCache) release(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.engines[path]
+	if !ok {
+		return
+	}
+	cached.refs--
+	if cached.refs > 0 {
+		return
+	}
+
+	delete(c.engines, path)
+	if err := cached.engine.Close(); err != nil {
+		logger.Warn("Failed to close database file", "path", path, "err", err)
+	}
+}
+
+func (s *Service) getDataSourceHandler(pluginCtx backend.PluginContext) (*instance, error) {
+	i, err := s.im.Get(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	return i.(*instance), nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: err.Error(),
+		}, nil
+	}
+
+	var tables int
+	row := dsHandler.engine.DB().QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master")
+	if err := row.Scan(&tables); err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Failed to read the database file: %s", err),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Database connection OK",
+	}, nil
+}
+
+type sqliteQueryResultTransformer struct{}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(err error) error {
+	var driverErr sqlite3.Error
+	if errors.As(err, &driverErr) && driverErr.Code == sqlite3.ErrAuth {
+		return errQueryNotAllowed
+	}
+	return err
+}
+
+// GetConverterList returns no converters, frameFromRows infers the types of the fields from their values.
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
@@ -0,0 +1,189 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePath(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "allowed")
+	other := filepath.Join(root, "allowed-other")
+	require.NoError(t, os.Mkdir(allowed, 0750))
+	require.NoError(t, os.Mkdir(other, 0750))
+
+	dbPath := filepath.Join(allowed, "metrics.db")
+	otherPath := filepath.Join(other, "metrics.db")
+	require.NoError(t, os.WriteFile(dbPath, nil, 0600))
+	require.NoError(t, os.WriteFile(otherPath, nil, 0600))
+	require.NoError(t, os.Symlink(otherPath, filepath.Join(allowed, "link.db")))
+
+	t.Run("Allows files in the allowed directories", func(t *testing.T) {
+		path, err := resolvePath(dbPath, []string{allowed})
+		require.NoError(t, err)
+		require.Equal(t, dbPath, path)
+	})
+
+	t.Run("Rejects files outside the allowed directories", func(t *testing.T) {
+		for _, path := range []string{
+			otherPath,
+			filepath.Join(allowed, "..", "allowed-other", "metrics.db"),
+			filepath.Join(allowed, "link.db"),
+		} {
+			_, err := resolvePath(path, []string{allowed})
+			require.ErrorIs(t, err, errPathNotAllowed, path)
+		}
+	})
+
+	t.Run("Rejects relative paths", func(t *testing.T) {
+		_, err := resolvePath("allowed/metrics.db", []string{allowed})
+		require.ErrorIs(t, err, errRelativePath)
+	})
+
+	t.Run("Rejects every file without allowed directories", func(t *testing.T) {
+		_, err := resolvePath(dbPath, nil)
+		require.ErrorIs(t, err, errNoAllowedPaths)
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "metrics.db")
+	createTestDatabase(t, dbPath)
+
+	cfg := &setting.Cfg{SQLiteDatasourceAllowedPaths: []string{dir}, DataProxyRowLimit: 1000}
+
+	t.Run("Queries time series with macros", func(t *testing.T) {
+		s := ProvideService(cfg)
+		res := runQuery(t, s, pluginContext(1, dbPath), `SELECT $__timeGroupAlias(time, '1h'), host AS metric, avg(value) AS value
+			FROM metrics WHERE $__timeFilter(time) GROUP BY 1, 2 ORDER BY 1`, "time_series")
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Fields, 3)
+		require.True(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC).Equal(frame.Fields[0].At(0).(time.Time)))
+		require.Equal(t, "a", frame.Fields[1].Name)
+		require.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, "b", frame.Fields[2].Name)
+		require.Nil(t, frame.Fields[2].At(1))
+	})
+
+	t.Run("Infers the types of expressions", func(t *testing.T) {
+		s := ProvideService(cfg)
+		res := runQuery(t, s, pluginContext(1, dbPath), "SELECT count(*) AS n, max(value) AS v, min(host) AS h, NULL AS x FROM metrics", "table")
+		require.NoError(t, res.Error)
+
+		fields := res.Frames[0].Fields
+		require.Equal(t, int64(4), *fields[0].At(0).(*int64))
+		require.Equal(t, 4.0, *fields[1].At(0).(*float64))
+		require.Equal(t, "a", *fields[2].At(0).(*string))
+		require.Equal(t, data.FieldTypeNullableString, fields[3].Type())
+	})
+
+	t.Run("Stops reading rows at the byte limit", func(t *testing.T) {
+		s := ProvideService(&setting.Cfg{SQLiteDatasourceAllowedPaths: []string{dir}, DataProxyRowLimit: -1, DataProxyByteLimit: 10})
+		res := runQuery(t, s, pluginContext(1, dbPath), "WITH RECURSIVE c(x) AS (SELECT 'abcd' UNION ALL SELECT x FROM c) SELECT x FROM c", "table")
+		require.NoError(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		require.Contains(t, res.Frames[0].Meta.Notices[0].Text, "byte limit of 10")
+	})
+
+	t.Run("Only allows reading the database file", func(t *testing.T) {
+		s := ProvideService(cfg)
+		for _, query := range []string{
+			"DELETE FROM metrics",
+			"CREATE TABLE other (value TEXT)",
+			"ATTACH DATABASE '" + filepath.Join(t.TempDir(), "other.db") + "' AS other",
+			"PRAGMA query_only = false",
+		} {
+			res := runQuery(t, s, pluginContext(1, dbPath), query, "table")
+			require.Error(t, res.Error, query)
+		}
+
+		res := runQuery(t, s, pluginContext(1, dbPath), "SELECT count(*) FROM metrics", "table")
+		require.NoError(t, res.Error)
+		require.Equal(t, int64(4), *res.Frames[0].Fields[0].At(0).(*int64))
+	})
+
+	t.Run("Checks the health of the data source", func(t *testing.T) {
+		s := ProvideService(cfg)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext(1, dbPath)})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+
+		res, err = s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext(2, "/etc/passwd")})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, errPathNotAllowed.Error(), res.Message)
+	})
+
+	t.Run("Data sources of the same file share its engine", func(t *testing.T) {
+		s := ProvideService(cfg)
+		first, err := s.getDataSourceHandler(pluginContext(1, dbPath))
+		require.NoError(t, err)
+		second, err := s.getDataSourceHandler(pluginContext(2, dbPath))
+		require.NoError(t, err)
+		require.Same(t, first.engine, second.engine)
+		require.Len(t, s.engines.engines, 1)
+
+		first.Dispose()
+		first.Dispose()
+		require.Len(t, s.engines.engines, 1)
+		require.NoError(t, second.engine.Ping())
+
+		second.Dispose()
+		require.Empty(t, s.engines.engines)
+	})
+}
+
+func createTestDatabase(t *testing.T, path string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.Exec(`CREATE TABLE metrics (time TEXT, host TEXT, value REAL);
+		INSERT INTO metrics VALUES
+			('2021-06-01 10:10:00', 'a', 1),
+			('2021-06-01 10:20:00', 'a', 2),
+			('2021-06-01 11:10:00', 'a', 3),
+			('2021-06-01 10:10:00', 'b', 4)`)
+	require.NoError(t, err)
+}
+
+func pluginContext(id int64, path string) backend.PluginContext {
+	jsonData, _ := json.Marshal(map[string]string{"path": path})
+	return backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: id, JSONData: jsonData},
+	}
+}
+
+func runQuery(t *testing.T, s *Service, pluginCtx backend.PluginContext, rawSQL, format string) backend.DataResponse {
+	t.Helper()
+
+	query, err := json.Marshal(map[string]string{"rawSql": rawSQL, "format": format})
+	require.NoError(t, err)
+	res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: pluginCtx,
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      query,
+			TimeRange: backend.TimeRange{From: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)},
+		}},
+	})
+	require.NoError(t, err)
+	return res.Responses["A"]
+}
@@ -0,0 +1,136 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// defaultSearchLimit is the number of traces returned by a search without limit, the same as the query editor.
+const defaultSearchLimit = 20
+
+type searchResponse struct {
+	Traces []searchTrace `json:"traces"`
+}
+
+type searchTrace struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        int64  `json:"durationMs"`
+}
+
+// querySearch finds the traces matching the tags and durations of the query in the time range.
+func (s *Service) querySearch(ctx context.Context, dsInfo *datasourceInfo, model *QueryModel, timeRange backend.TimeRange) backend.DataResponse {
+	request, err := s.createSearchRequest(ctx, dsInfo, model, timeRange)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	body, status, err := s.doRequest(dsInfo.HTTPClient, request)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	if status != http.StatusOK {
+		return backend.DataResponse{Error: fmt.Errorf("failed to search traces Status: %s Body: %s", statusText(status), string(body))}
+	}
+
+	var res searchResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to parse tempo search response: %w", err)}
+	}
+
+	return backend.DataResponse{Frames: data.Frames{searchToFrame(res.Traces)}}
+}
+
+func (s *Service) createSearchRequest(ctx context.Context, dsInfo *datasourceInfo, model *QueryModel, timeRange backend.TimeRange) (*http.Request, error) {
+	tags := strings.TrimSpace(model.Search)
+	if model.ServiceName != "" {
+		tags += fmt.Sprintf(` service.name="%s"`, model.ServiceName)
+	}
+	if model.SpanName != "" {
+		tags += fmt.Sprintf(` name="%s"`, model.SpanName)
+	}
+
+	params := url.Values{}
+	params.Set("tags", strings.TrimSpace(tags))
+
+	for name, value := range map[string]string{"minDuration": model.MinDuration, "maxDuration": model.MaxDuration} {
+		value = strings.ReplaceAll(value, " ", "")
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+		params.Set(name, value)
+	}
+
+	limit := model.Limit
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d", limit)
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	if !timeRange.From.IsZero() && !timeRange.To.IsZero() {
+		params.Set("start", strconv.FormatInt(timeRange.From.Unix(), 10))
+		params.Set("end", strconv.FormatInt(timeRange.To.Unix(), 10))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", dsInfo.URL+"/api/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	s.tlog.Debug("Tempo search request", "url", req.URL.String())
+	return req, nil
+}
+
+// searchToFrame returns a table of the traces found by a search, the most recent first.
+func searchToFrame(traces []searchTrace) *data.Frame {
+	startTimes := make(map[string]int64, len(traces))
+	for _, trace := range traces {
+		startTime, _ := strconv.ParseInt(trace.StartTimeUnixNano, 10, 64)
+		startTimes[trace.TraceID] = startTime
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i].TraceID] > startTimes[traces[j].TraceID]
+	})
+
+	traceIDs := make([]string, 0, len(traces))
+	traceNames := make([]string, 0, len(traces))
+	traceStartTimes := make([]time.Time, 0, len(traces))
+	durations := make([]float64, 0, len(traces))
+
+	for _, trace := range traces {
+		traceIDs = append(traceIDs, trace.TraceID)
+		traceNames = append(traceNames, strings.TrimSpace(trace.RootServiceName+" "+trace.RootTraceName))
+		traceStartTimes = append(traceStartTimes, time.Unix(0, startTimes[trace.TraceID]).UTC())
+		durations = append(durations, float64(trace.DurationMs))
+	}
+
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, traceIDs).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace ID"}),
+		data.NewField("traceName", nil, traceNames).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace name"}),
+		data.NewField("startTime", nil, traceStartTimes).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("duration", nil, durations).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable}
+	return frame
+}
@@ -0,0 +1,294 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// The service graph metrics written to Prometheus by the Tempo metrics generator.
+const (
+	secondsMetric = "traces_service_graph_request_server_seconds_sum"
+	totalsMetric  = "traces_service_graph_request_total"
+	failedMetric  = "traces_service_graph_request_failed_total"
+)
+
+type promVectorResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+type serviceGraphStats struct {
+	total   float64
+	seconds float64
+	failed  float64
+}
+
+type serviceGraphEdge struct {
+	serviceGraphStats
+	source string
+	target string
+}
+
+// queryServiceMap builds the service graph of the time range from the metrics in the linked Prometheus data source.
+func (s *Service) queryServiceMap(ctx context.Context, pluginCtx backend.PluginContext, dsInfo *datasourceInfo, model *QueryModel, timeRange backend.TimeRange) backend.DataResponse {
+	var dataSourceService dataSourceService
+	if s.dataSources != nil {
+		dataSourceService = s.dataSources()
+	}
+	if dsInfo.ServiceMapDatasource == "" || dataSourceService == nil {
+		return backend.DataResponse{Error: fmt.Errorf("no Prometheus data source is configured for the service graph")}
+	}
+
+	matchers, err := parseServiceMapQuery(model.ServiceMapQuery)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	query := &models.GetDataSourceQuery{Uid: dsInfo.ServiceMapDatasource, OrgId: pluginCtx.OrgID}
+	if err := dataSourceService.GetDataSource(ctx, query); err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to get the service graph data source: %w", err)}
+	}
+	if query.Result.Type != models.DS_PROMETHEUS {
+		return backend.DataResponse{Error: fmt.Errorf("the service graph data source must be a Prometheus data source, not %q", query.Result.Type)}
+	}
+	if err := s.checkQueryPermission(ctx, pluginCtx, query.Result); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	client, err := dataSourceService.GetHTTPClient(query.Result, s.httpClientProvider)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	rangeSeconds := int64(timeRange.To.Sub(timeRange.From).Seconds())
+	if rangeSeconds < 1 {
+		rangeSeconds = 1
+	}
+
+	nodes := map[string]*serviceGraphStats{}
+	edges := map[string]*serviceGraphEdge{}
+	for _, metric := range []string{totalsMetric, secondsMetric, failedMetric} {
+		expr := fmt.Sprintf("delta(%s[%ds])", metricSelector(metric, matchers), rangeSeconds)
+		res, err := s.queryPrometheus(ctx, client, query.Result.Url, expr, timeRange.To)
+		if err != nil {
+			return backend.DataResponse{Error: err}
+		}
+		collectServiceGraphMetric(res, metric, nodes, edges)
+	}
+
+	return backend.DataResponse{Frames: serviceGraphToFrames(nodes, edges, rangeSeconds)}
+}
+
+// checkQueryPermission returns models.ErrDataSourceAccessDenied when the user of the request may not query the
+// service graph data source.
+func (s *Service) checkQueryPermission(ctx context.Context, pluginCtx backend.PluginContext, ds *models.DataSource) error {
+	if pluginCtx.User == nil || s.bus == nil {
+		return models.ErrDataSourceAccessDenied
+	}
+
+	userQuery := &models.GetSignedInUserQuery{Login: pluginCtx.User.Login, OrgId: pluginCtx.OrgID}
+	if err := s.bus.Dispatch(ctx, userQuery); err != nil {
+		return fmt.Errorf("failed to get the user of the service graph query: %w", err)
+	}
+
+	filterQuery := &models.DatasourcesPermissionFilterQuery{
+		User:        userQuery.Result,
+		Datasources: []*models.DataSource{ds},
+	}
+	if err := s.bus.Dispatch(ctx, filterQuery); err != nil {
+		if !errors.Is(err, bus.ErrHandlerNotFound) {
+			return err
+		}
+		return nil
+	}
+	if len(filterQuery.Result) == 0 {
+		return models.ErrDataSourceAccessDenied
+	}
+	return nil
+}
+
+// parseServiceMapQuery parses the label matchers of a service map query, e.g. {client="app", server=~"db.*"}.
+func parseServiceMapQuery(query string) ([]*labels.Matcher, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+
+	matchers, err := parser.ParseMetricSelector(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service map query %q: %w", query, err)
+	}
+	for _, m := range matchers {
+		if m.Name == labels.MetricName {
+			return nil, fmt.Errorf("invalid service map query %q: the metric name can not be selected", query)
+		}
+	}
+	return matchers, nil
+}
+
+func metricSelector(metric string, matchers []*labels.Matcher) string {
+	selectors := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		selectors = append(selectors, m.String())
+	}
+	return metric + "{" + strings.Join(selectors, ",") + "}"
+}
+
+func (s *Service) queryPrometheus(ctx context.Context, client *http.Client, promURL string, expr string, at time.Time) (*promVectorResponse, error) {
+	params := url.Values{}
+	params.Set("query", expr)
+	params.Set("time", strconv.FormatInt(at.Unix(), 10))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", promURL+"/api/v1/query?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, status, err := s.doRequest(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res promVectorResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("failed to query service graph metrics Status: %s Body: %s", statusText(status), string(body))
+	}
+	if status != http.StatusOK || res.Status != "success" {
+		return nil, fmt.Errorf("failed to query service graph metrics Status: %s Error: %s", statusText(status), res.Error)
+	}
+	return &res, nil
+}
+
+// collectServiceGraphMetric adds the values of a metric to the edges between client and server, and to the
+// server nodes. The client nodes are added without stats, a node shows the requests it handled.
+func collectServiceGraphMetric(res *promVectorResponse, metric string, nodes map[string]*serviceGraphStats, edges map[string]*serviceGraphEdge) {
+	add := func(stats *serviceGraphStats, value float64) {
+		switch metric {
+		case totalsMetric:
+			stats.total += value
+		case secondsMetric:
+			stats.seconds += value
+		case failedMetric:
+			stats.failed += value
+		}
+	}
+
+	for _, sample := range res.Data.Result {
+		if len(sample.Value) != 2 {
+			continue
+		}
+		valueText, _ := sample.Value[1].(string)
+		value, err := strconv.ParseFloat(valueText, 64)
+		if err != nil {
+			continue
+		}
+
+		client, server := sample.Metric["client"], sample.Metric["server"]
+		edgeID := client + "_" + server
+		if _, ok := edges[edgeID]; !ok {
+			edges[edgeID] = &serviceGraphEdge{source: client, target: server}
+		}
+		add(&edges[edgeID].serviceGraphStats, value)
+
+		if _, ok := nodes[server]; !ok {
+			nodes[server] = &serviceGraphStats{}
+		}
+		add(nodes[server], value)
+
+		if _, ok := nodes[client]; !ok {
+			nodes[client] = &serviceGraphStats{}
+		}
+	}
+}
+
+func serviceGraphToFrames(nodes map[string]*serviceGraphStats, edges map[string]*serviceGraphEdge, rangeSeconds int64) data.Frames {
+	nodeIDs := make([]string, 0, len(nodes))
+	for id := range nodes {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Strings(nodeIDs)
+
+	var ids, titles []string
+	var responseTimes, requestRates, success, failed []float64
+	for _, id := range nodeIDs {
+		node := nodes[id]
+		ids = append(ids, id)
+		titles = append(titles, id)
+		if node.total > 0 {
+			responseTimes = append(responseTimes, node.seconds/node.total*1000)
+			requestRates = append(requestRates, math.Round(node.total/float64(rangeSeconds)*100)/100)
+			success = append(success, (node.total-node.failed)/node.total)
+			failed = append(failed, node.failed/node.total)
+		} else {
+			// a root client node did not handle any requests itself, NaN is not shown in the node graph
+			responseTimes = append(responseTimes, math.NaN())
+			requestRates = append(requestRates, math.NaN())
+			success = append(success, 1)
+			failed = append(failed, 0)
+		}
+	}
+
+	nodesFrame := data.NewFrame("Nodes",
+		data.NewField("id", nil, ids),
+		data.NewField("title", nil, titles).SetConfig(&data.FieldConfig{DisplayName: "Service name"}),
+		data.NewField("mainStat", nil, responseTimes).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/r"}),
+		data.NewField("secondaryStat", nil, requestRates).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "r/sec"}),
+		data.NewField("arc__success", nil, success).SetConfig(&data.FieldConfig{DisplayName: "Success", Color: map[string]interface{}{"mode": "fixed", "fixedColor": "green"}}),
+		data.NewField("arc__failed", nil, failed).SetConfig(&data.FieldConfig{DisplayName: "Failed", Color: map[string]interface{}{"mode": "fixed", "fixedColor": "red"}}),
+	)
+	nodesFrame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	edgeIDs := make([]string, 0, len(edges))
+	for id := range edges {
+		edgeIDs = append(edgeIDs, id)
+	}
+	sort.Strings(edgeIDs)
+
+	var edgeIDValues, sources, targets []string
+	var requests, edgeResponseTimes []float64
+	for _, id := range edgeIDs {
+		edge := edges[id]
+		edgeIDValues = append(edgeIDValues, id)
+		sources = append(sources, edge.source)
+		targets = append(targets, edge.target)
+		requests = append(requests, edge.total)
+		if edge.total > 0 {
+			edgeResponseTimes = append(edgeResponseTimes, edge.seconds/edge.total*1000)
+		} else {
+			edgeResponseTimes = append(edgeResponseTimes, math.NaN())
+		}
+	}
+
+	edgesFrame := data.NewFrame("Edges",
+		data.NewField("id", nil, edgeIDValues),
+		data.NewField("source", nil, sources),
+		data.NewField("target", nil, targets),
+		data.NewField("mainStat", nil, requests).SetConfig(&data.FieldConfig{DisplayName: "Requests", Unit: "r"}),
+		data.NewField("secondaryStat", nil, edgeResponseTimes).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/r"}),
+	)
+	edgesFrame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	return data.Frames{nodesFrame, edgesFrame}
+}
@@ -11,30 +11,73 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"go.opentelemetry.io/collector/model/otlp"
 )
 
 type Service struct {
-	im   instancemgmt.InstanceManager
-	tlog log.Logger
+	im                 instancemgmt.InstanceManager
+	tlog               log.Logger
+	httpClientProvider httpclient.Provider
+	bus                bus.Bus
+	dataSources        func() dataSourceService
 }
 
-func ProvideService(httpClientProvider httpclient.Provider) *Service {
+// dataSourceService is used to query the Prometheus data source holding the service graph metrics.
+type dataSourceService interface {
+	GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error
+	GetHTTPClient(ds *models.DataSource, provider httpclient.Provider) (*http.Client, error)
+}
+
+// ProvideService creates the Tempo service. The data sources service depends on the plugins that include
+// Tempo, so it is looked up through the provider when a service graph is queried.
+func ProvideService(httpClientProvider httpclient.Provider, bus bus.Bus, dataSources *datasources.ServiceProvider) *Service {
 	return &Service{
-		tlog: log.New("tsdb.tempo"),
-		im:   datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		tlog:               log.New("tsdb.tempo"),
+		im:                 datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		httpClientProvider: httpClientProvider,
+		bus:                bus,
+		dataSources: func() dataSourceService {
+			if s := dataSources.Get(); s != nil {
+				return s
+			}
+			return nil
+		},
 	}
 }
 
+const (
+	queryTypeTraceID    = "traceId"
+	queryTypeSearch     = "nativeSearch"
+	queryTypeServiceMap = "serviceMap"
+)
+
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient           *http.Client
+	URL                  string
+	ServiceMapDatasource string
+}
+
+type datasourceJSONData struct {
+	ServiceMap struct {
+		DatasourceUID string `json:"datasourceUid"`
+	} `json:"serviceMap"`
 }
 
 type QueryModel struct {
-	TraceID string `json:"query"`
+	QueryType       string `json:"queryType"`
+	TraceID         string `json:"query"`
+	Search          string `json:"search"`
+	ServiceName     string `json:"serviceName"`
+	SpanName        string `json:"spanName"`
+	MinDuration     string `json:"minDuration"`
+	MaxDuration     string `json:"maxDuration"`
+	Limit           int    `json:"limit"`
+	ServiceMapQuery string `json:"serviceMapQuery"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -49,9 +92,17 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := datasourceJSONData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:           client,
+			URL:                  settings.URL,
+			ServiceMapDatasource: jsonData.ServiceMap.DatasourceUID,
 		}
 		return model, nil
 	}
@@ -59,62 +110,89 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
-	queryRes := backend.DataResponse{}
-	refID := req.Queries[0].RefID
-
-	model := &QueryModel{}
-	err := json.Unmarshal(req.Queries[0].JSON, model)
-	if err != nil {
-		return result, err
-	}
 
 	dsInfo, err := s.getDSInfo(req.PluginContext)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, query := range req.Queries {
+		model := &QueryModel{}
+		if err := json.Unmarshal(query.JSON, model); err != nil {
+			return result, err
+		}
+
+		var queryRes backend.DataResponse
+		switch model.QueryType {
+		case "", queryTypeTraceID:
+			queryRes = s.queryTrace(ctx, dsInfo, model)
+		case queryTypeSearch:
+			queryRes = s.querySearch(ctx, dsInfo, model, query.TimeRange)
+		case queryTypeServiceMap:
+			queryRes = s.queryServiceMap(ctx, req.PluginContext, dsInfo, model, query.TimeRange)
+		default:
+			queryRes = backend.DataResponse{Error: fmt.Errorf("unsupported query type: %q", model.QueryType)}
+		}
+
+		for _, frame := range queryRes.Frames {
+			frame.RefID = query.RefID
+		}
+		result.Responses[query.RefID] = queryRes
+	}
+
+	return result, nil
+}
+
+// queryTrace fetches a trace by its id.
+func (s *Service) queryTrace(ctx context.Context, dsInfo *datasourceInfo, model *QueryModel) backend.DataResponse {
 	request, err := s.createRequest(ctx, dsInfo, model.TraceID)
 	if err != nil {
-		return result, err
+		return backend.DataResponse{Error: err}
 	}
 
-	resp, err := dsInfo.HTTPClient.Do(request)
+	body, status, err := s.doRequest(dsInfo.HTTPClient, request)
 	if err != nil {
-		return result, fmt.Errorf("failed get to tempo: %w", err)
+		return backend.DataResponse{Error: err}
 	}
 
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			s.tlog.Warn("failed to close response body", "err", err)
-		}
-	}()
+	if status != http.StatusOK {
+		return backend.DataResponse{Error: fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", model.TraceID, statusText(status), string(body))}
+	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	otTrace, err := otlp.NewProtobufTracesUnmarshaler().UnmarshalTraces(body)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return backend.DataResponse{Error: fmt.Errorf("failed to convert tempo response to Otlp: %w", err)}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		queryRes.Error = fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", model.TraceID, resp.Status, string(body))
-		result.Responses[refID] = queryRes
-		return result, nil
+	frame, err := TraceToFrame(otTrace)
+	if err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to transform trace %v to data frame: %w", model.TraceID, err)}
 	}
+	return backend.DataResponse{Frames: []*data.Frame{frame}}
+}
 
-	otTrace, err := otlp.NewProtobufTracesUnmarshaler().UnmarshalTraces(body)
+func statusText(status int) string {
+	return fmt.Sprintf("%d %s", status, http.StatusText(status))
+}
 
+// doRequest executes a request and returns the body and status code of the response.
+func (s *Service) doRequest(client *http.Client, request *http.Request) ([]byte, int, error) {
+	resp, err := client.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
+		return nil, 0, fmt.Errorf("failed get to tempo: %w", err)
 	}
 
-	frame, err := TraceToFrame(otTrace)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.tlog.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return &backend.QueryDataResponse{}, fmt.Errorf("failed to transform trace %v to data frame: %w", model.TraceID, err)
+		return nil, 0, err
 	}
-	frame.RefID = refID
-	frames := []*data.Frame{frame}
-	queryRes.Frames = frames
-	result.Responses[refID] = queryRes
-	return result, nil
+	return body, resp.StatusCode, nil
 }
 
 func (s *Service) createRequest(ctx context.Context, dsInfo *datasourceInfo, traceID string) (*http.Request, error) {
@@ -2,9 +2,22 @@ package tempo
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,4 +29,202 @@ func TestTempo(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(req.Header))
 	})
+
+	t.Run("createSearchRequest - builds the tags and validates durations", func(t *testing.T) {
+		service := &Service{tlog: log.New("tempo-test")}
+		timeRange := backend.TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)}
+		req, err := service.createSearchRequest(context.Background(), &datasourceInfo{URL: "http://tempo"}, &QueryModel{
+			Search:      "http.status_code=500",
+			ServiceName: "app",
+			MinDuration: "1 s",
+		}, timeRange)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/search", req.URL.Path)
+		query := req.URL.Query()
+		assert.Equal(t, `http.status_code=500 service.name="app"`, query.Get("tags"))
+		assert.Equal(t, "1s", query.Get("minDuration"))
+		assert.Equal(t, "20", query.Get("limit"))
+		assert.Equal(t, "1000", query.Get("start"))
+		assert.Equal(t, "2000", query.Get("end"))
+
+		_, err = service.createSearchRequest(context.Background(), &datasourceInfo{}, &QueryModel{MaxDuration: "forever"}, timeRange)
+		require.Error(t, err)
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	trace, err := ioutil.ReadFile("testData/tempo_proto_response")
+	require.NoError(t, err)
+
+	tempo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/traces/found":
+			_, _ = w.Write(trace)
+		case r.URL.Path == "/api/search":
+			_, _ = w.Write([]byte(`{"traces": [
+				{"traceID": "older", "rootServiceName": "app", "rootTraceName": "GET /", "startTimeUnixNano": "1616072924070000000", "durationMs": 8},
+				{"traceID": "newer", "rootServiceName": "app", "rootTraceName": "POST /", "startTimeUnixNano": "1616072925070000000", "durationMs": 120}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(tempo.Close)
+
+	var promQueries []string
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		promQueries = append(promQueries, query)
+		value := "10"
+		switch {
+		case strings.Contains(query, secondsMetric):
+			value = "2"
+		case strings.Contains(query, failedMetric):
+			value = "1"
+		}
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "vector", "result": [
+			{"metric": {"client": "gateway", "server": "app"}, "value": [2000, "` + value + `"]}
+		]}}`))
+	}))
+	t.Cleanup(prometheus.Close)
+
+	service := &Service{
+		tlog: log.New("tempo-test"),
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &datasourceInfo{HTTPClient: tempo.Client(), URL: tempo.URL, ServiceMapDatasource: "prom"}, nil
+		}),
+		bus:         newFakeBus(),
+		dataSources: func() dataSourceService { return &fakeDataSourceService{url: prometheus.URL} },
+	}
+
+	timeRange := backend.TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)}
+	newQuery := func(refID string, model QueryModel) backend.DataQuery {
+		body, err := json.Marshal(model)
+		require.NoError(t, err)
+		return backend.DataQuery{RefID: refID, JSON: body, TimeRange: timeRange}
+	}
+
+	pluginCtx := backend.PluginContext{
+		OrgID:                      1,
+		User:                       &backend.User{Login: "viewer"},
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{},
+	}
+	res, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: pluginCtx,
+		Queries: []backend.DataQuery{
+			newQuery("A", QueryModel{TraceID: "found"}),
+			newQuery("B", QueryModel{QueryType: queryTypeTraceID, TraceID: "missing"}),
+			newQuery("C", QueryModel{QueryType: queryTypeSearch, MinDuration: "5ms"}),
+			newQuery("D", QueryModel{QueryType: queryTypeServiceMap, ServiceMapQuery: `{client="gateway"}`}),
+			newQuery("E", QueryModel{QueryType: "upload"}),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Responses, 5)
+
+	t.Run("all queries of the request are handled", func(t *testing.T) {
+		require.NoError(t, res.Responses["A"].Error)
+		require.Equal(t, 30, res.Responses["A"].Frames[0].Rows())
+		require.Equal(t, "A", res.Responses["A"].Frames[0].RefID)
+
+		require.Error(t, res.Responses["B"].Error)
+		require.Contains(t, res.Responses["B"].Error.Error(), "404 Not Found")
+
+		require.EqualError(t, res.Responses["E"].Error, `unsupported query type: "upload"`)
+	})
+
+	t.Run("search returns a table of traces, the most recent first", func(t *testing.T) {
+		require.NoError(t, res.Responses["C"].Error)
+		frame := res.Responses["C"].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "newer", frame.Fields[0].At(0))
+		require.Equal(t, "app POST /", frame.Fields[1].At(0))
+		require.Equal(t, time.Unix(0, 1616072925070000000).UTC(), frame.Fields[2].At(0))
+		require.Equal(t, float64(120), frame.Fields[3].At(0))
+	})
+
+	t.Run("service map returns the nodes and edges of the service graph", func(t *testing.T) {
+		require.NoError(t, res.Responses["D"].Error)
+		frames := res.Responses["D"].Frames
+		require.Len(t, frames, 2)
+
+		nodes := frames[0]
+		require.Equal(t, 2, nodes.Rows())
+		require.Equal(t, "app", nodes.Fields[0].At(0))
+		require.Equal(t, float64(200), nodes.Fields[2].At(0))
+		require.Equal(t, 0.01, nodes.Fields[3].At(0))
+		require.Equal(t, 0.9, nodes.Fields[4].At(0))
+		require.Equal(t, "gateway", nodes.Fields[0].At(1))
+		require.True(t, math.IsNaN(nodes.Fields[2].At(1).(float64)))
+
+		edges := frames[1]
+		require.Equal(t, 1, edges.Rows())
+		require.Equal(t, "gateway_app", edges.Fields[0].At(0))
+		require.Equal(t, float64(10), edges.Fields[3].At(0))
+
+		require.Equal(t, []string{
+			`delta(traces_service_graph_request_total{client="gateway"}[1000s])`,
+			`delta(traces_service_graph_request_server_seconds_sum{client="gateway"}[1000s])`,
+			`delta(traces_service_graph_request_failed_total{client="gateway"}[1000s])`,
+		}, promQueries)
+	})
+
+	t.Run("service map only accepts label matchers", func(t *testing.T) {
+		for _, query := range []string{`{client="gateway"}) or vector(1`, `up`, `{__name__="up"}`} {
+			res := service.queryServiceMap(context.Background(), pluginCtx, &datasourceInfo{ServiceMapDatasource: "prom"}, &QueryModel{ServiceMapQuery: query}, timeRange)
+			require.Error(t, res.Error, query)
+			require.Contains(t, res.Error.Error(), "invalid service map query")
+		}
+	})
+
+	t.Run("service map requires a Prometheus data source", func(t *testing.T) {
+		res := service.queryServiceMap(context.Background(), pluginCtx, &datasourceInfo{ServiceMapDatasource: "loki"}, &QueryModel{}, timeRange)
+		require.EqualError(t, res.Error, `the service graph data source must be a Prometheus data source, not "loki"`)
+	})
+
+	t.Run("service map requires permission to query the data source", func(t *testing.T) {
+		denied := pluginCtx
+		denied.User = &backend.User{Login: "denied"}
+		res := service.queryServiceMap(context.Background(), denied, &datasourceInfo{ServiceMapDatasource: "prom"}, &QueryModel{}, timeRange)
+		require.ErrorIs(t, res.Error, models.ErrDataSourceAccessDenied)
+	})
+}
+
+// newFakeBus returns a bus where the user "denied" may not query any data source.
+func newFakeBus() bus.Bus {
+	b := bus.New()
+	b.AddHandler(func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+		query.Result = &models.SignedInUser{Login: query.Login, OrgId: query.OrgId}
+		return nil
+	})
+	b.AddHandler(func(ctx context.Context, query *models.DatasourcesPermissionFilterQuery) error {
+		if query.User.Login != "denied" {
+			query.Result = query.Datasources
+		}
+		return nil
+	})
+	return b
+}
+
+type fakeDataSourceService struct {
+	url string
+}
+
+func (s *fakeDataSourceService) GetDataSource(ctx context.Context, query *models.GetDataSourceQuery) error {
+	if query.OrgId != 1 {
+		return models.ErrDataSourceNotFound
+	}
+	switch query.Uid {
+	case "prom":
+		query.Result = &models.DataSource{Uid: query.Uid, Type: models.DS_PROMETHEUS, Url: s.url}
+	case "loki":
+		query.Result = &models.DataSource{Uid: query.Uid, Type: "loki"}
+	default:
+		return models.ErrDataSourceNotFound
+	}
+	return nil
+}
+
+func (s *fakeDataSourceService) GetHTTPClient(ds *models.DataSource, provider httpclient.Provider) (*http.Client, error) {
+	return http.DefaultClient, nil
 }
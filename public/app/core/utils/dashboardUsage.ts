@@ -0,0 +1,40 @@
+import { lastValueFrom } from 'rxjs';
+import { getBackendSrv } from '@grafana/runtime';
+
+export interface DashboardUsage {
+  total: number;
+  dashboards: Array<{ uid: string; title: string; url: string }>;
+}
+
+const maxListedDashboards = 5;
+
+/**
+ * Returns a warning naming the dashboards that use a data source or a plugin, given the url of its usage API.
+ * Returns undefined when no dashboard uses it or when the usage cannot be loaded.
+ */
+export async function getDashboardUsageWarning(url: string): Promise<string | undefined> {
+  try {
+    const response = await lastValueFrom(
+      getBackendSrv().fetch<DashboardUsage>({ method: 'GET', url, showErrorAlert: false })
+    );
+    return formatDashboardUsage(response.data);
+  } catch (err) {
+    console.log('Failed to load dashboard usage', err);
+    return undefined;
+  }
+}
+
+export function formatDashboardUsage(usage: DashboardUsage): string | undefined {
+  if (!usage.total) {
+    return undefined;
+  }
+
+  const titles = usage.dashboards.slice(0, maxListedDashboards).map((d) => d.title);
+  const others = usage.total - titles.length;
+  let list = titles.join(', ');
+  if (others > 0) {
+    list += titles.length ? ` and ${others} other${others > 1 ? 's' : ''}` : `${others}`;
+  }
+
+  return `Used by ${usage.total === 1 ? 'one dashboard' : `${usage.total} dashboards`}: ${list}.`;
+}
@@ -1,6 +1,8 @@
-import React, { useEffect } from 'react';
-import { Button, ConfirmModal, Modal, stylesFactory, useTheme } from '@grafana/ui';
+import React, { useEffect, useState } from 'react';
+import { lastValueFrom } from 'rxjs';
+import { Alert, Button, ConfirmModal, Modal, stylesFactory, useTheme } from '@grafana/ui';
 import { GrafanaTheme } from '@grafana/data';
+import { getBackendSrv, locationService } from '@grafana/runtime';
 import { css } from '@emotion/css';
 import { DashboardModel } from 'app/features/dashboard/state';
 import { useDashboardSave } from './useDashboardSave';
@@ -33,19 +35,9 @@ export const SaveDashboardErrorProxy: React.FC<SaveDashboardErrorProxyProps> = (
   return (
     <>
       {error.data && error.data.status === 'version-mismatch' && (
-        <ConfirmModal
-          isOpen={true}
-          title="Conflict"
-          body={
-            <div>
-              Someone else has updated this dashboard <br /> <small>Would you still like to save this dashboard?</small>
-            </div>
-          }
-          confirmText="Save and overwrite"
-          onConfirm={async () => {
-            await onDashboardSave(dashboardSaveModel, { overwrite: true }, dashboard);
-            onDismiss();
-          }}
+        <ConfirmVersionMismatchSaveModal
+          dashboard={dashboard}
+          dashboardSaveModel={dashboardSaveModel}
           onDismiss={onDismiss}
         />
       )}
@@ -74,6 +66,91 @@ export const SaveDashboardErrorProxy: React.FC<SaveDashboardErrorProxyProps> = (
   );
 };
 
+interface MergeConflict {
+  description: string;
+}
+
+interface MergeDashboardResult {
+  dashboard: any;
+  conflicts: MergeConflict[];
+}
+
+const ConfirmVersionMismatchSaveModal: React.FC<Omit<SaveDashboardErrorProxyProps, 'error'>> = ({
+  dashboard,
+  dashboardSaveModel,
+  onDismiss,
+}) => {
+  const theme = useTheme();
+  const { onDashboardSave } = useDashboardSave(dashboard);
+  const styles = getConfirmPluginDashboardSaveModalStyles(theme);
+  const [isMerging, setIsMerging] = useState(false);
+  const [mergeError, setMergeError] = useState<string>();
+  const [conflicts, setConflicts] = useState<MergeConflict[]>([]);
+
+  const onMerge = async () => {
+    setIsMerging(true);
+    try {
+      const result = await lastValueFrom(
+        getBackendSrv().fetch<MergeDashboardResult>({
+          method: 'POST',
+          url: '/api/dashboards/merge',
+          data: { dashboard: dashboardSaveModel },
+          showErrorAlert: false,
+        })
+      );
+      await onDashboardSave(result.data.dashboard, {}, dashboard);
+      onDismiss();
+      // the merged dashboard contains the changes saved by someone else
+      locationService.reload();
+    } catch (err) {
+      if (err.status === 409 && err.data?.conflicts) {
+        setConflicts(err.data.conflicts);
+      } else {
+        setMergeError(err.data?.message ?? 'Unable to merge the changes');
+      }
+    } finally {
+      setIsMerging(false);
+    }
+  };
+
+  return (
+    <Modal title="Conflict" icon="exclamation-triangle" isOpen={true} onDismiss={onDismiss}>
+      <div className={styles.modalText}>
+        Someone else has updated this dashboard
+        <br />
+        <small>Would you like to merge your changes with theirs or to overwrite them?</small>
+      </div>
+      {conflicts.length > 0 && (
+        <Alert severity="warning" title="Your changes conflict with the saved dashboard">
+          <ul>
+            {conflicts.map((conflict, i) => (
+              <li key={i}>{conflict.description}</li>
+            ))}
+          </ul>
+        </Alert>
+      )}
+      {mergeError && <Alert severity="error" title={mergeError} />}
+      <Modal.ButtonRow>
+        <Button variant="secondary" onClick={onDismiss} fill="outline">
+          Cancel
+        </Button>
+        <Button variant="primary" onClick={onMerge} disabled={isMerging || conflicts.length > 0}>
+          {isMerging ? 'Merging' : 'Merge and save'}
+        </Button>
+        <Button
+          variant="destructive"
+          onClick={async () => {
+            await onDashboardSave(dashboardSaveModel, { overwrite: true }, dashboard);
+            onDismiss();
+          }}
+        >
+          Save and overwrite
+        </Button>
+      </Modal.ButtonRow>
+    </Modal>
+  );
+};
+
 const ConfirmPluginDashboardSaveModal: React.FC<SaveDashboardModalProps> = ({ onDismiss, dashboard }) => {
   const theme = useTheme();
   const { onDashboardSave } = useDashboardSave(dashboard);
@@ -0,0 +1,112 @@
+import React, { Component } from 'react';
+import { connect, ConnectedProps } from 'react-redux';
+import { Unsubscribable } from 'rxjs';
+import { saveAs } from 'file-saver';
+import { dateTimeFormat, LoadingState, PanelData, toCSV } from '@grafana/data';
+import { initDashboard } from '../state/initDashboard';
+import { StoreState } from 'app/types';
+import { PanelModel } from 'app/features/dashboard/state';
+import { GrafanaRouteComponentProps } from 'app/core/navigation/types';
+import { DashboardPageRouteParams, SoloPanel } from './SoloPanelPage';
+
+const mapStateToProps = (state: StoreState) => ({
+  dashboard: state.dashboard.getModel(),
+});
+
+const mapDispatchToProps = {
+  initDashboard,
+};
+
+const connector = connect(mapStateToProps, mapDispatchToProps);
+
+export type Props = GrafanaRouteComponentProps<DashboardPageRouteParams, { panelId: string }> &
+  ConnectedProps<typeof connector>;
+
+export interface State {
+  panel: PanelModel | null;
+  notFound: boolean;
+}
+
+/**
+ * Renders a single panel like the solo panel page and downloads the data of the panel as CSV once its queries are
+ * done. Used by the image renderer to export the data of a panel.
+ */
+export class CSVPanelPage extends Component<Props, State> {
+  state: State = {
+    panel: null,
+    notFound: false,
+  };
+
+  private subscription?: Unsubscribable;
+
+  componentDidMount() {
+    const { match, route } = this.props;
+
+    this.props.initDashboard({
+      urlSlug: match.params.slug,
+      urlUid: match.params.uid,
+      urlType: match.params.type,
+      routeName: route.routeName,
+      fixUrl: false,
+    });
+  }
+
+  componentWillUnmount() {
+    this.subscription?.unsubscribe();
+  }
+
+  getPanelId(): number {
+    return parseInt(this.props.queryParams.panelId ?? '0', 10);
+  }
+
+  componentDidUpdate(prevProps: Props) {
+    const { dashboard } = this.props;
+
+    if (!dashboard) {
+      return;
+    }
+
+    // we just got a new dashboard
+    if (!prevProps.dashboard || prevProps.dashboard.uid !== dashboard.uid) {
+      const panel = dashboard.getPanelByUrlId(this.props.queryParams.panelId);
+
+      if (!panel) {
+        this.setState({ notFound: true });
+        return;
+      }
+
+      this.subscription?.unsubscribe();
+      this.subscription = panel
+        .getQueryRunner()
+        .getData({ withTransforms: true, withFieldConfig: false })
+        .subscribe((data) => this.onData(panel, data));
+
+      this.setState({ panel });
+    }
+  }
+
+  onData(panel: PanelModel, data: PanelData) {
+    if (data.state !== LoadingState.Done && data.state !== LoadingState.Error) {
+      return;
+    }
+    this.subscription?.unsubscribe();
+
+    const blob = new Blob([String.fromCharCode(0xfeff), toCSV(data.series)], {
+      type: 'text/csv;charset=utf-8',
+    });
+    saveAs(blob, `${panel.getDisplayTitle()}-data-${dateTimeFormat(new Date())}.csv`);
+  }
+
+  render() {
+    return (
+      <SoloPanel
+        dashboard={this.props.dashboard}
+        notFound={this.state.notFound}
+        panel={this.state.panel}
+        panelId={this.getPanelId()}
+      />
+    );
+  }
+}
+
+export default connector(CSVPanelPage);
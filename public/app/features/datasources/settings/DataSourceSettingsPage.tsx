@@ -32,6 +32,7 @@ import { GrafanaRouteComponentProps } from 'app/core/navigation/types';
 import { connect, ConnectedProps } from 'react-redux';
 import { cleanUpAction } from 'app/core/actions/cleanUp';
 import { ShowConfirmModalEvent } from '../../../types/events';
+import { getDashboardUsageWarning } from 'app/core/utils/dashboardUsage';
 
 export interface OwnProps extends GrafanaRouteComponentProps<{ uid: string }> {}
 
@@ -107,11 +108,14 @@ export class DataSourceSettingsPage extends PureComponent<Props> {
     this.testDataSource();
   };
 
-  onDelete = () => {
+  onDelete = async () => {
+    const usageWarning = await getDashboardUsageWarning(`/api/datasources/uid/${this.props.dataSource.uid}/usage`);
+
     appEvents.publish(
       new ShowConfirmModalEvent({
         title: 'Delete',
         text: `Are you sure you want to delete the "${this.props.dataSource.name}" data source?`,
+        text2: usageWarning,
         yesText: 'Delete',
         icon: 'trash-alt',
         onConfirm: () => {
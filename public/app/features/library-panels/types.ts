@@ -39,6 +39,8 @@ export interface LibraryElementDTO {
   model: any;
   version: number;
   meta: LibraryElementDTOMeta;
+  /** The version a dashboard is pinned to, unset when the dashboard follows the latest version */
+  pinnedVersion?: number;
 }
 
 export interface LibraryElementDTOMeta {
@@ -57,7 +59,7 @@ export interface LibraryElementDTOMetaUser {
   avatarUrl: string;
 }
 
-export type PanelModelLibraryPanel = Pick<LibraryElementDTO, 'uid' | 'name' | 'meta' | 'version'>;
+export type PanelModelLibraryPanel = Pick<LibraryElementDTO, 'uid' | 'name' | 'meta' | 'version' | 'pinnedVersion'>;
 
 export interface PanelModelWithLibraryPanel extends PanelModel {
   libraryPanel: PanelModelLibraryPanel;
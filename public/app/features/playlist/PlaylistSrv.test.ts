@@ -132,4 +132,25 @@ describe('PlaylistSrv', () => {
     expect((srv as any).validPlaylistUrl).toBe('/dash2');
     expect(srv.isPlaying).toBe(true);
   });
+
+  it('should open dashboards with the variables and time range of their item', async () => {
+    getMock.mockImplementation(
+      jest.fn((url) => {
+        switch (url) {
+          case '/api/playlists/1':
+            return Promise.resolve({ interval: '1s' });
+          case '/api/playlists/1/dashboards':
+            return Promise.resolve([{ url: '/d/abc/overview?from=now-1h&var-region=eu', interval: '30s' }]);
+          default:
+            throw new Error(`Unexpected url=${url}`);
+        }
+      })
+    );
+
+    await srv.start(1);
+
+    expect(locationService.getLocation().pathname).toBe('/d/abc/overview');
+    expect(locationService.getSearchObject()).toEqual({ from: 'now-1h', 'var-region': 'eu' });
+    expect(srv.isPlaying).toBe(true);
+  });
 });
@@ -6,6 +6,8 @@ import { getBackendSrv, locationService } from '@grafana/runtime';
 import { locationUtil, urlUtil, rangeUtil } from '@grafana/data';
 import { Location } from 'history';
 
+import { PlaylistDashboard } from './types';
+
 export const queryParamsToPreserve: { [key: string]: boolean } = {
   kiosk: true,
   autofitpanels: true,
@@ -14,7 +16,7 @@ export const queryParamsToPreserve: { [key: string]: boolean } = {
 
 export class PlaylistSrv {
   private nextTimeoutId: any;
-  private declare dashboards: Array<{ url: string }>;
+  private declare dashboards: PlaylistDashboard[];
   private index = 0;
   private declare interval: number;
   private declare startUrl: string;
@@ -47,13 +49,16 @@ export class PlaylistSrv {
     const dash = this.dashboards[this.index];
     const queryParams = locationService.getSearchObject();
     const filteredParams = pickBy(queryParams, (value: any, key: string) => queryParamsToPreserve[key]);
-    const nextDashboardUrl = locationUtil.stripBaseFromUrl(dash.url);
+    // items can add variable values and a time range to the dashboard url
+    const [nextDashboardUrl, dashboardParams] = locationUtil.stripBaseFromUrl(dash.url).split('?');
+    const params = { ...urlUtil.parseKeyValue(dashboardParams ?? ''), ...filteredParams };
+    const interval = dash.interval ? rangeUtil.intervalToMs(dash.interval) : this.interval;
 
     this.index++;
     this.validPlaylistUrl = nextDashboardUrl;
-    this.nextTimeoutId = setTimeout(() => this.next(), this.interval);
+    this.nextTimeoutId = setTimeout(() => this.next(), interval);
 
-    locationService.push(nextDashboardUrl + '?' + urlUtil.toUrlParams(filteredParams));
+    locationService.push(nextDashboardUrl + '?' + urlUtil.toUrlParams(params));
   }
 
   prev() {
@@ -83,7 +88,7 @@ export class PlaylistSrv {
       .then((playlist: any) => {
         return getBackendSrv()
           .get(`/api/playlists/${playlistId}/dashboards`)
-          .then((dashboards: any) => {
+          .then((dashboards: PlaylistDashboard[]) => {
             this.dashboards = dashboards;
             this.interval = rangeUtil.intervalToMs(playlist.interval);
             this.next();
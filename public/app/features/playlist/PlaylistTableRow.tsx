@@ -39,6 +39,18 @@ export const PlaylistTableRow: FC<PlaylistTableRowProps> = ({ item, onDelete, on
           <span>{item.title}</span>
         </td>
       ) : null}
+      {item.type === 'dashboard_by_uid' ? (
+        <td className={cx(styles.td, styles.item)}>
+          <Icon name="apps" />
+          <span>{item.title}</span>
+        </td>
+      ) : null}
+      {item.type === 'dashboard_search' ? (
+        <td className={cx(styles.td, styles.item)}>
+          <Icon name="search" />
+          <span>{item.title}</span>
+        </td>
+      ) : null}
       {item.type === 'dashboard_by_tag' ? (
         <td className={cx(styles.td, styles.item)}>
           <Icon name="tag-alt" aria-label={selectors.pages.PlaylistForm.itemTagType} />
@@ -14,6 +14,7 @@ export interface PlayListItemDTO {
 }
 
 export interface Playlist {
+  uid?: string;
   name: string;
   interval: string;
   items?: PlaylistItem[];
@@ -21,9 +22,29 @@ export interface Playlist {
 
 export interface PlaylistItem {
   id?: number;
-  value: string; //tag or id.toString()
-  type: 'dashboard_by_id' | 'dashboard_by_tag';
+  value: string; //tag, uid, title pattern or id.toString()
+  type: 'dashboard_by_id' | 'dashboard_by_tag' | 'dashboard_by_uid' | 'dashboard_search';
   order: number;
   title: string;
   playlistId?: number;
+  /** overrides the playlist interval for the dashboards of this item */
+  interval?: string;
+  options?: PlaylistItemOptions;
+}
+
+export interface PlaylistItemOptions {
+  folderUids?: string[];
+  tags?: string[];
+  variables?: Record<string, string[]>;
+  from?: string;
+  to?: string;
+}
+
+export interface PlaylistDashboard {
+  id: number;
+  uid: string;
+  title: string;
+  url: string;
+  order: number;
+  interval?: string;
 }
@@ -2,6 +2,7 @@ import React, { useState } from 'react';
 import { AppEvents } from '@grafana/data';
 import { Button, HorizontalGroup, ConfirmModal } from '@grafana/ui';
 import appEvents from 'app/core/app_events';
+import { getDashboardUsageWarning } from 'app/core/utils/dashboardUsage';
 
 import { CatalogPlugin, PluginStatus, Version } from '../../types';
 import { useInstallStatus, useUninstallStatus, useInstall, useUninstall } from '../../state/hooks';
@@ -18,7 +19,11 @@ export function InstallControlsButton({ plugin, pluginStatus, latestCompatibleVe
   const install = useInstall();
   const uninstall = useUninstall();
   const [isConfirmModalVisible, setIsConfirmModalVisible] = useState(false);
-  const showConfirmModal = () => setIsConfirmModalVisible(true);
+  const [usageWarning, setUsageWarning] = useState<string>();
+  const showConfirmModal = async () => {
+    setUsageWarning(await getDashboardUsageWarning(`/api/plugins/${plugin.id}/usage`));
+    setIsConfirmModalVisible(true);
+  };
   const hideConfirmModal = () => setIsConfirmModalVisible(false);
   const uninstallBtnText = isUninstalling ? 'Uninstalling' : 'Uninstall';
 
@@ -51,6 +56,7 @@ export function InstallControlsButton({ plugin, pluginStatus, latestCompatibleVe
           isOpen={isConfirmModalVisible}
           title={`Uninstall ${plugin.name}`}
           body="Are you sure you want to uninstall this plugin?"
+          description={usageWarning}
           confirmText="Confirm"
           icon="exclamation-triangle"
           onConfirm={onUninstall}
@@ -31,6 +31,7 @@ export enum FormatRegistryID {
   glob = 'glob',
   text = 'text',
   queryParam = 'queryparam',
+  sqlParam = 'param',
 }
 
 export const formatRegistry = new Registry<FormatRegistryItem>(() => {
@@ -252,6 +253,22 @@ export const formatRegistry = new Registry<FormatRegistryItem>(() => {
         return formatQueryParameter(name, value);
       },
     },
+    {
+      id: FormatRegistryID.sqlParam,
+      name: 'SQL parameter',
+      description:
+        'Keeps the variable in SQL queries so that the data source binds its values as query parameters, optionally converted to a type such as ${var:param:number}. Example in multi-variable scenario A + B + C => ${var:param}.',
+      formatter: (options, variable) => {
+        const { name } = variable;
+
+        // Variables that only exist in the scoped vars cannot be bound, fall back to quoted literals.
+        if (!name) {
+          return formatRegistry.get(FormatRegistryID.sqlString).formatter(options, variable);
+        }
+
+        return '${' + [name, FormatRegistryID.sqlParam, ...options.args].join(':') + '}';
+      },
+    },
   ];
 
   return formats;
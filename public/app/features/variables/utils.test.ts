@@ -3,11 +3,13 @@ import {
   ensureStringValues,
   findTemplateVarChanges,
   getCurrentText,
+  getSqlQueryParams,
   getVariableRefresh,
   isAllVariable,
 } from './utils';
 import { VariableRefresh } from './types';
 import { UrlQueryMap } from '@grafana/data';
+import { initTemplateSrv } from '../../../test/helpers/initTemplateSrv';
 
 describe('isAllVariable', () => {
   it.each`
@@ -194,3 +196,41 @@ describe('containsVariable', () => {
     expect(containsVariable(value, 'var')).toEqual(expected);
   });
 });
+
+describe('getSqlQueryParams', () => {
+  const templateSrv = initTemplateSrv([
+    { type: 'query', name: 'host', multi: true, current: { value: ['a', "b' OR '1'='1"] } },
+    { type: 'textbox', name: 'filter', current: { value: 'text' } },
+    { type: 'interval', name: 'interval', current: { value: '5m' } },
+  ]);
+
+  it('keeps ${var:param} references in the interpolated query', () => {
+    const rawSql = templateSrv.replace('SELECT * FROM t WHERE host IN (${host:param}) AND filter = $filter', {});
+    expect(rawSql).toBe('SELECT * FROM t WHERE host IN (${host:param}) AND filter = text');
+  });
+
+  it('keeps the declared type of ${var:param:type} references', () => {
+    const rawSql = templateSrv.replace('SELECT * FROM t WHERE id = ${filter:param:number}', {});
+    expect(rawSql).toBe('SELECT * FROM t WHERE id = ${filter:param:number}');
+    expect(getSqlQueryParams(rawSql, {}, templateSrv)).toEqual([
+      { name: 'filter', type: 'textbox', multi: false, values: ['text'] },
+    ]);
+  });
+
+  it('returns the values of the referenced variables', () => {
+    const rawSql = 'SELECT * FROM t WHERE host IN (${host:param}) AND x = ${filter:param} AND y = ${filter:param}';
+    expect(getSqlQueryParams(rawSql, {}, templateSrv)).toEqual([
+      { name: 'host', type: 'query', multi: true, values: ['a', "b' OR '1'='1"] },
+      { name: 'filter', type: 'textbox', multi: false, values: ['text'] },
+    ]);
+  });
+
+  it('uses the values of scoped vars', () => {
+    const params = getSqlQueryParams('SELECT ${host:param}', { host: { value: 'c', text: 'c' } }, templateSrv);
+    expect(params).toEqual([{ name: 'host', type: 'query', multi: true, values: ['c'] }]);
+  });
+
+  it('ignores unknown variables and other formats', () => {
+    expect(getSqlQueryParams('SELECT ${unknown:param}, ${interval:csv}, $interval', {}, templateSrv)).toEqual([]);
+  });
+});
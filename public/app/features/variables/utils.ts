@@ -52,6 +52,56 @@ export const getSearchFilterScopedVar = (args: {
   };
 };
 
+const SQL_PARAM_REGEX = /\$\{(\w+):param(?::\w+)?\}/g;
+
+export interface SqlQueryParam {
+  name: string;
+  type: VariableType;
+  multi: boolean;
+  values: string[];
+}
+
+/**
+ * Returns the variables a SQL query refers to as ${var:param} or ${var:param:type}, together with their current
+ * values, so that the data source can bind them as query parameters instead of interpolating them into the query.
+ */
+export function getSqlQueryParams(rawSql: string, scopedVars?: ScopedVars, templateSrv = getTemplateSrv()) {
+  const names = new Set<string>();
+  SQL_PARAM_REGEX.lastIndex = 0;
+  let match = SQL_PARAM_REGEX.exec(rawSql);
+  while (match) {
+    names.add(match[1]);
+    match = SQL_PARAM_REGEX.exec(rawSql);
+  }
+
+  const params: SqlQueryParam[] = [];
+  names.forEach((name) => {
+    const reference = '${' + name + ':json}';
+    const value = templateSrv.replace(reference, scopedVars);
+    if (value === reference) {
+      return;
+    }
+
+    const variable: any = templateSrv.getVariables().find((v) => v.name === name);
+    let values: unknown;
+    try {
+      values = JSON.parse(value);
+    } catch (e) {
+      // custom all values are not formatted
+      values = value;
+    }
+
+    params.push({
+      name,
+      type: variable?.type ?? 'textbox',
+      multi: Boolean(variable?.multi || variable?.includeAll),
+      values: (Array.isArray(values) ? values : [values]).map((v) => String(v)),
+    });
+  });
+
+  return params;
+}
+
 export function containsVariable(...args: any[]) {
   const variableName = args[args.length - 1];
   args[0] = typeof args[0] === 'string' ? args[0] : safeStringifyValue(args[0]);
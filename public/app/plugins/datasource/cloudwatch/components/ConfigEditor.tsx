@@ -26,6 +26,15 @@ export const ConfigEditor: FC<Props> = (props: Props) => {
   const datasource = useDatasource(options.name);
   useAuthenticationWarning(options.jsonData);
   const logsTimeoutError = useTimoutValidation(props.options.jsonData.logsTimeout);
+  const listMetricsCacheTTLError = useTimoutValidation(props.options.jsonData.listMetricsCacheTTL);
+  const metricDataCacheTTLError = useTimoutValidation(props.options.jsonData.metricDataCacheTTL);
+
+  const onUpdateNumberOption = (key: 'apiCallsPerSecond' | 'apiCallsBurst') => (
+    event: React.SyntheticEvent<HTMLInputElement>
+  ) => {
+    const value = parseFloat(event.currentTarget.value);
+    updateDatasourcePluginJsonDataOption(props, key, isNaN(value) ? undefined : value);
+  };
 
   return (
     <>
@@ -64,6 +73,64 @@ export const ConfigEditor: FC<Props> = (props: Props) => {
         </InlineField>
       </div>
 
+      <h3 className="page-heading">API usage</h3>
+      <div className="gf-form-group">
+        <InlineField
+          label="List metrics cache TTL"
+          labelWidth={28}
+          tooltip='How long metric, dimension key and dimension value lookups are cached. Not cached if empty. Must be a valid duration string, such as "1h" "5m" etc.'
+          invalid={Boolean(listMetricsCacheTTLError)}
+        >
+          <Input
+            width={60}
+            placeholder="1h"
+            value={options.jsonData.listMetricsCacheTTL || ''}
+            onChange={onUpdateDatasourceJsonDataOption(props, 'listMetricsCacheTTL')}
+            title={'The TTL must be a valid duration string, such as "1h" "5m" etc.'}
+          />
+        </InlineField>
+        <InlineField
+          label="Metric data cache TTL"
+          labelWidth={28}
+          tooltip='How long metric query responses are cached. The time range of the queries is aligned to their period so that refreshes within a period are answered from the cache. Not cached if empty. Must be a valid duration string, such as "1m" "30s" etc.'
+          invalid={Boolean(metricDataCacheTTLError)}
+        >
+          <Input
+            width={60}
+            placeholder="1m"
+            value={options.jsonData.metricDataCacheTTL || ''}
+            onChange={onUpdateDatasourceJsonDataOption(props, 'metricDataCacheTTL')}
+            title={'The TTL must be a valid duration string, such as "1m" "30s" etc.'}
+          />
+        </InlineField>
+        <InlineField
+          label="API calls per second"
+          labelWidth={28}
+          tooltip="The budget of AWS API calls per second of the data source. Calls over the budget wait for up to 5 seconds before they fail. No budget if empty or 0."
+        >
+          <Input
+            width={60}
+            type="number"
+            placeholder="Unlimited"
+            value={options.jsonData.apiCallsPerSecond ?? ''}
+            onChange={onUpdateNumberOption('apiCallsPerSecond')}
+          />
+        </InlineField>
+        <InlineField
+          label="API calls burst"
+          labelWidth={28}
+          tooltip="The number of AWS API calls the data source can make at once within its budget. Default is 10."
+        >
+          <Input
+            width={60}
+            type="number"
+            placeholder="10"
+            value={options.jsonData.apiCallsBurst ?? ''}
+            onChange={onUpdateNumberOption('apiCallsBurst')}
+          />
+        </InlineField>
+      </div>
+
       <XrayLinkConfig
         onChange={(uid) => updateDatasourcePluginJsonDataOption(props, 'tracingDatasourceUid', uid)}
         datasourceUid={options.jsonData.tracingDatasourceUid}
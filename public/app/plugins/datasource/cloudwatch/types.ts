@@ -123,6 +123,12 @@ export interface CloudWatchJsonData extends AwsAuthDataSourceJsonData {
   logsTimeout?: string;
   // Used to create links if logs contain traceId.
   tracingDatasourceUid?: string;
+  // Time strings like 1h, 5m etc. Responses are not cached if empty.
+  listMetricsCacheTTL?: string;
+  metricDataCacheTTL?: string;
+  // AWS API calls per second of the data source. There is no budget if empty or 0.
+  apiCallsPerSecond?: number;
+  apiCallsBurst?: number;
 }
 
 export interface CloudWatchSecureJsonData extends AwsAuthDataSourceSecureJsonData {
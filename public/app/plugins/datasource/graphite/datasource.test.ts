@@ -525,6 +525,53 @@ describe('graphiteDatasource', () => {
     });
   });
 
+  describe('with proxy access', () => {
+    let requestOptions: any;
+
+    beforeEach(() => {
+      const ds = new GraphiteDatasource(
+        { id: 1, url: '/api/datasources/proxy/1', access: 'proxy', name: 'graphiteProd', jsonData: {} },
+        ctx.templateSrv
+      );
+      ctx = { ...ctx, ds };
+      fetchMock.mockImplementation((options: any) => {
+        requestOptions = options;
+        return of(createFetchResponse(['backend_01', 'backend_02']));
+      });
+    });
+
+    it('should find metrics through the data source resources', async () => {
+      await ctx.ds.metricFindQuery('apps.*');
+
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/metrics/find');
+      expect(requestOptions.method).toEqual('POST');
+      expect(requestOptions.data).toEqual('query=apps.*');
+    });
+
+    it('should autocomplete tags and tag values through the data source resources', async () => {
+      await ctx.ds.metricFindQuery('tags(server=backend_01)');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
+      expect(requestOptions.params.expr).toEqual(['server=backend_01']);
+
+      await ctx.ds.metricFindQuery('tag_values(server)');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
+      expect(requestOptions.params.tag).toBe('server');
+    });
+
+    it('should fetch the function catalog through the data source resources', async () => {
+      ctx.ds.graphiteVersion = '1.1';
+      await ctx.ds.getFuncDefs();
+
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/functions');
+    });
+
+    it('should expand metrics through the proxy', async () => {
+      await ctx.ds.metricFindQuery('expand(*.servers.*)');
+
+      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/expand');
+    });
+  });
+
   describe('exporting to abstract query', () => {
     async function assertQueryExport(target: string, labelMatchers: AbstractLabelMatcher[]): Promise<void> {
       let abstractQueries = await ctx.ds.exportToAbstractQueries([
@@ -61,6 +61,7 @@ export class GraphiteDatasource
   implements DataSourceWithQueryExportSupport<GraphiteQuery> {
   basicAuth: string;
   url: string;
+  access: string;
   name: string;
   graphiteVersion: any;
   supportsTags: boolean;
@@ -77,6 +78,7 @@ export class GraphiteDatasource
     super(instanceSettings);
     this.basicAuth = instanceSettings.basicAuth;
     this.url = instanceSettings.url;
+    this.access = instanceSettings.access;
     this.name = instanceSettings.name;
     // graphiteVersion is set when a datasource is created but it hadn't been set in the past so we're
     // still falling back to the default behavior here for backwards compatibility (see also #17429)
@@ -531,7 +533,7 @@ export class GraphiteDatasource
     }
 
     return lastValueFrom(
-      this.doGraphiteRequest(httpOptions).pipe(
+      this.doResourceRequest(httpOptions).pipe(
         map((results: any) => {
           return _map(results.data, (metric) => {
             return {
@@ -667,7 +669,7 @@ export class GraphiteDatasource
       httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
       httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
     }
-    return lastValueFrom(this.doGraphiteRequest(httpOptions).pipe(mapToTags()));
+    return lastValueFrom(this.doResourceRequest(httpOptions).pipe(mapToTags()));
   }
 
   getTagValuesAutoComplete(expressions: any[], tag: any, valuePrefix: any, optionalOptions: any) {
@@ -694,7 +696,7 @@ export class GraphiteDatasource
       httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
       httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
     }
-    return lastValueFrom(this.doGraphiteRequest(httpOptions).pipe(mapToTags()));
+    return lastValueFrom(this.doResourceRequest(httpOptions).pipe(mapToTags()));
   }
 
   getVersion(optionalOptions: any) {
@@ -751,7 +753,7 @@ export class GraphiteDatasource
     };
 
     return lastValueFrom(
-      this.doGraphiteRequest(httpOptions).pipe(
+      this.doResourceRequest(httpOptions).pipe(
         map((results: any) => {
           if (results.status !== 200 || typeof results.data !== 'object') {
             if (typeof results.data === 'string') {
@@ -821,6 +823,27 @@ export class GraphiteDatasource
       );
   }
 
+  /**
+   * Sends metric and tag discovery requests and the request of the function catalog to the backend of the
+   * data source, which forwards them to Graphite. Data sources with browser access request Graphite directly.
+   */
+  doResourceRequest(options: { method?: string; url: any; requestId?: any; headers?: any; inspect?: any }) {
+    if (this.access !== 'proxy') {
+      return this.doGraphiteRequest(options);
+    }
+
+    options.url = `/api/datasources/${this.id}/resources${options.url}`;
+    options.inspect = { type: 'graphite' };
+
+    return getBackendSrv()
+      .fetch(options)
+      .pipe(
+        catchError((err: any) => {
+          return throwError(reduceError(err));
+        })
+      );
+  }
+
   buildGraphiteParams(options: any, scopedVars?: ScopedVars): string[] {
     const graphiteOptions = ['from', 'until', 'rawData', 'format', 'maxDataPoints', 'cacheTimeout'];
     const cleanOptions = [],
@@ -6,6 +6,7 @@ import { AnnotationEvent, DataSourceInstanceSettings, MetricFindValue, ScopedVar
 
 import ResponseParser from './response_parser';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
+import { getSqlQueryParams } from 'app/features/variables/utils';
 import { MssqlOptions, MssqlQuery, MssqlQueryForInterpolation } from './types';
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
@@ -77,6 +78,7 @@ export class MssqlDatasource extends DataSourceWithBackend<MssqlQuery, MssqlOpti
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(target.rawSql, scopedVars, this.interpolateVariable),
       format: target.format,
+      params: getSqlQueryParams(target.rawSql, scopedVars, this.templateSrv),
     };
   }
 
@@ -90,6 +92,7 @@ export class MssqlDatasource extends DataSourceWithBackend<MssqlQuery, MssqlOpti
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(options.annotation.rawQuery, options.scopedVars, this.interpolateVariable),
       format: 'table',
+      params: getSqlQueryParams(options.annotation.rawQuery, options.scopedVars, this.templateSrv),
     };
 
     return lastValueFrom(
@@ -130,6 +133,7 @@ export class MssqlDatasource extends DataSourceWithBackend<MssqlQuery, MssqlOpti
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(query, {}, this.interpolateVariable),
       format: 'table',
+      params: getSqlQueryParams(query, {}, this.templateSrv),
     };
 
     return lastValueFrom(
@@ -8,7 +8,7 @@ import MySQLQueryModel from 'app/plugins/datasource/mysql/mysql_query_model';
 import ResponseParser from './response_parser';
 import { MySQLOptions, MySQLQuery, MysqlQueryForInterpolation } from './types';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
-import { getSearchFilterScopedVar } from '../../../features/variables/utils';
+import { getSearchFilterScopedVar, getSqlQueryParams } from '../../../features/variables/utils';
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
 
@@ -78,11 +78,13 @@ export class MysqlDatasource extends DataSourceWithBackend<MySQLQuery, MySQLOpti
 
   applyTemplateVariables(target: MySQLQuery, scopedVars: ScopedVars): Record<string, any> {
     const queryModel = new MySQLQueryModel(target, this.templateSrv, scopedVars);
+    const rawSql = queryModel.render(this.interpolateVariable as any);
     return {
       refId: target.refId,
       datasource: this.getRef(),
-      rawSql: queryModel.render(this.interpolateVariable as any),
+      rawSql,
       format: target.format,
+      params: getSqlQueryParams(rawSql, scopedVars, this.templateSrv),
     };
   }
 
@@ -98,6 +100,7 @@ export class MysqlDatasource extends DataSourceWithBackend<MySQLQuery, MySQLOpti
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(options.annotation.rawQuery, options.scopedVars, this.interpolateVariable),
       format: 'table',
+      params: getSqlQueryParams(options.annotation.rawQuery, options.scopedVars, this.templateSrv),
     };
 
     return lastValueFrom(
@@ -127,17 +130,15 @@ export class MysqlDatasource extends DataSourceWithBackend<MySQLQuery, MySQLOpti
       refId = optionalOptions.variable.name;
     }
 
-    const rawSql = this.templateSrv.replace(
-      query,
-      getSearchFilterScopedVar({ query, wildcardChar: '%', options: optionalOptions }),
-      this.interpolateVariable
-    );
+    const searchFilter = getSearchFilterScopedVar({ query, wildcardChar: '%', options: optionalOptions });
+    const rawSql = this.templateSrv.replace(query, searchFilter, this.interpolateVariable);
 
     const interpolatedQuery = {
       refId: refId,
       datasource: this.getRef(),
       rawSql,
       format: 'table',
+      params: getSqlQueryParams(rawSql, searchFilter, this.templateSrv),
     };
 
     const range = this.timeSrv.timeRange();
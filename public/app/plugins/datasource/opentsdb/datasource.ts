@@ -31,6 +31,7 @@ import { OpenTsdbOptions, OpenTsdbQuery } from './types';
 export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenTsdbOptions> {
   type: any;
   url: any;
+  access: any;
   name: any;
   withCredentials: any;
   basicAuth: any;
@@ -46,6 +47,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     super(instanceSettings);
     this.type = 'opentsdb';
     this.url = instanceSettings.url;
+    this.access = instanceSettings.access;
     this.name = instanceSettings.name;
     this.withCredentials = instanceSettings.withCredentials;
     this.basicAuth = instanceSettings.basicAuth;
@@ -223,7 +225,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
   }
 
   _performSuggestQuery(query: string, type: string): Observable<any> {
-    return this._get('/api/suggest', { type, q: query, max: this.lookupLimit }).pipe(
+    return this._getResource('/api/suggest', { type, q: query, max: this.lookupLimit }).pipe(
       map((result: any) => {
         return result.data;
       })
@@ -247,7 +249,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
 
     const m = metric + '{' + keysQuery + '}';
 
-    return this._get('/api/search/lookup', { m: m, limit: this.lookupLimit }).pipe(
+    return this._getResource('/api/search/lookup', { m: m, limit: this.lookupLimit }).pipe(
       map((result: any) => {
         result = result.data.results;
         const tagvs: any[] = [];
@@ -266,7 +268,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
       return of([]);
     }
 
-    return this._get('/api/search/lookup', { m: metric, limit: 1000 }).pipe(
+    return this._getResource('/api/search/lookup', { m: metric, limit: 1000 }).pipe(
       map((result: any) => {
         result = result.data.results;
         const tagks: any[] = [];
@@ -297,6 +299,25 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     return getBackendSrv().fetch(options);
   }
 
+  /**
+   * Sends suggest and lookup requests to the backend of the data source, which forwards them to OpenTSDB.
+   * Data sources with browser access request OpenTSDB directly.
+   */
+  _getResource(
+    relativeUrl: string,
+    params?: { type?: string; q?: string; max?: number; m?: any; limit?: number }
+  ): Observable<FetchResponse> {
+    if (this.access !== 'proxy') {
+      return this._get(relativeUrl, params);
+    }
+
+    return getBackendSrv().fetch({
+      method: 'GET',
+      url: `/api/datasources/${this.id}/resources${relativeUrl}`,
+      params: params,
+    });
+  }
+
   _addCredentialOptions(options: any) {
     if (this.basicAuth || this.withCredentials) {
       options.withCredentials = true;
@@ -485,7 +506,7 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
 
       if (tsdbVersion >= 2) {
         query.rateOptions.dropResets =
-          !query.rateOptions.counterMax && (!query.rateOptions.ResetValue || query.rateOptions.ResetValue === 0);
+          !query.rateOptions.counterMax && (!query.rateOptions.resetValue || query.rateOptions.resetValue === 0);
       }
     }
 
@@ -20,12 +20,12 @@ const metricFindQueryData = [
 ];
 
 describe('opentsdb', () => {
-  function getTestcontext({ data = metricFindQueryData }: { data?: any } = {}) {
+  function getTestcontext({ data = metricFindQueryData, access }: { data?: any; access?: string } = {}) {
     jest.clearAllMocks();
     const fetchMock = jest.spyOn(backendSrv, 'fetch');
     fetchMock.mockImplementation(() => of(createFetchResponse(data)));
 
-    const instanceSettings = { url: '', jsonData: { tsdbVersion: 1 } };
+    const instanceSettings = { id: 1, url: '', access, jsonData: { tsdbVersion: 1 } };
     const replace = jest.fn((value) => value);
     const templateSrv: any = {
       replace,
@@ -118,6 +118,38 @@ describe('opentsdb', () => {
     });
   });
 
+  describe('When performing metricFindQuery with server access', () => {
+    it('metrics() should generate api suggest query of the data source resources', async () => {
+      const { ds, fetchMock } = getTestcontext({ access: 'proxy' });
+
+      await ds.metricFindQuery('metrics(pew)');
+
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/api/suggest');
+      expect(fetchMock.mock.calls[0][0].params?.type).toBe('metrics');
+      expect(fetchMock.mock.calls[0][0].params?.q).toBe('pew');
+    });
+
+    it('tag_values(cpu, test) should generate lookup query of the data source resources', async () => {
+      const { ds, fetchMock } = getTestcontext({ access: 'proxy' });
+
+      await ds.metricFindQuery('tag_values(cpu, test)');
+
+      expect(fetchMock.mock.calls[0][0].url).toBe('/api/datasources/1/resources/api/search/lookup');
+      expect(fetchMock.mock.calls[0][0].params?.m).toBe('cpu{test=*}');
+    });
+  });
+
+  describe('When converting a target with counter options', () => {
+    it('should not drop resets when a reset value is set', () => {
+      const { ds } = getTestcontext();
+      const target = { metric: 'net.bytes', shouldComputeRate: true, isCounter: true, counterResetValue: '10' };
+
+      const query = ds.convertTargetToQuery(target, { interval: '1m', scopedVars: {} }, 2);
+
+      expect(query.rateOptions).toEqual({ counter: true, resetValue: 10, dropResets: false });
+    });
+  });
+
   describe('When interpolating variables', () => {
     it('should return an empty array if no queries are provided', () => {
       const { ds } = getTestcontext();
@@ -10,7 +10,7 @@ import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_sr
 import { getTimeSrv, TimeSrv } from 'app/features/dashboard/services/TimeSrv';
 //Types
 import { PostgresOptions, PostgresQuery, PostgresQueryForInterpolation } from './types';
-import { getSearchFilterScopedVar } from '../../../features/variables/utils';
+import { getSearchFilterScopedVar, getSqlQueryParams } from '../../../features/variables/utils';
 import { toTestingStatus } from '@grafana/runtime/src/utils/queryResponse';
 
 export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, PostgresOptions> {
@@ -80,11 +80,13 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
 
   applyTemplateVariables(target: PostgresQuery, scopedVars: ScopedVars): Record<string, any> {
     const queryModel = new PostgresQueryModel(target, this.templateSrv, scopedVars);
+    const rawSql = queryModel.render(this.interpolateVariable as any);
     return {
       refId: target.refId,
       datasource: this.getRef(),
-      rawSql: queryModel.render(this.interpolateVariable as any),
+      rawSql,
       format: target.format,
+      params: getSqlQueryParams(rawSql, scopedVars, this.templateSrv),
     };
   }
 
@@ -100,6 +102,7 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(options.annotation.rawQuery, options.scopedVars, this.interpolateVariable),
       format: 'table',
+      params: getSqlQueryParams(options.annotation.rawQuery, options.scopedVars, this.templateSrv),
     };
 
     return lastValueFrom(
@@ -129,17 +132,15 @@ export class PostgresDatasource extends DataSourceWithBackend<PostgresQuery, Pos
       refId = optionalOptions.variable.name;
     }
 
-    const rawSql = this.templateSrv.replace(
-      query,
-      getSearchFilterScopedVar({ query, wildcardChar: '%', options: optionalOptions }),
-      this.interpolateVariable
-    );
+    const searchFilter = getSearchFilterScopedVar({ query, wildcardChar: '%', options: optionalOptions });
+    const rawSql = this.templateSrv.replace(query, searchFilter, this.interpolateVariable);
 
     const interpolatedQuery = {
       refId: refId,
       datasource: this.getRef(),
       rawSql,
       format: 'table',
+      params: getSqlQueryParams(rawSql, searchFilter, this.templateSrv),
     };
 
     const range = this.timeSrv.timeRange();
@@ -0,0 +1,12 @@
+export class SqliteConfigCtrl {
+  static templateUrl = 'partials/config.html';
+
+  // Set through angular bindings
+  declare current: any;
+
+  /** @ngInject */
+  constructor($scope: any) {
+    this.current = $scope.ctrl.current;
+    this.current.jsonData.path = this.current.jsonData.path || '';
+  }
+}
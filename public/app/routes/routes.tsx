@@ -68,6 +68,14 @@ export function getAppRoutes(): RouteDescriptor[] {
         () => import(/* webpackChunkName: "SoloPanelPage" */ '../features/dashboard/containers/SoloPanelPage')
       ),
     },
+    {
+      path: '/d-csv/:uid',
+      pageClass: 'dashboard-solo',
+      routeName: DashboardRoutes.Normal,
+      component: SafeDynamicImport(
+        () => import(/* webpackChunkName: "CSVPanelPage" */ '../features/dashboard/containers/CSVPanelPage')
+      ),
+    },
     {
       path: '/dashboard/import',
       component: SafeDynamicImport(